@@ -47,7 +47,9 @@ type BackupSpec struct {
 	SnapshotVolumes *bool `json:"snapshotVolumes"`
 
 	// TTL is a time.Duration-parseable string describing how long
-	// the Backup should be retained for.
+	// the Backup should be retained for. If it's zero, the server's
+	// default backup TTL is used. A negative TTL means the Backup
+	// never expires.
 	TTL metav1.Duration `json:"ttl"`
 
 	// IncludeClusterResources specifies whether cluster-scoped resources
@@ -56,12 +58,169 @@ type BackupSpec struct {
 
 	// Hooks represent custom behaviors that should be executed at different phases of the backup.
 	Hooks BackupHooks `json:"hooks"`
+
+	// DeletionPolicy specifies which of the backup's data is deleted when the
+	// backup is deleted. Defaults to All.
+	DeletionPolicy BackupDeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// IncludeArkResources specifies whether the Configs and Schedules
+	// in the Ark namespace are included in the backup, even if the
+	// Ark namespace or their resources aren't, so that the Ark
+	// installation can be rebuilt from the backup. If null, defaults
+	// to true.
+	IncludeArkResources *bool `json:"includeArkResources,omitempty"`
+
+	// MaxItemSizeBytes is the size, in bytes, of an item's JSON above
+	// which the item is handled according to LargeItemAction. If zero,
+	// the server's maxItemSizeBytes is used, and if that's also zero,
+	// items of any size are backed up.
+	MaxItemSizeBytes int64 `json:"maxItemSizeBytes,omitempty"`
+
+	// LargeItemAction is what's done with items larger than
+	// MaxItemSizeBytes. Defaults to the server's largeItemAction.
+	LargeItemAction LargeItemAction `json:"largeItemAction,omitempty"`
+
+	// MaxAdditionalItemDepth is how many levels of additional items
+	// returned by backup item actions are backed up. The items an action
+	// returns for an item that was itself returned by an action are one
+	// level deeper than it. Deeper items are left out of the backup with
+	// a warning. If zero, the server's maxAdditionalItemDepth is used.
+	MaxAdditionalItemDepth int `json:"maxAdditionalItemDepth,omitempty"`
+
+	// DryRun specifies that the backup should only determine which
+	// items it would include, counting them in the status and listing
+	// them in the log, without running hooks, taking snapshots, or
+	// uploading any data. Optional.
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// ShardBy specifies how the backup's contents are split into
+	// separate tarballs, listed in a manifest, in object storage, so
+	// that they can be transferred independently and restores only
+	// download the ones they need. If empty, the contents are stored
+	// in a single tarball. Optional.
+	ShardBy BackupShardBy `json:"shardBy,omitempty"`
+
+	// Quiesce specifies Deployments and StatefulSets that are scaled
+	// down to zero replicas before the backup's items are backed up,
+	// and scaled back up once they have been. Optional.
+	Quiesce *QuiesceSpec `json:"quiesce,omitempty"`
+
+	// FailurePolicy specifies what happens when an item or resource
+	// group can't be backed up. Defaults to Continue.
+	FailurePolicy BackupFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// BackupFailurePolicy specifies what happens when part of a backup
+// fails.
+type BackupFailurePolicy string
+
+const (
+	// BackupFailurePolicyContinue means the rest of the backup's items
+	// are still backed up, and everything that succeeded is persisted.
+	// The backup ends PartiallyFailed, unless a cluster couldn't be
+	// backed up at all, in which case it ends Failed.
+	BackupFailurePolicyContinue BackupFailurePolicy = "Continue"
+
+	// BackupFailurePolicyFailFast means the backup stops at the first
+	// error, and ends Failed.
+	BackupFailurePolicyFailFast BackupFailurePolicy = "FailFast"
+)
+
+// QuiesceSpec selects the workloads that are scaled down while a
+// backup is taken, so that their volumes aren't being written to.
+type QuiesceSpec struct {
+	// IncludedNamespaces specifies the namespaces of the workloads to
+	// scale down. If empty, workloads in all of the backup's
+	// namespaces are included.
+	IncludedNamespaces []string `json:"includedNamespaces,omitempty"`
+
+	// LabelSelector selects the Deployments and StatefulSets to scale
+	// down.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector"`
+
+	// Timeout is how long to wait for each workload's pods to be
+	// terminated. The backup proceeds once it elapses. Defaults to 5
+	// minutes.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
 }
 
+// BackupShardBy specifies how a backup's contents are split into
+// separate tarballs in object storage.
+type BackupShardBy string
+
+const (
+	// BackupShardByNamespace means each namespace's items are stored in
+	// their own tarball, and cluster-scoped items in another.
+	BackupShardByNamespace BackupShardBy = "Namespace"
+
+	// BackupShardByResourceGroup means the items of each API group's
+	// resources are stored in their own tarball.
+	BackupShardByResourceGroup BackupShardBy = "ResourceGroup"
+)
+
+// LargeItemAction is what's done with an item whose JSON is larger
+// than a backup's MaxItemSizeBytes.
+type LargeItemAction string
+
+const (
+	// LargeItemActionWarn means the item is backed up, and recorded in
+	// the backup's status.
+	LargeItemActionWarn LargeItemAction = "Warn"
+
+	// LargeItemActionSkip means the item is not backed up, and is
+	// recorded in the backup's status. Additional items returned by
+	// the item's actions, such as a PVC's PV, are still backed up.
+	LargeItemActionSkip LargeItemAction = "Skip"
+
+	// LargeItemActionOffload means the item is backed up, but stored
+	// in object storage as a separate object rather than in the
+	// backup's tarball, and is recorded in the backup's status. It's
+	// merged back into the backup's contents when they're downloaded
+	// for a restore.
+	LargeItemActionOffload LargeItemAction = "Offload"
+)
+
+// BackupDeletionPolicy specifies which of a backup's data is deleted when the
+// backup is deleted.
+type BackupDeletionPolicy string
+
+const (
+	// BackupDeletionPolicyAll means the backup's volume snapshots and its files
+	// in object storage are deleted.
+	BackupDeletionPolicyAll BackupDeletionPolicy = "All"
+
+	// BackupDeletionPolicySnapshotsOnly means the backup's volume snapshots are
+	// deleted, and its files in object storage are retained.
+	BackupDeletionPolicySnapshotsOnly BackupDeletionPolicy = "SnapshotsOnly"
+
+	// BackupDeletionPolicyObjectStorageOnly means the backup's files in object
+	// storage are deleted, and its volume snapshots are retained.
+	BackupDeletionPolicyObjectStorageOnly BackupDeletionPolicy = "ObjectStorageOnly"
+)
+
 // BackupHooks contains custom behaviors that should be executed at different phases of the backup.
 type BackupHooks struct {
 	// Resources are hooks that should be executed when backing up individual instances of a resource.
 	Resources []BackupResourceHookSpec `json:"resources"`
+	// ConsistencyGroups are groups of pods whose volumes are frozen together, then snapshotted, then
+	// unfrozen together, before any items are backed up.
+	ConsistencyGroups []ConsistencyGroupSpec `json:"consistencyGroups,omitempty"`
+}
+
+// ConsistencyGroupSpec defines a group of pods, such as the members of a replicated database, whose
+// volumes are all snapshotted while all of them are frozen, so that the snapshots are
+// crash-consistent with each other.
+type ConsistencyGroupSpec struct {
+	// Name is the name of this consistency group.
+	Name string `json:"name"`
+	// IncludedNamespaces specifies the namespaces of the pods in the group. If empty, pods in all of
+	// the backup's namespaces are included.
+	IncludedNamespaces []string `json:"includedNamespaces,omitempty"`
+	// LabelSelector selects the pods in the group. Only running pods are included.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector"`
+	// FSFreeze defines how each pod's volumes are frozen. If its Volumes are empty, all of each
+	// pod's volumes that are backed by PersistentVolumeClaims are frozen.
+	FSFreeze FSFreezeHook `json:"fsfreeze"`
 }
 
 // BackupResourceHookSpec defines one or more BackupResourceHooks that should be executed based on
@@ -95,6 +254,24 @@ type BackupResourceHookSpec struct {
 type BackupResourceHook struct {
 	// Exec defines an exec hook.
 	Exec *ExecHook `json:"exec"`
+	// FSFreeze defines an fsfreeze hook. It may only be used as a pre hook.
+	FSFreeze *FSFreezeHook `json:"fsfreeze,omitempty"`
+}
+
+// FSFreezeHook is a hook that freezes the filesystems of a pod's volumes before the pod is backed
+// up, and unfreezes them once the pod and its additional items (including volume snapshots) have
+// been backed up. Volumes are always unfrozen, even if the backup of the pod fails.
+type FSFreezeHook struct {
+	// Volumes are the names of the pod's volumes to freeze.
+	Volumes []string `json:"volumes"`
+	// Container is the container in the pod where fsfreeze should be executed. The volumes must be
+	// mounted in it. If not specified, the first container that mounts each volume is used.
+	Container string `json:"container,omitempty"`
+	// OnError specifies how Ark should behave if it encounters an error freezing a volume.
+	OnError HookErrorMode `json:"onError,omitempty"`
+	// Timeout defines the maximum amount of time Ark should wait for each freeze or unfreeze to
+	// complete before considering the execution a failure.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
 }
 
 // ExecHook is a hook that uses the pod exec API to execute a command in a container in a pod.
@@ -102,8 +279,23 @@ type ExecHook struct {
 	// Container is the container in the pod where the command should be executed. If not specified,
 	// the pod's first container is used.
 	Container string `json:"container"`
+	// Containers is a list of containers in the pod where the command should be executed. Init
+	// containers may be named. It may not be combined with Container or ContainerPattern.
+	Containers []string `json:"containers,omitempty"`
+	// ContainerPattern is a glob pattern (as accepted by path.Match). The command is executed in
+	// every running container in the pod whose name matches; init containers and containers that
+	// are not running are skipped. It may not be combined with Container or Containers.
+	ContainerPattern string `json:"containerPattern,omitempty"`
 	// Command is the command and arguments to execute.
 	Command []string `json:"command"`
+	// Env is a list of environment variables to set for the command. The container must provide
+	// the env binary.
+	Env []HookEnvVar `json:"env,omitempty"`
+	// WorkingDir is the absolute path of the directory the command is executed in. The container
+	// must provide /bin/sh.
+	WorkingDir string `json:"workingDir,omitempty"`
+	// Stdin is written to the command's standard input.
+	Stdin string `json:"stdin,omitempty"`
 	// OnError specifies how Ark should behave if it encounters an error executing this hook.
 	OnError HookErrorMode `json:"onError"`
 	// Timeout defines the maximum amount of time Ark should wait for the hook to complete before
@@ -111,6 +303,14 @@ type ExecHook struct {
 	Timeout metav1.Duration `json:"timeout"`
 }
 
+// HookEnvVar is an environment variable set for a hook's command.
+type HookEnvVar struct {
+	// Name is the name of the environment variable.
+	Name string `json:"name"`
+	// Value is the value of the environment variable.
+	Value string `json:"value"`
+}
+
 // HookErrorMode defines how Ark should treat an error from a hook.
 type HookErrorMode string
 
@@ -147,13 +347,20 @@ const (
 	// prevented it from completing successfully.
 	BackupPhaseFailed BackupPhase = "Failed"
 
+	// BackupPhasePartiallyFailed means the backup ran to completion, but
+	// some of its items couldn't be backed up. Everything that was
+	// backed up is persisted.
+	BackupPhasePartiallyFailed BackupPhase = "PartiallyFailed"
+
 	// BackupPhaseDeleting means the backup and all its associated data are being deleted.
 	BackupPhaseDeleting BackupPhase = "Deleting"
 )
 
 // BackupStatus captures the current status of an Ark backup.
 type BackupStatus struct {
-	// Version is the backup format version.
+	// Version is the format version of the backup's contents in
+	// object storage. Versions of Ark that can't read it don't sync
+	// or restore the backup.
 	Version int `json:"version"`
 
 	// Expiration is when this Backup is eligible for garbage-collection.
@@ -167,9 +374,199 @@ type BackupStatus struct {
 	// provider API.
 	VolumeBackups map[string]*VolumeBackupInfo `json:"volumeBackups"`
 
+	// VolumeSnapshots lists the snapshots of the backup's persistent
+	// volumes, along with the claims they were bound to. Each one's
+	// phase is kept up to date as the cloud provider processes it.
+	VolumeSnapshots []VolumeSnapshotStatus `json:"volumeSnapshots,omitempty"`
+
 	// ValidationErrors is a slice of all validation errors (if
 	// applicable).
 	ValidationErrors []string `json:"validationErrors"`
+
+	// ClusterInfo identifies the cluster the backup was taken
+	// from.
+	ClusterInfo *ClusterInfo `json:"clusterInfo,omitempty"`
+
+	// HookStatus summarizes the hooks that were executed
+	// during the backup.
+	HookStatus *HookStatus `json:"hookStatus,omitempty"`
+
+	// Attempts is the number of times the backup has been
+	// started. It's greater than 1 if the backup was restarted
+	// after being interrupted by the Ark server stopping.
+	Attempts int `json:"attempts,omitempty"`
+
+	// LargeItems lists the items whose JSON was larger than
+	// the backup's MaxItemSizeBytes, up to MaxRecordedLargeItems.
+	LargeItems []LargeItem `json:"largeItems,omitempty"`
+
+	// LargeItemCount is the number of items whose JSON was
+	// larger than the backup's MaxItemSizeBytes, including any
+	// that aren't listed in LargeItems.
+	LargeItemCount int `json:"largeItemCount,omitempty"`
+
+	// OffloadedItemCount is the number of large items that were
+	// stored as separate objects rather than in the backup's
+	// tarball.
+	OffloadedItemCount int `json:"offloadedItemCount,omitempty"`
+
+	// ItemCounts is the number of items of each resource that the
+	// backup includes or, for a dry-run backup, would include, keyed
+	// by group-resource.
+	ItemCounts map[string]int `json:"itemCounts,omitempty"`
+
+	// RequestedBy is who the client that created the backup said
+	// requested it, copied from its RequestedByAnnotation when it
+	// was first processed. It's asserted by the client and not
+	// verified, so it must not be relied on as audit data.
+	RequestedBy string `json:"requestedBy,omitempty"`
+
+	// QuiescedWorkloads lists the workloads that were scaled down
+	// while the backup was taken, and their original replica counts.
+	QuiescedWorkloads []QuiescedWorkload `json:"quiescedWorkloads,omitempty"`
+
+	// Warnings is a count of all warning messages that were generated
+	// during execution of the backup. The actual warnings are in the
+	// backup's log in object storage.
+	Warnings int `json:"warnings,omitempty"`
+
+	// Errors is a count of all the items that couldn't be backed up.
+	// The actual errors are in the backup's log in object storage.
+	Errors int `json:"errors,omitempty"`
+
+	// OperationID uniquely identifies the backup in the logs of the
+	// Ark server and its plugins. It's generated when the backup is
+	// first processed.
+	OperationID string `json:"operationID,omitempty"`
+}
+
+// QuiescedWorkload identifies a workload that was scaled down while
+// a backup was taken.
+type QuiescedWorkload struct {
+	// Resource is the workload's resource, formatted as resource.group.
+	Resource string `json:"resource"`
+
+	// Namespace is the workload's namespace.
+	Namespace string `json:"namespace"`
+
+	// Name is the workload's name.
+	Name string `json:"name"`
+
+	// Replicas is the workload's replica count before it was scaled
+	// down, which it was scaled back up to.
+	Replicas int64 `json:"replicas"`
+}
+
+// LargeItem identifies an item whose JSON was larger than a
+// backup's MaxItemSizeBytes.
+type LargeItem struct {
+	// Resource is the item's resource, formatted as resource.group.
+	Resource string `json:"resource"`
+
+	// Namespace is the item's namespace. It is empty for
+	// cluster-scoped items.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name is the item's name.
+	Name string `json:"name"`
+
+	// SizeBytes is the size of the item's JSON.
+	SizeBytes int64 `json:"sizeBytes"`
+
+	// Skipped is whether the item was left out of the backup.
+	Skipped bool `json:"skipped"`
+
+	// Offloaded is whether the item was stored as a separate
+	// object rather than in the backup's tarball.
+	Offloaded bool `json:"offloaded,omitempty"`
+}
+
+// HookStatus summarizes the hooks executed during a backup.
+type HookStatus struct {
+	// HooksAttempted is the number of hook executions that
+	// were attempted.
+	HooksAttempted int `json:"hooksAttempted"`
+
+	// HooksFailed is the number of hook executions that failed
+	// or timed out, including those whose OnError mode is
+	// Continue.
+	HooksFailed int `json:"hooksFailed"`
+
+	// Executions contains a summary of each hook execution that
+	// failed or timed out, up to MaxRecordedHookExecutions. The
+	// counts above include every execution.
+	Executions []HookExecution `json:"executions,omitempty"`
+}
+
+// HookExecution summarizes the execution of a hook in a single
+// container.
+type HookExecution struct {
+	// Name is the name of the hook spec, or <from-annotation>
+	// for hooks specified as pod annotations.
+	Name string `json:"name"`
+
+	// Phase is the hook phase (pre or post).
+	Phase string `json:"phase"`
+
+	// Namespace is the namespace of the pod.
+	Namespace string `json:"namespace"`
+
+	// Pod is the name of the pod.
+	Pod string `json:"pod"`
+
+	// Container is the container the hook was executed in. It
+	// is empty if no container could be selected.
+	Container string `json:"container,omitempty"`
+
+	// Duration is how long the hook took to execute.
+	Duration metav1.Duration `json:"duration"`
+
+	// Outcome is the result of the execution.
+	Outcome HookOutcome `json:"outcome"`
+
+	// OnError is the hook's error mode. A Failed or TimedOut
+	// execution with an OnError of Continue did not fail the
+	// backup.
+	OnError HookErrorMode `json:"onError,omitempty"`
+
+	// Error is the error encountered, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// HookOutcome is the result of a hook execution.
+type HookOutcome string
+
+const (
+	// HookOutcomeSucceeded means the hook's command completed
+	// successfully.
+	HookOutcomeSucceeded HookOutcome = "Succeeded"
+
+	// HookOutcomeFailed means the hook could not be executed or
+	// its command returned an error.
+	HookOutcomeFailed HookOutcome = "Failed"
+
+	// HookOutcomeTimedOut means the hook's command did not
+	// complete within the hook's timeout.
+	HookOutcomeTimedOut HookOutcome = "TimedOut"
+)
+
+// ClusterInfo captures the identity of the cluster a backup was
+// taken from.
+type ClusterInfo struct {
+	// Name is the cluster name configured for the Ark server.
+	Name string `json:"name,omitempty"`
+
+	// KubernetesVersion is the version of the cluster's API
+	// server.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// ArkVersion is the version of the Ark server that took
+	// the backup.
+	ArkVersion string `json:"arkVersion,omitempty"`
+
+	// APIResources is a snapshot of the resources served by
+	// the cluster's discovery API, keyed by group version.
+	APIResources map[string][]string `json:"apiResources,omitempty"`
 }
 
 // VolumeBackupInfo captures the required information about
@@ -191,8 +588,67 @@ type VolumeBackupInfo struct {
 	// Iops is the optional value of provisioned IOPS for the
 	// disk/volume in the cloud provider API.
 	Iops *int64 `json:"iops,omitempty"`
+
+	// Phase is the current state of the snapshot in the cloud
+	// provider API. Snapshots taken by older versions of Ark
+	// do not have a phase and are considered Completed.
+	Phase SnapshotPhase `json:"phase,omitempty"`
+
+	// CopyRegion is the region that the snapshot was copied to
+	// for disaster recovery, if snapshot copying is enabled.
+	CopyRegion string `json:"copyRegion,omitempty"`
+
+	// CopySnapshotID is the ID of the copy of the snapshot in
+	// CopyRegion.
+	CopySnapshotID string `json:"copySnapshotID,omitempty"`
+
+	// CopyPhase is the current state of the copy of the snapshot.
+	CopyPhase SnapshotPhase `json:"copyPhase,omitempty"`
 }
 
+// VolumeSnapshotStatus describes the snapshot of one of a backup's
+// persistent volumes.
+type VolumeSnapshotStatus struct {
+	// PersistentVolume is the name of the snapshotted PersistentVolume.
+	PersistentVolume string `json:"persistentVolume"`
+
+	// Namespace is the namespace of the PersistentVolumeClaim that the
+	// volume was bound to, if any.
+	Namespace string `json:"namespace,omitempty"`
+
+	// PersistentVolumeClaim is the name of the PersistentVolumeClaim
+	// that the volume was bound to, if any.
+	PersistentVolumeClaim string `json:"persistentVolumeClaim,omitempty"`
+
+	// SnapshotID is the ID of the snapshot in the cloud provider API.
+	SnapshotID string `json:"snapshotID"`
+
+	// Size is the volume's capacity, e.g. "10Gi".
+	Size string `json:"size,omitempty"`
+
+	// Phase is the current state of the snapshot in the cloud provider
+	// API.
+	Phase SnapshotPhase `json:"phase"`
+}
+
+// SnapshotPhase is a string representation of the lifecycle phase
+// of a cloud volume snapshot.
+type SnapshotPhase string
+
+const (
+	// SnapshotPhaseInProgress means the snapshot has been cut but the
+	// cloud provider has not finished processing it.
+	SnapshotPhaseInProgress SnapshotPhase = "InProgress"
+
+	// SnapshotPhaseCompleted means the snapshot is ready to be used
+	// for restores.
+	SnapshotPhaseCompleted SnapshotPhase = "Completed"
+
+	// SnapshotPhaseFailed means the cloud provider reported that the
+	// snapshot could not be completed.
+	SnapshotPhaseFailed SnapshotPhase = "Failed"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -50,6 +50,11 @@ type Config struct {
 	// Ark backups in object storage exist as Backup API objects in the cluster.
 	BackupSyncPeriod metav1.Duration `json:"backupSyncPeriod"`
 
+	// OrphanedBackupAction is the action the BackupSyncController takes for
+	// Completed Backup API objects whose files no longer exist in object
+	// storage. Defaults to Label.
+	OrphanedBackupAction OrphanedBackupAction `json:"orphanedBackupAction,omitempty"`
+
 	// GCSyncPeriod is how often the GCController runs to delete expired backup
 	// API objects and corresponding backup files in object storage.
 	GCSyncPeriod metav1.Duration `json:"gcSyncPeriod"`
@@ -58,6 +63,77 @@ type Config struct {
 	// new backups that should be triggered based on schedules.
 	ScheduleSyncPeriod metav1.Duration `json:"scheduleSyncPeriod"`
 
+	// SnapshotSyncPeriod is how often the BackupController checks the status of
+	// volume snapshots that the cloud provider is still processing.
+	SnapshotSyncPeriod metav1.Duration `json:"snapshotSyncPeriod"`
+
+	// DiscoveryRefreshPeriod is how often Ark refreshes its view of the
+	// resources served by the Kubernetes API server.
+	DiscoveryRefreshPeriod metav1.Duration `json:"discoveryRefreshPeriod"`
+
+	// ClientQPS is the maximum number of requests per second the Ark server
+	// makes to the Kubernetes API once its burst is used up. Defaults to 0,
+	// meaning client-go's default is used. The --client-qps flag takes
+	// precedence.
+	ClientQPS float32 `json:"clientQPS,omitempty"`
+
+	// ClientBurst is the maximum number of requests the Ark server makes to
+	// the Kubernetes API in a burst. Defaults to 0, meaning client-go's default
+	// is used. The --client-burst flag takes precedence.
+	ClientBurst int `json:"clientBurst,omitempty"`
+
+	// ClientRequestTimeout is how long the Ark server waits for each request
+	// to the Kubernetes API for items being backed up or restored before
+	// giving up on it. Defaults to 1 minute.
+	ClientRequestTimeout metav1.Duration `json:"clientRequestTimeout"`
+
+	// ClientRequestRetries is how many times the Ark server retries requests
+	// to get or list items being backed up or restored that time out or fail
+	// with a transient error. Defaults to 3.
+	ClientRequestRetries int `json:"clientRequestRetries,omitempty"`
+
+	// CompleteBackupsBeforeSnapshotsReady is whether a Backup should be marked
+	// Completed as soon as its volume snapshots have been initiated, rather than
+	// once the cloud provider reports that they are ready to be used.
+	CompleteBackupsBeforeSnapshotsReady bool `json:"completeBackupsBeforeSnapshotsReady"`
+
+	// InterruptedBackupRetries is the number of times a backup that was left
+	// InProgress by the Ark server stopping is restarted when the server starts
+	// again. Interrupted backups that have no retries left are marked Failed.
+	// Defaults to 0, meaning interrupted backups are never restarted.
+	InterruptedBackupRetries int `json:"interruptedBackupRetries,omitempty"`
+
+	// MaxItemSizeBytes is the size, in bytes, of an item's JSON above which
+	// the item is handled according to LargeItemAction, for backups that
+	// don't set their own. Defaults to 0, meaning items of any size are
+	// backed up.
+	MaxItemSizeBytes int64 `json:"maxItemSizeBytes,omitempty"`
+
+	// LargeItemAction is what's done with items larger than MaxItemSizeBytes,
+	// for backups that don't set their own. Defaults to Warn.
+	LargeItemAction LargeItemAction `json:"largeItemAction,omitempty"`
+
+	// DefaultBackupTTL is the TTL of backups that don't set their own.
+	// Defaults to 720h (30 days). A negative value disables it, so backups
+	// that don't set their own TTL never expire. The --default-backup-ttl
+	// flag takes precedence.
+	DefaultBackupTTL metav1.Duration `json:"defaultBackupTTL,omitempty"`
+
+	// MaxAdditionalItemDepth is how many levels of additional items returned
+	// by backup item actions are backed up, for backups that don't set their
+	// own. Defaults to 10.
+	MaxAdditionalItemDepth int `json:"maxAdditionalItemDepth,omitempty"`
+
+	// LogChunkSizeBytes is the size, in bytes, above which backup and restore
+	// logs are split into chunks that are stored as separate files, along
+	// with an index listing them. Defaults to 0, meaning logs aren't split.
+	LogChunkSizeBytes int64 `json:"logChunkSizeBytes,omitempty"`
+
+	// MaxLogSizeBytes is the size, in bytes, above which backup and restore
+	// logs are truncated before they're stored. Defaults to 0, meaning logs
+	// of any size are stored.
+	MaxLogSizeBytes int64 `json:"maxLogSizeBytes,omitempty"`
+
 	// ResourcePriorities is an ordered slice of resources specifying the desired
 	// order of resource restores. Any resources not in the list will be restored
 	// alphabetically after the prioritized resources.
@@ -66,14 +142,178 @@ type Config struct {
 	// RestoreOnlyMode is whether Ark should run in a mode where only restores
 	// are allowed; backups, schedules, and garbage-collection are all disabled.
 	RestoreOnlyMode bool `json:"restoreOnlyMode"`
+
+	// ClusterName is a user-defined name for the cluster that Ark is running in,
+	// used to identify backups taken from it. Optional.
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// ProvenanceAnnotations is whether every item that's backed up is
+	// annotated with the backup's name, its creation time, and the
+	// cluster's name, so that restored items record where they came from.
+	ProvenanceAnnotations bool `json:"provenanceAnnotations"`
+
+	// RestoreAPIVersionCheck is the action taken when a restore includes a
+	// resource whose backed-up API group version is not served by the cluster
+	// being restored into. Defaults to Warn.
+	RestoreAPIVersionCheck APIVersionCheckAction `json:"restoreAPIVersionCheck,omitempty"`
+
+	// RestoreAPIVersionCheckOverrides overrides RestoreAPIVersionCheck for
+	// specific resources, keyed by resource.group (e.g. deployments.apps).
+	RestoreAPIVersionCheckOverrides map[string]APIVersionCheckAction `json:"restoreAPIVersionCheckOverrides,omitempty"`
+
+	// AdditionalClusters is a list of other clusters whose resources are
+	// included in every backup, alongside those of the cluster Ark is
+	// running in. Optional.
+	AdditionalClusters []AdditionalCluster `json:"additionalClusters,omitempty"`
+
+	// CohabitatingResources are resources served by more than one API group,
+	// in addition to the built-in ones such as deployments. An entry for a
+	// built-in resource replaces it. Optional.
+	CohabitatingResources []CohabitatingResource `json:"cohabitatingResources,omitempty"`
+
+	// BackupSanitizers remove fields and annotations from items before
+	// they're written to a backup, e.g. to keep secrets' data out of backup
+	// storage. They're applied to a copy of each item after all backup item
+	// actions, so volume snapshots and post hooks still see the whole item.
+	// Sanitized items are restored without what was removed. Optional.
+	BackupSanitizers []BackupSanitizer `json:"backupSanitizers,omitempty"`
+
+	// Status is the current status of the configuration. It's set by the Ark
+	// server, and changing it doesn't cause the server to restart.
+	Status ConfigStatus `json:"status,omitempty"`
+}
+
+// ConfigStatus captures the current status of an Ark server's configuration.
+type ConfigStatus struct {
+	// BackupStorage is the result of the most recent check that the backup
+	// storage bucket can be accessed.
+	BackupStorage BackupStorageStatus `json:"backupStorage,omitempty"`
 }
 
+// BackupStoragePhase is whether the backup storage bucket can be accessed.
+type BackupStoragePhase string
+
+const (
+	// BackupStoragePhaseAvailable means the bucket could be listed, and an
+	// object could be written to and deleted from it.
+	BackupStoragePhaseAvailable BackupStoragePhase = "Available"
+
+	// BackupStoragePhaseUnavailable means one of the checks failed.
+	BackupStoragePhaseUnavailable BackupStoragePhase = "Unavailable"
+)
+
+// BackupStorageStatus is the result of checking that the backup storage
+// bucket can be accessed.
+type BackupStorageStatus struct {
+	// Phase is whether the bucket could be accessed.
+	Phase BackupStoragePhase `json:"phase,omitempty"`
+
+	// LastCheckTime is when the bucket was checked.
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+
+	// Message describes why the bucket couldn't be accessed.
+	Message string `json:"message,omitempty"`
+}
+
+// AdditionalCluster is configuration information for connecting to a cluster,
+// other than the one Ark is running in, whose resources should be backed up.
+type AdditionalCluster struct {
+	// Name identifies the cluster. Its resources are stored under
+	// clusters/<name>/ in the backup tarball.
+	Name string `json:"name"`
+
+	// KubeconfigSecret is the name of a secret in the Ark namespace whose
+	// "kubeconfig" key holds a kubeconfig for connecting to the cluster.
+	KubeconfigSecret string `json:"kubeconfigSecret"`
+
+	// Context is the kubeconfig context to use. Defaults to the kubeconfig's
+	// current context.
+	Context string `json:"context,omitempty"`
+}
+
+// CohabitatingResource is a resource that's served by more than one API
+// group, each of which serves the same objects. Only one group's copy of
+// them is backed up, and only one group's copy is restored.
+type CohabitatingResource struct {
+	// Resource is the resource's name, e.g. "deployments".
+	Resource string `json:"resource"`
+
+	// Groups are the API groups serving the resource, in order of
+	// preference. The core group is "". When a backup contains the
+	// resource from more than one of them, it's restored from the first
+	// that the cluster serves.
+	Groups []string `json:"groups"`
+}
+
+// BackupSanitizer removes fields and annotations from the items it matches
+// before they're written to a backup.
+type BackupSanitizer struct {
+	// Resources are the resources the sanitizer applies to, as resource.group
+	// (e.g. "secrets" or "deployments.apps"). If empty, it applies to all
+	// resources.
+	Resources []string `json:"resources,omitempty"`
+
+	// FieldSelector further restricts the items the sanitizer applies to,
+	// e.g. "type=kubernetes.io/tls". Optional.
+	FieldSelector string `json:"fieldSelector,omitempty"`
+
+	// RemoveFields are dot separated paths to fields that are removed from
+	// matching items, e.g. "data" or "spec.template.spec.containers".
+	RemoveFields []string `json:"removeFields,omitempty"`
+
+	// RemoveAnnotations are the keys of annotations that are removed from
+	// matching items. A key ending in "*" removes all annotations with
+	// that prefix, e.g. "kubectl.kubernetes.io/*".
+	RemoveAnnotations []string `json:"removeAnnotations,omitempty"`
+}
+
+// APIVersionCheckAction is the action taken by the restore preflight check
+// when a backed-up resource's API group version is not served by the cluster.
+type APIVersionCheckAction string
+
+const (
+	// APIVersionCheckActionIgnore means the resource is restored without
+	// any warning.
+	APIVersionCheckActionIgnore APIVersionCheckAction = "Ignore"
+
+	// APIVersionCheckActionWarn means a warning is added to the restore's
+	// results, and the restore proceeds.
+	APIVersionCheckActionWarn APIVersionCheckAction = "Warn"
+
+	// APIVersionCheckActionFail means the restore fails validation.
+	APIVersionCheckActionFail APIVersionCheckAction = "Fail"
+)
+
+// OrphanedBackupAction is the action taken by the backup sync controller for
+// a Completed Backup API object whose files no longer exist in object storage.
+type OrphanedBackupAction string
+
+const (
+	// OrphanedBackupActionIgnore means the Backup API object is left as-is.
+	OrphanedBackupActionIgnore OrphanedBackupAction = "Ignore"
+
+	// OrphanedBackupActionLabel means the Backup API object is labeled with
+	// MissingFromStorageLabel. The label is removed if the backup's files
+	// reappear in object storage.
+	OrphanedBackupActionLabel OrphanedBackupAction = "Label"
+
+	// OrphanedBackupActionDelete means the Backup API object is deleted.
+	OrphanedBackupActionDelete OrphanedBackupAction = "Delete"
+)
+
 // CloudProviderConfig is configuration information about how to connect
 // to a particular cloud.
 type CloudProviderConfig struct {
 	Name string `json:"name"`
 
 	Config map[string]string `json:"config"`
+
+	// CredentialsSecret is the name of a secret in the Ark namespace whose
+	// "cloud" key holds the provider's credentials, in the format of its
+	// credentials file. If set, they're used instead of the credentials in
+	// the Ark server's environment, so that the backup storage and persistent
+	// volume providers can use different accounts.
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
 }
 
 // ObjectStorageProviderConfig is configuration information for connecting to
@@ -86,4 +326,11 @@ type ObjectStorageProviderConfig struct {
 	// Bucket is the name of the bucket in object storage where Ark backups
 	// are stored.
 	Bucket string `json:"bucket"`
+
+	// PathTemplate is the path, relative to the bucket, of the directory that
+	// each backup is stored in. It's made up of '/'-separated segments, each
+	// of which is either a literal or one of the {cluster}, {schedule}, and
+	// {backup} variables, and must end with {backup}. Defaults to {backup}.
+	// Backups stored with a different template can still be read.
+	PathTemplate string `json:"pathTemplate,omitempty"`
 }
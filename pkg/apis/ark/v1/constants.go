@@ -25,11 +25,140 @@ const (
 	// for each resource type in the backup.
 	ResourcesDir = "resources"
 
+	// ClustersDir is a top-level directory in backups which contains a sub-directory,
+	// laid out like the top level of the backup, for each additional cluster that
+	// was backed up.
+	ClustersDir = "clusters"
+
+	// LargeItemsDir is a directory in backups, at the top level or in an additional
+	// cluster's sub-directory, which contains the items whose LargeItemAction is
+	// Offload, laid out as they would be otherwise. Its files are stored in object
+	// storage as separate objects rather than in the backup's tarball.
+	LargeItemsDir = "large-items"
+
+	// MaxRecordedLargeItems is the maximum number of items listed in a backup's
+	// status.largeItems. Its status.largeItemCount counts every large item.
+	MaxRecordedLargeItems = 100
+
+	// MaxRecordedHookExecutions is the maximum number of failed hook executions
+	// listed in a backup's status.hookStatus.executions. Its hooksAttempted and
+	// hooksFailed count every execution.
+	MaxRecordedHookExecutions = 100
+
+	// AdditionalClusterKubeconfigKey is the key in an additional cluster's secret
+	// that holds the kubeconfig for connecting to it.
+	AdditionalClusterKubeconfigKey = "kubeconfig"
+
+	// CloudCredentialsKey is the key in a cloud provider's credentials secret
+	// that holds its credentials file.
+	CloudCredentialsKey = "cloud"
+
 	// RestoreLabelKey is the label key that's applied to all resources that
 	// are created during a restore. This is applied for ease of identification
 	// of restored resources. The value will be the restore's name.
 	RestoreLabelKey = "ark-restore"
 
+	// ScheduleLabel is the label key that's applied to backups created by a
+	// schedule. The value will be the schedule's name.
+	ScheduleLabel = "ark-schedule"
+
+	// MissingFromStorageLabel is the label key that the backup sync controller
+	// applies to Completed backups whose files no longer exist in object
+	// storage. The value will be "true".
+	MissingFromStorageLabel = "ark.heptio.com/missing-from-storage"
+
+	// NamespaceAutoBackupLabel is the label key that's applied to the schedule
+	// that the namespace backup controller creates and keeps up to date. The
+	// value will be "true". A schedule without it is never modified by the
+	// controller.
+	NamespaceAutoBackupLabel = "ark.heptio.com/namespace-auto-backup"
+
+	// SnapshotBackupTagKey is the tag key that's applied to all volume snapshots
+	// taken during a backup. The value will be the backup's name.
+	SnapshotBackupTagKey = "ark.heptio.com/backup"
+
+	// SnapshotBucketTagKey is the tag key that's applied to all volume snapshots
+	// taken by an Ark server. The value will be the name of the bucket that the
+	// server stores backups in.
+	SnapshotBucketTagKey = "ark.heptio.com/bucket"
+
+	// SnapshotClusterTagKey is the tag key that's applied to all volume snapshots
+	// taken by an Ark server. The value will be the UID of the server's namespace,
+	// which identifies the Ark installation that took the snapshot when servers in
+	// several clusters share a bucket.
+	SnapshotClusterTagKey = "ark.heptio.com/cluster"
+
+	// SnapshotPVTagKey is the tag key that's applied to all volume snapshots
+	// taken during a backup. The value will be the name of the PersistentVolume
+	// that was snapshotted.
+	SnapshotPVTagKey = "ark.heptio.com/pv"
+
+	// BackupNameAnnotation is the annotation key that's applied to all items
+	// backed up when provenance annotations are enabled. The value will be the
+	// backup's name.
+	BackupNameAnnotation = "ark.heptio.com/backup-name"
+
+	// BackupTimestampAnnotation is the annotation key that's applied to all items
+	// backed up when provenance annotations are enabled. The value will be the
+	// backup's creation time, in RFC 3339 format.
+	BackupTimestampAnnotation = "ark.heptio.com/backup-timestamp"
+
+	// SourceClusterAnnotation is the annotation key that's applied to all items
+	// backed up when provenance annotations are enabled and the Ark server has
+	// a cluster name. The value will be the cluster's name.
+	SourceClusterAnnotation = "ark.heptio.com/source-cluster"
+
+	// OriginalReclaimPolicyAnnotation is the annotation key that's applied to
+	// PersistentVolumes restored by restores that annotate original volume specs.
+	// The value will be the PV's reclaim policy in the backup.
+	OriginalReclaimPolicyAnnotation = "ark.heptio.com/original-reclaim-policy"
+
+	// OriginalAccessModesAnnotation is the annotation key that's applied to
+	// PersistentVolumes and PersistentVolumeClaims restored by restores that
+	// annotate original volume specs. The value will be the comma-separated
+	// access modes of the item in the backup.
+	OriginalAccessModesAnnotation = "ark.heptio.com/original-access-modes"
+
+	// OriginalStorageClassAnnotation is the annotation key that's applied to
+	// PersistentVolumes and PersistentVolumeClaims restored by restores that
+	// annotate original volume specs. The value will be the storage class name
+	// of the item in the backup.
+	OriginalStorageClassAnnotation = "ark.heptio.com/original-storage-class"
+
+	// RequestedByAnnotation is the annotation key that identifies who requested
+	// a backup or restore. The ark CLI sets it to the name of the kubeconfig user
+	// it authenticates as, and the Ark server sets it to "schedule/<name>" on
+	// backups created by schedules. Any client can set it to anything, so it
+	// isn't the identity the API server authenticated.
+	RequestedByAnnotation = "ark.heptio.com/requested-by"
+
+	// OriginalReplicasAnnotation is the annotation key that's applied to
+	// Deployments and StatefulSets while they're scaled down by a backup
+	// that quiesces them. The value will be the workload's replica count
+	// before it was scaled down, which it's restored with.
+	OriginalReplicasAnnotation = "ark.heptio.com/original-replicas"
+
+	// DesiredReplicasAnnotation is the annotation key that records the
+	// replica count a Deployment or StatefulSet that's scaled down to
+	// zero, such as for maintenance, should run with. Users may set it
+	// when scaling a workload down; otherwise, it's recorded on backed-up
+	// workloads from their last applied configuration. Restores with the
+	// Desired replicas policy use it.
+	DesiredReplicasAnnotation = "ark.heptio.com/desired-replicas"
+
+	// OriginalAPIVersionAnnotation is the annotation key that's applied to
+	// items that are restored with a different API version than they were
+	// backed up with, because the cluster doesn't serve the backed-up one.
+	// The value will be the item's API version in the backup.
+	OriginalAPIVersionAnnotation = "ark.heptio.com/original-api-version"
+
+	// DeletedBackupAnnotation is the annotation key that's applied to the
+	// metadata file in object storage of a backup that was deleted with a
+	// deletion policy that retains some of its artifacts. The value will be
+	// the deletion policy. Backups with it are never synced from object
+	// storage, and their retained artifacts are never treated as orphans.
+	DeletedBackupAnnotation = "ark.heptio.com/deleted"
+
 	// ClusterScopedDir is the name of the directory containing cluster-scoped
 	// resources within an Ark backup.
 	ClusterScopedDir = "cluster"
@@ -21,6 +21,9 @@ import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 // DeleteBackupRequestSpec is the specification for which backups to delete.
 type DeleteBackupRequestSpec struct {
 	BackupName string `json:"backupName"`
+
+	// DeletionPolicy overrides the backup's deletion policy. Optional.
+	DeletionPolicy BackupDeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
 // DeleteBackupRequestPhase represents the lifecycle phase of a DeleteBackupRequest.
@@ -46,6 +49,57 @@ type DeleteBackupRequestStatus struct {
 	Phase DeleteBackupRequestPhase `json:"phase"`
 	// Errors contains any errors that were encountered during the deletion process.
 	Errors []string `json:"errors"`
+	// Artifacts records what happened to each of the backup's artifacts, so that any that
+	// remain after the deletion process can be identified.
+	Artifacts []ArtifactDeletionResult `json:"artifacts,omitempty"`
+}
+
+// BackupArtifact is a kind of data that's kept for a backup.
+type BackupArtifact string
+
+const (
+	// BackupArtifactTarball is the backup's contents in object storage, including any shards
+	// and their manifest.
+	BackupArtifactTarball BackupArtifact = "Tarball"
+	// BackupArtifactLogs is the backup's log file in object storage.
+	BackupArtifactLogs BackupArtifact = "Logs"
+	// BackupArtifactMetadata is the backup's metadata file in object storage.
+	BackupArtifactMetadata BackupArtifact = "Metadata"
+	// BackupArtifactRestoreData is a log, results, or plan file in object storage for a
+	// restore from the backup.
+	BackupArtifactRestoreData BackupArtifact = "RestoreData"
+	// BackupArtifactSnapshot is a snapshot, or a copy of one, of a persistent volume in the
+	// backup.
+	BackupArtifactSnapshot BackupArtifact = "Snapshot"
+	// BackupArtifactRestore is a Restore API object that references the backup.
+	BackupArtifactRestore BackupArtifact = "Restore"
+	// BackupArtifactBackup is the Backup API object itself.
+	BackupArtifactBackup BackupArtifact = "Backup"
+)
+
+// ArtifactDeletionPhase is the outcome of deleting a backup artifact.
+type ArtifactDeletionPhase string
+
+const (
+	// ArtifactDeletionPhaseDeleted means the artifact was deleted.
+	ArtifactDeletionPhaseDeleted ArtifactDeletionPhase = "Deleted"
+	// ArtifactDeletionPhaseFailed means deleting the artifact failed, so it remains.
+	ArtifactDeletionPhaseFailed ArtifactDeletionPhase = "Failed"
+	// ArtifactDeletionPhaseRetained means the artifact was kept, either because of the
+	// deletion policy or because deleting other artifacts failed.
+	ArtifactDeletionPhaseRetained ArtifactDeletionPhase = "Retained"
+)
+
+// ArtifactDeletionResult is the outcome of deleting one of a backup's artifacts.
+type ArtifactDeletionResult struct {
+	// Artifact is the kind of artifact.
+	Artifact BackupArtifact `json:"artifact"`
+	// Name identifies the artifact, e.g. its object storage key or snapshot ID.
+	Name string `json:"name"`
+	// Phase is the outcome of deleting the artifact.
+	Phase ArtifactDeletionPhase `json:"phase"`
+	// Error is the error that deleting the artifact failed with, if any.
+	Error string `json:"error,omitempty"`
 }
 
 // +genclient
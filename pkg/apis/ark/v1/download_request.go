@@ -28,10 +28,18 @@ type DownloadRequestSpec struct {
 type DownloadTargetKind string
 
 const (
-	DownloadTargetKindBackupLog      DownloadTargetKind = "BackupLog"
-	DownloadTargetKindBackupContents DownloadTargetKind = "BackupContents"
-	DownloadTargetKindRestoreLog     DownloadTargetKind = "RestoreLog"
-	DownloadTargetKindRestoreResults DownloadTargetKind = "RestoreResults"
+	DownloadTargetKindBackupLog       DownloadTargetKind = "BackupLog"
+	DownloadTargetKindBackupLogIndex  DownloadTargetKind = "BackupLogIndex"
+	DownloadTargetKindBackupContents  DownloadTargetKind = "BackupContents"
+	DownloadTargetKindBackupManifest  DownloadTargetKind = "BackupManifest"
+	DownloadTargetKindBackupShard     DownloadTargetKind = "BackupShard"
+	DownloadTargetKindRestoreLog      DownloadTargetKind = "RestoreLog"
+	DownloadTargetKindRestoreLogIndex DownloadTargetKind = "RestoreLogIndex"
+	DownloadTargetKindRestoreResults  DownloadTargetKind = "RestoreResults"
+	DownloadTargetKindRestorePlan     DownloadTargetKind = "RestorePlan"
+
+	DownloadTargetKindBackupLargeItemsIndex DownloadTargetKind = "BackupLargeItemsIndex"
+	DownloadTargetKindBackupLargeItem       DownloadTargetKind = "BackupLargeItem"
 )
 
 // DownloadTarget is the specification for what kind of file to download, and the name of the
@@ -41,6 +49,15 @@ type DownloadTarget struct {
 	Kind DownloadTargetKind `json:"kind"`
 	// Name is the name of the kubernetes resource with which the file is associated.
 	Name string `json:"name"`
+	// Shard is the name of the shard to download, for BackupShard targets.
+	Shard string `json:"shard,omitempty"`
+	// Chunk is the number of the chunk to download, for BackupLog and RestoreLog
+	// targets whose logs were split into chunks. Chunks are numbered from 1; zero
+	// downloads a log that wasn't split.
+	Chunk int `json:"chunk,omitempty"`
+	// Item is the path of the offloaded item to download, for BackupLargeItem
+	// targets, as listed in the backup's BackupLargeItemsIndex.
+	Item string `json:"item,omitempty"`
 }
 
 // DownloadRequestPhase represents the lifecycle phase of a DownloadRequest.
@@ -55,6 +55,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&DownloadRequestList{},
 		&DeleteBackupRequest{},
 		&DeleteBackupRequestList{},
+		&ServerStatusRequest{},
+		&ServerStatusRequestList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
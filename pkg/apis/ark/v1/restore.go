@@ -46,6 +46,29 @@ type RestoreSpec struct {
 	// namespaces of the same name.
 	NamespaceMapping map[string]string `json:"namespaceMapping"`
 
+	// AvailabilityZoneMapping is a map of source availability
+	// zones to target availability zones to restore volumes
+	// into. Volumes whose snapshots were taken in zones not
+	// included in the map are restored into the same zone.
+	AvailabilityZoneMapping map[string]string `json:"availabilityZoneMapping,omitempty"`
+
+	// ImageRegistryMapping is a map of source image registry
+	// prefixes to target prefixes. Container images in restored
+	// workloads that start with a source prefix are rewritten to
+	// use the target prefix. Image pull secrets are not changed.
+	ImageRegistryMapping map[string]string `json:"imageRegistryMapping,omitempty"`
+
+	// ClusterDomainMapping specifies a change of cluster DNS
+	// domain, such as from "cluster.local", that references to
+	// in restored items, e.g. "my-svc.my-ns.svc.cluster.local",
+	// are rewritten for. Optional.
+	ClusterDomainMapping *ClusterDomainMapping `json:"clusterDomainMapping,omitempty"`
+
+	// ResourceModifierConfigMap is the name of a ConfigMap, in the
+	// Ark server's namespace, of rules that patch the restored items
+	// they match before they're created. Optional.
+	ResourceModifierConfigMap string `json:"resourceModifierConfigMap,omitempty"`
+
 	// LabelSelector is a metav1.LabelSelector to filter with
 	// when restoring individual objects from the backup. If empty
 	// or nil, all objects are included. Optional.
@@ -59,6 +82,102 @@ type RestoreSpec struct {
 	// should be included for consideration in the restore. If null, defaults
 	// to true.
 	IncludeClusterResources *bool `json:"includeClusterResources"`
+
+	// PreserveNodePorts specifies whether to keep the nodePorts
+	// of restored Services rather than letting the cluster
+	// assign new ones. Optional.
+	PreserveNodePorts *bool `json:"preserveNodePorts,omitempty"`
+
+	// SkipCompletedJobs specifies whether Jobs that had completed
+	// when they were backed up are skipped, rather than recreated
+	// and run again. If null, defaults to true. Optional.
+	SkipCompletedJobs *bool `json:"skipCompletedJobs,omitempty"`
+
+	// RestoreStatus specifies the resources whose status should
+	// be restored, using the status subresource. If nil, status
+	// is not restored for any resources. Optional.
+	RestoreStatus *RestoreStatusSpec `json:"restoreStatus,omitempty"`
+
+	// IncludeArkResources specifies whether Ark Configs and Schedules
+	// in the backup are restored. Other Ark resources, such as
+	// Backups and Restores, are never restored. Optional.
+	IncludeArkResources bool `json:"includeArkResources,omitempty"`
+
+	// DryRun specifies that the restore should only determine what
+	// would be done with each item in the backup, recording it in a
+	// plan that's stored in object storage, without making any changes
+	// to the cluster. Optional.
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// AnnotateOriginalVolumeSpecs specifies that restored
+	// PersistentVolumes and PersistentVolumeClaims are annotated
+	// with the reclaim policy, access modes, and storage class
+	// they had when they were backed up. Optional.
+	AnnotateOriginalVolumeSpecs bool `json:"annotateOriginalVolumeSpecs,omitempty"`
+
+	// Verify specifies that, after all items have been restored, Ark
+	// waits for the restored Deployments, StatefulSets, and DaemonSets
+	// to have all of their replicas ready, recording the readiness of
+	// each in the restore's status. Optional.
+	Verify *RestoreVerifySpec `json:"verify,omitempty"`
+
+	// Replicas specifies which replica count Deployments and
+	// StatefulSets that were backed up with zero replicas are restored
+	// with. Defaults to Live.
+	Replicas ReplicasPolicy `json:"replicas,omitempty"`
+}
+
+// ReplicasPolicy specifies which replica count a restore creates a
+// workload that was backed up with zero replicas with.
+type ReplicasPolicy string
+
+const (
+	// ReplicasPolicyLive means workloads are restored with the replica
+	// count they had when they were backed up.
+	ReplicasPolicyLive ReplicasPolicy = "Live"
+
+	// ReplicasPolicyDesired means workloads that were backed up with
+	// zero replicas, such as those scaled down for maintenance, are
+	// restored with the replica count recorded in their
+	// DesiredReplicasAnnotation.
+	ReplicasPolicyDesired ReplicasPolicy = "Desired"
+)
+
+// ClusterDomainMapping maps the cluster DNS domain of the backed-up
+// cluster to the domain of the cluster being restored into.
+type ClusterDomainMapping struct {
+	// From is the cluster domain in the backup.
+	From string `json:"from"`
+
+	// To is the cluster domain that references to From are
+	// rewritten to.
+	To string `json:"to"`
+
+	// ResourceFields maps resources, formatted as resource.group,
+	// to the dot-separated paths of their items' fields whose
+	// references are rewritten, in addition to ConfigMaps' data and
+	// Ingresses' hosts. Optional.
+	ResourceFields map[string][]string `json:"resourceFields,omitempty"`
+}
+
+// RestoreVerifySpec configures the verification of a restore's
+// workloads.
+type RestoreVerifySpec struct {
+	// Timeout is how long Ark waits for the restored workloads to
+	// become ready. If zero, defaults to 5 minutes.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// RestoreStatusSpec selects the resources whose status is
+// restored.
+type RestoreStatusSpec struct {
+	// IncludedResources is a slice of resource names whose
+	// status should be restored.
+	IncludedResources []string `json:"includedResources"`
+
+	// ExcludedResources is a slice of resource names whose
+	// status should not be restored.
+	ExcludedResources []string `json:"excludedResources,omitempty"`
 }
 
 // RestorePhase is a string representation of the lifecycle phase
@@ -80,6 +199,16 @@ const (
 	// RestorePhaseCompleted means the restore has finished executing.
 	// Any relevant warnings or errors will be captured in the Status.
 	RestorePhaseCompleted RestorePhase = "Completed"
+
+	// RestorePhasePartiallyFailed means the restore has finished
+	// executing, but some of its items couldn't be restored. The
+	// number of errors is captured in the Status.
+	RestorePhasePartiallyFailed RestorePhase = "PartiallyFailed"
+
+	// RestorePhaseFailed means the restore has finished executing,
+	// but Ark itself hit an error, such as being unable to download
+	// the backup, so items may not have been restored at all.
+	RestorePhaseFailed RestorePhase = "Failed"
 )
 
 // RestoreStatus captures the current status of an Ark restore
@@ -98,6 +227,45 @@ type RestoreStatus struct {
 	// Errors is a count of all error messages that were generated during
 	// execution of the restore. The actual errors are stored in object storage.
 	Errors int `json:"errors"`
+
+	// RequestedBy is who the client that created the restore said
+	// requested it, copied from its RequestedByAnnotation when it was
+	// first processed. It's asserted by the client and not verified,
+	// so it must not be relied on as audit data.
+	RequestedBy string `json:"requestedBy,omitempty"`
+
+	// Workloads is the readiness of each Deployment, StatefulSet, and
+	// DaemonSet created by the restore, if spec.verify was set.
+	Workloads []WorkloadReadiness `json:"workloads,omitempty"`
+
+	// OperationID uniquely identifies the restore in the logs of the
+	// Ark server and its plugins. It's generated when the restore is
+	// first processed.
+	OperationID string `json:"operationID,omitempty"`
+}
+
+// WorkloadReadiness is the readiness of a restored Deployment,
+// StatefulSet, or DaemonSet when the restore finished verifying it.
+type WorkloadReadiness struct {
+	// Resource is the workload's resource, e.g. "deployments.apps".
+	Resource string `json:"resource"`
+
+	// Namespace is the namespace the workload was restored into.
+	Namespace string `json:"namespace"`
+
+	// Name is the workload's name.
+	Name string `json:"name"`
+
+	// DesiredReplicas is the number of replicas the workload wants.
+	DesiredReplicas int64 `json:"desiredReplicas"`
+
+	// ReadyReplicas is the number of the workload's replicas that
+	// were ready.
+	ReadyReplicas int64 `json:"readyReplicas"`
+
+	// Ready is true if all of the workload's replicas were ready
+	// before the verification timeout.
+	Ready bool `json:"ready"`
 }
 
 // RestoreResult is a collection of messages that were generated
@@ -118,6 +286,45 @@ type RestoreResult struct {
 	Namespaces map[string][]string `json:"namespaces"`
 }
 
+// RestorePlanAction is what a restore would do with an item.
+type RestorePlanAction string
+
+const (
+	// RestorePlanActionCreate means the item doesn't exist in the
+	// cluster and would be created.
+	RestorePlanActionCreate RestorePlanAction = "Create"
+
+	// RestorePlanActionSkip means the item would not be restored,
+	// because it already exists and matches the backed-up version
+	// or for the reason recorded in the plan.
+	RestorePlanActionSkip RestorePlanAction = "Skip"
+
+	// RestorePlanActionConflict means the item already exists in the
+	// cluster and differs from the backed-up version, so it would not
+	// be restored.
+	RestorePlanActionConflict RestorePlanAction = "Conflict"
+)
+
+// RestorePlanItem records what a dry-run restore would do with a
+// single item from the backup.
+type RestorePlanItem struct {
+	// Resource is the item's group-resource, e.g. deployments.apps.
+	Resource string `json:"resource"`
+
+	// Namespace is the namespace the item would be restored into,
+	// after any namespace mapping. Empty for cluster-scoped items.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name is the item's name.
+	Name string `json:"name"`
+
+	// Action is what the restore would do with the item.
+	Action RestorePlanAction `json:"action"`
+
+	// Reason explains why an item would be skipped. Optional.
+	Reason string `json:"reason,omitempty"`
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -27,8 +27,50 @@ type ScheduleSpec struct {
 	// Schedule is a Cron expression defining when to run
 	// the Backup.
 	Schedule string `json:"schedule"`
+
+	// TimeZone is the name of the time zone, from the IANA Time
+	// Zone database (e.g. "America/New_York"), to evaluate Schedule
+	// in. If empty, the Ark server's local time zone is used, which
+	// is usually UTC. Optional.
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// NameTemplate is a Go template for the names of the Backups
+	// created by this schedule. The template can reference
+	// .ScheduleName, .ClusterName, .Timestamp, and the date
+	// components .Year, .Month, .Day, .Hour, .Minute, and .Second.
+	// It must include enough of the time to give each run a
+	// different name, or the schedule fails validation. If empty,
+	// Backups are named <schedule name>-<timestamp>.
+	NameTemplate string `json:"nameTemplate,omitempty"`
+
+	// ConcurrencyPolicy specifies how to handle a run that's due
+	// while a Backup created by an earlier run hasn't finished.
+	// Defaults to Allow.
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// Jitter is the maximum amount of time to delay each run past
+	// the time given by Schedule, so that many schedules with the same
+	// Cron expression don't all create Backups at once. Each schedule
+	// is delayed by the same amount every run, based on its name.
+	// Jitter should be shorter than the time between runs. Optional.
+	Jitter metav1.Duration `json:"jitter,omitempty"`
 }
 
+// ConcurrencyPolicy is a string representation of how an Ark
+// schedule handles a run that's due while its earlier Backups
+// haven't finished.
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyPolicyAllow means the schedule creates a Backup for
+	// every run, regardless of any unfinished ones.
+	ConcurrencyPolicyAllow ConcurrencyPolicy = "Allow"
+
+	// ConcurrencyPolicyForbid means the schedule skips a run if any
+	// of its Backups haven't finished.
+	ConcurrencyPolicyForbid ConcurrencyPolicy = "Forbid"
+)
+
 // SchedulePhase is a string representation of the lifecycle phase
 // of an Ark schedule
 type SchedulePhase string
@@ -56,6 +98,10 @@ type ScheduleStatus struct {
 	// Schedule schedule
 	LastBackup metav1.Time `json:"lastBackup"`
 
+	// LastSkipped is the last time a run of this Schedule was
+	// skipped because of its concurrency policy
+	LastSkipped metav1.Time `json:"lastSkipped,omitempty"`
+
 	// ValidationErrors is a slice of all validation errors (if
 	// applicable)
 	ValidationErrors []string `json:"validationErrors"`
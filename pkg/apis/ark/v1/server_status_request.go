@@ -0,0 +1,92 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// ServerStatusRequestSpec is the specification for a ServerStatusRequest.
+type ServerStatusRequestSpec struct {
+}
+
+// ServerStatusRequestPhase represents the lifecycle phase of a ServerStatusRequest.
+type ServerStatusRequestPhase string
+
+const (
+	// ServerStatusRequestPhaseNew means the ServerStatusRequest has not been processed by the
+	// ServerStatusRequestController yet.
+	ServerStatusRequestPhaseNew ServerStatusRequestPhase = "New"
+	// ServerStatusRequestPhaseProcessed means the ServerStatusRequest has been processed by the
+	// ServerStatusRequestController.
+	ServerStatusRequestPhaseProcessed ServerStatusRequestPhase = "Processed"
+)
+
+// OperationKind represents what type of operation the server is running.
+type OperationKind string
+
+const (
+	OperationKindBackup         OperationKind = "Backup"
+	OperationKindRestore        OperationKind = "Restore"
+	OperationKindBackupDeletion OperationKind = "BackupDeletion"
+)
+
+// Operation is a backup, restore, or backup deletion that the server is running.
+type Operation struct {
+	// Kind is the type of operation.
+	Kind OperationKind `json:"kind"`
+	// Namespace is the namespace of the Backup, Restore, or DeleteBackupRequest being processed.
+	Namespace string `json:"namespace"`
+	// Name is the name of the Backup, Restore, or backup being deleted.
+	Name string `json:"name"`
+	// StartTimestamp records the time the server started the operation.
+	StartTimestamp metav1.Time `json:"startTimestamp"`
+}
+
+// ServerStatusRequestStatus is the current status of a ServerStatusRequest.
+type ServerStatusRequestStatus struct {
+	// Phase is the current lifecycle phase of the ServerStatusRequest.
+	Phase ServerStatusRequestPhase `json:"phase"`
+	// ProcessedTimestamp is when the ServerStatusRequest was processed by the
+	// ServerStatusRequestController.
+	ProcessedTimestamp metav1.Time `json:"processedTimestamp"`
+	// ServerVersion is the Ark server version.
+	ServerVersion string `json:"serverVersion"`
+	// Operations are the backups, restores, and backup deletions in progress when the
+	// ServerStatusRequest was processed, oldest first.
+	Operations []Operation `json:"operations"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServerStatusRequest is a request to access current status information about
+// the Ark server, such as the operations it's running.
+type ServerStatusRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec   ServerStatusRequestSpec   `json:"spec"`
+	Status ServerStatusRequestStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServerStatusRequestList is a list of ServerStatusRequests.
+type ServerStatusRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []ServerStatusRequest `json:"items"`
+}
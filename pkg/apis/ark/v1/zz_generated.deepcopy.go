@@ -25,6 +25,38 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdditionalCluster) DeepCopyInto(out *AdditionalCluster) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalCluster.
+func (in *AdditionalCluster) DeepCopy() *AdditionalCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(AdditionalCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactDeletionResult) DeepCopyInto(out *ArtifactDeletionResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactDeletionResult.
+func (in *ArtifactDeletionResult) DeepCopy() *ArtifactDeletionResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactDeletionResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Backup) DeepCopyInto(out *Backup) {
 	*out = *in
@@ -63,6 +95,13 @@ func (in *BackupHooks) DeepCopyInto(out *BackupHooks) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ConsistencyGroups != nil {
+		in, out := &in.ConsistencyGroups, &out.ConsistencyGroups
+		*out = make([]ConsistencyGroupSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -121,6 +160,15 @@ func (in *BackupResourceHook) DeepCopyInto(out *BackupResourceHook) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.FSFreeze != nil {
+		in, out := &in.FSFreeze, &out.FSFreeze
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(FSFreezeHook)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -200,6 +248,37 @@ func (in *BackupResourceHookSpec) DeepCopy() *BackupResourceHookSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupSanitizer) DeepCopyInto(out *BackupSanitizer) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemoveFields != nil {
+		in, out := &in.RemoveFields, &out.RemoveFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemoveAnnotations != nil {
+		in, out := &in.RemoveAnnotations, &out.RemoveAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupSanitizer.
+func (in *BackupSanitizer) DeepCopy() *BackupSanitizer {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupSanitizer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
 	*out = *in
@@ -252,6 +331,24 @@ func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
 		}
 	}
 	in.Hooks.DeepCopyInto(&out.Hooks)
+	if in.IncludeArkResources != nil {
+		in, out := &in.IncludeArkResources, &out.IncludeArkResources
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.Quiesce != nil {
+		in, out := &in.Quiesce, &out.Quiesce
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(QuiesceSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -281,11 +378,51 @@ func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
 			}
 		}
 	}
+	if in.VolumeSnapshots != nil {
+		in, out := &in.VolumeSnapshots, &out.VolumeSnapshots
+		*out = make([]VolumeSnapshotStatus, len(*in))
+		copy(*out, *in)
+	}
 	if in.ValidationErrors != nil {
 		in, out := &in.ValidationErrors, &out.ValidationErrors
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ClusterInfo != nil {
+		in, out := &in.ClusterInfo, &out.ClusterInfo
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ClusterInfo)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.HookStatus != nil {
+		in, out := &in.HookStatus, &out.HookStatus
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(HookStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.LargeItems != nil {
+		in, out := &in.LargeItems, &out.LargeItems
+		*out = make([]LargeItem, len(*in))
+		copy(*out, *in)
+	}
+	if in.ItemCounts != nil {
+		in, out := &in.ItemCounts, &out.ItemCounts
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.QuiescedWorkloads != nil {
+		in, out := &in.QuiescedWorkloads, &out.QuiescedWorkloads
+		*out = make([]QuiescedWorkload, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -299,6 +436,23 @@ func (in *BackupStatus) DeepCopy() *BackupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStorageStatus) DeepCopyInto(out *BackupStorageStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageStatus.
+func (in *BackupStorageStatus) DeepCopy() *BackupStorageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStorageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudProviderConfig) DeepCopyInto(out *CloudProviderConfig) {
 	*out = *in
@@ -322,6 +476,83 @@ func (in *CloudProviderConfig) DeepCopy() *CloudProviderConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterInfo) DeepCopyInto(out *ClusterInfo) {
+	*out = *in
+	if in.APIResources != nil {
+		in, out := &in.APIResources, &out.APIResources
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				(*out)[key] = make([]string, len(val))
+				copy((*out)[key], val)
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInfo.
+func (in *ClusterInfo) DeepCopy() *ClusterInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDomainMapping) DeepCopyInto(out *ClusterDomainMapping) {
+	*out = *in
+	if in.ResourceFields != nil {
+		in, out := &in.ResourceFields, &out.ResourceFields
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				(*out)[key] = make([]string, len(val))
+				copy((*out)[key], val)
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDomainMapping.
+func (in *ClusterDomainMapping) DeepCopy() *ClusterDomainMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDomainMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CohabitatingResource) DeepCopyInto(out *CohabitatingResource) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CohabitatingResource.
+func (in *CohabitatingResource) DeepCopy() *CohabitatingResource {
+	if in == nil {
+		return nil
+	}
+	out := new(CohabitatingResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Config) DeepCopyInto(out *Config) {
 	*out = *in
@@ -340,11 +571,42 @@ func (in *Config) DeepCopyInto(out *Config) {
 	out.BackupSyncPeriod = in.BackupSyncPeriod
 	out.GCSyncPeriod = in.GCSyncPeriod
 	out.ScheduleSyncPeriod = in.ScheduleSyncPeriod
+	out.SnapshotSyncPeriod = in.SnapshotSyncPeriod
+	out.DiscoveryRefreshPeriod = in.DiscoveryRefreshPeriod
+	out.ClientRequestTimeout = in.ClientRequestTimeout
+	out.DefaultBackupTTL = in.DefaultBackupTTL
 	if in.ResourcePriorities != nil {
 		in, out := &in.ResourcePriorities, &out.ResourcePriorities
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RestoreAPIVersionCheckOverrides != nil {
+		in, out := &in.RestoreAPIVersionCheckOverrides, &out.RestoreAPIVersionCheckOverrides
+		*out = make(map[string]APIVersionCheckAction, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AdditionalClusters != nil {
+		in, out := &in.AdditionalClusters, &out.AdditionalClusters
+		*out = make([]AdditionalCluster, len(*in))
+		copy(*out, *in)
+	}
+	if in.CohabitatingResources != nil {
+		in, out := &in.CohabitatingResources, &out.CohabitatingResources
+		*out = make([]CohabitatingResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BackupSanitizers != nil {
+		in, out := &in.BackupSanitizers, &out.BackupSanitizers
+		*out = make([]BackupSanitizer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -399,6 +661,54 @@ func (in *ConfigList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigStatus) DeepCopyInto(out *ConfigStatus) {
+	*out = *in
+	in.BackupStorage.DeepCopyInto(&out.BackupStorage)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigStatus.
+func (in *ConfigStatus) DeepCopy() *ConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsistencyGroupSpec) DeepCopyInto(out *ConsistencyGroupSpec) {
+	*out = *in
+	if in.IncludedNamespaces != nil {
+		in, out := &in.IncludedNamespaces, &out.IncludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	in.FSFreeze.DeepCopyInto(&out.FSFreeze)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsistencyGroupSpec.
+func (in *ConsistencyGroupSpec) DeepCopy() *ConsistencyGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsistencyGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeleteBackupRequest) DeepCopyInto(out *DeleteBackupRequest) {
 	*out = *in
@@ -484,6 +794,11 @@ func (in *DeleteBackupRequestStatus) DeepCopyInto(out *DeleteBackupRequestStatus
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = make([]ArtifactDeletionResult, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -611,11 +926,21 @@ func (in *DownloadTarget) DeepCopy() *DownloadTarget {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExecHook) DeepCopyInto(out *ExecHook) {
 	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Command != nil {
 		in, out := &in.Command, &out.Command
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]HookEnvVar, len(*in))
+		copy(*out, *in)
+	}
 	out.Timeout = in.Timeout
 	return
 }
@@ -631,54 +956,210 @@ func (in *ExecHook) DeepCopy() *ExecHook {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ObjectStorageProviderConfig) DeepCopyInto(out *ObjectStorageProviderConfig) {
+func (in *FSFreezeHook) DeepCopyInto(out *FSFreezeHook) {
 	*out = *in
-	in.CloudProviderConfig.DeepCopyInto(&out.CloudProviderConfig)
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Timeout = in.Timeout
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageProviderConfig.
-func (in *ObjectStorageProviderConfig) DeepCopy() *ObjectStorageProviderConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FSFreezeHook.
+func (in *FSFreezeHook) DeepCopy() *FSFreezeHook {
 	if in == nil {
 		return nil
 	}
-	out := new(ObjectStorageProviderConfig)
+	out := new(FSFreezeHook)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Restore) DeepCopyInto(out *Restore) {
+func (in *HookEnvVar) DeepCopyInto(out *HookEnvVar) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Restore.
-func (in *Restore) DeepCopy() *Restore {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookEnvVar.
+func (in *HookEnvVar) DeepCopy() *HookEnvVar {
 	if in == nil {
 		return nil
 	}
-	out := new(Restore)
+	out := new(HookEnvVar)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Restore) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookExecution) DeepCopyInto(out *HookExecution) {
+	*out = *in
+	out.Duration = in.Duration
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookExecution.
+func (in *HookExecution) DeepCopy() *HookExecution {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(HookExecution)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RestoreList) DeepCopyInto(out *RestoreList) {
+func (in *HookStatus) DeepCopyInto(out *HookStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
+	if in.Executions != nil {
+		in, out := &in.Executions, &out.Executions
+		*out = make([]HookExecution, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookStatus.
+func (in *HookStatus) DeepCopy() *HookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LargeItem) DeepCopyInto(out *LargeItem) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LargeItem.
+func (in *LargeItem) DeepCopy() *LargeItem {
+	if in == nil {
+		return nil
+	}
+	out := new(LargeItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectStorageProviderConfig) DeepCopyInto(out *ObjectStorageProviderConfig) {
+	*out = *in
+	in.CloudProviderConfig.DeepCopyInto(&out.CloudProviderConfig)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectStorageProviderConfig.
+func (in *ObjectStorageProviderConfig) DeepCopy() *ObjectStorageProviderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStorageProviderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Operation) DeepCopyInto(out *Operation) {
+	*out = *in
+	in.StartTimestamp.DeepCopyInto(&out.StartTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Operation.
+func (in *Operation) DeepCopy() *Operation {
+	if in == nil {
+		return nil
+	}
+	out := new(Operation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuiesceSpec) DeepCopyInto(out *QuiesceSpec) {
+	*out = *in
+	if in.IncludedNamespaces != nil {
+		in, out := &in.IncludedNamespaces, &out.IncludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	out.Timeout = in.Timeout
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuiesceSpec.
+func (in *QuiesceSpec) DeepCopy() *QuiesceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QuiesceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuiescedWorkload) DeepCopyInto(out *QuiescedWorkload) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuiescedWorkload.
+func (in *QuiescedWorkload) DeepCopy() *QuiescedWorkload {
+	if in == nil {
+		return nil
+	}
+	out := new(QuiescedWorkload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Restore) DeepCopyInto(out *Restore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Restore.
+func (in *Restore) DeepCopy() *Restore {
+	if in == nil {
+		return nil
+	}
+	out := new(Restore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Restore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreList) DeepCopyInto(out *RestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
@@ -708,6 +1189,22 @@ func (in *RestoreList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestorePlanItem) DeepCopyInto(out *RestorePlanItem) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestorePlanItem.
+func (in *RestorePlanItem) DeepCopy() *RestorePlanItem {
+	if in == nil {
+		return nil
+	}
+	out := new(RestorePlanItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestoreResult) DeepCopyInto(out *RestoreResult) {
 	*out = *in
@@ -776,6 +1273,29 @@ func (in *RestoreSpec) DeepCopyInto(out *RestoreSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.AvailabilityZoneMapping != nil {
+		in, out := &in.AvailabilityZoneMapping, &out.AvailabilityZoneMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImageRegistryMapping != nil {
+		in, out := &in.ImageRegistryMapping, &out.ImageRegistryMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ClusterDomainMapping != nil {
+		in, out := &in.ClusterDomainMapping, &out.ClusterDomainMapping
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ClusterDomainMapping)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	if in.LabelSelector != nil {
 		in, out := &in.LabelSelector, &out.LabelSelector
 		if *in == nil {
@@ -803,6 +1323,42 @@ func (in *RestoreSpec) DeepCopyInto(out *RestoreSpec) {
 			**out = **in
 		}
 	}
+	if in.PreserveNodePorts != nil {
+		in, out := &in.PreserveNodePorts, &out.PreserveNodePorts
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.SkipCompletedJobs != nil {
+		in, out := &in.SkipCompletedJobs, &out.SkipCompletedJobs
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(bool)
+			**out = **in
+		}
+	}
+	if in.RestoreStatus != nil {
+		in, out := &in.RestoreStatus, &out.RestoreStatus
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RestoreStatusSpec)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RestoreVerifySpec)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -824,6 +1380,11 @@ func (in *RestoreStatus) DeepCopyInto(out *RestoreStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]WorkloadReadiness, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -837,6 +1398,49 @@ func (in *RestoreStatus) DeepCopy() *RestoreStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreStatusSpec) DeepCopyInto(out *RestoreStatusSpec) {
+	*out = *in
+	if in.IncludedResources != nil {
+		in, out := &in.IncludedResources, &out.IncludedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedResources != nil {
+		in, out := &in.ExcludedResources, &out.ExcludedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreStatusSpec.
+func (in *RestoreStatusSpec) DeepCopy() *RestoreStatusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreStatusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreVerifySpec) DeepCopyInto(out *RestoreVerifySpec) {
+	*out = *in
+	out.Timeout = in.Timeout
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerifySpec.
+func (in *RestoreVerifySpec) DeepCopy() *RestoreVerifySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreVerifySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Schedule) DeepCopyInto(out *Schedule) {
 	*out = *in
@@ -902,6 +1506,7 @@ func (in *ScheduleList) DeepCopyObject() runtime.Object {
 func (in *ScheduleSpec) DeepCopyInto(out *ScheduleSpec) {
 	*out = *in
 	in.Template.DeepCopyInto(&out.Template)
+	out.Jitter = in.Jitter
 	return
 }
 
@@ -919,6 +1524,7 @@ func (in *ScheduleSpec) DeepCopy() *ScheduleSpec {
 func (in *ScheduleStatus) DeepCopyInto(out *ScheduleStatus) {
 	*out = *in
 	in.LastBackup.DeepCopyInto(&out.LastBackup)
+	in.LastSkipped.DeepCopyInto(&out.LastSkipped)
 	if in.ValidationErrors != nil {
 		in, out := &in.ValidationErrors, &out.ValidationErrors
 		*out = make([]string, len(*in))
@@ -937,6 +1543,107 @@ func (in *ScheduleStatus) DeepCopy() *ScheduleStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerStatusRequest) DeepCopyInto(out *ServerStatusRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerStatusRequest.
+func (in *ServerStatusRequest) DeepCopy() *ServerStatusRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerStatusRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ServerStatusRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerStatusRequestList) DeepCopyInto(out *ServerStatusRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ServerStatusRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerStatusRequestList.
+func (in *ServerStatusRequestList) DeepCopy() *ServerStatusRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerStatusRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ServerStatusRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerStatusRequestSpec) DeepCopyInto(out *ServerStatusRequestSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerStatusRequestSpec.
+func (in *ServerStatusRequestSpec) DeepCopy() *ServerStatusRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerStatusRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerStatusRequestStatus) DeepCopyInto(out *ServerStatusRequestStatus) {
+	*out = *in
+	in.ProcessedTimestamp.DeepCopyInto(&out.ProcessedTimestamp)
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]Operation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerStatusRequestStatus.
+func (in *ServerStatusRequestStatus) DeepCopy() *ServerStatusRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerStatusRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeBackupInfo) DeepCopyInto(out *VolumeBackupInfo) {
 	*out = *in
@@ -961,3 +1668,35 @@ func (in *VolumeBackupInfo) DeepCopy() *VolumeBackupInfo {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSnapshotStatus) DeepCopyInto(out *VolumeSnapshotStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSnapshotStatus.
+func (in *VolumeSnapshotStatus) DeepCopy() *VolumeSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadReadiness) DeepCopyInto(out *WorkloadReadiness) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadReadiness.
+func (in *WorkloadReadiness) DeepCopy() *WorkloadReadiness {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadReadiness)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit records the destructive operations Ark performs, such as deleting backups and
+// volume snapshots or creating resources during a restore, for compliance review.
+package audit
+
+import "time"
+
+// Action is the kind of change an audited operation made.
+type Action string
+
+const (
+	// ActionDelete is the Action for deleting something.
+	ActionDelete Action = "Delete"
+
+	// ActionCreate is the Action for creating something.
+	ActionCreate Action = "Create"
+)
+
+const (
+	// KindBackupStorage is the Kind of an Entry for a backup's directory in object storage.
+	// The Entry's Name is the backup's name.
+	KindBackupStorage = "BackupStorage"
+
+	// KindVolumeSnapshot is the Kind of an Entry for a volume snapshot. The Entry's Name is
+	// the snapshot's ID.
+	KindVolumeSnapshot = "VolumeSnapshot"
+)
+
+// Entry is a single audited operation.
+type Entry struct {
+	// Time is when the operation was performed. If it's zero when the Entry is recorded, it's
+	// set to the time it was recorded.
+	Time time.Time `json:"time"`
+
+	// Action is the kind of change the operation made.
+	Action Action `json:"action"`
+
+	// Kind is what was changed: KindBackupStorage, KindVolumeSnapshot, or the group-resource
+	// (e.g. "deployments.apps") of a Kubernetes resource.
+	Kind string `json:"kind"`
+
+	// Namespace is the namespace of a namespaced Kubernetes resource.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name identifies what was changed.
+	Name string `json:"name"`
+
+	// Backup is the name of the backup the operation was performed for, if any.
+	Backup string `json:"backup,omitempty"`
+
+	// Restore is the name of the restore the operation was performed for, if any.
+	Restore string `json:"restore,omitempty"`
+
+	// Error is the error the operation failed with, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Log records audited operations. Recording an Entry never fails; implementations that
+// can't record an Entry log the error.
+type Log interface {
+	Record(entry Entry)
+}
+
+// WithError returns a copy of e recording that its operation failed with err, if err isn't
+// nil.
+func (e Entry) WithError(err error) Entry {
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
+
+type multiLog []Log
+
+// NewMultiLog returns a Log that records each Entry to all of logs.
+func NewMultiLog(logs ...Log) Log {
+	return multiLog(logs)
+}
+
+func (l multiLog) Record(entry Entry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	for _, log := range l {
+		log.Record(entry)
+	}
+}
+
+type nopLog struct{}
+
+// NewNopLog returns a Log that discards every Entry.
+func NewNopLog() Log {
+	return nopLog{}
+}
+
+func (nopLog) Record(Entry) {}
@@ -0,0 +1,107 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+const eventSource = "ark-server"
+
+type eventLog struct {
+	client    corev1.EventsGetter
+	namespace string
+	logger    logrus.FieldLogger
+	clock     clock.Clock
+}
+
+// NewEventLog returns a Log that records each Entry as a Kubernetes Event on the Restore or
+// Backup in namespace that its operation was performed for. Entries that weren't performed
+// for a Restore or Backup, such as deletions of orphaned volume snapshots, aren't recorded.
+func NewEventLog(client corev1.EventsGetter, namespace string, logger logrus.FieldLogger) Log {
+	return &eventLog{
+		client:    client,
+		namespace: namespace,
+		logger:    logger,
+		clock:     &clock.RealClock{},
+	}
+}
+
+func (l *eventLog) Record(entry Entry) {
+	var involvedObject v1.ObjectReference
+	switch {
+	case entry.Restore != "":
+		involvedObject = v1.ObjectReference{Kind: "Restore", Name: entry.Restore}
+	case entry.Backup != "":
+		involvedObject = v1.ObjectReference{Kind: "Backup", Name: entry.Backup}
+	default:
+		return
+	}
+	involvedObject.APIVersion = api.SchemeGroupVersion.String()
+	involvedObject.Namespace = l.namespace
+
+	if entry.Time.IsZero() {
+		entry.Time = l.clock.Now()
+	}
+
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: l.namespace,
+			// the same naming scheme as client-go's event recorder
+			Name: fmt.Sprintf("%v.%x", involvedObject.Name, entry.Time.UnixNano()),
+		},
+		InvolvedObject: involvedObject,
+		Reason:         string(entry.Action),
+		Message:        eventMessage(entry),
+		Source:         v1.EventSource{Component: eventSource},
+		FirstTimestamp: metav1.NewTime(entry.Time),
+		LastTimestamp:  metav1.NewTime(entry.Time),
+		Count:          1,
+		Type:           v1.EventTypeNormal,
+	}
+	if entry.Error != "" {
+		event.Type = v1.EventTypeWarning
+	}
+
+	if _, err := l.client.Events(l.namespace).Create(event); err != nil {
+		l.logger.WithError(err).WithField("event", event.Name).Error("Error creating audit event")
+	}
+}
+
+// eventMessage returns a description of entry's operation, e.g. "Delete VolumeSnapshot snap-1".
+func eventMessage(entry Entry) string {
+	name := entry.Name
+	if entry.Namespace != "" {
+		name = entry.Namespace + "/" + name
+	}
+
+	msg := strings.Join([]string{string(entry.Action), entry.Kind, name}, " ")
+	if entry.Error != "" {
+		msg += " failed: " + entry.Error
+	}
+	return msg
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/api/core/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+type fakeEventClient struct {
+	events []*v1.Event
+
+	corev1.EventsGetter
+	corev1.EventInterface
+}
+
+func (c *fakeEventClient) Events(namespace string) corev1.EventInterface {
+	return c
+}
+
+func (c *fakeEventClient) Create(event *v1.Event) (*v1.Event, error) {
+	c.events = append(c.events, event)
+	return event, nil
+}
+
+func TestEventLog(t *testing.T) {
+	client := &fakeEventClient{}
+	log := NewEventLog(client, "heptio-ark", logrus.New())
+
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// orphans don't belong to a backup or restore, so they don't get events
+	log.Record(Entry{Time: now, Action: ActionDelete, Kind: KindVolumeSnapshot, Name: "snap-1"})
+	assert.Empty(t, client.events)
+
+	log.Record(Entry{Time: now, Action: ActionDelete, Kind: KindVolumeSnapshot, Name: "snap-2", Backup: "backup-1"})
+	log.Record(Entry{Time: now, Action: ActionCreate, Kind: "pods", Namespace: "ns-1", Name: "pod-1", Backup: "backup-1", Restore: "restore-1", Error: "oops"})
+	require.Len(t, client.events, 2)
+
+	event := client.events[0]
+	assert.Equal(t, "heptio-ark", event.Namespace)
+	assert.Equal(t, v1.ObjectReference{Kind: "Backup", APIVersion: "ark.heptio.com/v1", Namespace: "heptio-ark", Name: "backup-1"}, event.InvolvedObject)
+	assert.Equal(t, "Delete", event.Reason)
+	assert.Equal(t, "Delete VolumeSnapshot snap-2", event.Message)
+	assert.Equal(t, v1.EventTypeNormal, event.Type)
+
+	event = client.events[1]
+	assert.Equal(t, v1.ObjectReference{Kind: "Restore", APIVersion: "ark.heptio.com/v1", Namespace: "heptio-ark", Name: "restore-1"}, event.InvolvedObject)
+	assert.Equal(t, "Create pods ns-1/pod-1 failed: oops", event.Message)
+	assert.Equal(t, v1.EventTypeWarning, event.Type)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// maxBufferedEntries is the most entries an ObjectStoreLog keeps between successful flushes.
+const maxBufferedEntries = 10000
+
+// Uploader knows how to upload audit log files to object storage.
+type Uploader interface {
+	UploadAuditLog(bucket, name string, log io.Reader) error
+}
+
+// ObjectStoreLog is a Log that buffers entries in memory, and uploads them to object storage
+// when flushed, as a gzipped file containing one JSON-encoded Entry per line. Each flush
+// uploads a new file named after the time it was flushed. If uploads keep failing, entries
+// recorded once the buffer is full are dropped, and counted, rather than kept in memory
+// indefinitely.
+type ObjectStoreLog struct {
+	uploader   Uploader
+	bucket     string
+	logger     logrus.FieldLogger
+	clock      clock.Clock
+	maxEntries int
+
+	lock    sync.Mutex
+	entries []Entry
+	// dropped is the number of entries dropped since the last flush that reported them.
+	dropped int
+}
+
+// NewObjectStoreLog returns an ObjectStoreLog that uploads to bucket using uploader.
+func NewObjectStoreLog(uploader Uploader, bucket string, logger logrus.FieldLogger) *ObjectStoreLog {
+	return &ObjectStoreLog{
+		uploader: uploader,
+		bucket:   bucket,
+		logger:   logger.WithField("bucket", bucket),
+		clock:    &clock.RealClock{},
+
+		maxEntries: maxBufferedEntries,
+	}
+}
+
+func (l *ObjectStoreLog) Record(entry Entry) {
+	if entry.Time.IsZero() {
+		entry.Time = l.clock.Now()
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if len(l.entries) >= l.maxEntries {
+		l.dropped++
+		return
+	}
+	l.entries = append(l.entries, entry)
+}
+
+// Flush uploads the entries recorded since the last successful flush. If the upload fails,
+// the entries are kept, up to the buffer's limit, so the next flush can retry them.
+func (l *ObjectStoreLog) Flush() error {
+	l.lock.Lock()
+	entries := l.entries
+	dropped := l.dropped
+	l.entries = nil
+	l.dropped = 0
+	l.lock.Unlock()
+
+	if dropped > 0 {
+		l.logger.WithField("dropped", dropped).Error("Audit log entries were dropped because the audit log couldn't be uploaded")
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	buf := new(bytes.Buffer)
+	gzw := gzip.NewWriter(buf)
+	encoder := json.NewEncoder(gzw)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return errors.Wrap(err, "error encoding audit log entry")
+		}
+	}
+	if err := gzw.Close(); err != nil {
+		return errors.Wrap(err, "error compressing audit log")
+	}
+
+	name := l.clock.Now().UTC().Format("20060102T150405.000Z")
+	if err := l.uploader.UploadAuditLog(l.bucket, name, buf); err != nil {
+		l.lock.Lock()
+		l.entries = append(entries, l.entries...)
+		if len(l.entries) > l.maxEntries {
+			l.dropped += len(l.entries) - l.maxEntries
+			l.entries = l.entries[:l.maxEntries]
+		}
+		l.lock.Unlock()
+
+		return errors.Wrap(err, "error uploading audit log")
+	}
+
+	return nil
+}
+
+// Run flushes the log every period until ctx is done, then flushes it one last time.
+func (l *ObjectStoreLog) Run(ctx context.Context, period time.Duration) {
+	flush := func() {
+		if err := l.Flush(); err != nil {
+			l.logger.WithError(err).Error("Error flushing audit log")
+		}
+	}
+
+	wait.Until(flush, period, ctx.Done())
+	flush()
+}
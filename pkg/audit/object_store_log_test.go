@@ -0,0 +1,140 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+type fakeUploader struct {
+	err     error
+	uploads map[string][]byte
+}
+
+func (u *fakeUploader) UploadAuditLog(bucket, name string, log io.Reader) error {
+	if u.err != nil {
+		return u.err
+	}
+
+	data, err := ioutil.ReadAll(log)
+	if err != nil {
+		return err
+	}
+	u.uploads[bucket+"/"+name] = data
+	return nil
+}
+
+// decodeEntries decodes a gzipped audit log file.
+func decodeEntries(t *testing.T, data []byte) []Entry {
+	gzr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	var entries []Entry
+	scanner := bufio.NewScanner(gzr)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+
+	return entries
+}
+
+func TestObjectStoreLogFlush(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	uploader := &fakeUploader{uploads: make(map[string][]byte)}
+
+	log := NewObjectStoreLog(uploader, "bucket", logrus.New())
+	log.clock = clock.NewFakeClock(now)
+
+	// nothing is uploaded when there aren't any entries
+	require.NoError(t, log.Flush())
+	assert.Empty(t, uploader.uploads)
+
+	earlier := now.Add(-time.Minute)
+	log.Record(Entry{Time: earlier, Action: ActionDelete, Kind: KindVolumeSnapshot, Name: "snap-1", Backup: "backup-1"})
+	log.Record(Entry{Action: ActionCreate, Kind: "pods", Namespace: "ns-1", Name: "pod-1", Restore: "restore-1"}.WithError(errors.New("oops")))
+
+	// a failed upload keeps the entries for the next flush
+	uploader.err = errors.New("upload failed")
+	assert.EqualError(t, log.Flush(), "error uploading audit log: upload failed")
+	assert.Empty(t, uploader.uploads)
+
+	uploader.err = nil
+	require.NoError(t, log.Flush())
+	require.Contains(t, uploader.uploads, "bucket/20180601T120000.000Z")
+
+	entries := decodeEntries(t, uploader.uploads["bucket/20180601T120000.000Z"])
+	require.Len(t, entries, 2)
+	assert.True(t, earlier.Equal(entries[0].Time))
+	assert.Equal(t, "snap-1", entries[0].Name)
+	assert.Equal(t, "backup-1", entries[0].Backup)
+	assert.True(t, now.Equal(entries[1].Time))
+	assert.Equal(t, "ns-1", entries[1].Namespace)
+	assert.Equal(t, "oops", entries[1].Error)
+
+	// flushed entries aren't uploaded again
+	delete(uploader.uploads, "bucket/20180601T120000.000Z")
+	require.NoError(t, log.Flush())
+	assert.Empty(t, uploader.uploads)
+}
+
+func TestObjectStoreLogDropsEntriesWhenFull(t *testing.T) {
+	uploader := &fakeUploader{uploads: make(map[string][]byte), err: errors.New("upload failed")}
+
+	log := NewObjectStoreLog(uploader, "bucket", logrus.New())
+	log.clock = clock.NewFakeClock(time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC))
+	log.maxEntries = 2
+
+	log.Record(Entry{Name: "1"})
+	log.Record(Entry{Name: "2"})
+	log.Record(Entry{Name: "3"})
+	assert.Len(t, log.entries, 2)
+	assert.Equal(t, 1, log.dropped)
+
+	// the dropped count is reported, and reset, by the next flush
+	assert.Error(t, log.Flush())
+	assert.Len(t, log.entries, 2)
+	assert.Equal(t, 0, log.dropped)
+
+	log.Record(Entry{Name: "4"})
+	assert.Equal(t, 1, log.dropped)
+
+	uploader.err = nil
+	require.NoError(t, log.Flush())
+
+	entries := decodeEntries(t, uploader.uploads["bucket/20180601T120000.000Z"])
+	require.Len(t, entries, 2)
+	assert.Equal(t, "1", entries[0].Name)
+	assert.Equal(t, "2", entries[1].Name)
+	assert.Equal(t, 0, log.dropped)
+}
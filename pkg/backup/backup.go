@@ -21,19 +21,25 @@ import (
 	"compress/gzip"
 	"fmt"
 	"io"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	kuberrs "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/kuberesource"
 	"github.com/heptio/ark/pkg/util/collections"
 	kubeutil "github.com/heptio/ark/pkg/util/kube"
 	"github.com/heptio/ark/pkg/util/logging"
@@ -44,6 +50,11 @@ type Backupper interface {
 	// Backup takes a backup using the specification in the api.Backup and writes backup and log data
 	// to the given writers.
 	Backup(backup *api.Backup, backupFile, logFile io.Writer, actions []ItemAction) error
+
+	// UnquiesceInterrupted scales back up the workloads that were left scaled down by backups
+	// that were interrupted while they were quiesced. It must not be called while any backups
+	// are running.
+	UnquiesceInterrupted(log logrus.FieldLogger) error
 }
 
 // kubernetesBackupper implements Backupper.
@@ -53,6 +64,91 @@ type kubernetesBackupper struct {
 	podCommandExecutor    podCommandExecutor
 	groupBackupperFactory groupBackupperFactory
 	snapshotService       cloudprovider.SnapshotService
+	additionalClusters    []*AdditionalCluster
+	cohabitatingResources []api.CohabitatingResource
+	sanitizers            []api.BackupSanitizer
+}
+
+// Cluster is a connection to an additional cluster.
+type Cluster struct {
+	name               string
+	discoveryHelper    discovery.Helper
+	dynamicFactory     client.DynamicFactory
+	podCommandExecutor podCommandExecutor
+}
+
+// NewCluster creates a new Cluster. Its resources are written to the backup
+// tarball under clusters/<name>/.
+func NewCluster(
+	name string,
+	discoveryHelper discovery.Helper,
+	dynamicFactory client.DynamicFactory,
+	podCommandExecutor podCommandExecutor,
+) Cluster {
+	return Cluster{
+		name:               name,
+		discoveryHelper:    discoveryHelper,
+		dynamicFactory:     dynamicFactory,
+		podCommandExecutor: podCommandExecutor,
+	}
+}
+
+// AdditionalCluster is a cluster, other than the one Ark is running in, whose
+// resources are included in every backup. It's connected to when it's first
+// backed up rather than when the server starts, so a cluster that's unreachable
+// for a while is still backed up once it can be reached.
+type AdditionalCluster struct {
+	name    string
+	connect func() (Cluster, error)
+	backoff wait.Backoff
+
+	lock    sync.Mutex
+	cluster *Cluster
+}
+
+// NewAdditionalCluster creates a new AdditionalCluster that uses connect to
+// connect to the cluster.
+func NewAdditionalCluster(name string, connect func() (Cluster, error)) *AdditionalCluster {
+	return &AdditionalCluster{
+		name:    name,
+		connect: connect,
+		backoff: wait.Backoff{
+			Duration: 2 * time.Second,
+			Factor:   2,
+			Steps:    3,
+		},
+	}
+}
+
+// Connect returns the cluster, connecting to it if that hasn't already been done
+// successfully. Connecting is retried a few times before an error is returned.
+func (c *AdditionalCluster) Connect(log logrus.FieldLogger) (Cluster, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.cluster != nil {
+		return *c.cluster, nil
+	}
+
+	var lastErr error
+	err := wait.ExponentialBackoff(c.backoff, func() (bool, error) {
+		cluster, err := c.connect()
+		if err != nil {
+			log.WithError(err).Warn("Error connecting to additional cluster")
+			lastErr = err
+			return false, nil
+		}
+		c.cluster = &cluster
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		err = lastErr
+	}
+	if err != nil {
+		return Cluster{}, errors.WithMessage(err, "error connecting to cluster "+c.name)
+	}
+
+	return *c.cluster, nil
 }
 
 type itemKey struct {
@@ -67,28 +163,33 @@ type resolvedAction struct {
 	resourceIncludesExcludes  *collections.IncludesExcludes
 	namespaceIncludesExcludes *collections.IncludesExcludes
 	selector                  labels.Selector
+	fieldSelector             fields.Selector
 }
 
 func (i *itemKey) String() string {
 	return fmt.Sprintf("resource=%s,namespace=%s,name=%s", i.resource, i.namespace, i.name)
 }
 
-func cohabitatingResources() map[string]*cohabitatingResource {
-	return map[string]*cohabitatingResource{
-		"deployments":     newCohabitatingResource("deployments", "extensions", "apps"),
-		"daemonsets":      newCohabitatingResource("daemonsets", "extensions", "apps"),
-		"replicasets":     newCohabitatingResource("replicasets", "extensions", "apps"),
-		"networkpolicies": newCohabitatingResource("networkpolicies", "extensions", "networking.k8s.io"),
-		"events":          newCohabitatingResource("events", "", "events.k8s.io"),
+// cohabitatingResources returns a new map of resources, keyed by name, for tracking which
+// cohabitating resources a backup has processed.
+func cohabitatingResources(resources []api.CohabitatingResource) map[string]*cohabitatingResource {
+	res := make(map[string]*cohabitatingResource, len(resources))
+	for _, resource := range resources {
+		res[resource.Resource] = newCohabitatingResource(resource.Resource, resource.Groups...)
 	}
+	return res
 }
 
-// NewKubernetesBackupper creates a new kubernetesBackupper.
+// NewKubernetesBackupper creates a new kubernetesBackupper. Only one group's copy of each of
+// the built-in cohabitating resources and additionalCohabitatingResources is backed up.
 func NewKubernetesBackupper(
 	discoveryHelper discovery.Helper,
 	dynamicFactory client.DynamicFactory,
 	podCommandExecutor podCommandExecutor,
 	snapshotService cloudprovider.SnapshotService,
+	additionalClusters []*AdditionalCluster,
+	additionalCohabitatingResources []api.CohabitatingResource,
+	sanitizers []api.BackupSanitizer,
 ) (Backupper, error) {
 	return &kubernetesBackupper{
 		discoveryHelper:       discoveryHelper,
@@ -96,6 +197,9 @@ func NewKubernetesBackupper(
 		podCommandExecutor:    podCommandExecutor,
 		groupBackupperFactory: &defaultGroupBackupperFactory{},
 		snapshotService:       snapshotService,
+		additionalClusters:    additionalClusters,
+		cohabitatingResources: kuberesource.CohabitatingResources(additionalCohabitatingResources),
+		sanitizers:            sanitizers,
 	}, nil
 }
 
@@ -118,11 +222,19 @@ func resolveActions(actions []ItemAction, helper discovery.Helper) ([]resolvedAc
 			}
 		}
 
+		fieldSelector := fields.Everything()
+		if resourceSelector.FieldSelector != "" {
+			if fieldSelector, err = fields.ParseSelector(resourceSelector.FieldSelector); err != nil {
+				return nil, err
+			}
+		}
+
 		res := resolvedAction{
 			ItemAction:                action,
 			resourceIncludesExcludes:  resources,
 			namespaceIncludesExcludes: namespaces,
 			selector:                  selector,
+			fieldSelector:             fieldSelector,
 		}
 
 		resolved = append(resolved, res)
@@ -139,7 +251,7 @@ func getResourceIncludesExcludes(helper discovery.Helper, includes, excludes []s
 		includes,
 		excludes,
 		func(item string) string {
-			gvr, _, err := helper.ResourceFor(schema.ParseGroupResource(item).WithVersion(""))
+			gvr, _, err := discovery.ResourceForWithRefresh(helper, schema.ParseGroupResource(item).WithVersion(""))
 			if err != nil {
 				return ""
 			}
@@ -202,13 +314,16 @@ func getResourceHook(hookSpec api.BackupResourceHookSpec, discoveryHelper discov
 
 // Backup backs up the items specified in the Backup, placing them in a gzip-compressed tar file
 // written to backupFile. The finalized api.Backup is written to metadata.
+// UnquiesceInterrupted scales back up the workloads that still have the original replicas
+// annotation in the cluster Ark is running in.
+func (kb *kubernetesBackupper) UnquiesceInterrupted(log logrus.FieldLogger) error {
+	return newQuiescer(log, &api.Backup{}, kb.discoveryHelper, kb.dynamicFactory).unquiesceInterrupted()
+}
+
 func (kb *kubernetesBackupper) Backup(backup *api.Backup, backupFile, logFile io.Writer, actions []ItemAction) error {
 	gzippedData := gzip.NewWriter(backupFile)
 	defer gzippedData.Close()
 
-	tw := tar.NewWriter(gzippedData)
-	defer tw.Close()
-
 	gzippedLog := gzip.NewWriter(logFile)
 	defer gzippedLog.Close()
 
@@ -216,20 +331,147 @@ func (kb *kubernetesBackupper) Backup(backup *api.Backup, backupFile, logFile io
 	logger.Out = gzippedLog
 	logger.Hooks.Add(&logging.ErrorLocationHook{})
 	logger.Hooks.Add(&logging.LogLocationHook{})
-	log := logger.WithField("backup", kubeutil.NamespaceAndName(backup))
+	logCounter := logging.NewLogCounterHook()
+	logger.Hooks.Add(logCounter)
+	log := logger.WithFields(logrus.Fields{
+		"backup":                 kubeutil.NamespaceAndName(backup),
+		logging.OperationIDField: backup.Status.OperationID,
+	})
 	log.Info("Starting backup")
 
+	// record the format the backup's contents are stored in, which versions of
+	// Ark that can't read it check for before using the backup
+	backup.Status.Version = FormatVersion(backup)
+
+	var tw tarWriter = tar.NewWriter(gzippedData)
+	podCommandExecutor := kb.podCommandExecutor
+	snapshotService := kb.snapshotService
+
+	if backup.Spec.DryRun {
+		log.Info("Dry run: items will be listed but not written, hooks won't be executed, and volumes won't be snapshotted")
+		tw = newDryRunTarWriter(log)
+		podCommandExecutor = &dryRunPodCommandExecutor{}
+		snapshotService = nil
+	}
+	defer tw.Close()
+
+	// the number of items of each resource is recorded, so that restores can check the API
+	// versions of just the resources the backup contains
+	tw = newItemCountingTarWriter(tw, backup)
+
+	var errs []error
+
+	// quiesced workloads are scaled down before anything is backed up, and scaled back up as soon
+	// as the cluster Ark is running in has been backed up.
+	var workloadQuiescer *quiescer
+	if backup.Spec.Quiesce != nil {
+		if backup.Spec.DryRun {
+			log.Info("Dry run: workloads won't be quiesced")
+		} else {
+			workloadQuiescer = newQuiescer(log, backup, kb.discoveryHelper, kb.dynamicFactory)
+			// unquiesce does nothing if the workloads were already scaled back up below, so this
+			// only matters if the backup panics first
+			defer workloadQuiescer.unquiesce()
+			if err := workloadQuiescer.quiesce(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	// each consistency group's volumes are frozen and snapshotted together before anything else
+	// is backed up; their PVs are skipped when they're backed up along with the rest of the cluster.
+	if len(backup.Spec.Hooks.ConsistencyGroups) > 0 && !abortBackup(backup, errs) {
+		if snapshotService == nil || (backup.Spec.SnapshotVolumes != nil && !*backup.Spec.SnapshotVolumes) {
+			log.Info("Skipping consistency groups because volume snapshots aren't being taken")
+		} else if err := newConsistencyGroupBackupper(log, backup, kb.discoveryHelper, kb.dynamicFactory, podCommandExecutor, snapshotService).backupGroups(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if !abortBackup(backup, errs) {
+		if err := kb.backupCluster(log, backup, tw, kb.discoveryHelper, kb.dynamicFactory, podCommandExecutor, snapshotService, actions, includeArkResources(backup)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if workloadQuiescer != nil {
+		if err := workloadQuiescer.unquiesce(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, cluster := range kb.additionalClusters {
+		if abortBackup(backup, errs) {
+			break
+		}
+
+		clusterLog := log.WithField("cluster", cluster.name)
+
+		// a cluster that can't be reached is recorded as an error on the backup
+		// rather than leaving its resources out silently
+		connected, err := cluster.Connect(clusterLog)
+		if err != nil {
+			clusterLog.WithError(err).Error("Error connecting to additional cluster; the backup will not include its resources")
+			errs = append(errs, err)
+			continue
+		}
+
+		clusterLog.Info("Backing up additional cluster")
+
+		// volume snapshots are only taken in the cluster Ark is running in, since
+		// the snapshot service is configured for that cluster's cloud provider.
+		clusterTarWriter := &prefixedTarWriter{tarWriter: tw, prefix: filepath.Join(api.ClustersDir, cluster.name)}
+		clusterPodCommandExecutor := connected.podCommandExecutor
+		if backup.Spec.DryRun {
+			clusterPodCommandExecutor = &dryRunPodCommandExecutor{}
+		}
+		if err := kb.backupCluster(clusterLog, backup, clusterTarWriter, connected.discoveryHelper, connected.dynamicFactory, clusterPodCommandExecutor, nil, actions, false); err != nil {
+			errs = append(errs, errors.Wrapf(err, "error backing up cluster %s", cluster.name))
+		}
+	}
+
+	err := kuberrs.Flatten(kuberrs.NewAggregate(errs))
+	if err == nil {
+		log.Infof("Backup completed successfully")
+	} else if abortBackup(backup, errs) {
+		log.Infof("Backup stopped at its first error because its failure policy is %s: %v", backup.Spec.FailurePolicy, err)
+	} else {
+		log.Infof("Backup completed with errors: %v", err)
+	}
+
+	backup.Status.Warnings = logCounter.GetCount(logrus.WarnLevel)
+	if err != nil {
+		backup.Status.Errors = len(err.Errors())
+	}
+
+	return err
+}
+
+// backupCluster backs up the items specified in the Backup from a single cluster, using the
+// given clients, to tw. If arkResources is true, Ark's own Configs and Schedules in the
+// backup's namespace are also backed up.
+func (kb *kubernetesBackupper) backupCluster(
+	log logrus.FieldLogger,
+	backup *api.Backup,
+	tw tarWriter,
+	discoveryHelper discovery.Helper,
+	dynamicFactory client.DynamicFactory,
+	podCommandExecutor podCommandExecutor,
+	snapshotService cloudprovider.SnapshotService,
+	actions []ItemAction,
+	arkResources bool,
+) error {
 	namespaceIncludesExcludes := getNamespaceIncludesExcludes(backup)
 	log.Infof("Including namespaces: %s", namespaceIncludesExcludes.IncludesString())
 	log.Infof("Excluding namespaces: %s", namespaceIncludesExcludes.ExcludesString())
 
-	resourceIncludesExcludes := getResourceIncludesExcludes(kb.discoveryHelper, backup.Spec.IncludedResources, backup.Spec.ExcludedResources)
+	resourceIncludesExcludes := getResourceIncludesExcludes(discoveryHelper, backup.Spec.IncludedResources, backup.Spec.ExcludedResources)
 	log.Infof("Including resources: %s", resourceIncludesExcludes.IncludesString())
 	log.Infof("Excluding resources: %s", resourceIncludesExcludes.ExcludesString())
 
-	resourceHooks, err := getResourceHooks(backup.Spec.Hooks.Resources, kb.discoveryHelper)
+	resourceHooks, err := getResourceHooks(backup.Spec.Hooks.Resources, discoveryHelper)
 	if err != nil {
-		return err
+		return setupError{err}
 	}
 
 	var labelSelector string
@@ -240,9 +482,29 @@ func (kb *kubernetesBackupper) Backup(backup *api.Backup, backupFile, logFile io
 	backedUpItems := make(map[itemKey]struct{})
 	var errs []error
 
-	resolvedActions, err := resolveActions(actions, kb.discoveryHelper)
+	// When cluster-scoped resources are included automatically and only specific namespaces
+	// are backed up, cluster-scoped resources are only backed up as dependencies of the
+	// namespaced items that are (e.g. PVC->PV). Custom resources depend on their CRDs.
+	if backup.Spec.IncludeClusterResources == nil && !namespaceIncludesExcludes.IncludeEverything() {
+		actions = append([]ItemAction{newCustomResourceDefinitionAction(log, discoveryHelper, dynamicFactory)}, actions...)
+	}
+
+	// set the backup's log on plugin actions before resolving them so
+	// anything they log from AppliesTo ends up in it too
+	for _, action := range actions {
+		if logSetter, ok := action.(logging.LogSetter); ok {
+			logSetter.SetLog(log)
+		}
+	}
+
+	resolvedActions, err := resolveActions(actions, discoveryHelper)
+	if err != nil {
+		return setupError{err}
+	}
+
+	resolvedSanitizers, err := resolveSanitizers(kb.sanitizers, discoveryHelper)
 	if err != nil {
-		return err
+		return setupError{err}
 	}
 
 	gb := kb.groupBackupperFactory.newGroupBackupper(
@@ -251,31 +513,131 @@ func (kb *kubernetesBackupper) Backup(backup *api.Backup, backupFile, logFile io
 		namespaceIncludesExcludes,
 		resourceIncludesExcludes,
 		labelSelector,
-		kb.dynamicFactory,
-		kb.discoveryHelper,
+		dynamicFactory,
+		discoveryHelper,
 		backedUpItems,
-		cohabitatingResources(),
+		cohabitatingResources(kb.cohabitatingResources),
 		resolvedActions,
-		kb.podCommandExecutor,
+		resolvedSanitizers,
+		podCommandExecutor,
 		tw,
 		resourceHooks,
-		kb.snapshotService,
+		snapshotService,
 	)
 
-	for _, group := range kb.discoveryHelper.Resources() {
+	for _, group := range discoveryHelper.Resources() {
+		if abortBackup(backup, errs) {
+			break
+		}
+
 		if err := gb.backupGroup(group); err != nil {
 			errs = append(errs, err)
 		}
 	}
 
-	err = kuberrs.Flatten(kuberrs.NewAggregate(errs))
-	if err == nil {
-		log.Infof("Backup completed successfully")
-	} else {
-		log.Infof("Backup completed with errors: %v", err)
+	if arkResources && !abortBackup(backup, errs) {
+		if err := kb.backupArkResources(log, backup, tw, discoveryHelper, dynamicFactory, podCommandExecutor, snapshotService, resolvedActions, resolvedSanitizers, backedUpItems); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
-	return err
+	return kuberrs.Flatten(kuberrs.NewAggregate(errs))
+}
+
+// backupArkResources backs up Ark's Configs and Schedules from the backup's namespace, regardless
+// of the backup's namespace, resource, and label filters, so that the Ark installation can be
+// rebuilt from the backup. Items in backedUpItems, which were already backed up, are skipped.
+func (kb *kubernetesBackupper) backupArkResources(
+	log logrus.FieldLogger,
+	backup *api.Backup,
+	tw tarWriter,
+	discoveryHelper discovery.Helper,
+	dynamicFactory client.DynamicFactory,
+	podCommandExecutor podCommandExecutor,
+	snapshotService cloudprovider.SnapshotService,
+	resolvedActions []resolvedAction,
+	resolvedSanitizers []resolvedSanitizer,
+	backedUpItems map[itemKey]struct{},
+) error {
+	var arkGroups []*metav1.APIResourceList
+	for _, group := range discoveryHelper.Resources() {
+		if gv, err := schema.ParseGroupVersion(group.GroupVersion); err == nil && gv.Group == api.GroupName {
+			arkGroups = append(arkGroups, group)
+		}
+	}
+	if len(arkGroups) == 0 {
+		return nil
+	}
+
+	log.Infof("Including Ark resources from namespace %s", backup.Namespace)
+
+	gb := kb.groupBackupperFactory.newGroupBackupper(
+		log,
+		backup,
+		collections.NewIncludesExcludes().Includes(backup.Namespace),
+		getResourceIncludesExcludes(discoveryHelper, []string{kuberesource.ArkConfigs.String(), kuberesource.ArkSchedules.String()}, nil),
+		"",
+		dynamicFactory,
+		discoveryHelper,
+		backedUpItems,
+		cohabitatingResources(kb.cohabitatingResources),
+		resolvedActions,
+		resolvedSanitizers,
+		podCommandExecutor,
+		tw,
+		nil,
+		snapshotService,
+	)
+
+	var errs []error
+	for _, group := range arkGroups {
+		if abortBackup(backup, errs) {
+			break
+		}
+
+		if err := gb.backupGroup(group); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return kuberrs.NewAggregate(errs)
+}
+
+// setupError is returned when a cluster couldn't be backed up at all, e.g. because the backup's
+// hooks or plugin actions couldn't be resolved, as opposed to when some of its items couldn't be.
+type setupError struct {
+	err error
+}
+
+func (e setupError) Error() string {
+	return e.err.Error()
+}
+
+// IsSetupError returns true if err, or any of the errors it aggregates, kept a cluster from being
+// backed up at all, in which case the backup failed rather than partially failed.
+func IsSetupError(err error) bool {
+	if agg, ok := err.(kuberrs.Aggregate); ok {
+		for _, err := range agg.Errors() {
+			if IsSetupError(err) {
+				return true
+			}
+		}
+		return false
+	}
+
+	_, ok := errors.Cause(err).(setupError)
+	return ok
+}
+
+// abortBackup returns whether the backup should stop backing up items because of errs, which is
+// the case as soon as any error has occurred if its failure policy is FailFast.
+func abortBackup(backup *api.Backup, errs []error) bool {
+	return backup.Spec.FailurePolicy == api.BackupFailurePolicyFailFast && len(errs) > 0
+}
+
+// includeArkResources returns whether Ark's own resources should be included in the backup.
+func includeArkResources(backup *api.Backup) bool {
+	return backup.Spec.IncludeArkResources == nil || *backup.Spec.IncludeArkResources
 }
 
 type tarWriter interface {
@@ -283,3 +645,16 @@ type tarWriter interface {
 	Write([]byte) (int, error)
 	WriteHeader(*tar.Header) error
 }
+
+// prefixedTarWriter is a tarWriter that places every file it writes under prefix.
+type prefixedTarWriter struct {
+	tarWriter
+	prefix string
+}
+
+func (w *prefixedTarWriter) WriteHeader(hdr *tar.Header) error {
+	prefixed := *hdr
+	prefixed.Name = filepath.Join(w.prefix, hdr.Name)
+
+	return w.tarWriter.WriteHeader(&prefixed)
+}
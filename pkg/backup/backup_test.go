@@ -17,6 +17,7 @@ limitations under the License.
 package backup
 
 import (
+	"archive/tar"
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
@@ -35,14 +36,18 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	kuberrs "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/util/boolptr"
 	"github.com/heptio/ark/pkg/util/collections"
 	kubeutil "github.com/heptio/ark/pkg/util/kube"
 	arktest "github.com/heptio/ark/pkg/util/test"
@@ -107,6 +112,25 @@ func TestResolveActions(t *testing.T) {
 			expected:    nil,
 			expectError: true,
 		},
+		{
+			name:        "field selector error",
+			input:       []ItemAction{&fakeAction{selector: ResourceSelector{FieldSelector: "spec.type"}}},
+			expected:    nil,
+			expectError: true,
+		},
+		{
+			name:  "resolved with field selector",
+			input: []ItemAction{&fakeAction{selector: ResourceSelector{IncludedResources: []string{"foo"}, FieldSelector: "spec.type=LoadBalancer"}}},
+			expected: []resolvedAction{
+				{
+					ItemAction:                &fakeAction{selector: ResourceSelector{IncludedResources: []string{"foo"}, FieldSelector: "spec.type=LoadBalancer"}},
+					resourceIncludesExcludes:  collections.NewIncludesExcludes().Includes("foodies.somegroup"),
+					namespaceIncludesExcludes: collections.NewIncludesExcludes(),
+					selector:                  labels.Everything(),
+					fieldSelector:             fields.OneTermEqualSelector("spec.type", "LoadBalancer"),
+				},
+			},
+		},
 		{
 			name:  "resolved",
 			input: []ItemAction{newFakeAction("foo"), newFakeAction("bar")},
@@ -116,12 +140,14 @@ func TestResolveActions(t *testing.T) {
 					resourceIncludesExcludes:  collections.NewIncludesExcludes().Includes("foodies.somegroup"),
 					namespaceIncludesExcludes: collections.NewIncludesExcludes(),
 					selector:                  labels.Everything(),
+					fieldSelector:             fields.Everything(),
 				},
 				{
 					ItemAction:                newFakeAction("bar"),
 					resourceIncludesExcludes:  collections.NewIncludesExcludes().Includes("barnacles.anothergroup"),
 					namespaceIncludesExcludes: collections.NewIncludesExcludes(),
 					selector:                  labels.Everything(),
+					fieldSelector:             fields.Everything(),
 				},
 			},
 		},
@@ -194,6 +220,13 @@ func TestGetResourceIncludesExcludes(t *testing.T) {
 			expectedIncludes: []string{"foodies.somegroup", "fields.somegroup"},
 			expectedExcludes: []string{"barnacles.anothergroup", "bazaars.anothergroup"},
 		},
+		{
+			name:             "group wildcards are not resolved",
+			includes:         []string{"*.somegroup", "bar"},
+			excludes:         []string{"*.anothergroup"},
+			expectedIncludes: []string{"*.somegroup", "barnacles.anothergroup"},
+			expectedExcludes: []string{"*.anothergroup"},
+		},
 	}
 
 	for _, test := range tests {
@@ -380,6 +413,7 @@ func TestBackup(t *testing.T) {
 		expectedHooks         []resourceHook
 		backupGroupErrors     map[*metav1.APIResourceList]error
 		expectedError         error
+		expectedErrorCount    int
 	}{
 		{
 			name: "happy path, no actions, no label selector, no hooks, no errors",
@@ -432,7 +466,25 @@ func TestBackup(t *testing.T) {
 				certificatesGroup: nil,
 				rbacGroup:         errors.New("rbac error"),
 			},
-			expectedError: errors.New("[v1 error, rbac error]"),
+			expectedError:      errors.New("[v1 error, rbac error]"),
+			expectedErrorCount: 2,
+		},
+		{
+			name: "backupGroup errors with a FailFast failure policy stop the backup",
+			backup: &v1.Backup{
+				Spec: v1.BackupSpec{
+					FailurePolicy: v1.BackupFailurePolicyFailFast,
+				},
+			},
+			expectedNamespaces: collections.NewIncludesExcludes(),
+			expectedResources:  collections.NewIncludesExcludes(),
+			expectedHooks:      []resourceHook{},
+			// only the first group is backed up
+			backupGroupErrors: map[*metav1.APIResourceList]error{
+				v1Group: errors.New("v1 error"),
+			},
+			expectedError:      errors.New("v1 error"),
+			expectedErrorCount: 1,
 		},
 		{
 			name: "hooks",
@@ -513,6 +565,9 @@ func TestBackup(t *testing.T) {
 				dynamicFactory,
 				podCommandExecutor,
 				nil,
+				nil,
+				nil,
+				nil,
 			)
 			require.NoError(t, err)
 			kb := b.(*kubernetesBackupper)
@@ -533,7 +588,8 @@ func TestBackup(t *testing.T) {
 				dynamicFactory,
 				discoveryHelper,
 				map[itemKey]struct{}{}, // backedUpItems
-				cohabitatingResources(),
+				cohabitatingResources(kb.cohabitatingResources),
+				mock.Anything,
 				mock.Anything,
 				kb.podCommandExecutor,
 				mock.Anything, // tarWriter
@@ -562,8 +618,16 @@ func TestBackup(t *testing.T) {
 				}
 			}()
 
+			// the format version is recorded even if the backup fails
+			assert.Equal(t, FormatVersion1, test.backup.Status.Version)
+
+			// each item-level error is counted in the backup's status
+			assert.Equal(t, test.expectedErrorCount, test.backup.Status.Errors)
+
 			if test.expectedError != nil {
 				assert.EqualError(t, err, test.expectedError.Error())
+				// errors backing up groups only partially fail the backup
+				assert.False(t, IsSetupError(err))
 				return
 			}
 			assert.NoError(t, err)
@@ -571,6 +635,164 @@ func TestBackup(t *testing.T) {
 	}
 }
 
+func TestIsSetupError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "item error",
+			err:      errors.New("error backing up item"),
+			expected: false,
+		},
+		{
+			name:     "setup error",
+			err:      setupError{errors.New("invalid hook")},
+			expected: true,
+		},
+		{
+			name:     "wrapped setup error",
+			err:      errors.Wrap(setupError{errors.New("invalid hook")}, "error backing up cluster other"),
+			expected: true,
+		},
+		{
+			name:     "aggregate including a setup error",
+			err:      kuberrs.NewAggregate([]error{errors.New("error backing up item"), setupError{errors.New("invalid hook")}}),
+			expected: true,
+		},
+		{
+			name:     "aggregate of item errors",
+			err:      kuberrs.NewAggregate([]error{errors.New("error backing up item")}),
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsSetupError(test.err))
+		})
+	}
+}
+
+func TestBackupIncludesArkResources(t *testing.T) {
+	arkGroup := &metav1.APIResourceList{
+		GroupVersion: "ark.heptio.com/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "configs", Namespaced: true, Kind: "Config"},
+			{Name: "schedules", Namespaced: true, Kind: "Schedule"},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		includeArkResources *bool
+		expectArkResources  bool
+	}{
+		{
+			name:               "ark resources are included by default",
+			expectArkResources: true,
+		},
+		{
+			name:                "ark resources are included when requested",
+			includeArkResources: boolptr.True(),
+			expectArkResources:  true,
+		},
+		{
+			name:                "ark resources are not included when excluded",
+			includeArkResources: boolptr.False(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backup := &v1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "heptio-ark",
+					Name:      "backup-1",
+				},
+				Spec: v1.BackupSpec{
+					IncludedNamespaces:  []string{"ns-1"},
+					IncludeArkResources: test.includeArkResources,
+				},
+			}
+
+			discoveryHelper := &arktest.FakeDiscoveryHelper{
+				Mapper: &arktest.FakeMapper{
+					Resources: map[schema.GroupVersionResource]schema.GroupVersionResource{
+						{Group: "ark.heptio.com", Resource: "configs"}:   {Group: "ark.heptio.com", Version: "v1", Resource: "configs"},
+						{Group: "ark.heptio.com", Resource: "schedules"}: {Group: "ark.heptio.com", Version: "v1", Resource: "schedules"},
+					},
+				},
+				ResourceList: []*metav1.APIResourceList{v1Group, arkGroup},
+			}
+
+			b, err := NewKubernetesBackupper(discoveryHelper, nil, nil, nil, nil, nil, nil)
+			require.NoError(t, err)
+			kb := b.(*kubernetesBackupper)
+
+			groupBackupperFactory := &mockGroupBackupperFactory{}
+			defer groupBackupperFactory.AssertExpectations(t)
+			kb.groupBackupperFactory = groupBackupperFactory
+
+			groupBackupper := &mockGroupBackupper{}
+			defer groupBackupper.AssertExpectations(t)
+			groupBackupper.On("backupGroup", v1Group).Return(nil)
+			groupBackupper.On("backupGroup", arkGroup).Return(nil)
+
+			groupBackupperFactory.On("newGroupBackupper",
+				mock.Anything,
+				backup,
+				collections.NewIncludesExcludes().Includes("ns-1"),
+				collections.NewIncludesExcludes(),
+				"",
+				mock.Anything,
+				discoveryHelper,
+				mock.Anything,
+				mock.Anything,
+				mock.Anything,
+				mock.Anything,
+				mock.Anything,
+				mock.Anything,
+				mock.Anything,
+				mock.Anything,
+			).Return(groupBackupper)
+
+			arkGroupBackupper := &mockGroupBackupper{}
+			defer arkGroupBackupper.AssertExpectations(t)
+
+			if test.expectArkResources {
+				groupBackupperFactory.On("newGroupBackupper",
+					mock.Anything,
+					backup,
+					collections.NewIncludesExcludes().Includes("heptio-ark"),
+					collections.NewIncludesExcludes().Includes("configs.ark.heptio.com", "schedules.ark.heptio.com"),
+					"",
+					mock.Anything,
+					discoveryHelper,
+					mock.Anything,
+					mock.Anything,
+					mock.Anything,
+					mock.Anything,
+					mock.Anything,
+					mock.Anything,
+					[]resourceHook(nil),
+					mock.Anything,
+				).Return(arkGroupBackupper)
+
+				arkGroupBackupper.On("backupGroup", arkGroup).Return(nil)
+			}
+
+			assert.NoError(t, b.Backup(backup, &bytes.Buffer{}, &bytes.Buffer{}, nil))
+		})
+	}
+}
+
 func TestBackupUsesNewCohabitatingResourcesForEachBackup(t *testing.T) {
 	discoveryHelper := &arktest.FakeDiscoveryHelper{
 		Mapper: &arktest.FakeMapper{
@@ -578,7 +800,7 @@ func TestBackupUsesNewCohabitatingResourcesForEachBackup(t *testing.T) {
 		},
 	}
 
-	b, err := NewKubernetesBackupper(discoveryHelper, nil, nil, nil)
+	b, err := NewKubernetesBackupper(discoveryHelper, nil, nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	kb := b.(*kubernetesBackupper)
@@ -587,7 +809,7 @@ func TestBackupUsesNewCohabitatingResourcesForEachBackup(t *testing.T) {
 
 	// assert that newGroupBackupper() is called with the result of cohabitatingResources()
 	// passed as an argument.
-	firstCohabitatingResources := cohabitatingResources()
+	firstCohabitatingResources := cohabitatingResources(kb.cohabitatingResources)
 	groupBackupperFactory.On("newGroupBackupper",
 		mock.Anything,
 		mock.Anything,
@@ -603,6 +825,7 @@ func TestBackupUsesNewCohabitatingResourcesForEachBackup(t *testing.T) {
 		mock.Anything,
 		mock.Anything,
 		mock.Anything,
+		mock.Anything,
 	).Return(&mockGroupBackupper{})
 
 	assert.NoError(t, b.Backup(&v1.Backup{}, &bytes.Buffer{}, &bytes.Buffer{}, nil))
@@ -615,10 +838,10 @@ func TestBackupUsesNewCohabitatingResourcesForEachBackup(t *testing.T) {
 	}
 
 	// assert that on a second backup, newGroupBackupper() is called with the result of
-	// cohabitatingResources() passed as an argument, that the value is not the
+	// cohabitatingResources(kb.cohabitatingResources) passed as an argument, that the value is not the
 	// same as the mutated firstCohabitatingResources value, and that all of the `seen`
 	// flags are false as they should be for a new instance
-	secondCohabitatingResources := cohabitatingResources()
+	secondCohabitatingResources := cohabitatingResources(kb.cohabitatingResources)
 	groupBackupperFactory.On("newGroupBackupper",
 		mock.Anything,
 		mock.Anything,
@@ -634,6 +857,7 @@ func TestBackupUsesNewCohabitatingResourcesForEachBackup(t *testing.T) {
 		mock.Anything,
 		mock.Anything,
 		mock.Anything,
+		mock.Anything,
 	).Return(&mockGroupBackupper{})
 
 	assert.NoError(t, b.Backup(&v1.Backup{}, &bytes.Buffer{}, &bytes.Buffer{}, nil))
@@ -644,6 +868,180 @@ func TestBackupUsesNewCohabitatingResourcesForEachBackup(t *testing.T) {
 	groupBackupperFactory.AssertExpectations(t)
 }
 
+func TestBackupAdditionalClusters(t *testing.T) {
+	var (
+		primaryHelper = &arktest.FakeDiscoveryHelper{
+			Mapper:       &arktest.FakeMapper{},
+			ResourceList: []*metav1.APIResourceList{v1Group},
+		}
+		clusterHelper = &arktest.FakeDiscoveryHelper{
+			Mapper:       &arktest.FakeMapper{},
+			ResourceList: []*metav1.APIResourceList{v1Group, rbacGroup},
+		}
+		primaryDynamicFactory = &arktest.FakeDynamicFactory{}
+		clusterDynamicFactory = &arktest.FakeDynamicFactory{}
+	)
+
+	b, err := NewKubernetesBackupper(
+		primaryHelper,
+		primaryDynamicFactory,
+		nil,
+		nil,
+		[]*AdditionalCluster{NewAdditionalCluster("east", func() (Cluster, error) {
+			return NewCluster("east", clusterHelper, clusterDynamicFactory, nil), nil
+		})},
+		nil,
+		nil,
+	)
+	require.NoError(t, err)
+
+	kb := b.(*kubernetesBackupper)
+	groupBackupperFactory := &mockGroupBackupperFactory{}
+	defer groupBackupperFactory.AssertExpectations(t)
+	kb.groupBackupperFactory = groupBackupperFactory
+
+	primaryGroupBackupper := &mockGroupBackupper{}
+	defer primaryGroupBackupper.AssertExpectations(t)
+	primaryGroupBackupper.On("backupGroup", v1Group).Return(nil)
+
+	clusterGroupBackupper := &mockGroupBackupper{}
+	defer clusterGroupBackupper.AssertExpectations(t)
+	clusterGroupBackupper.On("backupGroup", v1Group).Return(nil)
+	clusterGroupBackupper.On("backupGroup", rbacGroup).Return(errors.New("rbac error"))
+
+	groupBackupperFactory.On("newGroupBackupper",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		primaryDynamicFactory,
+		primaryHelper,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.MatchedBy(func(tw tarWriter) bool {
+			_, prefixed := tw.(*prefixedTarWriter)
+			return !prefixed
+		}),
+		mock.Anything,
+		mock.Anything,
+	).Return(primaryGroupBackupper)
+
+	groupBackupperFactory.On("newGroupBackupper",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		clusterDynamicFactory,
+		clusterHelper,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.MatchedBy(func(tw tarWriter) bool {
+			prefixed, ok := tw.(*prefixedTarWriter)
+			return ok && prefixed.prefix == "clusters/east"
+		}),
+		mock.Anything,
+		nil,
+	).Return(clusterGroupBackupper)
+
+	err = b.Backup(&v1.Backup{}, &bytes.Buffer{}, &bytes.Buffer{}, nil)
+	assert.EqualError(t, err, "error backing up cluster east: rbac error")
+}
+
+func TestAdditionalClusterConnect(t *testing.T) {
+	var (
+		attempts int
+		connErr  = errors.New("connection refused")
+	)
+
+	cluster := NewAdditionalCluster("east", func() (Cluster, error) {
+		attempts++
+		if attempts <= 2 {
+			return Cluster{}, connErr
+		}
+		return NewCluster("east", &arktest.FakeDiscoveryHelper{}, &arktest.FakeDynamicFactory{}, nil), nil
+	})
+	cluster.backoff = wait.Backoff{Steps: 2}
+
+	// the cluster is unreachable for the first backup
+	_, err := cluster.Connect(arktest.NewLogger())
+	assert.EqualError(t, err, "error connecting to cluster east: connection refused")
+	assert.Equal(t, 2, attempts)
+
+	// and is connected to for the next one
+	connected, err := cluster.Connect(arktest.NewLogger())
+	require.NoError(t, err)
+	assert.Equal(t, "east", connected.name)
+	assert.Equal(t, 3, attempts)
+
+	// without connecting again after that
+	_, err = cluster.Connect(arktest.NewLogger())
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestBackupRecordsUnreachableCluster(t *testing.T) {
+	helper := &arktest.FakeDiscoveryHelper{
+		Mapper:       &arktest.FakeMapper{},
+		ResourceList: []*metav1.APIResourceList{v1Group},
+	}
+	dynamicFactory := &arktest.FakeDynamicFactory{}
+
+	cluster := NewAdditionalCluster("east", func() (Cluster, error) {
+		return Cluster{}, errors.New("connection refused")
+	})
+	cluster.backoff = wait.Backoff{Steps: 1}
+
+	b, err := NewKubernetesBackupper(helper, dynamicFactory, nil, nil, []*AdditionalCluster{cluster}, nil, nil)
+	require.NoError(t, err)
+
+	kb := b.(*kubernetesBackupper)
+	groupBackupperFactory := &mockGroupBackupperFactory{}
+	defer groupBackupperFactory.AssertExpectations(t)
+	kb.groupBackupperFactory = groupBackupperFactory
+
+	groupBackupper := &mockGroupBackupper{}
+	defer groupBackupper.AssertExpectations(t)
+	groupBackupper.On("backupGroup", v1Group).Return(nil)
+
+	groupBackupperFactory.On("newGroupBackupper",
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, dynamicFactory, helper,
+		mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+	).Return(groupBackupper)
+
+	backup := &v1.Backup{}
+	err = b.Backup(backup, &bytes.Buffer{}, &bytes.Buffer{}, nil)
+	assert.EqualError(t, err, "error connecting to cluster east: connection refused")
+	assert.Equal(t, 1, backup.Status.Errors)
+}
+
+func TestPrefixedTarWriter(t *testing.T) {
+	var buf bytes.Buffer
+	tw := &prefixedTarWriter{tarWriter: tar.NewWriter(&buf), prefix: "clusters/east"}
+
+	data := []byte("{}")
+	hdr := &tar.Header{Name: "resources/pods/namespaces/ns-1/pod-1.json", Size: int64(len(data)), Mode: 0755}
+	require.NoError(t, tw.WriteHeader(hdr))
+	_, err := tw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	// the caller's header isn't modified
+	assert.Equal(t, "resources/pods/namespaces/ns-1/pod-1.json", hdr.Name)
+
+	tr := tar.NewReader(&buf)
+	readHdr, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "clusters/east/resources/pods/namespaces/ns-1/pod-1.json", readHdr.Name)
+}
+
 type mockGroupBackupperFactory struct {
 	mock.Mock
 }
@@ -658,6 +1056,7 @@ func (f *mockGroupBackupperFactory) newGroupBackupper(
 	backedUpItems map[itemKey]struct{},
 	cohabitatingResources map[string]*cohabitatingResource,
 	actions []resolvedAction,
+	sanitizers []resolvedSanitizer,
 	podCommandExecutor podCommandExecutor,
 	tarWriter tarWriter,
 	resourceHooks []resourceHook,
@@ -674,6 +1073,7 @@ func (f *mockGroupBackupperFactory) newGroupBackupper(
 		backedUpItems,
 		cohabitatingResources,
 		actions,
+		sanitizers,
 		podCommandExecutor,
 		tarWriter,
 		resourceHooks,
@@ -0,0 +1,281 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kuberrs "k8s.io/apimachinery/pkg/util/errors"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/kuberesource"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+var (
+	podsAPIResource = metav1.APIResource{Name: "pods", Namespaced: true}
+	pvcsAPIResource = metav1.APIResource{Name: "persistentvolumeclaims", Namespaced: true}
+	pvsAPIResource  = metav1.APIResource{Name: "persistentvolumes"}
+)
+
+// consistencyGroupBackupper snapshots the volumes of each of a backup's consistency groups. All
+// of a group's pods have their volumes frozen before any of the group's volumes are snapshotted,
+// and none are unfrozen until all of the snapshots have been taken.
+type consistencyGroupBackupper struct {
+	log             logrus.FieldLogger
+	backup          *api.Backup
+	namespaces      *collections.IncludesExcludes
+	resources       *collections.IncludesExcludes
+	dynamicFactory  client.DynamicFactory
+	snapshotService cloudprovider.SnapshotService
+	hookHandler     *defaultItemHookHandler
+}
+
+// groupMember is a pod in a consistency group and the volumes of it that the group freezes.
+type groupMember struct {
+	pod  *unstructured.Unstructured
+	hook *api.FSFreezeHook
+}
+
+func newConsistencyGroupBackupper(
+	log logrus.FieldLogger,
+	backup *api.Backup,
+	discoveryHelper discovery.Helper,
+	dynamicFactory client.DynamicFactory,
+	podCommandExecutor podCommandExecutor,
+	snapshotService cloudprovider.SnapshotService,
+) *consistencyGroupBackupper {
+	return &consistencyGroupBackupper{
+		log:             log,
+		backup:          backup,
+		namespaces:      getNamespaceIncludesExcludes(backup),
+		resources:       getResourceIncludesExcludes(discoveryHelper, backup.Spec.IncludedResources, backup.Spec.ExcludedResources),
+		dynamicFactory:  dynamicFactory,
+		snapshotService: snapshotService,
+		hookHandler: &defaultItemHookHandler{
+			podCommandExecutor: podCommandExecutor,
+			backup:             backup,
+		},
+	}
+}
+
+// backupGroups snapshots the volumes of each of the backup's consistency groups. The PVs are
+// recorded in the backup's status, so they aren't snapshotted again when they're backed up.
+func (cb *consistencyGroupBackupper) backupGroups() error {
+	// PVs that wouldn't be backed up aren't snapshotted either
+	if !cb.resources.ShouldInclude(kuberesource.PersistentVolumes.String()) {
+		cb.log.Info("Skipping consistency groups because persistentvolumes are excluded from the backup")
+		return nil
+	}
+
+	selector := labels.Everything()
+	if cb.backup.Spec.LabelSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(cb.backup.Spec.LabelSelector); err != nil {
+			return errors.Wrap(err, "invalid label selector")
+		}
+	}
+
+	var errs []error
+
+	for _, group := range cb.backup.Spec.Hooks.ConsistencyGroups {
+		if err := cb.backupGroup(group, selector); err != nil {
+			errs = append(errs, errors.Wrapf(err, "error backing up consistency group %s", group.Name))
+		}
+	}
+
+	return kuberrs.NewAggregate(errs)
+}
+
+// backupGroup freezes the volumes of the group's pods and snapshots those whose pod, PVC, or PV
+// matches the backup's label selector, since only those PVs would be backed up.
+func (cb *consistencyGroupBackupper) backupGroup(group api.ConsistencyGroupSpec, selector labels.Selector) error {
+	log := cb.log.WithField("consistencyGroup", group.Name)
+
+	members, err := cb.getMembers(group)
+	if err != nil {
+		return err
+	}
+	log.Infof("Found %d running pods in consistency group", len(members))
+
+	// unfreeze in the reverse of the order the pods were frozen in, including any that were
+	// only partially frozen
+	var frozen []*groupMember
+	defer func() {
+		for i := len(frozen) - 1; i >= 0; i-- {
+			cb.hookHandler.unfreezeVolumes(log, frozen[i].pod)
+		}
+	}()
+
+	for i := range members {
+		member := &members[i]
+		frozen = append(frozen, member)
+
+		if err := cb.hookHandler.freezeVolumes(log, member.pod, group.Name, member.hook); err != nil {
+			if group.FSFreeze.OnError == api.HookErrorModeContinue {
+				log.WithError(err).WithField("pod", member.pod.GetNamespace()+"/"+member.pod.GetName()).Warn("Error freezing volumes, continuing because onError is Continue")
+				continue
+			}
+			return errors.Wrapf(err, "error freezing volumes of pod %s/%s", member.pod.GetNamespace(), member.pod.GetName())
+		}
+	}
+
+	var errs []error
+	for _, member := range members {
+		for _, volume := range member.hook.Volumes {
+			if err := cb.snapshotVolume(log, member.pod, volume, selector); err != nil {
+				errs = append(errs, errors.Wrapf(err, "error snapshotting volume %s of pod %s/%s", volume, member.pod.GetNamespace(), member.pod.GetName()))
+			}
+		}
+	}
+
+	return kuberrs.NewAggregate(errs)
+}
+
+// getMembers returns the running pods that match the group's label selector and are in both the
+// backup's and the group's namespaces, along with the fsfreeze hook to run in each of them.
+func (cb *consistencyGroupBackupper) getMembers(group api.ConsistencyGroupSpec) ([]groupMember, error) {
+	podClient, err := cb.dynamicFactory.ClientForGroupVersionResource(schema.GroupVersion{Version: "v1"}, podsAPIResource, "")
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(group.LabelSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid label selector")
+	}
+
+	list, err := podClient.List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing pods")
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	groupNamespaces := collections.NewIncludesExcludes().Includes(group.IncludedNamespaces...)
+
+	var members []groupMember
+	for _, item := range items {
+		pod, ok := item.(*unstructured.Unstructured)
+		if !ok {
+			return nil, errors.Errorf("unexpected type %T", item)
+		}
+
+		if !cb.namespaces.ShouldInclude(pod.GetNamespace()) || !groupNamespaces.ShouldInclude(pod.GetNamespace()) {
+			continue
+		}
+		if phase, _ := collections.GetString(pod.Object, "status.phase"); phase != "Running" {
+			continue
+		}
+
+		hook := group.FSFreeze
+		if len(hook.Volumes) == 0 {
+			hook.Volumes = getClaimVolumes(pod.Object)
+		}
+
+		members = append(members, groupMember{pod: pod, hook: &hook})
+	}
+
+	return members, nil
+}
+
+// snapshotVolume snapshots the PV bound to the PVC backing the pod's volume, if the pod, PVC,
+// or PV matches selector.
+func (cb *consistencyGroupBackupper) snapshotVolume(log logrus.FieldLogger, pod *unstructured.Unstructured, volume string, selector labels.Selector) error {
+	claimName := getClaimName(pod.Object, volume)
+	if claimName == "" {
+		log.Infof("Volume %s isn't backed by a PersistentVolumeClaim, skipping", volume)
+		return nil
+	}
+
+	pvcClient, err := cb.dynamicFactory.ClientForGroupVersionResource(schema.GroupVersion{Version: "v1"}, pvcsAPIResource, pod.GetNamespace())
+	if err != nil {
+		return err
+	}
+	pvc, err := pvcClient.Get(claimName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "error getting PersistentVolumeClaim %s", claimName)
+	}
+
+	pvName, err := collections.GetString(pvc.Object, "spec.volumeName")
+	if err != nil || pvName == "" {
+		return errors.Errorf("PersistentVolumeClaim %s isn't bound", claimName)
+	}
+
+	pvClient, err := cb.dynamicFactory.ClientForGroupVersionResource(schema.GroupVersion{Version: "v1"}, pvsAPIResource, "")
+	if err != nil {
+		return err
+	}
+	pv, err := pvClient.Get(pvName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "error getting PersistentVolume %s", pvName)
+	}
+
+	if !selector.Matches(labels.Set(pod.GetLabels())) && !selector.Matches(labels.Set(pvc.GetLabels())) && !selector.Matches(labels.Set(pv.GetLabels())) {
+		log.Infof("Skipping PersistentVolume %s because neither it, its claim, nor its pod matches the backup's label selector", pvName)
+		return nil
+	}
+
+	return takePVSnapshot(cb.snapshotService, pv, cb.backup, log.WithField("persistentVolume", pvName))
+}
+
+// getClaimVolumes returns the names of the pod's volumes that are backed by PersistentVolumeClaims.
+func getClaimVolumes(pod map[string]interface{}) []string {
+	var names []string
+	for _, volume := range getPodVolumes(pod) {
+		if collections.Exists(volume, "persistentVolumeClaim.claimName") {
+			name, _ := volume["name"].(string)
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// getClaimName returns the name of the PersistentVolumeClaim backing the pod's named volume, or
+// "" if it isn't backed by one.
+func getClaimName(pod map[string]interface{}, volume string) string {
+	for _, v := range getPodVolumes(pod) {
+		if v["name"] == volume {
+			claimName, _ := collections.GetString(v, "persistentVolumeClaim.claimName")
+			return claimName
+		}
+	}
+	return ""
+}
+
+func getPodVolumes(pod map[string]interface{}) []map[string]interface{} {
+	list, _ := collections.GetSlice(pod, "spec.volumes")
+
+	var volumes []map[string]interface{}
+	for _, obj := range list {
+		if volume, ok := obj.(map[string]interface{}); ok {
+			volumes = append(volumes, volume)
+		}
+	}
+	return volumes
+}
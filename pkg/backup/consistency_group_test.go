@@ -0,0 +1,188 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func consistencyGroupPod(namespace, name, phase, claimName string) unstructured.Unstructured {
+	return *unstructuredOrDie(fmt.Sprintf(`{
+		"apiVersion": "v1",
+		"kind": "Pod",
+		"metadata": {"namespace": %q, "name": %q, "labels": {"app": "db"}},
+		"spec": {
+			"containers": [{"name": "db", "volumeMounts": [{"name": "data", "mountPath": "/data"}, {"name": "config", "mountPath": "/config"}]}],
+			"volumes": [{"name": "data", "persistentVolumeClaim": {"claimName": %q}}, {"name": "config", "configMap": {"name": "db"}}]
+		},
+		"status": {"phase": %q}
+	}`, namespace, name, claimName, phase))
+}
+
+func TestConsistencyGroupBackupper(t *testing.T) {
+	tests := []struct {
+		name                string
+		failFreezing        string
+		onError             v1.HookErrorMode
+		excludedResources   []string
+		labelSelector       *metav1.LabelSelector
+		expectedEvents      []string
+		expectedSnapshotted []string
+		expectedErr         bool
+	}{
+		{
+			name: "all pods are frozen before any volumes are snapshotted",
+			expectedEvents: []string{
+				"--freeze ns-1/pod-0 (0 snapshots)",
+				"--freeze ns-1/pod-1 (0 snapshots)",
+				"--unfreeze ns-1/pod-1 (2 snapshots)",
+				"--unfreeze ns-1/pod-0 (2 snapshots)",
+			},
+			expectedSnapshotted: []string{"pv-0", "pv-1"},
+		},
+		{
+			name:         "a freeze failure unfreezes the frozen pods without snapshotting",
+			failFreezing: "pod-1",
+			expectedEvents: []string{
+				"--freeze ns-1/pod-0 (0 snapshots)",
+				"--freeze ns-1/pod-1 (0 snapshots)",
+				"--unfreeze ns-1/pod-0 (0 snapshots)",
+			},
+			expectedErr: true,
+		},
+		{
+			name:         "a freeze failure is ignored when onError is Continue",
+			failFreezing: "pod-1",
+			onError:      v1.HookErrorModeContinue,
+			expectedEvents: []string{
+				"--freeze ns-1/pod-0 (0 snapshots)",
+				"--freeze ns-1/pod-1 (0 snapshots)",
+				"--unfreeze ns-1/pod-0 (2 snapshots)",
+			},
+			expectedSnapshotted: []string{"pv-0", "pv-1"},
+		},
+		{
+			name:              "nothing is frozen or snapshotted when persistentvolumes are excluded",
+			excludedResources: []string{"persistentvolumes"},
+		},
+		{
+			name:          "only volumes whose pod, PVC, or PV matches the backup's label selector are snapshotted",
+			labelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"backup": "true"}},
+			expectedEvents: []string{
+				"--freeze ns-1/pod-0 (0 snapshots)",
+				"--freeze ns-1/pod-1 (0 snapshots)",
+				"--unfreeze ns-1/pod-1 (1 snapshots)",
+				"--unfreeze ns-1/pod-0 (1 snapshots)",
+			},
+			expectedSnapshotted: []string{"pv-1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backup := arktest.NewTestBackup().WithName("backup-1").WithExcludedNamespaces("ns-2").WithExcludedResources(test.excludedResources...).Backup
+			backup.Spec.LabelSelector = test.labelSelector
+			backup.Spec.Hooks.ConsistencyGroups = []v1.ConsistencyGroupSpec{
+				{
+					Name:          "db",
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+					FSFreeze:      v1.FSFreezeHook{OnError: test.onError},
+				},
+			}
+
+			podClient := &arktest.FakeDynamicClient{}
+			podClient.On("List", metav1.ListOptions{LabelSelector: "app=db"}).Return(&unstructured.UnstructuredList{
+				Items: []unstructured.Unstructured{
+					consistencyGroupPod("ns-1", "pod-0", "Running", "data-0"),
+					consistencyGroupPod("ns-1", "pod-1", "Running", "data-1"),
+					consistencyGroupPod("ns-1", "pod-2", "Pending", "data-2"),
+					consistencyGroupPod("ns-2", "pod-3", "Running", "data-3"),
+				},
+			}, nil)
+
+			pvcClient := &arktest.FakeDynamicClient{}
+			pvClient := &arktest.FakeDynamicClient{}
+			for i := 0; i < 2; i++ {
+				// only data-1 is labeled to match the backup's label selector
+				pvcClient.On("Get", fmt.Sprintf("data-%d", i), metav1.GetOptions{}).Return(
+					unstructuredOrDie(fmt.Sprintf(`{"apiVersion":"v1","kind":"PersistentVolumeClaim","metadata":{"namespace":"ns-1","name":"data-%d","labels":{"backup":"%t"}},"spec":{"volumeName":"pv-%d"}}`, i, i == 1, i)), nil)
+				pvClient.On("Get", fmt.Sprintf("pv-%d", i), metav1.GetOptions{}).Return(
+					unstructuredOrDie(fmt.Sprintf(`{"apiVersion":"v1","kind":"PersistentVolume","metadata":{"name":"pv-%d"}}`, i)), nil)
+			}
+
+			gv := schema.GroupVersion{Version: "v1"}
+			dynamicFactory := &arktest.FakeDynamicFactory{}
+			dynamicFactory.On("ClientForGroupVersionResource", gv, podsAPIResource, "").Return(podClient, nil)
+			dynamicFactory.On("ClientForGroupVersionResource", gv, pvcsAPIResource, "ns-1").Return(pvcClient, nil)
+			dynamicFactory.On("ClientForGroupVersionResource", gv, pvsAPIResource, "").Return(pvClient, nil)
+
+			var events []string
+			podCommandExecutor := &mockPodCommandExecutor{}
+			recordEvent := func(args mock.Arguments) {
+				hook := args.Get(5).(*v1.ExecHook)
+				events = append(events, fmt.Sprintf("%s %s/%s (%d snapshots)", hook.Command[1], args.String(2), args.String(3), len(backup.Status.VolumeBackups)))
+			}
+			isFreeze := mock.MatchedBy(func(hook *v1.ExecHook) bool { return hook.Command[1] == "--freeze" })
+			podCommandExecutor.On("executePodCommand", mock.Anything, mock.Anything, "ns-1", test.failFreezing, "db", isFreeze).Run(recordEvent).Return(nil, errors.New("freeze failed"))
+			podCommandExecutor.On("executePodCommand", mock.Anything, mock.Anything, "ns-1", mock.Anything, "db", mock.Anything).Run(recordEvent).Return(nil, nil)
+
+			snapshotService := &arktest.FakeSnapshotService{
+				SnapshottableVolumes: map[string]v1.VolumeBackupInfo{"volume-1": {SnapshotID: "snapshot-1"}},
+				VolumeID:             "volume-1",
+			}
+
+			err := newConsistencyGroupBackupper(arktest.NewLogger(), backup, arktest.NewFakeDiscoveryHelper(true, nil), dynamicFactory, podCommandExecutor, snapshotService).backupGroups()
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedEvents, events)
+
+			var snapshotted []string
+			for name := range backup.Status.VolumeBackups {
+				snapshotted = append(snapshotted, name)
+			}
+			sort.Strings(snapshotted)
+			assert.Equal(t, test.expectedSnapshotted, snapshotted)
+		})
+	}
+}
+
+func TestGetClaimVolumes(t *testing.T) {
+	pod := consistencyGroupPod("ns-1", "pod-0", "Running", "data-0")
+
+	assert.Equal(t, []string{"data"}, getClaimVolumes(pod.Object))
+	assert.Equal(t, "data-0", getClaimName(pod.Object, "data"))
+	assert.Equal(t, "", getClaimName(pod.Object, "config"))
+	require.Equal(t, "", getClaimName(pod.Object, "missing"))
+}
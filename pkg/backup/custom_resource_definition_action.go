@@ -0,0 +1,152 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/kuberesource"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+// customResourceDefinitionAction implements ItemAction. It's used for backups that include
+// cluster-scoped resources automatically (backup.spec.includeClusterResources is unset) and
+// only include specific namespaces, so that the CustomResourceDefinitions of any custom
+// resources in those namespaces are backed up with them.
+type customResourceDefinitionAction struct {
+	log             logrus.FieldLogger
+	discoveryHelper discovery.Helper
+	dynamicFactory  client.DynamicFactory
+
+	// crds maps the group and kind of each custom resource to the name of the
+	// CustomResourceDefinition that defines it. It's loaded the first time Execute
+	// is called.
+	crds map[schema.GroupKind]string
+}
+
+func newCustomResourceDefinitionAction(log logrus.FieldLogger, discoveryHelper discovery.Helper, dynamicFactory client.DynamicFactory) ItemAction {
+	return &customResourceDefinitionAction{
+		log:             log,
+		discoveryHelper: discoveryHelper,
+		dynamicFactory:  dynamicFactory,
+	}
+}
+
+// AppliesTo returns a ResourceSelector that applies to all items.
+func (a *customResourceDefinitionAction) AppliesTo() (ResourceSelector, error) {
+	return ResourceSelector{}, nil
+}
+
+// Execute adds the CustomResourceDefinition that defines the item's kind, if it's a
+// namespaced custom resource, to the list of additional items to be backed up.
+func (a *customResourceDefinitionAction) Execute(item runtime.Unstructured, backup *v1.Backup) (runtime.Unstructured, []ResourceIdentifier, error) {
+	metadata, err := meta.Accessor(item)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	// cluster-scoped custom resources are only backed up when their CRD would be, too
+	if metadata.GetNamespace() == "" {
+		return item, nil, nil
+	}
+
+	gk := item.GetObjectKind().GroupVersionKind().GroupKind()
+	if gk.Group == "" {
+		return item, nil, nil
+	}
+
+	if a.crds == nil {
+		if err := a.loadCRDs(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	name, found := a.crds[gk]
+	if !found {
+		return item, nil, nil
+	}
+
+	a.log.Infof("Adding customresourcedefinition %s to additionalItems since %s is a custom resource", name, gk)
+
+	return item, []ResourceIdentifier{
+		{
+			GroupResource: kuberesource.CustomResourceDefinitions,
+			Name:          name,
+		},
+	}, nil
+}
+
+// loadCRDs lists the cluster's CustomResourceDefinitions into a.crds. If the cluster doesn't
+// serve CustomResourceDefinitions, a.crds is left empty.
+func (a *customResourceDefinitionAction) loadCRDs() error {
+	a.crds = make(map[schema.GroupKind]string)
+
+	gvr, resource, err := discovery.ResourceForWithRefresh(a.discoveryHelper, kuberesource.CustomResourceDefinitions.WithVersion(""))
+	if err != nil {
+		a.log.WithError(err).Info("Unable to resolve customresourcedefinitions; not backing up CRDs of custom resources")
+		return nil
+	}
+
+	client, err := a.dynamicFactory.ClientForGroupVersionResource(gvr.GroupVersion(), resource, "")
+	if err != nil {
+		return err
+	}
+
+	list, err := client.List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "error listing customresourcedefinitions")
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, item := range items {
+		crd, ok := item.(runtime.Unstructured)
+		if !ok {
+			return errors.Errorf("unexpected type %T", item)
+		}
+
+		metadata, err := meta.Accessor(crd)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		group, err := collections.GetString(crd.UnstructuredContent(), "spec.group")
+		if err != nil {
+			return errors.Wrapf(err, "error getting group of customresourcedefinition %s", metadata.GetName())
+		}
+		kind, err := collections.GetString(crd.UnstructuredContent(), "spec.names.kind")
+		if err != nil {
+			return errors.Wrapf(err, "error getting kind of customresourcedefinition %s", metadata.GetName())
+		}
+
+		a.crds[schema.GroupKind{Group: group, Kind: kind}] = metadata.GetName()
+	}
+
+	return nil
+}
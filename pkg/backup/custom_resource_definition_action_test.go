@@ -0,0 +1,100 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/kuberesource"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestCustomResourceDefinitionAction(t *testing.T) {
+	tests := []struct {
+		name            string
+		item            string
+		expectedCRDName string
+	}{
+		{
+			name:            "namespaced custom resource adds its CRD",
+			item:            `{"apiVersion":"example.com/v1","kind":"Foo","metadata":{"namespace":"ns","name":"foo-1"}}`,
+			expectedCRDName: "foos.example.com",
+		},
+		{
+			name: "namespaced resource without a CRD adds nothing",
+			item: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns","name":"deploy-1"}}`,
+		},
+		{
+			name: "cluster-scoped custom resource adds nothing",
+			item: `{"apiVersion":"example.com/v1","kind":"Foo","metadata":{"name":"foo-1"}}`,
+		},
+		{
+			name: "core resource adds nothing",
+			item: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns","name":"cm-1"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			crdGVR := kuberesource.CustomResourceDefinitions.WithVersion("v1beta1")
+			discoveryHelper := arktest.NewFakeDiscoveryHelper(false, map[schema.GroupVersionResource]schema.GroupVersionResource{
+				kuberesource.CustomResourceDefinitions.WithVersion(""): crdGVR,
+			})
+
+			crdClient := &arktest.FakeDynamicClient{}
+			crdClient.On("List", metav1.ListOptions{}).Return(&unstructured.UnstructuredList{
+				Items: []unstructured.Unstructured{
+					*unstructuredOrDie(`{"apiVersion":"apiextensions.k8s.io/v1beta1","kind":"CustomResourceDefinition","metadata":{"name":"foos.example.com"},"spec":{"group":"example.com","names":{"kind":"Foo","plural":"foos"}}}`),
+				},
+			}, nil)
+
+			dynamicFactory := &arktest.FakeDynamicFactory{}
+			dynamicFactory.On("ClientForGroupVersionResource", crdGVR.GroupVersion(), metav1.APIResource{Name: "customresourcedefinitions"}, "").Return(crdClient, nil)
+
+			a := newCustomResourceDefinitionAction(arktest.NewLogger(), discoveryHelper, dynamicFactory)
+
+			item := unstructuredOrDie(test.item)
+			_, additional, err := a.Execute(item, &v1.Backup{})
+			require.NoError(t, err)
+
+			if test.expectedCRDName == "" {
+				assert.Empty(t, additional)
+				return
+			}
+			assert.Equal(t, []ResourceIdentifier{{GroupResource: kuberesource.CustomResourceDefinitions, Name: test.expectedCRDName}}, additional)
+		})
+	}
+}
+
+func TestCustomResourceDefinitionActionWithoutCRDs(t *testing.T) {
+	// the cluster doesn't serve customresourcedefinitions
+	discoveryHelper := arktest.NewFakeDiscoveryHelper(false, map[schema.GroupVersionResource]schema.GroupVersionResource{})
+
+	a := newCustomResourceDefinitionAction(arktest.NewLogger(), discoveryHelper, &arktest.FakeDynamicFactory{})
+
+	item := unstructuredOrDie(`{"apiVersion":"example.com/v1","kind":"Foo","metadata":{"namespace":"ns","name":"foo-1"}}`)
+	_, additional, err := a.Execute(item, &v1.Backup{})
+	require.NoError(t, err)
+	assert.Empty(t, additional)
+}
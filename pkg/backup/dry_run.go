@@ -0,0 +1,96 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// itemCountingTarWriter is a tarWriter that counts the items of each resource written
+// to it in the backup's status.
+type itemCountingTarWriter struct {
+	tarWriter
+	backup *api.Backup
+}
+
+func newItemCountingTarWriter(tw tarWriter, backup *api.Backup) *itemCountingTarWriter {
+	if backup.Status.ItemCounts == nil {
+		backup.Status.ItemCounts = make(map[string]int)
+	}
+
+	return &itemCountingTarWriter{
+		tarWriter: tw,
+		backup:    backup,
+	}
+}
+
+func (w *itemCountingTarWriter) WriteHeader(hdr *tar.Header) error {
+	// paths are [clusters/<cluster>/]resources/<resource>/...
+	parts := strings.Split(filepath.ToSlash(hdr.Name), "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if parts[i] == api.ResourcesDir {
+			w.backup.Status.ItemCounts[parts[i+1]]++
+			break
+		}
+	}
+
+	return w.tarWriter.WriteHeader(hdr)
+}
+
+// dryRunTarWriter is a tarWriter for dry-run backups. Instead of writing items, it
+// logs each item's path.
+type dryRunTarWriter struct {
+	log logrus.FieldLogger
+}
+
+func newDryRunTarWriter(log logrus.FieldLogger) *dryRunTarWriter {
+	return &dryRunTarWriter{
+		log: log,
+	}
+}
+
+func (w *dryRunTarWriter) WriteHeader(hdr *tar.Header) error {
+	w.log.WithField("path", hdr.Name).Info("Dry run: would back up item")
+	return nil
+}
+
+func (w *dryRunTarWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (w *dryRunTarWriter) Close() error {
+	return nil
+}
+
+// dryRunPodCommandExecutor is a podCommandExecutor for dry-run backups, which logs hooks
+// instead of executing them.
+type dryRunPodCommandExecutor struct{}
+
+func (e *dryRunPodCommandExecutor) executePodCommand(log logrus.FieldLogger, item map[string]interface{}, namespace, name, hookName string, hook *api.ExecHook) ([]api.HookExecution, error) {
+	log.WithFields(logrus.Fields{
+		"hookName": hookName,
+		"command":  hook.Command,
+	}).Info("Dry run: not executing hook")
+
+	return nil, nil
+}
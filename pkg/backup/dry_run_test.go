@@ -0,0 +1,62 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestItemCountingTarWriterCountsItems(t *testing.T) {
+	backup := &v1.Backup{}
+	w := newItemCountingTarWriter(newDryRunTarWriter(arktest.NewLogger()), backup)
+
+	paths := []string{
+		"resources/configmaps/namespaces/ns-1/cm-1.json",
+		"resources/configmaps/namespaces/ns-2/cm-2.json",
+		"resources/persistentvolumes/cluster/pv-1.json",
+		"clusters/other/resources/configmaps/namespaces/ns-1/cm-1.json",
+	}
+
+	for _, path := range paths {
+		require.NoError(t, w.WriteHeader(&tar.Header{Name: path}))
+		n, err := w.Write([]byte("data"))
+		require.NoError(t, err)
+		assert.Equal(t, 4, n)
+	}
+	require.NoError(t, w.Close())
+
+	expected := map[string]int{
+		"configmaps":        3,
+		"persistentvolumes": 1,
+	}
+	assert.Equal(t, expected, backup.Status.ItemCounts)
+}
+
+func TestDryRunPodCommandExecutorDoesNotExecute(t *testing.T) {
+	e := &dryRunPodCommandExecutor{}
+
+	executions, err := e.executePodCommand(arktest.NewLogger(), nil, "ns", "pod", "hook", &v1.ExecHook{Command: []string{"ls"}})
+	require.NoError(t, err)
+	assert.Empty(t, executions)
+}
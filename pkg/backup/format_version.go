@@ -0,0 +1,56 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"github.com/pkg/errors"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+const (
+	// FormatVersion1 is the format of backups whose contents are stored in a
+	// single tarball.
+	FormatVersion1 = 1
+
+	// FormatVersion2 is the format of backups whose contents are split into
+	// shards, listed in a manifest.
+	FormatVersion2 = 2
+
+	// MaxFormatVersion is the newest backup format that this version of Ark
+	// can read.
+	MaxFormatVersion = FormatVersion2
+)
+
+// FormatVersion returns the format that backup's contents are stored in.
+func FormatVersion(backup *api.Backup) int {
+	if backup.Spec.ShardBy != "" {
+		return FormatVersion2
+	}
+	return FormatVersion1
+}
+
+// CheckFormatVersion returns an error if the format version recorded in
+// backup's status is newer than this version of Ark can read. Backups that
+// don't record a version are treated as FormatVersion1.
+func CheckFormatVersion(backup *api.Backup) error {
+	if backup.Status.Version > MaxFormatVersion {
+		return errors.Errorf("backup %s has format version %d, but this version of Ark can only read format versions up to %d; upgrade Ark to use it",
+			backup.Name, backup.Status.Version, MaxFormatVersion)
+	}
+	return nil
+}
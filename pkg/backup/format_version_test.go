@@ -0,0 +1,73 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+func TestFormatVersion(t *testing.T) {
+	assert.Equal(t, FormatVersion1, FormatVersion(&v1.Backup{}))
+	assert.Equal(t, FormatVersion2, FormatVersion(&v1.Backup{Spec: v1.BackupSpec{ShardBy: v1.BackupShardByNamespace}}))
+}
+
+func TestCheckFormatVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     int
+		expectedErr string
+	}{
+		{
+			name:    "unversioned backups are readable",
+			version: 0,
+		},
+		{
+			name:    "version 1 is readable",
+			version: FormatVersion1,
+		},
+		{
+			name:    "the newest version is readable",
+			version: MaxFormatVersion,
+		},
+		{
+			name:        "newer versions aren't readable",
+			version:     MaxFormatVersion + 1,
+			expectedErr: "backup backup-1 has format version 3, but this version of Ark can only read format versions up to 2; upgrade Ark to use it",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backup := &v1.Backup{
+				ObjectMeta: metav1.ObjectMeta{Name: "backup-1"},
+				Status:     v1.BackupStatus{Version: test.version},
+			}
+
+			err := CheckFormatVersion(backup)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedErr)
+			}
+		})
+	}
+}
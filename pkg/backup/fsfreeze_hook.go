@@ -0,0 +1,149 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+const fsfreezeCommand = "/sbin/fsfreeze"
+
+// frozenVolume is a volume that has been frozen by an fsfreeze hook.
+type frozenVolume struct {
+	hookName  string
+	volume    string
+	container string
+	mountPath string
+	hook      *api.FSFreezeHook
+}
+
+// freezeVolumes freezes each of the hook's volumes in the pod. If a volume can't be frozen, an
+// error is returned; volumes that were frozen remain tracked so they can be unfrozen.
+func (h *defaultItemHookHandler) freezeVolumes(log logrus.FieldLogger, obj runtime.Unstructured, hookName string, hook *api.FSFreezeHook) error {
+	metadata, err := meta.Accessor(obj)
+	if err != nil {
+		return errors.Wrap(err, "unable to get a metadata accessor")
+	}
+	key := metadata.GetNamespace() + "/" + metadata.GetName()
+
+	for _, volume := range hook.Volumes {
+		container, mountPath, err := getVolumeMount(obj.UnstructuredContent(), volume, hook.Container)
+		if err != nil {
+			return err
+		}
+
+		execHook := &api.ExecHook{
+			Container: container,
+			Command:   []string{fsfreezeCommand, "--freeze", mountPath},
+			OnError:   api.HookErrorModeFail,
+			Timeout:   hook.Timeout,
+		}
+		executions, err := h.podCommandExecutor.executePodCommand(log, obj.UnstructuredContent(), metadata.GetNamespace(), metadata.GetName(), hookName, execHook)
+		h.recordExecutions(hookPhasePre, executions)
+		if err != nil {
+			return errors.Wrapf(err, "error freezing volume %s", volume)
+		}
+
+		if h.frozenVolumes == nil {
+			h.frozenVolumes = make(map[string][]frozenVolume)
+		}
+		h.frozenVolumes[key] = append(h.frozenVolumes[key], frozenVolume{
+			hookName:  hookName,
+			volume:    volume,
+			container: container,
+			mountPath: mountPath,
+			hook:      hook,
+		})
+	}
+
+	return nil
+}
+
+// unfreezeVolumes unfreezes all of the volumes in the pod that were frozen by fsfreeze hooks, in
+// the reverse of the order they were frozen in. Errors are logged and recorded in the backup's
+// hook status, but are not returned, since there's nothing more that can be done.
+func (h *defaultItemHookHandler) unfreezeVolumes(log logrus.FieldLogger, obj runtime.Unstructured) {
+	metadata, err := meta.Accessor(obj)
+	if err != nil {
+		log.WithError(errors.WithStack(err)).Error("Error getting metadata accessor; unable to unfreeze volumes")
+		return
+	}
+	key := metadata.GetNamespace() + "/" + metadata.GetName()
+
+	frozen := h.frozenVolumes[key]
+	delete(h.frozenVolumes, key)
+
+	for i := len(frozen) - 1; i >= 0; i-- {
+		volume := frozen[i]
+
+		execHook := &api.ExecHook{
+			Container: volume.container,
+			Command:   []string{fsfreezeCommand, "--unfreeze", volume.mountPath},
+			OnError:   api.HookErrorModeContinue,
+			Timeout:   volume.hook.Timeout,
+		}
+		executions, err := h.podCommandExecutor.executePodCommand(log, obj.UnstructuredContent(), metadata.GetNamespace(), metadata.GetName(), volume.hookName, execHook)
+		h.recordExecutions(hookPhasePost, executions)
+		if err != nil {
+			log.WithError(err).WithField("volume", volume.volume).Error("Error unfreezing volume")
+		}
+	}
+}
+
+// getVolumeMount returns the container and path where the named volume is mounted in the pod. If
+// container is empty, the first container that mounts the volume is used.
+func getVolumeMount(pod map[string]interface{}, volume, container string) (string, string, error) {
+	containers, err := collections.GetSlice(pod, "spec.containers")
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, obj := range containers {
+		c, ok := obj.(map[string]interface{})
+		if !ok {
+			return "", "", errors.Errorf("unexpected type for container %T", obj)
+		}
+		name, _ := c["name"].(string)
+		if container != "" && name != container {
+			continue
+		}
+
+		mounts, _ := c["volumeMounts"].([]interface{})
+		for _, obj := range mounts {
+			mount, ok := obj.(map[string]interface{})
+			if !ok {
+				return "", "", errors.Errorf("unexpected type for volume mount %T", obj)
+			}
+			if mount["name"] == volume {
+				mountPath, _ := mount["mountPath"].(string)
+				return name, mountPath, nil
+			}
+		}
+	}
+
+	if container != "" {
+		return "", "", errors.Errorf("volume %q is not mounted in container %q", volume, container)
+	}
+	return "", "", errors.Errorf("volume %q is not mounted in any container", volume)
+}
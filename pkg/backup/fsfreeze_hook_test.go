@@ -0,0 +1,205 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/kuberesource"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+const fsfreezePodJSON = `
+{
+	"apiVersion": "v1",
+	"kind": "Pod",
+	"metadata": {
+		"namespace": "ns",
+		"name": "name"
+	},
+	"spec": {
+		"containers": [
+			{"name": "app", "volumeMounts": [{"name": "data", "mountPath": "/data"}]},
+			{"name": "sidecar", "volumeMounts": [{"name": "data", "mountPath": "/mnt/data"}, {"name": "logs", "mountPath": "/logs"}]}
+		]
+	}
+}`
+
+func TestGetVolumeMount(t *testing.T) {
+	tests := []struct {
+		name              string
+		volume            string
+		container         string
+		expectedContainer string
+		expectedMountPath string
+		expectedError     string
+	}{
+		{
+			name:              "first container that mounts the volume is used",
+			volume:            "data",
+			expectedContainer: "app",
+			expectedMountPath: "/data",
+		},
+		{
+			name:              "specified container is used",
+			volume:            "data",
+			container:         "sidecar",
+			expectedContainer: "sidecar",
+			expectedMountPath: "/mnt/data",
+		},
+		{
+			name:          "volume not mounted in specified container",
+			volume:        "logs",
+			container:     "app",
+			expectedError: `volume "logs" is not mounted in container "app"`,
+		},
+		{
+			name:          "volume not mounted",
+			volume:        "cache",
+			expectedError: `volume "cache" is not mounted in any container`,
+		},
+	}
+
+	pod := unstructuredOrDie(fsfreezePodJSON).UnstructuredContent()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			container, mountPath, err := getVolumeMount(pod, test.volume, test.container)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedContainer, container)
+			assert.Equal(t, test.expectedMountPath, mountPath)
+		})
+	}
+}
+
+func fsfreezeExecHook(container, op, mountPath string, onError v1.HookErrorMode) *v1.ExecHook {
+	return &v1.ExecHook{
+		Container: container,
+		Command:   []string{fsfreezeCommand, op, mountPath},
+		OnError:   onError,
+	}
+}
+
+func TestFSFreezeHookUnfreezesInPostPhase(t *testing.T) {
+	podCommandExecutor := &mockPodCommandExecutor{}
+	defer podCommandExecutor.AssertExpectations(t)
+
+	h := &defaultItemHookHandler{podCommandExecutor: podCommandExecutor}
+	pod := unstructuredOrDie(fsfreezePodJSON)
+	hooks := []resourceHook{
+		{
+			name: "freeze",
+			pre: []v1.BackupResourceHook{
+				{FSFreeze: &v1.FSFreezeHook{Volumes: []string{"data", "logs"}}},
+			},
+		},
+	}
+
+	var calls []string
+	record := func(args mock.Arguments) {
+		hook := args.Get(5).(*v1.ExecHook)
+		calls = append(calls, hook.Command[1]+" "+hook.Command[2])
+	}
+
+	for _, hook := range []*v1.ExecHook{
+		fsfreezeExecHook("app", "--freeze", "/data", v1.HookErrorModeFail),
+		fsfreezeExecHook("sidecar", "--freeze", "/logs", v1.HookErrorModeFail),
+		fsfreezeExecHook("sidecar", "--unfreeze", "/logs", v1.HookErrorModeContinue),
+		fsfreezeExecHook("app", "--unfreeze", "/data", v1.HookErrorModeContinue),
+	} {
+		podCommandExecutor.On("executePodCommand", mock.Anything, pod.UnstructuredContent(), "ns", "name", "freeze", hook).Run(record).Return(nil, nil)
+	}
+
+	require.NoError(t, h.handleHooks(arktest.NewLogger(), kuberesource.Pods, pod, hooks, hookPhasePre))
+	assert.Equal(t, []string{"--freeze /data", "--freeze /logs"}, calls)
+
+	require.NoError(t, h.handleHooks(arktest.NewLogger(), kuberesource.Pods, pod, hooks, hookPhasePost))
+	assert.Equal(t, []string{"--freeze /data", "--freeze /logs", "--unfreeze /logs", "--unfreeze /data"}, calls)
+
+	// volumes are only unfrozen once
+	h.unfreezeVolumes(arktest.NewLogger(), pod)
+	assert.Len(t, calls, 4)
+}
+
+func TestFSFreezeHookUnfreezesOnFailure(t *testing.T) {
+	podCommandExecutor := &mockPodCommandExecutor{}
+	defer podCommandExecutor.AssertExpectations(t)
+
+	h := &defaultItemHookHandler{podCommandExecutor: podCommandExecutor}
+	pod := unstructuredOrDie(fsfreezePodJSON)
+	hooks := []resourceHook{
+		{
+			name: "freeze",
+			pre: []v1.BackupResourceHook{
+				{FSFreeze: &v1.FSFreezeHook{Volumes: []string{"data", "logs"}}},
+			},
+		},
+	}
+
+	podCommandExecutor.On("executePodCommand", mock.Anything, pod.UnstructuredContent(), "ns", "name", "freeze", fsfreezeExecHook("app", "--freeze", "/data", v1.HookErrorModeFail)).Return(nil, nil)
+	podCommandExecutor.On("executePodCommand", mock.Anything, pod.UnstructuredContent(), "ns", "name", "freeze", fsfreezeExecHook("sidecar", "--freeze", "/logs", v1.HookErrorModeFail)).Return(nil, errors.New("freeze failed"))
+	podCommandExecutor.On("executePodCommand", mock.Anything, pod.UnstructuredContent(), "ns", "name", "freeze", fsfreezeExecHook("app", "--unfreeze", "/data", v1.HookErrorModeContinue)).Return(nil, nil)
+
+	err := h.handleHooks(arktest.NewLogger(), kuberesource.Pods, pod, hooks, hookPhasePre)
+	assert.EqualError(t, err, "error freezing volume logs: freeze failed")
+	assert.Empty(t, h.frozenVolumes)
+}
+
+func TestValidateFSFreezeHooks(t *testing.T) {
+	hooks := v1.BackupHooks{
+		Resources: []v1.BackupResourceHookSpec{
+			{
+				Name:     "valid",
+				PreHooks: []v1.BackupResourceHook{{FSFreeze: &v1.FSFreezeHook{Volumes: []string{"data"}}}},
+			},
+			{
+				Name:      "post",
+				PostHooks: []v1.BackupResourceHook{{FSFreeze: &v1.FSFreezeHook{Volumes: []string{"data"}}}},
+			},
+			{
+				Name:     "no-volumes",
+				PreHooks: []v1.BackupResourceHook{{FSFreeze: &v1.FSFreezeHook{}}},
+			},
+			{
+				Name:     "both",
+				PreHooks: []v1.BackupResourceHook{{Exec: &v1.ExecHook{}, FSFreeze: &v1.FSFreezeHook{Volumes: []string{"data"}}}},
+			},
+		},
+	}
+
+	var msgs []string
+	for _, err := range ValidateHooks(hooks) {
+		msgs = append(msgs, err.Error())
+	}
+
+	assert.Equal(t, []string{
+		`hook "post": fsfreeze hooks may only be specified as pre hooks`,
+		`hook "no-volumes": fsfreeze hooks must specify at least one volume`,
+		`hook "both": only one of exec and fsfreeze may be specified`,
+	}, msgs)
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/discovery"
 	"github.com/heptio/ark/pkg/util/collections"
+	"github.com/heptio/ark/pkg/util/tracing"
 )
 
 type groupBackupperFactory interface {
@@ -45,6 +46,7 @@ type groupBackupperFactory interface {
 		backedUpItems map[itemKey]struct{},
 		cohabitatingResources map[string]*cohabitatingResource,
 		actions []resolvedAction,
+		sanitizers []resolvedSanitizer,
 		podCommandExecutor podCommandExecutor,
 		tarWriter tarWriter,
 		resourceHooks []resourceHook,
@@ -64,6 +66,7 @@ func (f *defaultGroupBackupperFactory) newGroupBackupper(
 	backedUpItems map[itemKey]struct{},
 	cohabitatingResources map[string]*cohabitatingResource,
 	actions []resolvedAction,
+	sanitizers []resolvedSanitizer,
 	podCommandExecutor podCommandExecutor,
 	tarWriter tarWriter,
 	resourceHooks []resourceHook,
@@ -80,6 +83,7 @@ func (f *defaultGroupBackupperFactory) newGroupBackupper(
 		backedUpItems:            backedUpItems,
 		cohabitatingResources:    cohabitatingResources,
 		actions:                  actions,
+		sanitizers:               sanitizers,
 		podCommandExecutor:       podCommandExecutor,
 		tarWriter:                tarWriter,
 		resourceHooks:            resourceHooks,
@@ -102,6 +106,7 @@ type defaultGroupBackupper struct {
 	backedUpItems            map[itemKey]struct{}
 	cohabitatingResources    map[string]*cohabitatingResource
 	actions                  []resolvedAction
+	sanitizers               []resolvedSanitizer
 	podCommandExecutor       podCommandExecutor
 	tarWriter                tarWriter
 	resourceHooks            []resourceHook
@@ -125,6 +130,7 @@ func (gb *defaultGroupBackupper) backupGroup(group *metav1.APIResourceList) erro
 			gb.backedUpItems,
 			gb.cohabitatingResources,
 			gb.actions,
+			gb.sanitizers,
 			gb.podCommandExecutor,
 			gb.tarWriter,
 			gb.resourceHooks,
@@ -132,6 +138,10 @@ func (gb *defaultGroupBackupper) backupGroup(group *metav1.APIResourceList) erro
 		)
 	)
 
+	span := tracing.StartSpan(gb.backup.Status.OperationID, "backupGroup")
+	span.SetTag("group", group.GroupVersion)
+	defer span.Finish()
+
 	log.Infof("Backing up group")
 
 	// Parse so we can check if this is the core group
@@ -146,6 +156,10 @@ func (gb *defaultGroupBackupper) backupGroup(group *metav1.APIResourceList) erro
 	}
 
 	for _, resource := range group.APIResources {
+		if abortBackup(gb.backup, errs) {
+			break
+		}
+
 		if err := rb.backupResource(group, resource); err != nil {
 			errs = append(errs, err)
 		}
@@ -30,7 +30,6 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestBackupGroup(t *testing.T) {
@@ -50,11 +49,7 @@ func TestBackupGroup(t *testing.T) {
 	}
 
 	cohabitatingResources := map[string]*cohabitatingResource{
-		"a": {
-			resource:       "a",
-			groupResource1: schema.GroupResource{Group: "g1", Resource: "a"},
-			groupResource2: schema.GroupResource{Group: "g2", Resource: "a"},
-		},
+		"a": newCohabitatingResource("a", "g1", "g2"),
 	}
 
 	actions := []resolvedAction{
@@ -64,6 +59,13 @@ func TestBackupGroup(t *testing.T) {
 		},
 	}
 
+	sanitizers := []resolvedSanitizer{
+		{
+			BackupSanitizer:          v1.BackupSanitizer{RemoveFields: []string{"data"}},
+			resourceIncludesExcludes: collections.NewIncludesExcludes().Includes("secrets"),
+		},
+	}
+
 	podCommandExecutor := &mockPodCommandExecutor{}
 	defer podCommandExecutor.AssertExpectations(t)
 
@@ -84,6 +86,7 @@ func TestBackupGroup(t *testing.T) {
 		backedUpItems,
 		cohabitatingResources,
 		actions,
+		sanitizers,
 		podCommandExecutor,
 		tarWriter,
 		resourceHooks,
@@ -108,6 +111,7 @@ func TestBackupGroup(t *testing.T) {
 		backedUpItems,
 		cohabitatingResources,
 		actions,
+		sanitizers,
 		podCommandExecutor,
 		tarWriter,
 		resourceHooks,
@@ -156,6 +160,7 @@ func (rbf *mockResourceBackupperFactory) newResourceBackupper(
 	backedUpItems map[itemKey]struct{},
 	cohabitatingResources map[string]*cohabitatingResource,
 	actions []resolvedAction,
+	sanitizers []resolvedSanitizer,
 	podCommandExecutor podCommandExecutor,
 	tarWriter tarWriter,
 	resourceHooks []resourceHook,
@@ -172,6 +177,7 @@ func (rbf *mockResourceBackupperFactory) newResourceBackupper(
 		backedUpItems,
 		cohabitatingResources,
 		actions,
+		sanitizers,
 		podCommandExecutor,
 		tarWriter,
 		resourceHooks,
@@ -20,6 +20,7 @@ import (
 	"archive/tar"
 	"encoding/json"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -38,6 +39,7 @@ import (
 	"github.com/heptio/ark/pkg/discovery"
 	"github.com/heptio/ark/pkg/util/collections"
 	"github.com/heptio/ark/pkg/util/logging"
+	"github.com/heptio/ark/pkg/util/tracing"
 )
 
 type itemBackupperFactory interface {
@@ -46,6 +48,7 @@ type itemBackupperFactory interface {
 		namespaces, resources *collections.IncludesExcludes,
 		backedUpItems map[itemKey]struct{},
 		actions []resolvedAction,
+		sanitizers []resolvedSanitizer,
 		podCommandExecutor podCommandExecutor,
 		tarWriter tarWriter,
 		resourceHooks []resourceHook,
@@ -62,6 +65,7 @@ func (f *defaultItemBackupperFactory) newItemBackupper(
 	namespaces, resources *collections.IncludesExcludes,
 	backedUpItems map[itemKey]struct{},
 	actions []resolvedAction,
+	sanitizers []resolvedSanitizer,
 	podCommandExecutor podCommandExecutor,
 	tarWriter tarWriter,
 	resourceHooks []resourceHook,
@@ -75,6 +79,7 @@ func (f *defaultItemBackupperFactory) newItemBackupper(
 		resources:       resources,
 		backedUpItems:   backedUpItems,
 		actions:         actions,
+		sanitizers:      sanitizers,
 		tarWriter:       tarWriter,
 		resourceHooks:   resourceHooks,
 		dynamicFactory:  dynamicFactory,
@@ -82,6 +87,7 @@ func (f *defaultItemBackupperFactory) newItemBackupper(
 		snapshotService: snapshotService,
 		itemHookHandler: &defaultItemHookHandler{
 			podCommandExecutor: podCommandExecutor,
+			backup:             backup,
 		},
 	}
 
@@ -101,6 +107,7 @@ type defaultItemBackupper struct {
 	resources       *collections.IncludesExcludes
 	backedUpItems   map[itemKey]struct{}
 	actions         []resolvedAction
+	sanitizers      []resolvedSanitizer
 	tarWriter       tarWriter
 	resourceHooks   []resourceHook
 	dynamicFactory  client.DynamicFactory
@@ -109,6 +116,152 @@ type defaultItemBackupper struct {
 
 	itemHookHandler         itemHookHandler
 	additionalItemBackupper ItemBackupper
+
+	// additionalItemDepth is how many levels of additional items deep the
+	// item currently being backed up is.
+	additionalItemDepth int
+
+	// additionalItemClients caches the dynamic clients used to get additional
+	// items, keyed by resource and namespace.
+	additionalItemClients map[additionalItemClientKey]client.Dynamic
+}
+
+// DefaultMaxAdditionalItemDepth is how many levels of additional items are backed up
+// for backups that don't set spec.maxAdditionalItemDepth.
+const DefaultMaxAdditionalItemDepth = 10
+
+// maxConcurrentAdditionalItemGets is how many of an action's additional items are
+// fetched from the API server at once.
+const maxConcurrentAdditionalItemGets = 10
+
+type additionalItemClientKey struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+// fetchedItem is an additional item fetched from the API server, along with the
+// resource it was resolved to.
+type fetchedItem struct {
+	groupResource schema.GroupResource
+	obj           runtime.Unstructured
+}
+
+// maxAdditionalItemDepth returns how many levels of additional items the backup allows.
+func (ib *defaultItemBackupper) maxAdditionalItemDepth() int {
+	if ib.backup.Spec.MaxAdditionalItemDepth > 0 {
+		return ib.backup.Spec.MaxAdditionalItemDepth
+	}
+	return DefaultMaxAdditionalItemDepth
+}
+
+// fetchAdditionalItems gets the additional items an action returned from the API server,
+// leaving out ones that are already in the backup or are deeper than the backup's
+// maxAdditionalItemDepth. The items are fetched concurrently, and returned in the order
+// of identifiers.
+func (ib *defaultItemBackupper) fetchAdditionalItems(log logrus.FieldLogger, identifiers []ResourceIdentifier) ([]fetchedItem, error) {
+	type get struct {
+		groupResource schema.GroupResource
+		client        client.Dynamic
+		name          string
+	}
+
+	var (
+		gets []get
+		seen = make(map[itemKey]struct{})
+	)
+	for _, identifier := range identifiers {
+		itemLog := log.WithFields(logrus.Fields{
+			"additionalItemResource":  identifier.GroupResource.String(),
+			"additionalItemNamespace": identifier.Namespace,
+			"additionalItemName":      identifier.Name,
+		})
+
+		if maxDepth := ib.maxAdditionalItemDepth(); ib.additionalItemDepth >= maxDepth {
+			itemLog.Warnf("Not backing up additional item because the backup's maxAdditionalItemDepth (%d) has been reached", maxDepth)
+			continue
+		}
+
+		gvr, resource, err := discovery.ResourceForWithRefresh(ib.discoveryHelper, identifier.GroupResource.WithVersion(""))
+		if err != nil {
+			return nil, err
+		}
+
+		// actions can return items that lead back to ones already in the backup,
+		// so don't fetch those again
+		groupResource := gvr.GroupResource()
+		key := itemKey{
+			resource:  groupResource.String(),
+			namespace: identifier.Namespace,
+			name:      identifier.Name,
+		}
+		if _, exists := ib.backedUpItems[key]; exists {
+			itemLog.Debug("Skipping additional item because it's already been backed up")
+			continue
+		}
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		dynamicClient, err := ib.additionalItemClient(gvr, resource, identifier.Namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		gets = append(gets, get{groupResource: groupResource, client: dynamicClient, name: identifier.Name})
+	}
+
+	var (
+		items     = make([]fetchedItem, len(gets))
+		errs      = make([]error, len(gets))
+		semaphore = make(chan struct{}, maxConcurrentAdditionalItemGets)
+		wg        sync.WaitGroup
+	)
+	for i := range gets {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(i int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			obj, err := gets[i].client.Get(gets[i].name, metav1.GetOptions{})
+			items[i] = fetchedItem{groupResource: gets[i].groupResource, obj: obj}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
+// additionalItemClient returns a dynamic client for resource in namespace, reusing the
+// one created for an earlier additional item if there is one.
+func (ib *defaultItemBackupper) additionalItemClient(gvr schema.GroupVersionResource, resource metav1.APIResource, namespace string) (client.Dynamic, error) {
+	key := additionalItemClientKey{gvr: gvr, namespace: namespace}
+	if dynamicClient, ok := ib.additionalItemClients[key]; ok {
+		return dynamicClient, nil
+	}
+
+	dynamicClient, err := ib.dynamicFactory.ClientForGroupVersionResource(gvr.GroupVersion(), resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if ib.additionalItemClients == nil {
+		ib.additionalItemClients = make(map[additionalItemClientKey]client.Dynamic)
+	}
+	ib.additionalItemClients[key] = dynamicClient
+
+	return dynamicClient, nil
 }
 
 // backupItem backs up an individual item to tarWriter. The item may be excluded based on the
@@ -135,8 +288,11 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 	}
 
 	// NOTE: we specifically allow namespaces to be backed up even if IncludeClusterResources is
-	// false.
-	if namespace == "" && groupResource != kuberesource.Namespaces && ib.backup.Spec.IncludeClusterResources != nil && !*ib.backup.Spec.IncludeClusterResources {
+	// false. PersistentVolumes are allowed too: when IncludeClusterResources is false they're
+	// only backed up (and snapshotted) as the volumes bound to included PersistentVolumeClaims,
+	// which can't be restored with their data without them.
+	if namespace == "" && groupResource != kuberesource.Namespaces && groupResource != kuberesource.PersistentVolumes &&
+		ib.backup.Spec.IncludeClusterResources != nil && !*ib.backup.Spec.IncludeClusterResources {
 		log.Info("Excluding item because resource is cluster-scoped and backup.spec.includeClusterResources is false")
 		return nil
 	}
@@ -160,10 +316,21 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 
 	log.Info("Backing up resource")
 
+	span := tracing.StartSpan(ib.backup.Status.OperationID, "backupItem")
+	span.SetTag("resource", groupResource.String())
+	span.SetTag("namespace", namespace)
+	span.SetTag("name", name)
+	defer span.Finish()
+
 	log.Debug("Executing pre hooks")
 	if err := ib.itemHookHandler.handleHooks(log, groupResource, obj, ib.resourceHooks, hookPhasePre); err != nil {
 		return err
 	}
+	if groupResource == kuberesource.Pods {
+		// Volumes frozen by pre hooks are normally unfrozen by the post hooks, but make sure
+		// they're unfrozen if the pod fails to back up.
+		defer ib.itemHookHandler.unfreezeVolumes(log, obj)
+	}
 
 	for _, action := range ib.actions {
 		if !action.resourceIncludesExcludes.ShouldInclude(groupResource.String()) {
@@ -181,34 +348,36 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 			continue
 		}
 
+		if !collections.MatchesFieldSelector(action.fieldSelector, obj.UnstructuredContent()) {
+			log.Debug("Skipping action because field selector does not match")
+			continue
+		}
+
 		log.Info("Executing custom action")
 
 		if logSetter, ok := action.ItemAction.(logging.LogSetter); ok {
 			logSetter.SetLog(log)
 		}
 
-		if updatedItem, additionalItemIdentifiers, err := action.Execute(obj, ib.backup); err == nil {
-			obj = updatedItem
+		actionSpan := span.StartChild("itemAction")
+		updatedItem, additionalItemIdentifiers, err := action.Execute(obj, ib.backup)
+		actionSpan.Finish()
 
-			for _, additionalItem := range additionalItemIdentifiers {
-				gvr, resource, err := ib.discoveryHelper.ResourceFor(additionalItem.GroupResource.WithVersion(""))
-				if err != nil {
-					return err
-				}
+		if err == nil {
+			obj = updatedItem
 
-				client, err := ib.dynamicFactory.ClientForGroupVersionResource(gvr.GroupVersion(), resource, additionalItem.Namespace)
-				if err != nil {
-					return err
-				}
+			additionalItems, err := ib.fetchAdditionalItems(log, additionalItemIdentifiers)
+			if err != nil {
+				return err
+			}
 
-				additionalItem, err := client.Get(additionalItem.Name, metav1.GetOptions{})
+			for _, additionalItem := range additionalItems {
+				ib.additionalItemDepth++
+				err := ib.additionalItemBackupper.backupItem(log, additionalItem.obj, additionalItem.groupResource)
+				ib.additionalItemDepth--
 				if err != nil {
 					return err
 				}
-
-				if err = ib.additionalItemBackupper.backupItem(log, additionalItem, gvr.GroupResource()); err != nil {
-					return err
-				}
 			}
 		} else {
 			// We want this to show up in the log file at the place where the error occurs. When we return
@@ -224,7 +393,11 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 		if ib.snapshotService == nil {
 			log.Debug("Skipping Persistent Volume snapshot because they're not enabled.")
 		} else {
-			if err := ib.takePVSnapshot(obj, ib.backup, log); err != nil {
+			snapshotSpan := span.StartChild("snapshot")
+			err := ib.takePVSnapshot(obj, ib.backup, log)
+			snapshotSpan.Finish()
+
+			if err != nil {
 				return err
 			}
 		}
@@ -242,11 +415,48 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 		filePath = filepath.Join(api.ResourcesDir, groupResource.String(), api.ClusterScopedDir, name+".json")
 	}
 
-	itemBytes, err := json.Marshal(obj.UnstructuredContent())
+	// sanitizers only change what's written to the backup, not the item that's used above to
+	// snapshot its volume and run its post hooks
+	itemBytes, err := json.Marshal(sanitize(log, ib.sanitizers, obj, groupResource))
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	if limit := ib.backup.Spec.MaxItemSizeBytes; limit > 0 && int64(len(itemBytes)) > limit {
+		var (
+			skip    = ib.backup.Spec.LargeItemAction == api.LargeItemActionSkip
+			offload = ib.backup.Spec.LargeItemAction == api.LargeItemActionOffload
+		)
+
+		ib.backup.Status.LargeItemCount++
+		if offload {
+			ib.backup.Status.OffloadedItemCount++
+		}
+		if len(ib.backup.Status.LargeItems) < api.MaxRecordedLargeItems {
+			ib.backup.Status.LargeItems = append(ib.backup.Status.LargeItems, api.LargeItem{
+				Resource:  groupResource.String(),
+				Namespace: namespace,
+				Name:      name,
+				SizeBytes: int64(len(itemBytes)),
+				Skipped:   skip,
+				Offloaded: offload,
+			})
+		}
+
+		switch {
+		case skip:
+			log.Warnf("Skipping item because its size (%d bytes) is larger than the backup's maxItemSizeBytes (%d)", len(itemBytes), limit)
+			return nil
+		case offload:
+			// the backup service stores items in the large items directory as separate objects
+			// when the backup is uploaded
+			log.Warnf("Offloading item because its size (%d bytes) is larger than the backup's maxItemSizeBytes (%d)", len(itemBytes), limit)
+			filePath = filepath.Join(api.LargeItemsDir, filePath)
+		default:
+			log.Warnf("Item's size (%d bytes) is larger than the backup's maxItemSizeBytes (%d)", len(itemBytes), limit)
+		}
+	}
+
 	hdr := &tar.Header{
 		Name:     filePath,
 		Size:     int64(len(itemBytes)),
@@ -270,10 +480,19 @@ func (ib *defaultItemBackupper) backupItem(logger logrus.FieldLogger, obj runtim
 // on PVs
 const zoneLabel = "failure-domain.beta.kubernetes.io/zone"
 
+// takePVSnapshot triggers a snapshot for the volume/disk underlying a PersistentVolume using the
+// item backupper's snapshot service.
+func (ib *defaultItemBackupper) takePVSnapshot(pv runtime.Unstructured, backup *api.Backup, log logrus.FieldLogger) error {
+	return takePVSnapshot(ib.snapshotService, pv, backup, log)
+}
+
 // takePVSnapshot triggers a snapshot for the volume/disk underlying a PersistentVolume if the provided
 // backup has volume snapshots enabled and the PV is of a compatible type. Also records cloud
-// disk type and IOPS (if applicable) to be able to restore to current state later.
-func (ib *defaultItemBackupper) takePVSnapshot(pv runtime.Unstructured, backup *api.Backup, log logrus.FieldLogger) error {
+// disk type and IOPS (if applicable) to be able to restore to current state later. The snapshot
+// is recorded as InProgress; the backup controller tracks it to completion in the background.
+// PVs that already have a snapshot recorded in the backup, such as those snapshotted with their
+// consistency group, are skipped.
+func takePVSnapshot(snapshotService cloudprovider.SnapshotService, pv runtime.Unstructured, backup *api.Backup, log logrus.FieldLogger) error {
 	log.Info("Executing takePVSnapshot")
 
 	if backup.Spec.SnapshotVolumes != nil && !*backup.Spec.SnapshotVolumes {
@@ -287,6 +506,11 @@ func (ib *defaultItemBackupper) takePVSnapshot(pv runtime.Unstructured, backup *
 	}
 
 	name := metadata.GetName()
+	if _, ok := backup.Status.VolumeBackups[name]; ok {
+		log.Info("PersistentVolume has already been snapshotted, skipping.")
+		return nil
+	}
+
 	var pvFailureDomainZone string
 	labels := metadata.GetLabels()
 
@@ -296,7 +520,7 @@ func (ib *defaultItemBackupper) takePVSnapshot(pv runtime.Unstructured, backup *
 		log.Infof("label %q is not present on PersistentVolume", zoneLabel)
 	}
 
-	volumeID, err := ib.snapshotService.GetVolumeID(pv)
+	volumeID, err := snapshotService.GetVolumeID(pv)
 	if err != nil {
 		return errors.Wrapf(err, "error getting volume ID for PersistentVolume")
 	}
@@ -308,19 +532,19 @@ func (ib *defaultItemBackupper) takePVSnapshot(pv runtime.Unstructured, backup *
 	log = log.WithField("volumeID", volumeID)
 
 	tags := map[string]string{
-		"ark.heptio.com/backup": backup.Name,
-		"ark.heptio.com/pv":     metadata.GetName(),
+		api.SnapshotBackupTagKey: backup.Name,
+		api.SnapshotPVTagKey:     metadata.GetName(),
 	}
 
 	log.Info("Snapshotting PersistentVolume")
-	snapshotID, err := ib.snapshotService.CreateSnapshot(volumeID, pvFailureDomainZone, tags)
+	snapshotID, err := snapshotService.CreateSnapshot(volumeID, pvFailureDomainZone, tags)
 	if err != nil {
 		// log+error on purpose - log goes to the per-backup log file, error goes to the backup
 		log.WithError(err).Error("error creating snapshot")
 		return errors.WithMessage(err, "error creating snapshot")
 	}
 
-	volumeType, iops, err := ib.snapshotService.GetVolumeInfo(volumeID, pvFailureDomainZone)
+	volumeType, iops, err := snapshotService.GetVolumeInfo(volumeID, pvFailureDomainZone)
 	if err != nil {
 		log.WithError(err).Error("error getting volume info")
 		return errors.WithMessage(err, "error getting volume info")
@@ -335,7 +559,22 @@ func (ib *defaultItemBackupper) takePVSnapshot(pv runtime.Unstructured, backup *
 		Type:             volumeType,
 		Iops:             iops,
 		AvailabilityZone: pvFailureDomainZone,
+		Phase:            api.SnapshotPhaseInProgress,
 	}
 
+	// the claim and capacity are optional, so they're left empty if they're missing
+	claimNamespace, _ := collections.GetString(pv.UnstructuredContent(), "spec.claimRef.namespace")
+	claimName, _ := collections.GetString(pv.UnstructuredContent(), "spec.claimRef.name")
+	size, _ := collections.GetString(pv.UnstructuredContent(), "spec.capacity.storage")
+
+	backup.Status.VolumeSnapshots = append(backup.Status.VolumeSnapshots, api.VolumeSnapshotStatus{
+		PersistentVolume:      name,
+		Namespace:             claimNamespace,
+		PersistentVolumeClaim: claimName,
+		SnapshotID:            snapshotID,
+		Size:                  size,
+		Phase:                 api.SnapshotPhaseInProgress,
+	})
+
 	return nil
 }
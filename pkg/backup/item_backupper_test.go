@@ -123,6 +123,37 @@ func TestBackupItemSkipsClusterScopedResourceWhenIncludeClusterResourcesFalse(t
 	assert.NoError(t, err)
 }
 
+func TestBackupItemIncludesPersistentVolumeWhenIncludeClusterResourcesFalse(t *testing.T) {
+	itemHookHandler := &mockItemHookHandler{}
+	defer itemHookHandler.AssertExpectations(t)
+
+	w := &fakeTarWriter{}
+
+	f := false
+	ib := &defaultItemBackupper{
+		backup: &v1.Backup{
+			Spec: v1.BackupSpec{
+				IncludeClusterResources: &f,
+			},
+		},
+		namespaces:      collections.NewIncludesExcludes().Includes("ns"),
+		resources:       collections.NewIncludesExcludes(),
+		backedUpItems:   make(map[itemKey]struct{}),
+		itemHookHandler: itemHookHandler,
+		tarWriter:       w,
+	}
+
+	pv := unstructuredOrDie(`{"apiVersion":"v1","kind":"PersistentVolume","metadata":{"name":"pv-1"}}`)
+	groupResource := schema.GroupResource{Resource: "persistentvolumes"}
+
+	itemHookHandler.On("handleHooks", mock.Anything, groupResource, pv, []resourceHook(nil), hookPhasePre).Return(nil)
+	itemHookHandler.On("handleHooks", mock.Anything, groupResource, pv, []resourceHook(nil), hookPhasePost).Return(nil)
+
+	require.NoError(t, ib.backupItem(arktest.NewLogger(), pv, groupResource))
+	require.Len(t, w.headers, 1)
+	assert.Equal(t, "resources/persistentvolumes/cluster/pv-1.json", w.headers[0].Name)
+}
+
 func TestBackupItemNoSkips(t *testing.T) {
 	tests := []struct {
 		name                                  string
@@ -347,6 +378,7 @@ func TestBackupItemNoSkips(t *testing.T) {
 				resources,
 				backedUpItems,
 				actions,
+				nil,
 				podCommandExecutor,
 				w,
 				resourceHooks,
@@ -384,9 +416,7 @@ func TestBackupItemNoSkips(t *testing.T) {
 			}
 
 			for i, item := range test.customActionAdditionalItemIdentifiers {
-				if test.additionalItemError != nil && i > 0 {
-					break
-				}
+				// all of an action's additional items are fetched before any of them are backed up
 				itemClient := &arktest.FakeDynamicClient{}
 				defer itemClient.AssertExpectations(t)
 
@@ -394,6 +424,9 @@ func TestBackupItemNoSkips(t *testing.T) {
 
 				itemClient.On("Get", item.Name, metav1.GetOptions{}).Return(test.customActionAdditionalItems[i], nil)
 
+				if test.additionalItemError != nil && i > 0 {
+					continue
+				}
 				additionalItemBackupper.On("backupItem", mock.AnythingOfType("*logrus.Entry"), test.customActionAdditionalItems[i], item.GroupResource).Return(test.additionalItemError)
 			}
 
@@ -453,6 +486,8 @@ func TestBackupItemNoSkips(t *testing.T) {
 
 				var expectedBackups []api.VolumeBackupInfo
 				for _, vbi := range test.snapshottableVolumes {
+					// snapshots are tracked to completion by the backup controller
+					vbi.Phase = api.SnapshotPhaseInProgress
 					expectedBackups = append(expectedBackups, vbi)
 				}
 
@@ -467,6 +502,309 @@ func TestBackupItemNoSkips(t *testing.T) {
 	}
 }
 
+type failingAction struct{}
+
+func (a *failingAction) Execute(item runtime.Unstructured, backup *v1.Backup) (runtime.Unstructured, []ResourceIdentifier, error) {
+	return nil, nil, errors.New("action failed")
+}
+
+func (a *failingAction) AppliesTo() (ResourceSelector, error) {
+	return ResourceSelector{}, nil
+}
+
+func TestBackupItemUnfreezesVolumesOnFailure(t *testing.T) {
+	itemHookHandler := &mockItemHookHandler{}
+	defer itemHookHandler.AssertExpectations(t)
+
+	ib := &defaultItemBackupper{
+		backup:          &v1.Backup{},
+		namespaces:      collections.NewIncludesExcludes(),
+		resources:       collections.NewIncludesExcludes(),
+		backedUpItems:   make(map[itemKey]struct{}),
+		itemHookHandler: itemHookHandler,
+		actions: []resolvedAction{
+			{
+				ItemAction:                &failingAction{},
+				namespaceIncludesExcludes: collections.NewIncludesExcludes(),
+				resourceIncludesExcludes:  collections.NewIncludesExcludes(),
+				selector:                  labels.Everything(),
+			},
+		},
+	}
+
+	pod := unstructuredOrDie(`{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"ns","name":"pod"}}`)
+	groupResource := schema.GroupResource{Resource: "pods"}
+
+	itemHookHandler.On("handleHooks", mock.Anything, groupResource, pod, []resourceHook(nil), hookPhasePre).Return(nil)
+	itemHookHandler.On("unfreezeVolumes", mock.Anything, pod)
+
+	err := ib.backupItem(arktest.NewLogger(), pod, groupResource)
+	assert.EqualError(t, err, "error executing custom action (groupResource=pods, namespace=ns, name=pod): action failed")
+}
+
+func TestBackupItemLargeItems(t *testing.T) {
+	tests := []struct {
+		name               string
+		maxItemSizeBytes   int64
+		largeItemAction    v1.LargeItemAction
+		expectBackedUp     bool
+		expectedPath       string
+		expectedLargeItems []v1.LargeItem
+	}{
+		{
+			name:           "no limit backs up the item",
+			expectBackedUp: true,
+		},
+		{
+			name:             "item under the limit is backed up",
+			maxItemSizeBytes: 1024,
+			largeItemAction:  v1.LargeItemActionSkip,
+			expectBackedUp:   true,
+		},
+		{
+			name:             "item over the limit is backed up and recorded with Warn",
+			maxItemSizeBytes: 10,
+			largeItemAction:  v1.LargeItemActionWarn,
+			expectBackedUp:   true,
+			expectedLargeItems: []v1.LargeItem{
+				{Resource: "configmaps", Namespace: "ns", Name: "cm", SizeBytes: 80, Skipped: false},
+			},
+		},
+		{
+			name:             "item over the limit is written to the large items directory and recorded with Offload",
+			maxItemSizeBytes: 10,
+			largeItemAction:  v1.LargeItemActionOffload,
+			expectBackedUp:   true,
+			expectedPath:     "large-items/resources/configmaps/namespaces/ns/cm.json",
+			expectedLargeItems: []v1.LargeItem{
+				{Resource: "configmaps", Namespace: "ns", Name: "cm", SizeBytes: 80, Offloaded: true},
+			},
+		},
+		{
+			name:             "item over the limit is skipped and recorded with Skip",
+			maxItemSizeBytes: 10,
+			largeItemAction:  v1.LargeItemActionSkip,
+			expectedLargeItems: []v1.LargeItem{
+				{Resource: "configmaps", Namespace: "ns", Name: "cm", SizeBytes: 80, Skipped: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			itemHookHandler := &mockItemHookHandler{}
+			defer itemHookHandler.AssertExpectations(t)
+
+			w := &fakeTarWriter{}
+
+			backup := &v1.Backup{
+				Spec: v1.BackupSpec{
+					MaxItemSizeBytes: test.maxItemSizeBytes,
+					LargeItemAction:  test.largeItemAction,
+				},
+			}
+
+			ib := &defaultItemBackupper{
+				backup:          backup,
+				namespaces:      collections.NewIncludesExcludes(),
+				resources:       collections.NewIncludesExcludes(),
+				backedUpItems:   make(map[itemKey]struct{}),
+				itemHookHandler: itemHookHandler,
+				tarWriter:       w,
+			}
+
+			item := unstructuredOrDie(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns","name":"cm"}}`)
+			groupResource := schema.GroupResource{Resource: "configmaps"}
+
+			itemHookHandler.On("handleHooks", mock.Anything, groupResource, item, []resourceHook(nil), hookPhasePre).Return(nil)
+			itemHookHandler.On("handleHooks", mock.Anything, groupResource, item, []resourceHook(nil), hookPhasePost).Return(nil)
+
+			require.NoError(t, ib.backupItem(arktest.NewLogger(), item, groupResource))
+
+			if test.expectBackedUp {
+				require.Len(t, w.headers, 1)
+
+				expectedPath := test.expectedPath
+				if expectedPath == "" {
+					expectedPath = "resources/configmaps/namespaces/ns/cm.json"
+				}
+				assert.Equal(t, expectedPath, w.headers[0].Name)
+			} else {
+				assert.Empty(t, w.headers)
+			}
+			assert.Equal(t, test.expectedLargeItems, backup.Status.LargeItems)
+			assert.Equal(t, len(test.expectedLargeItems), backup.Status.LargeItemCount)
+		})
+	}
+}
+
+// chainAction returns, as an additional item, the config map that next maps the item's name to.
+type chainAction struct {
+	next map[string]string
+}
+
+func (a *chainAction) Execute(item runtime.Unstructured, backup *v1.Backup) (runtime.Unstructured, []ResourceIdentifier, error) {
+	next, ok := a.next[item.UnstructuredContent()["metadata"].(map[string]interface{})["name"].(string)]
+	if !ok {
+		return item, nil, nil
+	}
+
+	return item, []ResourceIdentifier{{GroupResource: schema.GroupResource{Resource: "configmaps"}, Namespace: "ns", Name: next}}, nil
+}
+
+func (a *chainAction) AppliesTo() (ResourceSelector, error) {
+	return ResourceSelector{}, nil
+}
+
+func TestBackupItemAdditionalItemLimits(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxDepth        int
+		next            map[string]string
+		expectedFetched []string
+	}{
+		{
+			name:            "chain shorter than the limit is backed up",
+			maxDepth:        5,
+			next:            map[string]string{"cm-0": "cm-1", "cm-1": "cm-2"},
+			expectedFetched: []string{"cm-1", "cm-2"},
+		},
+		{
+			name:            "chain longer than the limit is truncated",
+			maxDepth:        2,
+			next:            map[string]string{"cm-0": "cm-1", "cm-1": "cm-2", "cm-2": "cm-3", "cm-3": "cm-4"},
+			expectedFetched: []string{"cm-1", "cm-2"},
+		},
+		{
+			name:            "default limit applies when the backup doesn't set one",
+			next:            map[string]string{"cm-0": "cm-1", "cm-1": "cm-2", "cm-2": "cm-3", "cm-3": "cm-4", "cm-4": "cm-5", "cm-5": "cm-6", "cm-6": "cm-7", "cm-7": "cm-8", "cm-8": "cm-9", "cm-9": "cm-10", "cm-10": "cm-11", "cm-11": "cm-12"},
+			expectedFetched: []string{"cm-1", "cm-2", "cm-3", "cm-4", "cm-5", "cm-6", "cm-7", "cm-8", "cm-9", "cm-10"},
+		},
+		{
+			name:            "cycle back to an item already backed up isn't fetched",
+			maxDepth:        10,
+			next:            map[string]string{"cm-0": "cm-1", "cm-1": "cm-2", "cm-2": "cm-0"},
+			expectedFetched: []string{"cm-1", "cm-2"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			itemHookHandler := &mockItemHookHandler{}
+			defer itemHookHandler.AssertExpectations(t)
+
+			dynamicFactory := &arktest.FakeDynamicFactory{}
+			defer dynamicFactory.AssertExpectations(t)
+
+			itemClient := &arktest.FakeDynamicClient{}
+			defer itemClient.AssertExpectations(t)
+
+			w := &fakeTarWriter{}
+			groupResource := schema.GroupResource{Resource: "configmaps"}
+			configMap := func(name string) runtime.Unstructured {
+				return unstructuredOrDie(fmt.Sprintf(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns","name":"%s"}}`, name))
+			}
+
+			ib := &defaultItemBackupper{
+				backup:        &v1.Backup{Spec: v1.BackupSpec{MaxAdditionalItemDepth: test.maxDepth}},
+				namespaces:    collections.NewIncludesExcludes(),
+				resources:     collections.NewIncludesExcludes(),
+				backedUpItems: make(map[itemKey]struct{}),
+				actions: []resolvedAction{
+					{
+						ItemAction:                &chainAction{next: test.next},
+						namespaceIncludesExcludes: collections.NewIncludesExcludes(),
+						resourceIncludesExcludes:  collections.NewIncludesExcludes(),
+						selector:                  labels.Everything(),
+					},
+				},
+				tarWriter:       w,
+				dynamicFactory:  dynamicFactory,
+				discoveryHelper: arktest.NewFakeDiscoveryHelper(true, nil),
+				itemHookHandler: itemHookHandler,
+			}
+			ib.additionalItemBackupper = ib
+
+			itemHookHandler.On("handleHooks", mock.Anything, groupResource, mock.Anything, []resourceHook(nil), mock.Anything).Return(nil)
+			if len(test.expectedFetched) > 0 {
+				dynamicFactory.On("ClientForGroupVersionResource", schema.GroupVersion{}, metav1.APIResource{Name: "configmaps"}, "ns").Return(itemClient, nil)
+			}
+			for _, name := range test.expectedFetched {
+				itemClient.On("Get", name, metav1.GetOptions{}).Return(configMap(name), nil).Once()
+			}
+
+			require.NoError(t, ib.backupItem(arktest.NewLogger(), configMap("cm-0"), groupResource))
+
+			assert.Len(t, w.headers, len(test.expectedFetched)+1)
+			assert.Equal(t, 0, ib.additionalItemDepth)
+		})
+	}
+}
+
+func TestFetchAdditionalItems(t *testing.T) {
+	configMaps := schema.GroupResource{Resource: "configmaps"}
+	configMap := func(name string) runtime.Unstructured {
+		return unstructuredOrDie(fmt.Sprintf(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns","name":"%s"}}`, name))
+	}
+
+	var identifiers []ResourceIdentifier
+	for i := 0; i < 25; i++ {
+		identifiers = append(identifiers, ResourceIdentifier{GroupResource: configMaps, Namespace: "ns", Name: fmt.Sprintf("cm-%d", i)})
+	}
+	// duplicates and items already in the backup aren't fetched
+	identifiers = append(identifiers, identifiers[0], ResourceIdentifier{GroupResource: configMaps, Namespace: "ns", Name: "backed-up"})
+
+	newItemBackupper := func(itemClient *arktest.FakeDynamicClient) (*defaultItemBackupper, *arktest.FakeDynamicFactory) {
+		dynamicFactory := &arktest.FakeDynamicFactory{}
+		dynamicFactory.On("ClientForGroupVersionResource", schema.GroupVersion{}, metav1.APIResource{Name: "configmaps"}, "ns").Return(itemClient, nil).Once()
+
+		return &defaultItemBackupper{
+			backup:          &v1.Backup{},
+			backedUpItems:   map[itemKey]struct{}{{resource: "configmaps", namespace: "ns", name: "backed-up"}: {}},
+			dynamicFactory:  dynamicFactory,
+			discoveryHelper: arktest.NewFakeDiscoveryHelper(true, nil),
+		}, dynamicFactory
+	}
+
+	t.Run("items are returned in order using one client per resource and namespace", func(t *testing.T) {
+		itemClient := &arktest.FakeDynamicClient{}
+		defer itemClient.AssertExpectations(t)
+
+		ib, dynamicFactory := newItemBackupper(itemClient)
+		defer dynamicFactory.AssertExpectations(t)
+
+		for i := 0; i < 25; i++ {
+			name := fmt.Sprintf("cm-%d", i)
+			itemClient.On("Get", name, metav1.GetOptions{}).Return(configMap(name), nil).Once()
+		}
+
+		items, err := ib.fetchAdditionalItems(arktest.NewLogger(), identifiers)
+		require.NoError(t, err)
+
+		require.Len(t, items, 25)
+		for i, item := range items {
+			assert.Equal(t, configMaps, item.groupResource)
+			assert.Equal(t, configMap(fmt.Sprintf("cm-%d", i)), item.obj)
+		}
+
+		// a later action's items reuse the client
+		itemClient.On("Get", "cm-25", metav1.GetOptions{}).Return(configMap("cm-25"), nil).Once()
+		_, err = ib.fetchAdditionalItems(arktest.NewLogger(), []ResourceIdentifier{{GroupResource: configMaps, Namespace: "ns", Name: "cm-25"}})
+		require.NoError(t, err)
+	})
+
+	t.Run("get error is returned", func(t *testing.T) {
+		itemClient := &arktest.FakeDynamicClient{}
+		ib, _ := newItemBackupper(itemClient)
+
+		itemClient.On("Get", "cm-3", metav1.GetOptions{}).Return((*unstructured.Unstructured)(nil), errors.New("get failed"))
+		itemClient.On("Get", mock.Anything, metav1.GetOptions{}).Return(configMap("cm"), nil)
+
+		_, err := ib.fetchAdditionalItems(arktest.NewLogger(), identifiers)
+		assert.EqualError(t, err, "get failed")
+	})
+}
+
 func TestTakePVSnapshot(t *testing.T) {
 	iops := int64(1000)
 
@@ -480,6 +818,9 @@ func TestTakePVSnapshot(t *testing.T) {
 		expectedSnapshotsTaken int
 		existingVolumeBackups  map[string]*v1.VolumeBackupInfo
 		volumeInfo             map[string]v1.VolumeBackupInfo
+		expectedClaimNamespace string
+		expectedClaimName      string
+		expectedSize           string
 	}{
 		{
 			name:            "snapshot disabled",
@@ -516,6 +857,19 @@ func TestTakePVSnapshot(t *testing.T) {
 				"vol-abc123": {Type: "io1", Iops: &iops, SnapshotID: "snap-1", AvailabilityZone: "us-east-1c"},
 			},
 		},
+		{
+			name:                   "bound PV records its claim and size",
+			snapshotEnabled:        true,
+			pv:                     `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "mypv"}, "spec": {"capacity": {"storage": "10Gi"}, "claimRef": {"namespace": "ns-1", "name": "data"}, "awsElasticBlockStore": {"volumeID": "aws://us-east-1c/vol-abc123"}}}`,
+			expectedSnapshotsTaken: 1,
+			expectedVolumeID:       "vol-abc123",
+			volumeInfo: map[string]v1.VolumeBackupInfo{
+				"vol-abc123": {Type: "gp", SnapshotID: "snap-1"},
+			},
+			expectedClaimNamespace: "ns-1",
+			expectedClaimName:      "data",
+			expectedSize:           "10Gi",
+		},
 		{
 			name:                   "preexisting volume backup info in backup status",
 			snapshotEnabled:        true,
@@ -615,11 +969,21 @@ func TestTakePVSnapshot(t *testing.T) {
 					Type:             test.volumeInfo[test.expectedVolumeID].Type,
 					Iops:             test.volumeInfo[test.expectedVolumeID].Iops,
 					AvailabilityZone: test.volumeInfo[test.expectedVolumeID].AvailabilityZone,
+					Phase:            v1.SnapshotPhaseInProgress,
 				}
 
 				if e, a := expectedVolumeBackups, backup.Status.VolumeBackups; !reflect.DeepEqual(e, a) {
 					t.Errorf("backup.status.VolumeBackups: expected %v, got %v", e, a)
 				}
+
+				assert.Equal(t, []v1.VolumeSnapshotStatus{{
+					PersistentVolume:      "mypv",
+					Namespace:             test.expectedClaimNamespace,
+					PersistentVolumeClaim: test.expectedClaimName,
+					SnapshotID:            snapshotID,
+					Size:                  test.expectedSize,
+					Phase:                 v1.SnapshotPhaseInProgress,
+				}}, backup.Status.VolumeSnapshots)
 			}
 		})
 	}
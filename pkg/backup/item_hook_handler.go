@@ -19,6 +19,7 @@ package backup
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
@@ -53,11 +54,20 @@ type itemHookHandler interface {
 		resourceHooks []resourceHook,
 		phase hookPhase,
 	) error
+
+	// unfreezeVolumes unfreezes any of the item's volumes that were frozen by fsfreeze hooks
+	// during the pre phase. It is a no-op if none are frozen.
+	unfreezeVolumes(log logrus.FieldLogger, obj runtime.Unstructured)
 }
 
 // defaultItemHookHandler is the default itemHookHandler.
 type defaultItemHookHandler struct {
 	podCommandExecutor podCommandExecutor
+	// backup, if set, has a summary of each hook execution recorded in its status.
+	backup *api.Backup
+	// frozenVolumes tracks the volumes frozen by fsfreeze hooks, keyed by pod, so that they can
+	// be unfrozen once the pod has been backed up.
+	frozenVolumes map[string][]frozenVolume
 }
 
 func (h *defaultItemHookHandler) handleHooks(
@@ -72,6 +82,11 @@ func (h *defaultItemHookHandler) handleHooks(
 		return nil
 	}
 
+	if phase == hookPhasePost {
+		// Volumes are unfrozen before any post hooks are executed.
+		h.unfreezeVolumes(log, obj)
+	}
+
 	metadata, err := meta.Accessor(obj)
 	if err != nil {
 		return errors.Wrap(err, "unable to get a metadata accessor")
@@ -94,7 +109,9 @@ func (h *defaultItemHookHandler) handleHooks(
 				"hookPhase":  phase,
 			},
 		)
-		if err := h.podCommandExecutor.executePodCommand(hookLog, obj.UnstructuredContent(), namespace, name, "<from-annotation>", hookFromAnnotations); err != nil {
+		executions, err := h.podCommandExecutor.executePodCommand(hookLog, obj.UnstructuredContent(), namespace, name, "<from-annotation>", hookFromAnnotations)
+		h.recordExecutions(phase, executions)
+		if err != nil {
 			hookLog.WithError(err).Error("Error executing hook")
 			if hookFromAnnotations.OnError == api.HookErrorModeFail {
 				return err
@@ -127,10 +144,29 @@ func (h *defaultItemHookHandler) handleHooks(
 							"hookPhase":  phase,
 						},
 					)
-					err := h.podCommandExecutor.executePodCommand(hookLog, obj.UnstructuredContent(), namespace, name, resourceHook.name, hook.Exec)
+					executions, err := h.podCommandExecutor.executePodCommand(hookLog, obj.UnstructuredContent(), namespace, name, resourceHook.name, hook.Exec)
+					h.recordExecutions(phase, executions)
 					if err != nil {
 						hookLog.WithError(err).Error("Error executing hook")
 						if hook.Exec.OnError == api.HookErrorModeFail {
+							// The pod won't be backed up, so don't leave any of its volumes frozen.
+							h.unfreezeVolumes(log, obj)
+							return err
+						}
+					}
+				}
+				if hook.FSFreeze != nil && phase == hookPhasePre {
+					hookLog := log.WithFields(
+						logrus.Fields{
+							"hookSource": "backupSpec",
+							"hookType":   "fsfreeze",
+							"hookPhase":  phase,
+						},
+					)
+					if err := h.freezeVolumes(hookLog, obj, resourceHook.name, hook.FSFreeze); err != nil {
+						hookLog.WithError(err).Error("Error executing hook")
+						if hook.FSFreeze.OnError != api.HookErrorModeContinue {
+							h.unfreezeVolumes(log, obj)
 							return err
 						}
 					}
@@ -142,13 +178,83 @@ func (h *defaultItemHookHandler) handleHooks(
 	return nil
 }
 
+// recordExecutions counts executions in the hook status of the handler's backup, and adds
+// the ones that didn't succeed to it, up to api.MaxRecordedHookExecutions.
+func (h *defaultItemHookHandler) recordExecutions(phase hookPhase, executions []api.HookExecution) {
+	if h.backup == nil || len(executions) == 0 {
+		return
+	}
+
+	if h.backup.Status.HookStatus == nil {
+		h.backup.Status.HookStatus = &api.HookStatus{}
+	}
+	status := h.backup.Status.HookStatus
+
+	for _, execution := range executions {
+		execution.Phase = string(phase)
+
+		status.HooksAttempted++
+		if execution.Outcome == api.HookOutcomeSucceeded {
+			continue
+		}
+
+		status.HooksFailed++
+		if len(status.Executions) < api.MaxRecordedHookExecutions {
+			status.Executions = append(status.Executions, execution)
+		}
+	}
+}
+
+// ValidateHooks returns an error for each hook in hooks that is invalid.
+func ValidateHooks(hooks api.BackupHooks) []error {
+	var errs []error
+
+	for _, spec := range hooks.Resources {
+		for i, list := range [][]api.BackupResourceHook{spec.Hooks, spec.PreHooks, spec.PostHooks} {
+			for _, hook := range list {
+				if hook.Exec != nil && hook.FSFreeze != nil {
+					errs = append(errs, errors.Errorf("hook %q: only one of exec and fsfreeze may be specified", spec.Name))
+					continue
+				}
+				if hook.Exec != nil {
+					if err := ValidateExecHook(hook.Exec); err != nil {
+						errs = append(errs, errors.Wrapf(err, "hook %q", spec.Name))
+					}
+				}
+				if hook.FSFreeze != nil {
+					// the last list is the post hooks
+					if i == 2 {
+						errs = append(errs, errors.Errorf("hook %q: fsfreeze hooks may only be specified as pre hooks", spec.Name))
+					} else if len(hook.FSFreeze.Volumes) == 0 {
+						errs = append(errs, errors.Errorf("hook %q: fsfreeze hooks must specify at least one volume", spec.Name))
+					}
+				}
+			}
+		}
+	}
+
+	for _, group := range hooks.ConsistencyGroups {
+		if group.Name == "" {
+			errs = append(errs, errors.New("consistency groups must have a name"))
+		}
+		if group.LabelSelector == nil {
+			errs = append(errs, errors.Errorf("consistency group %q: a label selector must be specified", group.Name))
+		} else if _, err := metav1.LabelSelectorAsSelector(group.LabelSelector); err != nil {
+			errs = append(errs, errors.Wrapf(err, "consistency group %q: invalid label selector", group.Name))
+		}
+	}
+
+	return errs
+}
+
 const (
-	podBackupHookContainerAnnotationKey = "hook.backup.ark.heptio.com/container"
-	podBackupHookCommandAnnotationKey   = "hook.backup.ark.heptio.com/command"
-	podBackupHookOnErrorAnnotationKey   = "hook.backup.ark.heptio.com/on-error"
-	podBackupHookTimeoutAnnotationKey   = "hook.backup.ark.heptio.com/timeout"
-	defaultHookOnError                  = api.HookErrorModeFail
-	defaultHookTimeout                  = 30 * time.Second
+	podBackupHookContainerAnnotationKey        = "hook.backup.ark.heptio.com/container"
+	podBackupHookContainerPatternAnnotationKey = "hook.backup.ark.heptio.com/container-pattern"
+	podBackupHookCommandAnnotationKey          = "hook.backup.ark.heptio.com/command"
+	podBackupHookOnErrorAnnotationKey          = "hook.backup.ark.heptio.com/on-error"
+	podBackupHookTimeoutAnnotationKey          = "hook.backup.ark.heptio.com/timeout"
+	defaultHookOnError                         = api.HookErrorModeFail
+	defaultHookTimeout                         = 30 * time.Second
 )
 
 func phasedKey(phase hookPhase, key string) string {
@@ -179,7 +285,18 @@ func getPodExecHookFromAnnotations(annotations map[string]string, phase hookPhas
 		command = append(command, commandValue)
 	}
 
-	container := getHookAnnotation(annotations, podBackupHookContainerAnnotationKey, phase)
+	// the container annotation may be a comma-separated list of containers
+	var (
+		container  string
+		containers []string
+	)
+	if containerValue := getHookAnnotation(annotations, podBackupHookContainerAnnotationKey, phase); strings.Contains(containerValue, ",") {
+		for _, name := range strings.Split(containerValue, ",") {
+			containers = append(containers, strings.TrimSpace(name))
+		}
+	} else {
+		container = containerValue
+	}
 
 	onError := api.HookErrorMode(getHookAnnotation(annotations, podBackupHookOnErrorAnnotationKey, phase))
 	if onError != api.HookErrorModeContinue && onError != api.HookErrorModeFail {
@@ -197,10 +314,12 @@ func getPodExecHookFromAnnotations(annotations map[string]string, phase hookPhas
 	}
 
 	return &api.ExecHook{
-		Container: container,
-		Command:   command,
-		OnError:   onError,
-		Timeout:   metav1.Duration{Duration: timeout},
+		Container:        container,
+		Containers:       containers,
+		ContainerPattern: getHookAnnotation(annotations, podBackupHookContainerPatternAnnotationKey, phase),
+		Command:          command,
+		OnError:          onError,
+		Timeout:          metav1.Duration{Duration: timeout},
 	}
 }
 
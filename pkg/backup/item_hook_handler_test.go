@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/kuberesource"
 	"github.com/heptio/ark/pkg/util/collections"
 	arktest "github.com/heptio/ark/pkg/util/test"
 	"github.com/pkg/errors"
@@ -44,6 +45,10 @@ func (h *mockItemHookHandler) handleHooks(log logrus.FieldLogger, groupResource
 	return args.Error(0)
 }
 
+func (h *mockItemHookHandler) unfreezeVolumes(log logrus.FieldLogger, obj runtime.Unstructured) {
+	h.Called(log, obj)
+}
+
 func TestHandleHooksSkips(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -467,20 +472,20 @@ func TestHandleHooks(t *testing.T) {
 			}
 
 			if test.expectedPodHook != nil {
-				podCommandExecutor.On("executePodCommand", mock.Anything, test.item.UnstructuredContent(), "ns", "name", "<from-annotation>", test.expectedPodHook).Return(test.expectedPodHookError)
+				podCommandExecutor.On("executePodCommand", mock.Anything, test.item.UnstructuredContent(), "ns", "name", "<from-annotation>", test.expectedPodHook).Return(nil, test.expectedPodHookError)
 			} else {
 			hookLoop:
 				for _, resourceHook := range test.hooks {
 					for _, hook := range resourceHook.pre {
 						hookError := test.hookErrorsByContainer[hook.Exec.Container]
-						podCommandExecutor.On("executePodCommand", mock.Anything, test.item.UnstructuredContent(), "ns", "name", resourceHook.name, hook.Exec).Return(hookError)
+						podCommandExecutor.On("executePodCommand", mock.Anything, test.item.UnstructuredContent(), "ns", "name", resourceHook.name, hook.Exec).Return(nil, hookError)
 						if hookError != nil && hook.Exec.OnError == v1.HookErrorModeFail {
 							break hookLoop
 						}
 					}
 					for _, hook := range resourceHook.post {
 						hookError := test.hookErrorsByContainer[hook.Exec.Container]
-						podCommandExecutor.On("executePodCommand", mock.Anything, test.item.UnstructuredContent(), "ns", "name", resourceHook.name, hook.Exec).Return(hookError)
+						podCommandExecutor.On("executePodCommand", mock.Anything, test.item.UnstructuredContent(), "ns", "name", resourceHook.name, hook.Exec).Return(nil, hookError)
 						if hookError != nil && hook.Exec.OnError == v1.HookErrorModeFail {
 							break hookLoop
 						}
@@ -501,6 +506,74 @@ func TestHandleHooks(t *testing.T) {
 	}
 }
 
+func TestHandleHooksRecordsHookStatus(t *testing.T) {
+	podCommandExecutor := &mockPodCommandExecutor{}
+	defer podCommandExecutor.AssertExpectations(t)
+
+	backup := &v1.Backup{}
+	h := &defaultItemHookHandler{
+		podCommandExecutor: podCommandExecutor,
+		backup:             backup,
+	}
+
+	item := unstructuredOrDie(`
+	{
+		"apiVersion": "v1",
+		"kind": "Pod",
+		"metadata": {
+			"namespace": "ns",
+			"name": "name"
+		}
+	}`)
+
+	hook := &v1.ExecHook{Containers: []string{"a", "b"}, OnError: v1.HookErrorModeContinue}
+	hooks := []resourceHook{
+		{
+			name: "freeze",
+			pre:  []v1.BackupResourceHook{{Exec: hook}},
+		},
+	}
+
+	podCommandExecutor.On("executePodCommand", mock.Anything, item.UnstructuredContent(), "ns", "name", "freeze", hook).Return(
+		[]v1.HookExecution{
+			{Name: "freeze", Namespace: "ns", Pod: "name", Container: "a", Outcome: v1.HookOutcomeSucceeded, OnError: v1.HookErrorModeContinue},
+			{Name: "freeze", Namespace: "ns", Pod: "name", Container: "b", Outcome: v1.HookOutcomeTimedOut, OnError: v1.HookErrorModeContinue, Error: "timed out after 30s"},
+		},
+		errors.New("timed out after 30s"),
+	)
+
+	err := h.handleHooks(arktest.NewLogger(), kuberesource.Pods, item, hooks, hookPhasePre)
+	require.NoError(t, err)
+
+	expected := &v1.HookStatus{
+		HooksAttempted: 2,
+		HooksFailed:    1,
+		Executions: []v1.HookExecution{
+			{Name: "freeze", Phase: "pre", Namespace: "ns", Pod: "name", Container: "b", Outcome: v1.HookOutcomeTimedOut, OnError: v1.HookErrorModeContinue, Error: "timed out after 30s"},
+		},
+	}
+	assert.Equal(t, expected, backup.Status.HookStatus)
+}
+
+func TestRecordExecutionsLimit(t *testing.T) {
+	backup := &v1.Backup{}
+	h := &defaultItemHookHandler{backup: backup}
+
+	for i := 0; i < v1.MaxRecordedHookExecutions+10; i++ {
+		h.recordExecutions(hookPhasePre, []v1.HookExecution{
+			{Name: "hook", Outcome: v1.HookOutcomeSucceeded},
+			{Name: "hook", Outcome: v1.HookOutcomeFailed},
+		})
+	}
+
+	assert.Equal(t, 2*(v1.MaxRecordedHookExecutions+10), backup.Status.HookStatus.HooksAttempted)
+	assert.Equal(t, v1.MaxRecordedHookExecutions+10, backup.Status.HookStatus.HooksFailed)
+	require.Len(t, backup.Status.HookStatus.Executions, v1.MaxRecordedHookExecutions)
+	for _, execution := range backup.Status.HookStatus.Executions {
+		assert.Equal(t, v1.HookOutcomeFailed, execution.Outcome)
+	}
+}
+
 func TestGetPodExecHookFromAnnotations(t *testing.T) {
 	phases := []hookPhase{"", hookPhasePre, hookPhasePost}
 	for _, phase := range phases {
@@ -594,6 +667,28 @@ func TestGetPodExecHookFromAnnotations(t *testing.T) {
 					Command:   []string{"/usr/bin/foo"},
 				},
 			},
+			{
+				name: "comma-separated containers",
+				annotations: map[string]string{
+					phasedKey(phase, podBackupHookContainerAnnotationKey): "a, b",
+					phasedKey(phase, podBackupHookCommandAnnotationKey):   "/usr/bin/foo",
+				},
+				expectedHook: &v1.ExecHook{
+					Containers: []string{"a", "b"},
+					Command:    []string{"/usr/bin/foo"},
+				},
+			},
+			{
+				name: "use the specified container pattern",
+				annotations: map[string]string{
+					phasedKey(phase, podBackupHookContainerPatternAnnotationKey): "db-*",
+					phasedKey(phase, podBackupHookCommandAnnotationKey):          "/usr/bin/foo",
+				},
+				expectedHook: &v1.ExecHook{
+					ContainerPattern: "db-*",
+					Command:          []string{"/usr/bin/foo"},
+				},
+			},
 		}
 
 		for _, test := range tests {
@@ -605,6 +700,45 @@ func TestGetPodExecHookFromAnnotations(t *testing.T) {
 	}
 }
 
+func TestValidateHooks(t *testing.T) {
+	hooks := v1.BackupHooks{
+		Resources: []v1.BackupResourceHookSpec{
+			{
+				Name: "valid",
+				PreHooks: []v1.BackupResourceHook{
+					{Exec: &v1.ExecHook{Containers: []string{"a", "b"}}},
+				},
+			},
+			{
+				Name: "invalid",
+				PostHooks: []v1.BackupResourceHook{
+					{},
+					{Exec: &v1.ExecHook{Container: "a", ContainerPattern: "b*"}},
+				},
+			},
+		},
+	}
+
+	errs := ValidateHooks(hooks)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], `hook "invalid": only one of container, containers and containerPattern may be specified`)
+}
+
+func TestValidateHooksConsistencyGroups(t *testing.T) {
+	hooks := v1.BackupHooks{
+		ConsistencyGroups: []v1.ConsistencyGroupSpec{
+			{Name: "valid", LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}},
+			{LabelSelector: &metav1.LabelSelector{}},
+			{Name: "no-selector"},
+		},
+	}
+
+	errs := ValidateHooks(hooks)
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "consistency groups must have a name")
+	assert.EqualError(t, errs[1], `consistency group "no-selector": a label selector must be specified`)
+}
+
 func TestResourceHookApplicableTo(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -0,0 +1,141 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package largeitem merges the items that a backup offloaded because of their size
+// back into its contents, for backups whose LargeItemAction is Offload.
+package largeitem
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// Index lists the items that a backup offloaded, by the paths they would have had
+// in its contents otherwise.
+type Index struct {
+	Items []string `json:"items"`
+}
+
+// HasOffloaded returns true if any of backup's items were offloaded because of their
+// size. Backups taken before OffloadedItemCount was added only list their offloaded
+// items.
+func HasOffloaded(backup *api.Backup) bool {
+	if backup.Status.OffloadedItemCount > 0 {
+		return true
+	}
+	for _, item := range backup.Status.LargeItems {
+		if item.Offloaded {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidPath returns true if item is a path that an offloaded item can have: a relative,
+// clean path in the resources directory of the backup or of one of its additional
+// clusters.
+func ValidPath(item string) bool {
+	if item == "" || path.IsAbs(item) || path.Clean(item) != item || strings.HasPrefix(item, "../") {
+		return false
+	}
+
+	parts := strings.Split(item, "/")
+	if len(parts) > 2 && parts[0] == api.ClustersDir {
+		parts = parts[2:]
+	}
+	return len(parts) > 1 && parts[0] == api.ResourcesDir
+}
+
+// Merge writes a gzipped tarball to w containing the files in contents, a backup's gzipped
+// tarball, followed by each of items, at the paths they would have had if they hadn't been
+// offloaded. open is called to read each item in turn.
+func Merge(w io.Writer, contents io.ReadCloser, items []string, open func(item string) (io.ReadCloser, error)) error {
+	defer contents.Close()
+
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+
+	gzr, err := gzip.NewReader(contents)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return errors.WithStack(err)
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	for _, item := range items {
+		if err := copyItem(tw, item, open); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(gzw.Close())
+}
+
+func copyItem(tw *tar.Writer, item string, open func(string) (io.ReadCloser, error)) error {
+	rc, err := open(item)
+	if err != nil {
+		return errors.WithMessage(err, "error downloading large item "+item)
+	}
+	defer rc.Close()
+
+	// the size has to be known before the item is written
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return errors.Wrapf(err, "error downloading large item %s", item)
+	}
+
+	header := &tar.Header{
+		Name:     item,
+		Size:     int64(len(data)),
+		Typeflag: tar.TypeReg,
+		Mode:     0755,
+		ModTime:  time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = tw.Write(data)
+	return errors.WithStack(err)
+}
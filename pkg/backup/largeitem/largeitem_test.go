@@ -0,0 +1,112 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package largeitem
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+func TestHasOffloaded(t *testing.T) {
+	assert.False(t, HasOffloaded(&api.Backup{}))
+	assert.False(t, HasOffloaded(&api.Backup{Status: api.BackupStatus{LargeItems: []api.LargeItem{{Skipped: true}}}}))
+	assert.True(t, HasOffloaded(&api.Backup{Status: api.BackupStatus{LargeItems: []api.LargeItem{{Offloaded: true}}}}))
+	assert.True(t, HasOffloaded(&api.Backup{Status: api.BackupStatus{OffloadedItemCount: 1}}))
+}
+
+func TestValidPath(t *testing.T) {
+	valid := []string{
+		"resources/configmaps/namespaces/ns-1/cm-1.json",
+		"clusters/east/resources/configmaps/namespaces/ns-1/cm-1.json",
+	}
+	for _, item := range valid {
+		assert.True(t, ValidPath(item), item)
+	}
+
+	invalid := []string{
+		"",
+		"resources",
+		"/resources/configmaps/namespaces/ns-1/cm-1.json",
+		"../other-backup/ark-backup.json",
+		"resources/../../other-backup/ark-backup.json",
+		"clusters/east/../../../other-backup/ark-backup.json",
+		"other/configmaps/namespaces/ns-1/cm-1.json",
+		"clusters/east/other/configmaps/namespaces/ns-1/cm-1.json",
+	}
+	for _, item := range invalid {
+		assert.False(t, ValidPath(item), item)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	var contents bytes.Buffer
+	gzw := gzip.NewWriter(&contents)
+	tw := tar.NewWriter(gzw)
+	data := []byte("{}")
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "resources/pods/namespaces/ns-1/pod-1.json", Size: int64(len(data)), Mode: 0755}))
+	_, err := tw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+
+	items := []string{
+		"resources/configmaps/namespaces/ns-1/cm-1.json",
+		"clusters/east/resources/configmaps/namespaces/ns-1/cm-2.json",
+	}
+
+	var merged bytes.Buffer
+	err = Merge(&merged, ioutil.NopCloser(&contents), items, func(item string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(item)), nil
+	})
+	require.NoError(t, err)
+
+	gzr, err := gzip.NewReader(&merged)
+	require.NoError(t, err)
+	tr := tar.NewReader(gzr)
+
+	files := make(map[string]string)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		data, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+
+		names = append(names, header.Name)
+		files[header.Name] = string(data)
+	}
+
+	assert.Equal(t, append([]string{"resources/pods/namespaces/ns-1/pod-1.json"}, items...), names)
+	assert.Equal(t, "{}", files["resources/pods/namespaces/ns-1/pod-1.json"])
+	for _, item := range items {
+		assert.Equal(t, item, files[item])
+	}
+}
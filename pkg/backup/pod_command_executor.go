@@ -18,7 +18,10 @@ package backup
 
 import (
 	"bytes"
+	"fmt"
 	"net/url"
+	"path"
+	"strings"
 	"time"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
@@ -26,6 +29,9 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	kapiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	kuberrs "k8s.io/apimachinery/pkg/util/errors"
 	kscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
@@ -33,9 +39,10 @@ import (
 
 // podCommandExecutor is capable of executing a command in a container in a pod.
 type podCommandExecutor interface {
-	// executePodCommand executes a command in a container in a pod. If the command takes longer than
-	// the specified timeout, an error is returned.
-	executePodCommand(log logrus.FieldLogger, item map[string]interface{}, namespace, name, hookName string, hook *api.ExecHook) error
+	// executePodCommand executes a command in one or more containers in a pod. If the command takes
+	// longer than the specified timeout, an error is returned. A summary of the execution in each
+	// container is returned, even if an error occurs.
+	executePodCommand(log logrus.FieldLogger, item map[string]interface{}, namespace, name, hookName string, hook *api.ExecHook) ([]api.HookExecution, error)
 }
 
 type poster interface {
@@ -47,6 +54,7 @@ type defaultPodCommandExecutor struct {
 	restClient       poster
 
 	streamExecutorFactory streamExecutorFactory
+	clock                 clock.Clock
 }
 
 // NewPodCommandExecutor creates a new podCommandExecutor.
@@ -56,6 +64,7 @@ func NewPodCommandExecutor(restClientConfig *rest.Config, restClient poster) pod
 		restClient:       restClient,
 
 		streamExecutorFactory: &defaultStreamExecutorFactory{},
+		clock:                 clock.RealClock{},
 	}
 }
 
@@ -63,33 +72,43 @@ func NewPodCommandExecutor(restClientConfig *rest.Config, restClient poster) pod
 // command takes longer than the specified timeout, an error is returned (NOTE: it is not currently
 // possible to ensure the command is terminated when the timeout occurs, so it may continue to run
 // in the background).
-func (e *defaultPodCommandExecutor) executePodCommand(log logrus.FieldLogger, item map[string]interface{}, namespace, name, hookName string, hook *api.ExecHook) error {
+func (e *defaultPodCommandExecutor) executePodCommand(log logrus.FieldLogger, item map[string]interface{}, namespace, name, hookName string, hook *api.ExecHook) ([]api.HookExecution, error) {
 	if item == nil {
-		return errors.New("item is required")
+		return nil, errors.New("item is required")
 	}
 	if namespace == "" {
-		return errors.New("namespace is required")
+		return nil, errors.New("namespace is required")
 	}
 	if name == "" {
-		return errors.New("name is required")
+		return nil, errors.New("name is required")
 	}
 	if hookName == "" {
-		return errors.New("hookName is required")
+		return nil, errors.New("hookName is required")
 	}
 	if hook == nil {
-		return errors.New("hook is required")
+		return nil, errors.New("hook is required")
 	}
 
-	if hook.Container == "" {
-		if err := setDefaultHookContainer(item, hook); err != nil {
-			return err
+	newExecution := func(container string) api.HookExecution {
+		return api.HookExecution{
+			Name:      hookName,
+			Namespace: namespace,
+			Pod:       name,
+			Container: container,
+			OnError:   hook.OnError,
 		}
-	} else if err := ensureContainerExists(item, hook.Container); err != nil {
-		return err
+	}
+
+	containers, err := getHookContainers(item, hook)
+	if err != nil {
+		execution := newExecution(hook.Container)
+		execution.Outcome = api.HookOutcomeFailed
+		execution.Error = err.Error()
+		return []api.HookExecution{execution}, err
 	}
 
 	if len(hook.Command) == 0 {
-		return errors.New("command is required")
+		return nil, errors.New("command is required")
 	}
 
 	switch hook.OnError {
@@ -106,13 +125,83 @@ func (e *defaultPodCommandExecutor) executePodCommand(log logrus.FieldLogger, it
 
 	hookLog := log.WithFields(
 		logrus.Fields{
-			"hookName":      hookName,
-			"hookContainer": hook.Container,
-			"hookCommand":   hook.Command,
-			"hookOnError":   hook.OnError,
-			"hookTimeout":   hook.Timeout,
+			"hookName":    hookName,
+			"hookCommand": hook.Command,
+			"hookOnError": hook.OnError,
+			"hookTimeout": hook.Timeout,
 		},
 	)
+
+	var (
+		executions []api.HookExecution
+		errs       []error
+	)
+	for _, container := range containers {
+		containerLog := hookLog.WithField("hookContainer", container)
+
+		execution := newExecution(container)
+		start := e.clock.Now()
+		err := e.executeInContainer(containerLog, namespace, name, container, hook)
+		execution.Duration = metav1.Duration{Duration: e.clock.Since(start)}
+
+		switch err.(type) {
+		case nil:
+			execution.Outcome = api.HookOutcomeSucceeded
+		case hookTimeoutError:
+			execution.Outcome = api.HookOutcomeTimedOut
+		default:
+			execution.Outcome = api.HookOutcomeFailed
+		}
+
+		if err != nil {
+			containerLog.WithError(err).Error("exec hook failed")
+			execution.Error = err.Error()
+			errs = append(errs, err)
+		}
+		executions = append(executions, execution)
+	}
+
+	if len(containers) > 1 {
+		hookLog.Infof("exec hook succeeded in %d of %d containers", len(containers)-len(errs), len(containers))
+	}
+
+	return executions, kuberrs.NewAggregate(errs)
+}
+
+// buildHookCommand returns the command to execute for hook. The pod exec API only accepts a
+// command, so environment variables are set using env, and the working directory is changed
+// using a shell.
+func buildHookCommand(hook *api.ExecHook) []string {
+	command := hook.Command
+
+	if len(hook.Env) > 0 {
+		envCommand := []string{"env"}
+		for _, env := range hook.Env {
+			envCommand = append(envCommand, env.Name+"="+env.Value)
+		}
+		command = append(envCommand, command...)
+	}
+
+	if hook.WorkingDir != "" {
+		// The working directory is passed as $0 and the command as $@, so neither needs to be
+		// quoted.
+		command = append([]string{"/bin/sh", "-c", `cd "$0" && exec "$@"`, hook.WorkingDir}, command...)
+	}
+
+	return command
+}
+
+// hookTimeoutError is returned when a hook's command does not complete within its timeout.
+type hookTimeoutError struct {
+	timeout time.Duration
+}
+
+func (e hookTimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %v", e.timeout)
+}
+
+// executeInContainer runs hook's command in a single container of the pod.
+func (e *defaultPodCommandExecutor) executeInContainer(hookLog logrus.FieldLogger, namespace, name, container string, hook *api.ExecHook) error {
 	hookLog.Info("running exec hook")
 
 	req := e.restClient.Post().
@@ -122,8 +211,9 @@ func (e *defaultPodCommandExecutor) executePodCommand(log logrus.FieldLogger, it
 		SubResource("exec")
 
 	req.VersionedParams(&kapiv1.PodExecOptions{
-		Container: hook.Container,
-		Command:   hook.Command,
+		Container: container,
+		Command:   buildHookCommand(hook),
+		Stdin:     hook.Stdin != "",
 		Stdout:    true,
 		Stderr:    true,
 	}, kscheme.ParameterCodec)
@@ -139,6 +229,9 @@ func (e *defaultPodCommandExecutor) executePodCommand(log logrus.FieldLogger, it
 		Stdout: &stdout,
 		Stderr: &stderr,
 	}
+	if hook.Stdin != "" {
+		streamOptions.Stdin = strings.NewReader(hook.Stdin)
+	}
 
 	errCh := make(chan error)
 
@@ -157,29 +250,190 @@ func (e *defaultPodCommandExecutor) executePodCommand(log logrus.FieldLogger, it
 	select {
 	case err = <-errCh:
 	case <-timeoutCh:
-		return errors.Errorf("timed out after %v", hook.Timeout.Duration)
+		return hookTimeoutError{timeout: hook.Timeout.Duration}
 	}
 
 	hookLog.Infof("stdout: %s", stdout.String())
 	hookLog.Infof("stderr: %s", stderr.String())
 
+	if err != nil && stderr.Len() > 0 {
+		return errors.Wrapf(err, "stderr: %s", strings.TrimSpace(stderr.String()))
+	}
 	return err
 }
 
-func ensureContainerExists(pod map[string]interface{}, container string) error {
+// getHookContainers returns the names of the containers in the pod that hook should be
+// executed in. If hook does not select any containers, the pod's first container is used.
+// It is an error for a named container not to exist, or for a pattern to match no containers.
+func getHookContainers(pod map[string]interface{}, hook *api.ExecHook) ([]string, error) {
+	if err := ValidateExecHook(hook); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case hook.Container != "":
+		if err := ensureContainerExists(pod, hook.Container); err != nil {
+			return nil, err
+		}
+		return []string{hook.Container}, nil
+	case len(hook.Containers) > 0:
+		for _, container := range hook.Containers {
+			if err := ensureContainerExists(pod, container); err != nil {
+				return nil, err
+			}
+		}
+		return hook.Containers, nil
+	case hook.ContainerPattern != "":
+		names, err := getRunningContainerNames(pod)
+		if err != nil {
+			return nil, err
+		}
+		var matches []string
+		for _, name := range names {
+			// the pattern has already been validated, so the error can be ignored
+			if ok, _ := path.Match(hook.ContainerPattern, name); ok {
+				matches = append(matches, name)
+			}
+		}
+		if len(matches) == 0 {
+			return nil, errors.Errorf("no running containers match pattern %q", hook.ContainerPattern)
+		}
+		return matches, nil
+	default:
+		if err := setDefaultHookContainer(pod, hook); err != nil {
+			return nil, err
+		}
+		return []string{hook.Container}, nil
+	}
+}
+
+// ValidateExecHook returns an error if hook's container selection, environment variables, or
+// working directory are invalid.
+func ValidateExecHook(hook *api.ExecHook) error {
+	selectors := 0
+	if hook.Container != "" {
+		selectors++
+	}
+	if len(hook.Containers) > 0 {
+		selectors++
+	}
+	if hook.ContainerPattern != "" {
+		selectors++
+	}
+	if selectors > 1 {
+		return errors.New("only one of container, containers and containerPattern may be specified")
+	}
+
+	seen := make(map[string]bool)
+	for _, container := range hook.Containers {
+		if container == "" {
+			return errors.New("containers may not contain an empty name")
+		}
+		if seen[container] {
+			return errors.Errorf("container %q is listed more than once", container)
+		}
+		seen[container] = true
+	}
+
+	if hook.ContainerPattern != "" {
+		if _, err := path.Match(hook.ContainerPattern, ""); err != nil {
+			return errors.Errorf("invalid containerPattern %q", hook.ContainerPattern)
+		}
+	}
+
+	for _, env := range hook.Env {
+		if env.Name == "" || strings.Contains(env.Name, "=") {
+			return errors.Errorf("invalid environment variable name %q", env.Name)
+		}
+	}
+
+	if hook.WorkingDir != "" && !path.IsAbs(hook.WorkingDir) {
+		return errors.Errorf("workingDir %q must be an absolute path", hook.WorkingDir)
+	}
+
+	return nil
+}
+
+// getContainerNames returns the names of the pod's init containers followed by its
+// containers.
+func getContainerNames(pod map[string]interface{}) ([]string, error) {
+	var names []string
+
+	for _, field := range []string{"spec.initContainers", "spec.containers"} {
+		if _, err := collections.GetValue(pod, field); err != nil {
+			// initContainers is optional
+			continue
+		}
+		containers, err := collections.GetSlice(pod, field)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range containers {
+			c, ok := obj.(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("unexpected type for container %T", obj)
+			}
+			name, ok := c["name"].(string)
+			if !ok {
+				return nil, errors.Errorf("unexpected type for container name %T", c["name"])
+			}
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// getRunningContainerNames returns the names of the pod's (non-init) containers that
+// status.containerStatuses reports as running.
+func getRunningContainerNames(pod map[string]interface{}) ([]string, error) {
 	containers, err := collections.GetSlice(pod, "spec.containers")
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	running := make(map[string]bool)
+	if _, err := collections.GetValue(pod, "status.containerStatuses"); err == nil {
+		statuses, err := collections.GetSlice(pod, "status.containerStatuses")
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range statuses {
+			status, ok := obj.(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("unexpected type for container status %T", obj)
+			}
+			name, _ := status["name"].(string)
+			if _, err := collections.GetMap(status, "state.running"); err == nil {
+				running[name] = true
+			}
+		}
 	}
+
+	var names []string
 	for _, obj := range containers {
 		c, ok := obj.(map[string]interface{})
 		if !ok {
-			return errors.Errorf("unexpected type for container %T", obj)
+			return nil, errors.Errorf("unexpected type for container %T", obj)
 		}
 		name, ok := c["name"].(string)
 		if !ok {
-			return errors.Errorf("unexpected type for container name %T", c["name"])
+			return nil, errors.Errorf("unexpected type for container name %T", c["name"])
+		}
+		if running[name] {
+			names = append(names, name)
 		}
+	}
+
+	return names, nil
+}
+
+func ensureContainerExists(pod map[string]interface{}, container string) error {
+	names, err := getContainerNames(pod)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
 		if name == container {
 			return nil
 		}
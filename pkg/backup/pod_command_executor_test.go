@@ -105,7 +105,7 @@ func TestExecutePodCommandMissingInputs(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			e := &defaultPodCommandExecutor{}
-			err := e.executePodCommand(arktest.NewLogger(), test.item, test.podNamespace, test.podName, test.hookName, test.hook)
+			_, err := e.executePodCommand(arktest.NewLogger(), test.item, test.podNamespace, test.podName, test.hookName, test.hook)
 			assert.Error(t, err)
 		})
 	}
@@ -123,6 +123,7 @@ func TestExecutePodCommand(t *testing.T) {
 		expectedTimeout       time.Duration
 		hookError             error
 		expectedError         string
+		stdin                 string
 	}{
 		{
 			name:                  "validate defaults",
@@ -150,6 +151,14 @@ func TestExecutePodCommand(t *testing.T) {
 			hookError:             errors.New("hook error"),
 			expectedError:         "hook error",
 		},
+		{
+			name:                  "stdin",
+			command:               []string{"some", "command"},
+			expectedContainerName: "foo",
+			expectedErrorMode:     v1.HookErrorModeFail,
+			expectedTimeout:       30 * time.Second,
+			stdin:                 "some input",
+		},
 	}
 
 	for _, test := range tests {
@@ -159,6 +168,7 @@ func TestExecutePodCommand(t *testing.T) {
 				Command:   test.command,
 				OnError:   test.errorMode,
 				Timeout:   metav1.Duration{Duration: test.timeout},
+				Stdin:     test.stdin,
 			}
 
 			pod, err := getAsMap(`
@@ -197,8 +207,12 @@ func TestExecutePodCommand(t *testing.T) {
 			defer streamExecutor.AssertExpectations(t)
 
 			expectedCommand := strings.Join(test.command, "&command=")
+			expectedStdin := ""
+			if test.stdin != "" {
+				expectedStdin = "&stdin=true"
+			}
 			expectedURL, _ := url.Parse(
-				fmt.Sprintf("https://some.server/api/v1/namespaces/namespace/pods/name/exec?command=%s&container=%s&stderr=true&stdout=true", expectedCommand, test.expectedContainerName),
+				fmt.Sprintf("https://some.server/api/v1/namespaces/namespace/pods/name/exec?command=%s&container=%s&stderr=true%s&stdout=true", expectedCommand, test.expectedContainerName, expectedStdin),
 			)
 			streamExecutorFactory.On("NewSPDYExecutor", clientConfig, "POST", expectedURL).Return(streamExecutor, nil)
 
@@ -207,14 +221,24 @@ func TestExecutePodCommand(t *testing.T) {
 				Stdout: &stdout,
 				Stderr: &stderr,
 			}
+			if test.stdin != "" {
+				expectedStreamOptions.Stdin = strings.NewReader(test.stdin)
+			}
 			streamExecutor.On("Stream", expectedStreamOptions).Return(test.hookError)
 
-			err = podCommandExecutor.executePodCommand(arktest.NewLogger(), pod, "namespace", "name", "hookName", &hook)
+			executions, err := podCommandExecutor.executePodCommand(arktest.NewLogger(), pod, "namespace", "name", "hookName", &hook)
+			require.Len(t, executions, 1)
+			assert.Equal(t, test.expectedContainerName, executions[0].Container)
+			assert.Equal(t, test.expectedErrorMode, executions[0].OnError)
 			if test.expectedError != "" {
 				assert.EqualError(t, err, test.expectedError)
+				assert.Equal(t, v1.HookOutcomeFailed, executions[0].Outcome)
+				assert.Equal(t, test.expectedError, executions[0].Error)
 				return
 			}
 
+			assert.Equal(t, v1.HookOutcomeSucceeded, executions[0].Outcome)
+
 			require.NoError(t, err)
 		})
 	}
@@ -238,6 +262,206 @@ func TestEnsureContainerExists(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetHookContainers(t *testing.T) {
+	pod := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"initContainers": []interface{}{
+				map[string]interface{}{"name": "init"},
+			},
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app"},
+				map[string]interface{}{"name": "db-primary"},
+				map[string]interface{}{"name": "db-replica"},
+				map[string]interface{}{"name": "db-backup"},
+			},
+		},
+		"status": map[string]interface{}{
+			"initContainerStatuses": []interface{}{
+				map[string]interface{}{"name": "init", "state": map[string]interface{}{"terminated": map[string]interface{}{}}},
+			},
+			"containerStatuses": []interface{}{
+				map[string]interface{}{"name": "app", "state": map[string]interface{}{"running": map[string]interface{}{}}},
+				map[string]interface{}{"name": "db-primary", "state": map[string]interface{}{"running": map[string]interface{}{}}},
+				map[string]interface{}{"name": "db-replica", "state": map[string]interface{}{"running": map[string]interface{}{}}},
+				map[string]interface{}{"name": "db-backup", "state": map[string]interface{}{"terminated": map[string]interface{}{}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		hook          v1.ExecHook
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:     "defaults to first container",
+			expected: []string{"app"},
+		},
+		{
+			name:     "single container",
+			hook:     v1.ExecHook{Container: "db-replica"},
+			expected: []string{"db-replica"},
+		},
+		{
+			name:     "init container",
+			hook:     v1.ExecHook{Container: "init"},
+			expected: []string{"init"},
+		},
+		{
+			name:          "missing container",
+			hook:          v1.ExecHook{Container: "nope"},
+			expectedError: `no such container: "nope"`,
+		},
+		{
+			name:     "multiple containers",
+			hook:     v1.ExecHook{Containers: []string{"init", "app"}},
+			expected: []string{"init", "app"},
+		},
+		{
+			name:          "multiple containers with one missing",
+			hook:          v1.ExecHook{Containers: []string{"app", "nope"}},
+			expectedError: `no such container: "nope"`,
+		},
+		{
+			name:     "pattern",
+			hook:     v1.ExecHook{ContainerPattern: "db-*"},
+			expected: []string{"db-primary", "db-replica"},
+		},
+		{
+			name:          "pattern with no matches",
+			hook:          v1.ExecHook{ContainerPattern: "cache-*"},
+			expectedError: `no running containers match pattern "cache-*"`,
+		},
+		{
+			name:          "pattern does not match init containers",
+			hook:          v1.ExecHook{ContainerPattern: "init*"},
+			expectedError: `no running containers match pattern "init*"`,
+		},
+		{
+			name:          "invalid hook",
+			hook:          v1.ExecHook{Container: "app", Containers: []string{"db-primary"}},
+			expectedError: "only one of container, containers and containerPattern may be specified",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			containers, err := getHookContainers(pod, &test.hook)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, containers)
+		})
+	}
+}
+
+func TestBuildHookCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		hook     v1.ExecHook
+		expected []string
+	}{
+		{
+			name:     "command only",
+			hook:     v1.ExecHook{Command: []string{"/bin/true"}},
+			expected: []string{"/bin/true"},
+		},
+		{
+			name: "env",
+			hook: v1.ExecHook{
+				Command: []string{"/bin/true", "-a"},
+				Env:     []v1.HookEnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "two words"}},
+			},
+			expected: []string{"env", "A=1", "B=two words", "/bin/true", "-a"},
+		},
+		{
+			name: "working dir",
+			hook: v1.ExecHook{
+				Command:    []string{"/bin/true"},
+				WorkingDir: "/var/lib/data",
+			},
+			expected: []string{"/bin/sh", "-c", `cd "$0" && exec "$@"`, "/var/lib/data", "/bin/true"},
+		},
+		{
+			name: "env and working dir",
+			hook: v1.ExecHook{
+				Command:    []string{"/bin/true"},
+				Env:        []v1.HookEnvVar{{Name: "A", Value: "1"}},
+				WorkingDir: "/var/lib/data",
+			},
+			expected: []string{"/bin/sh", "-c", `cd "$0" && exec "$@"`, "/var/lib/data", "env", "A=1", "/bin/true"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, buildHookCommand(&test.hook))
+		})
+	}
+}
+
+func TestValidateExecHook(t *testing.T) {
+	tests := []struct {
+		name          string
+		hook          v1.ExecHook
+		expectedError string
+	}{
+		{
+			name: "no containers specified",
+		},
+		{
+			name: "containers",
+			hook: v1.ExecHook{Containers: []string{"a", "b"}},
+		},
+		{
+			name:          "container and pattern",
+			hook:          v1.ExecHook{Container: "a", ContainerPattern: "b*"},
+			expectedError: "only one of container, containers and containerPattern may be specified",
+		},
+		{
+			name:          "empty container name",
+			hook:          v1.ExecHook{Containers: []string{"a", ""}},
+			expectedError: "containers may not contain an empty name",
+		},
+		{
+			name:          "duplicate container name",
+			hook:          v1.ExecHook{Containers: []string{"a", "a"}},
+			expectedError: `container "a" is listed more than once`,
+		},
+		{
+			name:          "malformed pattern",
+			hook:          v1.ExecHook{ContainerPattern: "db-["},
+			expectedError: `invalid containerPattern "db-["`,
+		},
+		{
+			name:          "invalid env name",
+			hook:          v1.ExecHook{Env: []v1.HookEnvVar{{Name: "A=B"}}},
+			expectedError: `invalid environment variable name "A=B"`,
+		},
+		{
+			name:          "relative working dir",
+			hook:          v1.ExecHook{WorkingDir: "data"},
+			expectedError: `workingDir "data" must be an absolute path`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateExecHook(&test.hook)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
 type mockStreamExecutorFactory struct {
 	mock.Mock
 }
@@ -270,7 +494,8 @@ type mockPodCommandExecutor struct {
 	mock.Mock
 }
 
-func (e *mockPodCommandExecutor) executePodCommand(log logrus.FieldLogger, item map[string]interface{}, namespace, name, hookName string, hook *v1.ExecHook) error {
+func (e *mockPodCommandExecutor) executePodCommand(log logrus.FieldLogger, item map[string]interface{}, namespace, name, hookName string, hook *v1.ExecHook) ([]v1.HookExecution, error) {
 	args := e.Called(log, item, namespace, name, hookName, hook)
-	return args.Error(0)
+	executions, _ := args.Get(0).([]v1.HookExecution)
+	return executions, args.Error(1)
 }
@@ -0,0 +1,69 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// provenanceAction implements ItemAction.
+type provenanceAction struct {
+	log logrus.FieldLogger
+}
+
+// NewProvenanceAction creates a new ItemAction that annotates every item with the
+// backup it was taken in, so that restored items record where they came from.
+func NewProvenanceAction(log logrus.FieldLogger) ItemAction {
+	return &provenanceAction{log: log}
+}
+
+// AppliesTo returns a ResourceSelector that applies to all items.
+func (a *provenanceAction) AppliesTo() (ResourceSelector, error) {
+	return ResourceSelector{}, nil
+}
+
+// Execute adds annotations with the backup's name, creation time, and source cluster
+// (if the cluster has a name) to the item.
+func (a *provenanceAction) Execute(item runtime.Unstructured, backup *v1.Backup) (runtime.Unstructured, []ResourceIdentifier, error) {
+	metadata, err := meta.Accessor(item)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to access item metadata")
+	}
+
+	annotations := metadata.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	annotations[v1.BackupNameAnnotation] = backup.Name
+	annotations[v1.BackupTimestampAnnotation] = backup.CreationTimestamp.UTC().Format(time.RFC3339)
+	if backup.Status.ClusterInfo != nil && backup.Status.ClusterInfo.Name != "" {
+		annotations[v1.SourceClusterAnnotation] = backup.Status.ClusterInfo.Name
+	}
+
+	metadata.SetAnnotations(annotations)
+
+	return item, nil, nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestProvenanceActionAppliesTo(t *testing.T) {
+	a := NewProvenanceAction(arktest.NewLogger())
+
+	actual, err := a.AppliesTo()
+	require.NoError(t, err)
+
+	assert.Equal(t, ResourceSelector{}, actual)
+}
+
+func TestProvenanceActionExecute(t *testing.T) {
+	created := metav1.NewTime(time.Date(2018, 6, 1, 12, 30, 0, 0, time.UTC))
+
+	tests := []struct {
+		name                string
+		item                runtime.Unstructured
+		clusterInfo         *v1.ClusterInfo
+		expectedAnnotations map[string]string
+	}{
+		{
+			name:        "item without annotations",
+			item:        unstructuredOrDie(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns", "name": "cm"}}`),
+			clusterInfo: &v1.ClusterInfo{Name: "prod"},
+			expectedAnnotations: map[string]string{
+				v1.BackupNameAnnotation:      "backup-1",
+				v1.BackupTimestampAnnotation: "2018-06-01T12:30:00Z",
+				v1.SourceClusterAnnotation:   "prod",
+			},
+		},
+		{
+			name: "existing annotations are preserved",
+			item: unstructuredOrDie(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns", "name": "cm", "annotations": {"foo": "bar"}}}`),
+			expectedAnnotations: map[string]string{
+				"foo":                        "bar",
+				v1.BackupNameAnnotation:      "backup-1",
+				v1.BackupTimestampAnnotation: "2018-06-01T12:30:00Z",
+			},
+		},
+		{
+			name:        "cluster without a name is not recorded",
+			item:        unstructuredOrDie(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns", "name": "cm"}}`),
+			clusterInfo: &v1.ClusterInfo{KubernetesVersion: "v1.10.0"},
+			expectedAnnotations: map[string]string{
+				v1.BackupNameAnnotation:      "backup-1",
+				v1.BackupTimestampAnnotation: "2018-06-01T12:30:00Z",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backup := arktest.NewTestBackup().WithName("backup-1").Backup
+			backup.CreationTimestamp = created
+			backup.Status.ClusterInfo = test.clusterInfo
+
+			a := NewProvenanceAction(arktest.NewLogger())
+
+			res, additional, err := a.Execute(test.item, backup)
+			require.NoError(t, err)
+
+			assert.Empty(t, additional)
+			assert.Equal(t, test.expectedAnnotations, res.(metav1.Object).GetAnnotations())
+		})
+	}
+}
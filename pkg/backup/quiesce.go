@@ -0,0 +1,334 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kuberrs "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/kuberesource"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+const (
+	// defaultQuiesceTimeout is how long a backup waits for its quiesced workloads' pods to be
+	// terminated if its quiesce spec doesn't specify a timeout.
+	defaultQuiesceTimeout = 5 * time.Minute
+
+	quiescePollInterval = 5 * time.Second
+)
+
+// quiescedResources are the resources that a backup's quiesce spec scales down.
+var quiescedResources = []schema.GroupResource{
+	kuberesource.Deployments,
+	kuberesource.StatefulSets,
+}
+
+// quiescer scales down the workloads selected by a backup's quiesce spec before the backup's
+// items are backed up, and scales them back up afterwards.
+type quiescer struct {
+	log             logrus.FieldLogger
+	backup          *api.Backup
+	namespaces      *collections.IncludesExcludes
+	discoveryHelper discovery.Helper
+	dynamicFactory  client.DynamicFactory
+	pollInterval    time.Duration
+
+	// quiesced are the workloads that have been scaled down.
+	quiesced []quiescedWorkload
+}
+
+// quiescedWorkload is a workload that has been scaled down, and the client for its resource in its
+// namespace.
+type quiescedWorkload struct {
+	api.QuiescedWorkload
+	client client.Dynamic
+}
+
+func newQuiescer(log logrus.FieldLogger, backup *api.Backup, discoveryHelper discovery.Helper, dynamicFactory client.DynamicFactory) *quiescer {
+	return &quiescer{
+		log:             log,
+		backup:          backup,
+		namespaces:      getNamespaceIncludesExcludes(backup),
+		discoveryHelper: discoveryHelper,
+		dynamicFactory:  dynamicFactory,
+		pollInterval:    quiescePollInterval,
+	}
+}
+
+// quiesce scales each of the workloads selected by the backup's quiesce spec down to zero
+// replicas, recording its original replica count in an annotation on it, so that it's restored
+// with that count, and in the backup's status. It then waits for their pods to be terminated, or
+// for the spec's timeout to elapse. Workloads that were scaled down are scaled back up by
+// unquiesce, even if an error is returned.
+func (q *quiescer) quiesce() error {
+	spec := q.backup.Spec.Quiesce
+
+	selector, err := metav1.LabelSelectorAsSelector(spec.LabelSelector)
+	if err != nil {
+		return errors.Wrap(err, "invalid quiesce label selector")
+	}
+	namespaces := collections.NewIncludesExcludes().Includes(spec.IncludedNamespaces...)
+
+	var errs []error
+	for _, groupResource := range quiescedResources {
+		gv, resource, objs, err := q.listWorkloads(groupResource, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, obj := range objs {
+			if !q.namespaces.ShouldInclude(obj.GetNamespace()) || !namespaces.ShouldInclude(obj.GetNamespace()) {
+				continue
+			}
+
+			resourceClient, err := q.dynamicFactory.ClientForGroupVersionResource(gv, resource, obj.GetNamespace())
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if err := q.scaleDown(groupResource, resourceClient, obj); err != nil {
+				errs = append(errs, errors.Wrapf(err, "error scaling down %s %s/%s", groupResource, obj.GetNamespace(), obj.GetName()))
+			}
+		}
+	}
+
+	q.waitForTermination()
+
+	return kuberrs.NewAggregate(errs)
+}
+
+// unquiesceInterrupted scales each workload that still has the original replicas annotation back
+// up to its original replica count. The annotation is only left behind by a backup that was
+// interrupted before it scaled its workloads back up, so this must not be called while any
+// backups are running.
+func (q *quiescer) unquiesceInterrupted() error {
+	var errs []error
+	for _, groupResource := range quiescedResources {
+		gv, resource, objs, err := q.listWorkloads(groupResource, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, obj := range objs {
+			value, ok := obj.GetAnnotations()[api.OriginalReplicasAnnotation]
+			if !ok {
+				continue
+			}
+			replicas, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "invalid %s annotation %q on %s %s/%s", api.OriginalReplicasAnnotation, value, groupResource, obj.GetNamespace(), obj.GetName()))
+				continue
+			}
+
+			resourceClient, err := q.dynamicFactory.ClientForGroupVersionResource(gv, resource, obj.GetNamespace())
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			workload := api.QuiescedWorkload{
+				Resource:  groupResource.String(),
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+				Replicas:  replicas,
+			}
+			q.log.Infof("%s %s/%s was left scaled down by an interrupted backup", workload.Resource, workload.Namespace, workload.Name)
+			q.quiesced = append(q.quiesced, quiescedWorkload{QuiescedWorkload: workload, client: resourceClient})
+		}
+	}
+
+	if err := q.unquiesce(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return kuberrs.NewAggregate(errs)
+}
+
+// listWorkloads lists the workloads of groupResource in all namespaces using options, and
+// returns them with the group version and resource to get clients for them with.
+func (q *quiescer) listWorkloads(groupResource schema.GroupResource, options metav1.ListOptions) (schema.GroupVersion, metav1.APIResource, []*unstructured.Unstructured, error) {
+	gvr, resource, err := q.discoveryHelper.ResourceFor(groupResource.WithVersion(""))
+	if err != nil {
+		return schema.GroupVersion{}, metav1.APIResource{}, nil, errors.Wrapf(err, "error resolving %s", groupResource)
+	}
+
+	listClient, err := q.dynamicFactory.ClientForGroupVersionResource(gvr.GroupVersion(), resource, "")
+	if err != nil {
+		return schema.GroupVersion{}, metav1.APIResource{}, nil, err
+	}
+	list, err := listClient.List(options)
+	if err != nil {
+		return schema.GroupVersion{}, metav1.APIResource{}, nil, errors.Wrapf(err, "error listing %s", groupResource)
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return schema.GroupVersion{}, metav1.APIResource{}, nil, errors.WithStack(err)
+	}
+
+	objs := make([]*unstructured.Unstructured, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(*unstructured.Unstructured)
+		if !ok {
+			return schema.GroupVersion{}, metav1.APIResource{}, nil, errors.Errorf("unexpected type %T", item)
+		}
+		objs = append(objs, obj)
+	}
+
+	return gvr.GroupVersion(), resource, objs, nil
+}
+
+// scaleDown scales the workload down to zero replicas. If it already has the original replicas
+// annotation, because an earlier backup didn't scale it back up, that count is kept.
+func (q *quiescer) scaleDown(groupResource schema.GroupResource, resourceClient client.Dynamic, obj *unstructured.Unstructured) error {
+	replicas, found, _ := unstructured.NestedInt64(obj.UnstructuredContent(), "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if value, ok := annotations[api.OriginalReplicasAnnotation]; ok {
+		if original, err := strconv.ParseInt(value, 10, 64); err == nil {
+			replicas = original
+		}
+	}
+
+	if replicas == 0 {
+		return nil
+	}
+
+	annotations[api.OriginalReplicasAnnotation] = strconv.FormatInt(replicas, 10)
+	obj.SetAnnotations(annotations)
+	if err := unstructured.SetNestedField(obj.UnstructuredContent(), int64(0), "spec", "replicas"); err != nil {
+		return errors.WithStack(err)
+	}
+
+	q.log.Infof("Scaling down %s %s/%s from %d replicas", groupResource, obj.GetNamespace(), obj.GetName(), replicas)
+	if _, err := resourceClient.Update(obj); err != nil {
+		return err
+	}
+
+	workload := api.QuiescedWorkload{
+		Resource:  groupResource.String(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Replicas:  replicas,
+	}
+	q.backup.Status.QuiescedWorkloads = append(q.backup.Status.QuiescedWorkloads, workload)
+	q.quiesced = append(q.quiesced, quiescedWorkload{QuiescedWorkload: workload, client: resourceClient})
+
+	return nil
+}
+
+// waitForTermination waits until none of the quiesced workloads have any pods, logging a warning
+// for each that still does once the quiesce spec's timeout elapses.
+func (q *quiescer) waitForTermination() {
+	if len(q.quiesced) == 0 {
+		return
+	}
+
+	timeout := q.backup.Spec.Quiesce.Timeout.Duration
+	if timeout == 0 {
+		timeout = defaultQuiesceTimeout
+	}
+
+	terminated := make([]bool, len(q.quiesced))
+	err := wait.PollImmediate(q.pollInterval, timeout, func() (bool, error) {
+		done := true
+		for i, workload := range q.quiesced {
+			if terminated[i] {
+				continue
+			}
+
+			obj, err := workload.client.Get(workload.Name, metav1.GetOptions{})
+			if err != nil {
+				q.log.WithError(err).Warnf("Error getting %s %s/%s", workload.Resource, workload.Namespace, workload.Name)
+				done = false
+				continue
+			}
+
+			replicas, _, _ := unstructured.NestedInt64(obj.UnstructuredContent(), "status", "replicas")
+			observedGeneration, _, _ := unstructured.NestedInt64(obj.UnstructuredContent(), "status", "observedGeneration")
+			if replicas == 0 && observedGeneration >= obj.GetGeneration() {
+				terminated[i] = true
+			} else {
+				done = false
+			}
+		}
+		return done, nil
+	})
+
+	if err == wait.ErrWaitTimeout {
+		for i, workload := range q.quiesced {
+			if !terminated[i] {
+				q.log.Warnf("%s %s/%s still had pods after %v; backing up anyway", workload.Resource, workload.Namespace, workload.Name, timeout)
+			}
+		}
+	}
+}
+
+// unquiesce scales each of the workloads that were scaled down back up to its original replica
+// count, and removes the original replicas annotation from it.
+func (q *quiescer) unquiesce() error {
+	var errs []error
+
+	for _, workload := range q.quiesced {
+		if err := q.scaleUp(workload); err != nil {
+			q.log.WithError(err).Errorf("Error scaling %s %s/%s back up to %d replicas", workload.Resource, workload.Namespace, workload.Name, workload.Replicas)
+			errs = append(errs, errors.Wrapf(err, "error scaling up %s %s/%s", workload.Resource, workload.Namespace, workload.Name))
+		}
+	}
+	q.quiesced = nil
+
+	return kuberrs.NewAggregate(errs)
+}
+
+func (q *quiescer) scaleUp(workload quiescedWorkload) error {
+	obj, err := workload.client.Get(workload.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	annotations := obj.GetAnnotations()
+	delete(annotations, api.OriginalReplicasAnnotation)
+	obj.SetAnnotations(annotations)
+	if err := unstructured.SetNestedField(obj.UnstructuredContent(), workload.Replicas, "spec", "replicas"); err != nil {
+		return errors.WithStack(err)
+	}
+
+	q.log.Infof("Scaling %s %s/%s back up to %d replicas", workload.Resource, workload.Namespace, workload.Name, workload.Replicas)
+	_, err = workload.client.Update(obj)
+	return err
+}
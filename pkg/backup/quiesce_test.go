@@ -0,0 +1,148 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func quiescedWorkloadObj(kind, namespace, name string, replicas int64, annotations string) *unstructured.Unstructured {
+	return unstructuredOrDie(fmt.Sprintf(`{
+		"apiVersion": "apps/v1",
+		"kind": %q,
+		"metadata": {"namespace": %q, "name": %q, "labels": {"quiesce": "true"}, "annotations": {%s}},
+		"spec": {"replicas": %d},
+		"status": {"replicas": 0}
+	}`, kind, namespace, name, annotations, replicas))
+}
+
+func TestQuiescer(t *testing.T) {
+	discoveryHelper := arktest.NewFakeDiscoveryHelper(false, map[schema.GroupVersionResource]schema.GroupVersionResource{
+		{Group: "apps", Resource: "deployments"}:  {Group: "apps", Version: "v1", Resource: "deployments"},
+		{Group: "apps", Resource: "statefulsets"}: {Group: "apps", Version: "v1", Resource: "statefulsets"},
+	})
+
+	deployment := quiescedWorkloadObj("Deployment", "ns-1", "web", 3, "")
+	statefulSet := quiescedWorkloadObj("StatefulSet", "ns-1", "db", 0, `"ark.heptio.com/original-replicas": "2"`)
+
+	deploymentClient := &arktest.FakeDynamicClient{}
+	deploymentClient.On("List", metav1.ListOptions{LabelSelector: "quiesce=true"}).Return(&unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			*deployment,
+			*quiescedWorkloadObj("Deployment", "ns-1", "already-scaled-down", 0, ""),
+			*quiescedWorkloadObj("Deployment", "ns-2", "excluded", 1, ""),
+		},
+	}, nil)
+	deploymentClient.On("Get", "web", metav1.GetOptions{}).Return(deployment, nil)
+
+	statefulSetClient := &arktest.FakeDynamicClient{}
+	statefulSetClient.On("List", metav1.ListOptions{LabelSelector: "quiesce=true"}).Return(&unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{*statefulSet},
+	}, nil)
+	statefulSetClient.On("Get", "db", metav1.GetOptions{}).Return(statefulSet, nil)
+
+	var updates []string
+	recordUpdate := func(args mock.Arguments) {
+		obj := args.Get(0).(*unstructured.Unstructured)
+		replicas, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		updates = append(updates, fmt.Sprintf("%s replicas=%d annotation=%q", obj.GetName(), replicas, obj.GetAnnotations()[v1.OriginalReplicasAnnotation]))
+	}
+	deploymentClient.On("Update", mock.Anything).Run(recordUpdate).Return(deployment, nil)
+	statefulSetClient.On("Update", mock.Anything).Run(recordUpdate).Return(statefulSet, nil)
+
+	dynamicFactory := &arktest.FakeDynamicFactory{}
+	gv := schema.GroupVersion{Group: "apps", Version: "v1"}
+	for _, namespace := range []string{"", "ns-1"} {
+		dynamicFactory.On("ClientForGroupVersionResource", gv, metav1.APIResource{Name: "deployments"}, namespace).Return(deploymentClient, nil)
+		dynamicFactory.On("ClientForGroupVersionResource", gv, metav1.APIResource{Name: "statefulsets"}, namespace).Return(statefulSetClient, nil)
+	}
+
+	backup := arktest.NewTestBackup().WithName("backup-1").WithExcludedNamespaces("ns-2").WithQuiesce(&v1.QuiesceSpec{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"quiesce": "true"}},
+	}).Backup
+
+	q := newQuiescer(arktest.NewLogger(), backup, discoveryHelper, dynamicFactory)
+	q.pollInterval = 0
+
+	require.NoError(t, q.quiesce())
+	assert.Equal(t, []string{
+		`web replicas=0 annotation="3"`,
+		`db replicas=0 annotation="2"`,
+	}, updates)
+	assert.Equal(t, []v1.QuiescedWorkload{
+		{Resource: "deployments.apps", Namespace: "ns-1", Name: "web", Replicas: 3},
+		{Resource: "statefulsets.apps", Namespace: "ns-1", Name: "db", Replicas: 2},
+	}, backup.Status.QuiescedWorkloads)
+
+	updates = nil
+	require.NoError(t, q.unquiesce())
+	assert.Equal(t, []string{
+		`web replicas=3 annotation=""`,
+		`db replicas=2 annotation=""`,
+	}, updates)
+}
+
+func TestUnquiesceInterrupted(t *testing.T) {
+	discoveryHelper := arktest.NewFakeDiscoveryHelper(false, map[schema.GroupVersionResource]schema.GroupVersionResource{
+		{Group: "apps", Resource: "deployments"}:  {Group: "apps", Version: "v1", Resource: "deployments"},
+		{Group: "apps", Resource: "statefulsets"}: {Group: "apps", Version: "v1", Resource: "statefulsets"},
+	})
+
+	leftScaledDown := quiescedWorkloadObj("Deployment", "ns-1", "web", 0, `"ark.heptio.com/original-replicas": "3"`)
+
+	deploymentClient := &arktest.FakeDynamicClient{}
+	deploymentClient.On("List", metav1.ListOptions{}).Return(&unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			*leftScaledDown,
+			*quiescedWorkloadObj("Deployment", "ns-2", "not-quiesced", 1, ""),
+		},
+	}, nil)
+	deploymentClient.On("Get", "web", metav1.GetOptions{}).Return(leftScaledDown, nil)
+
+	statefulSetClient := &arktest.FakeDynamicClient{}
+	statefulSetClient.On("List", metav1.ListOptions{}).Return(&unstructured.UnstructuredList{}, nil)
+
+	var updates []string
+	deploymentClient.On("Update", mock.Anything).Run(func(args mock.Arguments) {
+		obj := args.Get(0).(*unstructured.Unstructured)
+		replicas, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		updates = append(updates, fmt.Sprintf("%s replicas=%d annotation=%q", obj.GetName(), replicas, obj.GetAnnotations()[v1.OriginalReplicasAnnotation]))
+	}).Return(leftScaledDown, nil)
+
+	dynamicFactory := &arktest.FakeDynamicFactory{}
+	gv := schema.GroupVersion{Group: "apps", Version: "v1"}
+	for _, namespace := range []string{"", "ns-1"} {
+		dynamicFactory.On("ClientForGroupVersionResource", gv, metav1.APIResource{Name: "deployments"}, namespace).Return(deploymentClient, nil)
+	}
+	dynamicFactory.On("ClientForGroupVersionResource", gv, metav1.APIResource{Name: "statefulsets"}, "").Return(statefulSetClient, nil)
+
+	b := &kubernetesBackupper{discoveryHelper: discoveryHelper, dynamicFactory: dynamicFactory}
+	require.NoError(t, b.UnquiesceInterrupted(arktest.NewLogger()))
+	assert.Equal(t, []string{`web replicas=3 annotation=""`}, updates)
+}
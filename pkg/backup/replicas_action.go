@@ -0,0 +1,105 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	corev1api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// replicasAction implements ItemAction.
+type replicasAction struct {
+	log logrus.FieldLogger
+}
+
+// NewReplicasAction creates a new ItemAction that records the desired replica count of
+// Deployments and StatefulSets that are backed up with zero replicas.
+func NewReplicasAction(log logrus.FieldLogger) ItemAction {
+	return &replicasAction{log: log}
+}
+
+// AppliesTo returns a ResourceSelector that applies only to Deployments and StatefulSets.
+func (a *replicasAction) AppliesTo() (ResourceSelector, error) {
+	return ResourceSelector{
+		IncludedResources: []string{"deployments", "statefulsets"},
+	}, nil
+}
+
+// Execute records, in the DesiredReplicasAnnotation of a workload that's scaled down to zero
+// replicas, the replica count in its last applied configuration, so that restores can recreate it
+// with that count instead of zero. Workloads that already have the annotation, and those scaled
+// down by the backup's quiesce spec, are left as they are.
+func (a *replicasAction) Execute(item runtime.Unstructured, backup *v1.Backup) (runtime.Unstructured, []ResourceIdentifier, error) {
+	replicas, found, _ := unstructured.NestedInt64(item.UnstructuredContent(), "spec", "replicas")
+	if !found || replicas != 0 {
+		return item, nil, nil
+	}
+
+	metadata, err := meta.Accessor(item)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to access metadata")
+	}
+
+	annotations := metadata.GetAnnotations()
+	if _, ok := annotations[v1.OriginalReplicasAnnotation]; ok {
+		return item, nil, nil
+	}
+	if _, ok := annotations[v1.DesiredReplicasAnnotation]; ok {
+		return item, nil, nil
+	}
+
+	lastApplied, ok := annotations[corev1api.LastAppliedConfigAnnotation]
+	if !ok {
+		return item, nil, nil
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(lastApplied), &config); err != nil {
+		a.log.WithError(errors.WithStack(err)).Warn("Unable to parse last applied configuration; not recording desired replicas")
+		return item, nil, nil
+	}
+
+	// an unset replica count defaults to one
+	desired := int64(1)
+	if value, found, _ := unstructured.NestedFieldCopy(config, "spec", "replicas"); found {
+		count, ok := value.(float64)
+		if !ok {
+			a.log.Warnf("Unexpected type %T for spec.replicas in last applied configuration; not recording desired replicas", value)
+			return item, nil, nil
+		}
+		desired = int64(count)
+	}
+	if desired == 0 {
+		return item, nil, nil
+	}
+
+	a.log.Infof("Recording desired replica count %d from last applied configuration", desired)
+	annotations[v1.DesiredReplicasAnnotation] = strconv.FormatInt(desired, 10)
+	metadata.SetAnnotations(annotations)
+
+	return item, nil, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestReplicasActionExecute(t *testing.T) {
+	tests := []struct {
+		name            string
+		obj             string
+		expectedDesired string
+	}{
+		{
+			name: "a running workload isn't annotated",
+			obj:  `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"web","annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{\"spec\":{\"replicas\":3}}"}},"spec":{"replicas":3}}`,
+		},
+		{
+			name:            "a scaled down workload is annotated with its last applied replicas",
+			obj:             `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"web","annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{\"spec\":{\"replicas\":3}}"}},"spec":{"replicas":0}}`,
+			expectedDesired: "3",
+		},
+		{
+			name:            "a last applied configuration without replicas means one replica",
+			obj:             `{"apiVersion":"apps/v1","kind":"StatefulSet","metadata":{"namespace":"ns-1","name":"db","annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{\"spec\":{}}"}},"spec":{"replicas":0}}`,
+			expectedDesired: "1",
+		},
+		{
+			name:            "an existing desired replicas annotation is kept",
+			obj:             `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"web","annotations":{"ark.heptio.com/desired-replicas":"5","kubectl.kubernetes.io/last-applied-configuration":"{\"spec\":{\"replicas\":3}}"}},"spec":{"replicas":0}}`,
+			expectedDesired: "5",
+		},
+		{
+			name: "a quiesced workload isn't annotated",
+			obj:  `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"web","annotations":{"ark.heptio.com/original-replicas":"2","kubectl.kubernetes.io/last-applied-configuration":"{\"spec\":{\"replicas\":3}}"}},"spec":{"replicas":0}}`,
+		},
+		{
+			name: "a scaled down workload without a last applied configuration isn't annotated",
+			obj:  `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"web"},"spec":{"replicas":0}}`,
+		},
+		{
+			name: "an unparseable last applied configuration is ignored",
+			obj:  `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"web","annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{"}},"spec":{"replicas":0}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			action := NewReplicasAction(arktest.NewLogger())
+
+			res, additional, err := action.Execute(unstructuredOrDie(test.obj), arktest.NewTestBackup().Backup)
+			require.NoError(t, err)
+			assert.Empty(t, additional)
+
+			annotations := unstructuredOrDie(test.obj).GetAnnotations()
+			if test.expectedDesired != "" {
+				if annotations == nil {
+					annotations = make(map[string]string)
+				}
+				annotations[v1.DesiredReplicasAnnotation] = test.expectedDesired
+			}
+			metadata, err := meta.Accessor(res)
+			require.NoError(t, err)
+			assert.Equal(t, annotations, metadata.GetAnnotations())
+		})
+	}
+}
@@ -45,6 +45,7 @@ type resourceBackupperFactory interface {
 		backedUpItems map[itemKey]struct{},
 		cohabitatingResources map[string]*cohabitatingResource,
 		actions []resolvedAction,
+		sanitizers []resolvedSanitizer,
 		podCommandExecutor podCommandExecutor,
 		tarWriter tarWriter,
 		resourceHooks []resourceHook,
@@ -65,6 +66,7 @@ func (f *defaultResourceBackupperFactory) newResourceBackupper(
 	backedUpItems map[itemKey]struct{},
 	cohabitatingResources map[string]*cohabitatingResource,
 	actions []resolvedAction,
+	sanitizers []resolvedSanitizer,
 	podCommandExecutor podCommandExecutor,
 	tarWriter tarWriter,
 	resourceHooks []resourceHook,
@@ -80,6 +82,7 @@ func (f *defaultResourceBackupperFactory) newResourceBackupper(
 		discoveryHelper:       discoveryHelper,
 		backedUpItems:         backedUpItems,
 		actions:               actions,
+		sanitizers:            sanitizers,
 		cohabitatingResources: cohabitatingResources,
 		podCommandExecutor:    podCommandExecutor,
 		tarWriter:             tarWriter,
@@ -104,6 +107,7 @@ type defaultResourceBackupper struct {
 	backedUpItems         map[itemKey]struct{}
 	cohabitatingResources map[string]*cohabitatingResource
 	actions               []resolvedAction
+	sanitizers            []resolvedSanitizer
 	podCommandExecutor    podCommandExecutor
 	tarWriter             tarWriter
 	resourceHooks         []resourceHook
@@ -140,7 +144,8 @@ func (rb *defaultResourceBackupper) backupResource(
 				// back up cluster-scoped resources if we're doing a full-cluster
 				// (all namespaces) backup. Note that in the case of a subset of
 				// namespaces being backed up, some related cluster-scoped resources
-				// may still be backed up if triggered by a custom action (e.g. PVC->PV).
+				// may still be backed up if triggered by a custom action (e.g. PVC->PV, or custom
+				// resource->CRD).
 				// If we're processing namespaces themselves, we will not skip here, they may be
 				// filtered out later.
 				log.Info("Skipping resource because it's cluster-scoped and only specific namespaces are included in the backup")
@@ -157,14 +162,10 @@ func (rb *defaultResourceBackupper) backupResource(
 		return nil
 	}
 
-	if cohabitator, found := rb.cohabitatingResources[resource.Name]; found {
+	if cohabitator, found := rb.cohabitatingResources[resource.Name]; found && cohabitator.includes(gr) {
 		if cohabitator.seen {
-			log.WithFields(
-				logrus.Fields{
-					"cohabitatingResource1": cohabitator.groupResource1.String(),
-					"cohabitatingResource2": cohabitator.groupResource2.String(),
-				},
-			).Infof("Skipping resource because it cohabitates and we've already processed it")
+			log.WithField("cohabitatingResources", cohabitator.groupResourceStrings()).
+				Infof("Skipping resource because it cohabitates and we've already processed it")
 			return nil
 		}
 		cohabitator.seen = true
@@ -176,6 +177,7 @@ func (rb *defaultResourceBackupper) backupResource(
 		rb.resources,
 		rb.backedUpItems,
 		rb.actions,
+		rb.sanitizers,
 		rb.podCommandExecutor,
 		rb.tarWriter,
 		rb.resourceHooks,
@@ -203,6 +205,10 @@ func (rb *defaultResourceBackupper) backupResource(
 		}
 
 		for _, ns := range namespacesToList {
+			if abortBackup(rb.backup, errs) {
+				break
+			}
+
 			log.WithField("namespace", ns).Info("Getting namespace")
 			unstructured, err := resourceClient.Get(ns, metav1.GetOptions{})
 			if err != nil {
@@ -230,25 +236,38 @@ func (rb *defaultResourceBackupper) backupResource(
 	}
 
 	for _, namespace := range namespacesToList {
+		if abortBackup(rb.backup, errs) {
+			break
+		}
+
 		resourceClient, err := rb.dynamicFactory.ClientForGroupVersionResource(gv, resource, namespace)
 		if err != nil {
 			return err
 		}
 
+		// failing to list one namespace's items is recorded, and the remaining
+		// namespaces are still backed up
 		log.WithField("namespace", namespace).Info("Listing items")
 		unstructuredList, err := resourceClient.List(metav1.ListOptions{LabelSelector: rb.labelSelector})
 		if err != nil {
-			return errors.WithStack(err)
+			log.WithError(err).WithField("namespace", namespace).Error("Error listing items")
+			errs = append(errs, errors.Wrapf(err, "error listing %s in namespace %q", gr, namespace))
+			continue
 		}
 
 		// do the backup
 		items, err := meta.ExtractList(unstructuredList)
 		if err != nil {
-			return errors.WithStack(err)
+			errs = append(errs, errors.WithStack(err))
+			continue
 		}
 
 		log.WithField("namespace", namespace).Infof("Retrieved %d items", len(items))
 		for _, item := range items {
+			if abortBackup(rb.backup, errs) {
+				break
+			}
+
 			unstructured, ok := item.(runtime.Unstructured)
 			if !ok {
 				errs = append(errs, errors.Errorf("unexpected type %T", item))
@@ -276,14 +295,15 @@ func (rb *defaultResourceBackupper) backupResource(
 }
 
 // getNamespacesToList examines ie and resolves the includes and excludes to a full list of
-// namespaces to list. If ie is nil or it includes *, the result is just "" (list across all
-// namespaces). Otherwise, the result is a list of every included namespace minus all excluded ones.
+// namespaces to list. If ie is nil, it includes *, or it includes a pattern such as kube-*, the
+// result is just "" (list across all namespaces, which are filtered as they're backed up).
+// Otherwise, the result is a list of every included namespace minus all excluded ones.
 func getNamespacesToList(ie *collections.IncludesExcludes) []string {
 	if ie == nil {
 		return []string{""}
 	}
 
-	if ie.ShouldInclude("*") {
+	if ie.ShouldInclude("*") || ie.HasGlobIncludes() {
 		// "" means all namespaces
 		return []string{""}
 	}
@@ -300,16 +320,37 @@ func getNamespacesToList(ie *collections.IncludesExcludes) []string {
 
 type cohabitatingResource struct {
 	resource       string
-	groupResource1 schema.GroupResource
-	groupResource2 schema.GroupResource
+	groupResources []schema.GroupResource
 	seen           bool
 }
 
-func newCohabitatingResource(resource, group1, group2 string) *cohabitatingResource {
-	return &cohabitatingResource{
-		resource:       resource,
-		groupResource1: schema.GroupResource{Group: group1, Resource: resource},
-		groupResource2: schema.GroupResource{Group: group2, Resource: resource},
-		seen:           false,
+func newCohabitatingResource(resource string, groups ...string) *cohabitatingResource {
+	c := &cohabitatingResource{
+		resource: resource,
+		seen:     false,
+	}
+	for _, group := range groups {
+		c.groupResources = append(c.groupResources, schema.GroupResource{Group: group, Resource: resource})
+	}
+	return c
+}
+
+// includes returns whether gr is one of the cohabitating group resources, so that
+// unrelated resources that happen to share the name, such as custom resources, are
+// backed up normally.
+func (c *cohabitatingResource) includes(gr schema.GroupResource) bool {
+	for _, groupResource := range c.groupResources {
+		if groupResource == gr {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cohabitatingResource) groupResourceStrings() []string {
+	var res []string
+	for _, groupResource := range c.groupResources {
+		res = append(res, groupResource.String())
 	}
+	return res
 }
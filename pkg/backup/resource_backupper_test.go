@@ -268,6 +268,7 @@ func TestBackupResource(t *testing.T) {
 				backedUpItems,
 				cohabitatingResources,
 				actions,
+				nil,
 				podCommandExecutor,
 				tarWriter,
 				resourceHooks,
@@ -288,6 +289,7 @@ func TestBackupResource(t *testing.T) {
 					test.resources,
 					backedUpItems,
 					actions,
+					mock.Anything,
 					podCommandExecutor,
 					tarWriter,
 					resourceHooks,
@@ -342,6 +344,8 @@ func TestBackupResourceCohabitation(t *testing.T) {
 		groupVersion1 schema.GroupVersion
 		apiGroup2     *metav1.APIResourceList
 		groupVersion2 schema.GroupVersion
+		// expectBoth is whether the resource in apiGroup2 is also backed up
+		expectBoth bool
 	}{
 		{
 			name:          "deployments - extensions first",
@@ -375,6 +379,18 @@ func TestBackupResourceCohabitation(t *testing.T) {
 			apiGroup2:     extensionsGroup,
 			groupVersion2: extensionsGroupVersion,
 		},
+		{
+			name:          "deployments - unrelated group with the same resource name",
+			apiResource:   deploymentsResource,
+			apiGroup1:     appsGroup,
+			groupVersion1: appsGroupVersion,
+			apiGroup2: &metav1.APIResourceList{
+				GroupVersion: "example.com/v1",
+				APIResources: []metav1.APIResource{deploymentsResource},
+			},
+			groupVersion2: schema.GroupVersion{Group: "example.com", Version: "v1"},
+			expectBoth:    true,
+		},
 	}
 
 	for _, test := range tests {
@@ -427,6 +443,7 @@ func TestBackupResourceCohabitation(t *testing.T) {
 				backedUpItems,
 				cohabitatingResources,
 				actions,
+				nil,
 				podCommandExecutor,
 				tarWriter,
 				resourceHooks,
@@ -446,6 +463,7 @@ func TestBackupResourceCohabitation(t *testing.T) {
 				resources,
 				backedUpItems,
 				actions,
+				mock.Anything,
 				podCommandExecutor,
 				tarWriter,
 				resourceHooks,
@@ -466,6 +484,9 @@ func TestBackupResourceCohabitation(t *testing.T) {
 			require.NoError(t, err)
 
 			// STEP 3: try to back up the cohabitating resource
+			if test.expectBoth {
+				dynamicFactory.On("ClientForGroupVersionResource", test.groupVersion2, test.apiResource, "").Return(client, nil)
+			}
 			err = rb.backupResource(test.apiGroup2, test.apiResource)
 			require.NoError(t, err)
 		})
@@ -508,6 +529,7 @@ func TestBackupResourceOnlyIncludesSpecifiedNamespaces(t *testing.T) {
 		backedUpItems,
 		cohabitatingResources,
 		actions,
+		nil,
 		podCommandExecutor,
 		tarWriter,
 		resourceHooks,
@@ -541,6 +563,7 @@ func TestBackupResourceOnlyIncludesSpecifiedNamespaces(t *testing.T) {
 		resources,
 		backedUpItems,
 		actions,
+		mock.Anything,
 		podCommandExecutor,
 		tarWriter,
 		resourceHooks,
@@ -567,6 +590,41 @@ func TestBackupResourceOnlyIncludesSpecifiedNamespaces(t *testing.T) {
 	assert.Equal(t, "resources/namespaces/cluster/ns-1.json", tarWriter.headers[0].Name)
 }
 
+func TestGetNamespacesToList(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespaces *collections.IncludesExcludes
+		expected   []string
+	}{
+		{
+			name:       "nil lists all namespaces",
+			namespaces: nil,
+			expected:   []string{""},
+		},
+		{
+			name:       "* lists all namespaces",
+			namespaces: collections.NewIncludesExcludes().Includes("*").Excludes("ns-1"),
+			expected:   []string{""},
+		},
+		{
+			name:       "specific namespaces are listed individually",
+			namespaces: collections.NewIncludesExcludes().Includes("ns-1", "ns-2").Excludes("ns-2"),
+			expected:   []string{"ns-1"},
+		},
+		{
+			name:       "a pattern lists all namespaces",
+			namespaces: collections.NewIncludesExcludes().Includes("ns-1", "kube-*"),
+			expected:   []string{""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, getNamespacesToList(test.namespaces))
+		})
+	}
+}
+
 func TestBackupResourceListAllNamespacesExcludesCorrectly(t *testing.T) {
 	backup := &v1.Backup{}
 
@@ -603,6 +661,7 @@ func TestBackupResourceListAllNamespacesExcludesCorrectly(t *testing.T) {
 		backedUpItems,
 		cohabitatingResources,
 		actions,
+		nil,
 		podCommandExecutor,
 		tarWriter,
 		resourceHooks,
@@ -625,6 +684,7 @@ func TestBackupResourceListAllNamespacesExcludesCorrectly(t *testing.T) {
 		resources,
 		backedUpItems,
 		actions,
+		mock.Anything,
 		podCommandExecutor,
 		tarWriter,
 		resourceHooks,
@@ -661,6 +721,7 @@ func (ibf *mockItemBackupperFactory) newItemBackupper(
 	namespaces, resources *collections.IncludesExcludes,
 	backedUpItems map[itemKey]struct{},
 	actions []resolvedAction,
+	sanitizers []resolvedSanitizer,
 	podCommandExecutor podCommandExecutor,
 	tarWriter tarWriter,
 	resourceHooks []resourceHook,
@@ -674,6 +735,7 @@ func (ibf *mockItemBackupperFactory) newItemBackupper(
 		resources,
 		backedUpItems,
 		actions,
+		sanitizers,
 		podCommandExecutor,
 		tarWriter,
 		resourceHooks,
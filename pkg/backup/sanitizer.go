@@ -0,0 +1,121 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+// resolvedSanitizer is a BackupSanitizer with its resources resolved and its field selector
+// parsed.
+type resolvedSanitizer struct {
+	api.BackupSanitizer
+	resourceIncludesExcludes *collections.IncludesExcludes
+	fieldSelector            fields.Selector
+}
+
+// resolveSanitizers resolves the resources of each of sanitizers using helper.
+func resolveSanitizers(sanitizers []api.BackupSanitizer, helper discovery.Helper) ([]resolvedSanitizer, error) {
+	var resolved []resolvedSanitizer
+
+	for _, sanitizer := range sanitizers {
+		fieldSelector := fields.Everything()
+		if sanitizer.FieldSelector != "" {
+			var err error
+			if fieldSelector, err = fields.ParseSelector(sanitizer.FieldSelector); err != nil {
+				return nil, errors.Wrap(err, "invalid sanitizer field selector")
+			}
+		}
+
+		resolved = append(resolved, resolvedSanitizer{
+			BackupSanitizer:          sanitizer,
+			resourceIncludesExcludes: getResourceIncludesExcludes(helper, sanitizer.Resources, nil),
+			fieldSelector:            fieldSelector,
+		})
+	}
+
+	return resolved, nil
+}
+
+// sanitize returns the content of obj, an item of groupResource, with the fields and annotations
+// of each of sanitizers that match it removed. If any match, the content is a copy, so obj itself
+// still has everything that was removed.
+func sanitize(log logrus.FieldLogger, sanitizers []resolvedSanitizer, obj runtime.Unstructured, groupResource schema.GroupResource) map[string]interface{} {
+	content := obj.UnstructuredContent()
+	copied := false
+
+	for _, sanitizer := range sanitizers {
+		if !sanitizer.resourceIncludesExcludes.ShouldInclude(groupResource.String()) {
+			continue
+		}
+		if !collections.MatchesFieldSelector(sanitizer.fieldSelector, content) {
+			continue
+		}
+
+		if !copied {
+			content = runtime.DeepCopyJSON(content)
+			copied = true
+		}
+
+		for _, field := range sanitizer.RemoveFields {
+			log.Debugf("Removing field %s", field)
+			unstructured.RemoveNestedField(content, strings.Split(field, ".")...)
+		}
+
+		item := &unstructured.Unstructured{Object: content}
+		if annotations := item.GetAnnotations(); len(annotations) > 0 {
+			for key := range annotations {
+				if matchesAnnotation(key, sanitizer.RemoveAnnotations) {
+					log.Debugf("Removing annotation %s", key)
+					delete(annotations, key)
+				}
+			}
+			item.SetAnnotations(annotations)
+		}
+	}
+
+	return content
+}
+
+// matchesAnnotation returns whether key is one of keys, or starts with the prefix of one
+// of keys that ends in "*".
+func matchesAnnotation(key string, keys []string) bool {
+	for _, k := range keys {
+		if strings.HasSuffix(k, "*") {
+			if strings.HasPrefix(key, strings.TrimSuffix(k, "*")) {
+				return true
+			}
+			continue
+		}
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
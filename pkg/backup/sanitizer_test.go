@@ -0,0 +1,95 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name          string
+		sanitizers    []v1.BackupSanitizer
+		groupResource schema.GroupResource
+		item          string
+		expected      string
+	}{
+		{
+			name:          "top-level and nested fields are removed",
+			sanitizers:    []v1.BackupSanitizer{{RemoveFields: []string{"data", "stringData", "spec.template.spec.containers"}}},
+			groupResource: schema.GroupResource{Resource: "secrets"},
+			item:          `{"apiVersion":"v1","kind":"Secret","metadata":{"namespace":"ns","name":"s"},"type":"Opaque","data":{"key":"dmFsdWU="},"spec":{"template":{"spec":{"containers":[],"volumes":[]}}}}`,
+			expected:      `{"apiVersion":"v1","kind":"Secret","metadata":{"namespace":"ns","name":"s"},"type":"Opaque","spec":{"template":{"spec":{"volumes":[]}}}}`,
+		},
+		{
+			name:          "missing fields and paths through non-objects are ignored",
+			sanitizers:    []v1.BackupSanitizer{{RemoveFields: []string{"data", "type.value"}}},
+			groupResource: schema.GroupResource{Resource: "secrets"},
+			item:          `{"apiVersion":"v1","kind":"Secret","metadata":{"namespace":"ns","name":"s"},"type":"Opaque"}`,
+			expected:      `{"apiVersion":"v1","kind":"Secret","metadata":{"namespace":"ns","name":"s"},"type":"Opaque"}`,
+		},
+		{
+			name:          "annotations are removed by key and prefix",
+			sanitizers:    []v1.BackupSanitizer{{RemoveAnnotations: []string{"kubectl.kubernetes.io/*", "deployment.kubernetes.io/revision"}}},
+			groupResource: schema.GroupResource{Group: "apps", Resource: "deployments"},
+			item:          `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns","name":"d","annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{}","deployment.kubernetes.io/revision":"3","deployment.kubernetes.io/max-replicas":"4","foo":"bar"}}}`,
+			expected:      `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns","name":"d","annotations":{"deployment.kubernetes.io/max-replicas":"4","foo":"bar"}}}`,
+		},
+		{
+			name:          "every annotation is removed",
+			sanitizers:    []v1.BackupSanitizer{{RemoveAnnotations: []string{"*"}}},
+			groupResource: schema.GroupResource{Resource: "configmaps"},
+			item:          `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns","name":"cm","annotations":{"foo":"bar"}}}`,
+			expected:      `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns","name":"cm","annotations":{}}}`,
+		},
+		{
+			name: "only sanitizers whose resources and field selector match are applied",
+			sanitizers: []v1.BackupSanitizer{
+				{Resources: []string{"configmaps"}, RemoveFields: []string{"data"}},
+				{Resources: []string{"secrets"}, FieldSelector: "type=kubernetes.io/tls", RemoveFields: []string{"data"}},
+				{Resources: []string{"secrets"}, FieldSelector: "type=Opaque", RemoveFields: []string{"stringData"}},
+			},
+			groupResource: schema.GroupResource{Resource: "secrets"},
+			item:          `{"apiVersion":"v1","kind":"Secret","metadata":{"namespace":"ns","name":"s"},"type":"Opaque","data":{},"stringData":{}}`,
+			expected:      `{"apiVersion":"v1","kind":"Secret","metadata":{"namespace":"ns","name":"s"},"type":"Opaque","data":{}}`,
+		},
+	}
+
+	discoveryHelper := arktest.NewFakeDiscoveryHelper(true, nil)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sanitizers, err := resolveSanitizers(test.sanitizers, discoveryHelper)
+			require.NoError(t, err)
+
+			item := unstructuredOrDie(test.item)
+			res := sanitize(arktest.NewLogger(), sanitizers, item, test.groupResource)
+
+			assert.Equal(t, unstructuredOrDie(test.expected).UnstructuredContent(), res)
+			// the item itself is left as it was
+			assert.Equal(t, unstructuredOrDie(test.item).UnstructuredContent(), item.UnstructuredContent())
+		})
+	}
+}
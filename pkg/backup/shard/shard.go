@@ -0,0 +1,256 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package shard splits a backup's contents into separate tarballs, and combines them
+// again, for backups whose spec sets ShardBy.
+package shard
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// Manifest lists the shards that a sharded backup's contents are stored in.
+type Manifest struct {
+	Shards []Shard `json:"shards"`
+}
+
+// Shard is one of the tarballs that a sharded backup's contents are stored in.
+type Shard struct {
+	// Name identifies the shard within its backup, e.g. "namespaces.default" or
+	// "groups.apps".
+	Name string `json:"name"`
+
+	// Cluster is the additional cluster whose items are in the shard. It's empty
+	// for the cluster Ark is running in.
+	Cluster string `json:"cluster,omitempty"`
+
+	// Namespace is the namespace whose items are in the shard, for backups sharded
+	// by namespace. It's empty for the shard of cluster-scoped items.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Group is the API group whose resources' items are in the shard, for backups
+	// sharded by resource group. It's empty for the core group.
+	Group string `json:"group,omitempty"`
+
+	// Items is the number of items in the shard.
+	Items int `json:"items"`
+}
+
+// coreGroupName names the shard of the core API group's items, which has no group name.
+const coreGroupName = "core"
+
+// ForPath returns the shard that the file at path in a backup's contents belongs in.
+func ForPath(path string, by api.BackupShardBy) Shard {
+	var (
+		shard Shard
+		parts = strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	)
+
+	// clusters/<cluster>/resources/...
+	if len(parts) > 2 && parts[0] == api.ClustersDir {
+		shard.Cluster = parts[1]
+		parts = parts[2:]
+	}
+
+	// resources/<resource>.<group>/<cluster|namespaces>/[<namespace>/]<item>
+	switch by {
+	case api.BackupShardByNamespace:
+		if len(parts) > 4 && parts[0] == api.ResourcesDir && parts[2] == api.NamespaceScopedDir {
+			shard.Namespace = parts[3]
+			shard.Name = api.NamespaceScopedDir + "." + shard.Namespace
+		} else {
+			shard.Name = api.ClusterScopedDir
+		}
+	case api.BackupShardByResourceGroup:
+		if len(parts) > 1 && parts[0] == api.ResourcesDir {
+			if i := strings.Index(parts[1], "."); i >= 0 {
+				shard.Group = parts[1][i+1:]
+			}
+		}
+
+		groupName := shard.Group
+		if groupName == "" {
+			groupName = coreGroupName
+		}
+		shard.Name = "groups." + groupName
+	}
+
+	// cluster names can't contain dots, so shard names are unique
+	if shard.Cluster != "" {
+		shard.Name = api.ClustersDir + "." + shard.Cluster + "." + shard.Name
+	}
+
+	return shard
+}
+
+// file is a temp file that a shard's tarball is being written to.
+type file struct {
+	shard Shard
+	file  *os.File
+	gzw   *gzip.Writer
+	tw    *tar.Writer
+}
+
+func (f *file) close() error {
+	if err := f.tw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := f.gzw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Split reads a backup's contents, as a gzipped tarball, from r, and writes the files in
+// each of its shards to a separate gzipped tarball in a temp file. It returns the backup's
+// manifest and the temp files, keyed by shard name, which the caller must remove.
+func Split(r io.Reader, by api.BackupShardBy) (*Manifest, map[string]*os.File, error) {
+	files := make(map[string]*file)
+
+	// on error, remove any temp files that were created
+	removeFiles := func() {
+		for _, f := range files {
+			f.file.Close()
+			os.Remove(f.file.Name())
+		}
+	}
+
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			removeFiles()
+			return nil, nil, errors.WithStack(err)
+		}
+
+		shard := ForPath(header.Name, by)
+
+		f, ok := files[shard.Name]
+		if !ok {
+			tempFile, err := ioutil.TempFile("", "")
+			if err != nil {
+				removeFiles()
+				return nil, nil, errors.WithStack(err)
+			}
+
+			gzw := gzip.NewWriter(tempFile)
+			f = &file{shard: shard, file: tempFile, gzw: gzw, tw: tar.NewWriter(gzw)}
+			files[shard.Name] = f
+		}
+
+		if err := f.tw.WriteHeader(header); err != nil {
+			removeFiles()
+			return nil, nil, errors.WithStack(err)
+		}
+		if _, err := io.Copy(f.tw, tr); err != nil {
+			removeFiles()
+			return nil, nil, errors.WithStack(err)
+		}
+
+		if header.Typeflag == tar.TypeReg {
+			f.shard.Items++
+		}
+	}
+
+	manifest := &Manifest{Shards: []Shard{}}
+	res := make(map[string]*os.File, len(files))
+
+	for name, f := range files {
+		if err := f.close(); err != nil {
+			removeFiles()
+			return nil, nil, err
+		}
+
+		manifest.Shards = append(manifest.Shards, f.shard)
+		res[name] = f.file
+	}
+
+	sort.Slice(manifest.Shards, func(i, j int) bool {
+		return manifest.Shards[i].Name < manifest.Shards[j].Name
+	})
+
+	return manifest, res, nil
+}
+
+// Merge writes a gzipped tarball containing the files in each of shards to w. The shards'
+// gzipped tarballs are opened, and read, one at a time.
+func Merge(w io.Writer, shards []Shard, open func(Shard) (io.ReadCloser, error)) error {
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+
+	for _, shard := range shards {
+		if err := copyShard(tw, shard, open); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(gzw.Close())
+}
+
+func copyShard(tw *tar.Writer, shard Shard, open func(Shard) (io.ReadCloser, error)) error {
+	rc, err := open(shard)
+	if err != nil {
+		return errors.WithMessage(err, "error opening shard "+shard.Name)
+	}
+	defer rc.Close()
+
+	gzr, err := gzip.NewReader(rc)
+	if err != nil {
+		return errors.Wrapf(err, "error reading shard %s", shard.Name)
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error reading shard %s", shard.Name)
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return errors.WithStack(err)
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return errors.Wrapf(err, "error reading shard %s", shard.Name)
+		}
+	}
+}
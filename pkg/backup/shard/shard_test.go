@@ -0,0 +1,184 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shard
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+func TestForPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		by       api.BackupShardBy
+		expected Shard
+	}{
+		{
+			path:     "resources/pods/namespaces/ns-1/pod-1.json",
+			by:       api.BackupShardByNamespace,
+			expected: Shard{Name: "namespaces.ns-1", Namespace: "ns-1"},
+		},
+		{
+			path:     "resources/persistentvolumes/cluster/pv-1.json",
+			by:       api.BackupShardByNamespace,
+			expected: Shard{Name: "cluster"},
+		},
+		{
+			path:     "clusters/other/resources/pods/namespaces/ns-1/pod-1.json",
+			by:       api.BackupShardByNamespace,
+			expected: Shard{Name: "clusters.other.namespaces.ns-1", Cluster: "other", Namespace: "ns-1"},
+		},
+		{
+			path:     "resources/deployments.apps/namespaces/ns-1/deploy-1.json",
+			by:       api.BackupShardByResourceGroup,
+			expected: Shard{Name: "groups.apps", Group: "apps"},
+		},
+		{
+			path:     "resources/storageclasses.storage.k8s.io/cluster/sc-1.json",
+			by:       api.BackupShardByResourceGroup,
+			expected: Shard{Name: "groups.storage.k8s.io", Group: "storage.k8s.io"},
+		},
+		{
+			path:     "resources/pods/namespaces/ns-1/pod-1.json",
+			by:       api.BackupShardByResourceGroup,
+			expected: Shard{Name: "groups.core"},
+		},
+		{
+			path:     "clusters/other/resources/pods/namespaces/ns-1/pod-1.json",
+			by:       api.BackupShardByResourceGroup,
+			expected: Shard{Name: "clusters.other.groups.core", Cluster: "other"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.by)+" "+test.path, func(t *testing.T) {
+			assert.Equal(t, test.expected, ForPath(test.path, test.by))
+		})
+	}
+}
+
+// newTarball returns a gzipped tarball containing a file at each of paths, whose
+// contents are its path.
+func newTarball(t *testing.T, paths ...string) []byte {
+	buf := new(bytes.Buffer)
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+
+	for _, path := range paths {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: path, Size: int64(len(path)), Typeflag: tar.TypeReg, Mode: 0644}))
+		_, err := tw.Write([]byte(path))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+
+	return buf.Bytes()
+}
+
+// readTarball returns the contents of each file in the gzipped tarball read from r,
+// in order.
+func readTarball(t *testing.T, r io.Reader) []string {
+	gzr, err := gzip.NewReader(r)
+	require.NoError(t, err)
+
+	var res []string
+	tr := tar.NewReader(gzr)
+	for {
+		_, err := tr.Next()
+		if err == io.EOF {
+			return res
+		}
+		require.NoError(t, err)
+
+		data, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		res = append(res, string(data))
+	}
+}
+
+func TestSplitAndMerge(t *testing.T) {
+	tarball := newTarball(t,
+		"resources/namespaces/cluster/ns-1.json",
+		"resources/pods/namespaces/ns-1/pod-1.json",
+		"resources/pods/namespaces/ns-2/pod-2.json",
+		"resources/pods/namespaces/ns-1/pod-3.json",
+	)
+
+	manifest, files, err := Split(bytes.NewReader(tarball), api.BackupShardByNamespace)
+	require.NoError(t, err)
+	defer func() {
+		for _, file := range files {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	assert.Equal(t, []Shard{
+		{Name: "cluster", Items: 1},
+		{Name: "namespaces.ns-1", Namespace: "ns-1", Items: 2},
+		{Name: "namespaces.ns-2", Namespace: "ns-2", Items: 1},
+	}, manifest.Shards)
+	require.Len(t, files, 3)
+
+	for _, file := range files {
+		_, err := file.Seek(0, 0)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{
+		"resources/pods/namespaces/ns-1/pod-1.json",
+		"resources/pods/namespaces/ns-1/pod-3.json",
+	}, readTarball(t, files["namespaces.ns-1"]))
+
+	for _, file := range files {
+		_, err := file.Seek(0, 0)
+		require.NoError(t, err)
+	}
+	merged := new(bytes.Buffer)
+	err = Merge(merged, manifest.Shards, func(s Shard) (io.ReadCloser, error) {
+		return ioutil.NopCloser(files[s.Name]), nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"resources/namespaces/cluster/ns-1.json",
+		"resources/pods/namespaces/ns-1/pod-1.json",
+		"resources/pods/namespaces/ns-1/pod-3.json",
+		"resources/pods/namespaces/ns-2/pod-2.json",
+	}, readTarball(t, merged))
+}
+
+func TestSplitEmptyBackup(t *testing.T) {
+	manifest, files, err := Split(bytes.NewReader(newTarball(t)), api.BackupShardByNamespace)
+	require.NoError(t, err)
+	assert.Empty(t, manifest.Shards)
+	assert.Empty(t, files)
+
+	merged := new(bytes.Buffer)
+	require.NoError(t, Merge(merged, manifest.Shards, nil))
+	assert.Empty(t, readTarball(t, merged))
+}
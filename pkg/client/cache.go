@@ -0,0 +1,78 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// cachingDynamicFactory implements DynamicFactory, returning the same Dynamic client for
+// repeated requests for a resource in a namespace.
+type cachingDynamicFactory struct {
+	delegate DynamicFactory
+	clients  *lru.Cache
+}
+
+// dynamicClientKey identifies the clients in a cachingDynamicFactory's cache.
+type dynamicClientKey struct {
+	gv         schema.GroupVersion
+	resource   string
+	namespaced bool
+	namespace  string
+}
+
+// NewCachingDynamicFactory returns a DynamicFactory that keeps the size most recently used
+// clients created by delegate, keyed by group, version, resource and namespace, and returns
+// them again rather than creating new ones. It's safe to share between goroutines, such as
+// the backupper and restorer's.
+func NewCachingDynamicFactory(delegate DynamicFactory, size int) (DynamicFactory, error) {
+	clients, err := lru.New(size)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating dynamic client cache")
+	}
+
+	return &cachingDynamicFactory{
+		delegate: delegate,
+		clients:  clients,
+	}, nil
+}
+
+func (f *cachingDynamicFactory) ClientForGroupVersionResource(gv schema.GroupVersion, resource metav1.APIResource, namespace string) (Dynamic, error) {
+	key := dynamicClientKey{
+		gv:         gv,
+		resource:   resource.Name,
+		namespaced: resource.Namespaced,
+		namespace:  namespace,
+	}
+
+	if client, ok := f.clients.Get(key); ok {
+		return client.(Dynamic), nil
+	}
+
+	client, err := f.delegate.ClientForGroupVersionResource(gv, resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	f.clients.Add(key, client)
+
+	return client, nil
+}
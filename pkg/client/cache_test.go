@@ -0,0 +1,96 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// countingDynamicFactory returns a new Dynamic for each call, counting the calls.
+type countingDynamicFactory struct {
+	calls int
+	err   error
+}
+
+func (f *countingDynamicFactory) ClientForGroupVersionResource(gv schema.GroupVersion, resource metav1.APIResource, namespace string) (Dynamic, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &fakeDynamic{}, nil
+}
+
+func TestCachingDynamicFactory(t *testing.T) {
+	var (
+		delegate = &countingDynamicFactory{}
+		v1       = schema.GroupVersion{Version: "v1"}
+		pods     = metav1.APIResource{Name: "pods", Namespaced: true}
+		secrets  = metav1.APIResource{Name: "secrets", Namespaced: true}
+	)
+
+	factory, err := NewCachingDynamicFactory(delegate, 2)
+	require.NoError(t, err)
+
+	get := func(resource metav1.APIResource, namespace string) Dynamic {
+		client, err := factory.ClientForGroupVersionResource(v1, resource, namespace)
+		require.NoError(t, err)
+		return client
+	}
+
+	podsNS1 := get(pods, "ns-1")
+	assert.True(t, podsNS1 == get(pods, "ns-1"), "expected the cached client")
+	assert.Equal(t, 1, delegate.calls)
+
+	// a different namespace or resource gets its own client
+	podsNS2 := get(pods, "ns-2")
+	assert.False(t, podsNS1 == podsNS2, "expected a new client for a different namespace")
+	assert.Equal(t, 2, delegate.calls)
+
+	// adding a third client evicts the least recently used, pods in ns-2
+	get(pods, "ns-1")
+	get(secrets, "ns-1")
+	assert.Equal(t, 3, delegate.calls)
+
+	get(pods, "ns-1")
+	assert.Equal(t, 3, delegate.calls)
+
+	assert.False(t, podsNS2 == get(pods, "ns-2"), "expected the evicted client to be recreated")
+	assert.Equal(t, 4, delegate.calls)
+}
+
+func TestCachingDynamicFactoryErrors(t *testing.T) {
+	_, err := NewCachingDynamicFactory(&countingDynamicFactory{}, 0)
+	assert.Error(t, err)
+
+	delegate := &countingDynamicFactory{err: errors.New("no client")}
+	factory, err := NewCachingDynamicFactory(delegate, 10)
+	require.NoError(t, err)
+
+	// errors aren't cached
+	for i := 0; i < 2; i++ {
+		_, err = factory.ClientForGroupVersionResource(schema.GroupVersion{Version: "v1"}, metav1.APIResource{Name: "pods"}, "ns")
+		assert.EqualError(t, err, "no client")
+	}
+	assert.Equal(t, 2, delegate.calls)
+}
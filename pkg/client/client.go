@@ -24,12 +24,14 @@ import (
 
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/heptio/ark/pkg/buildinfo"
 )
 
 // Config returns a *rest.Config, using either the kubeconfig (if specified) or an in-cluster
-// configuration.
+// configuration. If kubecontext is specified, it's used instead of the kubeconfig's current
+// context.
 func Config(kubeconfig, kubecontext, baseName string) (*rest.Config, error) {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
 	loadingRules.ExplicitPath = kubeconfig
@@ -51,6 +53,65 @@ func Config(kubeconfig, kubecontext, baseName string) (*rest.Config, error) {
 	return clientConfig, nil
 }
 
+// ConfigFromKubeconfig returns a *rest.Config for the given context (or the
+// current context, if kubecontext is empty) of the provided kubeconfig contents.
+func ConfigFromKubeconfig(kubeconfig []byte, kubecontext, baseName string) (*rest.Config, error) {
+	rawConfig, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	kubeConfig := clientcmd.NewNonInteractiveClientConfig(*rawConfig, kubecontext, &clientcmd.ConfigOverrides{}, nil)
+	clientConfig, err := kubeConfig.ClientConfig()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	clientConfig.UserAgent = buildUserAgent(
+		baseName,
+		buildinfo.Version,
+		buildinfo.FormattedGitSHA(),
+		runtime.GOOS,
+		runtime.GOARCH,
+	)
+
+	return clientConfig, nil
+}
+
+// Username returns the name of the user that the kubeconfig's given context (or its current
+// context, if kubecontext is empty) authenticates as: the user's basic auth username if it has
+// one, or else the name of its entry in the kubeconfig. It returns an empty string if there's no
+// kubeconfig or it has no such context, e.g. when using in-cluster configuration. The name comes
+// from the local kubeconfig, not from the API server, so it isn't a verified identity.
+func Username(kubeconfig, kubecontext string) string {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+
+	rawConfig, err := loadingRules.Load()
+	if err != nil {
+		return ""
+	}
+
+	return usernameFromConfig(rawConfig, kubecontext)
+}
+
+func usernameFromConfig(config *clientcmdapi.Config, kubecontext string) string {
+	if kubecontext == "" {
+		kubecontext = config.CurrentContext
+	}
+
+	context, ok := config.Contexts[kubecontext]
+	if !ok {
+		return ""
+	}
+
+	if authInfo, ok := config.AuthInfos[context.AuthInfo]; ok && authInfo.Username != "" {
+		return authInfo.Username
+	}
+
+	return context.AuthInfo
+}
+
 // buildUserAgent builds a User-Agent string from given args.
 func buildUserAgent(command, version, formattedSha, os, arch string) string {
 	return fmt.Sprintf(
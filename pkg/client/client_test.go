@@ -1,9 +1,18 @@
 package client
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
 )
 
 func TestBuildUserAgent(t *testing.T) {
@@ -34,3 +43,186 @@ func TestBuildUserAgent(t *testing.T) {
 		})
 	}
 }
+
+const testKubeconfig = `
+apiVersion: v1
+kind: Config
+current-context: a
+clusters:
+- name: a
+  cluster:
+    server: https://a.example.com
+- name: b
+  cluster:
+    server: https://b.example.com
+users:
+- name: user
+  user:
+    token: abc123
+contexts:
+- name: a
+  context:
+    cluster: a
+    user: user
+- name: b
+  context:
+    cluster: b
+    user: user
+`
+
+func TestConfigFromKubeconfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		kubeconfig     string
+		context        string
+		expectedHost   string
+		expectedErrors bool
+	}{
+		{
+			name:         "empty context uses the current context",
+			kubeconfig:   testKubeconfig,
+			expectedHost: "https://a.example.com",
+		},
+		{
+			name:         "explicit context is used",
+			kubeconfig:   testKubeconfig,
+			context:      "b",
+			expectedHost: "https://b.example.com",
+		},
+		{
+			name:           "unknown context returns an error",
+			kubeconfig:     testKubeconfig,
+			context:        "c",
+			expectedErrors: true,
+		},
+		{
+			name:           "invalid kubeconfig returns an error",
+			kubeconfig:     "{",
+			expectedErrors: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, err := ConfigFromKubeconfig([]byte(test.kubeconfig), test.context, "ark")
+			if test.expectedErrors {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedHost, config.Host)
+			assert.Equal(t, "abc123", config.BearerToken)
+		})
+	}
+}
+
+func TestUsernameFromConfig(t *testing.T) {
+	config, err := clientcmd.Load([]byte(testKubeconfig + `
+- name: c
+  context:
+    cluster: a
+    user: basic-auth
+`))
+	require.NoError(t, err)
+	config.AuthInfos["basic-auth"] = &clientcmdapi.AuthInfo{Username: "admin", Password: "secret"}
+
+	assert.Equal(t, "user", usernameFromConfig(config, ""))
+	assert.Equal(t, "user", usernameFromConfig(config, "b"))
+	assert.Equal(t, "admin", usernameFromConfig(config, "c"))
+	assert.Equal(t, "", usernameFromConfig(config, "missing"))
+}
+
+func TestFactoryKubeconfigFlags(t *testing.T) {
+	kubeconfig, err := ioutil.TempFile("", "kubeconfig")
+	require.NoError(t, err)
+	defer os.Remove(kubeconfig.Name())
+
+	_, err = kubeconfig.WriteString(testKubeconfig)
+	require.NoError(t, err)
+	require.NoError(t, kubeconfig.Close())
+
+	tests := []struct {
+		name         string
+		args         []string
+		expectedHost string
+	}{
+		{
+			name:         "no context uses the current context",
+			args:         []string{"--kubeconfig", kubeconfig.Name()},
+			expectedHost: "https://a.example.com",
+		},
+		{
+			name:         "--kubecontext is used",
+			args:         []string{"--kubeconfig", kubeconfig.Name(), "--kubecontext", "b"},
+			expectedHost: "https://b.example.com",
+		},
+		{
+			name:         "--context is used",
+			args:         []string{"--kubeconfig", kubeconfig.Name(), "--context", "b"},
+			expectedHost: "https://b.example.com",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := NewFactory("ark").(*factory)
+
+			flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+			f.BindFlags(flags)
+			require.NoError(t, flags.Parse(test.args))
+
+			config, err := Config(f.kubeconfig, f.kubecontext, f.baseName)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedHost, config.Host)
+		})
+	}
+}
+
+func TestFactoryNamespace(t *testing.T) {
+	tests := []struct {
+		name               string
+		args               []string
+		inClusterNamespace bool
+		expected           string
+	}{
+		{
+			name:     "--namespace is used",
+			args:     []string{"--namespace", "foo"},
+			expected: "foo",
+		},
+		{
+			name:               "--namespace is used with the in-cluster namespace",
+			args:               []string{"--namespace", "foo"},
+			inClusterNamespace: true,
+			expected:           "foo",
+		},
+		{
+			name:               "the in-cluster namespace defaults to the default namespace outside a pod",
+			inClusterNamespace: true,
+			expected:           v1.DefaultNamespace,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := NewFactory("ark")
+
+			flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+			f.BindFlags(flags)
+			require.NoError(t, flags.Parse(test.args))
+
+			if test.inClusterNamespace {
+				f.UseInClusterNamespace()
+			}
+
+			assert.Equal(t, test.expected, f.Namespace())
+		})
+	}
+}
+
+func TestFactorySetBasename(t *testing.T) {
+	f := NewFactory("ark")
+	f.SetBasename("ark-server")
+
+	assert.Equal(t, "ark-server", f.(*factory).baseName)
+}
@@ -18,12 +18,16 @@ package client
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
 
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
 	clientset "github.com/heptio/ark/pkg/generated/clientset/versioned"
@@ -31,7 +35,7 @@ import (
 
 // Factory knows how to create an ArkClient and Kubernetes client.
 type Factory interface {
-	// BindFlags binds common flags (--kubeconfig, --namespace) to the passed-in FlagSet.
+	// BindFlags binds common flags (--kubeconfig, --kubecontext/--context, --namespace) to the passed-in FlagSet.
 	BindFlags(flags *pflag.FlagSet)
 	// Client returns an ArkClient. It uses the following priority to specify the cluster
 	// configuration: --kubeconfig flag, KUBECONFIG environment variable, in-cluster configuration.
@@ -39,15 +43,45 @@ type Factory interface {
 	// KubeClient returns a Kubernetes client. It uses the following priority to specify the cluster
 	// configuration: --kubeconfig flag, KUBECONFIG environment variable, in-cluster configuration.
 	KubeClient() (kubernetes.Interface, error)
+	// DynamicFactory returns a DynamicFactory for working with arbitrary Kubernetes resources. It
+	// uses the same cluster configuration as KubeClient.
+	DynamicFactory() (DynamicFactory, error)
+	// ClientForContext returns an ArkClient for the given kubeconfig context, rather than the
+	// one specified by the --kubecontext flag.
+	ClientForContext(kubecontext string) (clientset.Interface, error)
+	// ClientConfig returns the rest.Config the Factory's clients are created with.
+	ClientConfig() (*rest.Config, error)
+	// Username returns the name of the kubeconfig user the Factory's clients authenticate as,
+	// or an empty string if it can't be determined. See client.Username.
+	Username() string
+	// Namespace returns the namespace Ark operates in: the --namespace flag, or the namespace from
+	// the client config file, or the default namespace. See also UseInClusterNamespace.
 	Namespace() string
+	// SetBasename changes the basename used to construct the user-agent of the clients the Factory
+	// creates. This is useful for commands below the root ark command that need a different
+	// user-agent, such as the server.
+	SetBasename(baseName string)
+	// SetClientQPS sets the maximum number of requests per second the Factory's clients make
+	// once their burst is used up. If it's not set, client-go's default is used.
+	SetClientQPS(qps float32)
+	// SetClientBurst sets the maximum number of requests the Factory's clients make in a burst.
+	// If it's not set, client-go's default is used.
+	SetClientBurst(burst int)
+	// UseInClusterNamespace makes Namespace ignore the client config file and, unless the
+	// --namespace flag is set, return the namespace of the pod the process is running in, falling
+	// back to the default namespace.
+	UseInClusterNamespace()
 }
 
 type factory struct {
-	flags       *pflag.FlagSet
-	kubeconfig  string
-	kubecontext string
-	baseName    string
-	namespace   string
+	flags              *pflag.FlagSet
+	kubeconfig         string
+	kubecontext        string
+	baseName           string
+	namespace          string
+	inClusterNamespace bool
+	clientQPS          float32
+	clientBurst        int
 }
 
 // NewFactory returns a Factory.
@@ -70,6 +104,8 @@ func NewFactory(baseName string) Factory {
 	f.flags.StringVar(&f.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file to use to talk to the Kubernetes apiserver. If unset, try the environment variable KUBECONFIG, as well as in-cluster configuration")
 	f.flags.StringVarP(&f.namespace, "namespace", "n", f.namespace, "The namespace in which Ark should operate")
 	f.flags.StringVar(&f.kubecontext, "kubecontext", "", "The context to use to talk to the Kubernetes apiserver. If unset defaults to whatever your current-context is (kubectl config current-context)")
+	// --context matches kubectl's name for the same flag
+	f.flags.StringVar(&f.kubecontext, "context", "", "The context to use to talk to the Kubernetes apiserver. Same as --kubecontext")
 
 	return f
 }
@@ -78,12 +114,45 @@ func (f *factory) BindFlags(flags *pflag.FlagSet) {
 	flags.AddFlagSet(f.flags)
 }
 
-func (f *factory) Client() (clientset.Interface, error) {
+func (f *factory) ClientConfig() (*rest.Config, error) {
 	clientConfig, err := Config(f.kubeconfig, f.kubecontext, f.baseName)
 	if err != nil {
 		return nil, err
 	}
 
+	if f.clientQPS > 0 {
+		clientConfig.QPS = f.clientQPS
+	}
+	if f.clientBurst > 0 {
+		clientConfig.Burst = f.clientBurst
+	}
+
+	return clientConfig, nil
+}
+
+func (f *factory) Username() string {
+	return Username(f.kubeconfig, f.kubecontext)
+}
+
+func (f *factory) Client() (clientset.Interface, error) {
+	clientConfig, err := f.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	arkClient, err := clientset.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return arkClient, nil
+}
+
+func (f *factory) ClientForContext(kubecontext string) (clientset.Interface, error) {
+	clientConfig, err := Config(f.kubeconfig, kubecontext, f.baseName)
+	if err != nil {
+		return nil, err
+	}
+
 	arkClient, err := clientset.NewForConfig(clientConfig)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -92,7 +161,7 @@ func (f *factory) Client() (clientset.Interface, error) {
 }
 
 func (f *factory) KubeClient() (kubernetes.Interface, error) {
-	clientConfig, err := Config(f.kubeconfig, f.kubecontext, f.baseName)
+	clientConfig, err := f.ClientConfig()
 	if err != nil {
 		return nil, err
 	}
@@ -104,6 +173,46 @@ func (f *factory) KubeClient() (kubernetes.Interface, error) {
 	return kubeClient, nil
 }
 
+func (f *factory) DynamicFactory() (DynamicFactory, error) {
+	clientConfig, err := f.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDynamicFactory(dynamic.NewDynamicClientPool(clientConfig)), nil
+}
+
 func (f *factory) Namespace() string {
+	if f.inClusterNamespace && !f.flags.Changed("namespace") {
+		return inClusterNamespace()
+	}
 	return f.namespace
 }
+
+func (f *factory) SetBasename(baseName string) {
+	f.baseName = baseName
+}
+
+func (f *factory) SetClientQPS(qps float32) {
+	f.clientQPS = qps
+}
+
+func (f *factory) SetClientBurst(burst int) {
+	f.clientBurst = burst
+}
+
+func (f *factory) UseInClusterNamespace() {
+	f.inClusterNamespace = true
+}
+
+// inClusterNamespace returns the namespace of the service account the process is running
+// as, or the default namespace if it isn't running in a pod.
+func inClusterNamespace() string {
+	if data, err := ioutil.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace"); err == nil {
+		if ns := strings.TrimSpace(string(data)); len(ns) > 0 {
+			return ns
+		}
+	}
+
+	return v1.DefaultNamespace
+}
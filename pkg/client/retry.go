@@ -0,0 +1,211 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// retryBackoff is how long a retryingDynamic waits before its first retry of a call. The
+// wait doubles for each subsequent retry.
+var retryBackoff = time.Second
+
+// retryingDynamicFactory implements DynamicFactory, returning Dynamic clients that bound
+// and retry their calls.
+type retryingDynamicFactory struct {
+	delegate DynamicFactory
+	timeout  time.Duration
+	retries  int
+}
+
+// NewRetryingDynamicFactory returns a DynamicFactory whose clients give up on each Create,
+// Get, List and Update call that takes longer than timeout, and retry Get and List calls
+// that time out or fail with a transient error up to retries more times, backing off
+// between attempts. Creates and Updates aren't retried since they might have been applied
+// before failing, and Watches aren't bounded. A timeout of 0 means calls aren't bounded.
+func NewRetryingDynamicFactory(delegate DynamicFactory, timeout time.Duration, retries int) DynamicFactory {
+	return &retryingDynamicFactory{
+		delegate: delegate,
+		timeout:  timeout,
+		retries:  retries,
+	}
+}
+
+func (f *retryingDynamicFactory) ClientForGroupVersionResource(gv schema.GroupVersion, resource metav1.APIResource, namespace string) (Dynamic, error) {
+	client, err := f.delegate.ClientForGroupVersionResource(gv, resource, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &retryingDynamic{
+		delegate: client,
+		timeout:  f.timeout,
+		retries:  f.retries,
+	}, nil
+}
+
+// retryingDynamic implements Dynamic.
+type retryingDynamic struct {
+	delegate Dynamic
+	timeout  time.Duration
+	retries  int
+}
+
+var _ Dynamic = &retryingDynamic{}
+
+func (d *retryingDynamic) Create(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	res, err := d.callWithTimeout(func() (runtime.Object, error) {
+		return d.delegate.Create(obj)
+	})
+	return asUnstructured(res, err)
+}
+
+func (d *retryingDynamic) List(options metav1.ListOptions) (runtime.Object, error) {
+	return d.callWithRetries(func() (runtime.Object, error) {
+		return d.delegate.List(options)
+	})
+}
+
+func (d *retryingDynamic) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	return d.delegate.Watch(options)
+}
+
+func (d *retryingDynamic) Get(name string, opts metav1.GetOptions) (*unstructured.Unstructured, error) {
+	res, err := d.callWithRetries(func() (runtime.Object, error) {
+		return d.delegate.Get(name, opts)
+	})
+	return asUnstructured(res, err)
+}
+
+func (d *retryingDynamic) Update(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	res, err := d.callWithTimeout(func() (runtime.Object, error) {
+		return d.delegate.Update(obj)
+	})
+	return asUnstructured(res, err)
+}
+
+// callWithRetries makes call, retrying it if it fails with an error that isRetriable.
+func (d *retryingDynamic) callWithRetries(call func() (runtime.Object, error)) (runtime.Object, error) {
+	var (
+		res      runtime.Object
+		err      error
+		attempts int
+	)
+
+	backoff := wait.Backoff{
+		Duration: retryBackoff,
+		Factor:   2,
+		Steps:    d.retries + 1,
+	}
+
+	waitErr := wait.ExponentialBackoff(backoff, func() (bool, error) {
+		attempts++
+		res, err = d.callWithTimeout(call)
+		if err != nil && isRetriable(err) {
+			return false, nil
+		}
+		return true, nil
+	})
+	if waitErr == wait.ErrWaitTimeout && attempts > 1 {
+		return nil, errors.Wrapf(err, "giving up after %d attempts", attempts)
+	}
+
+	return res, err
+}
+
+type callResult struct {
+	obj runtime.Object
+	err error
+}
+
+// callWithTimeout makes call, returning an error if it doesn't return within the timeout.
+// The call can't be cancelled, so it carries on in the background and its result is
+// discarded.
+func (d *retryingDynamic) callWithTimeout(call func() (runtime.Object, error)) (runtime.Object, error) {
+	if d.timeout <= 0 {
+		return call()
+	}
+
+	// buffered so the call's goroutine can exit if it returns after the timeout
+	results := make(chan callResult, 1)
+	go func() {
+		obj, err := call()
+		results <- callResult{obj: obj, err: err}
+	}()
+
+	timer := time.NewTimer(d.timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-results:
+		return res.obj, res.err
+	case <-timer.C:
+		return nil, errCallTimedOut{timeout: d.timeout}
+	}
+}
+
+// errCallTimedOut is returned by calls that don't return within the timeout.
+type errCallTimedOut struct {
+	timeout time.Duration
+}
+
+func (e errCallTimedOut) Error() string {
+	return "call timed out after " + e.timeout.String()
+}
+
+// isRetriable returns whether a call that failed with err might succeed if it's made again:
+// if it timed out, failed before reaching the API server, or the API server reports a
+// transient problem.
+func isRetriable(err error) bool {
+	if _, ok := err.(errCallTimedOut); ok {
+		return true
+	}
+
+	if _, ok := err.(apierrors.APIStatus); !ok {
+		// the error didn't come from the API server, e.g. the connection was refused or reset
+		return true
+	}
+
+	return apierrors.IsTimeout(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err) ||
+		apierrors.IsUnexpectedServerError(err)
+}
+
+func asUnstructured(obj runtime.Object, err error) (*unstructured.Unstructured, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	res, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, errors.Errorf("unexpected type %T", obj)
+	}
+	return res, nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// fakeDynamic is a Dynamic whose Create, Get and List calls return the next of errs,
+// succeeding once errs runs out, after waiting for delay.
+type fakeDynamic struct {
+	lock  sync.Mutex
+	errs  []error
+	delay time.Duration
+	calls int
+}
+
+func (d *fakeDynamic) call() error {
+	time.Sleep(d.delay)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.calls++
+	if len(d.errs) == 0 {
+		return nil
+	}
+	err := d.errs[0]
+	d.errs = d.errs[1:]
+	return err
+}
+
+func (d *fakeDynamic) callCount() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.calls
+}
+
+func (d *fakeDynamic) Create(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if err := d.call(); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (d *fakeDynamic) List(options metav1.ListOptions) (runtime.Object, error) {
+	if err := d.call(); err != nil {
+		return nil, err
+	}
+	return &unstructured.UnstructuredList{}, nil
+}
+
+func (d *fakeDynamic) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	return nil, nil
+}
+
+func (d *fakeDynamic) Get(name string, opts metav1.GetOptions) (*unstructured.Unstructured, error) {
+	if err := d.call(); err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{}, nil
+}
+
+func (d *fakeDynamic) Update(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return obj, nil
+}
+
+type fakeDynamicFactory struct {
+	client Dynamic
+}
+
+func (f *fakeDynamicFactory) ClientForGroupVersionResource(gv schema.GroupVersion, resource metav1.APIResource, namespace string) (Dynamic, error) {
+	return f.client, nil
+}
+
+func TestRetryingDynamic(t *testing.T) {
+	defer func(backoff time.Duration) { retryBackoff = backoff }(retryBackoff)
+	retryBackoff = time.Millisecond
+
+	gr := schema.GroupResource{Resource: "pods"}
+
+	tests := []struct {
+		name          string
+		errs          []error
+		delay         time.Duration
+		timeout       time.Duration
+		expectedCalls int
+		expectedErr   bool
+	}{
+		{
+			name:          "no error",
+			expectedCalls: 1,
+		},
+		{
+			name:          "retriable errors are retried",
+			errs:          []error{apierrors.NewServerTimeout(gr, "list", 1), errors.New("connection reset")},
+			expectedCalls: 3,
+		},
+		{
+			name:          "non-retriable errors aren't retried",
+			errs:          []error{apierrors.NewNotFound(gr, "pod")},
+			expectedCalls: 1,
+			expectedErr:   true,
+		},
+		{
+			name:          "retries are bounded",
+			errs:          []error{apierrors.NewTooManyRequests("slow down", 1), apierrors.NewTooManyRequests("slow down", 1), apierrors.NewTooManyRequests("slow down", 1)},
+			expectedCalls: 3,
+			expectedErr:   true,
+		},
+		{
+			name:          "slow calls time out",
+			delay:         50 * time.Millisecond,
+			timeout:       time.Millisecond,
+			expectedCalls: 3,
+			expectedErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeDynamic{errs: test.errs, delay: test.delay}
+			factory := NewRetryingDynamicFactory(&fakeDynamicFactory{client: fake}, test.timeout, 2)
+
+			client, err := factory.ClientForGroupVersionResource(schema.GroupVersion{Version: "v1"}, metav1.APIResource{Name: "pods"}, "ns")
+			require.NoError(t, err)
+
+			_, err = client.List(metav1.ListOptions{})
+			assert.Equal(t, test.expectedErr, err != nil)
+
+			// wait for any calls that timed out to finish before checking the count
+			time.Sleep(2 * test.delay)
+			assert.Equal(t, test.expectedCalls, fake.callCount())
+		})
+	}
+}
+
+func TestRetryingDynamicDoesNotRetryCreate(t *testing.T) {
+	fake := &fakeDynamic{errs: []error{errors.New("connection reset")}}
+	client := &retryingDynamic{delegate: fake, retries: 2}
+
+	_, err := client.Create(&unstructured.Unstructured{})
+	assert.Error(t, err)
+	assert.Equal(t, 1, fake.callCount())
+}
@@ -17,7 +17,11 @@ limitations under the License.
 package aws
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -27,11 +31,15 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/util/collections"
 )
 
-const regionKey = "region"
+const (
+	regionKey    = "region"
+	encryptedKey = "encrypted"
+)
 
 // iopsVolumeTypes is a set of AWS EBS volume types for which IOPS should
 // be captured during snapshot and provided when creating a new volume
@@ -39,7 +47,14 @@ const regionKey = "region"
 var iopsVolumeTypes = sets.NewString("io1")
 
 type blockStore struct {
-	ec2 *ec2.EC2
+	ec2     *ec2.EC2
+	session *session.Session
+	region  string
+
+	// encrypted and kmsKeyID control the encryption of volumes created
+	// from snapshots, regardless of whether the snapshot is encrypted.
+	encrypted bool
+	kmsKeyID  string
 }
 
 func getSession(config *aws.Config) (*session.Session, error) {
@@ -65,7 +80,22 @@ func (b *blockStore) Init(config map[string]string) error {
 		return errors.Errorf("missing %s in aws configuration", regionKey)
 	}
 
-	awsConfig := aws.NewConfig().WithRegion(region)
+	encrypted, kmsKeyID, err := getEncryptionConfig(config)
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := cloudprovider.NewHTTPClient(config)
+	if err != nil {
+		return err
+	}
+
+	creds, err := getCredentials(config, httpClient)
+	if err != nil {
+		return err
+	}
+
+	awsConfig := aws.NewConfig().WithRegion(region).WithCredentials(creds).WithHTTPClient(httpClient)
 
 	sess, err := getSession(awsConfig)
 	if err != nil {
@@ -73,10 +103,57 @@ func (b *blockStore) Init(config map[string]string) error {
 	}
 
 	b.ec2 = ec2.New(sess)
+	b.session = sess
+	b.region = region
+	b.encrypted = encrypted
+	b.kmsKeyID = kmsKeyID
 
 	return nil
 }
 
+// getEncryptionConfig returns whether volumes created from snapshots should be encrypted,
+// and with which KMS key. Specifying a KMS key implies encryption.
+func getEncryptionConfig(config map[string]string) (bool, string, error) {
+	var encrypted bool
+	if val := config[encryptedKey]; val != "" {
+		var err error
+		if encrypted, err = strconv.ParseBool(val); err != nil {
+			return false, "", errors.Wrapf(err, "could not parse %s (expected bool)", encryptedKey)
+		}
+	}
+
+	kmsKeyID := config[kmsKeyIDKey]
+	if kmsKeyID != "" {
+		if config[encryptedKey] != "" && !encrypted {
+			return false, "", errors.Errorf("%s cannot be specified when %s is false", kmsKeyIDKey, encryptedKey)
+		}
+		encrypted = true
+	}
+
+	return encrypted, kmsKeyID, nil
+}
+
+// ec2ForRegion returns an EC2 client for the specified region. An empty region
+// refers to the region the block store is configured for.
+func (b *blockStore) ec2ForRegion(region string) *ec2.EC2 {
+	if region == "" || region == b.region {
+		return b.ec2
+	}
+
+	return ec2.New(b.session, aws.NewConfig().WithRegion(region))
+}
+
+// parseSnapshotID splits a snapshot ID into its region and EC2 snapshot ID. Snapshots
+// copied to another region by CopySnapshot have IDs of the form <region>/<snapshot ID>;
+// all other snapshot IDs are in the block store's region, which is returned as "".
+func parseSnapshotID(id string) (region string, snapshotID string) {
+	if i := strings.Index(id, "/"); i >= 0 {
+		return id[:i], id[i+1:]
+	}
+
+	return "", id
+}
+
 func (b *blockStore) CreateVolumeFromSnapshot(snapshotID, volumeType, volumeAZ string, iops *int64) (volumeID string, err error) {
 	// describe the snapshot so we can apply its tags to the volume
 	snapReq := &ec2.DescribeSnapshotsInput{
@@ -108,6 +185,14 @@ func (b *blockStore) CreateVolumeFromSnapshot(snapshotID, volumeType, volumeAZ s
 		req.Iops = iops
 	}
 
+	if b.encrypted {
+		req.Encrypted = aws.Bool(true)
+	}
+
+	if b.kmsKeyID != "" {
+		req.KmsKeyId = &b.kmsKeyID
+	}
+
 	res, err := b.ec2.CreateVolume(req)
 	if err != nil {
 		return "", errors.WithStack(err)
@@ -212,12 +297,125 @@ func ec2Tag(key, val string) *ec2.Tag {
 	return &ec2.Tag{Key: &key, Value: &val}
 }
 
+func (b *blockStore) describeSnapshot(client *ec2.EC2, snapshotID string) (*ec2.Snapshot, error) {
+	req := &ec2.DescribeSnapshotsInput{
+		SnapshotIds: []*string{&snapshotID},
+	}
+
+	res, err := client.DescribeSnapshots(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if count := len(res.Snapshots); count != 1 {
+		return nil, errors.Errorf("Expected one snapshot from DescribeSnapshots for snapshot ID %v, got %v", snapshotID, count)
+	}
+
+	return res.Snapshots[0], nil
+}
+
+func (b *blockStore) GetSnapshotPhase(snapshotID string) (api.SnapshotPhase, error) {
+	region, snapshotID := parseSnapshotID(snapshotID)
+
+	snapshot, err := b.describeSnapshot(b.ec2ForRegion(region), snapshotID)
+	if err != nil {
+		return "", err
+	}
+
+	switch aws.StringValue(snapshot.State) {
+	case ec2.SnapshotStateCompleted:
+		return api.SnapshotPhaseCompleted, nil
+	case ec2.SnapshotStateError:
+		return api.SnapshotPhaseFailed, nil
+	default:
+		return api.SnapshotPhaseInProgress, nil
+	}
+}
+
+func (b *blockStore) CopySnapshot(snapshotID, region string) (string, error) {
+	// describe the snapshot so we can copy its tags to the copy
+	snapshot, err := b.describeSnapshot(b.ec2, snapshotID)
+	if err != nil {
+		return "", err
+	}
+
+	target := b.ec2ForRegion(region)
+
+	res, err := target.CopySnapshot(&ec2.CopySnapshotInput{
+		SourceRegion:     &b.region,
+		SourceSnapshotId: &snapshotID,
+		Description:      aws.String(fmt.Sprintf("Copy of %s from %s", snapshotID, b.region)),
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	if len(snapshot.Tags) > 0 {
+		_, err := target.CreateTags(&ec2.CreateTagsInput{
+			Resources: []*string{res.SnapshotId},
+			Tags:      snapshot.Tags,
+		})
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+
+	return region + "/" + *res.SnapshotId, nil
+}
+
+func (b *blockStore) ListSnapshots(tagFilters map[string]string) ([]string, error) {
+	req := &ec2.DescribeSnapshotsInput{
+		OwnerIds: []*string{aws.String("self")},
+		Filters:  getTagFilters(tagFilters),
+	}
+
+	var snapshotIDs []string
+	err := b.ec2.DescribeSnapshotsPages(req, func(res *ec2.DescribeSnapshotsOutput, lastPage bool) bool {
+		for _, snapshot := range res.Snapshots {
+			snapshotIDs = append(snapshotIDs, aws.StringValue(snapshot.SnapshotId))
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return snapshotIDs, nil
+}
+
+// getTagFilters converts a set of tags to the EC2 filters that match resources
+// having all of them. Tags with an empty value match on the tag key only.
+func getTagFilters(tags map[string]string) []*ec2.Filter {
+	var filters []*ec2.Filter
+	for key, val := range tags {
+		if val == "" {
+			filters = append(filters, &ec2.Filter{
+				Name:   aws.String("tag-key"),
+				Values: []*string{aws.String(key)},
+			})
+			continue
+		}
+
+		filters = append(filters, &ec2.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: []*string{aws.String(val)},
+		})
+	}
+
+	sort.Slice(filters, func(i, j int) bool {
+		return *filters[i].Name < *filters[j].Name
+	})
+
+	return filters
+}
+
 func (b *blockStore) DeleteSnapshot(snapshotID string) error {
+	region, snapshotID := parseSnapshotID(snapshotID)
+
 	req := &ec2.DeleteSnapshotInput{
 		SnapshotId: &snapshotID,
 	}
 
-	_, err := b.ec2.DeleteSnapshot(req)
+	_, err := b.ec2ForRegion(region).DeleteSnapshot(req)
 
 	return errors.WithStack(err)
 }
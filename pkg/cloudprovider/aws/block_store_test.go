@@ -20,6 +20,7 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/heptio/ark/pkg/util/collections"
 	"github.com/stretchr/testify/assert"
@@ -167,3 +168,104 @@ func TestGetTags(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSnapshotID(t *testing.T) {
+	tests := []struct {
+		id                 string
+		expectedRegion     string
+		expectedSnapshotID string
+	}{
+		{
+			id:                 "snap-abc123",
+			expectedRegion:     "",
+			expectedSnapshotID: "snap-abc123",
+		},
+		{
+			id:                 "us-west-2/snap-abc123",
+			expectedRegion:     "us-west-2",
+			expectedSnapshotID: "snap-abc123",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			region, snapshotID := parseSnapshotID(test.id)
+			assert.Equal(t, test.expectedRegion, region)
+			assert.Equal(t, test.expectedSnapshotID, snapshotID)
+		})
+	}
+}
+
+func TestGetEncryptionConfig(t *testing.T) {
+	tests := []struct {
+		name              string
+		config            map[string]string
+		expectedEncrypted bool
+		expectedKMSKeyID  string
+		expectErr         bool
+	}{
+		{
+			name:              "no encryption config",
+			config:            map[string]string{},
+			expectedEncrypted: false,
+		},
+		{
+			name:              "encrypted without a KMS key",
+			config:            map[string]string{"encrypted": "true"},
+			expectedEncrypted: true,
+		},
+		{
+			name:              "KMS key implies encrypted",
+			config:            map[string]string{"kmsKeyId": "arn:aws:kms:us-east-1:123456789012:key/abc"},
+			expectedEncrypted: true,
+			expectedKMSKeyID:  "arn:aws:kms:us-east-1:123456789012:key/abc",
+		},
+		{
+			name:              "encrypted with a KMS key",
+			config:            map[string]string{"encrypted": "true", "kmsKeyId": "alias/ark"},
+			expectedEncrypted: true,
+			expectedKMSKeyID:  "alias/ark",
+		},
+		{
+			name:      "invalid encrypted value",
+			config:    map[string]string{"encrypted": "maybe"},
+			expectErr: true,
+		},
+		{
+			name:      "KMS key with encryption disabled",
+			config:    map[string]string{"encrypted": "false", "kmsKeyId": "alias/ark"},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encrypted, kmsKeyID, err := getEncryptionConfig(test.config)
+
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedEncrypted, encrypted)
+			assert.Equal(t, test.expectedKMSKeyID, kmsKeyID)
+		})
+	}
+}
+
+func TestGetTagFilters(t *testing.T) {
+	assert.Nil(t, getTagFilters(nil))
+
+	filters := getTagFilters(map[string]string{"ark.heptio.com/backup": "backup-1", "a": "b"})
+	require.Len(t, filters, 2)
+	assert.Equal(t, "tag:a", *filters[0].Name)
+	assert.Equal(t, []*string{aws.String("b")}, filters[0].Values)
+	assert.Equal(t, "tag:ark.heptio.com/backup", *filters[1].Name)
+	assert.Equal(t, []*string{aws.String("backup-1")}, filters[1].Values)
+
+	filters = getTagFilters(map[string]string{"ark.heptio.com/backup": ""})
+	require.Len(t, filters, 1)
+	assert.Equal(t, "tag-key", *filters[0].Name)
+	assert.Equal(t, []*string{aws.String("ark.heptio.com/backup")}, filters[0].Values)
+}
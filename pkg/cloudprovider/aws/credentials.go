@@ -0,0 +1,150 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/pkg/errors"
+
+	"github.com/heptio/ark/pkg/cloudprovider"
+)
+
+const (
+	// roleARNEnvVar and webIdentityTokenFileEnvVar are set by EKS in pods whose service
+	// account is annotated with an IAM role.
+	roleARNEnvVar              = "AWS_ROLE_ARN"
+	webIdentityTokenFileEnvVar = "AWS_WEB_IDENTITY_TOKEN_FILE"
+	roleSessionNameEnvVar      = "AWS_ROLE_SESSION_NAME"
+
+	defaultRoleSessionName = "heptio-ark"
+
+	// defaultSTSRegion is used to reach STS when no region is configured, which resolves to
+	// its global endpoint.
+	defaultSTSRegion = "us-east-1"
+)
+
+// getCredentials returns the credentials for a provider with the given config. If the config
+// has a credentials file, only it is used. Otherwise, static credentials from the environment or
+// a shared credentials file take precedence. If workload identity is enabled, they're followed
+// by IAM roles for service accounts (when configured, using httpClient to reach STS) and the
+// EC2 instance or ECS task role.
+func getCredentials(config map[string]string, httpClient *http.Client) (*credentials.Credentials, error) {
+	if credentialsFile := config[cloudprovider.CredentialsFileKey]; credentialsFile != "" {
+		return credentials.NewSharedCredentials(credentialsFile, ""), nil
+	}
+
+	workloadIdentity, err := cloudprovider.WorkloadIdentityEnabled(config)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := []credentials.Provider{
+		&credentials.EnvProvider{},
+		&credentials.SharedCredentialsProvider{},
+	}
+
+	if workloadIdentity {
+		if roleARN, tokenFile := os.Getenv(roleARNEnvVar), os.Getenv(webIdentityTokenFileEnvVar); roleARN != "" && tokenFile != "" {
+			stsRegion := config[regionKey]
+			if stsRegion == "" {
+				stsRegion = defaultSTSRegion
+			}
+
+			// the token is the credential, so the request to STS isn't signed
+			sess, err := session.NewSession(aws.NewConfig().WithRegion(stsRegion).WithCredentials(credentials.AnonymousCredentials).WithHTTPClient(httpClient))
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			providers = append(providers, newWebIdentityProvider(sts.New(sess), roleARN, tokenFile, os.Getenv(roleSessionNameEnvVar)))
+		}
+
+		providers = append(providers, defaults.RemoteCredProvider(*defaults.Config(), defaults.Handlers()))
+	}
+
+	return credentials.NewCredentials(&credentials.ChainProvider{
+		Providers:     providers,
+		VerboseErrors: true,
+	}), nil
+}
+
+// webIdentityAssumer is the subset of the STS API used by webIdentityProvider, so it can be
+// faked for testing.
+type webIdentityAssumer interface {
+	AssumeRoleWithWebIdentity(*sts.AssumeRoleWithWebIdentityInput) (*sts.AssumeRoleWithWebIdentityOutput, error)
+}
+
+// webIdentityProvider is a credentials.Provider that exchanges a service account token for
+// temporary credentials for an IAM role. The token is re-read from tokenFile on every
+// retrieval, since it's rotated by the kubelet.
+type webIdentityProvider struct {
+	credentials.Expiry
+
+	client      webIdentityAssumer
+	roleARN     string
+	tokenFile   string
+	sessionName string
+}
+
+func newWebIdentityProvider(client webIdentityAssumer, roleARN, tokenFile, sessionName string) *webIdentityProvider {
+	if sessionName == "" {
+		sessionName = defaultRoleSessionName
+	}
+
+	return &webIdentityProvider{
+		client:      client,
+		roleARN:     roleARN,
+		tokenFile:   tokenFile,
+		sessionName: sessionName,
+	}
+}
+
+func (p *webIdentityProvider) Retrieve() (credentials.Value, error) {
+	token, err := ioutil.ReadFile(p.tokenFile)
+	if err != nil {
+		return credentials.Value{}, errors.Wrapf(err, "error reading web identity token from %s", p.tokenFile)
+	}
+
+	res, err := p.client.AssumeRoleWithWebIdentity(&sts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          aws.String(p.roleARN),
+		RoleSessionName:  aws.String(p.sessionName),
+		WebIdentityToken: aws.String(strings.TrimSpace(string(token))),
+	})
+	if err != nil {
+		return credentials.Value{}, errors.Wrapf(err, "error assuming role %s with web identity", p.roleARN)
+	}
+
+	// refresh a little early so requests don't race the expiration
+	p.SetExpiration(aws.TimeValue(res.Credentials.Expiration), time.Minute)
+
+	return credentials.Value{
+		AccessKeyID:     aws.StringValue(res.Credentials.AccessKeyId),
+		SecretAccessKey: aws.StringValue(res.Credentials.SecretAccessKey),
+		SessionToken:    aws.StringValue(res.Credentials.SessionToken),
+		ProviderName:    "WebIdentityProvider",
+	}, nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWebIdentityAssumer struct {
+	input *sts.AssumeRoleWithWebIdentityInput
+	err   error
+}
+
+func (a *fakeWebIdentityAssumer) AssumeRoleWithWebIdentity(input *sts.AssumeRoleWithWebIdentityInput) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+	a.input = input
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	return &sts.AssumeRoleWithWebIdentityOutput{
+		Credentials: &sts.Credentials{
+			AccessKeyId:     aws.String("access-key"),
+			SecretAccessKey: aws.String("secret-key"),
+			SessionToken:    aws.String("session-token"),
+			Expiration:      aws.Time(time.Now().Add(time.Hour)),
+		},
+	}, nil
+}
+
+func TestWebIdentityProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("the-token\n"), 0600))
+
+	client := &fakeWebIdentityAssumer{}
+	provider := newWebIdentityProvider(client, "arn:aws:iam::123456789012:role/ark", tokenFile, "")
+	assert.True(t, provider.IsExpired())
+
+	creds, err := provider.Retrieve()
+	require.NoError(t, err)
+	assert.Equal(t, "access-key", creds.AccessKeyID)
+	assert.Equal(t, "secret-key", creds.SecretAccessKey)
+	assert.Equal(t, "session-token", creds.SessionToken)
+	assert.False(t, provider.IsExpired())
+
+	assert.Equal(t, "arn:aws:iam::123456789012:role/ark", aws.StringValue(client.input.RoleArn))
+	assert.Equal(t, defaultRoleSessionName, aws.StringValue(client.input.RoleSessionName))
+	assert.Equal(t, "the-token", aws.StringValue(client.input.WebIdentityToken))
+
+	client.err = errors.New("access denied")
+	_, err = provider.Retrieve()
+	assert.EqualError(t, err, "error assuming role arn:aws:iam::123456789012:role/ark with web identity: access denied")
+
+	provider = newWebIdentityProvider(client, "arn:aws:iam::123456789012:role/ark", filepath.Join(dir, "missing"), "")
+	_, err = provider.Retrieve()
+	assert.Error(t, err)
+}
+
+func TestGetCredentialsFromCredentialsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	credentialsFile := filepath.Join(dir, "credentials")
+	require.NoError(t, ioutil.WriteFile(credentialsFile, []byte("[default]\naws_access_key_id = file-access-key\naws_secret_access_key = file-secret-key\n"), 0600))
+
+	creds, err := getCredentials(map[string]string{"credentialsFile": credentialsFile}, http.DefaultClient)
+	require.NoError(t, err)
+	val, err := creds.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "file-access-key", val.AccessKeyID)
+	assert.Equal(t, "file-secret-key", val.SecretAccessKey)
+}
+
+func TestGetCredentialsWithoutWorkloadIdentity(t *testing.T) {
+	for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY", "AWS_SECRET_KEY", "AWS_SHARED_CREDENTIALS_FILE"} {
+		if val, ok := os.LookupEnv(key); ok {
+			defer os.Setenv(key, val)
+		} else {
+			defer os.Unsetenv(key)
+		}
+		os.Unsetenv(key)
+	}
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/nonexistent/credentials")
+
+	_, err := getCredentials(map[string]string{"workloadIdentity": "maybe"}, http.DefaultClient)
+	assert.Error(t, err)
+
+	// with workload identity disabled, only mounted or environment credentials are used,
+	// so there's nothing to fall back to
+	creds, err := getCredentials(map[string]string{"workloadIdentity": "false"}, http.DefaultClient)
+	require.NoError(t, err)
+	_, err = creds.Get()
+	assert.Error(t, err)
+
+	os.Setenv("AWS_ACCESS_KEY_ID", "env-access-key")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret-key")
+	creds, err = getCredentials(map[string]string{"workloadIdentity": "false"}, http.DefaultClient)
+	require.NoError(t, err)
+	val, err := creds.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "env-access-key", val.AccessKeyID)
+}
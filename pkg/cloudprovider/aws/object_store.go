@@ -19,10 +19,12 @@ package aws
 import (
 	"context"
 	"io"
+	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -49,10 +51,10 @@ func NewObjectStore() cloudprovider.ObjectStore {
 	return &objectStore{}
 }
 
-func getBucketRegion(bucket string) (string, error) {
+func getBucketRegion(bucket string, httpClient *http.Client) (string, error) {
 	var region string
 
-	session, err := session.NewSession()
+	session, err := session.NewSession(aws.NewConfig().WithHTTPClient(httpClient))
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
@@ -95,19 +97,31 @@ func (o *objectStore) Init(config map[string]string) error {
 		}
 	}
 
+	httpClient, err := cloudprovider.NewHTTPClient(config)
+	if err != nil {
+		return err
+	}
+
 	// AWS (not an alternate S3-compatible API) and region not
 	// explicitly specified: determine the bucket's region
 	if s3URL == "" && region == "" {
 		var err error
 
-		region, err = getBucketRegion(bucket)
+		region, err = getBucketRegion(bucket, httpClient)
 		if err != nil {
 			return err
 		}
 	}
 
+	creds, err := getCredentials(config, httpClient)
+	if err != nil {
+		return err
+	}
+
 	awsConfig := aws.NewConfig().
 		WithRegion(region).
+		WithCredentials(creds).
+		WithHTTPClient(httpClient).
 		WithS3ForcePathStyle(s3ForcePathStyle)
 
 	if s3URL != "" {
@@ -168,6 +182,26 @@ func (o *objectStore) GetObject(bucket string, key string) (io.ReadCloser, error
 	return res.Body, nil
 }
 
+func (o *objectStore) GetObjectIfChanged(bucket, key, etag string) (io.ReadCloser, string, error) {
+	req := &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	}
+	if etag != "" {
+		req.IfNoneMatch = &etag
+	}
+
+	res, err := o.s3.GetObject(req)
+	if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusNotModified {
+		return nil, "", cloudprovider.ErrObjectNotModified
+	}
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "error getting object %s", key)
+	}
+
+	return res.Body, aws.StringValue(res.ETag), nil
+}
+
 func (o *objectStore) ListCommonPrefixes(bucket string, delimiter string) ([]string, error) {
 	req := &s3.ListObjectsV2Input{
 		Bucket:    &bucket,
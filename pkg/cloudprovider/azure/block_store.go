@@ -19,20 +19,20 @@ package azure
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/arm/disk"
-	"github.com/Azure/azure-sdk-for-go/arm/examples/helpers"
-	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/pkg/errors"
 	"github.com/satori/uuid"
 
 	"k8s.io/apimachinery/pkg/runtime"
 
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/util/collections"
 )
@@ -46,6 +46,7 @@ const (
 	azureStorageKeyKey       = "AZURE_STORAGE_KEY"
 	azureResourceGroupKey    = "AZURE_RESOURCE_GROUP"
 	apiTimeoutKey            = "apiTimeout"
+	restoreResourceGroupKey  = "restoreResourceGroup"
 	snapshotsResource        = "snapshots"
 	disksResource            = "disks"
 )
@@ -56,6 +57,10 @@ type blockStore struct {
 	subscription  string
 	resourceGroup string
 	apiTimeout    time.Duration
+
+	// restoreResourceGroup is the resource group that volumes are created in
+	// when restoring from snapshots. It defaults to resourceGroup.
+	restoreResourceGroup string
 }
 
 type snapshotIdentifier struct {
@@ -68,7 +73,20 @@ func (si *snapshotIdentifier) String() string {
 	return getComputeResourceName(si.subscription, si.resourceGroup, snapshotsResource, si.name)
 }
 
-func getConfig() map[string]string {
+type diskIdentifier struct {
+	subscription  string
+	resourceGroup string
+	name          string
+}
+
+func (di *diskIdentifier) String() string {
+	return getComputeResourceName(di.subscription, di.resourceGroup, disksResource, di.name)
+}
+
+// getConfig returns the Azure credentials and settings from the environment, overridden by
+// those in the provider's credentials file, if it has one. The file has a KEY=VALUE line for
+// each of them.
+func getConfig(config map[string]string) (map[string]string, error) {
 	cfg := map[string]string{
 		azureClientIDKey:         "",
 		azureClientSecretKey:     "",
@@ -83,7 +101,34 @@ func getConfig() map[string]string {
 		cfg[key] = os.Getenv(key)
 	}
 
-	return cfg
+	credentialsFile := config[cloudprovider.CredentialsFileKey]
+	if credentialsFile == "" {
+		return cfg, nil
+	}
+
+	contents, err := ioutil.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for i, line := range strings.Split(string(contents), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid line %d in credentials file %s: expected KEY=VALUE", i+1, credentialsFile)
+		}
+
+		key := strings.TrimSpace(parts[0])
+		if _, ok := cfg[key]; ok {
+			cfg[key] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	return cfg, nil
 }
 
 func NewBlockStore() cloudprovider.BlockStore {
@@ -91,6 +136,10 @@ func NewBlockStore() cloudprovider.BlockStore {
 }
 
 func (b *blockStore) Init(config map[string]string) error {
+	if config[cloudprovider.SnapshotCopyRegionKey] != "" {
+		return errors.Errorf("%s is not supported for Azure", cloudprovider.SnapshotCopyRegionKey)
+	}
+
 	var (
 		apiTimeoutVal = config[apiTimeoutKey]
 		apiTimeout    time.Duration
@@ -105,11 +154,19 @@ func (b *blockStore) Init(config map[string]string) error {
 		apiTimeout = 2 * time.Minute
 	}
 
-	cfg := getConfig()
+	cfg, err := getConfig(config)
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := cloudprovider.NewHTTPClient(config)
+	if err != nil {
+		return err
+	}
 
-	spt, err := helpers.NewServicePrincipalTokenFromCredentials(cfg, azure.PublicCloud.ResourceManagerEndpoint)
+	authorizer, err := getAuthorizer(cfg, config, azure.PublicCloud.ResourceManagerEndpoint, httpClient)
 	if err != nil {
-		return errors.Wrap(err, "error creating new service principal token")
+		return err
 	}
 
 	disksClient := disk.NewDisksClient(cfg[azureSubscriptionIDKey])
@@ -118,7 +175,9 @@ func (b *blockStore) Init(config map[string]string) error {
 	disksClient.PollingDelay = 5 * time.Second
 	snapsClient.PollingDelay = 5 * time.Second
 
-	authorizer := autorest.NewBearerAuthorizer(spt)
+	disksClient.Sender = httpClient
+	snapsClient.Sender = httpClient
+
 	disksClient.Authorizer = authorizer
 	snapsClient.Authorizer = authorizer
 
@@ -128,6 +187,11 @@ func (b *blockStore) Init(config map[string]string) error {
 	b.resourceGroup = cfg[azureResourceGroupKey]
 	b.apiTimeout = apiTimeout
 
+	b.restoreResourceGroup = config[restoreResourceGroupKey]
+	if b.restoreResourceGroup == "" {
+		b.restoreResourceGroup = b.resourceGroup
+	}
+
 	return nil
 }
 
@@ -161,18 +225,28 @@ func (b *blockStore) CreateVolumeFromSnapshot(snapshotID, volumeType, volumeAZ s
 	ctx, cancel := context.WithTimeout(context.Background(), b.apiTimeout)
 	defer cancel()
 
-	_, errChan := b.disks.CreateOrUpdate(b.resourceGroup, *disk.Name, disk, ctx.Done())
+	_, errChan := b.disks.CreateOrUpdate(b.restoreResourceGroup, *disk.Name, disk, ctx.Done())
 
 	err = <-errChan
 
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
-	return diskName, nil
+
+	return b.getVolumeID(&diskIdentifier{
+		subscription:  b.subscription,
+		resourceGroup: b.restoreResourceGroup,
+		name:          diskName,
+	}), nil
 }
 
 func (b *blockStore) GetVolumeInfo(volumeID, volumeAZ string) (string, *int64, error) {
-	res, err := b.disks.Get(b.resourceGroup, volumeID)
+	diskInfo, err := b.parseDiskName(volumeID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	res, err := b.disks.Get(diskInfo.resourceGroup, diskInfo.name)
 	if err != nil {
 		return "", nil, errors.WithStack(err)
 	}
@@ -181,7 +255,12 @@ func (b *blockStore) GetVolumeInfo(volumeID, volumeAZ string) (string, *int64, e
 }
 
 func (b *blockStore) IsVolumeReady(volumeID, volumeAZ string) (ready bool, err error) {
-	res, err := b.disks.Get(b.resourceGroup, volumeID)
+	diskInfo, err := b.parseDiskName(volumeID)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := b.disks.Get(diskInfo.resourceGroup, diskInfo.name)
 	if err != nil {
 		return false, errors.WithStack(err)
 	}
@@ -194,23 +273,30 @@ func (b *blockStore) IsVolumeReady(volumeID, volumeAZ string) (ready bool, err e
 }
 
 func (b *blockStore) CreateSnapshot(volumeID, volumeAZ string, tags map[string]string) (string, error) {
+	diskIdentifier, err := b.parseDiskName(volumeID)
+	if err != nil {
+		return "", err
+	}
+
 	// Lookup disk info for its Location
-	diskInfo, err := b.disks.Get(b.resourceGroup, volumeID)
+	diskInfo, err := b.disks.Get(diskIdentifier.resourceGroup, diskIdentifier.name)
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
 
-	fullDiskName := getComputeResourceName(b.subscription, b.resourceGroup, disksResource, volumeID)
+	fullDiskName := diskIdentifier.String()
 	// snapshot names must be <= 80 characters long
 	var snapshotName string
 	suffix := "-" + uuid.NewV4().String()
 
-	if len(volumeID) <= (80 - len(suffix)) {
-		snapshotName = volumeID + suffix
+	if len(diskIdentifier.name) <= (80 - len(suffix)) {
+		snapshotName = diskIdentifier.name + suffix
 	} else {
-		snapshotName = volumeID[0:80-len(suffix)] + suffix
+		snapshotName = diskIdentifier.name[0:80-len(suffix)] + suffix
 	}
 
+	// this is always a full snapshot: the disks API version in the vendored SDK
+	// (2016-04-30-preview) doesn't support incremental ones
 	snap := disk.Snapshot{
 		Name: &snapshotName,
 		Properties: &disk.Properties{
@@ -236,6 +322,51 @@ func (b *blockStore) CreateSnapshot(volumeID, volumeAZ string, tags map[string]s
 	return getComputeResourceName(b.subscription, b.resourceGroup, snapshotsResource, snapshotName), nil
 }
 
+func (b *blockStore) ListSnapshots(tagFilters map[string]string) ([]string, error) {
+	var snapshotIDs []string
+
+	res, err := b.snaps.ListByResourceGroup(b.resourceGroup)
+	for {
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if res.Value != nil {
+			for _, snapshot := range *res.Value {
+				if snapshot.Name != nil && hasTags(snapshot.Tags, tagFilters) {
+					snapshotIDs = append(snapshotIDs, getComputeResourceName(b.subscription, b.resourceGroup, snapshotsResource, *snapshot.Name))
+				}
+			}
+		}
+
+		if res.NextLink == nil || *res.NextLink == "" {
+			break
+		}
+
+		res, err = b.snaps.ListByResourceGroupNextResults(res)
+	}
+
+	return snapshotIDs, nil
+}
+
+// hasTags returns true if tags contains every key/value pair in filters. Filters
+// with an empty value match any value for the key. Filter keys are normalized the
+// same way as Ark-assigned tags are when snapshots are created.
+func hasTags(tags *map[string]*string, filters map[string]string) bool {
+	for key, val := range filters {
+		if tags == nil {
+			return false
+		}
+
+		tag, ok := (*tags)[strings.Replace(key, "/", "-", -1)]
+		if !ok || tag == nil || (val != "" && *tag != val) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func getSnapshotTags(arkTags map[string]string, diskTags *map[string]*string) *map[string]*string {
 	if diskTags == nil && len(arkTags) == 0 {
 		return nil
@@ -267,6 +398,35 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+func (b *blockStore) GetSnapshotPhase(snapshotID string) (api.SnapshotPhase, error) {
+	snapshotInfo, err := b.parseSnapshotName(snapshotID)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := b.snaps.Get(snapshotInfo.resourceGroup, snapshotInfo.name)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	if res.Properties == nil || res.Properties.ProvisioningState == nil {
+		return "", errors.New("nil ProvisioningState returned from Get call")
+	}
+
+	switch *res.Properties.ProvisioningState {
+	case "Succeeded":
+		return api.SnapshotPhaseCompleted, nil
+	case "Failed":
+		return api.SnapshotPhaseFailed, nil
+	default:
+		return api.SnapshotPhaseInProgress, nil
+	}
+}
+
+func (b *blockStore) CopySnapshot(snapshotID, region string) (string, error) {
+	return "", errors.New("copying snapshots to another region is not supported for Azure")
+}
+
 func (b *blockStore) DeleteSnapshot(snapshotID string) error {
 	snapshotInfo, err := b.parseSnapshotName(snapshotID)
 	if err != nil {
@@ -344,6 +504,68 @@ func parseFullSnapshotName(name string) (*snapshotIdentifier, error) {
 	return snapshotID, nil
 }
 
+var diskURIRegexp = regexp.MustCompile(
+	`^\/subscriptions\/(?P<subscription>.*)\/resourceGroups\/(?P<resourceGroup>.*)\/providers\/Microsoft.Compute\/disks\/(?P<diskName>.*)$`)
+
+// parseDiskName takes a volume ID, which is either a disk name or a
+// fully-qualified disk URI, and returns a disk identifier or an error if
+// the ID is not in a valid format. Disk names that are not fully-qualified
+// are assumed to be in the subscription and resource group that the block
+// store is configured with.
+func (b *blockStore) parseDiskName(name string) (*diskIdentifier, error) {
+	switch {
+	case !strings.Contains(name, "/"):
+		return &diskIdentifier{
+			subscription:  b.subscription,
+			resourceGroup: b.resourceGroup,
+			name:          name,
+		}, nil
+	case diskURIRegexp.MatchString(name):
+		return parseFullDiskName(name)
+	default:
+		return nil, errors.New("disk name is not in a valid format")
+	}
+}
+
+// parseFullDiskName takes a fully-qualified disk URI and returns a disk
+// identifier or an error if the URI does not match the regexp.
+func parseFullDiskName(name string) (*diskIdentifier, error) {
+	submatches := diskURIRegexp.FindStringSubmatch(name)
+	if len(submatches) != len(diskURIRegexp.SubexpNames()) {
+		return nil, errors.New("disk URI could not be parsed")
+	}
+
+	diskID := &diskIdentifier{}
+
+	// capture names start at index 1 to line up with the corresponding indexes
+	// of submatches (see godoc on SubexpNames())
+	for i, names := 1, diskURIRegexp.SubexpNames(); i < len(names); i++ {
+		switch names[i] {
+		case "subscription":
+			diskID.subscription = submatches[i]
+		case "resourceGroup":
+			diskID.resourceGroup = submatches[i]
+		case "diskName":
+			diskID.name = submatches[i]
+		default:
+			return nil, errors.New("unexpected named capture from disk URI regex")
+		}
+	}
+
+	return diskID, nil
+}
+
+// getVolumeID returns the volume ID for a disk: the disk name if the disk is in
+// the block store's configured subscription and resource group, or the disk's
+// fully-qualified URI otherwise.
+func (b *blockStore) getVolumeID(disk *diskIdentifier) string {
+	if disk.subscription == b.subscription && disk.resourceGroup == b.resourceGroup {
+		return disk.name
+	}
+
+	return disk.String()
+}
+
 func (b *blockStore) GetVolumeID(pv runtime.Unstructured) (string, error) {
 	if !collections.Exists(pv.UnstructuredContent(), "spec.azureDisk") {
 		return "", nil
@@ -354,6 +576,16 @@ func (b *blockStore) GetVolumeID(pv runtime.Unstructured) (string, error) {
 		return "", err
 	}
 
+	// if the PV's disk URI points at a disk in a different resource group than the
+	// one the block store is configured with, use the URI so the disk can be found.
+	if diskURI, err := collections.GetString(pv.UnstructuredContent(), "spec.azureDisk.diskURI"); err == nil && diskURIRegexp.MatchString(diskURI) {
+		diskInfo, err := parseFullDiskName(diskURI)
+		if err != nil {
+			return "", err
+		}
+		return b.getVolumeID(diskInfo), nil
+	}
+
 	return volumeID, nil
 }
 
@@ -363,8 +595,13 @@ func (b *blockStore) SetVolumeID(pv runtime.Unstructured, volumeID string) (runt
 		return nil, err
 	}
 
-	azure["diskName"] = volumeID
-	azure["diskURI"] = getComputeResourceName(b.subscription, b.resourceGroup, disksResource, volumeID)
+	diskInfo, err := b.parseDiskName(volumeID)
+	if err != nil {
+		return nil, err
+	}
+
+	azure["diskName"] = diskInfo.name
+	azure["diskURI"] = diskInfo.String()
 
 	return pv, nil
 }
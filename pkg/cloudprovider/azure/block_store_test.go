@@ -27,7 +27,10 @@ import (
 )
 
 func TestGetVolumeID(t *testing.T) {
-	b := &blockStore{}
+	b := &blockStore{
+		subscription:  "sub",
+		resourceGroup: "rg",
+	}
 
 	pv := &unstructured.Unstructured{}
 
@@ -50,6 +53,18 @@ func TestGetVolumeID(t *testing.T) {
 	volumeID, err = b.GetVolumeID(pv)
 	assert.NoError(t, err)
 	assert.Equal(t, "foo", volumeID)
+
+	// diskURI in the configured resource group -> disk name
+	azure["diskURI"] = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/disks/foo"
+	volumeID, err = b.GetVolumeID(pv)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", volumeID)
+
+	// diskURI in another resource group -> fully-qualified disk URI
+	azure["diskURI"] = "/subscriptions/sub/resourceGroups/other-rg/providers/Microsoft.Compute/disks/foo"
+	volumeID, err = b.GetVolumeID(pv)
+	assert.NoError(t, err)
+	assert.Equal(t, "/subscriptions/sub/resourceGroups/other-rg/providers/Microsoft.Compute/disks/foo", volumeID)
 }
 
 func TestSetVolumeID(t *testing.T) {
@@ -88,6 +103,57 @@ func TestSetVolumeID(t *testing.T) {
 	actual, err = collections.GetString(updatedPV.UnstructuredContent(), "spec.azureDisk.diskURI")
 	require.NoError(t, err)
 	assert.Equal(t, "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/disks/revised", actual)
+
+	// fully-qualified volume ID in another resource group
+	updatedPV, err = b.SetVolumeID(pv, "/subscriptions/sub/resourceGroups/restore-rg/providers/Microsoft.Compute/disks/restored")
+	require.NoError(t, err)
+	actual, err = collections.GetString(updatedPV.UnstructuredContent(), "spec.azureDisk.diskName")
+	require.NoError(t, err)
+	assert.Equal(t, "restored", actual)
+	actual, err = collections.GetString(updatedPV.UnstructuredContent(), "spec.azureDisk.diskURI")
+	require.NoError(t, err)
+	assert.Equal(t, "/subscriptions/sub/resourceGroups/restore-rg/providers/Microsoft.Compute/disks/restored", actual)
+
+	// invalid volume ID
+	_, err = b.SetVolumeID(pv, "foo/bar")
+	assert.Error(t, err)
+}
+
+func TestParseDiskName(t *testing.T) {
+	b := &blockStore{
+		subscription:  "default-sub",
+		resourceGroup: "default-rg",
+	}
+
+	// invalid name
+	_, err := b.parseDiskName("foo/bar")
+	assert.Error(t, err)
+
+	// fully-qualified name
+	disk, err := b.parseDiskName("/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Compute/disks/disk-1")
+	require.NoError(t, err)
+	assert.Equal(t, "sub-1", disk.subscription)
+	assert.Equal(t, "rg-1", disk.resourceGroup)
+	assert.Equal(t, "disk-1", disk.name)
+
+	// disk name only
+	disk, err = b.parseDiskName("disk-1")
+	require.NoError(t, err)
+	assert.Equal(t, b.subscription, disk.subscription)
+	assert.Equal(t, b.resourceGroup, disk.resourceGroup)
+	assert.Equal(t, "disk-1", disk.name)
+}
+
+func TestGetVolumeIDForDisk(t *testing.T) {
+	b := &blockStore{
+		subscription:  "sub",
+		resourceGroup: "rg",
+	}
+
+	assert.Equal(t, "disk-1", b.getVolumeID(&diskIdentifier{subscription: "sub", resourceGroup: "rg", name: "disk-1"}))
+	assert.Equal(t,
+		"/subscriptions/sub/resourceGroups/restore-rg/providers/Microsoft.Compute/disks/disk-1",
+		b.getVolumeID(&diskIdentifier{subscription: "sub", resourceGroup: "restore-rg", name: "disk-1"}))
 }
 
 // TODO(1.0) rename to TestParseFullSnapshotName, switch to testing
@@ -222,3 +288,24 @@ func TestGetSnapshotTags(t *testing.T) {
 		})
 	}
 }
+
+func TestHasTags(t *testing.T) {
+	tags := &map[string]*string{
+		"ark.heptio.com-backup": stringPtr("backup-1"),
+		"a":                     stringPtr("b"),
+	}
+
+	assert.True(t, hasTags(tags, nil))
+	assert.True(t, hasTags(tags, map[string]string{"ark.heptio.com/backup": "backup-1"}))
+	assert.False(t, hasTags(tags, map[string]string{"ark.heptio.com/backup": "backup-2"}))
+	assert.False(t, hasTags(tags, map[string]string{"c": "d"}))
+	assert.True(t, hasTags(tags, map[string]string{"ark.heptio.com/backup": ""}))
+	assert.False(t, hasTags(tags, map[string]string{"c": ""}))
+	assert.False(t, hasTags(nil, map[string]string{"a": "b"}))
+	assert.True(t, hasTags(nil, nil))
+}
+
+func TestInitRejectsSnapshotCopyRegion(t *testing.T) {
+	err := (&blockStore{}).Init(map[string]string{"snapshotCopyRegion": "other-region"})
+	assert.EqualError(t, err, "snapshotCopyRegion is not supported for Azure")
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/arm/examples/helpers"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/pkg/errors"
+
+	"github.com/heptio/ark/pkg/cloudprovider"
+)
+
+const (
+	// imdsTokenEndpoint is the instance metadata service endpoint that issues tokens for a
+	// VM's managed identity. AAD pod identity intercepts requests to it and answers with a
+	// token for the identity bound to the pod.
+	imdsTokenEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+	imdsAPIVersion    = "2018-02-01"
+	storageAPIVersion = "2017-10-01"
+
+	// tokenRefreshWindow is how long before a token expires that it's refreshed.
+	tokenRefreshWindow = 5 * time.Minute
+)
+
+// getAuthorizer returns an authorizer for requests to resource. A service principal is used
+// if AZURE_CLIENT_SECRET is set, getting its tokens from Azure AD with httpClient. Otherwise, if workload identity is enabled in config, tokens
+// come from the managed identity (optionally the user-assigned one with AZURE_CLIENT_ID)
+// available to the pod.
+func getAuthorizer(cfg, config map[string]string, resource string, httpClient *http.Client) (autorest.Authorizer, error) {
+	if cfg[azureClientSecretKey] != "" {
+		spt, err := helpers.NewServicePrincipalTokenFromCredentials(cfg, resource)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating new service principal token")
+		}
+		spt.SetSender(httpClient)
+		return autorest.NewBearerAuthorizer(spt), nil
+	}
+
+	workloadIdentity, err := cloudprovider.WorkloadIdentityEnabled(config)
+	if err != nil {
+		return nil, err
+	}
+	if !workloadIdentity {
+		return nil, errors.Errorf("%s is undefined and %s is disabled", azureClientSecretKey, cloudprovider.WorkloadIdentityKey)
+	}
+
+	tokenProvider := newIMDSTokenProvider(imdsTokenEndpoint, resource, cfg[azureClientIDKey])
+	if err := tokenProvider.Refresh(); err != nil {
+		return nil, err
+	}
+
+	return autorest.NewBearerAuthorizer(tokenProvider), nil
+}
+
+// imdsTokenProvider is an adal.OAuthTokenProvider and adal.Refresher that gets tokens from
+// the instance metadata service.
+type imdsTokenProvider struct {
+	endpoint string
+	resource string
+	clientID string
+	sender   autorest.Sender
+
+	lock      sync.Mutex
+	token     string
+	expiresOn time.Time
+}
+
+func newIMDSTokenProvider(endpoint, resource, clientID string) *imdsTokenProvider {
+	return &imdsTokenProvider{
+		endpoint: endpoint,
+		resource: resource,
+		clientID: clientID,
+		sender:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *imdsTokenProvider) OAuthToken() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.token
+}
+
+func (p *imdsTokenProvider) EnsureFresh() error {
+	p.lock.Lock()
+	fresh := time.Now().Add(tokenRefreshWindow).Before(p.expiresOn)
+	p.lock.Unlock()
+
+	if fresh {
+		return nil
+	}
+	return p.Refresh()
+}
+
+func (p *imdsTokenProvider) Refresh() error {
+	return p.RefreshExchange(p.resource)
+}
+
+func (p *imdsTokenProvider) RefreshExchange(resource string) error {
+	params := map[string]interface{}{
+		"api-version": imdsAPIVersion,
+		"resource":    resource,
+	}
+	if p.clientID != "" {
+		params["client_id"] = p.clientID
+	}
+
+	req, err := autorest.Prepare(&http.Request{},
+		autorest.AsGet(),
+		autorest.WithBaseURL(p.endpoint),
+		autorest.WithQueryParameters(params),
+		autorest.WithHeader("Metadata", "true"),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	res, err := autorest.SendWithSender(p.sender, req)
+	if err != nil {
+		return errors.Wrap(err, "error getting token from the instance metadata service")
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresOn   string `json:"expires_on"`
+	}
+	if err := autorest.Respond(res,
+		azure.WithErrorUnlessStatusCode(http.StatusOK),
+		autorest.ByUnmarshallingJSON(&token),
+		autorest.ByClosing(),
+	); err != nil {
+		return errors.Wrap(err, "error getting token from the instance metadata service")
+	}
+
+	expiresOn, err := strconv.ParseInt(token.ExpiresOn, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "error parsing token expiration %q", token.ExpiresOn)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.token = token.AccessToken
+	p.expiresOn = time.Unix(expiresOn, 0)
+
+	return nil
+}
+
+// getStorageAccountKey uses authorizer to look up the first access key of a storage account
+// through the Azure Resource Manager API at baseURL.
+func getStorageAccountKey(sender autorest.Sender, authorizer autorest.Authorizer, baseURL, subscription, resourceGroup, storageAccount string) (string, error) {
+	req, err := autorest.Prepare(&http.Request{},
+		autorest.AsPost(),
+		autorest.WithBaseURL(baseURL),
+		autorest.WithPathParameters("/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Storage/storageAccounts/{accountName}/listKeys", map[string]interface{}{
+			"subscriptionId":    autorest.Encode("path", subscription),
+			"resourceGroupName": autorest.Encode("path", resourceGroup),
+			"accountName":       autorest.Encode("path", storageAccount),
+		}),
+		autorest.WithQueryParameters(map[string]interface{}{"api-version": storageAPIVersion}),
+		authorizer.WithAuthorization(),
+	)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	res, err := autorest.SendWithSender(sender, req)
+	if err != nil {
+		return "", errors.Wrapf(err, "error listing keys for storage account %s", storageAccount)
+	}
+
+	var keys struct {
+		Keys []struct {
+			Value string `json:"value"`
+		} `json:"keys"`
+	}
+	if err := autorest.Respond(res,
+		azure.WithErrorUnlessStatusCode(http.StatusOK),
+		autorest.ByUnmarshallingJSON(&keys),
+		autorest.ByClosing(),
+	); err != nil {
+		return "", errors.Wrapf(err, "error listing keys for storage account %s", storageAccount)
+	}
+
+	if len(keys.Keys) == 0 || keys.Keys[0].Value == "" {
+		return "", errors.Errorf("storage account %s has no keys", storageAccount)
+	}
+
+	return keys.Keys[0].Value, nil
+}
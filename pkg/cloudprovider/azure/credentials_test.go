@@ -0,0 +1,131 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIMDSTokenProvider(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		assert.Equal(t, "true", r.Header.Get("Metadata"))
+		assert.Equal(t, "https://management.azure.com/", r.URL.Query().Get("resource"))
+		assert.Equal(t, "client-1", r.URL.Query().Get("client_id"))
+
+		expiresOn := time.Now().Add(time.Hour).Unix()
+		fmt.Fprintf(w, `{"access_token":"token-%d","expires_on":"%s","token_type":"Bearer"}`, requests, strconv.FormatInt(expiresOn, 10))
+	}))
+	defer server.Close()
+
+	provider := newIMDSTokenProvider(server.URL, "https://management.azure.com/", "client-1")
+
+	require.NoError(t, provider.EnsureFresh())
+	assert.Equal(t, "token-1", provider.OAuthToken())
+
+	// the token is still fresh, so it isn't refreshed
+	require.NoError(t, provider.EnsureFresh())
+	assert.Equal(t, "token-1", provider.OAuthToken())
+	assert.Equal(t, 1, requests)
+
+	// the token is about to expire, so it's refreshed
+	provider.expiresOn = time.Now().Add(time.Minute)
+	require.NoError(t, provider.EnsureFresh())
+	assert.Equal(t, "token-2", provider.OAuthToken())
+}
+
+func TestIMDSTokenProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	provider := newIMDSTokenProvider(server.URL, "https://management.azure.com/", "")
+	assert.Error(t, provider.Refresh())
+}
+
+func TestGetAuthorizerWithoutWorkloadIdentity(t *testing.T) {
+	_, err := getAuthorizer(map[string]string{}, map[string]string{"workloadIdentity": "false"}, "https://management.azure.com/", http.DefaultClient)
+	assert.EqualError(t, err, "AZURE_CLIENT_SECRET is undefined and workloadIdentity is disabled")
+}
+
+func TestGetStorageAccountKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/subscriptions/sub-1/resourceGroups/rg-1/providers/Microsoft.Storage/storageAccounts/account-1/listKeys", r.URL.Path)
+		assert.Equal(t, "Bearer the-token", r.Header.Get("Authorization"))
+
+		if r.URL.Query().Get("api-version") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"keys":[{"keyName":"key1","value":"key-1"},{"keyName":"key2","value":"key-2"}]}`)
+	}))
+	defer server.Close()
+
+	authorizer := autorest.NewBearerAuthorizer(&fakeTokenProvider{token: "the-token"})
+
+	key, err := getStorageAccountKey(http.DefaultClient, authorizer, server.URL, "sub-1", "rg-1", "account-1")
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", key)
+}
+
+type fakeTokenProvider struct {
+	token string
+}
+
+func (p *fakeTokenProvider) OAuthToken() string {
+	return p.token
+}
+
+func TestGetConfigFromCredentialsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	credentialsFile := filepath.Join(dir, "credentials")
+	require.NoError(t, ioutil.WriteFile(credentialsFile, []byte(`
+# the second account
+AZURE_SUBSCRIPTION_ID=sub-2
+AZURE_CLIENT_SECRET = secret=with=equals
+UNKNOWN_KEY=ignored
+`), 0600))
+
+	cfg, err := getConfig(map[string]string{"credentialsFile": credentialsFile})
+	require.NoError(t, err)
+	assert.Equal(t, "sub-2", cfg[azureSubscriptionIDKey])
+	assert.Equal(t, "secret=with=equals", cfg[azureClientSecretKey])
+	assert.NotContains(t, cfg, "UNKNOWN_KEY")
+
+	require.NoError(t, ioutil.WriteFile(credentialsFile, []byte("AZURE_SUBSCRIPTION_ID\n"), 0600))
+	_, err = getConfig(map[string]string{"credentialsFile": credentialsFile})
+	assert.EqualError(t, err, fmt.Sprintf("invalid line 1 in credentials file %s: expected KEY=VALUE", credentialsFile))
+}
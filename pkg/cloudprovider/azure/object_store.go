@@ -18,15 +18,21 @@ package azure
 
 import (
 	"io"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/storage"
+	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/pkg/errors"
 
 	"github.com/heptio/ark/pkg/cloudprovider"
 )
 
+// storageResourceGroupKey is the config key for the resource group of the storage account,
+// used to look up its key when AZURE_STORAGE_KEY isn't set. It defaults to AZURE_RESOURCE_GROUP.
+const storageResourceGroupKey = "resourceGroup"
+
 type objectStore struct {
 	blobClient *storage.BlobStorageClient
 }
@@ -36,12 +42,40 @@ func NewObjectStore() cloudprovider.ObjectStore {
 }
 
 func (o *objectStore) Init(config map[string]string) error {
-	cfg := getConfig()
+	cfg, err := getConfig(config)
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := cloudprovider.NewHTTPClient(config)
+	if err != nil {
+		return err
+	}
+
+	storageKey := cfg[azureStorageKeyKey]
+	if storageKey == "" {
+		// no key was provided, so look it up with the service principal or workload identity
+		authorizer, err := getAuthorizer(cfg, config, azure.PublicCloud.ResourceManagerEndpoint, httpClient)
+		if err != nil {
+			return err
+		}
+
+		resourceGroup := config[storageResourceGroupKey]
+		if resourceGroup == "" {
+			resourceGroup = cfg[azureResourceGroupKey]
+		}
 
-	storageClient, err := storage.NewBasicClient(cfg[azureStorageAccountIDKey], cfg[azureStorageKeyKey])
+		storageKey, err = getStorageAccountKey(httpClient, authorizer, azure.PublicCloud.ResourceManagerEndpoint, cfg[azureSubscriptionIDKey], resourceGroup, cfg[azureStorageAccountIDKey])
+		if err != nil {
+			return err
+		}
+	}
+
+	storageClient, err := storage.NewBasicClient(cfg[azureStorageAccountIDKey], storageKey)
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	storageClient.HTTPClient = httpClient
 
 	blobClient := storageClient.GetBlobService()
 
@@ -83,6 +117,28 @@ func (o *objectStore) GetObject(bucket string, key string) (io.ReadCloser, error
 	return res, nil
 }
 
+func (o *objectStore) GetObjectIfChanged(bucket, key, etag string) (io.ReadCloser, string, error) {
+	container, err := getContainerReference(o.blobClient, bucket)
+	if err != nil {
+		return nil, "", err
+	}
+
+	blob, err := getBlobReference(container, key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	res, err := blob.Get(&storage.GetBlobOptions{IfNoneMatch: etag})
+	if statusErr, ok := err.(storage.UnexpectedStatusCodeError); ok && statusErr.Got() == http.StatusNotModified {
+		return nil, "", cloudprovider.ErrObjectNotModified
+	}
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	return res, blob.Properties.Etag, nil
+}
+
 func (o *objectStore) ListCommonPrefixes(bucket string, delimiter string) ([]string, error) {
 	container, err := getContainerReference(o.blobClient, bucket)
 	if err != nil {
@@ -93,16 +149,16 @@ func (o *objectStore) ListCommonPrefixes(bucket string, delimiter string) ([]str
 		Delimiter: delimiter,
 	}
 
-	res, err := container.ListBlobs(params)
+	var ret []string
+	err = listBlobPages(container, params, func(res storage.BlobListResponse) {
+		// Azure returns prefixes inclusive of the last delimiter. We need to strip
+		// it.
+		for _, prefix := range res.BlobPrefixes {
+			ret = append(ret, prefix[0:strings.LastIndex(prefix, delimiter)])
+		}
+	})
 	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-
-	// Azure returns prefixes inclusive of the last delimiter. We need to strip
-	// it.
-	ret := make([]string, 0, len(res.BlobPrefixes))
-	for _, prefix := range res.BlobPrefixes {
-		ret = append(ret, prefix[0:strings.LastIndex(prefix, delimiter)])
+		return nil, err
 	}
 
 	return ret, nil
@@ -118,19 +174,38 @@ func (o *objectStore) ListObjects(bucket, prefix string) ([]string, error) {
 		Prefix: prefix,
 	}
 
-	res, err := container.ListBlobs(params)
+	var ret []string
+	err = listBlobPages(container, params, func(res storage.BlobListResponse) {
+		for _, blob := range res.Blobs {
+			ret = append(ret, blob.Name)
+		}
+	})
 	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-
-	ret := make([]string, 0, len(res.Blobs))
-	for _, blob := range res.Blobs {
-		ret = append(ret, blob.Name)
+		return nil, err
 	}
 
 	return ret, nil
 }
 
+// listBlobPages lists the blobs in container that match params, calling fn with each
+// page of results. Azure returns at most 5000 results per request, so large containers
+// must be listed using the continuation marker returned with each page.
+func listBlobPages(container *storage.Container, params storage.ListBlobsParameters, fn func(storage.BlobListResponse)) error {
+	for {
+		res, err := container.ListBlobs(params)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		fn(res)
+
+		if res.NextMarker == "" {
+			return nil
+		}
+		params.Marker = res.NextMarker
+	}
+}
+
 func (o *objectStore) DeleteObject(bucket string, key string) error {
 	container, err := getContainerReference(o.blobClient, bucket)
 	if err != nil {
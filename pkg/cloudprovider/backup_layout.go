@@ -0,0 +1,139 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+const (
+	// PathTemplateCluster is replaced in a path template with the name of the
+	// cluster Ark is running in.
+	PathTemplateCluster = "{cluster}"
+
+	// PathTemplateSchedule is replaced in a path template with the name of the
+	// schedule that created the backup, or unscheduledDir if there isn't one.
+	PathTemplateSchedule = "{schedule}"
+
+	// PathTemplateBackup is replaced in a path template with the backup's name.
+	PathTemplateBackup = "{backup}"
+
+	// unscheduledDir is used in place of {schedule} for backups that weren't
+	// created by a schedule.
+	unscheduledDir = "unscheduled"
+)
+
+var pathTemplateVariable = regexp.MustCompile(`\{[^}]*\}`)
+
+// ValidatePathTemplate returns an error if template is not a valid path template
+// for the directories that backups are stored in. A valid template is made up of
+// '/'-separated segments, each of which is either a literal or one of the
+// {cluster}, {schedule}, and {backup} variables, and ends with {backup}.
+func ValidatePathTemplate(template, clusterName string) error {
+	segments := strings.Split(template, "/")
+
+	for i, segment := range segments {
+		if segment == "" {
+			return errors.Errorf("path template %q must not contain empty segments", template)
+		}
+
+		for _, variable := range pathTemplateVariable.FindAllString(segment, -1) {
+			switch variable {
+			case PathTemplateCluster:
+				if clusterName == "" {
+					return errors.Errorf("path template %q uses %s, but clusterName is not set", template, PathTemplateCluster)
+				}
+			case PathTemplateSchedule:
+			case PathTemplateBackup:
+				if i != len(segments)-1 || segment != PathTemplateBackup {
+					return errors.Errorf("path template %q may only use %s as its last segment", template, PathTemplateBackup)
+				}
+			default:
+				return errors.Errorf("path template %q uses unknown variable %s", template, variable)
+			}
+		}
+	}
+
+	if segments[len(segments)-1] != PathTemplateBackup {
+		return errors.Errorf("path template %q must end with %s", template, PathTemplateBackup)
+	}
+
+	return nil
+}
+
+// backupLayout determines the directories in object storage that backups are stored in.
+type backupLayout struct {
+	pathTemplate string
+	clusterName  string
+}
+
+// dirFor returns the directory that the backup's files are stored in.
+func (l *backupLayout) dirFor(backup *api.Backup) string {
+	schedule := backup.Labels[api.ScheduleLabel]
+	if schedule == "" {
+		schedule = unscheduledDir
+	}
+
+	return strings.NewReplacer(
+		PathTemplateCluster, l.clusterName,
+		PathTemplateSchedule, schedule,
+		PathTemplateBackup, backup.Name,
+	).Replace(l.pathTemplate)
+}
+
+// segmentPattern returns a regular expression matching the values that a path template
+// segment can take.
+func (l *backupLayout) segmentPattern(segment string) string {
+	var pattern string
+	for {
+		loc := pathTemplateVariable.FindStringIndex(segment)
+		if loc == nil {
+			return pattern + regexp.QuoteMeta(segment)
+		}
+
+		pattern += regexp.QuoteMeta(segment[:loc[0]])
+		if segment[loc[0]:loc[1]] == PathTemplateCluster {
+			pattern += regexp.QuoteMeta(l.clusterName)
+		} else {
+			pattern += "[^/]+"
+		}
+		segment = segment[loc[1]:]
+	}
+}
+
+// topLevelDirPattern returns a regular expression matching the top-level directories in
+// object storage that can hold backups stored under the path template.
+func (l *backupLayout) topLevelDirPattern() *regexp.Regexp {
+	segments := strings.Split(l.pathTemplate, "/")
+	return regexp.MustCompile("^" + l.segmentPattern(segments[0]) + "$")
+}
+
+// dirPattern returns a regular expression matching the backup directories that the path
+// template can give.
+func (l *backupLayout) dirPattern() *regexp.Regexp {
+	segments := strings.Split(l.pathTemplate, "/")
+	patterns := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		patterns = append(patterns, l.segmentPattern(segment))
+	}
+	return regexp.MustCompile("^" + strings.Join(patterns, "/") + "$")
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestValidatePathTemplate(t *testing.T) {
+	tests := []struct {
+		name          string
+		template      string
+		clusterName   string
+		expectedError string
+	}{
+		{
+			name:     "backup only is valid",
+			template: "{backup}",
+		},
+		{
+			name:        "all variables and literals are valid",
+			template:    "ark/{cluster}/{schedule}/{backup}",
+			clusterName: "prod",
+		},
+		{
+			name:          "cluster without a cluster name is invalid",
+			template:      "{cluster}/{backup}",
+			expectedError: `path template "{cluster}/{backup}" uses {cluster}, but clusterName is not set`,
+		},
+		{
+			name:          "template not ending with backup is invalid",
+			template:      "{schedule}",
+			expectedError: `path template "{schedule}" must end with {backup}`,
+		},
+		{
+			name:          "backup before the last segment is invalid",
+			template:      "{backup}/{schedule}/{backup}",
+			expectedError: `path template "{backup}/{schedule}/{backup}" may only use {backup} as its last segment`,
+		},
+		{
+			name:          "backup combined with a literal is invalid",
+			template:      "{schedule}/backup-{backup}",
+			expectedError: `path template "{schedule}/backup-{backup}" may only use {backup} as its last segment`,
+		},
+		{
+			name:          "unknown variable is invalid",
+			template:      "{namespace}/{backup}",
+			expectedError: `path template "{namespace}/{backup}" uses unknown variable {namespace}`,
+		},
+		{
+			name:          "empty segment is invalid",
+			template:      "/{backup}",
+			expectedError: `path template "/{backup}" must not contain empty segments`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidatePathTemplate(test.template, test.clusterName)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestBackupLayoutDirFor(t *testing.T) {
+	layout := &backupLayout{pathTemplate: "ark/{cluster}/{schedule}/{backup}", clusterName: "prod"}
+
+	scheduled := arktest.NewTestBackup().WithName("daily-20180101").WithLabel(api.ScheduleLabel, "daily").Backup
+	assert.Equal(t, "ark/prod/daily/daily-20180101", layout.dirFor(scheduled))
+
+	unscheduled := arktest.NewTestBackup().WithName("backup-1").Backup
+	assert.Equal(t, "ark/prod/unscheduled/backup-1", layout.dirFor(unscheduled))
+}
@@ -17,20 +17,35 @@ limitations under the License.
 package cloudprovider
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/backup/largeitem"
+	"github.com/heptio/ark/pkg/backup/shard"
 	"github.com/heptio/ark/pkg/generated/clientset/versioned/scheme"
+	"github.com/heptio/ark/pkg/util/logchunk"
 )
 
 // BackupService contains methods for working with backups in object storage.
@@ -39,28 +54,58 @@ type BackupService interface {
 	// UploadBackup uploads the specified Ark backup of a set of Kubernetes API objects, whose manifests are
 	// stored in the specified file, into object storage in an Ark bucket, tagged with Ark metadata. Returns
 	// an error if a problem is encountered accessing the file or performing the upload via the cloud API.
-	UploadBackup(bucket, name string, metadata, backup, log io.Reader) error
+	// If the backup's spec sets ShardBy, its contents are split into shards, which are uploaded separately
+	// along with a manifest listing them.
+	UploadBackup(bucket string, backup *api.Backup, metadata, backupFile, log io.Reader) error
 
-	// DownloadBackup downloads an Ark backup with the specified object key from object storage via the cloud API.
-	// It returns the snapshot metadata and data (separately), or an error if a problem is encountered
-	// downloading or reading the file from the cloud API.
-	DownloadBackup(bucket, name string) (io.ReadCloser, error)
+	// DownloadBackup downloads the contents of an Ark backup from object storage via the cloud API, as a
+	// gzipped tarball. The contents of a sharded backup are combined from each of its shards that include
+	// returns true for, or all of them if include is nil. It returns an error if a problem is encountered
+	// downloading the file, or the backup's manifest, from the cloud API.
+	DownloadBackup(bucket string, backup *api.Backup, include func(shard.Shard) bool) (io.ReadCloser, error)
 
-	// DeleteBackupDir deletes all files in object storage for the given backup.
-	DeleteBackupDir(bucket, backupName string) error
+	// DeleteBackupDir deletes all files in object storage for the given backup. It returns the
+	// result of deleting each of them, along with an error if any couldn't be deleted.
+	DeleteBackupDir(bucket, backupName string) ([]api.ArtifactDeletionResult, error)
+
+	// ListBackupDirs lists the names of all backup directories in object storage, whether or
+	// not they contain valid backup metadata.
+	ListBackupDirs(bucket string) ([]string, error)
 
 	// GetBackup gets the specified api.Backup from the given bucket in object storage.
 	GetBackup(bucket, name string) (*api.Backup, error)
 
-	// CreateSignedURL creates a pre-signed URL that can be used to download a file from object
-	// storage. The URL expires after ttl.
-	CreateSignedURL(target api.DownloadTarget, bucket, directory string, ttl time.Duration) (string, error)
+	// BackupMetadataExists returns whether the named backup's metadata file exists in object
+	// storage. Unlike an error from GetBackup, a false result confirms the file is missing.
+	BackupMetadataExists(bucket, backupName string) (bool, error)
+
+	// ForEachBackup calls fn with each api.Backup in object storage for the given bucket,
+	// one at a time, so that buckets with many backups can be processed without holding
+	// all of them in memory. Backup directories without valid metadata are skipped. If fn
+	// returns an error, iteration stops and that error is returned.
+	ForEachBackup(bucket string, fn func(*api.Backup) error) error
+
+	// CreateSignedURL creates a pre-signed URL that can be used to download a file belonging to
+	// the named backup from object storage. The URL expires after ttl.
+	CreateSignedURL(target api.DownloadTarget, bucket, backupName string, ttl time.Duration) (string, error)
 
 	// UploadRestoreLog uploads the restore's log file to object storage.
 	UploadRestoreLog(bucket, backup, restore string, log io.Reader) error
 
 	// UploadRestoreResults uploads the restore's results file to object storage.
 	UploadRestoreResults(bucket, backup, restore string, results io.Reader) error
+
+	// UploadRestorePlan uploads a dry-run restore's plan file to object storage.
+	UploadRestorePlan(bucket, backup, restore string, plan io.Reader) error
+
+	// UploadAuditLog uploads a file of audit log entries to object storage. Audit logs are
+	// stored outside of any backup's directory, so they outlive the backups they describe.
+	UploadAuditLog(bucket, name string, log io.Reader) error
+
+	// ValidateAccess checks that the bucket exists and that objects can be listed in it and,
+	// unless readOnly is true, written to and deleted from it, so misconfigured storage is found
+	// before a backup fails.
+	ValidateAccess(bucket string, readOnly bool) error
 }
 
 // BackupGetter knows how to list backups in object storage.
@@ -72,9 +117,36 @@ type BackupGetter interface {
 const (
 	metadataFileFormatString       = "%s/ark-backup.json"
 	backupFileFormatString         = "%s/%s.tar.gz"
+	backupManifestFormatString     = "%s/%s-manifest.json"
+	backupShardFileFormatString    = "%s/%s-%s.tar.gz"
+	largeItemsPrefixFormatString   = "%s/%s/"
+	largeItemsIndexFormatString    = "%s/%s-large-items-index.json"
 	backupLogFileFormatString      = "%s/%s-logs.gz"
+	backupLogChunkFormatString     = "%s/%s-logs-%d.gz"
+	backupLogIndexFormatString     = "%s/%s-logs-index.json"
 	restoreLogFileFormatString     = "%s/restore-%s-logs.gz"
+	restoreLogChunkFormatString    = "%s/restore-%s-logs-%d.gz"
+	restoreLogIndexFormatString    = "%s/restore-%s-logs-index.json"
 	restoreResultsFileFormatString = "%s/restore-%s-results.gz"
+	restorePlanFileFormatString    = "%s/restore-%s-plan.gz"
+	auditLogFileFormatString       = "%s/%s.gz"
+
+	// auditLogDir is the top-level directory audit logs are stored in. It isn't a backup
+	// directory, so it's never listed as one. Its leading dot keeps it from colliding with
+	// a backup's directory, since backup names must start with a letter or digit.
+	auditLogDir = ".ark-audit"
+
+	// accessCheckKey is the object written and deleted by ValidateAccess. It's at the top
+	// level of the bucket, outside of any directory, so it's never listed as a backup.
+	accessCheckKey = ".ark-access-check"
+
+	// metadataFileName is the name of the file in each backup directory that holds the
+	// backup's metadata.
+	metadataFileName = "ark-backup.json"
+
+	// minBackupDirsRefreshInterval is the shortest time between listings of a bucket's
+	// backup directories when looking up a backup that isn't in the cache.
+	minBackupDirsRefreshInterval = time.Minute
 )
 
 func getMetadataKey(directory string) string {
@@ -85,33 +157,332 @@ func getBackupContentsKey(directory, backup string) string {
 	return fmt.Sprintf(backupFileFormatString, directory, backup)
 }
 
+func getBackupManifestKey(directory, backup string) string {
+	return fmt.Sprintf(backupManifestFormatString, directory, backup)
+}
+
+func getBackupShardKey(directory, backup, shard string) string {
+	return fmt.Sprintf(backupShardFileFormatString, directory, backup, shard)
+}
+
+func getLargeItemsPrefix(directory string) string {
+	return fmt.Sprintf(largeItemsPrefixFormatString, directory, api.LargeItemsDir)
+}
+
+func getLargeItemKey(directory, itemPath string) string {
+	return getLargeItemsPrefix(directory) + itemPath
+}
+
+func getLargeItemsIndexKey(directory, backup string) string {
+	return fmt.Sprintf(largeItemsIndexFormatString, directory, backup)
+}
+
 func getBackupLogKey(directory, backup string) string {
 	return fmt.Sprintf(backupLogFileFormatString, directory, backup)
 }
 
+func getBackupLogChunkKey(directory, backup string, chunk int) string {
+	return fmt.Sprintf(backupLogChunkFormatString, directory, backup, chunk)
+}
+
+func getBackupLogIndexKey(directory, backup string) string {
+	return fmt.Sprintf(backupLogIndexFormatString, directory, backup)
+}
+
 func getRestoreLogKey(directory, restore string) string {
 	return fmt.Sprintf(restoreLogFileFormatString, directory, restore)
 }
 
+func getRestoreLogChunkKey(directory, restore string, chunk int) string {
+	return fmt.Sprintf(restoreLogChunkFormatString, directory, restore, chunk)
+}
+
+func getRestoreLogIndexKey(directory, restore string) string {
+	return fmt.Sprintf(restoreLogIndexFormatString, directory, restore)
+}
+
 func getRestoreResultsKey(directory, restore string) string {
 	return fmt.Sprintf(restoreResultsFileFormatString, directory, restore)
 }
 
+func getRestorePlanKey(directory, restore string) string {
+	return fmt.Sprintf(restorePlanFileFormatString, directory, restore)
+}
+
+func getAuditLogKey(name string) string {
+	return fmt.Sprintf(auditLogFileFormatString, auditLogDir, name)
+}
+
 type backupService struct {
 	objectStore ObjectStore
 	decoder     runtime.Decoder
 	logger      logrus.FieldLogger
+
+	// layout is nil if backups are stored in top-level directories named after them.
+	layout *backupLayout
+
+	// logChunkSizeBytes and maxLogSizeBytes are the sizes above which backup and restore
+	// logs are split into chunks, and truncated, respectively. Zero means no limit.
+	logChunkSizeBytes int64
+	maxLogSizeBytes   int64
+
+	// dirsLock guards dirs, which maps each bucket's backup names to the directories
+	// they're stored in when layout is set.
+	dirsLock sync.Mutex
+	dirs     map[string]map[string]string
+
+	// dirsRefreshed maps each bucket to the time its backup directories were last listed,
+	// and is guarded by dirsLock.
+	dirsRefreshed map[string]time.Time
+	clock         clock.Clock
+
+	// metadataLock guards metadata, which maps each bucket's metadata keys to the most
+	// recently downloaded version of the backup metadata stored there, so that metadata
+	// that hasn't changed doesn't need to be downloaded and decoded again.
+	metadataLock sync.Mutex
+	metadata     map[string]map[string]cachedBackupMetadata
+}
+
+// cachedBackupMetadata is a decoded backup metadata file and the ETag it had when it
+// was downloaded.
+type cachedBackupMetadata struct {
+	etag   string
+	backup *api.Backup
 }
 
 var _ BackupService = &backupService{}
 var _ BackupGetter = &backupService{}
 
+// BackupServiceConfig holds the settings of a backup service created by
+// NewBackupServiceWithConfig.
+type BackupServiceConfig struct {
+	// PathTemplate, if set, gives the directories that new backups are stored in (see
+	// ValidatePathTemplate). Otherwise, they're stored in top-level directories named
+	// after them.
+	PathTemplate string
+
+	// ClusterName is the name of the cluster Ark is running in, for use in PathTemplate.
+	ClusterName string
+
+	// LogChunkSizeBytes is the size above which backup and restore logs are split into
+	// chunks, which are stored along with an index listing them. Zero means logs aren't
+	// split.
+	LogChunkSizeBytes int64
+
+	// MaxLogSizeBytes is the size above which backup and restore logs are truncated.
+	// Zero means logs of any size are stored.
+	MaxLogSizeBytes int64
+}
+
 // NewBackupService creates a backup service using the provided object store
 func NewBackupService(objectStore ObjectStore, logger logrus.FieldLogger) BackupService {
-	return &backupService{
-		objectStore: objectStore,
-		decoder:     scheme.Codecs.UniversalDecoder(api.SchemeGroupVersion),
-		logger:      logger,
+	return NewBackupServiceWithConfig(objectStore, BackupServiceConfig{}, logger)
+}
+
+// NewBackupServiceWithPathTemplate creates a backup service using the provided object store
+// that stores new backups in the directories given by pathTemplate (see ValidatePathTemplate).
+// Backups stored in other directories, including the top-level directories used by
+// NewBackupService, can still be read and deleted.
+func NewBackupServiceWithPathTemplate(objectStore ObjectStore, pathTemplate, clusterName string, logger logrus.FieldLogger) BackupService {
+	return NewBackupServiceWithConfig(objectStore, BackupServiceConfig{PathTemplate: pathTemplate, ClusterName: clusterName}, logger)
+}
+
+// NewBackupServiceWithConfig creates a backup service using the provided object store,
+// configured by config.
+func NewBackupServiceWithConfig(objectStore ObjectStore, config BackupServiceConfig, logger logrus.FieldLogger) BackupService {
+	service := &backupService{
+		objectStore:       objectStore,
+		decoder:           scheme.Codecs.UniversalDecoder(api.SchemeGroupVersion),
+		logger:            logger,
+		logChunkSizeBytes: config.LogChunkSizeBytes,
+		maxLogSizeBytes:   config.MaxLogSizeBytes,
+		metadata:          make(map[string]map[string]cachedBackupMetadata),
+	}
+
+	if config.PathTemplate != "" {
+		service.layout = &backupLayout{
+			pathTemplate: config.PathTemplate,
+			clusterName:  config.ClusterName,
+		}
+		service.dirs = make(map[string]map[string]string)
+		service.dirsRefreshed = make(map[string]time.Time)
+		service.clock = clock.RealClock{}
+	}
+
+	return service
+}
+
+// backupDir returns the directory in object storage that the named backup's files are
+// stored in.
+func (br *backupService) backupDir(bucket, backupName string) string {
+	if br.layout == nil {
+		return backupName
+	}
+
+	if dir, ok := br.cachedBackupDir(bucket, backupName); ok {
+		return dir
+	}
+
+	if !br.shouldRefreshBackupDirs(bucket) {
+		return backupName
+	}
+
+	if _, err := br.refreshBackupDirs(bucket); err != nil {
+		br.logger.WithError(err).WithField("bucket", bucket).Error("Error listing backup directories")
+	}
+
+	if dir, ok := br.cachedBackupDir(bucket, backupName); ok {
+		return dir
+	}
+
+	// the backup doesn't exist yet, or it was stored before the path template was configured
+	return backupName
+}
+
+func (br *backupService) cachedBackupDir(bucket, backupName string) (string, bool) {
+	br.dirsLock.Lock()
+	defer br.dirsLock.Unlock()
+
+	dir, ok := br.dirs[bucket][backupName]
+	return dir, ok
+}
+
+func (br *backupService) setCachedBackupDir(bucket, backupName, dir string) {
+	br.dirsLock.Lock()
+	defer br.dirsLock.Unlock()
+
+	if br.dirs[bucket] == nil {
+		br.dirs[bucket] = make(map[string]string)
+	}
+	br.dirs[bucket][backupName] = dir
+}
+
+func (br *backupService) deleteCachedBackupDir(bucket, backupName string) {
+	br.dirsLock.Lock()
+	defer br.dirsLock.Unlock()
+
+	delete(br.dirs[bucket], backupName)
+}
+
+// refreshBackupDirs finds the backup directories in the bucket and returns the sorted names
+// of the backups stored in them. Top-level directories that can't hold backups stored under
+// the path template are backups stored before it was configured; the others are searched for
+// directories that match the template and contain backup metadata. A backup is named after
+// its directory's last path segment.
+func (br *backupService) refreshBackupDirs(bucket string) ([]string, error) {
+	br.dirsLock.Lock()
+	br.dirsRefreshed[bucket] = br.clock.Now()
+	br.dirsLock.Unlock()
+
+	prefixes, err := br.objectStore.ListCommonPrefixes(bucket, "/")
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		topLevelDirPattern = br.layout.topLevelDirPattern()
+		dirPattern         = br.layout.dirPattern()
+		dirs               = make(map[string]string)
+	)
+
+	addDir := func(dir string) {
+		name := path.Base(dir)
+		if existing, ok := dirs[name]; ok && existing != dir {
+			br.logger.WithFields(logrus.Fields{
+				"bucket":  bucket,
+				"backup":  name,
+				"dir":     dir,
+				"usedDir": existing,
+			}).Warn("Found more than one directory for backup")
+			return
+		}
+		dirs[name] = dir
+	}
+
+	for _, prefix := range prefixes {
+		if prefix == auditLogDir {
+			continue
+		}
+
+		if !topLevelDirPattern.MatchString(prefix) {
+			addDir(prefix)
+			continue
+		}
+
+		keys, err := br.objectStore.ListObjects(bucket, prefix+"/")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if path.Base(key) != metadataFileName {
+				continue
+			}
+
+			if dir := path.Dir(key); dir == prefix || dirPattern.MatchString(dir) {
+				addDir(dir)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(dirs))
+	for name := range dirs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	br.dirsLock.Lock()
+	br.dirs[bucket] = dirs
+	br.dirsLock.Unlock()
+
+	return names, nil
+}
+
+// shouldRefreshBackupDirs returns true if the bucket's backup directories weren't listed
+// within the last minBackupDirsRefreshInterval, so that looking up backups that don't exist
+// doesn't list the bucket every time.
+func (br *backupService) shouldRefreshBackupDirs(bucket string) bool {
+	br.dirsLock.Lock()
+	defer br.dirsLock.Unlock()
+
+	refreshed, ok := br.dirsRefreshed[bucket]
+	return !ok || br.clock.Since(refreshed) >= minBackupDirsRefreshInterval
+}
+
+func (br *backupService) cachedMetadata(bucket, key string) cachedBackupMetadata {
+	br.metadataLock.Lock()
+	defer br.metadataLock.Unlock()
+
+	return br.metadata[bucket][key]
+}
+
+func (br *backupService) setCachedMetadata(bucket, key string, metadata cachedBackupMetadata) {
+	br.metadataLock.Lock()
+	defer br.metadataLock.Unlock()
+
+	if br.metadata[bucket] == nil {
+		br.metadata[bucket] = make(map[string]cachedBackupMetadata)
+	}
+	br.metadata[bucket][key] = metadata
+}
+
+func (br *backupService) deleteCachedMetadata(bucket, key string) {
+	br.metadataLock.Lock()
+	defer br.metadataLock.Unlock()
+
+	delete(br.metadata[bucket], key)
+}
+
+// pruneCachedMetadata removes the cached metadata for every key in the bucket that
+// isn't in keys.
+func (br *backupService) pruneCachedMetadata(bucket string, keys map[string]bool) {
+	br.metadataLock.Lock()
+	defer br.metadataLock.Unlock()
+
+	for key := range br.metadata[bucket] {
+		if !keys[key] {
+			delete(br.metadata[bucket], key)
+		}
 	}
 }
 
@@ -137,11 +508,20 @@ func (br *backupService) seekAndPutObject(bucket, key string, file io.Reader) er
 	return br.objectStore.PutObject(bucket, key, file)
 }
 
-func (br *backupService) UploadBackup(bucket, backupName string, metadata, backup, log io.Reader) error {
+func (br *backupService) UploadBackup(bucket string, backup *api.Backup, metadata, backupFile, log io.Reader) error {
+	backupName := backup.Name
+
+	dir := backupName
+	if br.layout != nil {
+		dir = br.layout.dirFor(backup)
+		br.setCachedBackupDir(bucket, backupName, dir)
+	}
+
 	// Uploading the log file is best-effort; if it fails, we log the error but it doesn't impact the
 	// backup's status.
-	logKey := getBackupLogKey(backupName, backupName)
-	if err := br.seekAndPutObject(bucket, logKey, log); err != nil {
+	logKey := getBackupLogKey(dir, backupName)
+	chunkKey := func(chunk int) string { return getBackupLogChunkKey(dir, backupName, chunk) }
+	if err := br.putLog(bucket, logKey, getBackupLogIndexKey(dir, backupName), chunkKey, log); err != nil {
 		br.logger.WithError(err).WithFields(logrus.Fields{
 			"bucket": bucket,
 			"key":    logKey,
@@ -156,15 +536,14 @@ func (br *backupService) UploadBackup(bucket, backupName string, metadata, backu
 	}
 
 	// upload metadata file
-	metadataKey := getMetadataKey(backupName)
+	metadataKey := getMetadataKey(dir)
 	if err := br.seekAndPutObject(bucket, metadataKey, metadata); err != nil {
 		// failure to upload metadata file is a hard-stop
 		return err
 	}
 
-	if backup != nil {
-		// upload tar file
-		if err := br.seekAndPutObject(bucket, getBackupContentsKey(backupName, backupName), backup); err != nil {
+	if backupFile != nil {
+		if err := br.uploadContents(bucket, dir, backup, backupFile); err != nil {
 			// try to delete the metadata file since the data upload failed
 			deleteErr := br.objectStore.DeleteObject(bucket, metadataKey)
 
@@ -175,38 +554,361 @@ func (br *backupService) UploadBackup(bucket, backupName string, metadata, backu
 	return nil
 }
 
-func (br *backupService) DownloadBackup(bucket, backupName string) (io.ReadCloser, error) {
-	return br.objectStore.GetObject(bucket, getBackupContentsKey(backupName, backupName))
+// putLog uploads the gzipped log to key. If the log is larger than the service's log chunk
+// size, it's split into chunks that are uploaded to the keys returned by chunkKey, followed by
+// an index listing them, which is uploaded to indexKey. If it's larger than the service's
+// maximum log size, it's truncated.
+func (br *backupService) putLog(bucket, key, indexKey string, chunkKey func(int) string, log io.Reader) error {
+	if log == nil {
+		return nil
+	}
+
+	if br.logChunkSizeBytes <= 0 && br.maxLogSizeBytes <= 0 {
+		return br.seekAndPutObject(bucket, key, log)
+	}
+
+	if err := seekToBeginning(log); err != nil {
+		return errors.WithStack(err)
+	}
+
+	index, files, err := logchunk.Split(log, br.logChunkSizeBytes, br.maxLogSizeBytes)
+	if err != nil {
+		return errors.WithMessage(err, "error splitting log into chunks")
+	}
+	defer func() {
+		for _, file := range files {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	// a log that fits in a single chunk is stored just like one that isn't split
+	if index.Chunks == 1 && index.TruncatedBytes == 0 {
+		return br.seekAndPutObject(bucket, key, files[0])
+	}
+
+	for i, file := range files {
+		if err := br.seekAndPutObject(bucket, chunkKey(i+1), file); err != nil {
+			return err
+		}
+	}
+
+	indexJSON, err := json.Marshal(index)
+	if err != nil {
+		return errors.Wrap(err, "error encoding log index")
+	}
+
+	return br.objectStore.PutObject(bucket, indexKey, bytes.NewReader(indexJSON))
+}
+
+// uploadContents uploads the backup's contents, as a tarball or as shards according to its
+// ShardBy, after storing any offloaded items as separate objects.
+func (br *backupService) uploadContents(bucket, dir string, backup *api.Backup, backupFile io.Reader) error {
+	if largeitem.HasOffloaded(backup) {
+		remaining, err := br.uploadLargeItems(bucket, dir, backup.Name, backupFile)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			remaining.Close()
+			os.Remove(remaining.Name())
+		}()
+
+		backupFile = remaining
+	}
+
+	if backup.Spec.ShardBy != "" {
+		return br.uploadShards(bucket, dir, backup, backupFile)
+	}
+
+	// upload tar file
+	return br.seekAndPutObject(bucket, getBackupContentsKey(dir, backup.Name), backupFile)
+}
+
+// largeItemPath returns the path that a file in a backup's large items directory would
+// have had otherwise, and whether it's in that directory at all.
+func largeItemPath(name string) (string, bool) {
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(name)), "/")
+
+	// [clusters/<cluster>/]large-items/resources/...
+	i := 0
+	if len(parts) > 2 && parts[0] == api.ClustersDir {
+		i = 2
+	}
+	if len(parts) < i+2 || parts[i] != api.LargeItemsDir {
+		return "", false
+	}
+
+	return path.Join(append(parts[:i:i], parts[i+1:]...)...), true
+}
+
+// uploadLargeItems streams each file in the large items directory of the backup's contents
+// to a separate object in object storage, followed by the index listing them, and returns a
+// temp file, which the caller must remove, with the rest of the backup's contents.
+func (br *backupService) uploadLargeItems(bucket, dir, backupName string, backupFile io.Reader) (*os.File, error) {
+	if err := seekToBeginning(backupFile); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	gzr, err := gzip.NewReader(backupFile)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer gzr.Close()
+
+	remaining, err := ioutil.TempFile("", "")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	index, err := br.splitLargeItems(bucket, dir, tar.NewReader(gzr), remaining)
+	if err == nil {
+		err = br.putLargeItemsIndex(bucket, dir, backupName, index)
+	}
+	if err != nil {
+		remaining.Close()
+		os.Remove(remaining.Name())
+		return nil, err
+	}
+
+	return remaining, nil
+}
+
+func (br *backupService) splitLargeItems(bucket, dir string, tr *tar.Reader, remaining io.Writer) (*largeitem.Index, error) {
+	index := new(largeitem.Index)
+
+	gzw := gzip.NewWriter(remaining)
+	tw := tar.NewWriter(gzw)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if itemPath, ok := largeItemPath(header.Name); ok {
+			if err := br.objectStore.PutObject(bucket, getLargeItemKey(dir, itemPath), tr); err != nil {
+				return nil, errors.WithMessage(err, "error uploading large item "+itemPath)
+			}
+			index.Items = append(index.Items, itemPath)
+			continue
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return index, errors.WithStack(gzw.Close())
+}
+
+// putLargeItemsIndex uploads the index of a backup's offloaded items, which clients use to
+// download them along with the backup's contents.
+func (br *backupService) putLargeItemsIndex(bucket, dir, backupName string, index *largeitem.Index) error {
+	indexJSON, err := json.Marshal(index)
+	if err != nil {
+		return errors.Wrap(err, "error encoding large items index")
+	}
+
+	return br.objectStore.PutObject(bucket, getLargeItemsIndexKey(dir, backupName), bytes.NewReader(indexJSON))
+}
+
+// mergeLargeItems writes a gzipped tarball to w containing the files in contents, a backup's
+// gzipped tarball, and the backup's offloaded items, at the paths they would have had if they
+// hadn't been offloaded.
+func (br *backupService) mergeLargeItems(w io.Writer, bucket, dir string, contents io.ReadCloser) error {
+	prefix := getLargeItemsPrefix(dir)
+	keys, err := br.objectStore.ListObjects(bucket, prefix)
+	if err != nil {
+		contents.Close()
+		return errors.WithMessage(err, "error listing large items")
+	}
+
+	items := make([]string, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, strings.TrimPrefix(key, prefix))
+	}
+
+	return largeitem.Merge(w, contents, items, func(item string) (io.ReadCloser, error) {
+		return br.objectStore.GetObject(bucket, getLargeItemKey(dir, item))
+	})
+}
+
+// uploadShards splits the backup's contents into shards, and uploads each of them
+// followed by the manifest listing them.
+func (br *backupService) uploadShards(bucket, dir string, backup *api.Backup, backupFile io.Reader) error {
+	if err := seekToBeginning(backupFile); err != nil {
+		return errors.WithStack(err)
+	}
+
+	manifest, files, err := shard.Split(backupFile, backup.Spec.ShardBy)
+	if err != nil {
+		return errors.WithMessage(err, "error splitting backup into shards")
+	}
+	defer func() {
+		for _, file := range files {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	for _, s := range manifest.Shards {
+		if err := br.seekAndPutObject(bucket, getBackupShardKey(dir, backup.Name, s.Name), files[s.Name]); err != nil {
+			return err
+		}
+	}
+
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "error encoding backup manifest")
+	}
+
+	return br.objectStore.PutObject(bucket, getBackupManifestKey(dir, backup.Name), bytes.NewReader(manifestJSON))
+}
+
+func (br *backupService) DownloadBackup(bucket string, backup *api.Backup, include func(shard.Shard) bool) (io.ReadCloser, error) {
+	dir := br.backupDir(bucket, backup.Name)
+
+	contents, err := br.downloadContents(bucket, dir, backup, include)
+	if err != nil || !largeitem.HasOffloaded(backup) {
+		return contents, err
+	}
+
+	// offloaded items are added to the end of the backup's contents as they're read
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(br.mergeLargeItems(pw, bucket, dir, contents))
+	}()
+
+	return pr, nil
+}
+
+func (br *backupService) downloadContents(bucket, dir string, backup *api.Backup, include func(shard.Shard) bool) (io.ReadCloser, error) {
+	if backup.Spec.ShardBy == "" {
+		return br.objectStore.GetObject(bucket, getBackupContentsKey(dir, backup.Name))
+	}
+
+	manifest, err := br.getManifest(bucket, dir, backup.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var shards []shard.Shard
+	for _, s := range manifest.Shards {
+		if include == nil || include(s) {
+			shards = append(shards, s)
+		}
+	}
+
+	// the shards are combined as they're read, so closing the reader early stops the
+	// remaining shards from being downloaded.
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(shard.Merge(pw, shards, func(s shard.Shard) (io.ReadCloser, error) {
+			return br.objectStore.GetObject(bucket, getBackupShardKey(dir, backup.Name, s.Name))
+		}))
+	}()
+
+	return pr, nil
+}
+
+func (br *backupService) getManifest(bucket, dir, backupName string) (*shard.Manifest, error) {
+	res, err := br.objectStore.GetObject(bucket, getBackupManifestKey(dir, backupName))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	manifest := new(shard.Manifest)
+	if err := json.NewDecoder(res).Decode(manifest); err != nil {
+		return nil, errors.Wrap(err, "error decoding backup manifest")
+	}
+
+	return manifest, nil
 }
 
 func (br *backupService) GetAllBackups(bucket string) ([]*api.Backup, error) {
-	prefixes, err := br.objectStore.ListCommonPrefixes(bucket, "/")
+	output := []*api.Backup{}
+
+	err := br.ForEachBackup(bucket, func(backup *api.Backup) error {
+		output = append(output, backup)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	if len(prefixes) == 0 {
-		return []*api.Backup{}, nil
+
+	return output, nil
+}
+
+func (br *backupService) ForEachBackup(bucket string, fn func(*api.Backup) error) error {
+	prefixes, err := br.ListBackupDirs(bucket)
+	if err != nil {
+		return err
 	}
 
-	output := make([]*api.Backup, 0, len(prefixes))
+	metadataKeys := make(map[string]bool, len(prefixes))
 
 	for _, backupDir := range prefixes {
+		metadataKeys[getMetadataKey(br.backupDir(bucket, backupDir))] = true
+
 		backup, err := br.GetBackup(bucket, backupDir)
 		if err != nil {
 			br.logger.WithError(err).WithField("dir", backupDir).Error("Error reading backup directory")
 			continue
 		}
 
-		output = append(output, backup)
+		if err := fn(backup); err != nil {
+			return err
+		}
 	}
 
-	return output, nil
+	// forget the metadata for backups that no longer exist
+	br.pruneCachedMetadata(bucket, metadataKeys)
+
+	return nil
+}
+
+func (br *backupService) ListBackupDirs(bucket string) ([]string, error) {
+	if br.layout == nil {
+		prefixes, err := br.objectStore.ListCommonPrefixes(bucket, "/")
+		if err != nil {
+			return nil, err
+		}
+
+		dirs := make([]string, 0, len(prefixes))
+		for _, prefix := range prefixes {
+			if prefix != auditLogDir {
+				dirs = append(dirs, prefix)
+			}
+		}
+		return dirs, nil
+	}
+
+	return br.refreshBackupDirs(bucket)
 }
 
 func (br *backupService) GetBackup(bucket, backupName string) (*api.Backup, error) {
-	key := getMetadataKey(backupName)
+	key := getMetadataKey(br.backupDir(bucket, backupName))
+
+	// Only download and decode the metadata if it's changed since it was cached. Object
+	// stores that don't implement ConditionalObjectStore never return an ETag, so their
+	// metadata is never cached.
+	cached := br.cachedMetadata(bucket, key)
 
-	res, err := br.objectStore.GetObject(bucket, key)
+	res, etag, err := GetObjectIfChanged(br.objectStore, bucket, key, cached.etag)
+	if err == ErrObjectNotModified && cached.backup != nil {
+		return cached.backup.DeepCopy(), nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -227,54 +929,204 @@ func (br *backupService) GetBackup(bucket, backupName string) (*api.Backup, erro
 		return nil, errors.Errorf("unexpected type for %s/%s: %T", bucket, key, obj)
 	}
 
+	if etag != "" {
+		br.setCachedMetadata(bucket, key, cachedBackupMetadata{etag: etag, backup: backup.DeepCopy()})
+	}
+
 	return backup, nil
 }
 
-func (br *backupService) DeleteBackupDir(bucket, backupName string) error {
-	objects, err := br.objectStore.ListObjects(bucket, backupName+"/")
+func (br *backupService) BackupMetadataExists(bucket, backupName string) (bool, error) {
+	key := getMetadataKey(br.backupDir(bucket, backupName))
+
+	keys, err := br.objectStore.ListObjects(bucket, key)
 	if err != nil {
-		return err
+		return false, err
+	}
+
+	for _, k := range keys {
+		if k == key {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// artifactFor returns the kind of backup artifact that key, in backupName's directory dir, is.
+func artifactFor(dir, backupName, key string) api.BackupArtifact {
+	switch key {
+	case getMetadataKey(dir):
+		return api.BackupArtifactMetadata
+	case getBackupLogKey(dir, backupName), getBackupLogIndexKey(dir, backupName):
+		return api.BackupArtifactLogs
+	case getBackupContentsKey(dir, backupName), getBackupManifestKey(dir, backupName):
+		return api.BackupArtifactTarball
+	}
+
+	base := path.Base(key)
+	if strings.HasPrefix(base, "restore-") &&
+		(strings.HasSuffix(base, "-logs.gz") || strings.HasSuffix(base, "-results.gz") || strings.HasSuffix(base, "-plan.gz") ||
+			isLogChunkOrIndex(base)) {
+		return api.BackupArtifactRestoreData
+	}
+
+	if strings.HasPrefix(base, backupName+"-logs-") && isLogChunkOrIndex(base) {
+		return api.BackupArtifactLogs
+	}
+
+	// anything else is one of the backup's shards
+	return api.BackupArtifactTarball
+}
+
+// logChunkOrIndexRegexp matches the names of the files that a log that was split into chunks
+// is stored in.
+var logChunkOrIndexRegexp = regexp.MustCompile(`-logs-([0-9]+\.gz|index\.json)$`)
+
+// isLogChunkOrIndex returns whether the file named base is one of a log's chunks, or its index.
+func isLogChunkOrIndex(base string) bool {
+	return logChunkOrIndexRegexp.MatchString(base)
+}
+
+func (br *backupService) DeleteBackupDir(bucket, backupName string) ([]api.ArtifactDeletionResult, error) {
+	dir := br.backupDir(bucket, backupName)
+
+	objects, err := br.objectStore.ListObjects(bucket, dir+"/")
+	if err != nil {
+		return nil, err
 	}
 
-	var errs []error
+	var (
+		results []api.ArtifactDeletionResult
+		errs    []error
+	)
 	for _, key := range objects {
+		if br.layout != nil && path.Dir(key) != dir {
+			// another backup's directory may be nested under this one, e.g. if a backup
+			// has the same name as a cluster in the path template.
+			continue
+		}
+
 		br.logger.WithFields(logrus.Fields{
 			"bucket": bucket,
 			"key":    key,
 		}).Debug("Trying to delete object")
+
+		result := api.ArtifactDeletionResult{
+			Artifact: artifactFor(dir, backupName, key),
+			Name:     key,
+			Phase:    api.ArtifactDeletionPhaseDeleted,
+		}
 		if err := br.objectStore.DeleteObject(bucket, key); err != nil {
 			errs = append(errs, err)
+			result.Phase = api.ArtifactDeletionPhaseFailed
+			result.Error = err.Error()
 		}
+		results = append(results, result)
+	}
+
+	br.deleteCachedMetadata(bucket, getMetadataKey(dir))
+
+	if len(errs) == 0 && br.layout != nil {
+		br.deleteCachedBackupDir(bucket, backupName)
 	}
 
-	return errors.WithStack(kerrors.NewAggregate(errs))
+	return results, errors.WithStack(kerrors.NewAggregate(errs))
 }
 
-func (br *backupService) CreateSignedURL(target api.DownloadTarget, bucket, directory string, ttl time.Duration) (string, error) {
+func (br *backupService) CreateSignedURL(target api.DownloadTarget, bucket, backupName string, ttl time.Duration) (string, error) {
+	directory := br.backupDir(bucket, backupName)
+
 	switch target.Kind {
 	case api.DownloadTargetKindBackupContents:
 		return br.objectStore.CreateSignedURL(bucket, getBackupContentsKey(directory, target.Name), ttl)
+	case api.DownloadTargetKindBackupManifest:
+		return br.objectStore.CreateSignedURL(bucket, getBackupManifestKey(directory, target.Name), ttl)
+	case api.DownloadTargetKindBackupShard:
+		// shard names can't be used to sign URLs for objects outside the backup's directory
+		if target.Shard == "" || strings.Contains(target.Shard, "/") {
+			return "", errors.Errorf("invalid shard name %q", target.Shard)
+		}
+		return br.objectStore.CreateSignedURL(bucket, getBackupShardKey(directory, target.Name, target.Shard), ttl)
+	case api.DownloadTargetKindBackupLargeItemsIndex:
+		return br.objectStore.CreateSignedURL(bucket, getLargeItemsIndexKey(directory, target.Name), ttl)
+	case api.DownloadTargetKindBackupLargeItem:
+		// item paths can't be used to sign URLs for objects outside the backup's large items
+		if !largeitem.ValidPath(target.Item) {
+			return "", errors.Errorf("invalid large item path %q", target.Item)
+		}
+		return br.objectStore.CreateSignedURL(bucket, getLargeItemKey(directory, target.Item), ttl)
 	case api.DownloadTargetKindBackupLog:
+		if target.Chunk < 0 {
+			return "", errors.Errorf("invalid log chunk %d", target.Chunk)
+		}
+		if target.Chunk > 0 {
+			return br.objectStore.CreateSignedURL(bucket, getBackupLogChunkKey(directory, target.Name, target.Chunk), ttl)
+		}
 		return br.objectStore.CreateSignedURL(bucket, getBackupLogKey(directory, target.Name), ttl)
+	case api.DownloadTargetKindBackupLogIndex:
+		return br.objectStore.CreateSignedURL(bucket, getBackupLogIndexKey(directory, target.Name), ttl)
 	case api.DownloadTargetKindRestoreLog:
+		if target.Chunk < 0 {
+			return "", errors.Errorf("invalid log chunk %d", target.Chunk)
+		}
+		if target.Chunk > 0 {
+			return br.objectStore.CreateSignedURL(bucket, getRestoreLogChunkKey(directory, target.Name, target.Chunk), ttl)
+		}
 		return br.objectStore.CreateSignedURL(bucket, getRestoreLogKey(directory, target.Name), ttl)
+	case api.DownloadTargetKindRestoreLogIndex:
+		return br.objectStore.CreateSignedURL(bucket, getRestoreLogIndexKey(directory, target.Name), ttl)
 	case api.DownloadTargetKindRestoreResults:
 		return br.objectStore.CreateSignedURL(bucket, getRestoreResultsKey(directory, target.Name), ttl)
+	case api.DownloadTargetKindRestorePlan:
+		return br.objectStore.CreateSignedURL(bucket, getRestorePlanKey(directory, target.Name), ttl)
 	default:
 		return "", errors.Errorf("unsupported download target kind %q", target.Kind)
 	}
 }
 
 func (br *backupService) UploadRestoreLog(bucket, backup, restore string, log io.Reader) error {
-	key := getRestoreLogKey(backup, restore)
-	return br.objectStore.PutObject(bucket, key, log)
+	dir := br.backupDir(bucket, backup)
+	chunkKey := func(chunk int) string { return getRestoreLogChunkKey(dir, restore, chunk) }
+
+	return br.putLog(bucket, getRestoreLogKey(dir, restore), getRestoreLogIndexKey(dir, restore), chunkKey, log)
 }
 
 func (br *backupService) UploadRestoreResults(bucket, backup, restore string, results io.Reader) error {
-	key := getRestoreResultsKey(backup, restore)
+	key := getRestoreResultsKey(br.backupDir(bucket, backup), restore)
 	return br.objectStore.PutObject(bucket, key, results)
 }
 
+func (br *backupService) UploadRestorePlan(bucket, backup, restore string, plan io.Reader) error {
+	key := getRestorePlanKey(br.backupDir(bucket, backup), restore)
+	return br.objectStore.PutObject(bucket, key, plan)
+}
+
+func (br *backupService) UploadAuditLog(bucket, name string, log io.Reader) error {
+	return br.objectStore.PutObject(bucket, getAuditLogKey(name), log)
+}
+
+func (br *backupService) ValidateAccess(bucket string, readOnly bool) error {
+	if _, err := br.objectStore.ListCommonPrefixes(bucket, "/"); err != nil {
+		return errors.WithMessage(err, "error listing bucket")
+	}
+
+	if readOnly {
+		return nil
+	}
+
+	probe := strings.NewReader(time.Now().UTC().Format(time.RFC3339))
+	if err := br.objectStore.PutObject(bucket, accessCheckKey, probe); err != nil {
+		return errors.WithMessage(err, "error writing to bucket")
+	}
+
+	if err := br.objectStore.DeleteObject(bucket, accessCheckKey); err != nil {
+		return errors.WithMessage(err, "error deleting from bucket")
+	}
+
+	return nil
+}
+
 // cachedBackupService wraps a real backup service with a cache for getting cloud backups.
 type cachedBackupService struct {
 	BackupService
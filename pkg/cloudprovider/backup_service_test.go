@@ -17,23 +17,29 @@ limitations under the License.
 package cloudprovider
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"errors"
 	"io"
 	"io/ioutil"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
 	testutil "github.com/heptio/ark/pkg/util/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/backup/shard"
 	"github.com/heptio/ark/pkg/util/encode"
 	arktest "github.com/heptio/ark/pkg/util/test"
 )
@@ -114,7 +120,8 @@ func TestUploadBackup(t *testing.T) {
 
 			backupService := NewBackupService(objStore, logger)
 
-			err := backupService.UploadBackup(bucket, backupName, test.metadata, test.backup, test.log)
+			backup := &api.Backup{ObjectMeta: metav1.ObjectMeta{Name: backupName}}
+			err := backupService.UploadBackup(bucket, backup, test.metadata, test.backup, test.log)
 
 			if test.expectedErr != "" {
 				assert.EqualError(t, err, test.expectedErr)
@@ -136,7 +143,7 @@ func TestDownloadBackup(t *testing.T) {
 	o.On("GetObject", bucket, backup+"/"+backup+".tar.gz").Return(ioutil.NopCloser(strings.NewReader("foo")), nil)
 
 	s := NewBackupService(o, logger)
-	rc, err := s.DownloadBackup(bucket, backup)
+	rc, err := s.DownloadBackup(bucket, &api.Backup{ObjectMeta: metav1.ObjectMeta{Name: backup}}, nil)
 	require.NoError(t, err)
 	require.NotNil(t, rc)
 	data, err := ioutil.ReadAll(rc)
@@ -145,20 +152,252 @@ func TestDownloadBackup(t *testing.T) {
 	o.AssertExpectations(t)
 }
 
+func TestUploadAndDownloadShardedBackup(t *testing.T) {
+	var (
+		o       = &testutil.ObjectStore{}
+		bucket  = "b"
+		logger  = arktest.NewLogger()
+		objects = make(map[string][]byte)
+		backup  = &api.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: "bak"},
+			Spec:       api.BackupSpec{ShardBy: api.BackupShardByNamespace},
+		}
+	)
+
+	o.On("PutObject", bucket, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		data, err := ioutil.ReadAll(args.Get(2).(io.Reader))
+		require.NoError(t, err)
+		objects[args.String(1)] = data
+	}).Return(nil)
+	o.On("GetObject", bucket, mock.Anything).Return(
+		func(bucket, key string) io.ReadCloser {
+			return ioutil.NopCloser(bytes.NewReader(objects[key]))
+		},
+		nil,
+	)
+
+	contents := newTarball(t,
+		"resources/namespaces/cluster/ns-1.json",
+		"resources/pods/namespaces/ns-1/pod-1.json",
+		"resources/pods/namespaces/ns-2/pod-2.json",
+		"resources/deployments.apps/namespaces/ns-2/deploy-1.json",
+	)
+
+	s := NewBackupService(o, logger)
+	require.NoError(t, s.UploadBackup(bucket, backup, newStringReadSeeker("foo"), bytes.NewReader(contents), nil))
+
+	var keys []string
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	assert.Equal(t, []string{
+		"bak/ark-backup.json",
+		"bak/bak-cluster.tar.gz",
+		"bak/bak-manifest.json",
+		"bak/bak-namespaces.ns-1.tar.gz",
+		"bak/bak-namespaces.ns-2.tar.gz",
+	}, keys)
+
+	// only the shards that include returns true for are downloaded
+	rc, err := s.DownloadBackup(bucket, backup, func(s shard.Shard) bool { return s.Namespace != "ns-1" })
+	require.NoError(t, err)
+	defer rc.Close()
+
+	assert.Equal(t, []string{
+		"resources/namespaces/cluster/ns-1.json",
+		"resources/pods/namespaces/ns-2/pod-2.json",
+		"resources/deployments.apps/namespaces/ns-2/deploy-1.json",
+	}, tarballFiles(t, rc))
+}
+
+func TestUploadAndDownloadBackupWithOffloadedItems(t *testing.T) {
+	var (
+		o       = &testutil.ObjectStore{}
+		bucket  = "b"
+		logger  = arktest.NewLogger()
+		objects = make(map[string][]byte)
+		backup  = &api.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: "bak"},
+			Status: api.BackupStatus{
+				LargeItems: []api.LargeItem{
+					{Resource: "configmaps", Namespace: "ns-1", Name: "cm-1", Offloaded: true},
+					{Resource: "configmaps", Namespace: "ns-1", Name: "cm-2", Offloaded: true},
+				},
+			},
+		}
+	)
+
+	o.On("PutObject", bucket, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		data, err := ioutil.ReadAll(args.Get(2).(io.Reader))
+		require.NoError(t, err)
+		objects[args.String(1)] = data
+	}).Return(nil)
+	o.On("GetObject", bucket, mock.Anything).Return(
+		func(bucket, key string) io.ReadCloser {
+			return ioutil.NopCloser(bytes.NewReader(objects[key]))
+		},
+		nil,
+	)
+	o.On("ListObjects", bucket, "bak/large-items/").Return(
+		func(bucket, prefix string) []string {
+			var keys []string
+			for key := range objects {
+				if strings.HasPrefix(key, prefix) {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			return keys
+		},
+		nil,
+	)
+
+	contents := newTarball(t,
+		"resources/pods/namespaces/ns-1/pod-1.json",
+		"large-items/resources/configmaps/namespaces/ns-1/cm-1.json",
+		"clusters/other/large-items/resources/configmaps/namespaces/ns-1/cm-2.json",
+	)
+
+	s := NewBackupService(o, logger)
+	require.NoError(t, s.UploadBackup(bucket, backup, newStringReadSeeker("foo"), bytes.NewReader(contents), nil))
+
+	// offloaded items are stored as separate objects, and left out of the tarball
+	var keys []string
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	assert.Equal(t, []string{
+		"bak/ark-backup.json",
+		"bak/bak-large-items-index.json",
+		"bak/bak.tar.gz",
+		"bak/large-items/clusters/other/resources/configmaps/namespaces/ns-1/cm-2.json",
+		"bak/large-items/resources/configmaps/namespaces/ns-1/cm-1.json",
+	}, keys)
+	assert.JSONEq(t, `{"items":["resources/configmaps/namespaces/ns-1/cm-1.json","clusters/other/resources/configmaps/namespaces/ns-1/cm-2.json"]}`, string(objects["bak/bak-large-items-index.json"]))
+	assert.Equal(t, []string{"resources/pods/namespaces/ns-1/pod-1.json"}, tarballFiles(t, bytes.NewReader(objects["bak/bak.tar.gz"])))
+
+	// they're added back, at the paths they'd have had otherwise, when the backup is downloaded
+	rc, err := s.DownloadBackup(bucket, backup, nil)
+	require.NoError(t, err)
+	defer rc.Close()
+
+	assert.Equal(t, []string{
+		"resources/pods/namespaces/ns-1/pod-1.json",
+		"clusters/other/resources/configmaps/namespaces/ns-1/cm-2.json",
+		"resources/configmaps/namespaces/ns-1/cm-1.json",
+	}, tarballFiles(t, rc))
+}
+
+func TestUploadChunkedLogs(t *testing.T) {
+	var (
+		o       = &testutil.ObjectStore{}
+		bucket  = "b"
+		logger  = arktest.NewLogger()
+		objects = make(map[string][]byte)
+		backup  = &api.Backup{ObjectMeta: metav1.ObjectMeta{Name: "bak"}}
+	)
+
+	o.On("PutObject", bucket, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		data, err := ioutil.ReadAll(args.Get(2).(io.Reader))
+		require.NoError(t, err)
+		objects[args.String(1)] = data
+	}).Return(nil)
+
+	s := NewBackupServiceWithConfig(o, BackupServiceConfig{LogChunkSizeBytes: 10}, logger)
+
+	// the backup's log is split into a chunk per line
+	require.NoError(t, s.UploadBackup(bucket, backup, newStringReadSeeker("foo"), nil, bytes.NewReader(gzippedString(t, "line 1\nline 2\n"))))
+
+	// the restore's log fits in a single chunk, so it isn't split
+	require.NoError(t, s.UploadRestoreLog(bucket, "bak", "r1", bytes.NewReader(gzippedString(t, "line 1\n"))))
+
+	var keys []string
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	assert.Equal(t, []string{
+		"bak/ark-backup.json",
+		"bak/bak-logs-1.gz",
+		"bak/bak-logs-2.gz",
+		"bak/bak-logs-index.json",
+		"bak/restore-r1-logs.gz",
+	}, keys)
+
+	assert.JSONEq(t, `{"chunks":2}`, string(objects["bak/bak-logs-index.json"]))
+	assert.Equal(t, "line 2\n", gunzippedString(t, objects["bak/bak-logs-2.gz"]))
+	assert.Equal(t, "line 1\n", gunzippedString(t, objects["bak/restore-r1-logs.gz"]))
+}
+
+func gzippedString(t *testing.T, s string) []byte {
+	buf := new(bytes.Buffer)
+	gzw := gzip.NewWriter(buf)
+	_, err := gzw.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+	return buf.Bytes()
+}
+
+func gunzippedString(t *testing.T, data []byte) string {
+	gzr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	res, err := ioutil.ReadAll(gzr)
+	require.NoError(t, err)
+	return string(res)
+}
+
+// newTarball returns a gzipped tarball containing an empty file at each of paths.
+func newTarball(t *testing.T, paths ...string) []byte {
+	buf := new(bytes.Buffer)
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+
+	for _, path := range paths {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: path, Typeflag: tar.TypeReg, Mode: 0644}))
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+
+	return buf.Bytes()
+}
+
+// tarballFiles returns the paths of the files in the gzipped tarball read from r.
+func tarballFiles(t *testing.T, r io.Reader) []string {
+	gzr, err := gzip.NewReader(r)
+	require.NoError(t, err)
+
+	var paths []string
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return paths
+		}
+		require.NoError(t, err)
+		paths = append(paths, header.Name)
+	}
+}
+
 func TestDeleteBackup(t *testing.T) {
 	tests := []struct {
 		name             string
 		listObjectsError error
 		deleteErrors     []error
 		expectedErr      string
+		expectedPhases   []api.ArtifactDeletionPhase
 	}{
 		{
-			name: "normal case",
+			name:           "normal case",
+			expectedPhases: []api.ArtifactDeletionPhase{"Deleted", "Deleted", "Deleted", "Deleted", "Deleted", "Deleted", "Deleted"},
 		},
 		{
-			name:         "some delete errors, do as much as we can",
-			deleteErrors: []error{errors.New("a"), nil, errors.New("c")},
-			expectedErr:  "[a, c]",
+			name:           "some delete errors, do as much as we can",
+			deleteErrors:   []error{errors.New("a"), nil, errors.New("c")},
+			expectedErr:    "[a, c]",
+			expectedPhases: []api.ArtifactDeletionPhase{"Failed", "Deleted", "Failed", "Deleted", "Deleted", "Deleted", "Deleted"},
 		},
 	}
 
@@ -167,7 +406,7 @@ func TestDeleteBackup(t *testing.T) {
 			var (
 				bucket   = "bucket"
 				backup   = "bak"
-				objects  = []string{"bak/ark-backup.json", "bak/bak.tar.gz", "bak/bak.log.gz"}
+				objects  = []string{"bak/ark-backup.json", "bak/bak.tar.gz", "bak/bak-logs.gz", "bak/restore-r1-results.gz", "bak/bak-logs-1.gz", "bak/bak-logs-index.json", "bak/restore-r1-logs-1.gz"}
 				objStore = &testutil.ObjectStore{}
 				logger   = arktest.NewLogger()
 			)
@@ -184,7 +423,7 @@ func TestDeleteBackup(t *testing.T) {
 
 			backupService := NewBackupService(objStore, logger)
 
-			err := backupService.DeleteBackupDir(bucket, backup)
+			results, err := backupService.DeleteBackupDir(bucket, backup)
 
 			if test.expectedErr != "" {
 				assert.EqualError(t, err, test.expectedErr)
@@ -192,6 +431,26 @@ func TestDeleteBackup(t *testing.T) {
 				assert.NoError(t, err)
 			}
 
+			var (
+				artifacts []api.BackupArtifact
+				phases    []api.ArtifactDeletionPhase
+			)
+			for i, result := range results {
+				assert.Equal(t, objects[i], result.Name)
+				artifacts = append(artifacts, result.Artifact)
+				phases = append(phases, result.Phase)
+			}
+			assert.Equal(t, []api.BackupArtifact{
+				api.BackupArtifactMetadata,
+				api.BackupArtifactTarball,
+				api.BackupArtifactLogs,
+				api.BackupArtifactRestoreData,
+				api.BackupArtifactLogs,
+				api.BackupArtifactLogs,
+				api.BackupArtifactRestoreData,
+			}, artifacts)
+			assert.Equal(t, test.expectedPhases, phases)
+
 			objStore.AssertExpectations(t)
 		})
 	}
@@ -245,8 +504,8 @@ func TestGetAllBackups(t *testing.T) {
 			)
 
 			objStore.On("ListCommonPrefixes", bucket, "/").Return([]string{"backup-1", "backup-2"}, nil)
-			objStore.On("GetObject", bucket, "backup-1/ark-backup.json").Return(ioutil.NopCloser(bytes.NewReader(test.storageData["backup-1/ark-backup.json"])), nil)
-			objStore.On("GetObject", bucket, "backup-2/ark-backup.json").Return(ioutil.NopCloser(bytes.NewReader(test.storageData["backup-2/ark-backup.json"])), nil)
+			objStore.On("GetObjectIfChanged", bucket, "backup-1/ark-backup.json", "").Return(ioutil.NopCloser(bytes.NewReader(test.storageData["backup-1/ark-backup.json"])), "", nil)
+			objStore.On("GetObjectIfChanged", bucket, "backup-2/ark-backup.json", "").Return(ioutil.NopCloser(bytes.NewReader(test.storageData["backup-2/ark-backup.json"])), "", nil)
 
 			backupService := NewBackupService(objStore, logger)
 
@@ -265,11 +524,218 @@ func TestGetAllBackups(t *testing.T) {
 	}
 }
 
+func TestForEachBackup(t *testing.T) {
+	var (
+		bucket   = "bucket"
+		objStore = &testutil.ObjectStore{}
+		logger   = arktest.NewLogger()
+	)
+
+	objStore.On("ListCommonPrefixes", bucket, "/").Return([]string{"backup-1", "backup-2", "backup-3"}, nil)
+	objStore.On("GetObjectIfChanged", bucket, "backup-1/ark-backup.json", "").
+		Return(ioutil.NopCloser(bytes.NewReader(encodeToBytes(&api.Backup{ObjectMeta: metav1.ObjectMeta{Name: "backup-1"}}))), "", nil)
+	objStore.On("GetObjectIfChanged", bucket, "backup-2/ark-backup.json", "").
+		Return(ioutil.NopCloser(bytes.NewReader(encodeToBytes(&api.Backup{ObjectMeta: metav1.ObjectMeta{Name: "backup-2"}}))), "", nil)
+
+	backupService := NewBackupService(objStore, logger)
+
+	var visited []string
+	err := backupService.ForEachBackup(bucket, func(backup *api.Backup) error {
+		visited = append(visited, backup.Name)
+		if backup.Name == "backup-2" {
+			return errors.New("stop")
+		}
+		return nil
+	})
+
+	// iteration stops at the first error, so backup-3's metadata is never fetched
+	assert.EqualError(t, err, "stop")
+	assert.Equal(t, []string{"backup-1", "backup-2"}, visited)
+	objStore.AssertExpectations(t)
+}
+
+func TestListBackupDirsSkipsAuditLogs(t *testing.T) {
+	var (
+		bucket   = "bucket"
+		objStore = &testutil.ObjectStore{}
+		logger   = arktest.NewLogger()
+	)
+	defer objStore.AssertExpectations(t)
+
+	backupService := NewBackupService(objStore, logger)
+
+	log := strings.NewReader("log")
+	objStore.On("PutObject", bucket, ".ark-audit/20180601T120000.000Z.gz", log).Return(nil)
+	require.NoError(t, backupService.UploadAuditLog(bucket, "20180601T120000.000Z", log))
+
+	objStore.On("ListCommonPrefixes", bucket, "/").Return([]string{".ark-audit", "backup-1"}, nil)
+	dirs, err := backupService.ListBackupDirs(bucket)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup-1"}, dirs)
+}
+
+func TestValidateAccess(t *testing.T) {
+	tests := []struct {
+		name        string
+		readOnly    bool
+		listErr     error
+		putErr      error
+		deleteErr   error
+		expectedErr string
+	}{
+		{
+			name: "accessible bucket",
+		},
+		{
+			name:     "read-only check only lists the bucket",
+			readOnly: true,
+		},
+		{
+			name:        "bucket can't be listed",
+			listErr:     errors.New("NoSuchBucket"),
+			expectedErr: "error listing bucket: NoSuchBucket",
+		},
+		{
+			name:        "bucket is read-only",
+			putErr:      errors.New("AccessDenied"),
+			expectedErr: "error writing to bucket: AccessDenied",
+		},
+		{
+			name:        "objects can't be deleted",
+			deleteErr:   errors.New("AccessDenied"),
+			expectedErr: "error deleting from bucket: AccessDenied",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			objStore := &testutil.ObjectStore{}
+			backupService := NewBackupService(objStore, arktest.NewLogger())
+
+			objStore.On("ListCommonPrefixes", "bucket", "/").Return([]string{"backup-1"}, test.listErr)
+			if test.listErr == nil && !test.readOnly {
+				objStore.On("PutObject", "bucket", accessCheckKey, mock.Anything).Return(test.putErr)
+			}
+			if test.listErr == nil && test.putErr == nil && !test.readOnly {
+				objStore.On("DeleteObject", "bucket", accessCheckKey).Return(test.deleteErr)
+			}
+
+			err := backupService.ValidateAccess("bucket", test.readOnly)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedErr)
+			}
+			objStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetBackupUsesCachedMetadata(t *testing.T) {
+	var (
+		bucket   = "bucket"
+		key      = "backup-1/ark-backup.json"
+		objStore = &testutil.ObjectStore{}
+		logger   = arktest.NewLogger()
+	)
+
+	service := NewBackupService(objStore, logger).(*backupService)
+
+	// the first fetch downloads the metadata and caches it with its ETag
+	objStore.On("GetObjectIfChanged", bucket, key, "").
+		Return(ioutil.NopCloser(bytes.NewReader(encodeToBytes(&api.Backup{ObjectMeta: metav1.ObjectMeta{Name: "backup-1"}}))), "etag-1", nil).Once()
+	res, err := service.GetBackup(bucket, "backup-1")
+	require.NoError(t, err)
+	assert.Equal(t, "backup-1", res.Name)
+
+	// modifying the returned backup doesn't modify the cached one
+	res.Namespace = "modified"
+
+	// unchanged metadata is returned from the cache
+	objStore.On("GetObjectIfChanged", bucket, key, "etag-1").Return(nil, "", ErrObjectNotModified).Once()
+	res, err = service.GetBackup(bucket, "backup-1")
+	require.NoError(t, err)
+	assert.Equal(t, "backup-1", res.Name)
+	assert.Empty(t, res.Namespace)
+
+	// changed metadata is downloaded again
+	objStore.On("GetObjectIfChanged", bucket, key, "etag-1").
+		Return(ioutil.NopCloser(bytes.NewReader(encodeToBytes(&api.Backup{ObjectMeta: metav1.ObjectMeta{Name: "backup-1", Labels: map[string]string{"a": "b"}}}))), "etag-2", nil).Once()
+	res, err = service.GetBackup(bucket, "backup-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "b"}, res.Labels)
+
+	// metadata for backups that no longer exist is forgotten when listing all backups
+	objStore.On("ListCommonPrefixes", bucket, "/").Return([]string{}, nil).Once()
+	_, err = service.GetAllBackups(bucket)
+	require.NoError(t, err)
+	assert.Empty(t, service.metadata[bucket])
+
+	objStore.AssertExpectations(t)
+}
+
+func TestBackupMetadataExists(t *testing.T) {
+	var (
+		bucket   = "bucket"
+		objStore = &testutil.ObjectStore{}
+		service  = NewBackupService(objStore, arktest.NewLogger())
+	)
+
+	objStore.On("ListObjects", bucket, "backup-1/ark-backup.json").Return([]string{"backup-1/ark-backup.json"}, nil)
+	objStore.On("ListObjects", bucket, "backup-2/ark-backup.json").Return([]string{"backup-2/ark-backup.json.tmp"}, nil)
+	objStore.On("ListObjects", bucket, "backup-3/ark-backup.json").Return(nil, errors.New("throttled"))
+
+	exists, err := service.BackupMetadataExists(bucket, "backup-1")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = service.BackupMetadataExists(bucket, "backup-2")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = service.BackupMetadataExists(bucket, "backup-3")
+	assert.EqualError(t, err, "throttled")
+
+	objStore.AssertExpectations(t)
+}
+
+// unconditionalObjectStore hides the GetObjectIfChanged method of the ObjectStore it
+// wraps, like a plugin that doesn't implement ConditionalObjectStore.
+type unconditionalObjectStore struct {
+	ObjectStore
+}
+
+func TestGetBackupWithoutConditionalFetches(t *testing.T) {
+	var (
+		bucket   = "bucket"
+		key      = "backup-1/ark-backup.json"
+		objStore = &testutil.ObjectStore{}
+		logger   = arktest.NewLogger()
+	)
+
+	service := NewBackupService(&unconditionalObjectStore{objStore}, logger).(*backupService)
+
+	// the metadata is downloaded every time, since there's no ETag to cache it with
+	for i := 0; i < 2; i++ {
+		objStore.On("GetObject", bucket, key).
+			Return(ioutil.NopCloser(bytes.NewReader(encodeToBytes(&api.Backup{ObjectMeta: metav1.ObjectMeta{Name: "backup-1"}}))), nil).Once()
+		res, err := service.GetBackup(bucket, "backup-1")
+		require.NoError(t, err)
+		assert.Equal(t, "backup-1", res.Name)
+	}
+
+	assert.Empty(t, service.metadata[bucket])
+	objStore.AssertExpectations(t)
+}
+
 func TestCreateSignedURL(t *testing.T) {
 	tests := []struct {
 		name        string
 		targetKind  api.DownloadTargetKind
 		targetName  string
+		targetShard string
+		targetChunk int
+		targetItem  string
 		directory   string
 		expectedKey string
 	}{
@@ -287,6 +753,51 @@ func TestCreateSignedURL(t *testing.T) {
 			directory:   "my-backup",
 			expectedKey: "my-backup/my-backup-logs.gz",
 		},
+		{
+			name:        "backup log chunk",
+			targetKind:  api.DownloadTargetKindBackupLog,
+			targetName:  "my-backup",
+			targetChunk: 2,
+			directory:   "my-backup",
+			expectedKey: "my-backup/my-backup-logs-2.gz",
+		},
+		{
+			name:        "backup log index",
+			targetKind:  api.DownloadTargetKindBackupLogIndex,
+			targetName:  "my-backup",
+			directory:   "my-backup",
+			expectedKey: "my-backup/my-backup-logs-index.json",
+		},
+		{
+			name:        "backup manifest",
+			targetKind:  api.DownloadTargetKindBackupManifest,
+			targetName:  "my-backup",
+			directory:   "my-backup",
+			expectedKey: "my-backup/my-backup-manifest.json",
+		},
+		{
+			name:        "backup shard",
+			targetKind:  api.DownloadTargetKindBackupShard,
+			targetName:  "my-backup",
+			targetShard: "namespaces.default",
+			directory:   "my-backup",
+			expectedKey: "my-backup/my-backup-namespaces.default.tar.gz",
+		},
+		{
+			name:        "backup large items index",
+			targetKind:  api.DownloadTargetKindBackupLargeItemsIndex,
+			targetName:  "my-backup",
+			directory:   "my-backup",
+			expectedKey: "my-backup/my-backup-large-items-index.json",
+		},
+		{
+			name:        "backup large item",
+			targetKind:  api.DownloadTargetKindBackupLargeItem,
+			targetName:  "my-backup",
+			targetItem:  "clusters/east/resources/configmaps/namespaces/ns-1/cm-1.json",
+			directory:   "my-backup",
+			expectedKey: "my-backup/large-items/clusters/east/resources/configmaps/namespaces/ns-1/cm-1.json",
+		},
 		{
 			name:        "scheduled backup contents",
 			targetKind:  api.DownloadTargetKindBackupContents,
@@ -308,6 +819,21 @@ func TestCreateSignedURL(t *testing.T) {
 			directory:   "b",
 			expectedKey: "b/restore-b-20170913154901-logs.gz",
 		},
+		{
+			name:        "restore log chunk",
+			targetKind:  api.DownloadTargetKindRestoreLog,
+			targetName:  "b-20170913154901",
+			targetChunk: 1,
+			directory:   "b",
+			expectedKey: "b/restore-b-20170913154901-logs-1.gz",
+		},
+		{
+			name:        "restore log index",
+			targetKind:  api.DownloadTargetKindRestoreLogIndex,
+			targetName:  "b-20170913154901",
+			directory:   "b",
+			expectedKey: "b/restore-b-20170913154901-logs-index.json",
+		},
 		{
 			name:        "restore results",
 			targetKind:  api.DownloadTargetKindRestoreResults,
@@ -322,6 +848,13 @@ func TestCreateSignedURL(t *testing.T) {
 			directory:   "b-cool-20170913154901",
 			expectedKey: "b-cool-20170913154901/restore-b-cool-20170913154901-20170913154902-results.gz",
 		},
+		{
+			name:        "restore plan",
+			targetKind:  api.DownloadTargetKindRestorePlan,
+			targetName:  "b-20170913154901",
+			directory:   "b",
+			expectedKey: "b/restore-b-20170913154901-plan.gz",
+		},
 	}
 
 	for _, test := range tests {
@@ -333,8 +866,11 @@ func TestCreateSignedURL(t *testing.T) {
 			)
 
 			target := api.DownloadTarget{
-				Kind: test.targetKind,
-				Name: test.targetName,
+				Kind:  test.targetKind,
+				Name:  test.targetName,
+				Shard: test.targetShard,
+				Chunk: test.targetChunk,
+				Item:  test.targetItem,
 			}
 			objectStorage.On("CreateSignedURL", "bucket", test.expectedKey, time.Duration(0)).Return("url", nil)
 			url, err := backupService.CreateSignedURL(target, "bucket", test.directory, 0)
@@ -345,6 +881,26 @@ func TestCreateSignedURL(t *testing.T) {
 	}
 }
 
+func TestCreateSignedURLRejectsInvalidShardNames(t *testing.T) {
+	backupService := NewBackupService(&testutil.ObjectStore{}, arktest.NewLogger())
+
+	for _, name := range []string{"", "../other-backup/other-backup"} {
+		target := api.DownloadTarget{Kind: api.DownloadTargetKindBackupShard, Name: "my-backup", Shard: name}
+		_, err := backupService.CreateSignedURL(target, "bucket", "my-backup", 0)
+		assert.Error(t, err)
+	}
+}
+
+func TestCreateSignedURLRejectsInvalidLargeItemPaths(t *testing.T) {
+	backupService := NewBackupService(&testutil.ObjectStore{}, arktest.NewLogger())
+
+	for _, item := range []string{"", "../other-backup/ark-backup.json", "/resources/pods/namespaces/ns-1/pod-1.json", "resources/../../other-backup/ark-backup.json", "other/file.json"} {
+		target := api.DownloadTarget{Kind: api.DownloadTargetKindBackupLargeItem, Name: "my-backup", Item: item}
+		_, err := backupService.CreateSignedURL(target, "bucket", "my-backup", 0)
+		assert.Error(t, err, item)
+	}
+}
+
 func jsonMarshal(obj interface{}) []byte {
 	res, err := json.Marshal(obj)
 	if err != nil {
@@ -374,3 +930,97 @@ func newStringReadSeeker(s string) *stringReadSeeker {
 func (srs *stringReadSeeker) Seek(offset int64, whence int) (int64, error) {
 	return 0, nil
 }
+
+func TestBackupServiceWithPathTemplate(t *testing.T) {
+	var (
+		bucket   = "bucket"
+		objStore = &testutil.ObjectStore{}
+		logger   = arktest.NewLogger()
+	)
+	defer objStore.AssertExpectations(t)
+
+	backupService := NewBackupServiceWithPathTemplate(objStore, "{cluster}/{schedule}/{backup}", "prod", logger)
+
+	// new backups are stored in the templated directory
+	backup := &api.Backup{ObjectMeta: metav1.ObjectMeta{Name: "daily-1", Labels: map[string]string{api.ScheduleLabel: "daily"}}}
+	metadata := newStringReadSeeker("foo")
+	objStore.On("PutObject", bucket, "prod/daily/daily-1/ark-backup.json", metadata).Return(nil)
+	require.NoError(t, backupService.UploadBackup(bucket, backup, metadata, nil, nil))
+
+	// backups in templated and top-level directories are found by listing the top-level
+	// directories, and the objects in the ones the template can give
+	objStore.On("ListCommonPrefixes", bucket, "/").Return([]string{"prod", "old-backup", ".ark-audit"}, nil)
+	objStore.On("ListObjects", bucket, "prod/").Return([]string{
+		"prod/daily/daily-1/ark-backup.json",
+		"prod/daily/daily-1/daily-1.tar.gz",
+		"prod/unscheduled/backup-2/ark-backup.json",
+		"prod/unscheduled/backup-2/nested/ark-backup.json",
+		"prod/daily/not-a-backup/file.txt",
+	}, nil)
+
+	dirs, err := backupService.ListBackupDirs(bucket)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup-2", "daily-1", "old-backup"}, dirs)
+
+	objStore.On("GetObjectIfChanged", bucket, "prod/unscheduled/backup-2/ark-backup.json", "").
+		Return(ioutil.NopCloser(bytes.NewReader(encodeToBytes(&api.Backup{ObjectMeta: metav1.ObjectMeta{Name: "backup-2"}}))), "", nil)
+	res, err := backupService.GetBackup(bucket, "backup-2")
+	require.NoError(t, err)
+	assert.Equal(t, "backup-2", res.Name)
+
+	objStore.On("GetObject", bucket, "old-backup/old-backup.tar.gz").Return(ioutil.NopCloser(strings.NewReader("bar")), nil)
+	_, err = backupService.DownloadBackup(bucket, &api.Backup{ObjectMeta: metav1.ObjectMeta{Name: "old-backup"}}, nil)
+	require.NoError(t, err)
+
+	objStore.On("CreateSignedURL", bucket, "prod/daily/daily-1/daily-1-logs.gz", time.Minute).Return("url", nil)
+	url, err := backupService.CreateSignedURL(api.DownloadTarget{Kind: api.DownloadTargetKindBackupLog, Name: "daily-1"}, bucket, "daily-1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "url", url)
+}
+
+func TestDeleteBackupDirWithPathTemplate(t *testing.T) {
+	var (
+		bucket   = "bucket"
+		objStore = &testutil.ObjectStore{}
+		logger   = arktest.NewLogger()
+	)
+	defer objStore.AssertExpectations(t)
+
+	backupService := NewBackupServiceWithPathTemplate(objStore, "{cluster}/{backup}", "prod", logger)
+
+	// a backup stored before the path template was configured, and named after the cluster,
+	// has the cluster's other backups nested under its directory
+	objStore.On("ListCommonPrefixes", bucket, "/").Return([]string{"prod"}, nil)
+	objStore.On("ListObjects", bucket, "prod/").Return([]string{
+		"prod/ark-backup.json",
+		"prod/prod.tar.gz",
+		"prod/backup-1/ark-backup.json",
+	}, nil)
+	objStore.On("DeleteObject", bucket, "prod/ark-backup.json").Return(nil)
+	objStore.On("DeleteObject", bucket, "prod/prod.tar.gz").Return(nil)
+
+	_, err := backupService.DeleteBackupDir(bucket, "prod")
+	require.NoError(t, err)
+}
+
+func TestBackupDirsRefreshIsRateLimited(t *testing.T) {
+	var (
+		bucket    = "bucket"
+		objStore  = &testutil.ObjectStore{}
+		logger    = arktest.NewLogger()
+		fakeClock = clock.NewFakeClock(time.Now())
+	)
+	defer objStore.AssertExpectations(t)
+
+	backupService := NewBackupServiceWithPathTemplate(objStore, "{cluster}/{backup}", "prod", logger).(*backupService)
+	backupService.clock = fakeClock
+
+	objStore.On("ListCommonPrefixes", bucket, "/").Return([]string{}, nil).Twice()
+
+	// the first lookup of a missing backup lists the bucket, but lookups shortly after it don't
+	assert.Equal(t, "missing", backupService.backupDir(bucket, "missing"))
+	assert.Equal(t, "missing", backupService.backupDir(bucket, "missing"))
+
+	fakeClock.Step(minBackupDirsRefreshInterval)
+	assert.Equal(t, "missing", backupService.backupDir(bucket, "missing"))
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// CredentialsFileKey is the provider config key for the path of a file holding the provider's
+// credentials, in the format of its usual credentials file. It's set by the server from a
+// provider's credentials secret, and when present, takes precedence over the credentials in
+// the environment.
+const CredentialsFileKey = "credentialsFile"
+
+// WorkloadIdentityKey is the provider config key that controls whether a cloud provider may
+// obtain credentials from the environment it's running in (an instance profile, IAM roles
+// for service accounts, AAD pod identity, or GKE workload identity) when no credentials are
+// mounted into the pod. It defaults to true.
+const WorkloadIdentityKey = "workloadIdentity"
+
+// WorkloadIdentityEnabled returns whether a provider's config allows it to fall back to
+// workload identity credentials.
+func WorkloadIdentityEnabled(config map[string]string) (bool, error) {
+	val := config[WorkloadIdentityKey]
+	if val == "" {
+		return true, nil
+	}
+
+	enabled, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not parse %s (expected bool)", WorkloadIdentityKey)
+	}
+
+	return enabled, nil
+}
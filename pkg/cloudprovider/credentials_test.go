@@ -0,0 +1,37 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkloadIdentityEnabled(t *testing.T) {
+	enabled, err := WorkloadIdentityEnabled(nil)
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	enabled, err = WorkloadIdentityEnabled(map[string]string{WorkloadIdentityKey: "false"})
+	require.NoError(t, err)
+	assert.False(t, enabled)
+
+	_, err = WorkloadIdentityEnabled(map[string]string{WorkloadIdentityKey: "nope"})
+	assert.EqualError(t, err, `could not parse workloadIdentity (expected bool): strconv.ParseBool: parsing "nope": invalid syntax`)
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package gcp
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
+	"net/http"
 	"os"
 
 	"github.com/pkg/errors"
@@ -30,6 +32,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/util/collections"
 )
@@ -47,14 +50,18 @@ func NewBlockStore(log logrus.FieldLogger) cloudprovider.BlockStore {
 }
 
 func (b *blockStore) Init(config map[string]string) error {
-	project, err := extractProjectFromCreds()
+	if config[cloudprovider.SnapshotCopyRegionKey] != "" {
+		return errors.Errorf("%s is not supported for GCP", cloudprovider.SnapshotCopyRegionKey)
+	}
+
+	project, err := getProject(config)
 	if err != nil {
 		return err
 	}
 
-	client, err := google.DefaultClient(oauth2.NoContext, compute.ComputeScope)
+	client, err := getClient(config)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
 
 	gce, err := compute.New(client)
@@ -78,8 +85,57 @@ func (b *blockStore) Init(config map[string]string) error {
 	return nil
 }
 
-func extractProjectFromCreds() (string, error) {
-	credsBytes, err := ioutil.ReadFile(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
+// getClient returns an HTTP client authorized with the provider's credentials file, if it has
+// one, or the default credentials.
+func getClient(config map[string]string) (*http.Client, error) {
+	credentialsFile := config[cloudprovider.CredentialsFileKey]
+	if credentialsFile == "" {
+		client, err := google.DefaultClient(oauth2.NoContext, compute.ComputeScope)
+		return client, errors.WithStack(err)
+	}
+
+	creds, err := ioutil.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	jwtConfig, err := google.JWTConfigFromJSON(creds, compute.ComputeScope)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return jwtConfig.Client(oauth2.NoContext), nil
+}
+
+// getProject returns the project from the provider's credentials file or the one in the
+// environment, or if there isn't one and workload identity is enabled, from the environment's
+// default credentials.
+func getProject(config map[string]string) (string, error) {
+	if credentialsFile := config[cloudprovider.CredentialsFileKey]; credentialsFile != "" {
+		return extractProjectFromCreds(credentialsFile)
+	}
+
+	if credentialsFile := os.Getenv(credentialsEnvVar); credentialsFile != "" {
+		return extractProjectFromCreds(credentialsFile)
+	}
+
+	if err := checkWorkloadIdentity(config); err != nil {
+		return "", err
+	}
+
+	creds, err := google.FindDefaultCredentials(context.Background(), compute.ComputeScope)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if creds.ProjectID == "" {
+		return "", errors.New("cannot determine project from the default credentials")
+	}
+
+	return creds.ProjectID, nil
+}
+
+func extractProjectFromCreds(credentialsFile string) (string, error) {
+	credsBytes, err := ioutil.ReadFile(credentialsFile)
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
@@ -175,6 +231,65 @@ func (b *blockStore) CreateSnapshot(volumeID, volumeAZ string, tags map[string]s
 	return gceSnap.Name, nil
 }
 
+func (b *blockStore) GetSnapshotPhase(snapshotID string) (api.SnapshotPhase, error) {
+	snapshot, err := b.gce.Snapshots.Get(b.project, snapshotID).Do()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	switch snapshot.Status {
+	case "READY":
+		return api.SnapshotPhaseCompleted, nil
+	case "FAILED":
+		return api.SnapshotPhaseFailed, nil
+	default:
+		return api.SnapshotPhaseInProgress, nil
+	}
+}
+
+func (b *blockStore) CopySnapshot(snapshotID, region string) (string, error) {
+	// GCP snapshots are stored in multi-regional storage and can be used to create disks in
+	// any region, so there is no need to copy them.
+	return "", errors.New("copying snapshots to another region is not supported for GCP")
+}
+
+func (b *blockStore) ListSnapshots(tagFilters map[string]string) ([]string, error) {
+	var snapshotIDs []string
+
+	err := b.gce.Snapshots.List(b.project).Pages(context.Background(), func(res *compute.SnapshotList) error {
+		for _, snapshot := range res.Items {
+			// tags are stored as a JSON doc in the snapshot's description
+			var tags map[string]string
+			if err := json.Unmarshal([]byte(snapshot.Description), &tags); err != nil {
+				continue
+			}
+
+			if hasTags(tags, tagFilters) {
+				snapshotIDs = append(snapshotIDs, snapshot.Name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return snapshotIDs, nil
+}
+
+// hasTags returns true if tags contains every key/value pair in filters. Filters
+// with an empty value match any value for the key.
+func hasTags(tags, filters map[string]string) bool {
+	for key, val := range filters {
+		tag, ok := tags[key]
+		if !ok || (val != "" && tag != val) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func getSnapshotTags(arkTags map[string]string, diskDescription string, log logrus.FieldLogger) string {
 	// Kubernetes uses the description field of GCP disks to store a JSON doc containing
 	// tags.
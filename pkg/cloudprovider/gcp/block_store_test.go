@@ -18,6 +18,9 @@ package gcp
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/heptio/ark/pkg/util/collections"
@@ -149,3 +152,37 @@ func TestGetSnapshotTags(t *testing.T) {
 		})
 	}
 }
+
+func TestHasTags(t *testing.T) {
+	tags := map[string]string{"ark.heptio.com/backup": "backup-1", "a": "b"}
+
+	assert.True(t, hasTags(tags, nil))
+	assert.True(t, hasTags(tags, map[string]string{"ark.heptio.com/backup": "backup-1"}))
+	assert.False(t, hasTags(tags, map[string]string{"ark.heptio.com/backup": "backup-2"}))
+	assert.False(t, hasTags(tags, map[string]string{"c": "d"}))
+	assert.True(t, hasTags(tags, map[string]string{"ark.heptio.com/backup": ""}))
+	assert.False(t, hasTags(tags, map[string]string{"c": ""}))
+	assert.False(t, hasTags(nil, map[string]string{"a": "b"}))
+}
+
+func TestGetProjectFromCredentialsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	credentialsFile := filepath.Join(dir, "credentials")
+	require.NoError(t, ioutil.WriteFile(credentialsFile, []byte(`{"type":"service_account","project_id":"project-2"}`), 0600))
+
+	project, err := getProject(map[string]string{"credentialsFile": credentialsFile})
+	require.NoError(t, err)
+	assert.Equal(t, "project-2", project)
+
+	require.NoError(t, ioutil.WriteFile(credentialsFile, []byte(`{"type":"service_account"}`), 0600))
+	_, err = getProject(map[string]string{"credentialsFile": credentialsFile})
+	assert.EqualError(t, err, "cannot fetch project_id from GCP credentials file")
+}
+
+func TestInitRejectsSnapshotCopyRegion(t *testing.T) {
+	err := (&blockStore{}).Init(map[string]string{"snapshotCopyRegion": "other-region"})
+	assert.EqualError(t, err, "snapshotCopyRegion is not supported for GCP")
+}
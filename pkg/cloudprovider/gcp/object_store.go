@@ -21,6 +21,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,6 +36,20 @@ import (
 
 const credentialsEnvVar = "GOOGLE_APPLICATION_CREDENTIALS"
 
+// checkWorkloadIdentity returns an error if config doesn't allow falling back to the
+// credentials of the environment, such as GKE workload identity, when credentialsEnvVar
+// isn't set.
+func checkWorkloadIdentity(config map[string]string) error {
+	workloadIdentity, err := cloudprovider.WorkloadIdentityEnabled(config)
+	if err != nil {
+		return err
+	}
+	if !workloadIdentity {
+		return errors.Errorf("%s is undefined and %s is disabled", credentialsEnvVar, cloudprovider.WorkloadIdentityKey)
+	}
+	return nil
+}
+
 // bucketWriter wraps the GCP SDK functions for accessing object store so they can be faked for testing.
 type bucketWriter interface {
 	// getWriteCloser returns an io.WriteCloser that can be used to upload data to the specified bucket for the specified key.
@@ -61,31 +76,43 @@ func NewObjectStore() cloudprovider.ObjectStore {
 }
 
 func (o *objectStore) Init(config map[string]string) error {
-	credentialsFile := os.Getenv(credentialsEnvVar)
-	if credentialsFile == "" {
-		return errors.Errorf("%s is undefined", credentialsEnvVar)
-	}
+	clientOptions := []option.ClientOption{option.WithScopes(storage.ScopeReadWrite)}
 
-	// Get the email and private key from the credentials file so we can pre-sign download URLs
-	creds, err := ioutil.ReadFile(credentialsFile)
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	jwtConfig, err := google.JWTConfigFromJSON(creds)
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	if jwtConfig.Email == "" {
-		return errors.Errorf("credentials file pointed to by %s does not contain an email", credentialsEnvVar)
-	}
-	if len(jwtConfig.PrivateKey) == 0 {
-		return errors.Errorf("credentials file pointed to by %s does not contain a private key", credentialsEnvVar)
+	credentialsFile := config[cloudprovider.CredentialsFileKey]
+	if credentialsFile != "" {
+		clientOptions = append(clientOptions, option.WithCredentialsFile(credentialsFile))
+	} else {
+		credentialsFile = os.Getenv(credentialsEnvVar)
 	}
 
-	o.googleAccessID = jwtConfig.Email
-	o.privateKey = jwtConfig.PrivateKey
+	if credentialsFile == "" {
+		if err := checkWorkloadIdentity(config); err != nil {
+			return err
+		}
 
-	client, err := storage.NewClient(context.Background(), option.WithScopes(storage.ScopeReadWrite))
+		// the client uses workload identity, which can't pre-sign download URLs
+	} else {
+		// Get the email and private key from the credentials file so we can pre-sign download URLs
+		creds, err := ioutil.ReadFile(credentialsFile)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		jwtConfig, err := google.JWTConfigFromJSON(creds)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if jwtConfig.Email == "" {
+			return errors.Errorf("credentials file %s does not contain an email", credentialsFile)
+		}
+		if len(jwtConfig.PrivateKey) == 0 {
+			return errors.Errorf("credentials file %s does not contain a private key", credentialsFile)
+		}
+
+		o.googleAccessID = jwtConfig.Email
+		o.privateKey = jwtConfig.PrivateKey
+	}
+
+	client, err := storage.NewClient(context.Background(), clientOptions...)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -121,6 +148,29 @@ func (o *objectStore) GetObject(bucket string, key string) (io.ReadCloser, error
 	return r, nil
 }
 
+// GetObjectIfChanged uses the object's generation, which changes every time the
+// object is overwritten, as its ETag.
+func (o *objectStore) GetObjectIfChanged(bucket, key, etag string) (io.ReadCloser, string, error) {
+	obj := o.client.Bucket(bucket).Object(key)
+
+	attrs, err := obj.Attrs(context.Background())
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	generation := strconv.FormatInt(attrs.Generation, 10)
+	if etag != "" && etag == generation {
+		return nil, "", cloudprovider.ErrObjectNotModified
+	}
+
+	r, err := obj.Generation(attrs.Generation).NewReader(context.Background())
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	return r, generation, nil
+}
+
 func (o *objectStore) ListCommonPrefixes(bucket string, delimiter string) ([]string, error) {
 	q := &storage.Query{
 		Delimiter: delimiter,
@@ -172,6 +222,10 @@ func (o *objectStore) DeleteObject(bucket string, key string) error {
 }
 
 func (o *objectStore) CreateSignedURL(bucket, key string, ttl time.Duration) (string, error) {
+	if len(o.privateKey) == 0 {
+		return "", errors.Errorf("signed URLs require a service account key, but %s is undefined", credentialsEnvVar)
+	}
+
 	return storage.SignedURL(bucket, key, &storage.SignedURLOptions{
 		GoogleAccessID: o.googleAccessID,
 		PrivateKey:     o.privateKey,
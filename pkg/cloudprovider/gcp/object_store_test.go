@@ -18,8 +18,10 @@ package gcp
 import (
 	"errors"
 	"io"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -96,3 +98,22 @@ func TestPutObject(t *testing.T) {
 		})
 	}
 }
+
+func TestInitWithoutCredentialsFile(t *testing.T) {
+	if val, ok := os.LookupEnv(credentialsEnvVar); ok {
+		defer os.Setenv(credentialsEnvVar, val)
+	} else {
+		defer os.Unsetenv(credentialsEnvVar)
+	}
+	os.Unsetenv(credentialsEnvVar)
+
+	err := NewObjectStore().Init(map[string]string{"workloadIdentity": "false"})
+	assert.EqualError(t, err, "GOOGLE_APPLICATION_CREDENTIALS is undefined and workloadIdentity is disabled")
+}
+
+func TestCreateSignedURLWithoutPrivateKey(t *testing.T) {
+	o := &objectStore{}
+
+	_, err := o.CreateSignedURL("bucket", "key", time.Minute)
+	assert.EqualError(t, err, "signed URLs require a service account key, but GOOGLE_APPLICATION_CREDENTIALS is undefined")
+}
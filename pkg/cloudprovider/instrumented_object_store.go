@@ -0,0 +1,182 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"expvar"
+	"io"
+	"sync"
+	"time"
+)
+
+// ObjectStoreMetrics is the expvar map that object storage operation metrics are published
+// in, keyed by "<provider>/<bucket>". Each key's map contains, for each operation (e.g.
+// "putObject"), its count ("putObjectCount"), the number of them that failed
+// ("putObjectErrors"), and the total time spent in them ("putObjectSeconds"), along with the
+// number of bytes uploaded ("bytesWritten") and downloaded ("bytesRead").
+var ObjectStoreMetrics = expvar.NewMap("objectStore")
+
+// objectStoreMetricsLock serializes adding a provider and bucket's map to ObjectStoreMetrics.
+var objectStoreMetricsLock sync.Mutex
+
+// instrumentedObjectStore wraps an ObjectStore, recording metrics for each of its operations
+// in ObjectStoreMetrics.
+type instrumentedObjectStore struct {
+	ObjectStore
+
+	provider string
+}
+
+// NewInstrumentedObjectStore returns an ObjectStore that records the latency, errors, and
+// bytes transferred of store's operations, labeled with the name of its provider.
+func NewInstrumentedObjectStore(store ObjectStore, provider string) ObjectStore {
+	return &instrumentedObjectStore{
+		ObjectStore: store,
+		provider:    provider,
+	}
+}
+
+// metricsFor returns the map that metrics for bucket's operations are recorded in.
+func (s *instrumentedObjectStore) metricsFor(bucket string) *expvar.Map {
+	key := s.provider + "/" + bucket
+
+	objectStoreMetricsLock.Lock()
+	defer objectStoreMetricsLock.Unlock()
+
+	if m, ok := ObjectStoreMetrics.Get(key).(*expvar.Map); ok {
+		return m
+	}
+
+	m := new(expvar.Map).Init()
+	ObjectStoreMetrics.Set(key, m)
+	return m
+}
+
+// record records an operation on bucket that started at start and returned err.
+func (s *instrumentedObjectStore) record(bucket, operation string, start time.Time, err error) {
+	m := s.metricsFor(bucket)
+
+	m.Add(operation+"Count", 1)
+	if err != nil {
+		m.Add(operation+"Errors", 1)
+	}
+	m.AddFloat(operation+"Seconds", time.Since(start).Seconds())
+}
+
+func (s *instrumentedObjectStore) PutObject(bucket string, key string, body io.Reader) error {
+	start := time.Now()
+	r := &countingReader{r: body}
+
+	err := s.ObjectStore.PutObject(bucket, key, r)
+
+	s.record(bucket, "putObject", start, err)
+	s.metricsFor(bucket).Add("bytesWritten", r.n)
+
+	return err
+}
+
+func (s *instrumentedObjectStore) GetObject(bucket string, key string) (io.ReadCloser, error) {
+	start := time.Now()
+
+	body, err := s.ObjectStore.GetObject(bucket, key)
+
+	s.record(bucket, "getObject", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &countingReadCloser{ReadCloser: body, metrics: s.metricsFor(bucket)}, nil
+}
+
+func (s *instrumentedObjectStore) GetObjectIfChanged(bucket, key, etag string) (io.ReadCloser, string, error) {
+	start := time.Now()
+
+	body, newETag, err := GetObjectIfChanged(s.ObjectStore, bucket, key, etag)
+
+	// an unchanged object isn't a failure
+	recordedErr := err
+	if err == ErrObjectNotModified {
+		recordedErr = nil
+	}
+	s.record(bucket, "getObject", start, recordedErr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &countingReadCloser{ReadCloser: body, metrics: s.metricsFor(bucket)}, newETag, nil
+}
+
+func (s *instrumentedObjectStore) ListCommonPrefixes(bucket string, delimiter string) ([]string, error) {
+	start := time.Now()
+
+	prefixes, err := s.ObjectStore.ListCommonPrefixes(bucket, delimiter)
+
+	s.record(bucket, "listCommonPrefixes", start, err)
+	return prefixes, err
+}
+
+func (s *instrumentedObjectStore) ListObjects(bucket, prefix string) ([]string, error) {
+	start := time.Now()
+
+	keys, err := s.ObjectStore.ListObjects(bucket, prefix)
+
+	s.record(bucket, "listObjects", start, err)
+	return keys, err
+}
+
+func (s *instrumentedObjectStore) DeleteObject(bucket string, key string) error {
+	start := time.Now()
+
+	err := s.ObjectStore.DeleteObject(bucket, key)
+
+	s.record(bucket, "deleteObject", start, err)
+	return err
+}
+
+func (s *instrumentedObjectStore) CreateSignedURL(bucket, key string, ttl time.Duration) (string, error) {
+	start := time.Now()
+
+	url, err := s.ObjectStore.CreateSignedURL(bucket, key, ttl)
+
+	s.record(bucket, "createSignedURL", start, err)
+	return url, err
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// countingReadCloser adds the bytes read from its ReadCloser to metrics' "bytesRead".
+type countingReadCloser struct {
+	io.ReadCloser
+
+	metrics *expvar.Map
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.metrics.Add("bytesRead", int64(n))
+	return n, err
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"expvar"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+// metricValue returns the value of the named metric recorded for provider and bucket.
+func metricValue(t *testing.T, provider, bucket, name string) string {
+	m, ok := ObjectStoreMetrics.Get(provider + "/" + bucket).(*expvar.Map)
+	require.True(t, ok, "no metrics for %s/%s", provider, bucket)
+
+	v := m.Get(name)
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
+
+func TestInstrumentedObjectStore(t *testing.T) {
+	objectStore := &arktest.ObjectStore{}
+	defer objectStore.AssertExpectations(t)
+
+	// each test uses its own bucket, since metrics are global
+	bucket := "instrumented-bucket"
+	store := NewInstrumentedObjectStore(objectStore, "fake")
+
+	objectStore.On("PutObject", bucket, "key-1", mock.Anything).Return(func(bucket, key string, body io.Reader) error {
+		_, err := ioutil.ReadAll(body)
+		return err
+	})
+	require.NoError(t, store.PutObject(bucket, "key-1", strings.NewReader("hello")))
+
+	objectStore.On("GetObject", bucket, "key-1").Return(ioutil.NopCloser(strings.NewReader("hello, world")), nil)
+	body, err := store.GetObject(bucket, "key-1")
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	objectStore.On("DeleteObject", bucket, "key-1").Return(errors.New("delete failed"))
+	assert.EqualError(t, store.DeleteObject(bucket, "key-1"), "delete failed")
+
+	objectStore.On("ListObjects", bucket, "prefix").Return([]string{"prefix/a"}, nil)
+	keys, err := store.ListObjects(bucket, "prefix")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prefix/a"}, keys)
+
+	assert.Equal(t, "1", metricValue(t, "fake", bucket, "putObjectCount"))
+	assert.Equal(t, "", metricValue(t, "fake", bucket, "putObjectErrors"))
+	assert.Equal(t, "5", metricValue(t, "fake", bucket, "bytesWritten"))
+	assert.Equal(t, "1", metricValue(t, "fake", bucket, "getObjectCount"))
+	assert.Equal(t, "12", metricValue(t, "fake", bucket, "bytesRead"))
+	assert.Equal(t, "1", metricValue(t, "fake", bucket, "deleteObjectCount"))
+	assert.Equal(t, "1", metricValue(t, "fake", bucket, "deleteObjectErrors"))
+	assert.Equal(t, "1", metricValue(t, "fake", bucket, "listObjectsCount"))
+	assert.NotEmpty(t, metricValue(t, "fake", bucket, "listObjectsSeconds"))
+}
+
+func TestInstrumentedObjectStoreNotModifiedIsNotAnError(t *testing.T) {
+	objectStore := &arktest.ObjectStore{}
+	defer objectStore.AssertExpectations(t)
+
+	bucket := "not-modified-bucket"
+	store := NewInstrumentedObjectStore(objectStore, "fake")
+
+	objectStore.On("GetObjectIfChanged", bucket, "key-1", "etag-1").Return(nil, "", ErrObjectNotModified)
+	_, _, err := GetObjectIfChanged(store, bucket, "key-1", "etag-1")
+	assert.Equal(t, ErrObjectNotModified, err)
+
+	assert.Equal(t, "1", metricValue(t, "fake", bucket, "getObjectCount"))
+	assert.Equal(t, "", metricValue(t, "fake", bucket, "getObjectErrors"))
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// RetryMaxAttemptsKey is the provider config key for the maximum number of attempts
+	// made for a throttled cloud API call.
+	RetryMaxAttemptsKey = "retryMaxAttempts"
+	// RetryInitialBackoffKey is the provider config key for the delay before the first retry.
+	RetryInitialBackoffKey = "retryInitialBackoff"
+	// RetryMaxBackoffKey is the provider config key for the maximum delay between retries.
+	RetryMaxBackoffKey = "retryMaxBackoff"
+
+	defaultRetryMaxAttempts    = 5
+	defaultRetryInitialBackoff = time.Second
+	defaultRetryMaxBackoff     = 30 * time.Second
+
+	retryBackoffFactor = 2.0
+	retryJitterFactor  = 0.5
+)
+
+// RetryConfig controls how throttled cloud API calls are retried.
+type RetryConfig struct {
+	// MaxAttempts is the total number of times a call is attempted, including the first. A value
+	// of 1 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. Subsequent delays grow exponentially
+	// and have jitter applied.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryConfig returns the RetryConfig used when a provider does not specify one.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:    defaultRetryMaxAttempts,
+		InitialBackoff: defaultRetryInitialBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+	}
+}
+
+// ParseRetryConfig builds a RetryConfig from a provider's config map, using defaults for any
+// keys that are not present.
+func ParseRetryConfig(config map[string]string) (RetryConfig, error) {
+	res := DefaultRetryConfig()
+
+	if val := config[RetryMaxAttemptsKey]; val != "" {
+		attempts, err := strconv.Atoi(val)
+		if err != nil {
+			return RetryConfig{}, errors.Wrapf(err, "could not parse %s (expected integer)", RetryMaxAttemptsKey)
+		}
+		if attempts < 1 {
+			return RetryConfig{}, errors.Errorf("%s must be at least 1", RetryMaxAttemptsKey)
+		}
+		res.MaxAttempts = attempts
+	}
+
+	if val := config[RetryInitialBackoffKey]; val != "" {
+		backoff, err := time.ParseDuration(val)
+		if err != nil {
+			return RetryConfig{}, errors.Wrapf(err, "could not parse %s (expected time.Duration)", RetryInitialBackoffKey)
+		}
+		res.InitialBackoff = backoff
+	}
+
+	if val := config[RetryMaxBackoffKey]; val != "" {
+		backoff, err := time.ParseDuration(val)
+		if err != nil {
+			return RetryConfig{}, errors.Wrapf(err, "could not parse %s (expected time.Duration)", RetryMaxBackoffKey)
+		}
+		res.MaxBackoff = backoff
+	}
+
+	return res, nil
+}
+
+// throttlingErrorMessages are lower-cased fragments of the error messages the cloud providers
+// return when a request is throttled. Errors from plugins cross a gRPC boundary, so matching on
+// the message is the only reliable way to classify them.
+var throttlingErrorMessages = []string{
+	"throttl",              // AWS: Throttling, ThrottlingException, RequestThrottled
+	"requestlimitexceeded", // AWS EC2
+	"ratelimitexceeded",    // GCP
+	"too many requests",    // HTTP 429 (Azure, GCP)
+	"status code: 429",     // AWS SDK
+	"statuscode=429",       // Azure SDK
+	"error 429",            // GCP SDK
+	"serverbusy",           // Azure
+}
+
+// IsThrottlingError returns true if err looks like the result of a cloud API throttling or
+// temporarily rejecting a request.
+func IsThrottlingError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range throttlingErrorMessages {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retrier retries operations that fail due to throttling, using exponential backoff with jitter.
+type retrier struct {
+	config RetryConfig
+	sleep  func(time.Duration)
+}
+
+func newRetrier(config RetryConfig) *retrier {
+	return &retrier{
+		config: config,
+		sleep:  time.Sleep,
+	}
+}
+
+// do invokes fn until it succeeds, returns an error that is not a throttling error, or the
+// maximum number of attempts is reached. The last error is returned.
+func (r *retrier) do(fn func() error) error {
+	backoff := r.config.InitialBackoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || !IsThrottlingError(err) || attempt >= r.config.MaxAttempts {
+			break
+		}
+
+		delay := wait.Jitter(backoff, retryJitterFactor)
+		if r.config.MaxBackoff > 0 && delay > r.config.MaxBackoff {
+			delay = r.config.MaxBackoff
+		}
+		r.sleep(delay)
+
+		backoff = time.Duration(float64(backoff) * retryBackoffFactor)
+	}
+
+	return err
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetryConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]string
+		expected    RetryConfig
+		expectError bool
+	}{
+		{
+			name:     "empty config returns defaults",
+			config:   map[string]string{},
+			expected: DefaultRetryConfig(),
+		},
+		{
+			name: "all keys are parsed",
+			config: map[string]string{
+				RetryMaxAttemptsKey:    "3",
+				RetryInitialBackoffKey: "500ms",
+				RetryMaxBackoffKey:     "10s",
+			},
+			expected: RetryConfig{MaxAttempts: 3, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 10 * time.Second},
+		},
+		{
+			name:        "invalid max attempts returns an error",
+			config:      map[string]string{RetryMaxAttemptsKey: "foo"},
+			expectError: true,
+		},
+		{
+			name:        "max attempts less than 1 returns an error",
+			config:      map[string]string{RetryMaxAttemptsKey: "0"},
+			expectError: true,
+		},
+		{
+			name:        "invalid backoff returns an error",
+			config:      map[string]string{RetryInitialBackoffKey: "foo"},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := ParseRetryConfig(test.config)
+
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestIsThrottlingError(t *testing.T) {
+	assert.False(t, IsThrottlingError(nil))
+	assert.False(t, IsThrottlingError(errors.New("InvalidVolume.NotFound: the volume does not exist")))
+	assert.True(t, IsThrottlingError(errors.New("RequestLimitExceeded: Request limit exceeded.")))
+	assert.True(t, IsThrottlingError(errors.New("ThrottlingException: Rate exceeded")))
+	assert.True(t, IsThrottlingError(errors.New("googleapi: Error 429: Rate Limit Exceeded, rateLimitExceeded")))
+	assert.True(t, IsThrottlingError(errors.New("compute.SnapshotsClient#CreateOrUpdate: Failure sending request: StatusCode=429")))
+}
+
+func TestRetrierDo(t *testing.T) {
+	throttled := errors.New("Throttling: Rate exceeded")
+
+	tests := []struct {
+		name           string
+		errs           []error
+		expectedCalls  int
+		expectedErr    error
+		expectedSleeps int
+	}{
+		{
+			name:          "success on first attempt doesn't retry",
+			errs:          []error{nil},
+			expectedCalls: 1,
+		},
+		{
+			name:           "throttling errors are retried until success",
+			errs:           []error{throttled, throttled, nil},
+			expectedCalls:  3,
+			expectedSleeps: 2,
+		},
+		{
+			name:          "non-throttling errors are not retried",
+			errs:          []error{errors.New("boom")},
+			expectedCalls: 1,
+			expectedErr:   errors.New("boom"),
+		},
+		{
+			name:           "gives up after max attempts",
+			errs:           []error{throttled, throttled, throttled, throttled},
+			expectedCalls:  3,
+			expectedErr:    throttled,
+			expectedSleeps: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sleeps []time.Duration
+
+			r := newRetrier(RetryConfig{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: 1500 * time.Millisecond})
+			r.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+			calls := 0
+			err := r.do(func() error {
+				err := test.errs[calls]
+				calls++
+				return err
+			})
+
+			if test.expectedErr != nil {
+				assert.EqualError(t, err, test.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedCalls, calls)
+			assert.Len(t, sleeps, test.expectedSleeps)
+			for _, d := range sleeps {
+				assert.True(t, d >= time.Second && d <= 1500*time.Millisecond, "sleep %v out of range", d)
+			}
+		})
+	}
+}
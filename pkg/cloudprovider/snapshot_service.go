@@ -21,14 +21,16 @@ import (
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
 )
 
 // SnapshotService exposes Ark-specific operations for snapshotting and restoring block
 // volumes.
 type SnapshotService interface {
-	// CreateSnapshot triggers a snapshot for the specified cloud volume and tags it with metadata.
-	// it returns the cloud snapshot ID, or an error if a problem is encountered triggering the snapshot via
-	// the cloud API.
+	// CreateSnapshot triggers a snapshot for the specified cloud volume and tags it with metadata,
+	// along with the service's scope tags. It returns the cloud snapshot ID, or an error if a problem
+	// is encountered triggering the snapshot via the cloud API.
 	CreateSnapshot(volumeID, volumeAZ string, tags map[string]string) (string, error)
 
 	// CreateVolumeFromSnapshot triggers a restore operation to create a new cloud volume from the specified
@@ -36,6 +38,17 @@ type SnapshotService interface {
 	// encountered triggering the restore via the cloud API.
 	CreateVolumeFromSnapshot(snapshotID, volumeType, volumeAZ string, iops *int64) (string, error)
 
+	// GetSnapshotPhase returns the current phase of the specified snapshot in the cloud provider API.
+	GetSnapshotPhase(snapshotID string) (api.SnapshotPhase, error)
+
+	// CopySnapshot triggers a copy of the specified snapshot to another region via the cloud API.
+	// It returns the ID of the copy.
+	CopySnapshot(snapshotID, region string) (string, error)
+
+	// ListSnapshots returns the IDs of all snapshots in the cloud API that have all of the
+	// specified tags and the service's scope tags.
+	ListSnapshots(tagFilters map[string]string) ([]string, error)
+
 	// DeleteSnapshot triggers a deletion of the specified Ark snapshot via the cloud API. It returns an
 	// error if a problem is encountered triggering the deletion via the cloud API.
 	DeleteSnapshot(snapshotID string) error
@@ -50,6 +63,11 @@ type SnapshotService interface {
 	SetVolumeID(pv runtime.Unstructured, volumeID string) (runtime.Unstructured, error)
 }
 
+// SnapshotCopyRegionKey is the PersistentVolumeProvider config key for the region that
+// volume snapshots are copied to for disaster recovery. Snapshots are not copied if it's
+// not set.
+const SnapshotCopyRegionKey = "snapshotCopyRegion"
+
 const (
 	volumeCreateWaitTimeout  = 30 * time.Second
 	volumeCreatePollInterval = 1 * time.Second
@@ -57,15 +75,39 @@ const (
 
 type snapshotService struct {
 	blockStore BlockStore
+	retrier    *retrier
+	scopeTags  map[string]string
 }
 
 var _ SnapshotService = &snapshotService{}
 
-// NewSnapshotService creates a snapshot service using the provided block store
-func NewSnapshotService(blockStore BlockStore) SnapshotService {
+// NewSnapshotService creates a snapshot service using the provided block store. Calls to
+// CreateSnapshot, GetVolumeInfo and GetSnapshotPhase that are throttled by the cloud provider
+// are retried according to retryConfig. scopeTags are applied to every snapshot the service
+// creates and required of every snapshot it lists, so that snapshots taken by other Ark
+// installations sharing the same cloud account are never listed.
+func NewSnapshotService(blockStore BlockStore, retryConfig RetryConfig, scopeTags map[string]string) SnapshotService {
 	return &snapshotService{
 		blockStore: blockStore,
+		retrier:    newRetrier(retryConfig),
+		scopeTags:  scopeTags,
+	}
+}
+
+// withScopeTags returns a copy of tags that also contains the service's scope tags.
+func (sr *snapshotService) withScopeTags(tags map[string]string) map[string]string {
+	if len(sr.scopeTags) == 0 {
+		return tags
+	}
+
+	res := make(map[string]string, len(tags)+len(sr.scopeTags))
+	for k, v := range tags {
+		res[k] = v
+	}
+	for k, v := range sr.scopeTags {
+		res[k] = v
 	}
+	return res
 }
 
 func (sr *snapshotService) CreateVolumeFromSnapshot(snapshotID string, volumeType string, volumeAZ string, iops *int64) (string, error) {
@@ -93,7 +135,55 @@ func (sr *snapshotService) CreateVolumeFromSnapshot(snapshotID string, volumeTyp
 }
 
 func (sr *snapshotService) CreateSnapshot(volumeID, volumeAZ string, tags map[string]string) (string, error) {
-	return sr.blockStore.CreateSnapshot(volumeID, volumeAZ, tags)
+	var snapshotID string
+
+	tags = sr.withScopeTags(tags)
+
+	err := sr.retrier.do(func() error {
+		var err error
+		snapshotID, err = sr.blockStore.CreateSnapshot(volumeID, volumeAZ, tags)
+		return err
+	})
+
+	return snapshotID, err
+}
+
+func (sr *snapshotService) GetSnapshotPhase(snapshotID string) (api.SnapshotPhase, error) {
+	var phase api.SnapshotPhase
+
+	err := sr.retrier.do(func() error {
+		var err error
+		phase, err = sr.blockStore.GetSnapshotPhase(snapshotID)
+		return err
+	})
+
+	return phase, err
+}
+
+func (sr *snapshotService) CopySnapshot(snapshotID, region string) (string, error) {
+	var copySnapshotID string
+
+	err := sr.retrier.do(func() error {
+		var err error
+		copySnapshotID, err = sr.blockStore.CopySnapshot(snapshotID, region)
+		return err
+	})
+
+	return copySnapshotID, err
+}
+
+func (sr *snapshotService) ListSnapshots(tagFilters map[string]string) ([]string, error) {
+	var snapshotIDs []string
+
+	tagFilters = sr.withScopeTags(tagFilters)
+
+	err := sr.retrier.do(func() error {
+		var err error
+		snapshotIDs, err = sr.blockStore.ListSnapshots(tagFilters)
+		return err
+	})
+
+	return snapshotIDs, err
 }
 
 func (sr *snapshotService) DeleteSnapshot(snapshotID string) error {
@@ -101,7 +191,18 @@ func (sr *snapshotService) DeleteSnapshot(snapshotID string) error {
 }
 
 func (sr *snapshotService) GetVolumeInfo(volumeID, volumeAZ string) (string, *int64, error) {
-	return sr.blockStore.GetVolumeInfo(volumeID, volumeAZ)
+	var (
+		volumeType string
+		iops       *int64
+	)
+
+	err := sr.retrier.do(func() error {
+		var err error
+		volumeType, iops, err = sr.blockStore.GetVolumeInfo(volumeID, volumeAZ)
+		return err
+	})
+
+	return volumeType, iops, err
 }
 
 func (sr *snapshotService) GetVolumeID(pv runtime.Unstructured) (string, error) {
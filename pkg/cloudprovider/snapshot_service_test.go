@@ -0,0 +1,58 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tagRecordingBlockStore records the tags passed to CreateSnapshot and ListSnapshots.
+type tagRecordingBlockStore struct {
+	BlockStore
+
+	createTags  map[string]string
+	listFilters map[string]string
+}
+
+func (b *tagRecordingBlockStore) CreateSnapshot(volumeID, volumeAZ string, tags map[string]string) (string, error) {
+	b.createTags = tags
+	return "snap-1", nil
+}
+
+func (b *tagRecordingBlockStore) ListSnapshots(tagFilters map[string]string) ([]string, error) {
+	b.listFilters = tagFilters
+	return []string{"snap-1"}, nil
+}
+
+func TestSnapshotServiceScopeTags(t *testing.T) {
+	blockStore := &tagRecordingBlockStore{}
+	service := NewSnapshotService(blockStore, RetryConfig{MaxAttempts: 1}, map[string]string{"scope": "bucket"})
+
+	tags := map[string]string{"backup": "backup-1"}
+	_, err := service.CreateSnapshot("vol-1", "zone-1", tags)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"backup": "backup-1", "scope": "bucket"}, blockStore.createTags)
+	// the caller's tags aren't modified
+	assert.Equal(t, map[string]string{"backup": "backup-1"}, tags)
+
+	_, err = service.ListSnapshots(map[string]string{"backup": ""})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"backup": "", "scope": "bucket"}, blockStore.listFilters)
+}
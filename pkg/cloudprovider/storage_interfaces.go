@@ -20,9 +20,17 @@ import (
 	"io"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"k8s.io/apimachinery/pkg/runtime"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
 )
 
+// ErrObjectNotModified is returned by ConditionalObjectStore.GetObjectIfChanged when the
+// object's current ETag matches the one provided.
+var ErrObjectNotModified = errors.New("object not modified")
+
 // ObjectStore exposes basic object-storage operations required
 // by Ark.
 type ObjectStore interface {
@@ -57,6 +65,29 @@ type ObjectStore interface {
 	CreateSignedURL(bucket, key string, ttl time.Duration) (string, error)
 }
 
+// ConditionalObjectStore is an optional interface that an ObjectStore can implement
+// to skip downloading objects that haven't changed since they were last fetched.
+// Object stores that don't implement it are read using GetObject.
+type ConditionalObjectStore interface {
+	// GetObjectIfChanged retrieves the object with the given key from the specified
+	// bucket in object storage along with its current ETag, unless etag is non-empty
+	// and matches the object's current ETag, in which case it returns ErrObjectNotModified.
+	// An empty ETag is returned if the object store can't provide one.
+	GetObjectIfChanged(bucket, key, etag string) (body io.ReadCloser, newETag string, err error)
+}
+
+// GetObjectIfChanged fetches the object with the given key from the specified bucket using
+// objectStore's GetObjectIfChanged if it's a ConditionalObjectStore, and otherwise using
+// GetObject, in which case the object is always returned with an empty ETag.
+func GetObjectIfChanged(objectStore ObjectStore, bucket, key, etag string) (io.ReadCloser, string, error) {
+	if conditional, ok := objectStore.(ConditionalObjectStore); ok {
+		return conditional.GetObjectIfChanged(bucket, key, etag)
+	}
+
+	body, err := objectStore.GetObject(bucket, key)
+	return body, "", err
+}
+
 // BlockStore exposes basic block-storage operations required
 // by Ark.
 type BlockStore interface {
@@ -87,6 +118,22 @@ type BlockStore interface {
 	// set of tags to the snapshot.
 	CreateSnapshot(volumeID, volumeAZ string, tags map[string]string) (snapshotID string, err error)
 
+	// GetSnapshotPhase returns whether the specified volume snapshot is still being
+	// processed by the cloud provider (InProgress), is ready to be used (Completed),
+	// or could not be completed (Failed).
+	GetSnapshotPhase(snapshotID string) (api.SnapshotPhase, error)
+
+	// CopySnapshot starts copying the specified volume snapshot to another region, and
+	// returns the ID of the copy. The returned ID must be usable with GetSnapshotPhase and
+	// DeleteSnapshot. Block stores that don't support copying snapshots return an error.
+	CopySnapshot(snapshotID, region string) (copySnapshotID string, err error)
+
+	// ListSnapshots returns the IDs of all volume snapshots that have all of the
+	// specified tags. A tag with an empty value matches snapshots that have the tag
+	// with any value. An empty set of tags matches every snapshot the block store
+	// has access to.
+	ListSnapshots(tagFilters map[string]string) ([]string, error)
+
 	// DeleteSnapshot deletes the specified volume snapshot.
 	DeleteSnapshot(snapshotID string) error
 }
@@ -0,0 +1,112 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ProxyURLKey is the provider config key for the URL of an HTTP(S) proxy to send cloud API
+	// requests through. It defaults to the proxy from the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY
+	// environment variables.
+	ProxyURLKey = "proxyUrl"
+	// CACertFileKey is the provider config key for the path of a file of PEM-encoded CA
+	// certificates to trust, in addition to the system's, when connecting to cloud APIs.
+	CACertFileKey = "caCertFile"
+	// DialTimeoutKey is the provider config key for how long to wait for a connection to a
+	// cloud API to be established.
+	DialTimeoutKey = "dialTimeout"
+	// ResponseHeaderTimeoutKey is the provider config key for how long to wait for a cloud API's
+	// response headers after sending a request. It doesn't limit how long reading or writing the
+	// body takes, so large uploads and downloads aren't cut off.
+	ResponseHeaderTimeoutKey = "responseHeaderTimeout"
+
+	defaultDialTimeout         = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+)
+
+// NewHTTPClient returns an HTTP client for a provider's cloud API requests, with the proxy, CA
+// certificates, and timeouts from its config.
+func NewHTTPClient(config map[string]string) (*http.Client, error) {
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   defaultTLSHandshakeTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	if val := config[ProxyURLKey]; val != "" {
+		proxyURL, err := url.Parse(val)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse %s", ProxyURLKey)
+		}
+		if proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, errors.Errorf("%s must be an absolute URL, e.g. http://proxy:3128", ProxyURLKey)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if val := config[CACertFileKey]; val != "" {
+		pem, err := ioutil.ReadFile(val)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading %s", CACertFileKey)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("%s %s does not contain any PEM-encoded certificates", CACertFileKey, val)
+		}
+
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	dialTimeout := defaultDialTimeout
+	if val := config[DialTimeoutKey]; val != "" {
+		timeout, err := time.ParseDuration(val)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse %s (expected time.Duration)", DialTimeoutKey)
+		}
+		dialTimeout = timeout
+	}
+	transport.DialContext = (&net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+
+	if val := config[ResponseHeaderTimeoutKey]; val != "" {
+		timeout, err := time.ParseDuration(val)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse %s (expected time.Duration)", ResponseHeaderTimeoutKey)
+		}
+		transport.ResponseHeaderTimeout = timeout
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
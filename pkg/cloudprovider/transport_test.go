@@ -0,0 +1,116 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	client, err := NewHTTPClient(map[string]string{
+		ProxyURLKey:              "http://proxy.example.com:3128",
+		DialTimeoutKey:           "5s",
+		ResponseHeaderTimeoutKey: "1m",
+	})
+	require.NoError(t, err)
+
+	transport := client.Transport.(*http.Transport)
+	req, err := http.NewRequest("GET", "https://s3.amazonaws.com/bucket", nil)
+	require.NoError(t, err)
+	proxyURL, err := transport.Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.example.com:3128", proxyURL.String())
+	assert.Equal(t, time.Minute, transport.ResponseHeaderTimeout)
+
+	// no timeout on the client as a whole, so large transfers aren't cut off
+	assert.Equal(t, time.Duration(0), client.Timeout)
+}
+
+func TestNewHTTPClientInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]string
+		expectedErr string
+	}{
+		{
+			name:        "relative proxy URL",
+			config:      map[string]string{ProxyURLKey: "proxy:3128"},
+			expectedErr: "proxyUrl must be an absolute URL, e.g. http://proxy:3128",
+		},
+		{
+			name:        "invalid dial timeout",
+			config:      map[string]string{DialTimeoutKey: "5"},
+			expectedErr: `could not parse dialTimeout (expected time.Duration): time: missing unit in duration "5"`,
+		},
+		{
+			name:        "invalid response header timeout",
+			config:      map[string]string{ResponseHeaderTimeoutKey: "soon"},
+			expectedErr: `could not parse responseHeaderTimeout (expected time.Duration): time: invalid duration "soon"`,
+		},
+		{
+			name:        "missing CA cert file",
+			config:      map[string]string{CACertFileKey: "/nonexistent/ca.pem"},
+			expectedErr: "error reading caCertFile: open /nonexistent/ca.pem: no such file or directory",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewHTTPClient(test.config)
+			assert.EqualError(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestNewHTTPClientCACertFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// without the server's CA, its certificate isn't trusted
+	client, err := NewHTTPClient(nil)
+	require.NoError(t, err)
+	_, err = client.Get(server.URL)
+	assert.Error(t, err)
+
+	caCertFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, ioutil.WriteFile(caCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+
+	client, err = NewHTTPClient(map[string]string{CACertFileKey: caCertFile})
+	require.NoError(t, err)
+	res, err := client.Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	require.NoError(t, ioutil.WriteFile(caCertFile, []byte("not a certificate"), 0600))
+	_, err = NewHTTPClient(map[string]string{CACertFileKey: caCertFile})
+	assert.EqualError(t, err, "caCertFile "+caCertFile+" does not contain any PEM-encoded certificates")
+}
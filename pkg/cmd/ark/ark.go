@@ -29,6 +29,7 @@ import (
 	"github.com/heptio/ark/pkg/cmd/cli/delete"
 	"github.com/heptio/ark/pkg/cmd/cli/describe"
 	"github.com/heptio/ark/pkg/cmd/cli/get"
+	"github.com/heptio/ark/pkg/cmd/cli/migrate"
 	"github.com/heptio/ark/pkg/cmd/cli/plugin"
 	"github.com/heptio/ark/pkg/cmd/cli/restore"
 	"github.com/heptio/ark/pkg/cmd/cli/schedule"
@@ -57,7 +58,7 @@ operations can also be performed as 'ark backup get' and 'ark schedule create'.`
 		backup.NewCommand(f),
 		schedule.NewCommand(f),
 		restore.NewCommand(f),
-		server.NewCommand(),
+		server.NewCommand(f),
 		version.NewCommand(),
 		get.NewCommand(f),
 		describe.NewCommand(f),
@@ -65,6 +66,7 @@ operations can also be performed as 'ark backup get' and 'ark schedule create'.`
 		runplugin.NewCommand(f),
 		plugin.NewCommand(f),
 		delete.NewCommand(f),
+		migrate.NewCommand(f),
 		cliclient.NewCommand(),
 		completion.NewCommand(),
 	)
@@ -36,6 +36,7 @@ func NewCommand(f client.Factory) *cobra.Command {
 		NewDescribeCommand(f, "describe"),
 		NewDownloadCommand(f),
 		NewDeleteCommand(f, "delete"),
+		NewDiffCommand(f, "diff"),
 	)
 
 	return c
@@ -18,18 +18,23 @@ package backup
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kuberrs "k8s.io/apimachinery/pkg/util/errors"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
 	"github.com/heptio/ark/pkg/cmd/util/flag"
 	"github.com/heptio/ark/pkg/cmd/util/output"
+	arkdiscovery "github.com/heptio/ark/pkg/discovery"
 )
 
 func NewCreateCommand(f client.Factory, use string) *cobra.Command {
@@ -41,12 +46,14 @@ func NewCreateCommand(f client.Factory, use string) *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 		Run: func(c *cobra.Command, args []string) {
 			cmd.CheckError(o.Complete(args))
-			cmd.CheckError(o.Validate(c, args))
+			cmd.CheckError(o.Validate(c, args, f))
 			cmd.CheckError(o.Run(c, f))
 		},
 	}
 
 	o.BindFlags(c.Flags())
+	// dry runs are only offered for individual backups, not schedules, so this isn't in BindFlags
+	c.Flags().BoolVar(&o.DryRunItems, "dry-run-items", o.DryRunItems, "don't back up any data; instead, count the items that would be included in the backup's status and list them in its log")
 	output.BindFlags(c.Flags())
 	output.ClearOutputFlagDefault(c)
 
@@ -64,24 +71,51 @@ type CreateOptions struct {
 	Labels                  flag.Map
 	Selector                flag.LabelSelector
 	IncludeClusterResources flag.OptionalBool
+	DeletionPolicy          *flag.Enum
+	IncludeArkResources     flag.OptionalBool
+	ShardBy                 *flag.Enum
+	FailurePolicy           *flag.Enum
+	QuiesceSelector         flag.LabelSelector
+	QuiesceTimeout          time.Duration
+	ValidateAgainstCluster  bool
+	DryRunItems             bool
+}
+
+var deletionPolicies = []string{
+	string(api.BackupDeletionPolicyAll),
+	string(api.BackupDeletionPolicySnapshotsOnly),
+	string(api.BackupDeletionPolicyObjectStorageOnly),
+}
+
+var shardBys = []string{
+	string(api.BackupShardByNamespace),
+	string(api.BackupShardByResourceGroup),
+}
+
+var failurePolicies = []string{
+	string(api.BackupFailurePolicyContinue),
+	string(api.BackupFailurePolicyFailFast),
 }
 
 func NewCreateOptions() *CreateOptions {
 	return &CreateOptions{
-		TTL:                     30 * 24 * time.Hour,
 		IncludeNamespaces:       flag.NewStringArray("*"),
 		Labels:                  flag.NewMap(),
 		SnapshotVolumes:         flag.NewOptionalBool(nil),
 		IncludeClusterResources: flag.NewOptionalBool(nil),
+		DeletionPolicy:          flag.NewEnum("", deletionPolicies...),
+		IncludeArkResources:     flag.NewOptionalBool(nil),
+		ShardBy:                 flag.NewEnum("", shardBys...),
+		FailurePolicy:           flag.NewEnum("", failurePolicies...),
 	}
 }
 
 func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
-	flags.DurationVar(&o.TTL, "ttl", o.TTL, "how long before the backup can be garbage collected")
-	flags.Var(&o.IncludeNamespaces, "include-namespaces", "namespaces to include in the backup (use '*' for all namespaces)")
-	flags.Var(&o.ExcludeNamespaces, "exclude-namespaces", "namespaces to exclude from the backup")
-	flags.Var(&o.IncludeResources, "include-resources", "resources to include in the backup, formatted as resource.group, such as storageclasses.storage.k8s.io (use '*' for all resources)")
-	flags.Var(&o.ExcludeResources, "exclude-resources", "resources to exclude from the backup, formatted as resource.group, such as storageclasses.storage.k8s.io")
+	flags.DurationVar(&o.TTL, "ttl", o.TTL, "how long before the backup can be garbage collected. If not set, the server's default backup TTL (720h unless configured otherwise) is used. Use a negative value, such as --ttl=-1s, for a backup that never expires")
+	flags.Var(&o.IncludeNamespaces, "include-namespaces", "namespaces to include in the backup (use '*' for all namespaces). May include patterns such as 'kube-*'")
+	flags.Var(&o.ExcludeNamespaces, "exclude-namespaces", "namespaces to exclude from the backup. May include patterns such as '*-system'")
+	flags.Var(&o.IncludeResources, "include-resources", "resources to include in the backup, formatted as resource.group, such as storageclasses.storage.k8s.io (use '*' for all resources, or '*.group' for all resources in a group)")
+	flags.Var(&o.ExcludeResources, "exclude-resources", "resources to exclude from the backup, formatted as resource.group, such as storageclasses.storage.k8s.io (use '*.group' for all resources in a group)")
 	flags.Var(&o.Labels, "labels", "labels to apply to the backup")
 	flags.VarP(&o.Selector, "selector", "l", "only back up resources matching this label selector")
 	f := flags.VarPF(&o.SnapshotVolumes, "snapshot-volumes", "", "take snapshots of PersistentVolumes as part of the backup")
@@ -91,14 +125,72 @@ func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
 
 	f = flags.VarPF(&o.IncludeClusterResources, "include-cluster-resources", "", "include cluster-scoped resources in the backup")
 	f.NoOptDefVal = "true"
+
+	f = flags.VarPF(&o.IncludeArkResources, "include-ark-resources", "", "include Ark's Config and Schedules in the backup, even if the Ark namespace isn't included. Defaults to true.")
+	f.NoOptDefVal = "true"
+
+	flags.Var(o.DeletionPolicy, "deletion-policy", fmt.Sprintf("which of the backup's data to delete when the backup is deleted. Valid values are %s. Defaults to All.", strings.Join(deletionPolicies, ", ")))
+	flags.BoolVar(&o.ValidateAgainstCluster, "validate", o.ValidateAgainstCluster, "check that the included and excluded resources are served by the cluster, and that the included namespaces exist, before creating")
+	flags.Var(o.ShardBy, "shard-by", fmt.Sprintf("store the backup's contents in object storage as a separate tarball for each namespace or API group, so that restores only download the ones they need. Valid values are %s. Defaults to a single tarball.", strings.Join(shardBys, ", ")))
+	flags.Var(o.FailurePolicy, "failure-policy", fmt.Sprintf("what to do when an item can't be backed up: Continue backing up the rest of the items and mark the backup PartiallyFailed, or FailFast to stop and mark it Failed. Valid values are %s. Defaults to Continue.", strings.Join(failurePolicies, ", ")))
+	flags.Var(&o.QuiesceSelector, "quiesce-selector", "scale the Deployments and StatefulSets matching this label selector down to zero replicas while the backup is taken, restoring them with their original replica counts")
+	flags.DurationVar(&o.QuiesceTimeout, "quiesce-timeout", o.QuiesceTimeout, "how long to wait for quiesced workloads' pods to terminate before backing up anyway. Defaults to 5m.")
 }
 
-func (o *CreateOptions) Validate(c *cobra.Command, args []string) error {
+func (o *CreateOptions) Validate(c *cobra.Command, args []string, f client.Factory) error {
 	if err := output.ValidateFlags(c); err != nil {
 		return err
 	}
 
-	return nil
+	if o.Selector.LabelSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(o.Selector.LabelSelector); err != nil {
+			return errors.Wrap(err, "invalid --selector")
+		}
+	}
+
+	if o.QuiesceSelector.LabelSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(o.QuiesceSelector.LabelSelector); err != nil {
+			return errors.Wrap(err, "invalid --quiesce-selector")
+		}
+	} else if o.QuiesceTimeout != 0 {
+		return errors.New("--quiesce-timeout requires --quiesce-selector")
+	}
+
+	if !o.ValidateAgainstCluster {
+		return nil
+	}
+
+	kubeClient, err := f.KubeClient()
+	if err != nil {
+		return err
+	}
+
+	discoveryHelper, err := arkdiscovery.NewHelper(kubeClient.Discovery(), logrus.New())
+	if err != nil {
+		return err
+	}
+
+	var resources []string
+	resources = append(resources, o.IncludeResources...)
+	resources = append(resources, o.ExcludeResources...)
+
+	errs := validateResources(discoveryHelper, resources)
+	errs = append(errs, validateNamespaces(kubeClient.CoreV1().Namespaces(), o.IncludeNamespaces)...)
+
+	return kuberrs.NewAggregate(errs)
+}
+
+// Quiesce returns the quiesce spec for the options' --quiesce-selector and --quiesce-timeout, or
+// nil if no workloads are quiesced.
+func (o *CreateOptions) Quiesce() *api.QuiesceSpec {
+	if o.QuiesceSelector.LabelSelector == nil {
+		return nil
+	}
+
+	return &api.QuiesceSpec{
+		LabelSelector: o.QuiesceSelector.LabelSelector,
+		Timeout:       metav1.Duration{Duration: o.QuiesceTimeout},
+	}
 }
 
 func (o *CreateOptions) Complete(args []string) error {
@@ -127,9 +219,19 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 			SnapshotVolumes:    o.SnapshotVolumes.Value,
 			TTL:                metav1.Duration{Duration: o.TTL},
 			IncludeClusterResources: o.IncludeClusterResources.Value,
+			DeletionPolicy: api.BackupDeletionPolicy(o.DeletionPolicy.String()),
+			IncludeArkResources: o.IncludeArkResources.Value,
+			DryRun: o.DryRunItems,
+			ShardBy: api.BackupShardBy(o.ShardBy.String()),
+			Quiesce: o.Quiesce(),
+			FailurePolicy: api.BackupFailurePolicy(o.FailurePolicy.String()),
 		},
 	}
 
+	if username := f.Username(); username != "" {
+		backup.Annotations = map[string]string{api.RequestedByAnnotation: username}
+	}
+
 	if printed, err := output.PrintWithFormat(c, backup); printed || err != nil {
 		return err
 	}
@@ -139,7 +241,11 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 		return err
 	}
 
-	fmt.Printf("Backup request %q submitted successfully.\n", backup.Name)
+	if backup.Spec.DryRun {
+		fmt.Printf("Dry-run backup request %q submitted successfully. No data will be backed up.\n", backup.Name)
+	} else {
+		fmt.Printf("Backup request %q submitted successfully.\n", backup.Name)
+	}
 	fmt.Printf("Run `ark backup describe %s` for more details.\n", backup.Name)
 	return nil
 }
@@ -18,30 +18,55 @@ package backup
 
 import (
 	"bufio"
-	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/backup"
+	"github.com/heptio/ark/pkg/cmd/cli/completion"
 	clientset "github.com/heptio/ark/pkg/generated/clientset/versioned"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kuberrs "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/util/flag"
+	"github.com/heptio/ark/pkg/cmd/util/output"
 )
 
-// NewDeleteCommand creates a new command that deletes a backup.
+// NewDeleteCommand creates a new command that deletes backups.
 func NewDeleteCommand(f client.Factory, use string) *cobra.Command {
-	o := &DeleteOptions{}
+	o := &DeleteOptions{
+		DeletionPolicy: flag.NewEnum("", deletionPolicies...),
+		Timeout:        10 * time.Minute,
+	}
 
 	c := &cobra.Command{
-		Use:   fmt.Sprintf("%s NAME", use),
-		Short: "Delete a backup",
-		Args:  cobra.ExactArgs(1),
+		Use:   fmt.Sprintf("%s [NAME...]", use),
+		Short: "Delete backups",
+		Long: `Delete one or more backups, by name, by label selector, or all of them.
+
+With --selector or --all, --phase may be used to only delete backups in certain phases, such as
+the backups that failed.`,
+		Example: `  # delete a backup
+  ark backup delete backup-1
+
+  # delete all of the backups created by a schedule that failed
+  ark backup delete --selector ark-schedule=daily --phase Failed,FailedValidation
+
+  # delete all backups without asking for confirmation
+  ark backup delete --all --confirm
+
+  # delete a backup, and report any of its data that couldn't be deleted
+  ark backup delete backup-1 --wait`,
 		Run: func(c *cobra.Command, args []string) {
 			cmd.CheckError(o.Complete(f, args))
 			cmd.CheckError(o.Validate(c, args, f))
@@ -51,27 +76,42 @@ func NewDeleteCommand(f client.Factory, use string) *cobra.Command {
 
 	o.BindFlags(c.Flags())
 
+	completion.CompleteNames(c, "backups")
+
 	return c
 }
 
-// DeleteOptions contains parameters for deleting a backup.
+// DeleteOptions contains parameters for deleting backups.
 type DeleteOptions struct {
-	Name    string
-	Confirm bool
+	Names          []string
+	Selector       string
+	All            bool
+	Phases         flag.StringArray
+	Confirm        bool
+	DeletionPolicy *flag.Enum
+	Wait           bool
+	Timeout        time.Duration
 
-	client    clientset.Interface
-	namespace string
-	backup    *v1.Backup
+	client       clientset.Interface
+	namespace    string
+	backups      []v1.Backup
+	pollInterval time.Duration
 }
 
 // BindFlags binds options for this command to flags.
 func (o *DeleteOptions) BindFlags(flags *pflag.FlagSet) {
 	flags.BoolVar(&o.Confirm, "confirm", o.Confirm, "Confirm deletion")
+	flags.Var(o.DeletionPolicy, "deletion-policy", fmt.Sprintf("which of the backup's data to delete, overriding the backup's deletion policy. Valid values are %s.", strings.Join(deletionPolicies, ", ")))
+	flags.StringVarP(&o.Selector, "selector", "l", o.Selector, "delete all backups matching this label selector")
+	flags.BoolVar(&o.All, "all", o.All, "delete all backups")
+	flags.Var(&o.Phases, "phase", "with --selector or --all, only delete backups in these phases. Valid values are "+strings.Join(backupPhases, ", "))
+	flags.BoolVar(&o.Wait, "wait", o.Wait, "wait for each backup's deletion to be processed, and show the result of deleting each of its artifacts")
+	flags.DurationVar(&o.Timeout, "timeout", o.Timeout, "with --wait, maximum time to wait for each backup's deletion to be processed")
 }
 
 // Complete fills out the remainder of the parameters based on user input.
 func (o *DeleteOptions) Complete(f client.Factory, args []string) error {
-	o.Name = args[0]
+	o.Names = args
 
 	o.namespace = f.Namespace()
 
@@ -81,12 +121,6 @@ func (o *DeleteOptions) Complete(f client.Factory, args []string) error {
 	}
 	o.client = client
 
-	backup, err := o.client.ArkV1().Backups(f.Namespace()).Get(o.Name, metav1.GetOptions{})
-	if err != nil {
-		return err
-	}
-	o.backup = backup
-
 	return nil
 }
 
@@ -96,28 +130,147 @@ func (o *DeleteOptions) Validate(c *cobra.Command, args []string, f client.Facto
 		return errors.New("Ark client is not set; unable to proceed")
 	}
 
-	if o.backup == nil {
-		return errors.New("backup is not set; unable to proceed")
+	specified := 0
+	for _, set := range []bool{len(o.Names) > 0, o.Selector != "", o.All} {
+		if set {
+			specified++
+		}
+	}
+	if specified != 1 {
+		return errors.New("exactly one of backup names, --selector, or --all must be specified")
 	}
 
-	return nil
+	if len(o.Phases) > 0 && len(o.Names) > 0 {
+		return errors.New("--phase may only be used with --selector or --all")
+	}
+
+	return validatePhases(o.Phases)
+}
+
+// getBackups returns the backups to delete, by name or by listing those matching the selector, or
+// all of them, in the requested phases.
+func (o *DeleteOptions) getBackups() ([]v1.Backup, error) {
+	if len(o.Names) > 0 {
+		var backups []v1.Backup
+		for _, name := range o.Names {
+			backup, err := o.client.ArkV1().Backups(o.namespace).Get(name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			backups = append(backups, *backup)
+		}
+		return backups, nil
+	}
+
+	list, err := o.client.ArkV1().Backups(o.namespace).List(metav1.ListOptions{LabelSelector: o.Selector})
+	if err != nil {
+		return nil, err
+	}
+
+	return filterBackups(list.Items, o.Phases, 0, 0, time.Now()), nil
 }
 
 // Run performs the delete backup operation.
 func (o *DeleteOptions) Run() error {
+	backups, err := o.getBackups()
+	if err != nil {
+		return err
+	}
+	o.backups = backups
+
+	if len(o.backups) == 0 {
+		fmt.Println("No backups found.")
+		return nil
+	}
+
+	if len(o.Names) == 0 {
+		fmt.Printf("The following %d backups will be deleted:\n", len(o.backups))
+		for _, backup := range o.backups {
+			fmt.Printf("  %s (%s)\n", backup.Name, backup.Status.Phase)
+		}
+	}
+
 	if !o.Confirm && !getConfirmation() {
 		// Don't do anything unless we get confirmation
 		return nil
 	}
 
-	deleteRequest := backup.NewDeleteBackupRequest(o.backup.Name, string(o.backup.UID))
+	var (
+		errs     []error
+		requests []*v1.DeleteBackupRequest
+	)
+	for _, b := range o.backups {
+		deleteRequest := backup.NewDeleteBackupRequest(b.Name, string(b.UID))
+		deleteRequest.Spec.DeletionPolicy = v1.BackupDeletionPolicy(o.DeletionPolicy.String())
+
+		req, err := o.client.ArkV1().DeleteBackupRequests(o.namespace).Create(deleteRequest)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "error requesting deletion of backup %q", b.Name))
+			continue
+		}
+		requests = append(requests, req)
+
+		fmt.Printf("Request to delete backup %q submitted successfully.\n", b.Name)
+	}
+
+	if o.Wait {
+		for _, req := range requests {
+			if err := o.waitForDeletion(req); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	} else if len(requests) > 0 {
+		fmt.Println("Backups will be fully deleted after all associated data (disk snapshots, backup files, restores) are removed.")
+	}
+
+	return kuberrs.NewAggregate(errs)
+}
+
+// waitForDeletion waits up to the timeout for req to be processed, then prints the result of
+// deleting each of its backup's artifacts. It returns an error if the backup wasn't fully deleted.
+func (o *DeleteOptions) waitForDeletion(req *v1.DeleteBackupRequest) error {
+	interval := o.pollInterval
+	if interval == 0 {
+		interval = time.Second
+	}
+
+	backupName := req.Spec.BackupName
+
+	var processed *v1.DeleteBackupRequest
+	err := wait.PollImmediate(interval, o.Timeout, func() (bool, error) {
+		res, err := o.client.ArkV1().DeleteBackupRequests(req.Namespace).Get(req.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			// requests are removed once their backup has been fully deleted
+			return true, nil
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "error getting deletion request for backup %q", backupName)
+		}
 
-	if _, err := o.client.ArkV1().DeleteBackupRequests(o.namespace).Create(deleteRequest); err != nil {
+		if res.Status.Phase != v1.DeleteBackupRequestPhaseProcessed {
+			return false, nil
+		}
+		processed = res
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.Errorf("timed out waiting for backup %q to be deleted", backupName)
+	}
+	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Request to delete backup %q submitted successfully.\nThe backup will be fully deleted after all associated data (disk snapshots, backup files, restores) are removed.\n", o.backup.Name)
-	return nil
+	if processed == nil || len(processed.Status.Errors) == 0 {
+		fmt.Printf("Backup %q deleted.\n", backupName)
+		return nil
+	}
+
+	fmt.Printf("Backup %q was not fully deleted:\n", backupName)
+	fmt.Print(output.Describe(func(d *output.Describer) {
+		output.DescribeArtifactDeletionResults(d, "  ", processed.Status.Artifacts)
+	}))
+
+	return errors.Errorf("backup %q was not fully deleted: %s", backupName, strings.Join(processed.Status.Errors, "; "))
 }
 
 func getConfirmation() bool {
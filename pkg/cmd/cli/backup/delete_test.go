@@ -0,0 +1,228 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/cmd/util/flag"
+	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestDeleteBackups(t *testing.T) {
+	backups := []runtime.Object{
+		arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("daily-1").WithLabel(v1.ScheduleLabel, "daily").WithPhase(v1.BackupPhaseCompleted).Backup,
+		arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("daily-2").WithLabel(v1.ScheduleLabel, "daily").WithPhase(v1.BackupPhaseFailed).Backup,
+		arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("manual").WithPhase(v1.BackupPhaseFailed).Backup,
+	}
+
+	tests := []struct {
+		name     string
+		options  DeleteOptions
+		expected []string
+	}{
+		{
+			name:     "by name",
+			options:  DeleteOptions{Names: []string{"manual", "daily-1"}},
+			expected: []string{"manual", "daily-1"},
+		},
+		{
+			name:     "by selector",
+			options:  DeleteOptions{Selector: v1.ScheduleLabel + "=daily"},
+			expected: []string{"daily-1", "daily-2"},
+		},
+		{
+			name:     "by selector and phase",
+			options:  DeleteOptions{Selector: v1.ScheduleLabel + "=daily", Phases: flag.NewStringArray("Failed")},
+			expected: []string{"daily-2"},
+		},
+		{
+			name:     "all in a phase",
+			options:  DeleteOptions{All: true, Phases: flag.NewStringArray("Failed")},
+			expected: []string{"daily-2", "manual"},
+		},
+		{
+			name:    "no matches",
+			options: DeleteOptions{All: true, Phases: flag.NewStringArray("InProgress")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(backups...)
+
+			// delete backup requests are created with generated names, which the fake
+			// clientset doesn't support, so they're captured instead
+			var requested []string
+			client.PrependReactor("create", "deletebackuprequests", func(action core.Action) (bool, runtime.Object, error) {
+				req := action.(core.CreateAction).GetObject().(*v1.DeleteBackupRequest)
+				requested = append(requested, req.Spec.BackupName)
+				return true, req, nil
+			})
+
+			o := test.options
+			o.Confirm = true
+			o.DeletionPolicy = flag.NewEnum("", deletionPolicies...)
+			o.client = client
+			o.namespace = "heptio-ark"
+
+			require.NoError(t, o.Run())
+			assert.Equal(t, test.expected, requested)
+		})
+	}
+}
+
+func TestDeleteOptionsValidate(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	tests := []struct {
+		name        string
+		options     DeleteOptions
+		expectedErr string
+	}{
+		{
+			name:    "names",
+			options: DeleteOptions{Names: []string{"backup-1"}},
+		},
+		{
+			name:    "all with phases",
+			options: DeleteOptions{All: true, Phases: flag.NewStringArray("Failed")},
+		},
+		{
+			name:        "nothing specified",
+			expectedErr: "exactly one of backup names, --selector, or --all must be specified",
+		},
+		{
+			name:        "names and all",
+			options:     DeleteOptions{Names: []string{"backup-1"}, All: true},
+			expectedErr: "exactly one of backup names, --selector, or --all must be specified",
+		},
+		{
+			name:        "names with phases",
+			options:     DeleteOptions{Names: []string{"backup-1"}, Phases: flag.NewStringArray("Failed")},
+			expectedErr: "--phase may only be used with --selector or --all",
+		},
+		{
+			name:        "invalid phase",
+			options:     DeleteOptions{Selector: "a=b", Phases: flag.NewStringArray("Broken")},
+			expectedErr: `invalid --phase "Broken": valid values are New, FailedValidation, InProgress, Completed, PartiallyFailed, Failed, Deleting`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := test.options
+			o.client = client
+
+			err := o.Validate(nil, nil, nil)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestDeleteBackupsWait(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("deleted").Backup,
+		arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("failed").Backup,
+	)
+
+	client.PrependReactor("create", "deletebackuprequests", func(action core.Action) (bool, runtime.Object, error) {
+		req := action.(core.CreateAction).GetObject().(*v1.DeleteBackupRequest)
+		req.Name = req.Spec.BackupName + "-request"
+		return true, req, nil
+	})
+	client.PrependReactor("get", "deletebackuprequests", func(action core.Action) (bool, runtime.Object, error) {
+		name := action.(core.GetAction).GetName()
+		if name == "deleted-request" {
+			// the request has been removed along with its backup
+			return true, nil, apierrors.NewNotFound(v1.Resource("deletebackuprequests"), name)
+		}
+
+		req := &v1.DeleteBackupRequest{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "heptio-ark", Name: name},
+			Spec:       v1.DeleteBackupRequestSpec{BackupName: "failed"},
+			Status: v1.DeleteBackupRequestStatus{
+				Phase:  v1.DeleteBackupRequestPhaseProcessed,
+				Errors: []string{"error deleting snapshot snap-1: not found"},
+				Artifacts: []v1.ArtifactDeletionResult{
+					{Artifact: v1.BackupArtifactSnapshot, Name: "snap-1", Phase: v1.ArtifactDeletionPhaseFailed, Error: "error deleting snapshot snap-1: not found"},
+					{Artifact: v1.BackupArtifactBackup, Name: "heptio-ark/failed", Phase: v1.ArtifactDeletionPhaseRetained},
+				},
+			},
+		}
+		return true, req, nil
+	})
+
+	o := DeleteOptions{
+		Names:          []string{"deleted", "failed"},
+		Confirm:        true,
+		Wait:           true,
+		DeletionPolicy: flag.NewEnum("", deletionPolicies...),
+		client:         client,
+		namespace:      "heptio-ark",
+		Timeout:        time.Minute,
+		pollInterval:   time.Millisecond,
+	}
+
+	assert.EqualError(t, o.Run(), `backup "failed" was not fully deleted: error deleting snapshot snap-1: not found`)
+}
+
+func TestDeleteBackupsWaitTimesOut(t *testing.T) {
+	client := fake.NewSimpleClientset(arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-1").Backup)
+
+	client.PrependReactor("create", "deletebackuprequests", func(action core.Action) (bool, runtime.Object, error) {
+		req := action.(core.CreateAction).GetObject().(*v1.DeleteBackupRequest)
+		req.Name = req.Spec.BackupName + "-request"
+		return true, req, nil
+	})
+	client.PrependReactor("get", "deletebackuprequests", func(action core.Action) (bool, runtime.Object, error) {
+		// the request is never processed
+		return true, &v1.DeleteBackupRequest{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "heptio-ark", Name: action.(core.GetAction).GetName()},
+			Spec:       v1.DeleteBackupRequestSpec{BackupName: "backup-1"},
+			Status:     v1.DeleteBackupRequestStatus{Phase: v1.DeleteBackupRequestPhaseInProgress},
+		}, nil
+	})
+
+	o := DeleteOptions{
+		Names:          []string{"backup-1"},
+		Confirm:        true,
+		Wait:           true,
+		DeletionPolicy: flag.NewEnum("", deletionPolicies...),
+		Timeout:        10 * time.Millisecond,
+		client:         client,
+		namespace:      "heptio-ark",
+		pollInterval:   time.Millisecond,
+	}
+
+	assert.EqualError(t, o.Run(), `timed out waiting for backup "backup-1" to be deleted`)
+}
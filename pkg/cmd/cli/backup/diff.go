@@ -0,0 +1,583 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/cli/completion"
+	"github.com/heptio/ark/pkg/cmd/util/downloadrequest"
+	"github.com/heptio/ark/pkg/cmd/util/flag"
+	arkdiscovery "github.com/heptio/ark/pkg/discovery"
+	clientset "github.com/heptio/ark/pkg/generated/clientset/versioned"
+	"github.com/heptio/ark/pkg/restore"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+func NewDiffCommand(f client.Factory, use string) *cobra.Command {
+	o := NewDiffOptions()
+
+	c := &cobra.Command{
+		Use:   use + " NAME [OTHER-NAME]",
+		Short: "Compare a backup against the cluster or another backup",
+		Long: `Compare the items in a backup against the current state of the cluster or, if a
+second backup is given, against the items in that backup.
+
+Items that are in the backup but no longer in the cluster, items in the cluster that
+the backup would have included but doesn't, and items whose spec, data, labels or
+annotations have changed since the backup was taken are reported. When comparing two
+backups, the items only in each backup and the items that changed between them are
+reported. Items with a controller owner, which are recreated by their controllers on
+restore, are ignored, as are the provenance annotations that backups add to items.`,
+		Example: `  # compare a backup against the cluster
+  ark backup diff backup-1
+
+  # compare two backups taken by a schedule
+  ark backup diff daily-20180601070000 daily-20180602070000`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args))
+			cmd.CheckError(o.Validate(c, args))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	completion.CompleteNames(c, "backups")
+
+	return c
+}
+
+type DiffOptions struct {
+	Name              string
+	OtherName         string
+	IncludeNamespaces flag.StringArray
+	ExcludeNamespaces flag.StringArray
+	IncludeResources  flag.StringArray
+	ExcludeResources  flag.StringArray
+	Timeout           time.Duration
+}
+
+func NewDiffOptions() *DiffOptions {
+	return &DiffOptions{
+		IncludeNamespaces: flag.NewStringArray("*"),
+		Timeout:           time.Minute,
+	}
+}
+
+func (o *DiffOptions) BindFlags(flags *pflag.FlagSet) {
+	flags.Var(&o.IncludeNamespaces, "include-namespaces", "namespaces to compare (use '*' for all namespaces)")
+	flags.Var(&o.ExcludeNamespaces, "exclude-namespaces", "namespaces not to compare")
+	flags.Var(&o.IncludeResources, "include-resources", "resources to compare, formatted as resource.group, such as storageclasses.storage.k8s.io (use '*' for all resources)")
+	flags.Var(&o.ExcludeResources, "exclude-resources", "resources not to compare, formatted as resource.group, such as storageclasses.storage.k8s.io")
+	flags.DurationVar(&o.Timeout, "timeout", o.Timeout, "maximum time to wait to process download request")
+}
+
+func (o *DiffOptions) Validate(c *cobra.Command, args []string) error {
+	if errs := collections.ValidateIncludesExcludes(o.IncludeNamespaces, o.ExcludeNamespaces); len(errs) > 0 {
+		return errors.Errorf("invalid namespace includes/excludes: %v", errs)
+	}
+	if errs := collections.ValidateIncludesExcludes(o.IncludeResources, o.ExcludeResources); len(errs) > 0 {
+		return errors.Errorf("invalid resource includes/excludes: %v", errs)
+	}
+	return nil
+}
+
+func (o *DiffOptions) Complete(args []string) error {
+	o.Name = args[0]
+	if len(args) > 1 {
+		o.OtherName = args[1]
+	}
+	return nil
+}
+
+func (o *DiffOptions) Run(c *cobra.Command, f client.Factory) error {
+	arkClient, err := f.Client()
+	if err != nil {
+		return err
+	}
+
+	backup, err := getBackupToDiff(arkClient, f.Namespace(), o.Name)
+	if err != nil {
+		return err
+	}
+
+	if o.OtherName != "" {
+		if _, err := getBackupToDiff(arkClient, f.Namespace(), o.OtherName); err != nil {
+			return err
+		}
+	}
+
+	kubeClient, err := f.KubeClient()
+	if err != nil {
+		return err
+	}
+
+	discoveryHelper, err := arkdiscovery.NewHelper(kubeClient.Discovery(), logrus.New())
+	if err != nil {
+		return err
+	}
+
+	filter := &itemFilter{
+		resources:  restore.GetResourceIncludesExcludes(discoveryHelper, o.IncludeResources, o.ExcludeResources),
+		namespaces: collections.NewIncludesExcludes().Includes(o.IncludeNamespaces...).Excludes(o.ExcludeNamespaces...),
+	}
+
+	backupItems, err := downloadBackupItems(arkClient, f.Namespace(), o.Name, filter, o.Timeout)
+	if err != nil {
+		return err
+	}
+
+	if o.OtherName != "" {
+		otherItems, err := downloadBackupItems(arkClient, f.Namespace(), o.OtherName, filter, o.Timeout)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Comparing backup %s against backup %s\n\n", o.Name, o.OtherName)
+		printDiff(os.Stdout, diffItems(backupItems, otherItems), "Only in "+o.Name, "Only in "+o.OtherName)
+
+		return nil
+	}
+
+	dynamicFactory, err := f.DynamicFactory()
+	if err != nil {
+		return err
+	}
+
+	selector := labels.Everything()
+	if backup.Spec.LabelSelector != nil {
+		if selector, err = metav1.LabelSelectorAsSelector(backup.Spec.LabelSelector); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	clusterItems, err := getClusterItems(discoveryHelper, dynamicFactory, backupItems, selector)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Comparing backup %s against the cluster\n\n", o.Name)
+	printDiff(os.Stdout, diffItems(backupItems, clusterItems), "Missing from cluster", "Added in cluster")
+
+	return nil
+}
+
+// getBackupToDiff gets the named backup, returning an error if it has no contents
+// to compare.
+func getBackupToDiff(arkClient clientset.Interface, namespace, name string) (*v1.Backup, error) {
+	backup, err := arkClient.ArkV1().Backups(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if backup.Spec.DryRun {
+		return nil, errors.Errorf("backup %s is a dry run and has no contents to compare", name)
+	}
+	return backup, nil
+}
+
+// itemKey identifies an item in a backup or the cluster.
+type itemKey struct {
+	resource  string
+	namespace string
+	name      string
+}
+
+func (k itemKey) String() string {
+	if k.namespace == "" {
+		return fmt.Sprintf("%s %s", k.resource, k.name)
+	}
+	return fmt.Sprintf("%s %s/%s", k.resource, k.namespace, k.name)
+}
+
+// itemFilter selects which of a backup's items are compared.
+type itemFilter struct {
+	resources  *collections.IncludesExcludes
+	namespaces *collections.IncludesExcludes
+}
+
+func (f *itemFilter) shouldInclude(key itemKey) bool {
+	if !f.resources.ShouldInclude(key.resource) {
+		return false
+	}
+	return key.namespace == "" || f.namespaces.ShouldInclude(key.namespace)
+}
+
+// downloadBackupItems downloads the named backup's contents to a temp file and reads
+// the items in it.
+func downloadBackupItems(arkClient clientset.Interface, namespace, name string, filter *itemFilter, timeout time.Duration) (map[itemKey]*unstructured.Unstructured, error) {
+	file, err := ioutil.TempFile("", name)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating temp file")
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if err := downloadrequest.StreamBackupContents(arkClient.ArkV1(), namespace, name, file, timeout); err != nil {
+		return nil, err
+	}
+
+	if _, err := file.Seek(0, 0); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	items, err := readBackupItems(file, filter)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading backup %s", name)
+	}
+	return items, nil
+}
+
+// readBackupItems reads the items from a gzipped backup tarball, keyed by resource,
+// namespace and name. Items from additional clusters and items with a controller
+// owner are skipped, and each item's metadata and status are reset as they would be
+// on restore.
+func readBackupItems(r io.Reader, filter *itemFilter) (map[itemKey]*unstructured.Unstructured, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer gzr.Close()
+
+	items := make(map[itemKey]*unstructured.Unstructured)
+
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		key, ok := itemKeyForPath(header.Name)
+		if !ok || !filter.shouldInclude(key) {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		obj := new(unstructured.Unstructured)
+		if err := json.Unmarshal(data, obj); err != nil {
+			return nil, errors.Wrapf(err, "error decoding %s", header.Name)
+		}
+
+		if restore.HasControllerOwner(obj.GetOwnerReferences()) {
+			continue
+		}
+
+		if items[key], err = normalizeItem(obj); err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
+// itemKeyForPath returns the key for the item at the given path in a backup
+// tarball, which is resources/<resource>/<cluster|namespaces>/[<namespace>/]<name>.json.
+func itemKeyForPath(path string) (itemKey, bool) {
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	if len(parts) < 4 || parts[0] != v1.ResourcesDir || !strings.HasSuffix(path, ".json") {
+		return itemKey{}, false
+	}
+
+	name := strings.TrimSuffix(parts[len(parts)-1], ".json")
+
+	switch {
+	case parts[2] == v1.ClusterScopedDir && len(parts) == 4:
+		return itemKey{resource: parts[1], name: name}, true
+	case parts[2] == v1.NamespaceScopedDir && len(parts) == 5:
+		return itemKey{resource: parts[1], namespace: parts[3], name: name}, true
+	default:
+		return itemKey{}, false
+	}
+}
+
+// provenanceAnnotations are the annotations that backups with provenance annotations
+// enabled add to each item. They differ between any two such backups.
+var provenanceAnnotations = []string{
+	v1.BackupNameAnnotation,
+	v1.BackupTimestampAnnotation,
+	v1.SourceClusterAnnotation,
+}
+
+// normalizeItem removes the parts of an item that change without any change to
+// what would be restored: status, server-populated metadata, the restore label
+// and provenance annotations.
+func normalizeItem(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	obj, err := restore.ResetMetadataAndStatus(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if itemLabels := obj.GetLabels(); itemLabels[v1.RestoreLabelKey] != "" {
+		delete(itemLabels, v1.RestoreLabelKey)
+		obj.SetLabels(itemLabels)
+	}
+
+	if annotations := obj.GetAnnotations(); len(annotations) > 0 {
+		for _, key := range provenanceAnnotations {
+			delete(annotations, key)
+		}
+		if len(annotations) > 0 {
+			obj.SetAnnotations(annotations)
+		} else {
+			unstructured.RemoveNestedField(obj.Object, "metadata", "annotations")
+		}
+	}
+
+	return obj, nil
+}
+
+// getClusterItems gets the current state of the given backup items from the cluster.
+// For each namespace-scoped resource and namespace in the backup, every item matching
+// the backup's label selector is listed, so that items created since the backup are
+// found. Cluster-scoped items are only looked up by name.
+func getClusterItems(helper arkdiscovery.Helper, dynamicFactory client.DynamicFactory, backupItems map[itemKey]*unstructured.Unstructured, selector labels.Selector) (map[itemKey]*unstructured.Unstructured, error) {
+	type resourceNamespace struct {
+		resource  string
+		namespace string
+	}
+
+	listed := make(map[resourceNamespace]bool)
+	items := make(map[itemKey]*unstructured.Unstructured)
+
+	for key := range backupItems {
+		gvr, apiResource, err := arkdiscovery.ResourceForWithRefresh(helper, schema.ParseGroupResource(key.resource).WithVersion(""))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error resolving resource %s", key.resource)
+		}
+
+		resourceClient, err := dynamicFactory.ClientForGroupVersionResource(gvr.GroupVersion(), apiResource, key.namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		if key.namespace == "" {
+			obj, err := resourceClient.Get(key.name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "error getting %s", key)
+			}
+			if items[key], err = normalizeItem(obj); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		rn := resourceNamespace{resource: key.resource, namespace: key.namespace}
+		if listed[rn] {
+			continue
+		}
+		listed[rn] = true
+
+		list, err := resourceClient.List(metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return nil, errors.Wrapf(err, "error listing %s in namespace %s", key.resource, key.namespace)
+		}
+
+		objs, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		for _, o := range objs {
+			obj, ok := o.(*unstructured.Unstructured)
+			if !ok {
+				return nil, errors.Errorf("unexpected type %T", o)
+			}
+			if restore.HasControllerOwner(obj.GetOwnerReferences()) {
+				continue
+			}
+
+			itemKey := itemKey{resource: key.resource, namespace: key.namespace, name: obj.GetName()}
+			if items[itemKey], err = normalizeItem(obj); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// itemChange is an item whose contents differ, along with the fields that changed.
+type itemChange struct {
+	key    itemKey
+	fields []string
+}
+
+// itemDiff is the result of comparing two sets of items.
+type itemDiff struct {
+	removed   []itemKey
+	added     []itemKey
+	changed   []itemChange
+	unchanged int
+}
+
+// diffItems compares the items in from against the items in to. Items are reported as
+// removed if they're only in from, added if they're only in to, and changed if any of
+// their top-level fields, labels or annotations differ.
+func diffItems(from, to map[itemKey]*unstructured.Unstructured) itemDiff {
+	var diff itemDiff
+
+	for key, fromItem := range from {
+		toItem, found := to[key]
+		if !found {
+			diff.removed = append(diff.removed, key)
+			continue
+		}
+
+		if fields := changedFields(fromItem, toItem); len(fields) > 0 {
+			diff.changed = append(diff.changed, itemChange{key: key, fields: fields})
+		} else {
+			diff.unchanged++
+		}
+	}
+
+	for key := range to {
+		if _, found := from[key]; !found {
+			diff.added = append(diff.added, key)
+		}
+	}
+
+	sortKeys(diff.removed)
+	sortKeys(diff.added)
+	sort.Slice(diff.changed, func(i, j int) bool {
+		return keyLess(diff.changed[i].key, diff.changed[j].key)
+	})
+
+	return diff
+}
+
+// changedFields returns the sorted names of the top-level fields, plus metadata.labels
+// and metadata.annotations, whose values differ between a and b.
+func changedFields(a, b *unstructured.Unstructured) []string {
+	var fields []string
+
+	for _, field := range unionKeys(a.Object, b.Object) {
+		if field == "metadata" {
+			continue
+		}
+		if !equality.Semantic.DeepEqual(a.Object[field], b.Object[field]) {
+			fields = append(fields, field)
+		}
+	}
+
+	if !equality.Semantic.DeepEqual(a.GetLabels(), b.GetLabels()) {
+		fields = append(fields, "metadata.labels")
+	}
+	if !equality.Semantic.DeepEqual(a.GetAnnotations(), b.GetAnnotations()) {
+		fields = append(fields, "metadata.annotations")
+	}
+
+	sort.Strings(fields)
+	return fields
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make(map[string]bool)
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+
+	var ret []string
+	for k := range keys {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func keyLess(a, b itemKey) bool {
+	if a.resource != b.resource {
+		return a.resource < b.resource
+	}
+	if a.namespace != b.namespace {
+		return a.namespace < b.namespace
+	}
+	return a.name < b.name
+}
+
+func sortKeys(keys []itemKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		return keyLess(keys[i], keys[j])
+	})
+}
+
+// printDiff writes a report of diff to w, using the given headings for removed and
+// added items.
+func printDiff(w io.Writer, diff itemDiff, removedHeading, addedHeading string) {
+	printKeys := func(heading string, keys []itemKey) {
+		fmt.Fprintf(w, "%s (%d):\n", heading, len(keys))
+		for _, key := range keys {
+			fmt.Fprintf(w, "  %s\n", key)
+		}
+		fmt.Fprintln(w)
+	}
+
+	printKeys(removedHeading, diff.removed)
+	printKeys(addedHeading, diff.added)
+
+	fmt.Fprintf(w, "Changed (%d):\n", len(diff.changed))
+	for _, change := range diff.changed {
+		fmt.Fprintf(w, "  %s: %s\n", change.key, strings.Join(change.fields, ", "))
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "Unchanged: %d\n", diff.unchanged)
+}
@@ -0,0 +1,279 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+func newTarball(t *testing.T, files map[string]string) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+
+	for name, contents := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Size:     int64(len(contents)),
+			Typeflag: tar.TypeReg,
+			Mode:     0755,
+		}))
+		_, err := tw.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+
+	return buf
+}
+
+func TestReadBackupItems(t *testing.T) {
+	tarball := newTarball(t, map[string]string{
+		"metadata/ark-backup.json":                                   `{}`,
+		"resources/persistentvolumes/cluster/pv-1.json":              `{"apiVersion":"v1","kind":"PersistentVolume","metadata":{"name":"pv-1","uid":"123"},"status":{"phase":"Bound"}}`,
+		"resources/configmaps/namespaces/ns-1/cm-1.json":             `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns-1","name":"cm-1","resourceVersion":"5","labels":{"a":"b","ark-restore":"r"}},"data":{"foo":"bar"}}`,
+		"resources/configmaps/namespaces/ns-2/cm-2.json":             `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns-2","name":"cm-2"}}`,
+		"resources/secrets/namespaces/ns-1/secret-1.json":            `{"apiVersion":"v1","kind":"Secret","metadata":{"namespace":"ns-1","name":"secret-1"}}`,
+		"resources/pods/namespaces/ns-1/pod-1.json":                  `{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"ns-1","name":"pod-1","ownerReferences":[{"apiVersion":"apps/v1","kind":"ReplicaSet","name":"rs","uid":"1","controller":true}]}}`,
+		"clusters/other/resources/configmaps/namespaces/ns-1/x.json": `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns-1","name":"x"}}`,
+	})
+
+	filter := &itemFilter{
+		resources:  collections.NewIncludesExcludes().Excludes("secrets"),
+		namespaces: collections.NewIncludesExcludes().Excludes("ns-2"),
+	}
+
+	items, err := readBackupItems(tarball, filter)
+	require.NoError(t, err)
+
+	expected := map[itemKey]*unstructured.Unstructured{
+		{resource: "persistentvolumes", name: "pv-1"}: {Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "PersistentVolume",
+			"metadata":   map[string]interface{}{"name": "pv-1"},
+		}},
+		{resource: "configmaps", namespace: "ns-1", name: "cm-1"}: {Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"namespace": "ns-1",
+				"name":      "cm-1",
+				"labels":    map[string]interface{}{"a": "b"},
+			},
+			"data": map[string]interface{}{"foo": "bar"},
+		}},
+	}
+
+	assert.Equal(t, expected, items)
+}
+
+func TestItemKeyForPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected itemKey
+		ok       bool
+	}{
+		{path: "resources/persistentvolumes/cluster/pv-1.json", expected: itemKey{resource: "persistentvolumes", name: "pv-1"}, ok: true},
+		{path: "resources/deployments.apps/namespaces/ns-1/d.json", expected: itemKey{resource: "deployments.apps", namespace: "ns-1", name: "d"}, ok: true},
+		{path: "metadata/ark-backup.json"},
+		{path: "resources/configmaps/namespaces/cm.json"},
+		{path: "resources/configmaps/other/ns-1/cm.json"},
+		{path: "clusters/other/resources/configmaps/namespaces/ns-1/cm.json"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			key, ok := itemKeyForPath(test.path)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, key)
+		})
+	}
+}
+
+func newItem(labels map[string]interface{}, data map[string]interface{}) *unstructured.Unstructured {
+	metadata := map[string]interface{}{"name": "item"}
+	if labels != nil {
+		metadata["labels"] = labels
+	}
+
+	obj := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   metadata,
+	}
+	if data != nil {
+		obj["data"] = data
+	}
+
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func TestDiffItems(t *testing.T) {
+	removed := itemKey{resource: "configmaps", namespace: "ns", name: "removed"}
+	added := itemKey{resource: "configmaps", namespace: "ns", name: "added"}
+	same := itemKey{resource: "configmaps", namespace: "ns", name: "same"}
+	dataChanged := itemKey{resource: "configmaps", namespace: "ns", name: "data-changed"}
+	labelsChanged := itemKey{resource: "configmaps", namespace: "ns", name: "labels-changed"}
+	clusterScoped := itemKey{resource: "persistentvolumes", name: "pv"}
+
+	from := map[string]interface{}{"foo": "bar"}
+	to := map[string]interface{}{"foo": "baz"}
+
+	diff := diffItems(
+		map[itemKey]*unstructured.Unstructured{
+			removed:       newItem(nil, from),
+			same:          newItem(nil, from),
+			dataChanged:   newItem(nil, from),
+			labelsChanged: newItem(map[string]interface{}{"a": "b"}, from),
+			clusterScoped: newItem(nil, nil),
+		},
+		map[itemKey]*unstructured.Unstructured{
+			added:         newItem(nil, from),
+			same:          newItem(nil, from),
+			dataChanged:   newItem(nil, to),
+			labelsChanged: newItem(map[string]interface{}{"a": "c"}, to),
+		},
+	)
+
+	assert.Equal(t, []itemKey{removed, clusterScoped}, diff.removed)
+	assert.Equal(t, []itemKey{added}, diff.added)
+	assert.Equal(t, []itemChange{
+		{key: dataChanged, fields: []string{"data"}},
+		{key: labelsChanged, fields: []string{"data", "metadata.labels"}},
+	}, diff.changed)
+	assert.Equal(t, 1, diff.unchanged)
+}
+
+func TestNormalizeItem(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"namespace":       "ns",
+			"name":            "cm",
+			"resourceVersion": "123",
+			"labels": map[string]interface{}{
+				"app":              "foo",
+				v1.RestoreLabelKey: "restore-1",
+			},
+			"annotations": map[string]interface{}{
+				"note":                       "kept",
+				v1.BackupNameAnnotation:      "backup-1",
+				v1.BackupTimestampAnnotation: "2018-01-01T00:00:00Z",
+				v1.SourceClusterAnnotation:   "east",
+			},
+		},
+		"data": map[string]interface{}{"foo": "bar"},
+	}}
+
+	res, err := normalizeItem(obj)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"app": "foo"}, res.GetLabels())
+	assert.Equal(t, map[string]string{"note": "kept"}, res.GetAnnotations())
+	assert.Empty(t, res.GetResourceVersion())
+
+	// an item with only provenance annotations is the same as one without any
+	obj = &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"namespace":   "ns",
+			"name":        "cm",
+			"annotations": map[string]interface{}{v1.BackupNameAnnotation: "backup-1"},
+		},
+	}}
+
+	res, err = normalizeItem(obj)
+	require.NoError(t, err)
+	_, found := res.Object["metadata"].(map[string]interface{})["annotations"]
+	assert.False(t, found)
+}
+
+func TestPrintDiff(t *testing.T) {
+	diff := itemDiff{
+		removed: []itemKey{{resource: "persistentvolumes", name: "pv"}},
+		changed: []itemChange{
+			{key: itemKey{resource: "configmaps", namespace: "ns", name: "cm"}, fields: []string{"data", "metadata.labels"}},
+		},
+		unchanged: 3,
+	}
+
+	buf := new(bytes.Buffer)
+	printDiff(buf, diff, "Missing from cluster", "Added in cluster")
+
+	expected := `Missing from cluster (1):
+  persistentvolumes pv
+
+Added in cluster (0):
+
+Changed (1):
+  configmaps ns/cm: data, metadata.labels
+
+Unchanged: 3
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestDiffBackups(t *testing.T) {
+	filter := &itemFilter{
+		resources:  collections.NewIncludesExcludes().Includes("*"),
+		namespaces: collections.NewIncludesExcludes().Includes("*"),
+	}
+
+	a, err := readBackupItems(newTarball(t, map[string]string{
+		"resources/configmaps/namespaces/ns/cm-1.json": `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns","name":"cm-1","uid":"1"},"data":{"foo":"bar"}}`,
+		"resources/configmaps/namespaces/ns/cm-2.json": `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns","name":"cm-2"}}`,
+	}), filter)
+	require.NoError(t, err)
+
+	b, err := readBackupItems(newTarball(t, map[string]string{
+		"resources/configmaps/namespaces/ns/cm-1.json":  `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns","name":"cm-1","uid":"2"},"data":{"foo":"baz"}}`,
+		"resources/secrets/namespaces/ns/secret-1.json": `{"apiVersion":"v1","kind":"Secret","metadata":{"namespace":"ns","name":"secret-1"}}`,
+		"resources/persistentvolumes/cluster/pv-1.json": `{"apiVersion":"v1","kind":"PersistentVolume","metadata":{"name":"pv-1"}}`,
+	}), filter)
+	require.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	printDiff(buf, diffItems(a, b), "Only in a", "Only in b")
+
+	expected := `Only in a (1):
+  configmaps ns/cm-2
+
+Only in b (2):
+  persistentvolumes pv-1
+  secrets ns/secret-1
+
+Changed (1):
+  configmaps ns/cm-1: data
+
+Unchanged: 0
+`
+	assert.Equal(t, expected, buf.String())
+}
@@ -26,9 +26,9 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
-	"github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/cli/completion"
 	"github.com/heptio/ark/pkg/cmd/util/downloadrequest"
 )
 
@@ -47,6 +47,8 @@ func NewDownloadCommand(f client.Factory) *cobra.Command {
 
 	o.BindFlags(c.Flags())
 
+	completion.CompleteNames(c, "backups")
+
 	return c
 }
 
@@ -103,7 +105,7 @@ func (o *DownloadOptions) Run(c *cobra.Command, f client.Factory) error {
 	}
 	defer backupDest.Close()
 
-	err = downloadrequest.Stream(arkClient.ArkV1(), f.Namespace(), o.Name, v1.DownloadTargetKindBackupContents, backupDest, o.Timeout)
+	err = downloadrequest.StreamBackupContents(arkClient.ArkV1(), f.Namespace(), o.Name, backupDest, o.Timeout)
 	if err != nil {
 		os.Remove(o.Output)
 		cmd.CheckError(err)
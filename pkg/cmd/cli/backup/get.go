@@ -17,6 +17,10 @@ limitations under the License.
 package backup
 
 import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,11 +28,29 @@ import (
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/cli/completion"
+	"github.com/heptio/ark/pkg/cmd/util/flag"
 	"github.com/heptio/ark/pkg/cmd/util/output"
 )
 
+var backupPhases = []string{
+	string(api.BackupPhaseNew),
+	string(api.BackupPhaseFailedValidation),
+	string(api.BackupPhaseInProgress),
+	string(api.BackupPhaseCompleted),
+	string(api.BackupPhasePartiallyFailed),
+	string(api.BackupPhaseFailed),
+	string(api.BackupPhaseDeleting),
+}
+
 func NewGetCommand(f client.Factory, use string) *cobra.Command {
-	var listOptions metav1.ListOptions
+	var (
+		listOptions metav1.ListOptions
+		schedule    string
+		phases      flag.StringArray
+		olderThan   time.Duration
+		newerThan   time.Duration
+	)
 
 	c := &cobra.Command{
 		Use:   use,
@@ -36,16 +58,28 @@ func NewGetCommand(f client.Factory, use string) *cobra.Command {
 		Run: func(c *cobra.Command, args []string) {
 			err := output.ValidateFlags(c)
 			cmd.CheckError(err)
+			cmd.CheckError(validatePhases(phases))
 
 			arkClient, err := f.Client()
 			cmd.CheckError(err)
 
+			// the schedule is matched by the API server, along with any other label selector
+			if schedule != "" {
+				if listOptions.LabelSelector != "" {
+					listOptions.LabelSelector += ","
+				}
+				listOptions.LabelSelector += api.ScheduleLabel + "=" + schedule
+			}
+
 			var backups *api.BackupList
 			if len(args) > 0 {
 				backups = new(api.BackupList)
 				for _, name := range args {
 					backup, err := arkClient.Ark().Backups(f.Namespace()).Get(name, metav1.GetOptions{})
 					cmd.CheckError(err)
+					if schedule != "" && backup.Labels[api.ScheduleLabel] != schedule {
+						continue
+					}
 					backups.Items = append(backups.Items, *backup)
 				}
 			} else {
@@ -53,14 +87,79 @@ func NewGetCommand(f client.Factory, use string) *cobra.Command {
 				cmd.CheckError(err)
 			}
 
+			backups.Items = filterBackups(backups.Items, phases, olderThan, newerThan, time.Now())
+
 			_, err = output.PrintWithFormat(c, backups)
 			cmd.CheckError(err)
 		},
 	}
 
 	c.Flags().StringVarP(&listOptions.LabelSelector, "selector", "l", listOptions.LabelSelector, "only show items matching this label selector")
+	c.Flags().StringVar(&schedule, "schedule", schedule, "only show backups created by this schedule")
+	c.Flags().Var(&phases, "phase", "only show backups in these phases. Valid values are "+strings.Join(backupPhases, ", "))
+	c.Flags().DurationVar(&olderThan, "older-than", olderThan, "only show backups created more than this long ago, such as 720h")
+	c.Flags().DurationVar(&newerThan, "newer-than", newerThan, "only show backups created less than this long ago, such as 24h")
 
 	output.BindFlags(c.Flags())
 
+	completion.CompleteNames(c, "backups")
+	completion.CompleteFlagNames(c, "schedule", "schedules")
+
 	return c
 }
+
+// validatePhases returns an error if any of phases isn't a backup phase.
+func validatePhases(phases []string) error {
+	for _, phase := range phases {
+		valid := false
+		for _, backupPhase := range backupPhases {
+			if phase == backupPhase {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return errors.Errorf("invalid --phase %q: valid values are %s", phase, strings.Join(backupPhases, ", "))
+		}
+	}
+	return nil
+}
+
+// filterBackups returns the backups that are in one of phases, if any are given, and that were
+// created more than olderThan and less than newerThan before now, if they're non-zero. Backups
+// that the server hasn't processed yet, which have no phase, are New.
+func filterBackups(backups []api.Backup, phases []string, olderThan, newerThan time.Duration, now time.Time) []api.Backup {
+	var res []api.Backup
+
+	for _, backup := range backups {
+		if len(phases) > 0 {
+			backupPhase := backup.Status.Phase
+			if backupPhase == "" {
+				backupPhase = api.BackupPhaseNew
+			}
+
+			matched := false
+			for _, phase := range phases {
+				if string(backupPhase) == phase {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		age := now.Sub(backup.CreationTimestamp.Time)
+		if olderThan > 0 && age <= olderThan {
+			continue
+		}
+		if newerThan > 0 && age >= newerThan {
+			continue
+		}
+
+		res = append(res, backup)
+	}
+
+	return res
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+func TestFilterBackups(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	backup := func(name string, phase api.BackupPhase, age time.Duration) api.Backup {
+		return api.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Status:     api.BackupStatus{Phase: phase},
+		}
+	}
+	backups := []api.Backup{
+		backup("new", api.BackupPhaseNew, time.Minute),
+		backup("unprocessed", "", time.Minute),
+		backup("completed-today", api.BackupPhaseCompleted, 2*time.Hour),
+		backup("failed-yesterday", api.BackupPhaseFailed, 30*time.Hour),
+		backup("completed-last-month", api.BackupPhaseCompleted, 30*24*time.Hour),
+	}
+
+	tests := []struct {
+		name      string
+		phases    []string
+		olderThan time.Duration
+		newerThan time.Duration
+		expected  []string
+	}{
+		{
+			name:     "no filters",
+			expected: []string{"new", "unprocessed", "completed-today", "failed-yesterday", "completed-last-month"},
+		},
+		{
+			name:     "phases",
+			phases:   []string{"Completed", "Failed"},
+			expected: []string{"completed-today", "failed-yesterday", "completed-last-month"},
+		},
+		{
+			name:     "backups without a phase are new",
+			phases:   []string{"New"},
+			expected: []string{"new", "unprocessed"},
+		},
+		{
+			name:      "older than",
+			olderThan: 24 * time.Hour,
+			expected:  []string{"failed-yesterday", "completed-last-month"},
+		},
+		{
+			name:      "newer than",
+			newerThan: 24 * time.Hour,
+			expected:  []string{"new", "unprocessed", "completed-today"},
+		},
+		{
+			name:      "all filters",
+			phases:    []string{"Completed"},
+			olderThan: time.Hour,
+			newerThan: 7 * 24 * time.Hour,
+			expected:  []string{"completed-today"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var names []string
+			for _, backup := range filterBackups(backups, test.phases, test.olderThan, test.newerThan, now) {
+				names = append(names, backup.Name)
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestValidatePhases(t *testing.T) {
+	assert.NoError(t, validatePhases(nil))
+	assert.NoError(t, validatePhases([]string{"Completed", "Failed", "Deleting"}))
+	assert.EqualError(t, validatePhases([]string{"Completed", "Done"}), `invalid --phase "Done": valid values are New, FailedValidation, InProgress, Completed, PartiallyFailed, Failed, Deleting`)
+}
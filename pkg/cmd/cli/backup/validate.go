@@ -0,0 +1,71 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	arkdiscovery "github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+// validateResources returns an error for each of resources that can't be resolved by the
+// cluster's discovery API. Wildcards ("*" and "*.group") aren't checked.
+func validateResources(discoveryHelper arkdiscovery.Helper, resources []string) []error {
+	var errs []error
+
+	for _, resource := range resources {
+		if strings.HasPrefix(resource, "*") {
+			continue
+		}
+
+		if _, _, err := discoveryHelper.ResourceFor(schema.ParseGroupResource(resource).WithVersion("")); err != nil {
+			errs = append(errs, errors.Errorf("resource %q isn't served by the cluster", resource))
+		}
+	}
+
+	return errs
+}
+
+// validateNamespaces returns an error for each of namespaces that doesn't exist in the
+// cluster. Patterns, such as "*" and "kube-*", aren't checked.
+func validateNamespaces(namespaceClient corev1.NamespaceInterface, namespaces []string) []error {
+	var errs []error
+
+	for _, namespace := range namespaces {
+		if collections.IsGlob(namespace) {
+			continue
+		}
+
+		_, err := namespaceClient.Get(namespace, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			errs = append(errs, errors.Errorf("namespace %q doesn't exist", namespace))
+		case err != nil:
+			errs = append(errs, errors.Wrapf(err, "error getting namespace %q", namespace))
+		}
+	}
+
+	return errs
+}
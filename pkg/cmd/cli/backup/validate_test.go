@@ -0,0 +1,76 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func errorStrings(errs []error) []string {
+	var res []string
+	for _, err := range errs {
+		res = append(res, err.Error())
+	}
+	return res
+}
+
+func TestValidateResources(t *testing.T) {
+	discoveryHelper := arktest.NewFakeDiscoveryHelper(false, map[schema.GroupVersionResource]schema.GroupVersionResource{
+		{Resource: "pods"}:                       {Version: "v1", Resource: "pods"},
+		{Resource: "deployments"}:                {Group: "apps", Version: "v1", Resource: "deployments"},
+		{Group: "apps", Resource: "deployments"}: {Group: "apps", Version: "v1", Resource: "deployments"},
+	})
+
+	errs := validateResources(discoveryHelper, []string{"*", "*.apps", "pods", "deployments", "deployments.apps", "widgets", "widgets.example.com"})
+
+	assert.Equal(t, []string{
+		`resource "widgets" isn't served by the cluster`,
+		`resource "widgets.example.com" isn't served by the cluster`,
+	}, errorStrings(errs))
+}
+
+type fakeNamespaceClient struct {
+	namespaces map[string]bool
+
+	corev1.NamespaceInterface
+}
+
+func (c *fakeNamespaceClient) Get(name string, opts metav1.GetOptions) (*v1.Namespace, error) {
+	if !c.namespaces[name] {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, name)
+	}
+	return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil
+}
+
+func TestValidateNamespaces(t *testing.T) {
+	client := &fakeNamespaceClient{namespaces: map[string]bool{"ns-1": true}}
+
+	assert.Empty(t, validateNamespaces(client, []string{"*"}))
+	assert.Empty(t, validateNamespaces(client, []string{"kube-*", "*-system"}))
+	assert.Empty(t, validateNamespaces(client, []string{"ns-1"}))
+	assert.Equal(t, []string{`namespace "ns-2" doesn't exist`}, errorStrings(validateNamespaces(client, []string{"ns-1", "ns-2"})))
+}
@@ -17,20 +17,30 @@ limitations under the License.
 package completion
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"strings"
 
 	kubectlcmd "github.com/heptio/ark/third_party/kubernetes/pkg/kubectl/cmd"
 	"github.com/spf13/cobra"
 )
 
+// NameAnnotation is the annotation on a command whose arguments are names of
+// Ark resources. Its value is the resource, e.g. "backups".
+const NameAnnotation = "ark.heptio.com/complete-names"
+
 func NewCommand() *cobra.Command {
 	c := &cobra.Command{
 		Use:   "completion SHELL",
 		Short: "Output shell completion code for the specified shell (bash or zsh)",
 		Long: `Generate shell completion code.
 
-Auto completion supports both bash and zsh. Output is to STDOUT.
+Auto completion supports both bash and zsh. Output is to STDOUT. Besides commands
+and flags, the names of backups, restores and schedules are completed by querying
+the Ark API in the current kubeconfig context and namespace, or those given with
+--kubeconfig, --kubecontext (or --context) and --namespace on the command line being
+completed.
 
 Load the ark completion code for bash into the current shell -
 source <(ark completion bash)
@@ -41,12 +51,15 @@ source <(ark completion zsh)
 		Args:      cobra.ExactArgs(1),
 		ValidArgs: []string{"bash", "zsh"},
 		Run: func(cmd *cobra.Command, args []string) {
+			root := cmd.Root()
+			root.BashCompletionFunction = bashCompletionFunction(root)
+
 			shell := args[0]
 			switch shell {
 			case "bash":
-				cmd.Root().GenBashCompletion(os.Stdout)
+				root.GenBashCompletion(os.Stdout)
 			case "zsh":
-				kubectlcmd.GenZshCompletion(os.Stdout, cmd.Root())
+				kubectlcmd.GenZshCompletion(os.Stdout, root)
 			default:
 				fmt.Printf("Invalid shell specified, specify bash or zsh\n")
 				os.Exit(1)
@@ -56,3 +69,104 @@ source <(ark completion zsh)
 
 	return c
 }
+
+// CompleteNames makes shell completion of c's arguments complete the names
+// of the given Ark resource: "backups", "restores" or "schedules".
+func CompleteNames(c *cobra.Command, resource string) {
+	if c.Annotations == nil {
+		c.Annotations = make(map[string]string)
+	}
+	c.Annotations[NameAnnotation] = resource
+}
+
+// CompleteFlagNames makes shell completion of c's flag complete the names
+// of the given Ark resource. The flag must already be defined.
+func CompleteFlagNames(c *cobra.Command, flag, resource string) {
+	if err := c.MarkFlagCustom(flag, "__ark_get_"+resource); err != nil {
+		panic(err)
+	}
+}
+
+// bashCompletionHelpers lists the names of Ark resources for completion, passing
+// along the flags that select the cluster and namespace from the command line
+// being completed. %[1]s is the name of the root command.
+const bashCompletionHelpers = `
+__ark_override_flag_list=(--kubeconfig --kubecontext --context --namespace -n)
+
+__ark_override_flags()
+{
+    local ${__ark_override_flag_list[*]##*-} two_word_of of var
+    for w in "${words[@]}"; do
+        if [ -n "${two_word_of}" ]; then
+            eval "${two_word_of##*-}=\"${two_word_of}=\${w}\""
+            two_word_of=
+            continue
+        fi
+        for of in "${__ark_override_flag_list[@]}"; do
+            case "${w}" in
+                ${of}=*)
+                    eval "${of##*-}=\"${w}\""
+                    ;;
+                ${of})
+                    two_word_of="${of}"
+                    ;;
+            esac
+        done
+    done
+    for var in "${__ark_override_flag_list[@]##*-}"; do
+        if eval "test -n \"\$${var}\""; then
+            eval "echo -n \${${var}}' '"
+        fi
+    done
+}
+
+__ark_get_resource()
+{
+    local ark_out
+    if ark_out=$(%[1]s get "$1" $(__ark_override_flags) 2>/dev/null | awk 'NR > 1 { print $1 }'); then
+        COMPREPLY=( $( compgen -W "${ark_out[*]}" -- "$cur" ) )
+    fi
+}
+
+__ark_get_backups()
+{
+    __ark_get_resource backups
+}
+
+__ark_get_restores()
+{
+    __ark_get_resource restores
+}
+
+__ark_get_schedules()
+{
+    __ark_get_resource schedules
+}
+`
+
+// bashCompletionFunction returns the bash functions used to complete the names
+// of Ark resources, including a __custom_func that completes the arguments of
+// each of root's commands that has the NameAnnotation.
+func bashCompletionFunction(root *cobra.Command) string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, bashCompletionHelpers, root.Name())
+
+	buf.WriteString("\n__custom_func() {\n    case ${last_command} in\n")
+	writeNameCompletions(buf, root)
+	buf.WriteString("        *)\n            ;;\n    esac\n}\n")
+
+	return buf.String()
+}
+
+func writeNameCompletions(buf *bytes.Buffer, c *cobra.Command) {
+	if resource := c.Annotations[NameAnnotation]; resource != "" {
+		// this matches the ${last_command} that cobra's bash completion sets
+		// for the command
+		name := strings.Replace(c.CommandPath(), " ", "_", -1)
+		fmt.Fprintf(buf, "        %s)\n            __ark_get_%s\n            return\n            ;;\n", name, resource)
+	}
+
+	for _, child := range c.Commands() {
+		writeNameCompletions(buf, child)
+	}
+}
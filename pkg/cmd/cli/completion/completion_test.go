@@ -0,0 +1,56 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package completion
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBashCompletionFunction(t *testing.T) {
+	root := &cobra.Command{Use: "ark"}
+	backup := &cobra.Command{Use: "backup"}
+	describe := &cobra.Command{Use: "describe", Run: func(*cobra.Command, []string) {}}
+	create := &cobra.Command{Use: "create", Run: func(*cobra.Command, []string) {}}
+	restore := &cobra.Command{Use: "restore", Run: func(*cobra.Command, []string) {}}
+
+	backup.AddCommand(describe, create)
+	root.AddCommand(backup, restore)
+
+	CompleteNames(describe, "backups")
+	restore.Flags().String("from-backup", "", "")
+	CompleteFlagNames(restore, "from-backup", "backups")
+
+	function := bashCompletionFunction(root)
+	assert.Contains(t, function, "ark_backup_describe)\n            __ark_get_backups\n")
+	assert.NotContains(t, function, "ark_backup_create)")
+	assert.Contains(t, function, `ark_out=$(ark get "$1" $(__ark_override_flags)`)
+
+	root.BashCompletionFunction = function
+	buf := new(bytes.Buffer)
+	require.NoError(t, root.GenBashCompletion(buf))
+	assert.Contains(t, buf.String(), `flags_completion+=("__ark_get_backups")`)
+}
+
+func TestCompleteFlagNamesPanicsForUnknownFlag(t *testing.T) {
+	c := &cobra.Command{Use: "create"}
+	assert.Panics(t, func() { CompleteFlagNames(c, "from-backup", "backups") })
+}
@@ -0,0 +1,347 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/util/flag"
+	arkclient "github.com/heptio/ark/pkg/generated/clientset/versioned"
+)
+
+const defaultPollInterval = 2 * time.Second
+
+func NewCommand(f client.Factory) *cobra.Command {
+	o := NewOptions()
+
+	c := &cobra.Command{
+		Use:   "migrate [NAME] --source-context SOURCE --dest-context DEST",
+		Short: "Migrate resources from one cluster to another",
+		Long: `Migrate resources from one cluster to another by backing them up in the source
+cluster and restoring the backup into the destination cluster.
+
+Both clusters must be running Ark, configured with the same backup storage
+provider and bucket. The backup becomes visible in the destination cluster the
+next time its Ark server syncs backups from object storage, which happens every
+backupSyncPeriod.`,
+		Example: `  # migrate everything from the "staging" context to the "production" context
+  ark migrate --source-context staging --dest-context production
+
+  # migrate the "app" namespace into the "app-v2" namespace
+  ark migrate app-migration --source-context old --dest-context new \
+      --include-namespaces app --namespace-mappings app:app-v2`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			cmd.CheckError(o.Complete(args, f))
+			cmd.CheckError(o.Validate(c, args, f))
+			cmd.CheckError(o.Run(c, f))
+		},
+	}
+
+	o.BindFlags(c.Flags())
+
+	return c
+}
+
+type Options struct {
+	Name                    string
+	SourceContext           string
+	DestContext             string
+	IncludeNamespaces       flag.StringArray
+	ExcludeNamespaces       flag.StringArray
+	IncludeResources        flag.StringArray
+	ExcludeResources        flag.StringArray
+	Selector                flag.LabelSelector
+	SnapshotVolumes         flag.OptionalBool
+	IncludeClusterResources flag.OptionalBool
+	NamespaceMappings       flag.Map
+	TTL                     time.Duration
+	Timeout                 time.Duration
+
+	sourceClient arkclient.Interface
+	destClient   arkclient.Interface
+	pollInterval time.Duration
+}
+
+func NewOptions() *Options {
+	return &Options{
+		IncludeNamespaces:       flag.NewStringArray("*"),
+		SnapshotVolumes:         flag.NewOptionalBool(nil),
+		IncludeClusterResources: flag.NewOptionalBool(nil),
+		NamespaceMappings:       flag.NewMap().WithEntryDelimiter(",").WithKeyValueDelimiter(":"),
+		TTL:                     30 * 24 * time.Hour,
+		Timeout:                 2 * time.Hour,
+		pollInterval:            defaultPollInterval,
+	}
+}
+
+func (o *Options) BindFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.SourceContext, "source-context", "", "the kubeconfig context of the cluster to migrate from")
+	flags.StringVar(&o.DestContext, "dest-context", "", "the kubeconfig context of the cluster to migrate to")
+	flags.Var(&o.IncludeNamespaces, "include-namespaces", "namespaces to include in the migration (use '*' for all namespaces)")
+	flags.Var(&o.ExcludeNamespaces, "exclude-namespaces", "namespaces to exclude from the migration")
+	flags.Var(&o.IncludeResources, "include-resources", "resources to include in the migration, formatted as resource.group, such as storageclasses.storage.k8s.io (use '*' for all resources)")
+	flags.Var(&o.ExcludeResources, "exclude-resources", "resources to exclude from the migration, formatted as resource.group, such as storageclasses.storage.k8s.io")
+	flags.VarP(&o.Selector, "selector", "l", "only migrate resources matching this label selector")
+	flags.Var(&o.NamespaceMappings, "namespace-mappings", "namespace mappings from name in the source cluster to desired name in the destination cluster in the form src1:dst1,src2:dst2,...")
+	flags.DurationVar(&o.TTL, "ttl", o.TTL, "how long before the migration's backup can be garbage collected")
+	flags.DurationVar(&o.Timeout, "timeout", o.Timeout, "maximum time to wait for each step of the migration to finish")
+
+	f := flags.VarPF(&o.SnapshotVolumes, "snapshot-volumes", "", "take snapshots of PersistentVolumes in the source cluster and restore them in the destination cluster")
+	// this allows the user to just specify "--snapshot-volumes" as shorthand for "--snapshot-volumes=true"
+	// like a normal bool flag
+	f.NoOptDefVal = "true"
+
+	f = flags.VarPF(&o.IncludeClusterResources, "include-cluster-resources", "", "include cluster-scoped resources in the migration")
+	f.NoOptDefVal = "true"
+}
+
+func (o *Options) Complete(args []string, f client.Factory) error {
+	if len(args) == 1 {
+		o.Name = args[0]
+	} else {
+		o.Name = fmt.Sprintf("migrate-%s", time.Now().Format("20060102150405"))
+	}
+
+	if o.SourceContext == "" || o.DestContext == "" {
+		// Validate reports this
+		return nil
+	}
+
+	var err error
+	if o.sourceClient, err = f.ClientForContext(o.SourceContext); err != nil {
+		return err
+	}
+	if o.destClient, err = f.ClientForContext(o.DestContext); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *Options) Validate(c *cobra.Command, args []string, f client.Factory) error {
+	if o.SourceContext == "" {
+		return errors.New("--source-context is required")
+	}
+	if o.DestContext == "" {
+		return errors.New("--dest-context is required")
+	}
+	if o.SourceContext == o.DestContext {
+		return errors.New("--source-context and --dest-context must be different")
+	}
+
+	if o.sourceClient == nil || o.destClient == nil {
+		// This should never happen
+		return errors.New("Ark clients are not set; unable to proceed")
+	}
+
+	return checkSharedBackupStorage(o.sourceClient, o.destClient, f.Namespace())
+}
+
+// checkSharedBackupStorage returns an error unless the source and destination Ark servers
+// store backups in the same bucket, which is how the destination sees the source's backups.
+func checkSharedBackupStorage(sourceClient, destClient arkclient.Interface, namespace string) error {
+	sourceConfig, err := sourceClient.ArkV1().Configs(namespace).Get("default", metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "error getting Ark config from source cluster")
+	}
+
+	destConfig, err := destClient.ArkV1().Configs(namespace).Get("default", metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "error getting Ark config from destination cluster")
+	}
+
+	source, dest := sourceConfig.BackupStorageProvider, destConfig.BackupStorageProvider
+	if source.Name != dest.Name || source.Bucket != dest.Bucket {
+		return errors.Errorf(
+			"source and destination clusters must use the same backup storage: source uses %s bucket %q, destination uses %s bucket %q",
+			source.Name, source.Bucket, dest.Name, dest.Bucket,
+		)
+	}
+
+	return nil
+}
+
+func (o *Options) Run(c *cobra.Command, f client.Factory) error {
+	if o.sourceClient == nil || o.destClient == nil {
+		// This should never happen
+		return errors.New("Ark clients are not set; unable to proceed")
+	}
+
+	namespace := f.Namespace()
+
+	backup := &api.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      o.Name,
+		},
+		Spec: api.BackupSpec{
+			IncludedNamespaces:      o.IncludeNamespaces,
+			ExcludedNamespaces:      o.ExcludeNamespaces,
+			IncludedResources:       o.IncludeResources,
+			ExcludedResources:       o.ExcludeResources,
+			LabelSelector:           o.Selector.LabelSelector,
+			SnapshotVolumes:         o.SnapshotVolumes.Value,
+			TTL:                     metav1.Duration{Duration: o.TTL},
+			IncludeClusterResources: o.IncludeClusterResources.Value,
+		},
+	}
+
+	if _, err := o.sourceClient.ArkV1().Backups(namespace).Create(backup); err != nil {
+		return errors.Wrap(err, "error creating backup in source cluster")
+	}
+	fmt.Printf("[%s] Backup %q submitted successfully.\n", o.SourceContext, o.Name)
+
+	if err := o.waitForBackup(namespace); err != nil {
+		return err
+	}
+
+	fmt.Printf("[%s] Waiting for backup %q to be synced from object storage.\n", o.DestContext, o.Name)
+	if err := o.waitForSyncedBackup(namespace); err != nil {
+		return err
+	}
+
+	restore := &api.Restore{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      o.Name,
+		},
+		Spec: api.RestoreSpec{
+			BackupName:       o.Name,
+			NamespaceMapping: o.NamespaceMappings.Data(),
+			RestorePVs:       o.SnapshotVolumes.Value,
+		},
+	}
+
+	if _, err := o.destClient.ArkV1().Restores(namespace).Create(restore); err != nil {
+		return errors.Wrap(err, "error creating restore in destination cluster")
+	}
+	fmt.Printf("[%s] Restore %q submitted successfully.\n", o.DestContext, o.Name)
+
+	if err := o.waitForRestore(namespace); err != nil {
+		return err
+	}
+
+	fmt.Printf("Migration %q completed. Run `ark --kubecontext %s restore describe %s` for more details.\n", o.Name, o.DestContext, o.Name)
+	return nil
+}
+
+// waitForBackup waits for the migration's backup to complete in the source cluster,
+// printing each phase it goes through.
+func (o *Options) waitForBackup(namespace string) error {
+	var lastPhase api.BackupPhase
+
+	err := wait.PollImmediate(o.pollInterval, o.Timeout, func() (bool, error) {
+		backup, err := o.sourceClient.ArkV1().Backups(namespace).Get(o.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, errors.Wrap(err, "error getting backup from source cluster")
+		}
+
+		if phase := backup.Status.Phase; phase != "" && phase != lastPhase {
+			fmt.Printf("[%s] Backup %q is %s.\n", o.SourceContext, o.Name, phase)
+			lastPhase = phase
+		}
+
+		switch backup.Status.Phase {
+		case api.BackupPhaseCompleted:
+			return true, nil
+		case api.BackupPhaseFailedValidation:
+			return false, errors.Errorf("backup failed validation: %s", strings.Join(backup.Status.ValidationErrors, "; "))
+		case api.BackupPhaseFailed:
+			return false, errors.Errorf("backup failed. Run `ark --kubecontext %s backup logs %s` for more details", o.SourceContext, o.Name)
+		case api.BackupPhasePartiallyFailed:
+			return false, errors.Errorf("backup partially failed. Run `ark --kubecontext %s backup logs %s` for more details", o.SourceContext, o.Name)
+		}
+
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.Errorf("timed out waiting for backup %q to complete", o.Name)
+	}
+
+	return err
+}
+
+// waitForSyncedBackup waits for the migration's backup to appear in the destination cluster.
+func (o *Options) waitForSyncedBackup(namespace string) error {
+	err := wait.PollImmediate(o.pollInterval, o.Timeout, func() (bool, error) {
+		_, err := o.destClient.ArkV1().Backups(namespace).Get(o.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, errors.Wrap(err, "error getting backup from destination cluster")
+		}
+
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.Errorf("timed out waiting for backup %q to be synced to the destination cluster", o.Name)
+	}
+
+	return err
+}
+
+// waitForRestore waits for the migration's restore to complete in the destination cluster,
+// printing each phase it goes through.
+func (o *Options) waitForRestore(namespace string) error {
+	var lastPhase api.RestorePhase
+
+	err := wait.PollImmediate(o.pollInterval, o.Timeout, func() (bool, error) {
+		restore, err := o.destClient.ArkV1().Restores(namespace).Get(o.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, errors.Wrap(err, "error getting restore from destination cluster")
+		}
+
+		if phase := restore.Status.Phase; phase != "" && phase != lastPhase {
+			fmt.Printf("[%s] Restore %q is %s.\n", o.DestContext, o.Name, phase)
+			lastPhase = phase
+		}
+
+		switch restore.Status.Phase {
+		case api.RestorePhaseCompleted, api.RestorePhasePartiallyFailed:
+			if restore.Status.Warnings > 0 || restore.Status.Errors > 0 {
+				fmt.Printf("[%s] Restore %q finished with %d warning(s) and %d error(s).\n", o.DestContext, o.Name, restore.Status.Warnings, restore.Status.Errors)
+			}
+			return true, nil
+		case api.RestorePhaseFailed:
+			return false, errors.Errorf("restore failed with %d error(s)", restore.Status.Errors)
+		case api.RestorePhaseFailedValidation:
+			return false, errors.Errorf("restore failed validation: %s", strings.Join(restore.Status.ValidationErrors, "; "))
+		}
+
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.Errorf("timed out waiting for restore %q to complete", o.Name)
+	}
+
+	return err
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func newConfig(provider, bucket string) *v1.Config {
+	config := &v1.Config{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "heptio-ark", Name: "default"},
+	}
+	config.BackupStorageProvider.Name = provider
+	config.BackupStorageProvider.Bucket = bucket
+
+	return config
+}
+
+func TestCheckSharedBackupStorage(t *testing.T) {
+	tests := []struct {
+		name          string
+		sourceConfig  *v1.Config
+		destConfig    *v1.Config
+		expectedError string
+	}{
+		{
+			name:         "same provider and bucket is valid",
+			sourceConfig: newConfig("aws", "backups"),
+			destConfig:   newConfig("aws", "backups"),
+		},
+		{
+			name:          "different buckets are invalid",
+			sourceConfig:  newConfig("aws", "backups"),
+			destConfig:    newConfig("aws", "other"),
+			expectedError: `source and destination clusters must use the same backup storage: source uses aws bucket "backups", destination uses aws bucket "other"`,
+		},
+		{
+			name:          "different providers are invalid",
+			sourceConfig:  newConfig("aws", "backups"),
+			destConfig:    newConfig("gcp", "backups"),
+			expectedError: `source and destination clusters must use the same backup storage: source uses aws bucket "backups", destination uses gcp bucket "backups"`,
+		},
+		{
+			name:          "missing destination config is invalid",
+			sourceConfig:  newConfig("aws", "backups"),
+			expectedError: `error getting Ark config from destination cluster: configs.ark.heptio.com "default" not found`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sourceClient := fake.NewSimpleClientset(test.sourceConfig)
+			destClient := fake.NewSimpleClientset()
+			if test.destConfig != nil {
+				destClient = fake.NewSimpleClientset(test.destConfig)
+			}
+
+			err := checkSharedBackupStorage(sourceClient, destClient, "heptio-ark")
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestWaitForBackup(t *testing.T) {
+	tests := []struct {
+		name          string
+		phase         v1.BackupPhase
+		expectedError string
+	}{
+		{
+			name:  "completed backup succeeds",
+			phase: v1.BackupPhaseCompleted,
+		},
+		{
+			name:          "failed backup returns an error",
+			phase:         v1.BackupPhaseFailed,
+			expectedError: "backup failed. Run `ark --kubecontext source backup logs migration` for more details",
+		},
+		{
+			name:          "backup that failed validation returns an error",
+			phase:         v1.BackupPhaseFailedValidation,
+			expectedError: "backup failed validation: ",
+		},
+		{
+			name:          "backup that doesn't finish times out",
+			phase:         v1.BackupPhaseInProgress,
+			expectedError: `timed out waiting for backup "migration" to complete`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := NewOptions()
+			o.Name = "migration"
+			o.SourceContext = "source"
+			o.Timeout = 10 * time.Millisecond
+			o.pollInterval = time.Millisecond
+			o.sourceClient = fake.NewSimpleClientset(
+				arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("migration").WithPhase(test.phase).Backup,
+			)
+
+			err := o.waitForBackup("heptio-ark")
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestWaitForSyncedBackup(t *testing.T) {
+	o := NewOptions()
+	o.Name = "migration"
+	o.Timeout = 10 * time.Millisecond
+	o.pollInterval = time.Millisecond
+
+	o.destClient = fake.NewSimpleClientset()
+	assert.EqualError(t, o.waitForSyncedBackup("heptio-ark"), `timed out waiting for backup "migration" to be synced to the destination cluster`)
+
+	o.destClient = fake.NewSimpleClientset(arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("migration").Backup)
+	assert.NoError(t, o.waitForSyncedBackup("heptio-ark"))
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operation
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/util/output"
+	"github.com/heptio/ark/pkg/cmd/util/serverstatus"
+)
+
+func NewGetCommand(f client.Factory, use string) *cobra.Command {
+	timeout := time.Minute
+
+	c := &cobra.Command{
+		Use:   use,
+		Short: "Get the backups, restores, and backup deletions the server is running",
+		Args:  cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			err := output.ValidateFlags(c)
+			cmd.CheckError(err)
+
+			arkClient, err := f.Client()
+			cmd.CheckError(err)
+
+			status, err := serverstatus.Get(arkClient.ArkV1(), f.Namespace(), timeout)
+			cmd.CheckError(err)
+
+			_, err = output.PrintWithFormat(c, status)
+			cmd.CheckError(err)
+		},
+	}
+
+	c.Flags().DurationVar(&timeout, "timeout", timeout, "how long to wait for the server to respond")
+
+	output.BindFlags(c.Flags())
+
+	return c
+}
@@ -18,6 +18,7 @@ package restore
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -29,6 +30,7 @@ import (
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/cli/completion"
 	"github.com/heptio/ark/pkg/cmd/util/flag"
 	"github.com/heptio/ark/pkg/cmd/util/output"
 	arkclient "github.com/heptio/ark/pkg/generated/clientset/versioned"
@@ -56,6 +58,7 @@ func NewCreateCommand(f client.Factory, use string) *cobra.Command {
 	o.BindFlags(c.Flags())
 	output.BindFlags(c.Flags())
 	output.ClearOutputFlagDefault(c)
+	completion.CompleteFlagNames(c, "from-backup", "backups")
 
 	return c
 }
@@ -70,10 +73,30 @@ type CreateOptions struct {
 	IncludeResources        flag.StringArray
 	ExcludeResources        flag.StringArray
 	NamespaceMappings       flag.Map
+	ZoneMappings            flag.Map
+	RegistryMappings        flag.Map
+	ClusterDomainMapping    string
+	ClusterDomainFields     flag.StringArray
+	ResourceModifiers       string
 	Selector                flag.LabelSelector
 	IncludeClusterResources flag.OptionalBool
+	PreserveNodePorts       flag.OptionalBool
+	SkipCompletedJobs       flag.OptionalBool
+	RestoreStatus           flag.StringArray
+	IncludeArkResources     bool
+	DryRunPlan              bool
+	AnnotateVolumeSpecs     bool
+	Verify                  bool
+	VerifyTimeout           time.Duration
+	Replicas                *flag.Enum
 
-	client arkclient.Interface
+	client               arkclient.Interface
+	clusterDomainMapping *api.ClusterDomainMapping
+}
+
+var replicasPolicies = []string{
+	string(api.ReplicasPolicyLive),
+	string(api.ReplicasPolicyDesired),
 }
 
 func NewCreateOptions() *CreateOptions {
@@ -81,19 +104,29 @@ func NewCreateOptions() *CreateOptions {
 		Labels:                  flag.NewMap(),
 		IncludeNamespaces:       flag.NewStringArray("*"),
 		NamespaceMappings:       flag.NewMap().WithEntryDelimiter(",").WithKeyValueDelimiter(":"),
+		ZoneMappings:            flag.NewMap().WithEntryDelimiter(",").WithKeyValueDelimiter(":"),
+		RegistryMappings:        flag.NewMap().WithEntryDelimiter(",").WithKeyValueDelimiter("="),
 		RestoreVolumes:          flag.NewOptionalBool(nil),
 		IncludeClusterResources: flag.NewOptionalBool(nil),
+		PreserveNodePorts:       flag.NewOptionalBool(nil),
+		SkipCompletedJobs:       flag.NewOptionalBool(nil),
+		Replicas:                flag.NewEnum("", replicasPolicies...),
 	}
 }
 
 func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&o.BackupName, "from-backup", "", "backup to restore from")
-	flags.Var(&o.IncludeNamespaces, "include-namespaces", "namespaces to include in the restore (use '*' for all namespaces)")
-	flags.Var(&o.ExcludeNamespaces, "exclude-namespaces", "namespaces to exclude from the restore")
+	flags.Var(&o.IncludeNamespaces, "include-namespaces", "namespaces to include in the restore (use '*' for all namespaces). May include patterns such as 'kube-*'")
+	flags.Var(&o.ExcludeNamespaces, "exclude-namespaces", "namespaces to exclude from the restore. May include patterns such as '*-system'")
 	flags.Var(&o.NamespaceMappings, "namespace-mappings", "namespace mappings from name in the backup to desired restored name in the form src1:dst1,src2:dst2,...")
+	flags.Var(&o.ZoneMappings, "availability-zone-mappings", "availability zone mappings from the zone a volume was snapshotted in to the zone to restore it into, in the form src1:dst1,src2:dst2,...")
+	flags.Var(&o.RegistryMappings, "image-registry-mappings", "image registry mappings from the registry prefix in the backup to the desired prefix in the form src1=dst1,src2=dst2,...")
+	flags.StringVar(&o.ClusterDomainMapping, "cluster-domain-mapping", "", "cluster DNS domain mapping from the domain in the backup to the restoring cluster's domain in the form src:dst, such as cluster.local:east.local. References to the domain in configmaps, ingress hosts, and externalName services are rewritten")
+	flags.Var(&o.ClusterDomainFields, "cluster-domain-fields", "additional fields whose cluster domain references are rewritten when --cluster-domain-mapping is set, in the form resource.group:path.to.field, such as certificates.certmanager.k8s.io:spec.dnsNames")
+	flags.StringVar(&o.ResourceModifiers, "resource-modifiers", "", "name of a ConfigMap, in the Ark server's namespace, of rules with JSON patches and JSON merge patches to apply to the restored items they match")
 	flags.Var(&o.Labels, "labels", "labels to apply to the restore")
-	flags.Var(&o.IncludeResources, "include-resources", "resources to include in the restore, formatted as resource.group, such as storageclasses.storage.k8s.io (use '*' for all resources)")
-	flags.Var(&o.ExcludeResources, "exclude-resources", "resources to exclude from the restore, formatted as resource.group, such as storageclasses.storage.k8s.io")
+	flags.Var(&o.IncludeResources, "include-resources", "resources to include in the restore, formatted as resource.group, such as storageclasses.storage.k8s.io (use '*' for all resources, or '*.group' for all resources in a group)")
+	flags.Var(&o.ExcludeResources, "exclude-resources", "resources to exclude from the restore, formatted as resource.group, such as storageclasses.storage.k8s.io (use '*.group' for all resources in a group)")
 	flags.VarP(&o.Selector, "selector", "l", "only restore resources matching this label selector")
 	f := flags.VarPF(&o.RestoreVolumes, "restore-volumes", "", "whether to restore volumes from snapshots")
 	// this allows the user to just specify "--restore-volumes" as shorthand for "--restore-volumes=true"
@@ -102,6 +135,20 @@ func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
 
 	f = flags.VarPF(&o.IncludeClusterResources, "include-cluster-resources", "", "include cluster-scoped resources in the restore")
 	f.NoOptDefVal = "true"
+
+	f = flags.VarPF(&o.PreserveNodePorts, "preserve-node-ports", "", "keep the nodePorts of restored services instead of letting the cluster assign new ones")
+	f.NoOptDefVal = "true"
+
+	f = flags.VarPF(&o.SkipCompletedJobs, "skip-completed-jobs", "", "skip jobs that had completed when they were backed up, rather than running them again. Defaults to true")
+	f.NoOptDefVal = "true"
+
+	flags.BoolVar(&o.IncludeArkResources, "include-ark-resources", o.IncludeArkResources, "restore Ark's Config and Schedules from the backup. Existing ones aren't overwritten, and Backups and Restores are never restored.")
+	flags.Var(&o.RestoreStatus, "restore-status", "resources whose status should be restored, formatted as resource.group, such as certificates.certmanager.k8s.io (use '*' for all resources)")
+	flags.BoolVar(&o.DryRunPlan, "dry-run-plan", o.DryRunPlan, "don't change the cluster; instead, record which items would be created, skipped, or conflict with existing ones in a plan shown by 'ark restore describe'")
+	flags.BoolVar(&o.AnnotateVolumeSpecs, "annotate-volume-specs", o.AnnotateVolumeSpecs, "annotate restored persistent volumes and claims with the reclaim policy, access modes, and storage class they had when backed up")
+	flags.BoolVar(&o.Verify, "verify", o.Verify, "wait for restored deployments, statefulsets, and daemonsets to have all of their replicas ready, recording the readiness of each in the restore's status")
+	flags.DurationVar(&o.VerifyTimeout, "verify-timeout", o.VerifyTimeout, "how long to wait for restored workloads to become ready when --verify is set. Defaults to 5 minutes if unset")
+	flags.Var(o.Replicas, "replicas", fmt.Sprintf("which replica count to restore deployments and statefulsets that were backed up with zero replicas with: Live restores them with zero, and Desired with the count recorded in their %s annotation. Valid values are %s. Defaults to Live.", api.DesiredReplicasAnnotation, strings.Join(replicasPolicies, ", ")))
 }
 
 func (o *CreateOptions) Validate(c *cobra.Command, args []string, f client.Factory) error {
@@ -118,6 +165,12 @@ func (o *CreateOptions) Validate(c *cobra.Command, args []string, f client.Facto
 		return errors.New("Ark client is not set; unable to proceed")
 	}
 
+	clusterDomainMapping, err := parseClusterDomainMapping(o.ClusterDomainMapping, o.ClusterDomainFields)
+	if err != nil {
+		return err
+	}
+	o.clusterDomainMapping = clusterDomainMapping
+
 	if _, err := o.client.ArkV1().Backups(f.Namespace()).Get(o.BackupName, metav1.GetOptions{}); err != nil {
 		return err
 	}
@@ -154,18 +207,44 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 			Labels:    o.Labels.Data(),
 		},
 		Spec: api.RestoreSpec{
-			BackupName:              o.BackupName,
-			IncludedNamespaces:      o.IncludeNamespaces,
-			ExcludedNamespaces:      o.ExcludeNamespaces,
-			IncludedResources:       o.IncludeResources,
-			ExcludedResources:       o.ExcludeResources,
-			NamespaceMapping:        o.NamespaceMappings.Data(),
-			LabelSelector:           o.Selector.LabelSelector,
-			RestorePVs:              o.RestoreVolumes.Value,
-			IncludeClusterResources: o.IncludeClusterResources.Value,
+			BackupName:                  o.BackupName,
+			IncludedNamespaces:          o.IncludeNamespaces,
+			ExcludedNamespaces:          o.ExcludeNamespaces,
+			IncludedResources:           o.IncludeResources,
+			ExcludedResources:           o.ExcludeResources,
+			NamespaceMapping:            o.NamespaceMappings.Data(),
+			AvailabilityZoneMapping:     o.ZoneMappings.Data(),
+			ImageRegistryMapping:        o.RegistryMappings.Data(),
+			ClusterDomainMapping:        o.clusterDomainMapping,
+			ResourceModifierConfigMap:   o.ResourceModifiers,
+			LabelSelector:               o.Selector.LabelSelector,
+			RestorePVs:                  o.RestoreVolumes.Value,
+			IncludeClusterResources:     o.IncludeClusterResources.Value,
+			PreserveNodePorts:           o.PreserveNodePorts.Value,
+			SkipCompletedJobs:           o.SkipCompletedJobs.Value,
+			IncludeArkResources:         o.IncludeArkResources,
+			DryRun:                      o.DryRunPlan,
+			AnnotateOriginalVolumeSpecs: o.AnnotateVolumeSpecs,
+			Replicas:                    api.ReplicasPolicy(o.Replicas.String()),
 		},
 	}
 
+	if len(o.RestoreStatus) > 0 {
+		restore.Spec.RestoreStatus = &api.RestoreStatusSpec{
+			IncludedResources: o.RestoreStatus,
+		}
+	}
+
+	if o.Verify {
+		restore.Spec.Verify = &api.RestoreVerifySpec{
+			Timeout: metav1.Duration{Duration: o.VerifyTimeout},
+		}
+	}
+
+	if username := f.Username(); username != "" {
+		restore.Annotations = map[string]string{api.RequestedByAnnotation: username}
+	}
+
 	if printed, err := output.PrintWithFormat(c, restore); printed || err != nil {
 		return err
 	}
@@ -175,7 +254,46 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 		return err
 	}
 
-	fmt.Printf("Restore request %q submitted successfully.\n", restore.Name)
+	if restore.Spec.DryRun {
+		fmt.Printf("Dry-run restore request %q submitted successfully. The cluster won't be changed.\n", restore.Name)
+	} else {
+		fmt.Printf("Restore request %q submitted successfully.\n", restore.Name)
+	}
 	fmt.Printf("Run `ark restore describe %s` for more details.\n", restore.Name)
 	return nil
 }
+
+// parseClusterDomainMapping returns the cluster domain mapping specified by a "src:dst" mapping
+// and fields in the form "resource.group:path.to.field", or nil if mapping is empty.
+func parseClusterDomainMapping(mapping string, fields []string) (*api.ClusterDomainMapping, error) {
+	if mapping == "" {
+		if len(fields) > 0 {
+			return nil, errors.New("--cluster-domain-fields requires --cluster-domain-mapping")
+		}
+		return nil, nil
+	}
+
+	domains := strings.Split(mapping, ":")
+	if len(domains) != 2 || domains[0] == "" || domains[1] == "" {
+		return nil, errors.Errorf("invalid cluster domain mapping %q, expected src:dst", mapping)
+	}
+
+	res := &api.ClusterDomainMapping{
+		From: domains[0],
+		To:   domains[1],
+	}
+
+	for _, field := range fields {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("invalid cluster domain field %q, expected resource.group:path.to.field", field)
+		}
+
+		if res.ResourceFields == nil {
+			res.ResourceFields = make(map[string][]string)
+		}
+		res.ResourceFields[parts[0]] = append(res.ResourceFields[parts[0]], parts[1])
+	}
+
+	return res, nil
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/cli/completion"
 	"github.com/heptio/ark/pkg/cmd/util/downloadrequest"
 	arkclient "github.com/heptio/ark/pkg/generated/clientset/versioned"
 )
@@ -45,13 +46,15 @@ func NewLogsCommand(f client.Factory) *cobra.Command {
 			cmd.CheckError(l.Validate(f))
 			arkClient, err := f.Client()
 			cmd.CheckError(err)
-			err = downloadrequest.Stream(arkClient.ArkV1(), f.Namespace(), args[0], v1.DownloadTargetKindRestoreLog, os.Stdout, timeout)
+			err = downloadrequest.StreamLog(arkClient.ArkV1(), f.Namespace(), args[0], v1.DownloadTargetKindRestoreLog, os.Stdout, timeout)
 			cmd.CheckError(err)
 		},
 	}
 
 	c.Flags().DurationVar(&timeout, "timeout", timeout, "how long to wait to receive logs")
 
+	completion.CompleteNames(c, "restores")
+
 	return c
 }
 
@@ -87,7 +90,9 @@ func (l *LogsOptions) Validate(f client.Factory) error {
 	if err != nil {
 		return err
 	}
-	if r.Status.Phase != v1.RestorePhaseCompleted {
+	switch r.Status.Phase {
+	case v1.RestorePhaseCompleted, v1.RestorePhasePartiallyFailed, v1.RestorePhaseFailed:
+	default:
 		return errors.Errorf("unable to retrieve logs because restore is not complete")
 	}
 	return nil
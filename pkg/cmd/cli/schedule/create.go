@@ -18,6 +18,8 @@ package schedule
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -29,6 +31,7 @@ import (
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
 	"github.com/heptio/ark/pkg/cmd/cli/backup"
+	"github.com/heptio/ark/pkg/cmd/util/flag"
 	"github.com/heptio/ark/pkg/cmd/util/output"
 )
 
@@ -52,7 +55,7 @@ func NewCreateCommand(f client.Factory, use string) *cobra.Command {
 		Args:    cobra.ExactArgs(1),
 		Run: func(c *cobra.Command, args []string) {
 			cmd.CheckError(o.Complete(args))
-			cmd.CheckError(o.Validate(c, args))
+			cmd.CheckError(o.Validate(c, args, f))
 			cmd.CheckError(o.Run(c, f))
 		},
 	}
@@ -65,29 +68,43 @@ func NewCreateCommand(f client.Factory, use string) *cobra.Command {
 }
 
 type CreateOptions struct {
-	BackupOptions *backup.CreateOptions
-	Schedule      string
+	BackupOptions     *backup.CreateOptions
+	Schedule          string
+	TimeZone          string
+	NameTemplate      string
+	ConcurrencyPolicy *flag.Enum
+	Jitter            time.Duration
 
 	labelSelector *metav1.LabelSelector
 }
 
+var concurrencyPolicies = []string{
+	string(api.ConcurrencyPolicyAllow),
+	string(api.ConcurrencyPolicyForbid),
+}
+
 func NewCreateOptions() *CreateOptions {
 	return &CreateOptions{
-		BackupOptions: backup.NewCreateOptions(),
+		BackupOptions:     backup.NewCreateOptions(),
+		ConcurrencyPolicy: flag.NewEnum("", concurrencyPolicies...),
 	}
 }
 
 func (o *CreateOptions) BindFlags(flags *pflag.FlagSet) {
 	o.BackupOptions.BindFlags(flags)
 	flags.StringVar(&o.Schedule, "schedule", o.Schedule, "a cron expression specifying a recurring schedule for this backup to run")
+	flags.StringVar(&o.TimeZone, "time-zone", o.TimeZone, "the time zone to evaluate the schedule's cron expression in, e.g. 'America/New_York' (default the Ark server's time zone, usually UTC)")
+	flags.StringVar(&o.NameTemplate, "name-template", o.NameTemplate, "a Go template for the names of backups created by this schedule, e.g. '{{.ScheduleName}}-{{.Year}}{{.Month}}{{.Day}}-{{.Hour}}{{.Minute}}'. It must give each run a different name (default <schedule name>-<timestamp>)")
+	flags.Var(o.ConcurrencyPolicy, "concurrency-policy", fmt.Sprintf("what to do when the schedule is due while a backup it created earlier hasn't finished. Valid values are %s. Allow creates a backup for every run, and Forbid skips the run. Defaults to Allow.", strings.Join(concurrencyPolicies, ", ")))
+	flags.DurationVar(&o.Jitter, "jitter", o.Jitter, "the maximum amount of time to delay each run past its scheduled time, so that schedules with the same cron expression don't all run at once. Each schedule is delayed by the same amount every run")
 }
 
-func (o *CreateOptions) Validate(c *cobra.Command, args []string) error {
+func (o *CreateOptions) Validate(c *cobra.Command, args []string, f client.Factory) error {
 	if len(o.Schedule) == 0 {
 		return errors.New("--schedule is required")
 	}
 
-	return o.BackupOptions.Validate(c, args)
+	return o.BackupOptions.Validate(c, args, f)
 }
 
 func (o *CreateOptions) Complete(args []string) error {
@@ -107,15 +124,24 @@ func (o *CreateOptions) Run(c *cobra.Command, f client.Factory) error {
 		},
 		Spec: api.ScheduleSpec{
 			Template: api.BackupSpec{
-				IncludedNamespaces: o.BackupOptions.IncludeNamespaces,
-				ExcludedNamespaces: o.BackupOptions.ExcludeNamespaces,
-				IncludedResources:  o.BackupOptions.IncludeResources,
-				ExcludedResources:  o.BackupOptions.ExcludeResources,
-				LabelSelector:      o.BackupOptions.Selector.LabelSelector,
-				SnapshotVolumes:    o.BackupOptions.SnapshotVolumes.Value,
-				TTL:                metav1.Duration{Duration: o.BackupOptions.TTL},
+				IncludedNamespaces:  o.BackupOptions.IncludeNamespaces,
+				ExcludedNamespaces:  o.BackupOptions.ExcludeNamespaces,
+				IncludedResources:   o.BackupOptions.IncludeResources,
+				ExcludedResources:   o.BackupOptions.ExcludeResources,
+				LabelSelector:       o.BackupOptions.Selector.LabelSelector,
+				SnapshotVolumes:     o.BackupOptions.SnapshotVolumes.Value,
+				TTL:                 metav1.Duration{Duration: o.BackupOptions.TTL},
+				DeletionPolicy:      api.BackupDeletionPolicy(o.BackupOptions.DeletionPolicy.String()),
+				IncludeArkResources: o.BackupOptions.IncludeArkResources.Value,
+				ShardBy:             api.BackupShardBy(o.BackupOptions.ShardBy.String()),
+				Quiesce:             o.BackupOptions.Quiesce(),
+				FailurePolicy:       api.BackupFailurePolicy(o.BackupOptions.FailurePolicy.String()),
 			},
-			Schedule: o.Schedule,
+			Schedule:          o.Schedule,
+			TimeZone:          o.TimeZone,
+			NameTemplate:      o.NameTemplate,
+			ConcurrencyPolicy: api.ConcurrencyPolicy(o.ConcurrencyPolicy.String()),
+			Jitter:            metav1.Duration{Duration: o.Jitter},
 		},
 	}
 
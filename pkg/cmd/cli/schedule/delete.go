@@ -23,6 +23,7 @@ import (
 
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/cli/completion"
 )
 
 func NewDeleteCommand(f client.Factory, use string) *cobra.Command {
@@ -43,5 +44,7 @@ func NewDeleteCommand(f client.Factory, use string) *cobra.Command {
 		},
 	}
 
+	completion.CompleteNames(c, "schedules")
+
 	return c
 }
@@ -24,6 +24,7 @@ import (
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cmd"
+	"github.com/heptio/ark/pkg/cmd/cli/completion"
 	"github.com/heptio/ark/pkg/cmd/util/output"
 )
 
@@ -67,5 +68,7 @@ func NewGetCommand(f client.Factory, use string) *cobra.Command {
 
 	output.BindFlags(c.Flags())
 
+	completion.CompleteNames(c, "schedules")
+
 	return c
 }
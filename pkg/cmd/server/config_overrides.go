@@ -0,0 +1,206 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/cmd/util/flag"
+)
+
+// configMapFlag is the name of the flag naming the ConfigMap that server settings are read from.
+const configMapFlag = "config-map"
+
+// configOverrides holds settings, set with server flags or in the server's ConfigMap, that take
+// precedence over the same settings in the Config. Unset (zero) values leave the Config's value
+// in place.
+type configOverrides struct {
+	backupSyncPeriod         time.Duration
+	gcSyncPeriod             time.Duration
+	scheduleSyncPeriod       time.Duration
+	snapshotSyncPeriod       time.Duration
+	defaultBackupTTL         time.Duration
+	resourcePriorities       flag.StringArray
+	backupStorageProvider    string
+	bucket                   string
+	backupStorageConfig      flag.Map
+	persistentVolumeProvider string
+	persistentVolumeConfig   flag.Map
+}
+
+func newConfigOverrides() configOverrides {
+	return configOverrides{
+		backupStorageConfig:    flag.NewMap(),
+		persistentVolumeConfig: flag.NewMap(),
+	}
+}
+
+func (o *configOverrides) bindFlags(flags *pflag.FlagSet) {
+	flags.DurationVar(&o.backupSyncPeriod, "backup-sync-period", o.backupSyncPeriod, "how often to sync backups in object storage to Backup API objects. Takes precedence over the Config's backupSyncPeriod")
+	flags.DurationVar(&o.gcSyncPeriod, "gc-sync-period", o.gcSyncPeriod, "how often to garbage collect expired backups. Takes precedence over the Config's gcSyncPeriod")
+	flags.DurationVar(&o.scheduleSyncPeriod, "schedule-sync-period", o.scheduleSyncPeriod, "how often to check schedules for backups that are due. Takes precedence over the Config's scheduleSyncPeriod")
+	flags.DurationVar(&o.snapshotSyncPeriod, "snapshot-sync-period", o.snapshotSyncPeriod, "how often to check whether backups' volume snapshots are ready. Takes precedence over the Config's snapshotSyncPeriod")
+	flags.DurationVar(&o.defaultBackupTTL, "default-backup-ttl", o.defaultBackupTTL, "TTL of backups that don't set their own, including those created with ark backup create without --ttl. A negative value, such as -1s, disables it, so those backups never expire. Takes precedence over the Config's defaultBackupTTL")
+	flags.Var(&o.resourcePriorities, "restore-resource-priorities", "comma-separated list of resources to restore first, in order. Takes precedence over the Config's resourcePriorities")
+	flags.StringVar(&o.backupStorageProvider, "backup-storage-provider", o.backupStorageProvider, "name of the object storage provider backups are stored in. Takes precedence over the Config's backupStorageProvider.name")
+	flags.StringVar(&o.bucket, "bucket", o.bucket, "name of the bucket backups are stored in. Takes precedence over the Config's backupStorageProvider.bucket")
+	flags.Var(&o.backupStorageConfig, "backup-storage-config", "configuration for the object storage provider, as key=value pairs (e.g. region=us-east-1). Takes precedence over the Config's backupStorageProvider.config")
+	flags.StringVar(&o.persistentVolumeProvider, "persistent-volume-provider", o.persistentVolumeProvider, "name of the provider to snapshot persistent volumes with. Takes precedence over the Config's persistentVolumeProvider.name")
+	flags.Var(&o.persistentVolumeConfig, "persistent-volume-config", "configuration for the persistent volume provider, as key=value pairs (e.g. region=us-east-1). Takes precedence over the Config's persistentVolumeProvider.config")
+}
+
+// providesBackupStorage returns true if the overrides include everything needed to store
+// backups, so that the server can run without a Config.
+func (o *configOverrides) providesBackupStorage() bool {
+	return o.backupStorageProvider != "" && o.bucket != ""
+}
+
+// apply sets each of c's settings that's overridden to the override's value.
+func (o *configOverrides) apply(c *api.Config, logger logrus.FieldLogger) error {
+	var overridden []string
+
+	setDuration := func(name string, d *metav1.Duration, override time.Duration) {
+		if override > 0 {
+			d.Duration = override
+			overridden = append(overridden, name)
+		}
+	}
+	setDuration("backupSyncPeriod", &c.BackupSyncPeriod, o.backupSyncPeriod)
+	setDuration("gcSyncPeriod", &c.GCSyncPeriod, o.gcSyncPeriod)
+	setDuration("scheduleSyncPeriod", &c.ScheduleSyncPeriod, o.scheduleSyncPeriod)
+	setDuration("snapshotSyncPeriod", &c.SnapshotSyncPeriod, o.snapshotSyncPeriod)
+
+	// a negative default backup TTL disables it, so it's overridden by any non-zero value
+	if o.defaultBackupTTL != 0 {
+		c.DefaultBackupTTL.Duration = o.defaultBackupTTL
+		overridden = append(overridden, "defaultBackupTTL")
+	}
+
+	if len(o.resourcePriorities) > 0 {
+		c.ResourcePriorities = []string(o.resourcePriorities)
+		overridden = append(overridden, "resourcePriorities")
+	}
+
+	if o.backupStorageProvider != "" {
+		c.BackupStorageProvider.Name = o.backupStorageProvider
+		overridden = append(overridden, "backupStorageProvider.name")
+	}
+	if o.bucket != "" {
+		c.BackupStorageProvider.Bucket = o.bucket
+		overridden = append(overridden, "backupStorageProvider.bucket")
+	}
+	if data := o.backupStorageConfig.Data(); len(data) > 0 {
+		c.BackupStorageProvider.Config = copyStringMap(data)
+		overridden = append(overridden, "backupStorageProvider.config")
+	}
+
+	if o.persistentVolumeProvider != "" {
+		if c.PersistentVolumeProvider == nil {
+			c.PersistentVolumeProvider = &api.CloudProviderConfig{}
+		}
+		c.PersistentVolumeProvider.Name = o.persistentVolumeProvider
+		overridden = append(overridden, "persistentVolumeProvider.name")
+	}
+	if data := o.persistentVolumeConfig.Data(); len(data) > 0 {
+		if c.PersistentVolumeProvider == nil {
+			return errors.New("persistent-volume-config can't be set without a persistent volume provider")
+		}
+		c.PersistentVolumeProvider.Config = copyStringMap(data)
+		overridden = append(overridden, "persistentVolumeProvider.config")
+	}
+
+	if len(overridden) > 0 {
+		logger.WithField("settings", overridden).Info("Using server flag or ConfigMap values instead of the Config's")
+	}
+
+	return nil
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	res := make(map[string]string, len(m))
+	for k, v := range m {
+		res[k] = v
+	}
+	return res
+}
+
+// loadConfigMap gets the named ConfigMap in the server's namespace and applies its data to
+// flags. It returns the names of the flags that were set from it.
+func loadConfigMap(f client.Factory, name string, flags *pflag.FlagSet) ([]string, error) {
+	kubeClient, err := f.KubeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	configMap, err := kubeClient.CoreV1().ConfigMaps(f.Namespace()).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting ConfigMap %s/%s", f.Namespace(), name)
+	}
+
+	set, err := applyConfigMap(flags, configMap.Data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error applying ConfigMap %s/%s", f.Namespace(), name)
+	}
+
+	return set, nil
+}
+
+// applyConfigMap sets each flag named by a key in data to the key's value, unless the flag was
+// set on the command line, so that flags take precedence over the ConfigMap. It returns the
+// names of the flags that were set, and an error if a key isn't the name of a flag or its value
+// isn't valid for it.
+func applyConfigMap(flags *pflag.FlagSet, data map[string]string) ([]string, error) {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var set, unknown []string
+	for _, key := range keys {
+		f := flags.Lookup(key)
+		if f == nil || key == configMapFlag {
+			unknown = append(unknown, key)
+			continue
+		}
+
+		if f.Changed {
+			continue
+		}
+
+		if err := flags.Set(key, data[key]); err != nil {
+			return nil, err
+		}
+		set = append(set, key)
+	}
+
+	if len(unknown) > 0 {
+		return nil, errors.Errorf("unknown server settings: %s", strings.Join(unknown, ", "))
+	}
+
+	return set, nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func newTestConfig() *v1.Config {
+	return &v1.Config{
+		BackupSyncPeriod:   metav1.Duration{Duration: time.Hour},
+		GCSyncPeriod:       metav1.Duration{Duration: time.Hour},
+		ResourcePriorities: []string{"namespaces"},
+		BackupStorageProvider: v1.ObjectStorageProviderConfig{
+			CloudProviderConfig: v1.CloudProviderConfig{
+				Name:   "aws",
+				Config: map[string]string{"region": "us-east-1"},
+			},
+			Bucket: "config-bucket",
+		},
+	}
+}
+
+func TestConfigOverridesApply(t *testing.T) {
+	logger := arktest.NewLogger()
+
+	// nothing overridden leaves the Config as it is
+	c := newTestConfig()
+	o := newConfigOverrides()
+	require.NoError(t, o.apply(c, logger))
+	assert.Equal(t, newTestConfig(), c)
+
+	// overridden settings replace the Config's
+	o.backupSyncPeriod = time.Minute
+	o.defaultBackupTTL = -time.Second
+	o.resourcePriorities = []string{"secrets", "configmaps"}
+	o.bucket = "flag-bucket"
+	require.NoError(t, o.backupStorageConfig.Set("region=us-west-2"))
+	o.persistentVolumeProvider = "aws"
+	require.NoError(t, o.persistentVolumeConfig.Set("region=us-west-2"))
+
+	require.NoError(t, o.apply(c, logger))
+	assert.Equal(t, time.Minute, c.BackupSyncPeriod.Duration)
+	assert.Equal(t, time.Hour, c.GCSyncPeriod.Duration)
+	// a negative default backup TTL disables it
+	assert.Equal(t, -time.Second, c.DefaultBackupTTL.Duration)
+	assert.Equal(t, []string{"secrets", "configmaps"}, c.ResourcePriorities)
+	assert.Equal(t, "aws", c.BackupStorageProvider.Name)
+	assert.Equal(t, "flag-bucket", c.BackupStorageProvider.Bucket)
+	assert.Equal(t, map[string]string{"region": "us-west-2"}, c.BackupStorageProvider.Config)
+	require.NotNil(t, c.PersistentVolumeProvider)
+	assert.Equal(t, "aws", c.PersistentVolumeProvider.Name)
+	assert.Equal(t, map[string]string{"region": "us-west-2"}, c.PersistentVolumeProvider.Config)
+
+	// persistent volume config without a provider is an error
+	o = newConfigOverrides()
+	require.NoError(t, o.persistentVolumeConfig.Set("region=us-west-2"))
+	assert.EqualError(t, o.apply(newTestConfig(), logger), "persistent-volume-config can't be set without a persistent volume provider")
+}
+
+func TestConfigOverridesProvidesBackupStorage(t *testing.T) {
+	o := newConfigOverrides()
+	assert.False(t, o.providesBackupStorage())
+
+	o.backupStorageProvider = "gcp"
+	assert.False(t, o.providesBackupStorage())
+
+	o.bucket = "bucket-1"
+	assert.True(t, o.providesBackupStorage())
+}
+
+func TestApplyConfigMap(t *testing.T) {
+	newFlags := func(o *configOverrides) *pflag.FlagSet {
+		flags := pflag.NewFlagSet("server", pflag.ContinueOnError)
+		flags.String(configMapFlag, "", "")
+		o.bindFlags(flags)
+		return flags
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		data        map[string]string
+		expectedSet []string
+		expectedErr string
+		check       func(t *testing.T, o *configOverrides)
+	}{
+		{
+			name: "ConfigMap values set flags",
+			data: map[string]string{
+				"backup-sync-period":          "10m",
+				"restore-resource-priorities": "namespaces,secrets",
+				"backup-storage-config":       "region=us-east-1,s3ForcePathStyle=true",
+			},
+			expectedSet: []string{"backup-storage-config", "backup-sync-period", "restore-resource-priorities"},
+			check: func(t *testing.T, o *configOverrides) {
+				assert.Equal(t, 10*time.Minute, o.backupSyncPeriod)
+				assert.Equal(t, []string{"namespaces", "secrets"}, []string(o.resourcePriorities))
+				assert.Equal(t, map[string]string{"region": "us-east-1", "s3ForcePathStyle": "true"}, o.backupStorageConfig.Data())
+			},
+		},
+		{
+			name:        "flags take precedence over the ConfigMap",
+			args:        []string{"--bucket=flag-bucket"},
+			data:        map[string]string{"bucket": "configmap-bucket", "gc-sync-period": "2h"},
+			expectedSet: []string{"gc-sync-period"},
+			check: func(t *testing.T, o *configOverrides) {
+				assert.Equal(t, "flag-bucket", o.bucket)
+				assert.Equal(t, 2*time.Hour, o.gcSyncPeriod)
+			},
+		},
+		{
+			name:        "unknown keys are an error",
+			data:        map[string]string{"bucket": "bucket-1", "buckit": "bucket-1", "config-map": "other"},
+			expectedErr: "unknown server settings: buckit, config-map",
+		},
+		{
+			name:        "invalid values are an error",
+			data:        map[string]string{"schedule-sync-period": "often"},
+			expectedErr: `invalid argument "often" for "--schedule-sync-period" flag`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := newConfigOverrides()
+			flags := newFlags(&o)
+			require.NoError(t, flags.Parse(test.args))
+
+			set, err := applyConfigMap(flags, test.data)
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedSet, set)
+			test.check(t, &o)
+		})
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/heptio/ark/pkg/controller"
+)
+
+const (
+	// objectStoreCheckPeriod is how often the backup storage bucket is checked to be reachable.
+	// Readiness probes report the result of the latest check, so they don't list the bucket
+	// every time.
+	objectStoreCheckPeriod = 30 * time.Second
+
+	// objectStoreCheckTimeout is how long a check of the backup storage bucket can take before
+	// it's considered to have failed.
+	objectStoreCheckTimeout = 10 * time.Second
+)
+
+// controllerState is whether a controller's Run func is still running.
+type controllerState string
+
+const (
+	controllerStateRunning controllerState = "running"
+	controllerStateStopped controllerState = "stopped"
+)
+
+// healthCheck is the result of one of the checks reported by /healthz or /readyz.
+type healthCheck struct {
+	name string
+	err  error
+}
+
+// healthChecker tracks the state of the server for its liveness (/healthz) and readiness
+// (/readyz) endpoints.
+type healthChecker struct {
+	// ctx is the server's context. Controllers stopping after it's done isn't a failure.
+	ctx   context.Context
+	clock clock.Clock
+
+	lock               sync.Mutex
+	standby            bool
+	controllers        map[string]controllerState
+	controllersStarted bool
+	cachesSynced       bool
+	unsyncedCaches     []string
+
+	objectStoreChecked bool
+	objectStoreErr     error
+}
+
+func newHealthChecker(ctx context.Context) *healthChecker {
+	return &healthChecker{
+		ctx:         ctx,
+		clock:       clock.RealClock{},
+		controllers: make(map[string]controllerState),
+	}
+}
+
+// setStandby records whether the server is waiting to acquire the leader lease.
+func (h *healthChecker) setStandby(standby bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.standby = standby
+}
+
+// runObjectStoreChecks runs check, which checks that backup storage is reachable, every
+// objectStoreCheckPeriod until the server's context is done.
+func (h *healthChecker) runObjectStoreChecks(check func() error) {
+	wait.Until(func() { h.checkObjectStore(check) }, objectStoreCheckPeriod, h.ctx.Done())
+}
+
+// checkObjectStore runs check and records its result, or a timeout if it takes longer than
+// objectStoreCheckTimeout. The lock isn't held while check runs, so a slow object store
+// doesn't block the liveness and readiness checks.
+func (h *healthChecker) checkObjectStore(check func() error) {
+	res := make(chan error, 1)
+	go func() {
+		res <- check()
+	}()
+
+	timeout := h.clock.NewTimer(objectStoreCheckTimeout)
+	defer timeout.Stop()
+
+	var err error
+	select {
+	case err = <-res:
+	case <-timeout.C():
+		err = errors.Errorf("timed out after %v", objectStoreCheckTimeout)
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.objectStoreChecked = true
+	h.objectStoreErr = err
+}
+
+// setControllersStarted records that all of the server's controllers have been started.
+func (h *healthChecker) setControllersStarted() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.controllersStarted = true
+}
+
+// setCachesSynced records the result of waiting for the shared informers' caches to sync,
+// keyed by the type of the informer's objects.
+func (h *healthChecker) setCachesSynced(synced map[string]bool) {
+	var unsynced []string
+	for name, ok := range synced {
+		if !ok {
+			unsynced = append(unsynced, name)
+		}
+	}
+	sort.Strings(unsynced)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.cachesSynced = true
+	h.unsyncedCaches = unsynced
+}
+
+// runController runs c with one worker until ctx is done, recording its state under name.
+func (h *healthChecker) runController(ctx context.Context, name string, c controller.Interface) {
+	h.runControllerWithWorkers(ctx, name, c, 1)
+}
+
+// runControllerWithWorkers runs c with the given number of workers until ctx is done,
+// recording its state under name.
+func (h *healthChecker) runControllerWithWorkers(ctx context.Context, name string, c controller.Interface, workers int) {
+	h.lock.Lock()
+	h.controllers[name] = controllerStateRunning
+	h.lock.Unlock()
+
+	c.Run(ctx, workers)
+
+	h.lock.Lock()
+	h.controllers[name] = controllerStateStopped
+	h.lock.Unlock()
+}
+
+// checkControllers returns an error naming the controllers that have stopped while the
+// server is still running.
+func (h *healthChecker) checkControllers() error {
+	if h.ctx.Err() != nil {
+		return nil
+	}
+
+	var stopped []string
+	for name, state := range h.controllers {
+		if state == controllerStateStopped {
+			stopped = append(stopped, name)
+		}
+	}
+	if len(stopped) == 0 {
+		return nil
+	}
+
+	sort.Strings(stopped)
+	return errors.Errorf("controllers stopped: %v", stopped)
+}
+
+// liveness returns the checks that fail if the server is wedged and should be restarted.
+func (h *healthChecker) liveness() []healthCheck {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return []healthCheck{
+		{name: "controllers", err: h.checkControllers()},
+	}
+}
+
+// readiness returns the checks that fail if the server isn't able to process backups and
+// restores. A server that's waiting to acquire the leader lease is ready.
+func (h *healthChecker) readiness() []healthCheck {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.standby {
+		return []healthCheck{{name: "leaderElection"}}
+	}
+
+	controllersErr := h.checkControllers()
+	if !h.controllersStarted {
+		controllersErr = errors.New("controllers haven't been started")
+	}
+
+	objectStoreErr := h.objectStoreErr
+	if !h.objectStoreChecked {
+		objectStoreErr = errors.New("backup storage hasn't been checked")
+	}
+
+	var cachesErr error
+	switch {
+	case !h.cachesSynced:
+		cachesErr = errors.New("informer caches haven't synced")
+	case len(h.unsyncedCaches) > 0:
+		cachesErr = errors.Errorf("informer caches didn't sync: %v", h.unsyncedCaches)
+	}
+
+	return []healthCheck{
+		{name: "controllers", err: controllersErr},
+		{name: "informers", err: cachesErr},
+		{name: "objectStore", err: objectStoreErr},
+	}
+}
+
+// handler returns an HTTP handler that reports the results of checks, with a 503 status if
+// any of them failed.
+func (h *healthChecker) handler(checks func() []healthCheck) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results := checks()
+
+		status := http.StatusOK
+		for _, check := range results {
+			if check.err != nil {
+				status = http.StatusServiceUnavailable
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(status)
+
+		for _, check := range results {
+			if check.err != nil {
+				fmt.Fprintf(w, "[-]%s failed: %v\n", check.name, check.err)
+			} else {
+				fmt.Fprintf(w, "[+]%s ok\n", check.name)
+			}
+		}
+	})
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// fakeController is a controller.Interface whose Run returns when stop is closed.
+type fakeController struct {
+	stop chan struct{}
+}
+
+func (c *fakeController) Run(ctx context.Context, workers int) error {
+	<-c.stop
+	return nil
+}
+
+// get returns the status and body of a request to handler.
+func get(handler http.Handler) (int, string) {
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+	return res.Code, res.Body.String()
+}
+
+func TestHealthChecker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := newHealthChecker(ctx)
+	liveness, readiness := h.handler(h.liveness), h.handler(h.readiness)
+
+	// a server waiting for the leader lease is ready
+	h.setStandby(true)
+	code, body := get(readiness)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "[+]leaderElection ok\n", body)
+	h.setStandby(false)
+
+	// nothing has been started yet
+	code, body = get(readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "[-]controllers failed: controllers haven't been started\n"+
+		"[-]informers failed: informer caches haven't synced\n"+
+		"[-]objectStore failed: backup storage hasn't been checked\n", body)
+
+	code, body = get(liveness)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "[+]controllers ok\n", body)
+
+	h.checkObjectStore(func() error { return nil })
+	h.setCachesSynced(map[string]bool{"*v1.Backup": true, "*v1.Restore": true})
+
+	backup, restore := &fakeController{stop: make(chan struct{})}, &fakeController{stop: make(chan struct{})}
+	restoreStopped := make(chan struct{})
+	go h.runController(ctx, "backup", backup)
+	go func() {
+		h.runController(ctx, "restore", restore)
+		close(restoreStopped)
+	}()
+	assert.True(t, waitFor(func() bool {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+		return len(h.controllers) == 2
+	}))
+
+	// the server isn't ready until it has started all of its controllers
+	code, body = get(readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body, "[-]controllers failed: controllers haven't been started\n")
+
+	// everything's running
+	h.setControllersStarted()
+	code, body = get(readiness)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "[+]controllers ok\n[+]informers ok\n[+]objectStore ok\n", body)
+
+	// readiness reports the result of the latest object store check
+	h.checkObjectStore(func() error { return errors.New("bucket not found") })
+	code, body = get(readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body, "[-]objectStore failed: bucket not found\n")
+
+	// a check that doesn't finish in time fails, and doesn't block the other checks while it runs
+	fakeClock := clock.NewFakeClock(time.Now())
+	h.clock = fakeClock
+	blocked := make(chan struct{})
+	defer close(blocked)
+	checked := make(chan struct{})
+	go func() {
+		h.checkObjectStore(func() error {
+			<-blocked
+			return nil
+		})
+		close(checked)
+	}()
+	assert.True(t, waitFor(fakeClock.HasWaiters))
+
+	code, _ = get(liveness)
+	assert.Equal(t, http.StatusOK, code)
+	code, body = get(readiness)
+	assert.Contains(t, body, "[-]objectStore failed: bucket not found\n")
+
+	fakeClock.Step(objectStoreCheckTimeout)
+	<-checked
+	code, body = get(readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body, "[-]objectStore failed: timed out after 10s\n")
+
+	h.checkObjectStore(func() error { return nil })
+
+	// a controller that stops while the server is running fails both checks
+	close(restore.stop)
+	<-restoreStopped
+
+	code, body = get(liveness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "[-]controllers failed: controllers stopped: [restore]\n", body)
+
+	code, body = get(readiness)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body, "[-]controllers failed: controllers stopped: [restore]\n")
+
+	// but not once the server is shutting down
+	cancel()
+	code, _ = get(liveness)
+	assert.Equal(t, http.StatusOK, code)
+
+	close(backup.stop)
+}
+
+func TestHealthCheckerUnsyncedCaches(t *testing.T) {
+	h := newHealthChecker(context.Background())
+	h.setCachesSynced(map[string]bool{"*v1.Backup": true, "*v1.Schedule": false, "*v1.Restore": false})
+
+	assert.Equal(t, "informers", h.readiness()[1].name)
+	assert.EqualError(t, h.readiness()[1].err, "informer caches didn't sync: [*v1.Restore *v1.Schedule]")
+}
+
+// waitFor returns whether condition becomes true within a second.
+func waitFor(condition func() bool) bool {
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
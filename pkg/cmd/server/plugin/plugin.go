@@ -83,6 +83,8 @@ func NewCommand(f client.Factory) *cobra.Command {
 					action = backup.NewBackupPVAction(logger)
 				case "pod":
 					action = backup.NewPodAction(logger)
+				case "replicas":
+					action = backup.NewReplicasAction(logger)
 				case "serviceaccount":
 					clientset, err := f.KubeClient()
 					cmd.CheckError(err)
@@ -106,6 +108,10 @@ func NewCommand(f client.Factory) *cobra.Command {
 					action = restore.NewPodAction(logger)
 				case "svc":
 					action = restore.NewServiceAction(logger)
+				case "image-registry":
+					action = restore.NewImageRegistryAction(logger)
+				case "replicas":
+					action = restore.NewReplicasAction(logger)
 				default:
 					logger.Fatal("Unrecognized plugin name")
 				}
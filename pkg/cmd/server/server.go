@@ -19,8 +19,10 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"expvar"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"os/signal"
 	"reflect"
@@ -32,15 +34,17 @@ import (
 
 	"github.com/heptio/ark/pkg/buildinfo"
 	"github.com/pkg/errors"
+	"github.com/satori/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"github.com/spf13/pflag"
 
 	"k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
@@ -50,6 +54,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/audit"
 	"github.com/heptio/ark/pkg/backup"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cloudprovider"
@@ -60,18 +65,47 @@ import (
 	clientset "github.com/heptio/ark/pkg/generated/clientset/versioned"
 	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
+	"github.com/heptio/ark/pkg/leaderelection"
 	"github.com/heptio/ark/pkg/plugin"
 	"github.com/heptio/ark/pkg/restore"
 	"github.com/heptio/ark/pkg/util/kube"
 	"github.com/heptio/ark/pkg/util/logging"
 	"github.com/heptio/ark/pkg/util/stringslice"
+	"github.com/heptio/ark/pkg/util/tracing"
 )
 
-func NewCommand() *cobra.Command {
+// serverConfig holds the server's settings from its command-line flags.
+type serverConfig struct {
+	pluginDir         string
+	deleteOrphans     bool
+	orphanGracePeriod time.Duration
+	leaderElect       bool
+	clientQPS         float32
+	clientBurst       int
+	metricsAddress    string
+	auditEvents       bool
+	traceCollectorURL string
+	configMap         string
+	configOverrides   configOverrides
+
+	autoBackupNamespaceSelector string
+	autoBackupSchedule          string
+	restoreWorkers              int
+}
+
+func NewCommand(f client.Factory) *cobra.Command {
 	var (
 		sortedLogLevels = getSortedLogLevels()
 		logLevelFlag    = flag.NewEnum(logrus.InfoLevel.String(), sortedLogLevels...)
-		pluginDir       = "/plugins"
+		config          = serverConfig{
+			pluginDir:       "/plugins",
+			metricsAddress:  ":8085",
+			configOverrides: newConfigOverrides(),
+
+			autoBackupSchedule: "0 1 * * *",
+			restoreWorkers:     1,
+			orphanGracePeriod:  24 * time.Hour,
+		}
 	)
 
 	var command = &cobra.Command{
@@ -79,6 +113,19 @@ func NewCommand() *cobra.Command {
 		Short: "Run the ark server",
 		Long:  "Run the ark server",
 		Run: func(c *cobra.Command, args []string) {
+			// the client config file isn't applicable to the server, which runs in the namespace
+			// of its pod unless --namespace is set
+			f.UseInClusterNamespace()
+
+			// the ConfigMap is applied before anything else, so that it can set any of the
+			// server's flags, including --log-level
+			var fromConfigMap []string
+			if config.configMap != "" {
+				var err error
+				fromConfigMap, err = loadConfigMap(f, config.configMap, c.Flags())
+				cmd.CheckError(err)
+			}
+
 			logLevel := logrus.InfoLevel
 
 			if parsed, err := logrus.ParseLevel(logLevelFlag.String()); err == nil {
@@ -93,22 +140,11 @@ func NewCommand() *cobra.Command {
 
 			logger := newLogger(logLevel, &logging.ErrorLocationHook{}, &logging.LogLocationHook{})
 			logger.Infof("Starting Ark server %s", buildinfo.FormattedGitSHA())
-
-			// NOTE: the namespace flag is bound to ark's persistent flags when the root ark command
-			// creates the client Factory and binds the Factory's flags. We're not using a Factory here in
-			// the server because the Factory gets its basename set at creation time, and the basename is
-			// used to construct the user-agent for clients. Also, the Factory's Namespace() method uses
-			// the client config file to determine the appropriate namespace to use, and that isn't
-			// applicable to the server (it uses the method directly below instead). We could potentially
-			// add a SetBasename() method to the Factory, and tweak how Namespace() works, if we wanted to
-			// have the server use the Factory.
-			namespaceFlag := c.Flag("namespace")
-			if namespaceFlag == nil {
-				cmd.CheckError(errors.New("unable to look up namespace flag"))
+			if len(fromConfigMap) > 0 {
+				logger.WithField("flags", fromConfigMap).Infof("Applied server settings from ConfigMap %s", config.configMap)
 			}
-			namespace := getServerNamespace(namespaceFlag)
 
-			s, err := newServer(namespace, fmt.Sprintf("%s-%s", c.Parent().Name(), c.Name()), pluginDir, logger)
+			s, err := newServer(f, fmt.Sprintf("%s-%s", c.Parent().Name(), c.Name()), config, logger)
 
 			cmd.CheckError(err)
 
@@ -117,25 +153,24 @@ func NewCommand() *cobra.Command {
 	}
 
 	command.Flags().Var(logLevelFlag, "log-level", fmt.Sprintf("the level at which to log. Valid values are %s.", strings.Join(sortedLogLevels, ", ")))
-	command.Flags().StringVar(&pluginDir, "plugin-dir", pluginDir, "directory containing Ark plugins")
+	command.Flags().StringVar(&config.pluginDir, "plugin-dir", config.pluginDir, "directory containing Ark plugins")
+	command.Flags().BoolVar(&config.deleteOrphans, "delete-orphaned-resources", config.deleteOrphans, "delete volume snapshots and backup files in object storage that don't belong to any backup, instead of only reporting them")
+	command.Flags().DurationVar(&config.orphanGracePeriod, "orphan-grace-period", config.orphanGracePeriod, "with --delete-orphaned-resources, how long a volume snapshot or backup directory has to have not belonged to any backup before it's deleted. This keeps the snapshots and files of backups that are still being taken by other Ark servers sharing the bucket from being deleted")
+	command.Flags().BoolVar(&config.leaderElect, "leader-elect", config.leaderElect, "acquire a leader lease in the Ark namespace before running controllers, so that multiple replicas of the server can be run for high availability")
+	command.Flags().Float32Var(&config.clientQPS, "client-qps", config.clientQPS, "maximum number of requests per second to the Kubernetes API once the burst is used up. Takes precedence over the Config's clientQPS. If neither is set, client-go's default is used")
+	command.Flags().StringVar(&config.metricsAddress, "metrics-address", config.metricsAddress, "the address to serve metrics, such as object storage operation latency and errors, on at /metrics, and liveness and readiness checks on at /healthz and /readyz. Set to an empty string to disable")
+	command.Flags().BoolVar(&config.auditEvents, "audit-events", config.auditEvents, "record Kubernetes events on backups and restores for the destructive operations Ark performs for them, in addition to the audit log in object storage")
+	command.Flags().IntVar(&config.clientBurst, "client-burst", config.clientBurst, "maximum number of requests to the Kubernetes API in a burst. Takes precedence over the Config's clientBurst. If neither is set, client-go's default is used")
+	command.Flags().StringVar(&config.traceCollectorURL, "trace-collector-url", config.traceCollectorURL, "URL of a Zipkin-compatible collector, such as Zipkin or Jaeger's Zipkin endpoint (e.g. http://zipkin:9411/api/v2/spans), to send traces of backups and restores to. Tracing is disabled if it isn't set")
+	command.Flags().StringVar(&config.configMap, configMapFlag, config.configMap, "name of a ConfigMap in the Ark namespace whose data sets server flags, keyed by flag name. Flags set on the command line take precedence over the ConfigMap, which takes precedence over the Config")
+	config.configOverrides.bindFlags(command.Flags())
+	command.Flags().StringVar(&config.autoBackupNamespaceSelector, "auto-backup-namespace-selector", config.autoBackupNamespaceSelector, fmt.Sprintf("label selector for namespaces to back up automatically. If set, the server creates the %s schedule in the Ark namespace, and keeps its included namespaces up to date with the namespaces matching the selector as they're created, relabeled, and deleted", autoBackupScheduleName))
+	command.Flags().IntVar(&config.restoreWorkers, "restore-workers", config.restoreWorkers, "number of restores to run at the same time. Restores into any of the same namespaces as an in-progress restore fail validation instead of running")
+	command.Flags().StringVar(&config.autoBackupSchedule, "auto-backup-schedule", config.autoBackupSchedule, "cron expression that the schedule for --auto-backup-namespace-selector is created with")
 
 	return command
 }
 
-func getServerNamespace(namespaceFlag *pflag.Flag) string {
-	if namespaceFlag.Changed {
-		return namespaceFlag.Value.String()
-	}
-
-	if data, err := ioutil.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace"); err == nil {
-		if ns := strings.TrimSpace(string(data)); len(ns) > 0 {
-			return ns
-		}
-	}
-
-	return api.DefaultNamespace
-}
-
 func newLogger(level logrus.Level, hooks ...logrus.Hook) *logrus.Logger {
 	logger := logrus.New()
 	logger.Level = level
@@ -169,11 +204,13 @@ func getSortedLogLevels() []string {
 
 type server struct {
 	namespace             string
+	baseName              string
 	kubeClientConfig      *rest.Config
 	kubeClient            kubernetes.Interface
 	arkClient             clientset.Interface
 	backupService         cloudprovider.BackupService
 	snapshotService       cloudprovider.SnapshotService
+	snapshotCopyRegion    string
 	discoveryClient       discovery.DiscoveryInterface
 	clientPool            dynamic.ClientPool
 	sharedInformerFactory informers.SharedInformerFactory
@@ -181,56 +218,165 @@ type server struct {
 	cancelFunc            context.CancelFunc
 	logger                logrus.FieldLogger
 	pluginManager         plugin.Manager
+	deleteOrphans         bool
+	orphanGracePeriod     time.Duration
+	leaderElect           bool
+	lostLeadership        chan error
+	factory               client.Factory
+	clientQPS             float32
+	clientBurst           int
+	metricsAddress        string
+	auditEvents           bool
+	traceCollectorURL     string
+	health                *healthChecker
+	configOverrides       configOverrides
+	autoBackupSelector    labels.Selector
+	autoBackupSchedule    string
+	restoreWorkers        int
+
+	// credentialsFiles are the files holding credentials from providers' secrets, which
+	// are removed when the server shuts down.
+	credentialsFiles []string
 }
 
-func newServer(namespace, baseName, pluginDir string, logger *logrus.Logger) (*server, error) {
-	clientConfig, err := client.Config("", "", baseName)
+func newServer(f client.Factory, baseName string, config serverConfig, logger *logrus.Logger) (*server, error) {
+	// the basename is used to construct the user-agent for clients
+	f.SetBasename(baseName)
+	f.SetClientQPS(config.clientQPS)
+	f.SetClientBurst(config.clientBurst)
+
+	// the controllers' work queues are created once the server is running, so their metrics
+	// have to be registered first
+	controller.RegisterWorkqueueMetrics()
+
+	pluginManager, err := plugin.NewManager(logger, logger.Level, config.pluginDir)
 	if err != nil {
 		return nil, err
 	}
 
-	kubeClient, err := kubernetes.NewForConfig(clientConfig)
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	s := &server{
+		namespace:      f.Namespace(),
+		baseName:       baseName,
+		ctx:            ctx,
+		cancelFunc:     cancelFunc,
+		logger:         logger,
+		pluginManager:  pluginManager,
+		deleteOrphans:  config.deleteOrphans,
+		leaderElect:    config.leaderElect,
+		lostLeadership: make(chan error, 1),
+		factory:        f,
+		clientQPS:      config.clientQPS,
+		clientBurst:    config.clientBurst,
+		metricsAddress: config.metricsAddress,
+		auditEvents:    config.auditEvents,
+
+		traceCollectorURL: config.traceCollectorURL,
+		health:            newHealthChecker(ctx),
+		configOverrides:   config.configOverrides,
+		restoreWorkers:    config.restoreWorkers,
+		orphanGracePeriod: config.orphanGracePeriod,
+	}
+
+	if s.restoreWorkers < 1 {
+		return nil, errors.Errorf("restore-workers must be at least 1, got %d", s.restoreWorkers)
+	}
+
+	if config.autoBackupNamespaceSelector != "" {
+		selector, err := labels.Parse(config.autoBackupNamespaceSelector)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid auto-backup-namespace-selector")
+		}
+		s.autoBackupSelector = selector
+		s.autoBackupSchedule = config.autoBackupSchedule
+	}
+
+	if err := s.initClients(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// initClients creates the server's clients using its factory.
+func (s *server) initClients() error {
+	clientConfig, err := s.factory.ClientConfig()
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return err
 	}
 
-	arkClient, err := clientset.NewForConfig(clientConfig)
+	kubeClient, err := s.factory.KubeClient()
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return err
 	}
 
-	pluginManager, err := plugin.NewManager(logger, logger.Level, pluginDir)
+	arkClient, err := s.factory.Client()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	ctx, cancelFunc := context.WithCancel(context.Background())
+	s.kubeClientConfig = clientConfig
+	s.kubeClient = kubeClient
+	s.arkClient = arkClient
+	s.discoveryClient = arkClient.Discovery()
+	s.clientPool = dynamic.NewDynamicClientPool(clientConfig)
+	s.sharedInformerFactory = informers.NewFilteredSharedInformerFactory(arkClient, 0, s.namespace, nil)
 
-	s := &server{
-		namespace:             namespace,
-		kubeClientConfig:      clientConfig,
-		kubeClient:            kubeClient,
-		arkClient:             arkClient,
-		discoveryClient:       arkClient.Discovery(),
-		clientPool:            dynamic.NewDynamicClientPool(clientConfig),
-		sharedInformerFactory: informers.NewFilteredSharedInformerFactory(arkClient, 0, namespace, nil),
-		ctx:           ctx,
-		cancelFunc:    cancelFunc,
-		logger:        logger,
-		pluginManager: pluginManager,
+	return nil
+}
+
+// applyClientRateLimits recreates the server's clients with the config's clientQPS and
+// clientBurst, for whichever of them weren't set with the --client-qps and --client-burst flags.
+func (s *server) applyClientRateLimits(config *api.Config) error {
+	qps, burst := s.clientQPS, s.clientBurst
+	if qps == 0 {
+		qps = config.ClientQPS
+	}
+	if burst == 0 {
+		burst = config.ClientBurst
 	}
 
-	return s, nil
+	if qps == s.clientQPS && burst == s.clientBurst {
+		// the clients were already created with these limits
+		return nil
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"clientQPS":   qps,
+		"clientBurst": burst,
+	}).Info("Applying client rate limits from config")
+
+	s.factory.SetClientQPS(qps)
+	s.factory.SetClientBurst(burst)
+	s.clientQPS, s.clientBurst = qps, burst
+
+	return s.initClients()
 }
 
 func (s *server) run() error {
+	defer s.removeCredentialsFiles()
 	defer s.pluginManager.CleanupClients()
 	s.handleShutdownSignals()
+	s.serveMetrics()
+	s.startTracing()
 
 	if err := s.ensureArkNamespace(); err != nil {
 		return err
 	}
 
+	if s.leaderElect {
+		s.health.setStandby(true)
+		if err := s.acquireLeadership(); err != nil {
+			if s.ctx.Err() != nil {
+				// shut down before becoming the leader
+				return nil
+			}
+			return err
+		}
+		s.health.setStandby(false)
+	}
+
 	originalConfig, err := s.loadConfig()
 	if err != nil {
 		return err
@@ -239,9 +385,24 @@ func (s *server) run() error {
 	// watchConfig needs to examine the unmodified original config, so we keep that around as a
 	// separate object, and instead apply defaults to a clone.
 	config := originalConfig.DeepCopy()
+	if err := s.configOverrides.apply(config, s.logger); err != nil {
+		return err
+	}
 	applyConfigDefaults(config, s.logger)
 
-	s.watchConfig(originalConfig)
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	if err := s.applyClientRateLimits(config); err != nil {
+		return err
+	}
+
+	// without a Config, there's nothing to watch: all of the settings come from flags, which
+	// can't change while the server is running
+	if originalConfig.Name != "" {
+		s.watchConfig(originalConfig)
+	}
 
 	if err := s.initBackupService(config); err != nil {
 		return err
@@ -255,6 +416,82 @@ func (s *server) run() error {
 		return err
 	}
 
+	select {
+	case err := <-s.lostLeadership:
+		return err
+	default:
+	}
+
+	return nil
+}
+
+// serveMetrics serves the metrics published with expvar, such as cloudprovider.ObjectStoreMetrics
+// and controller.WorkqueueMetrics, as JSON at /metrics on s.metricsAddress until the server shuts
+// down, along with the server's liveness and readiness checks at /healthz and /readyz. It's a no-op
+// if the address is empty.
+func (s *server) serveMetrics() {
+	if s.metricsAddress == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", expvar.Handler())
+	mux.Handle("/healthz", s.health.handler(s.health.liveness))
+	mux.Handle("/readyz", s.health.handler(s.health.readiness))
+	metricsServer := &http.Server{Addr: s.metricsAddress, Handler: mux}
+
+	go func() {
+		<-s.ctx.Done()
+		metricsServer.Close()
+	}()
+
+	go func() {
+		s.logger.Infof("Serving metrics at %s/metrics", s.metricsAddress)
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			s.logger.WithError(err).Error("Error serving metrics")
+		}
+	}()
+}
+
+// startTracing enables tracing of backups and restores, sending spans to s.traceCollectorURL
+// until the server shuts down. It's a no-op if the URL is empty.
+func (s *server) startTracing() {
+	if s.traceCollectorURL == "" {
+		return
+	}
+
+	tracer := tracing.NewTracer(s.traceCollectorURL, "ark", s.logger)
+	tracing.SetTracer(tracer)
+	go tracer.Run(s.ctx.Done())
+
+	s.logger.Infof("Sending traces to %s", s.traceCollectorURL)
+}
+
+// acquireLeadership blocks until this server holds the leader lease in the Ark namespace, then
+// keeps renewing it in the background. If the lease can't be renewed, s.cancelFunc is invoked so
+// the controllers shut down before another replica takes over.
+func (s *server) acquireLeadership() error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// the identity is unique to this process, so that a restarted pod with the same hostname
+	// doesn't take over the lease its previous incarnation held while that may still be running
+	identity := hostname + "_" + uuid.NewV4().String()
+
+	elector := leaderelection.NewLeaderElector(s.kubeClient.CoreV1(), s.namespace, leaderLockName, identity, s.logger)
+	if err := elector.Acquire(s.ctx); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := elector.Renew(s.ctx); err != nil {
+			s.logger.WithError(err).Error("Lost leadership. Shutting down")
+			s.lostLeadership <- err
+			s.cancelFunc()
+		}
+	}()
+
 	return nil
 }
 
@@ -286,12 +523,16 @@ func (s *server) loadConfig() (*api.Config, error) {
 	for {
 		config, err = s.arkClient.ArkV1().Configs(s.namespace).Get("default", metav1.GetOptions{})
 		if err == nil {
+			s.logger.Warn("The Config resource is deprecated and will be removed in a future release. Set its settings with server flags or a ConfigMap instead")
 			break
 		}
 		if !apierrors.IsNotFound(err) {
 			s.logger.WithError(err).Error("Error retrieving configuration")
+		} else if s.configOverrides.providesBackupStorage() {
+			s.logger.Info("Configuration not found, using server flags and ConfigMap settings")
+			return &api.Config{ObjectMeta: metav1.ObjectMeta{Namespace: s.namespace}}, nil
 		} else {
-			s.logger.Info("Configuration not found")
+			s.logger.Info("Configuration not found, and backup storage isn't set with server flags or a ConfigMap")
 		}
 		s.logger.Info("Will attempt to retrieve configuration again in 5 seconds")
 		time.Sleep(5 * time.Second)
@@ -301,9 +542,26 @@ func (s *server) loadConfig() (*api.Config, error) {
 }
 
 const (
-	defaultGCSyncPeriod       = 60 * time.Minute
-	defaultBackupSyncPeriod   = 60 * time.Minute
-	defaultScheduleSyncPeriod = time.Minute
+	defaultGCSyncPeriod           = 60 * time.Minute
+	defaultBackupSyncPeriod       = 60 * time.Minute
+	defaultScheduleSyncPeriod     = time.Minute
+	defaultSnapshotSyncPeriod     = time.Minute
+	defaultDiscoveryRefreshPeriod = 5 * time.Minute
+	defaultClientRequestTimeout   = time.Minute
+	defaultClientRequestRetries   = 3
+	defaultBackupTTL              = 30 * 24 * time.Hour
+
+	// dynamicClientCacheSize is how many clients for the items being backed up or
+	// restored are kept for reuse.
+	dynamicClientCacheSize = 500
+
+	// autoBackupScheduleName is the name of the schedule, in the Ark namespace, that backs up
+	// the namespaces matching --auto-backup-namespace-selector.
+	autoBackupScheduleName = "namespace-auto-backup"
+
+	// leaderLockName is the name of the ConfigMap in the Ark namespace that records the
+	// leader lease when leader election is enabled.
+	leaderLockName = "ark-leader"
 )
 
 var defaultResourcePriorities = []string{
@@ -329,6 +587,30 @@ func applyConfigDefaults(c *api.Config, logger logrus.FieldLogger) {
 		c.ScheduleSyncPeriod.Duration = defaultScheduleSyncPeriod
 	}
 
+	if c.SnapshotSyncPeriod.Duration == 0 {
+		c.SnapshotSyncPeriod.Duration = defaultSnapshotSyncPeriod
+	}
+
+	if c.DiscoveryRefreshPeriod.Duration == 0 {
+		c.DiscoveryRefreshPeriod.Duration = defaultDiscoveryRefreshPeriod
+	}
+
+	if c.ClientRequestTimeout.Duration == 0 {
+		c.ClientRequestTimeout.Duration = defaultClientRequestTimeout
+	}
+
+	if c.ClientRequestRetries == 0 {
+		c.ClientRequestRetries = defaultClientRequestRetries
+	}
+
+	if c.DefaultBackupTTL.Duration == 0 {
+		c.DefaultBackupTTL.Duration = defaultBackupTTL
+	}
+
+	if c.MaxAdditionalItemDepth == 0 {
+		c.MaxAdditionalItemDepth = backup.DefaultMaxAdditionalItemDepth
+	}
+
 	if len(c.ResourcePriorities) == 0 {
 		c.ResourcePriorities = defaultResourcePriorities
 		logger.WithField("priorities", c.ResourcePriorities).Info("Using default resource priorities")
@@ -336,6 +618,18 @@ func applyConfigDefaults(c *api.Config, logger logrus.FieldLogger) {
 		logger.WithField("priorities", c.ResourcePriorities).Info("Using resource priorities from config")
 	}
 
+	if c.RestoreAPIVersionCheck == "" {
+		c.RestoreAPIVersionCheck = api.APIVersionCheckActionWarn
+	}
+
+	if c.OrphanedBackupAction == "" {
+		c.OrphanedBackupAction = api.OrphanedBackupActionLabel
+	}
+
+	if c.LargeItemAction == "" {
+		c.LargeItemAction = api.LargeItemActionWarn
+	}
+
 	if c.BackupStorageProvider.Config == nil {
 		c.BackupStorageProvider.Config = make(map[string]string)
 	}
@@ -346,6 +640,133 @@ func applyConfigDefaults(c *api.Config, logger logrus.FieldLogger) {
 	c.BackupStorageProvider.Config["bucket"] = c.BackupStorageProvider.Bucket
 }
 
+// validateConfig returns an error if the config contains invalid values.
+func validateConfig(c *api.Config) error {
+	if !isValidAPIVersionCheckAction(c.RestoreAPIVersionCheck) {
+		return errors.Errorf("invalid restoreAPIVersionCheck %q", c.RestoreAPIVersionCheck)
+	}
+
+	for resource, action := range c.RestoreAPIVersionCheckOverrides {
+		if !isValidAPIVersionCheckAction(action) {
+			return errors.Errorf("invalid restoreAPIVersionCheckOverrides action %q for %s", action, resource)
+		}
+	}
+
+	switch c.OrphanedBackupAction {
+	case api.OrphanedBackupActionIgnore, api.OrphanedBackupActionLabel, api.OrphanedBackupActionDelete:
+	default:
+		return errors.Errorf("invalid orphanedBackupAction %q", c.OrphanedBackupAction)
+	}
+
+	if c.InterruptedBackupRetries < 0 {
+		return errors.Errorf("invalid interruptedBackupRetries %d", c.InterruptedBackupRetries)
+	}
+
+	if c.ClientQPS < 0 {
+		return errors.Errorf("invalid clientQPS %v", c.ClientQPS)
+	}
+
+	if c.ClientBurst < 0 {
+		return errors.Errorf("invalid clientBurst %d", c.ClientBurst)
+	}
+
+	if c.ClientRequestTimeout.Duration < 0 {
+		return errors.Errorf("invalid clientRequestTimeout %s", c.ClientRequestTimeout.Duration)
+	}
+
+	if c.ClientRequestRetries < 0 {
+		return errors.Errorf("invalid clientRequestRetries %d", c.ClientRequestRetries)
+	}
+
+	if c.MaxItemSizeBytes < 0 {
+		return errors.Errorf("invalid maxItemSizeBytes %d", c.MaxItemSizeBytes)
+	}
+
+	if c.MaxAdditionalItemDepth < 0 {
+		return errors.Errorf("invalid maxAdditionalItemDepth %d", c.MaxAdditionalItemDepth)
+	}
+
+	switch c.LargeItemAction {
+	case api.LargeItemActionWarn, api.LargeItemActionSkip, api.LargeItemActionOffload:
+	default:
+		return errors.Errorf("invalid largeItemAction %q", c.LargeItemAction)
+	}
+
+	if c.LogChunkSizeBytes < 0 {
+		return errors.Errorf("invalid logChunkSizeBytes %d", c.LogChunkSizeBytes)
+	}
+
+	if c.MaxLogSizeBytes < 0 {
+		return errors.Errorf("invalid maxLogSizeBytes %d", c.MaxLogSizeBytes)
+	}
+
+	if pathTemplate := c.BackupStorageProvider.PathTemplate; pathTemplate != "" {
+		if err := cloudprovider.ValidatePathTemplate(pathTemplate, c.ClusterName); err != nil {
+			return errors.WithMessage(err, "invalid backupStorageProvider pathTemplate")
+		}
+	}
+
+	clusterNames := make(map[string]struct{})
+	for _, cluster := range c.AdditionalClusters {
+		if cluster.Name == "" || strings.ContainsAny(cluster.Name, "/.") {
+			return errors.Errorf("invalid additionalClusters name %q", cluster.Name)
+		}
+		if _, exists := clusterNames[cluster.Name]; exists {
+			return errors.Errorf("additionalClusters name %q is used more than once", cluster.Name)
+		}
+		clusterNames[cluster.Name] = struct{}{}
+
+		if cluster.KubeconfigSecret == "" {
+			return errors.Errorf("additionalClusters entry %q must specify a kubeconfigSecret", cluster.Name)
+		}
+	}
+
+	for _, resource := range c.CohabitatingResources {
+		if resource.Resource == "" {
+			return errors.New("cohabitatingResources entries must specify a resource")
+		}
+		if len(sets.NewString(resource.Groups...)) < 2 {
+			return errors.Errorf("cohabitatingResources entry %q must specify at least two different groups", resource.Resource)
+		}
+	}
+
+	// removing any of these would leave an item that can't be restored
+	unremovableFields := sets.NewString("apiVersion", "kind", "metadata", "metadata.name", "metadata.namespace")
+	for i, sanitizer := range c.BackupSanitizers {
+		if len(sanitizer.RemoveFields) == 0 && len(sanitizer.RemoveAnnotations) == 0 {
+			return errors.Errorf("backupSanitizers entry %d must specify removeFields or removeAnnotations", i)
+		}
+		if sanitizer.FieldSelector != "" {
+			if _, err := fields.ParseSelector(sanitizer.FieldSelector); err != nil {
+				return errors.Wrapf(err, "invalid backupSanitizers entry %d fieldSelector", i)
+			}
+		}
+		for _, field := range sanitizer.RemoveFields {
+			if field == "" || strings.HasPrefix(field, ".") || strings.HasSuffix(field, ".") || strings.Contains(field, "..") {
+				return errors.Errorf("backupSanitizers entry %d has invalid removeFields path %q", i, field)
+			}
+			if unremovableFields.Has(field) {
+				return errors.Errorf("backupSanitizers entry %d can't remove %s", i, field)
+			}
+		}
+		for _, annotation := range sanitizer.RemoveAnnotations {
+			if annotation == "" {
+				return errors.Errorf("backupSanitizers entry %d has invalid removeAnnotations key %q", i, annotation)
+			}
+		}
+	}
+
+	return nil
+}
+
+func isValidAPIVersionCheckAction(action api.APIVersionCheckAction) bool {
+	switch action {
+	case api.APIVersionCheckActionIgnore, api.APIVersionCheckActionWarn, api.APIVersionCheckActionFail:
+		return true
+	}
+	return false
+}
+
 // watchConfig adds an update event handler to the Config shared informer, invoking s.cancelFunc
 // when it sees a change.
 func (s *server) watchConfig(config *api.Config) {
@@ -371,6 +792,13 @@ func (s *server) watchConfig(config *api.Config) {
 				config.APIVersion = updated.APIVersion
 			}
 
+			// the server updates the Config's status itself, so changes to it (and the resulting
+			// new resource version and generation) aren't configuration changes
+			updated = updated.DeepCopy()
+			updated.Status = config.Status
+			updated.ResourceVersion = config.ResourceVersion
+			updated.Generation = config.Generation
+
 			if !reflect.DeepEqual(config, updated) {
 				s.logger.Info("Detected a config change. Gracefully shutting down")
 				s.cancelFunc()
@@ -392,12 +820,48 @@ func (s *server) handleShutdownSignals() {
 
 func (s *server) initBackupService(config *api.Config) error {
 	s.logger.Info("Configuring cloud provider for backup service")
-	objectStore, err := getObjectStore(config.BackupStorageProvider.CloudProviderConfig, s.pluginManager)
+	cloudConfig, err := s.withCredentialsFile(config.BackupStorageProvider.CloudProviderConfig)
+	if err != nil {
+		return err
+	}
+
+	objectStore, err := getObjectStore(cloudConfig, s.pluginManager)
 	if err != nil {
 		return err
 	}
 
-	s.backupService = cloudprovider.NewBackupService(objectStore, s.logger)
+	if pathTemplate := config.BackupStorageProvider.PathTemplate; pathTemplate != "" {
+		s.logger.WithField("pathTemplate", pathTemplate).Info("Storing backups using path template")
+	}
+	if config.LogChunkSizeBytes > 0 || config.MaxLogSizeBytes > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"logChunkSizeBytes": config.LogChunkSizeBytes,
+			"maxLogSizeBytes":   config.MaxLogSizeBytes,
+		}).Info("Limiting the size of backup and restore log files")
+	}
+	s.backupService = cloudprovider.NewBackupServiceWithConfig(objectStore, cloudprovider.BackupServiceConfig{
+		PathTemplate:      config.BackupStorageProvider.PathTemplate,
+		ClusterName:       config.ClusterName,
+		LogChunkSizeBytes: config.LogChunkSizeBytes,
+		MaxLogSizeBytes:   config.MaxLogSizeBytes,
+	}, s.logger)
+
+	bucket := config.BackupStorageProvider.Bucket
+	accessErr := s.backupService.ValidateAccess(bucket, config.RestoreOnlyMode)
+	if config.Name != "" {
+		if err := controller.UpdateBackupStorageStatus(s.arkClient.ArkV1(), config.Namespace, config.Name, accessErr, time.Now()); err != nil {
+			s.logger.WithError(err).Error("Error updating config status")
+		}
+	}
+	if accessErr != nil {
+		return errors.WithMessage(accessErr, fmt.Sprintf("backup storage bucket %s is not accessible", bucket))
+	}
+	s.logger.WithField("bucket", bucket).Info("Validated access to backup storage")
+
+	go s.health.runObjectStoreChecks(func() error {
+		return s.backupService.ValidateAccess(bucket, true)
+	})
+
 	return nil
 }
 
@@ -408,14 +872,107 @@ func (s *server) initSnapshotService(config *api.Config) error {
 	}
 
 	s.logger.Info("Configuring cloud provider for snapshot service")
-	blockStore, err := getBlockStore(*config.PersistentVolumeProvider, s.pluginManager)
+	cloudConfig, err := s.withCredentialsFile(*config.PersistentVolumeProvider)
 	if err != nil {
 		return err
 	}
-	s.snapshotService = cloudprovider.NewSnapshotService(blockStore)
+
+	blockStore, err := getBlockStore(cloudConfig, s.pluginManager)
+	if err != nil {
+		return err
+	}
+
+	retryConfig, err := cloudprovider.ParseRetryConfig(config.PersistentVolumeProvider.Config)
+	if err != nil {
+		return err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"maxAttempts":    retryConfig.MaxAttempts,
+		"initialBackoff": retryConfig.InitialBackoff,
+		"maxBackoff":     retryConfig.MaxBackoff,
+	}).Info("Using retry configuration for throttled snapshot API calls")
+
+	// scope the snapshots this server takes, and looks for, to its backup storage bucket and to
+	// this installation, since servers in other clusters can share the bucket
+	ns, err := s.kubeClient.CoreV1().Namespaces().Get(s.namespace, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "error getting namespace %s", s.namespace)
+	}
+	scopeTags := map[string]string{
+		api.SnapshotBucketTagKey:  config.BackupStorageProvider.Bucket,
+		api.SnapshotClusterTagKey: string(ns.UID),
+	}
+	s.snapshotService = cloudprovider.NewSnapshotService(blockStore, retryConfig, scopeTags)
+
+	if region := config.PersistentVolumeProvider.Config[cloudprovider.SnapshotCopyRegionKey]; region != "" {
+		s.logger.WithField("region", region).Info("Volume snapshots will be copied to a secondary region")
+		s.snapshotCopyRegion = region
+	}
+
 	return nil
 }
 
+// withCredentialsFile is like the withCredentialsFile function, and records the file it writes,
+// if any, so it's removed when the server shuts down. Plugins may read the file whenever they
+// need to refresh their credentials, so it's kept until then.
+func (s *server) withCredentialsFile(cloudConfig api.CloudProviderConfig) (api.CloudProviderConfig, error) {
+	res, file, err := withCredentialsFile(cloudConfig, s.kubeClient.CoreV1().Secrets(s.namespace), "")
+	if file != "" {
+		s.credentialsFiles = append(s.credentialsFiles, file)
+	}
+	return res, err
+}
+
+// removeCredentialsFiles removes the files written by withCredentialsFile.
+func (s *server) removeCredentialsFiles() {
+	for _, file := range s.credentialsFiles {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			s.logger.WithError(errors.WithStack(err)).WithField("file", file).Error("Error removing credentials file")
+		}
+	}
+	s.credentialsFiles = nil
+}
+
+// withCredentialsFile returns a copy of cloudConfig that, if it has a credentials secret, points
+// its provider at a file in dir (or the default temp directory, if dir is empty) holding the
+// secret's credentials, along with the file's name. The caller is responsible for removing the
+// file once the provider no longer needs it.
+func withCredentialsFile(cloudConfig api.CloudProviderConfig, secrets kcorev1client.SecretInterface, dir string) (api.CloudProviderConfig, string, error) {
+	if cloudConfig.CredentialsSecret == "" {
+		return cloudConfig, "", nil
+	}
+
+	secret, err := secrets.Get(cloudConfig.CredentialsSecret, metav1.GetOptions{})
+	if err != nil {
+		return api.CloudProviderConfig{}, "", errors.Wrapf(err, "error getting credentials secret for provider %s", cloudConfig.Name)
+	}
+
+	creds, ok := secret.Data[api.CloudCredentialsKey]
+	if !ok {
+		return api.CloudProviderConfig{}, "", errors.Errorf("credentials secret for provider %s does not have a %q key", cloudConfig.Name, api.CloudCredentialsKey)
+	}
+
+	// ioutil.TempFile creates the file readable only by the server (and its plugins)
+	file, err := ioutil.TempFile(dir, "ark-credentials-")
+	if err != nil {
+		return api.CloudProviderConfig{}, "", errors.WithStack(err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(creds); err != nil {
+		os.Remove(file.Name())
+		return api.CloudProviderConfig{}, "", errors.WithStack(err)
+	}
+
+	res := *cloudConfig.DeepCopy()
+	if res.Config == nil {
+		res.Config = make(map[string]string)
+	}
+	res.Config[cloudprovider.CredentialsFileKey] = file.Name()
+
+	return res, file.Name(), nil
+}
+
 func getObjectStore(cloudConfig api.CloudProviderConfig, manager plugin.Manager) (cloudprovider.ObjectStore, error) {
 	if cloudConfig.Name == "" {
 		return nil, errors.New("object storage provider name must not be empty")
@@ -430,7 +987,7 @@ func getObjectStore(cloudConfig api.CloudProviderConfig, manager plugin.Manager)
 		return nil, err
 	}
 
-	return objectStore, nil
+	return cloudprovider.NewInstrumentedObjectStore(objectStore, cloudConfig.Name), nil
 }
 
 func getBlockStore(cloudConfig api.CloudProviderConfig, manager plugin.Manager) (cloudprovider.BlockStore, error) {
@@ -472,17 +1029,23 @@ func (s *server) runControllers(config *api.Config) error {
 		s.logger,
 	)
 
+	auditLog := s.newAuditLog(ctx, config.BackupStorageProvider.Bucket, &wg)
+
 	backupSyncController := controller.NewBackupSyncController(
+		s.arkClient.ArkV1(),
 		s.arkClient.ArkV1(),
 		s.backupService,
 		config.BackupStorageProvider.Bucket,
 		config.BackupSyncPeriod.Duration,
+		config.OrphanedBackupAction,
 		s.namespace,
+		config.Name,
+		config.RestoreOnlyMode,
 		s.logger,
 	)
 	wg.Add(1)
 	go func() {
-		backupSyncController.Run(ctx, 1)
+		s.health.runController(ctx, "backupSync", backupSyncController)
 		wg.Done()
 	}()
 
@@ -496,16 +1059,22 @@ func (s *server) runControllers(config *api.Config) error {
 				s.logger.WithError(err).Error("Error refreshing discovery")
 			}
 		},
-		5*time.Minute,
+		config.DiscoveryRefreshPeriod.Duration,
 		ctx.Done(),
 	)
 
+	operationTracker := controller.NewOperationTracker()
+
+	// the backupper and restorer share a dynamic factory so they reuse each other's clients
+	itemDynamicFactory, err := newItemDynamicFactory(s.clientPool, config)
+	cmd.CheckError(err)
+
 	if config.RestoreOnlyMode {
 		s.logger.Info("Restore only mode - not starting the backup, schedule, delete-backup, or GC controllers")
 	} else {
-		backupTracker := controller.NewBackupTracker()
+		additionalClusters := s.newAdditionalClusters(config)
 
-		backupper, err := newBackupper(discoveryHelper, s.clientPool, s.backupService, s.snapshotService, s.kubeClientConfig, s.kubeClient.CoreV1())
+		backupper, err := newBackupper(discoveryHelper, itemDynamicFactory, s.backupService, s.snapshotService, s.kubeClientConfig, s.kubeClient.CoreV1(), additionalClusters, config.CohabitatingResources, config.BackupSanitizers)
 		cmd.CheckError(err)
 		backupController := controller.NewBackupController(
 			s.sharedInformerFactory.Ark().V1().Backups(),
@@ -516,11 +1085,23 @@ func (s *server) runControllers(config *api.Config) error {
 			s.snapshotService != nil,
 			s.logger,
 			s.pluginManager,
-			backupTracker,
+			operationTracker,
+			s.snapshotService,
+			config.SnapshotSyncPeriod.Duration,
+			config.CompleteBackupsBeforeSnapshotsReady,
+			s.snapshotCopyRegion,
+			discoveryHelper,
+			config.ClusterName,
+			config.ProvenanceAnnotations,
+			config.InterruptedBackupRetries,
+			config.MaxItemSizeBytes,
+			config.LargeItemAction,
+			config.DefaultBackupTTL.Duration,
+			config.MaxAdditionalItemDepth,
 		)
 		wg.Add(1)
 		go func() {
-			backupController.Run(ctx, 1)
+			s.health.runController(ctx, "backup", backupController)
 			wg.Done()
 		}()
 
@@ -530,14 +1111,41 @@ func (s *server) runControllers(config *api.Config) error {
 			s.arkClient.ArkV1(),
 			s.sharedInformerFactory.Ark().V1().Schedules(),
 			config.ScheduleSyncPeriod.Duration,
+			config.ClusterName,
 			s.logger,
 		)
 		wg.Add(1)
 		go func() {
-			scheduleController.Run(ctx, 1)
+			s.health.runController(ctx, "schedule", scheduleController)
 			wg.Done()
 		}()
 
+		if s.autoBackupSelector != nil {
+			namespaceInformer := cache.NewSharedIndexInformer(
+				cache.NewListWatchFromClient(s.kubeClient.CoreV1().RESTClient(), "namespaces", metav1.NamespaceAll, fields.Everything()),
+				&v1.Namespace{},
+				0,
+				cache.Indexers{},
+			)
+			go namespaceInformer.Run(ctx.Done())
+
+			namespaceBackupController := controller.NewNamespaceBackupController(
+				s.namespace,
+				autoBackupScheduleName,
+				s.autoBackupSchedule,
+				s.autoBackupSelector,
+				namespaceInformer,
+				s.sharedInformerFactory.Ark().V1().Schedules(),
+				s.arkClient.ArkV1(),
+				s.logger,
+			)
+			wg.Add(1)
+			go func() {
+				s.health.runController(ctx, "namespaceBackup", namespaceBackupController)
+				wg.Done()
+			}()
+		}
+
 		gcController := controller.NewGCController(
 			s.logger,
 			s.sharedInformerFactory.Ark().V1().Backups(),
@@ -546,7 +1154,7 @@ func (s *server) runControllers(config *api.Config) error {
 		)
 		wg.Add(1)
 		go func() {
-			gcController.Run(ctx, 1)
+			s.health.runController(ctx, "gc", gcController)
 			wg.Done()
 		}()
 
@@ -560,11 +1168,30 @@ func (s *server) runControllers(config *api.Config) error {
 			config.BackupStorageProvider.Bucket,
 			s.sharedInformerFactory.Ark().V1().Restores(),
 			s.arkClient.ArkV1(), // restoreClient
-			backupTracker,
+			operationTracker,
+			auditLog,
 		)
 		wg.Add(1)
 		go func() {
-			backupDeletionController.Run(ctx, 1)
+			s.health.runController(ctx, "backupDeletion", backupDeletionController)
+			wg.Done()
+		}()
+
+		orphanController := controller.NewOrphanController(
+			s.arkClient.ArkV1(),
+			s.backupService,
+			config.BackupStorageProvider.Bucket,
+			s.snapshotService,
+			config.GCSyncPeriod.Duration,
+			s.namespace,
+			s.deleteOrphans,
+			s.orphanGracePeriod,
+			auditLog,
+			s.logger,
+		)
+		wg.Add(1)
+		go func() {
+			s.health.runController(ctx, "orphan", orphanController)
 			wg.Done()
 		}()
 
@@ -572,12 +1199,14 @@ func (s *server) runControllers(config *api.Config) error {
 
 	restorer, err := newRestorer(
 		discoveryHelper,
-		s.clientPool,
+		itemDynamicFactory,
 		s.backupService,
 		s.snapshotService,
 		config.ResourcePriorities,
+		config.CohabitatingResources,
 		s.arkClient.ArkV1(),
 		s.kubeClient,
+		auditLog,
 		s.logger,
 	)
 	cmd.CheckError(err)
@@ -594,10 +1223,15 @@ func (s *server) runControllers(config *api.Config) error {
 		s.snapshotService != nil,
 		s.logger,
 		s.pluginManager,
+		discoveryHelper,
+		config.RestoreAPIVersionCheck,
+		config.RestoreAPIVersionCheckOverrides,
+		controller.NewRestoreTracker(),
+		operationTracker,
 	)
 	wg.Add(1)
 	go func() {
-		restoreController.Run(ctx, 1)
+		s.health.runControllerWithWorkers(ctx, "restore", restoreController, s.restoreWorkers)
 		wg.Done()
 	}()
 
@@ -611,13 +1245,35 @@ func (s *server) runControllers(config *api.Config) error {
 	)
 	wg.Add(1)
 	go func() {
-		downloadRequestController.Run(ctx, 1)
+		s.health.runController(ctx, "downloadRequest", downloadRequestController)
+		wg.Done()
+	}()
+
+	serverStatusRequestController := controller.NewServerStatusRequestController(
+		s.logger,
+		s.arkClient.ArkV1(),
+		s.sharedInformerFactory.Ark().V1().ServerStatusRequests(),
+		operationTracker,
+	)
+	wg.Add(1)
+	go func() {
+		s.health.runController(ctx, "serverStatusRequest", serverStatusRequestController)
 		wg.Done()
 	}()
 
+	s.health.setControllersStarted()
+
 	// SHARED INFORMERS HAVE TO BE STARTED AFTER ALL CONTROLLERS
 	go s.sharedInformerFactory.Start(ctx.Done())
 
+	go func() {
+		synced := make(map[string]bool)
+		for informerType, ok := range s.sharedInformerFactory.WaitForCacheSync(ctx.Done()) {
+			synced[informerType.String()] = ok
+		}
+		s.health.setCachesSynced(synced)
+	}()
+
 	// Remove this sometime after v0.8.0
 	cache.WaitForCacheSync(ctx.Done(), s.sharedInformerFactory.Ark().V1().Backups().Informer().HasSynced)
 	s.removeDeprecatedGCFinalizer()
@@ -632,6 +1288,27 @@ func (s *server) runControllers(config *api.Config) error {
 	return nil
 }
 
+// auditLogFlushPeriod is how often the audit log's entries are uploaded to object storage.
+const auditLogFlushPeriod = time.Minute
+
+// newAuditLog returns the audit log that the controllers record destructive operations in. Its
+// entries are uploaded to bucket until ctx is done, and are also recorded as Kubernetes events
+// if s.auditEvents is set. wg is done once the last entries have been uploaded.
+func (s *server) newAuditLog(ctx context.Context, bucket string, wg *sync.WaitGroup) audit.Log {
+	objectStoreLog := audit.NewObjectStoreLog(s.backupService, bucket, s.logger)
+	wg.Add(1)
+	go func() {
+		objectStoreLog.Run(ctx, auditLogFlushPeriod)
+		wg.Done()
+	}()
+
+	if !s.auditEvents {
+		return audit.NewMultiLog(objectStoreLog)
+	}
+
+	return audit.NewMultiLog(objectStoreLog, audit.NewEventLog(s.kubeClient.CoreV1(), s.namespace, s.logger))
+}
+
 const gcFinalizer = "gc.ark.heptio.com"
 
 func (s *server) removeDeprecatedGCFinalizer() {
@@ -671,40 +1348,136 @@ func (s *server) removeDeprecatedGCFinalizer() {
 	}
 }
 
+// newItemDynamicFactory returns a DynamicFactory for getting, listing and creating the items
+// being backed up or restored, whose requests time out and are retried according to the config.
+// Its clients are cached, so it should be shared by everything using the same clientPool.
+func newItemDynamicFactory(clientPool dynamic.ClientPool, config *api.Config) (client.DynamicFactory, error) {
+	return client.NewCachingDynamicFactory(
+		client.NewRetryingDynamicFactory(
+			client.NewDynamicFactory(clientPool),
+			config.ClientRequestTimeout.Duration,
+			config.ClientRequestRetries,
+		),
+		dynamicClientCacheSize,
+	)
+}
+
 func newBackupper(
 	discoveryHelper arkdiscovery.Helper,
-	clientPool dynamic.ClientPool,
+	dynamicFactory client.DynamicFactory,
 	backupService cloudprovider.BackupService,
 	snapshotService cloudprovider.SnapshotService,
 	kubeClientConfig *rest.Config,
 	kubeCoreV1Client kcorev1client.CoreV1Interface,
+	additionalClusters []*backup.AdditionalCluster,
+	cohabitatingResources []api.CohabitatingResource,
+	sanitizers []api.BackupSanitizer,
 ) (backup.Backupper, error) {
 	return backup.NewKubernetesBackupper(
 		discoveryHelper,
-		client.NewDynamicFactory(clientPool),
+		dynamicFactory,
 		backup.NewPodCommandExecutor(kubeClientConfig, kubeCoreV1Client.RESTClient()),
 		snapshotService,
+		additionalClusters,
+		cohabitatingResources,
+		sanitizers,
 	)
 }
 
+// newAdditionalClusters returns the additional clusters in the config. Each one is connected
+// to, using the kubeconfig stored in its secret, when it's first backed up, and its discovery
+// information is kept up to date from then until the server shuts down. A cluster that can't be
+// connected to is retried for each backup, and recorded as an error on the backups that miss it.
+func (s *server) newAdditionalClusters(config *api.Config) []*backup.AdditionalCluster {
+	var clusters []*backup.AdditionalCluster
+
+	for _, additionalCluster := range config.AdditionalClusters {
+		additionalCluster := additionalCluster
+		log := s.logger.WithField("cluster", additionalCluster.Name)
+
+		clusters = append(clusters, backup.NewAdditionalCluster(additionalCluster.Name, func() (backup.Cluster, error) {
+			return s.newAdditionalCluster(config, additionalCluster, log)
+		}))
+		log.Info("Backups will include resources from additional cluster")
+	}
+
+	return clusters
+}
+
+// newAdditionalCluster connects to an additional cluster using the kubeconfig stored in its
+// secret.
+func (s *server) newAdditionalCluster(config *api.Config, additionalCluster api.AdditionalCluster, log logrus.FieldLogger) (backup.Cluster, error) {
+	secret, err := s.kubeClient.CoreV1().Secrets(s.namespace).Get(additionalCluster.KubeconfigSecret, metav1.GetOptions{})
+	if err != nil {
+		return backup.Cluster{}, errors.Wrapf(err, "error getting kubeconfig secret for cluster %s", additionalCluster.Name)
+	}
+
+	kubeconfig, ok := secret.Data[api.AdditionalClusterKubeconfigKey]
+	if !ok {
+		return backup.Cluster{}, errors.Errorf("kubeconfig secret for cluster %s does not have a %q key", additionalCluster.Name, api.AdditionalClusterKubeconfigKey)
+	}
+
+	clientConfig, err := client.ConfigFromKubeconfig(kubeconfig, additionalCluster.Context, s.baseName)
+	if err != nil {
+		return backup.Cluster{}, errors.Wrapf(err, "error loading kubeconfig for cluster %s", additionalCluster.Name)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return backup.Cluster{}, errors.WithStack(err)
+	}
+
+	discoveryHelper, err := arkdiscovery.NewHelper(kubeClient.Discovery(), log)
+	if err != nil {
+		return backup.Cluster{}, errors.Wrapf(err, "error getting discovery information for cluster %s", additionalCluster.Name)
+	}
+
+	dynamicFactory, err := newItemDynamicFactory(dynamic.NewDynamicClientPool(clientConfig), config)
+	if err != nil {
+		return backup.Cluster{}, err
+	}
+
+	go wait.Until(
+		func() {
+			if err := discoveryHelper.Refresh(); err != nil {
+				log.WithError(err).Error("Error refreshing discovery")
+			}
+		},
+		config.DiscoveryRefreshPeriod.Duration,
+		s.ctx.Done(),
+	)
+
+	return backup.NewCluster(
+		additionalCluster.Name,
+		discoveryHelper,
+		dynamicFactory,
+		backup.NewPodCommandExecutor(clientConfig, kubeClient.CoreV1().RESTClient()),
+	), nil
+}
+
 func newRestorer(
 	discoveryHelper arkdiscovery.Helper,
-	clientPool dynamic.ClientPool,
+	dynamicFactory client.DynamicFactory,
 	backupService cloudprovider.BackupService,
 	snapshotService cloudprovider.SnapshotService,
 	resourcePriorities []string,
+	cohabitatingResources []api.CohabitatingResource,
 	backupClient arkv1client.BackupsGetter,
 	kubeClient kubernetes.Interface,
+	auditLog audit.Log,
 	logger logrus.FieldLogger,
 ) (restore.Restorer, error) {
 	return restore.NewKubernetesRestorer(
 		discoveryHelper,
-		client.NewDynamicFactory(clientPool),
+		dynamicFactory,
 		backupService,
 		snapshotService,
 		resourcePriorities,
+		cohabitatingResources,
 		backupClient,
 		kubeClient.CoreV1().Namespaces(),
+		kubeClient.CoreV1(),
+		auditLog,
 		logger,
 	)
 }
@@ -17,12 +17,26 @@ limitations under the License.
 package server
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1api "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/backup"
+	"github.com/heptio/ark/pkg/client"
+	clientset "github.com/heptio/ark/pkg/generated/clientset/versioned"
 	arktest "github.com/heptio/ark/pkg/util/test"
 )
 
@@ -37,17 +51,383 @@ func TestApplyConfigDefaults(t *testing.T) {
 	assert.Equal(t, defaultGCSyncPeriod, c.GCSyncPeriod.Duration)
 	assert.Equal(t, defaultBackupSyncPeriod, c.BackupSyncPeriod.Duration)
 	assert.Equal(t, defaultScheduleSyncPeriod, c.ScheduleSyncPeriod.Duration)
+	assert.Equal(t, defaultDiscoveryRefreshPeriod, c.DiscoveryRefreshPeriod.Duration)
+	assert.Equal(t, defaultClientRequestTimeout, c.ClientRequestTimeout.Duration)
+	assert.Equal(t, defaultClientRequestRetries, c.ClientRequestRetries)
+	assert.Equal(t, defaultBackupTTL, c.DefaultBackupTTL.Duration)
+	assert.Equal(t, backup.DefaultMaxAdditionalItemDepth, c.MaxAdditionalItemDepth)
 	assert.Equal(t, defaultResourcePriorities, c.ResourcePriorities)
+	assert.Equal(t, v1.APIVersionCheckActionWarn, c.RestoreAPIVersionCheck)
+	assert.Equal(t, v1.OrphanedBackupActionLabel, c.OrphanedBackupAction)
+	assert.Equal(t, v1.LargeItemActionWarn, c.LargeItemAction)
 
 	// make sure defaulting doesn't overwrite real values
 	c.GCSyncPeriod.Duration = 5 * time.Minute
 	c.BackupSyncPeriod.Duration = 4 * time.Minute
 	c.ScheduleSyncPeriod.Duration = 3 * time.Minute
+	c.DiscoveryRefreshPeriod.Duration = 2 * time.Minute
 	c.ResourcePriorities = []string{"a", "b"}
+	c.DefaultBackupTTL.Duration = -time.Second
 
 	applyConfigDefaults(c, logger)
 	assert.Equal(t, 5*time.Minute, c.GCSyncPeriod.Duration)
 	assert.Equal(t, 4*time.Minute, c.BackupSyncPeriod.Duration)
 	assert.Equal(t, 3*time.Minute, c.ScheduleSyncPeriod.Duration)
+	assert.Equal(t, 2*time.Minute, c.DiscoveryRefreshPeriod.Duration)
 	assert.Equal(t, []string{"a", "b"}, c.ResourcePriorities)
+	assert.Equal(t, -time.Second, c.DefaultBackupTTL.Duration)
+}
+
+func TestValidateConfigAdditionalClusters(t *testing.T) {
+	tests := []struct {
+		name          string
+		clusters      []v1.AdditionalCluster
+		expectedError string
+	}{
+		{
+			name:     "no additional clusters is valid",
+			clusters: nil,
+		},
+		{
+			name: "uniquely-named clusters with secrets are valid",
+			clusters: []v1.AdditionalCluster{
+				{Name: "east", KubeconfigSecret: "east-kubeconfig"},
+				{Name: "west", KubeconfigSecret: "west-kubeconfig", Context: "admin"},
+			},
+		},
+		{
+			name:          "empty name is invalid",
+			clusters:      []v1.AdditionalCluster{{KubeconfigSecret: "kubeconfig"}},
+			expectedError: `invalid additionalClusters name ""`,
+		},
+		{
+			name:          "name containing a path separator is invalid",
+			clusters:      []v1.AdditionalCluster{{Name: "../east", KubeconfigSecret: "kubeconfig"}},
+			expectedError: `invalid additionalClusters name "../east"`,
+		},
+		{
+			name: "duplicate names are invalid",
+			clusters: []v1.AdditionalCluster{
+				{Name: "east", KubeconfigSecret: "a"},
+				{Name: "east", KubeconfigSecret: "b"},
+			},
+			expectedError: `additionalClusters name "east" is used more than once`,
+		},
+		{
+			name:          "missing secret is invalid",
+			clusters:      []v1.AdditionalCluster{{Name: "east"}},
+			expectedError: `additionalClusters entry "east" must specify a kubeconfigSecret`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &v1.Config{
+				RestoreAPIVersionCheck: v1.APIVersionCheckActionWarn,
+				OrphanedBackupAction:   v1.OrphanedBackupActionLabel,
+				LargeItemAction:        v1.LargeItemActionWarn,
+				AdditionalClusters:     test.clusters,
+			}
+
+			err := validateConfig(c)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestValidateConfigCohabitatingResources(t *testing.T) {
+	c := &v1.Config{
+		RestoreAPIVersionCheck: v1.APIVersionCheckActionWarn,
+		OrphanedBackupAction:   v1.OrphanedBackupActionLabel,
+		LargeItemAction:        v1.LargeItemActionWarn,
+	}
+
+	c.CohabitatingResources = []v1.CohabitatingResource{{Resource: "widgets", Groups: []string{"", "example.com"}}}
+	assert.NoError(t, validateConfig(c))
+
+	c.CohabitatingResources = []v1.CohabitatingResource{{Groups: []string{"", "example.com"}}}
+	assert.EqualError(t, validateConfig(c), "cohabitatingResources entries must specify a resource")
+
+	c.CohabitatingResources = []v1.CohabitatingResource{{Resource: "widgets", Groups: []string{"example.com", "example.com"}}}
+	assert.EqualError(t, validateConfig(c), `cohabitatingResources entry "widgets" must specify at least two different groups`)
+}
+
+func TestValidateConfigBackupSanitizers(t *testing.T) {
+	c := &v1.Config{
+		RestoreAPIVersionCheck: v1.APIVersionCheckActionWarn,
+		OrphanedBackupAction:   v1.OrphanedBackupActionLabel,
+		LargeItemAction:        v1.LargeItemActionWarn,
+	}
+
+	c.BackupSanitizers = []v1.BackupSanitizer{
+		{Resources: []string{"secrets"}, FieldSelector: "type=kubernetes.io/tls", RemoveFields: []string{"data"}},
+		{RemoveAnnotations: []string{"kubectl.kubernetes.io/*"}},
+	}
+	assert.NoError(t, validateConfig(c))
+
+	c.BackupSanitizers = []v1.BackupSanitizer{{Resources: []string{"secrets"}}}
+	assert.EqualError(t, validateConfig(c), "backupSanitizers entry 0 must specify removeFields or removeAnnotations")
+
+	c.BackupSanitizers = []v1.BackupSanitizer{{FieldSelector: "type=a=b", RemoveFields: []string{"data"}}}
+	assert.EqualError(t, validateConfig(c), `invalid backupSanitizers entry 0 fieldSelector: invalid field selector: unescaped character in value: 61`)
+
+	c.BackupSanitizers = []v1.BackupSanitizer{{RemoveFields: []string{"spec..data"}}}
+	assert.EqualError(t, validateConfig(c), `backupSanitizers entry 0 has invalid removeFields path "spec..data"`)
+
+	c.BackupSanitizers = []v1.BackupSanitizer{{RemoveFields: []string{"metadata.name"}}}
+	assert.EqualError(t, validateConfig(c), "backupSanitizers entry 0 can't remove metadata.name")
+
+	c.BackupSanitizers = []v1.BackupSanitizer{{RemoveAnnotations: []string{""}}}
+	assert.EqualError(t, validateConfig(c), `backupSanitizers entry 0 has invalid removeAnnotations key ""`)
+}
+
+func TestValidateConfigPathTemplate(t *testing.T) {
+	c := &v1.Config{
+		RestoreAPIVersionCheck: v1.APIVersionCheckActionWarn,
+		OrphanedBackupAction:   v1.OrphanedBackupActionLabel,
+		LargeItemAction:        v1.LargeItemActionWarn,
+	}
+
+	c.BackupStorageProvider.PathTemplate = "{cluster}/{backup}"
+	assert.EqualError(t, validateConfig(c), `invalid backupStorageProvider pathTemplate: path template "{cluster}/{backup}" uses {cluster}, but clusterName is not set`)
+
+	c.ClusterName = "prod"
+	assert.NoError(t, validateConfig(c))
+}
+
+func TestValidateConfigOrphanedBackupAction(t *testing.T) {
+	c := &v1.Config{RestoreAPIVersionCheck: v1.APIVersionCheckActionWarn, LargeItemAction: v1.LargeItemActionWarn}
+
+	for _, action := range []v1.OrphanedBackupAction{v1.OrphanedBackupActionIgnore, v1.OrphanedBackupActionLabel, v1.OrphanedBackupActionDelete} {
+		c.OrphanedBackupAction = action
+		assert.NoError(t, validateConfig(c))
+	}
+
+	c.OrphanedBackupAction = "Archive"
+	assert.EqualError(t, validateConfig(c), `invalid orphanedBackupAction "Archive"`)
+}
+
+func TestValidateConfigInterruptedBackupRetries(t *testing.T) {
+	c := &v1.Config{
+		RestoreAPIVersionCheck: v1.APIVersionCheckActionWarn,
+		OrphanedBackupAction:   v1.OrphanedBackupActionLabel,
+		LargeItemAction:        v1.LargeItemActionWarn,
+	}
+
+	c.InterruptedBackupRetries = 2
+	assert.NoError(t, validateConfig(c))
+
+	c.InterruptedBackupRetries = -1
+	assert.EqualError(t, validateConfig(c), `invalid interruptedBackupRetries -1`)
+}
+
+func TestValidateConfigLargeItems(t *testing.T) {
+	c := &v1.Config{
+		RestoreAPIVersionCheck: v1.APIVersionCheckActionWarn,
+		OrphanedBackupAction:   v1.OrphanedBackupActionLabel,
+		MaxItemSizeBytes:       1024 * 1024,
+	}
+
+	for _, action := range []v1.LargeItemAction{v1.LargeItemActionWarn, v1.LargeItemActionSkip, v1.LargeItemActionOffload} {
+		c.LargeItemAction = action
+		assert.NoError(t, validateConfig(c))
+	}
+
+	c.LargeItemAction = "Compress"
+	assert.EqualError(t, validateConfig(c), `invalid largeItemAction "Compress"`)
+
+	c.LargeItemAction = v1.LargeItemActionWarn
+	c.MaxItemSizeBytes = -1
+	assert.EqualError(t, validateConfig(c), `invalid maxItemSizeBytes -1`)
+
+	c.MaxItemSizeBytes = 0
+	c.MaxAdditionalItemDepth = -1
+	assert.EqualError(t, validateConfig(c), `invalid maxAdditionalItemDepth -1`)
+}
+
+func TestValidateConfigLogSizes(t *testing.T) {
+	c := &v1.Config{
+		RestoreAPIVersionCheck: v1.APIVersionCheckActionWarn,
+		OrphanedBackupAction:   v1.OrphanedBackupActionLabel,
+		LargeItemAction:        v1.LargeItemActionWarn,
+		LogChunkSizeBytes:      10 * 1024 * 1024,
+		MaxLogSizeBytes:        100 * 1024 * 1024,
+	}
+	assert.NoError(t, validateConfig(c))
+
+	c.LogChunkSizeBytes = -1
+	assert.EqualError(t, validateConfig(c), `invalid logChunkSizeBytes -1`)
+
+	c.LogChunkSizeBytes = 0
+	c.MaxLogSizeBytes = -1
+	assert.EqualError(t, validateConfig(c), `invalid maxLogSizeBytes -1`)
+}
+
+func TestValidateConfigClientSettings(t *testing.T) {
+	c := &v1.Config{
+		RestoreAPIVersionCheck: v1.APIVersionCheckActionWarn,
+		OrphanedBackupAction:   v1.OrphanedBackupActionLabel,
+		LargeItemAction:        v1.LargeItemActionWarn,
+		ClientQPS:              20,
+		ClientBurst:            30,
+	}
+	assert.NoError(t, validateConfig(c))
+
+	c.ClientQPS = -1
+	assert.EqualError(t, validateConfig(c), `invalid clientQPS -1`)
+
+	c.ClientQPS = 20
+	c.ClientBurst = -1
+	assert.EqualError(t, validateConfig(c), `invalid clientBurst -1`)
+
+	c.ClientBurst = 30
+	c.ClientRequestTimeout.Duration = -time.Second
+	assert.EqualError(t, validateConfig(c), `invalid clientRequestTimeout -1s`)
+
+	c.ClientRequestTimeout.Duration = time.Minute
+	c.ClientRequestRetries = -1
+	assert.EqualError(t, validateConfig(c), `invalid clientRequestRetries -1`)
+}
+
+// fakeFactory is a client.Factory whose clients are created for a fixed host with the
+// QPS and burst last set on it. Only the methods used by the server's client setup
+// are implemented.
+type fakeFactory struct {
+	client.Factory
+	qps   float32
+	burst int
+}
+
+func (f *fakeFactory) SetClientQPS(qps float32) { f.qps = qps }
+func (f *fakeFactory) SetClientBurst(burst int) { f.burst = burst }
+
+func (f *fakeFactory) ClientConfig() (*rest.Config, error) {
+	return &rest.Config{Host: "https://example.com", QPS: f.qps, Burst: f.burst}, nil
+}
+
+func (f *fakeFactory) KubeClient() (kubernetes.Interface, error) {
+	config, _ := f.ClientConfig()
+	return kubernetes.NewForConfig(config)
+}
+
+func (f *fakeFactory) Client() (clientset.Interface, error) {
+	config, _ := f.ClientConfig()
+	return clientset.NewForConfig(config)
+}
+
+func TestApplyClientRateLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		flagQPS       float32
+		flagBurst     int
+		configQPS     float32
+		configBurst   int
+		expectedQPS   float32
+		expectedBurst int
+	}{
+		{
+			name: "nothing set keeps the client-go defaults",
+		},
+		{
+			name:          "config values are used when flags aren't set",
+			configQPS:     20,
+			configBurst:   30,
+			expectedQPS:   20,
+			expectedBurst: 30,
+		},
+		{
+			name:          "flags take precedence over config values",
+			flagQPS:       50,
+			flagBurst:     100,
+			configQPS:     20,
+			configBurst:   30,
+			expectedQPS:   50,
+			expectedBurst: 100,
+		},
+		{
+			name:          "flags and config values are combined",
+			flagQPS:       50,
+			configBurst:   30,
+			expectedQPS:   50,
+			expectedBurst: 30,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := &fakeFactory{qps: test.flagQPS, burst: test.flagBurst}
+			s := &server{
+				factory:     f,
+				clientQPS:   test.flagQPS,
+				clientBurst: test.flagBurst,
+				logger:      arktest.NewLogger(),
+			}
+			require.NoError(t, s.initClients())
+
+			config := &v1.Config{ClientQPS: test.configQPS, ClientBurst: test.configBurst}
+			require.NoError(t, s.applyClientRateLimits(config))
+
+			assert.Equal(t, test.expectedQPS, s.kubeClientConfig.QPS)
+			assert.Equal(t, test.expectedBurst, s.kubeClientConfig.Burst)
+		})
+	}
+}
+
+type fakeSecretClient struct {
+	secrets map[string]*corev1api.Secret
+
+	corev1.SecretInterface
+}
+
+func (c *fakeSecretClient) Get(name string, opts metav1.GetOptions) (*corev1api.Secret, error) {
+	secret, ok := c.secrets[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, name)
+	}
+	return secret, nil
+}
+
+func TestWithCredentialsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	secrets := &fakeSecretClient{secrets: map[string]*corev1api.Secret{
+		"aws-account-2": {Data: map[string][]byte{"cloud": []byte("[default]\naws_access_key_id=key-2\n")}},
+		"no-cloud-key":  {Data: map[string][]byte{"other": []byte("foo")}},
+	}}
+
+	// without a credentials secret, the config is unchanged
+	cloudConfig := v1.CloudProviderConfig{Name: "aws", Config: map[string]string{"region": "us-east-1"}}
+	res, file, err := withCredentialsFile(cloudConfig, secrets, dir)
+	require.NoError(t, err)
+	assert.Equal(t, cloudConfig, res)
+	assert.Empty(t, file)
+
+	cloudConfig.CredentialsSecret = "aws-account-2"
+	res, file, err = withCredentialsFile(cloudConfig, secrets, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", res.Config["region"])
+	assert.Equal(t, file, res.Config["credentialsFile"])
+	assert.NotContains(t, cloudConfig.Config, "credentialsFile", "the original config should not be modified")
+
+	creds, err := ioutil.ReadFile(res.Config["credentialsFile"])
+	require.NoError(t, err)
+	assert.Equal(t, "[default]\naws_access_key_id=key-2\n", string(creds))
+
+	// the server removes the files it wrote when it shuts down
+	s := &server{logger: arktest.NewLogger(), credentialsFiles: []string{file}}
+	s.removeCredentialsFiles()
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+
+	_, _, err = withCredentialsFile(v1.CloudProviderConfig{Name: "aws", CredentialsSecret: "no-cloud-key"}, secrets, dir)
+	assert.EqualError(t, err, `credentials secret for provider aws does not have a "cloud" key`)
+
+	_, _, err = withCredentialsFile(v1.CloudProviderConfig{Name: "aws", CredentialsSecret: "missing"}, secrets, dir)
+	assert.EqualError(t, err, `error getting credentials secret for provider aws: secrets "missing" not found`)
 }
@@ -17,8 +17,11 @@ limitations under the License.
 package downloadrequest
 
 import (
+	"bytes"
 	"compress/gzip"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -30,20 +33,159 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
+	pkgbackup "github.com/heptio/ark/pkg/backup"
+	"github.com/heptio/ark/pkg/backup/largeitem"
+	"github.com/heptio/ark/pkg/backup/shard"
 	arkclientv1 "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
+	"github.com/heptio/ark/pkg/util/logchunk"
 )
 
+// ErrNotFound is returned when the file to be downloaded doesn't exist.
+var ErrNotFound = errors.New("file not found")
+
 func Stream(client arkclientv1.DownloadRequestsGetter, namespace, name string, kind v1.DownloadTargetKind, w io.Writer, timeout time.Duration) error {
+	return StreamTarget(client, namespace, v1.DownloadTarget{Kind: kind, Name: name}, w, timeout)
+}
+
+// StreamBackupContents writes the contents of the named backup, as a gzipped tarball, to w.
+// The contents of a sharded backup are downloaded from each of its shards in turn, and
+// combined into a single tarball. Items that the backup offloaded because of their size are
+// downloaded separately and added to the end of the tarball.
+func StreamBackupContents(client arkclientv1.ArkV1Interface, namespace, name string, w io.Writer, timeout time.Duration) error {
+	backup, err := client.Backups(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := pkgbackup.CheckFormatVersion(backup); err != nil {
+		return err
+	}
+
+	if !largeitem.HasOffloaded(backup) {
+		return streamTarball(client, namespace, backup, w, timeout)
+	}
+
+	indexJSON := new(bytes.Buffer)
+	if err := Stream(client, namespace, name, v1.DownloadTargetKindBackupLargeItemsIndex, indexJSON, timeout); err != nil {
+		return errors.WithMessage(err, "error downloading large items index")
+	}
+
+	index := new(largeitem.Index)
+	if err := json.Unmarshal(indexJSON.Bytes(), index); err != nil {
+		return errors.Wrap(err, "error decoding large items index")
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(streamTarball(client, namespace, backup, pw, timeout))
+	}()
+
+	return largeitem.Merge(w, pr, index.Items, func(item string) (io.ReadCloser, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			target := v1.DownloadTarget{Kind: v1.DownloadTargetKindBackupLargeItem, Name: name, Item: item}
+			pw.CloseWithError(StreamTarget(client, namespace, target, pw, timeout))
+		}()
+		return pr, nil
+	})
+}
+
+// streamTarball writes the backup's tarball, or its shards combined into a single tarball,
+// to w.
+func streamTarball(client arkclientv1.ArkV1Interface, namespace string, backup *v1.Backup, w io.Writer, timeout time.Duration) error {
+	name := backup.Name
+
+	if backup.Spec.ShardBy == "" {
+		return Stream(client, namespace, name, v1.DownloadTargetKindBackupContents, w, timeout)
+	}
+
+	manifestJSON := new(bytes.Buffer)
+	if err := Stream(client, namespace, name, v1.DownloadTargetKindBackupManifest, manifestJSON, timeout); err != nil {
+		return errors.WithMessage(err, "error downloading backup manifest")
+	}
+
+	manifest := new(shard.Manifest)
+	if err := json.Unmarshal(manifestJSON.Bytes(), manifest); err != nil {
+		return errors.Wrap(err, "error decoding backup manifest")
+	}
+
+	return shard.Merge(w, manifest.Shards, func(s shard.Shard) (io.ReadCloser, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			target := v1.DownloadTarget{Kind: v1.DownloadTargetKindBackupShard, Name: name, Shard: s.Name}
+			pw.CloseWithError(StreamTarget(client, namespace, target, pw, timeout))
+		}()
+		return pr, nil
+	})
+}
+
+// StreamLog writes the log of the named backup or restore, decompressed, to w. kind must be
+// BackupLog or RestoreLog. A log that was split into chunks is downloaded one chunk at a
+// time, in the order listed by its index.
+func StreamLog(client arkclientv1.DownloadRequestsGetter, namespace, name string, kind v1.DownloadTargetKind, w io.Writer, timeout time.Duration) error {
+	var indexKind v1.DownloadTargetKind
+	switch kind {
+	case v1.DownloadTargetKindBackupLog:
+		indexKind = v1.DownloadTargetKindBackupLogIndex
+	case v1.DownloadTargetKindRestoreLog:
+		indexKind = v1.DownloadTargetKindRestoreLogIndex
+	default:
+		return errors.Errorf("unsupported log download target kind %q", kind)
+	}
+
+	indexJSON := new(bytes.Buffer)
+	err := Stream(client, namespace, name, indexKind, indexJSON, timeout)
+	if err == ErrNotFound {
+		// logs that weren't split into chunks don't have an index
+		return Stream(client, namespace, name, kind, w, timeout)
+	}
+	if err != nil {
+		return errors.WithMessage(err, "error downloading log index")
+	}
+
+	index := new(logchunk.Index)
+	if err := json.Unmarshal(indexJSON.Bytes(), index); err != nil {
+		return errors.Wrap(err, "error decoding log index")
+	}
+
+	for chunk := 1; chunk <= index.Chunks; chunk++ {
+		target := v1.DownloadTarget{Kind: kind, Name: name, Chunk: chunk}
+		if err := StreamTarget(client, namespace, target, w, timeout); err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("error downloading log chunk %d", chunk))
+		}
+	}
+
+	return nil
+}
+
+// StreamTarget writes the file identified by target to w, decompressing it if it's a
+// gzipped log or results file.
+func StreamTarget(client arkclientv1.DownloadRequestsGetter, namespace string, target v1.DownloadTarget, w io.Writer, timeout time.Duration) error {
+	// a backup's shards, and a log's index and chunks, are requested in quick succession,
+	// so their requests' names include the shard or chunk to keep them unique
+	name := target.Name
+	switch {
+	case target.Shard != "":
+		name += "-" + target.Shard
+	case target.Chunk > 0:
+		name += fmt.Sprintf("-log-%d", target.Chunk)
+	case target.Item != "":
+		item := fnv.New32a()
+		item.Write([]byte(target.Item))
+		name += fmt.Sprintf("-large-item-%x", item.Sum32())
+	case target.Kind == v1.DownloadTargetKindBackupLogIndex || target.Kind == v1.DownloadTargetKindRestoreLogIndex:
+		name += "-log-index"
+	case target.Kind == v1.DownloadTargetKindBackupLargeItemsIndex:
+		name += "-large-items-index"
+	}
+
 	req := &v1.DownloadRequest{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: namespace,
 			Name:      fmt.Sprintf("%s-%s", name, time.Now().Format("20060102150405")),
 		},
 		Spec: v1.DownloadRequestSpec{
-			Target: v1.DownloadTarget{
-				Kind: kind,
-				Name: name,
-			},
+			Target: target,
 		},
 	}
 
@@ -98,7 +240,7 @@ Loop:
 	}
 
 	if req.Status.DownloadURL == "" {
-		return errors.New("file not found")
+		return ErrNotFound
 	}
 
 	httpClient := new(http.Client)
@@ -119,6 +261,10 @@ Loop:
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
@@ -129,7 +275,11 @@ Loop:
 	}
 
 	reader := resp.Body
-	if kind != v1.DownloadTargetKindBackupContents {
+	switch target.Kind {
+	case v1.DownloadTargetKindBackupContents, v1.DownloadTargetKindBackupManifest, v1.DownloadTargetKindBackupShard,
+		v1.DownloadTargetKindBackupLogIndex, v1.DownloadTargetKindRestoreLogIndex,
+		v1.DownloadTargetKindBackupLargeItemsIndex, v1.DownloadTargetKindBackupLargeItem:
+	default:
 		// need to decompress logs
 		gzipReader, err := gzip.NewReader(resp.Body)
 		if err != nil {
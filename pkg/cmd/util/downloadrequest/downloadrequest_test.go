@@ -17,12 +17,16 @@ limitations under the License.
 package downloadrequest
 
 import (
+	"archive/tar"
 	"bytes"
 	"compress/gzip"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -222,3 +226,186 @@ func newDownloadRequest(name string) *downloadRequest {
 		},
 	}
 }
+
+func TestStreamBackupContentsCombinesShards(t *testing.T) {
+	backup := &v1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "backup"},
+		Spec:       v1.BackupSpec{ShardBy: v1.BackupShardByNamespace},
+	}
+	client := fake.NewSimpleClientset(backup)
+
+	shards := map[string][]byte{
+		"cluster":         newTarball(t, "resources/namespaces/cluster/ns-1.json"),
+		"namespaces.ns-1": newTarball(t, "resources/pods/namespaces/ns-1/pod-1.json"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/manifest" {
+			fmt.Fprint(w, `{"shards":[{"name":"cluster"},{"name":"namespaces.ns-1","namespace":"ns-1"}]}`)
+			return
+		}
+		w.Write(shards[strings.TrimPrefix(req.URL.Path, "/shards/")])
+	}))
+	defer server.Close()
+
+	// each request is given the URL of its target as soon as it's watched
+	var (
+		lock    sync.Mutex
+		created *v1.DownloadRequest
+	)
+	client.PrependReactor("create", "downloadrequests", func(action core.Action) (bool, runtime.Object, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		created = action.(core.CreateAction).GetObject().(*v1.DownloadRequest).DeepCopy()
+		switch created.Spec.Target.Kind {
+		case v1.DownloadTargetKindBackupManifest:
+			created.Status.DownloadURL = server.URL + "/manifest"
+		case v1.DownloadTargetKindBackupShard:
+			created.Status.DownloadURL = server.URL + "/shards/" + created.Spec.Target.Shard
+		}
+		return true, created, nil
+	})
+	client.PrependWatchReactor("downloadrequests", func(action core.Action) (bool, watch.Interface, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Modify(created)
+		return true, w, nil
+	})
+
+	output := new(bytes.Buffer)
+	require.NoError(t, StreamBackupContents(client.ArkV1(), "namespace", "backup", output, 30*time.Second))
+
+	gzr, err := gzip.NewReader(output)
+	require.NoError(t, err)
+
+	var paths []string
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		paths = append(paths, header.Name)
+	}
+
+	assert.Equal(t, []string{"resources/namespaces/cluster/ns-1.json", "resources/pods/namespaces/ns-1/pod-1.json"}, paths)
+}
+
+func TestStreamLog(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     v1.DownloadTargetKind
+		objects  map[string]string
+		expected string
+	}{
+		{
+			name: "backup log that wasn't split is downloaded directly",
+			kind: v1.DownloadTargetKindBackupLog,
+			objects: map[string]string{
+				"/BackupLog/0": "line 1\nline 2\n",
+			},
+			expected: "line 1\nline 2\n",
+		},
+		{
+			name: "chunks of a backup log are downloaded in order",
+			kind: v1.DownloadTargetKindBackupLog,
+			objects: map[string]string{
+				"/BackupLogIndex/0": `{"chunks":2}`,
+				"/BackupLog/1":      "line 1\n",
+				"/BackupLog/2":      "line 2\n",
+			},
+			expected: "line 1\nline 2\n",
+		},
+		{
+			name: "chunks of a restore log are downloaded in order",
+			kind: v1.DownloadTargetKindRestoreLog,
+			objects: map[string]string{
+				"/RestoreLogIndex/0": `{"chunks":2}`,
+				"/RestoreLog/1":      "line 1\n",
+				"/RestoreLog/2":      "line 2\n",
+			},
+			expected: "line 1\nline 2\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				contents, ok := test.objects[req.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				if strings.Contains(req.URL.Path, "Index") {
+					fmt.Fprint(w, contents)
+					return
+				}
+
+				gzw := gzip.NewWriter(w)
+				fmt.Fprint(gzw, contents)
+				gzw.Close()
+			}))
+			defer server.Close()
+
+			// each request is given the URL of its target as soon as it's watched
+			var (
+				lock    sync.Mutex
+				created *v1.DownloadRequest
+			)
+			client.PrependReactor("create", "downloadrequests", func(action core.Action) (bool, runtime.Object, error) {
+				lock.Lock()
+				defer lock.Unlock()
+
+				created = action.(core.CreateAction).GetObject().(*v1.DownloadRequest).DeepCopy()
+				created.Status.DownloadURL = fmt.Sprintf("%s/%s/%d", server.URL, created.Spec.Target.Kind, created.Spec.Target.Chunk)
+				return true, created, nil
+			})
+			client.PrependWatchReactor("downloadrequests", func(action core.Action) (bool, watch.Interface, error) {
+				lock.Lock()
+				defer lock.Unlock()
+
+				w := watch.NewFakeWithChanSize(1, false)
+				w.Modify(created)
+				return true, w, nil
+			})
+
+			output := new(bytes.Buffer)
+			require.NoError(t, StreamLog(client.ArkV1(), "namespace", "name", test.kind, output, 30*time.Second))
+			assert.Equal(t, test.expected, output.String())
+		})
+	}
+}
+
+// newTarball returns a gzipped tarball containing an empty file at each of paths.
+func newTarball(t *testing.T, paths ...string) []byte {
+	buf := new(bytes.Buffer)
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+
+	for _, path := range paths {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: path, Typeflag: tar.TypeReg, Mode: 0644}))
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+
+	return buf.Bytes()
+}
+
+func TestStreamBackupContentsRejectsNewerFormatVersions(t *testing.T) {
+	backup := &v1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "backup"},
+		Status:     v1.BackupStatus{Version: 3},
+	}
+	client := fake.NewSimpleClientset(backup)
+
+	err := StreamBackupContents(client.ArkV1(), "namespace", "backup", new(bytes.Buffer), time.Second)
+	assert.EqualError(t, err, "backup backup has format version 3, but this version of Ark can only read format versions up to 2; upgrade Ark to use it")
+	assert.Empty(t, client.Actions()[1:], "no download requests should be created")
+}
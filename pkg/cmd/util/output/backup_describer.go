@@ -18,6 +18,8 @@ package output
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
@@ -25,7 +27,7 @@ import (
 )
 
 // DescribeBackup describes a backup in human-readable format.
-func DescribeBackup(backup *v1.Backup, deleteRequests []v1.DeleteBackupRequest) string {
+func DescribeBackup(backup *v1.Backup, deleteRequests []v1.DeleteBackupRequest, details bool) string {
 	return Describe(func(d *Describer) {
 		d.DescribeMetadata(backup.ObjectMeta)
 
@@ -35,12 +37,24 @@ func DescribeBackup(backup *v1.Backup, deleteRequests []v1.DeleteBackupRequest)
 			phase = v1.BackupPhaseNew
 		}
 		d.Printf("Phase:\t%s\n", phase)
+		if backup.Status.RequestedBy != "" {
+			d.Printf("Requested by (unverified):\t%s\n", backup.Status.RequestedBy)
+		}
+		if backup.Status.OperationID != "" {
+			d.Printf("Operation ID:\t%s\n", backup.Status.OperationID)
+		}
+		if backup.Status.Attempts > 1 {
+			d.Printf("Attempts:\t%d\n", backup.Status.Attempts)
+		}
+		if backup.Spec.DryRun {
+			d.Printf("Dry run:\ttrue (run `ark backup logs %s` to list the items)\n", backup.Name)
+		}
 
 		d.Println()
 		DescribeBackupSpec(d, backup.Spec)
 
 		d.Println()
-		DescribeBackupStatus(d, backup.Status)
+		DescribeBackupStatus(d, backup.Status, details)
 
 		if len(deleteRequests) > 0 {
 			d.Println()
@@ -83,6 +97,7 @@ func DescribeBackupSpec(d *Describer, spec v1.BackupSpec) {
 	d.Printf("\tExcluded:\t%s\n", s)
 
 	d.Printf("\tCluster-scoped:\t%s\n", BoolPointerString(spec.IncludeClusterResources, "excluded", "included", "auto"))
+	d.Printf("\tArk resources:\t%s\n", BoolPointerString(spec.IncludeArkResources, "excluded", "included", "included"))
 
 	d.Println()
 	s = "<none>"
@@ -97,6 +112,36 @@ func DescribeBackupSpec(d *Describer, spec v1.BackupSpec) {
 	d.Println()
 	d.Printf("TTL:\t%s\n", spec.TTL.Duration)
 
+	if spec.MaxItemSizeBytes > 0 {
+		d.Println()
+		d.Printf("Max item size:\t%d bytes (%s larger items)\n", spec.MaxItemSizeBytes, strings.ToLower(string(spec.LargeItemAction)))
+	}
+
+	if spec.ShardBy != "" {
+		d.Println()
+		d.Printf("Shard by:\t%s\n", spec.ShardBy)
+	}
+
+	if spec.FailurePolicy != "" {
+		d.Println()
+		d.Printf("Failure policy:\t%s\n", spec.FailurePolicy)
+	}
+
+	if spec.Quiesce != nil {
+		d.Println()
+		var selector string
+		if spec.Quiesce.LabelSelector != nil {
+			selector = metav1.FormatLabelSelector(spec.Quiesce.LabelSelector)
+		}
+		d.Printf("Quiesce:\t%s\n", selector)
+		if len(spec.Quiesce.IncludedNamespaces) > 0 {
+			d.Printf("\tNamespaces:\t%s\n", strings.Join(spec.Quiesce.IncludedNamespaces, ", "))
+		}
+		if spec.Quiesce.Timeout.Duration > 0 {
+			d.Printf("\tTimeout:\t%s\n", spec.Quiesce.Timeout.Duration)
+		}
+	}
+
 	d.Println()
 	if len(spec.Hooks.Resources) == 0 {
 		d.Printf("Hooks:\t<none>\n")
@@ -157,13 +202,23 @@ func DescribeBackupSpec(d *Describer, spec v1.BackupSpec) {
 
 }
 
-// DescribeBackupStatus describes a backup status in human-readable format.
-func DescribeBackupStatus(d *Describer, status v1.BackupStatus) {
+// DescribeBackupStatus describes a backup status in human-readable format. If details is true,
+// each of the backup's volume snapshots is listed.
+func DescribeBackupStatus(d *Describer, status v1.BackupStatus, details bool) {
 	d.Printf("Backup Format Version:\t%d\n", status.Version)
 
 	d.Println()
 	d.Printf("Expiration:\t%s\n", status.Expiration.Time)
 
+	if status.ClusterInfo != nil {
+		d.Println()
+		d.Printf("Cluster:\n")
+		d.Printf("\tName:\t%s\n", valueOrNone(status.ClusterInfo.Name))
+		d.Printf("\tKubernetes Version:\t%s\n", valueOrNone(status.ClusterInfo.KubernetesVersion))
+		d.Printf("\tArk Version:\t%s\n", valueOrNone(status.ClusterInfo.ArkVersion))
+		d.Printf("\tAPI Group Versions:\t%d\n", len(status.ClusterInfo.APIResources))
+	}
+
 	d.Println()
 	d.Printf("Validation errors:")
 	if len(status.ValidationErrors) == 0 {
@@ -174,6 +229,73 @@ func DescribeBackupStatus(d *Describer, status v1.BackupStatus) {
 		}
 	}
 
+	d.Println()
+	d.Printf("Warnings:\t%s\n", countOrNone(status.Warnings))
+	d.Printf("Errors:\t%s\n", countOrNone(status.Errors))
+
+	if len(status.ItemCounts) > 0 {
+		var resources []string
+		total := 0
+		for resource, count := range status.ItemCounts {
+			resources = append(resources, resource)
+			total += count
+		}
+		sort.Strings(resources)
+
+		d.Println()
+		d.Printf("Items:\t%d\n", total)
+		for _, resource := range resources {
+			d.Printf("\t%s:\t%d\n", resource, status.ItemCounts[resource])
+		}
+	}
+
+	if len(status.QuiescedWorkloads) > 0 {
+		d.Println()
+		d.Printf("Quiesced workloads:\n")
+		for _, workload := range status.QuiescedWorkloads {
+			d.Printf("\t%s %s/%s: %d replicas\n", workload.Resource, workload.Namespace, workload.Name, workload.Replicas)
+		}
+	}
+
+	if len(status.LargeItems) > 0 {
+		// backups taken before LargeItemCount was added only list their large items
+		count := status.LargeItemCount
+		if count < len(status.LargeItems) {
+			count = len(status.LargeItems)
+		}
+
+		d.Println()
+		d.Printf("Large items:\t%d\n", count)
+		for _, item := range status.LargeItems {
+			outcome := "backed up"
+			switch {
+			case item.Skipped:
+				outcome = "skipped"
+			case item.Offloaded:
+				outcome = "offloaded"
+			}
+			d.Printf("\t%s %s/%s: %d bytes (%s)\n", item.Resource, item.Namespace, item.Name, item.SizeBytes, outcome)
+		}
+		if omitted := count - len(status.LargeItems); omitted > 0 {
+			d.Printf("\t(%d more not listed)\n", omitted)
+		}
+	}
+
+	if status.HookStatus != nil {
+		d.Println()
+		d.Printf("Hooks:\t%d attempted, %d failed\n", status.HookStatus.HooksAttempted, status.HookStatus.HooksFailed)
+		for _, execution := range status.HookStatus.Executions {
+			if execution.Outcome == v1.HookOutcomeSucceeded {
+				continue
+			}
+			d.Printf("\t%s (%s) in %s/%s", execution.Name, execution.Phase, execution.Namespace, execution.Pod)
+			if execution.Container != "" {
+				d.Printf(" [%s]", execution.Container)
+			}
+			d.Printf(":\t%s after %s (onError=%s): %s\n", execution.Outcome, execution.Duration.Duration, execution.OnError, execution.Error)
+		}
+	}
+
 	d.Println()
 	if len(status.VolumeBackups) == 0 {
 		d.Printf("Persistent Volumes: <none included>\n")
@@ -189,10 +311,44 @@ func DescribeBackupStatus(d *Describer, status v1.BackupStatus) {
 				iops = fmt.Sprintf("%d", *info.Iops)
 			}
 			d.Printf("\t\tIOPS:\t%s\n", iops)
+			phase := info.Phase
+			if phase == "" {
+				phase = v1.SnapshotPhaseCompleted
+			}
+			d.Printf("\t\tPhase:\t%s\n", phase)
+			if info.CopySnapshotID != "" {
+				d.Printf("\t\tCopy (%s):\t%s (%s)\n", info.CopyRegion, info.CopySnapshotID, info.CopyPhase)
+			}
+		}
+	}
+
+	if len(status.VolumeSnapshots) > 0 {
+		d.Println()
+		if !details {
+			d.Printf("Volume Snapshots:\t%d (specify --details for more information)\n", len(status.VolumeSnapshots))
+		} else {
+			DescribeVolumeSnapshots(d, status.VolumeSnapshots)
 		}
 	}
 }
 
+// DescribeVolumeSnapshots describes a backup's volume snapshots in human-readable format.
+func DescribeVolumeSnapshots(d *Describer, snapshots []v1.VolumeSnapshotStatus) {
+	d.Printf("Volume Snapshots:\n")
+	for _, snapshot := range snapshots {
+		d.Printf("\t%s:\n", snapshot.PersistentVolume)
+
+		claim := "<none>"
+		if snapshot.PersistentVolumeClaim != "" {
+			claim = snapshot.Namespace + "/" + snapshot.PersistentVolumeClaim
+		}
+		d.Printf("\t\tClaim:\t%s\n", claim)
+		d.Printf("\t\tSnapshot ID:\t%s\n", snapshot.SnapshotID)
+		d.Printf("\t\tSize:\t%s\n", valueOrNone(snapshot.Size))
+		d.Printf("\t\tPhase:\t%s\n", snapshot.Phase)
+	}
+}
+
 // DescribeDeleteBackupRequests describes delete backup requests in human-readable format.
 func DescribeDeleteBackupRequests(d *Describer, requests []v1.DeleteBackupRequest) {
 	d.Printf("Deletion Attempts")
@@ -216,6 +372,22 @@ func DescribeDeleteBackupRequests(d *Describer, requests []v1.DeleteBackupReques
 				d.Printf("\t\t%s\n", err)
 			}
 		}
+		if len(req.Status.Artifacts) > 0 {
+			d.Printf("\tArtifacts:\n")
+			DescribeArtifactDeletionResults(d, "\t\t", req.Status.Artifacts)
+		}
+	}
+}
+
+// DescribeArtifactDeletionResults describes what happened to each of a backup's artifacts when it
+// was deleted, one per line, each starting with indent.
+func DescribeArtifactDeletionResults(d *Describer, indent string, results []v1.ArtifactDeletionResult) {
+	for _, result := range results {
+		d.Printf("%s%s %s:\t%s", indent, result.Artifact, result.Name, result.Phase)
+		if result.Error != "" {
+			d.Printf(" (%s)", result.Error)
+		}
+		d.Println()
 	}
 }
 
@@ -228,3 +400,18 @@ func failedDeletionCount(requests []v1.DeleteBackupRequest) int {
 	}
 	return count
 }
+
+// valueOrNone returns s, or "<none>" if s is empty.
+func valueOrNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+func countOrNone(n int) string {
+	if n == 0 {
+		return "<none>"
+	}
+	return strconv.Itoa(n)
+}
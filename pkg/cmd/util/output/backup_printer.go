@@ -32,6 +32,9 @@ import (
 
 var (
 	backupColumns = []string{"NAME", "STATUS", "CREATED", "EXPIRES", "SELECTOR"}
+
+	// backupWideColumns are added to backupColumns for wide output.
+	backupWideColumns = []string{"REQUESTED BY"}
 )
 
 func printBackupList(list *v1.BackupList, w io.Writer, options printers.PrintOptions) error {
@@ -96,6 +99,12 @@ func printBackup(backup *v1.Backup, w io.Writer, options printers.PrintOptions)
 		return err
 	}
 
+	if options.Wide {
+		if _, err := fmt.Fprintf(w, "\t%s", requestedBy(backup.Status.RequestedBy)); err != nil {
+			return err
+		}
+	}
+
 	if _, err := fmt.Fprint(w, printers.AppendLabels(backup.Labels, options.ColumnLabels)); err != nil {
 		return err
 	}
@@ -104,6 +113,14 @@ func printBackup(backup *v1.Backup, w io.Writer, options printers.PrintOptions)
 	return err
 }
 
+// requestedBy returns who requested a backup or restore for printing.
+func requestedBy(name string) string {
+	if name == "" {
+		return "n/a"
+	}
+	return name
+}
+
 func humanReadableTimeFromNow(when time.Time) string {
 	if when.IsZero() {
 		return "n/a"
@@ -17,11 +17,15 @@ limitations under the License.
 package output
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/printers"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
 )
@@ -87,3 +91,23 @@ func TestSortBackups(t *testing.T) {
 		})
 	}
 }
+
+func TestPrintBackupWide(t *testing.T) {
+	backup := &v1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-1"},
+		Status:     v1.BackupStatus{Phase: v1.BackupPhaseCompleted, RequestedBy: "user-1"},
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, printBackup(backup, buf, printers.PrintOptions{}))
+	assert.NotContains(t, buf.String(), "user-1")
+
+	buf.Reset()
+	require.NoError(t, printBackup(backup, buf, printers.PrintOptions{Wide: true}))
+	assert.True(t, strings.HasSuffix(buf.String(), "\tuser-1\n"), "unexpected output %q", buf.String())
+
+	backup.Status.RequestedBy = ""
+	buf.Reset()
+	require.NoError(t, printBackup(backup, buf, printers.PrintOptions{Wide: true}))
+	assert.True(t, strings.HasSuffix(buf.String(), "\tn/a\n"), "unexpected output %q", buf.String())
+}
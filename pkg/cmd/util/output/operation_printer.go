@@ -0,0 +1,48 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"k8s.io/kubernetes/pkg/printers"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+var (
+	operationColumns = []string{"KIND", "NAME", "STARTED"}
+)
+
+// printServerStatusRequest prints a row for each of the operations in a processed
+// ServerStatusRequest.
+func printServerStatusRequest(req *v1.ServerStatusRequest, w io.Writer, options printers.PrintOptions) error {
+	for _, operation := range req.Status.Operations {
+		if options.WithNamespace {
+			if _, err := fmt.Fprintf(w, "%s\t", operation.Namespace); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", operation.Kind, operation.Name, humanReadableTimeFromNow(operation.StartTimestamp.Time)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/printers"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+func TestPrintServerStatusRequest(t *testing.T) {
+	started := metav1.NewTime(time.Now().Add(-5 * time.Minute))
+
+	req := &v1.ServerStatusRequest{
+		Status: v1.ServerStatusRequestStatus{
+			Phase: v1.ServerStatusRequestPhaseProcessed,
+			Operations: []v1.Operation{
+				{Kind: v1.OperationKindBackup, Namespace: "heptio-ark", Name: "backup-1", StartTimestamp: started},
+				{Kind: v1.OperationKindBackupDeletion, Namespace: "heptio-ark", Name: "backup-2", StartTimestamp: started},
+			},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, printServerStatusRequest(req, buf, printers.PrintOptions{}))
+	assert.Equal(t, "Backup\tbackup-1\t5m ago\nBackupDeletion\tbackup-2\t5m ago\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, printServerStatusRequest(&v1.ServerStatusRequest{}, buf, printers.PrintOptions{}))
+	assert.Empty(t, buf.String())
+}
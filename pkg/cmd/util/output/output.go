@@ -35,7 +35,7 @@ import (
 // BindFlags defines a set of output-specific flags within the provided
 // FlagSet.
 func BindFlags(flags *pflag.FlagSet) {
-	flags.StringP("output", "o", "table", "Output display format. For create commands, display the object but do not send it to the server. Valid formats are 'table', 'json', and 'yaml'.")
+	flags.StringP("output", "o", "table", "Output display format. For create commands, display the object but do not send it to the server. Valid formats are 'table', 'wide', 'json', and 'yaml'.")
 	labelColumns := flag.NewStringArray()
 	flags.Var(&labelColumns, "label-columns", "a comma-separated list of labels to be displayed as columns")
 	flags.Bool("show-labels", false, "show labels in the last column")
@@ -82,9 +82,9 @@ func ValidateFlags(cmd *cobra.Command) error {
 func validateOutputFlag(cmd *cobra.Command) error {
 	output := GetOutputFlagValue(cmd)
 	switch output {
-	case "", "table", "json", "yaml":
+	case "", "table", "wide", "json", "yaml":
 	default:
-		return errors.Errorf("invalid output format %q - valid values are 'table', 'json', and 'yaml'", output)
+		return errors.Errorf("invalid output format %q - valid values are 'table', 'wide', 'json', and 'yaml'", output)
 	}
 	return nil
 }
@@ -98,13 +98,13 @@ func PrintWithFormat(c *cobra.Command, obj runtime.Object) (bool, error) {
 	}
 
 	switch format {
-	case "table":
+	case "table", "wide":
 		return printTable(c, obj)
 	case "json", "yaml":
 		return printEncoded(obj, format)
 	}
 
-	return false, errors.Errorf("unsupported output format %q; valid values are 'table', 'json', and 'yaml'", format)
+	return false, errors.Errorf("unsupported output format %q; valid values are 'table', 'wide', 'json', and 'yaml'", format)
 }
 
 func printEncoded(obj runtime.Object, format string) (bool, error) {
@@ -135,12 +135,13 @@ func printTable(cmd *cobra.Command, obj runtime.Object) (bool, error) {
 		return false, err
 	}
 
-	printer.Handler(backupColumns, nil, printBackup)
-	printer.Handler(backupColumns, nil, printBackupList)
-	printer.Handler(restoreColumns, nil, printRestore)
-	printer.Handler(restoreColumns, nil, printRestoreList)
+	printer.Handler(backupColumns, backupWideColumns, printBackup)
+	printer.Handler(backupColumns, backupWideColumns, printBackupList)
+	printer.Handler(restoreColumns, restoreWideColumns, printRestore)
+	printer.Handler(restoreColumns, restoreWideColumns, printRestoreList)
 	printer.Handler(scheduleColumns, nil, printSchedule)
 	printer.Handler(scheduleColumns, nil, printScheduleList)
+	printer.Handler(operationColumns, nil, printServerStatusRequest)
 
 	err = printer.PrintObj(obj, os.Stdout)
 	if err != nil {
@@ -157,6 +158,7 @@ func NewPrinter(cmd *cobra.Command) (*printers.HumanReadablePrinter, error) {
 		NoHeaders:    flag.GetOptionalBoolFlag(cmd, "no-headers"),
 		ShowLabels:   GetShowLabelsValue(cmd),
 		ColumnLabels: GetLabelColumnsValues(cmd),
+		Wide:         GetOutputFlagValue(cmd) == "wide",
 	}
 
 	printer := printers.NewHumanReadablePrinter(
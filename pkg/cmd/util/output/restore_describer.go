@@ -19,6 +19,7 @@ package output
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
@@ -67,10 +68,36 @@ func DescribeRestore(restore *v1.Restore, arkClient clientset.Interface) string
 		d.Printf("\tExcluded:\t%s\n", s)
 
 		d.Printf("\tCluster-scoped:\t%s\n", BoolPointerString(restore.Spec.IncludeClusterResources, "excluded", "included", "auto"))
+		s = "excluded"
+		if restore.Spec.IncludeArkResources {
+			s = "included"
+		}
+		d.Printf("\tArk resources:\t%s\n", s)
 
 		d.Println()
 		d.DescribeMap("Namespace mappings", restore.Spec.NamespaceMapping)
 
+		d.Println()
+		d.DescribeMap("Availability zone mappings", restore.Spec.AvailabilityZoneMapping)
+
+		d.Println()
+		d.DescribeMap("Image registry mappings", restore.Spec.ImageRegistryMapping)
+
+		if mapping := restore.Spec.ClusterDomainMapping; mapping != nil {
+			d.Println()
+			d.Printf("Cluster domain mapping:\t%s => %s\n", mapping.From, mapping.To)
+			fields := make(map[string]string, len(mapping.ResourceFields))
+			for resource, paths := range mapping.ResourceFields {
+				fields[resource] = strings.Join(paths, ",")
+			}
+			d.DescribeMap("Cluster domain fields", fields)
+		}
+
+		if restore.Spec.ResourceModifierConfigMap != "" {
+			d.Println()
+			d.Printf("Resource modifiers:\t%s\n", restore.Spec.ResourceModifierConfigMap)
+		}
+
 		d.Println()
 		s = "<none>"
 		if restore.Spec.LabelSelector != nil {
@@ -81,8 +108,54 @@ func DescribeRestore(restore *v1.Restore, arkClient clientset.Interface) string
 		d.Println()
 		d.Printf("Restore PVs:\t%s\n", BoolPointerString(restore.Spec.RestorePVs, "false", "true", "auto"))
 
+		d.Println()
+		d.Printf("Preserve node ports:\t%s\n", BoolPointerString(restore.Spec.PreserveNodePorts, "false", "true", "false"))
+
+		d.Println()
+		d.Printf("Skip completed jobs:\t%s\n", BoolPointerString(restore.Spec.SkipCompletedJobs, "false", "true", "true"))
+
+		d.Println()
+		s = "<none>"
+		if restore.Spec.RestoreStatus != nil {
+			s = strings.Join(restore.Spec.RestoreStatus.IncludedResources, ", ")
+			if len(restore.Spec.RestoreStatus.ExcludedResources) > 0 {
+				s += fmt.Sprintf(" (excluding %s)", strings.Join(restore.Spec.RestoreStatus.ExcludedResources, ", "))
+			}
+		}
+		d.Printf("Restore status:\t%s\n", s)
+
+		if restore.Spec.AnnotateOriginalVolumeSpecs {
+			d.Println()
+			d.Printf("Annotate volume specs:\ttrue\n")
+		}
+
+		if restore.Spec.DryRun {
+			d.Println()
+			d.Printf("Dry run:\ttrue\n")
+		}
+
+		if restore.Spec.Verify != nil {
+			d.Println()
+			s = "5m0s (default)"
+			if restore.Spec.Verify.Timeout.Duration > 0 {
+				s = restore.Spec.Verify.Timeout.Duration.String()
+			}
+			d.Printf("Verify timeout:\t%s\n", s)
+		}
+
+		if restore.Spec.Replicas != "" {
+			d.Println()
+			d.Printf("Replicas:\t%s\n", restore.Spec.Replicas)
+		}
+
 		d.Println()
 		d.Printf("Phase:\t%s\n", restore.Status.Phase)
+		if restore.Status.RequestedBy != "" {
+			d.Printf("Requested by (unverified):\t%s\n", restore.Status.RequestedBy)
+		}
+		if restore.Status.OperationID != "" {
+			d.Printf("Operation ID:\t%s\n", restore.Status.OperationID)
+		}
 
 		d.Println()
 		d.Printf("Validation errors:")
@@ -94,11 +167,95 @@ func DescribeRestore(restore *v1.Restore, arkClient clientset.Interface) string
 			}
 		}
 
+		if restore.Spec.Verify != nil && restoreFinished(restore) {
+			d.Println()
+			describeWorkloadReadiness(d, restore.Status.Workloads)
+		}
+
 		d.Println()
 		describeRestoreResults(d, restore, arkClient)
+
+		if restore.Spec.DryRun && restoreFinished(restore) {
+			d.Println()
+			describeRestorePlan(d, restore, arkClient)
+		}
 	})
 }
 
+// restoreFinished returns whether restore has finished executing, whether or not
+// all of its items were restored.
+func restoreFinished(restore *v1.Restore) bool {
+	switch restore.Status.Phase {
+	case v1.RestorePhaseCompleted, v1.RestorePhasePartiallyFailed, v1.RestorePhaseFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+func describeRestorePlan(d *Describer, restore *v1.Restore, arkClient clientset.Interface) {
+	var buf bytes.Buffer
+	var plan []v1.RestorePlanItem
+
+	if err := downloadrequest.Stream(arkClient.ArkV1(), restore.Namespace, restore.Name, v1.DownloadTargetKindRestorePlan, &buf, 30*time.Second); err != nil {
+		d.Printf("Plan:\t<error getting plan: %v>\n", err)
+		return
+	}
+
+	if err := json.NewDecoder(&buf).Decode(&plan); err != nil {
+		d.Printf("Plan:\t<error decoding plan: %v>\n", err)
+		return
+	}
+
+	if len(plan) == 0 {
+		d.Printf("Plan:\t<none>\n")
+		return
+	}
+
+	byAction := make(map[v1.RestorePlanAction][]v1.RestorePlanItem)
+	for _, item := range plan {
+		byAction[item.Action] = append(byAction[item.Action], item)
+	}
+
+	d.Printf("Plan:\n")
+	for _, action := range []v1.RestorePlanAction{v1.RestorePlanActionCreate, v1.RestorePlanActionConflict, v1.RestorePlanActionSkip} {
+		items := byAction[action]
+		if len(items) == 0 {
+			continue
+		}
+
+		d.Printf("\t%s (%d):\n", action, len(items))
+		for _, item := range items {
+			name := item.Name
+			if item.Namespace != "" {
+				name = item.Namespace + "/" + item.Name
+			}
+
+			if item.Reason != "" {
+				d.Printf("\t\t%s %s (%s)\n", item.Resource, name, item.Reason)
+			} else {
+				d.Printf("\t\t%s %s\n", item.Resource, name)
+			}
+		}
+	}
+}
+
+func describeWorkloadReadiness(d *Describer, workloads []v1.WorkloadReadiness) {
+	if len(workloads) == 0 {
+		d.Printf("Workloads:\t<none>\n")
+		return
+	}
+
+	d.Printf("Workloads:\n")
+	for _, w := range workloads {
+		s := "ready"
+		if !w.Ready {
+			s = "not ready"
+		}
+		d.Printf("\t%s %s/%s:\t%s (%d/%d replicas)\n", w.Resource, w.Namespace, w.Name, s, w.ReadyReplicas, w.DesiredReplicas)
+	}
+}
+
 func describeRestoreResults(d *Describer, restore *v1.Restore, arkClient clientset.Interface) {
 	if restore.Status.Warnings == 0 && restore.Status.Errors == 0 {
 		d.Printf("Warnings:\t<none>\nErrors:\t<none>\n")
@@ -28,6 +28,9 @@ import (
 
 var (
 	restoreColumns = []string{"NAME", "BACKUP", "STATUS", "WARNINGS", "ERRORS", "CREATED", "SELECTOR"}
+
+	// restoreWideColumns are added to restoreColumns for wide output.
+	restoreWideColumns = []string{"REQUESTED BY"}
 )
 
 func printRestoreList(list *v1.RestoreList, w io.Writer, options printers.PrintOptions) error {
@@ -67,6 +70,12 @@ func printRestore(restore *v1.Restore, w io.Writer, options printers.PrintOption
 		return err
 	}
 
+	if options.Wide {
+		if _, err := fmt.Fprintf(w, "\t%s", requestedBy(restore.Status.RequestedBy)); err != nil {
+			return err
+		}
+	}
+
 	if _, err := fmt.Fprint(w, printers.AppendLabels(restore.Labels, options.ColumnLabels)); err != nil {
 		return err
 	}
@@ -37,6 +37,28 @@ func DescribeSchedule(schedule *v1.Schedule) string {
 func DescribeScheduleSpec(d *Describer, spec v1.ScheduleSpec) {
 	d.Printf("Schedule:\t%s\n", spec.Schedule)
 
+	timeZone := spec.TimeZone
+	if timeZone == "" {
+		timeZone = "<server>"
+	}
+	d.Printf("Time Zone:\t%s\n", timeZone)
+
+	nameTemplate := spec.NameTemplate
+	if nameTemplate == "" {
+		nameTemplate = "<default>"
+	}
+	d.Printf("Backup Name Template:\t%s\n", nameTemplate)
+
+	concurrencyPolicy := spec.ConcurrencyPolicy
+	if concurrencyPolicy == "" {
+		concurrencyPolicy = v1.ConcurrencyPolicyAllow
+	}
+	d.Printf("Concurrency Policy:\t%s\n", concurrencyPolicy)
+
+	if spec.Jitter.Duration > 0 {
+		d.Printf("Jitter:\t%s\n", spec.Jitter.Duration)
+	}
+
 	d.Println()
 	d.Println("Backup Template:")
 	d.Prefix = "\t"
@@ -65,4 +87,8 @@ func DescribeScheduleStatus(d *Describer, status v1.ScheduleStatus) {
 		lastBackup = fmt.Sprintf("%v", status.LastBackup.Time)
 	}
 	d.Printf("Last Backup:\t%s\n", lastBackup)
+
+	if !status.LastSkipped.Time.IsZero() {
+		d.Printf("Last Skipped:\t%v\n", status.LastSkipped.Time)
+	}
 }
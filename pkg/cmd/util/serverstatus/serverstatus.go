@@ -0,0 +1,88 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serverstatus
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	arkclientv1 "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
+)
+
+// Get creates a ServerStatusRequest in namespace, waits up to timeout for the Ark server to
+// process it, and returns the processed request. The request is deleted before returning.
+func Get(client arkclientv1.ServerStatusRequestsGetter, namespace string, timeout time.Duration) (*v1.ServerStatusRequest, error) {
+	req := &v1.ServerStatusRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    namespace,
+			GenerateName: "ark-cli-",
+		},
+	}
+
+	req, err := client.ServerStatusRequests(namespace).Create(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer client.ServerStatusRequests(namespace).Delete(req.Name, nil)
+
+	listOptions := metav1.ListOptions{
+		// TODO: once the minimum supported Kubernetes version is v1.9.0, uncomment the following line.
+		// See http://issue.k8s.io/51046 for details.
+		//FieldSelector:   "metadata.name=" + req.Name
+		ResourceVersion: req.ResourceVersion,
+	}
+	watcher, err := client.ServerStatusRequests(namespace).Watch(listOptions)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer watcher.Stop()
+
+	expired := time.NewTimer(timeout)
+	defer expired.Stop()
+
+	for {
+		select {
+		case <-expired.C:
+			return nil, errors.New("timed out waiting for the Ark server to respond; is it running?")
+		case e := <-watcher.ResultChan():
+			updated, ok := e.Object.(*v1.ServerStatusRequest)
+			if !ok {
+				return nil, errors.Errorf("unexpected type %T", e.Object)
+			}
+
+			// TODO: once the minimum supported Kubernetes version is v1.9.0, remove the following check.
+			// See http://issue.k8s.io/51046 for details.
+			if updated.Name != req.Name {
+				continue
+			}
+
+			switch e.Type {
+			case watch.Deleted:
+				return nil, errors.New("server status request was unexpectedly deleted")
+			case watch.Modified:
+				if updated.Status.Phase == v1.ServerStatusRequestPhaseProcessed {
+					return updated, nil
+				}
+			}
+		}
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/clock"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -41,7 +42,9 @@ import (
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/backup"
+	"github.com/heptio/ark/pkg/buildinfo"
 	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/discovery"
 	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions/ark/v1"
 	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
@@ -49,10 +52,10 @@ import (
 	"github.com/heptio/ark/pkg/util/collections"
 	"github.com/heptio/ark/pkg/util/encode"
 	kubeutil "github.com/heptio/ark/pkg/util/kube"
+	"github.com/heptio/ark/pkg/util/logging"
+	"github.com/heptio/ark/pkg/util/tracing"
 )
 
-const backupVersion = 1
-
 type backupController struct {
 	backupper        backup.Backupper
 	backupService    cloudprovider.BackupService
@@ -64,9 +67,37 @@ type backupController struct {
 	syncHandler      func(backupName string) error
 	queue            workqueue.RateLimitingInterface
 	clock            clock.Clock
+	newOperationID   func() string
 	logger           logrus.FieldLogger
 	pluginManager    plugin.Manager
-	backupTracker    BackupTracker
+	operationTracker OperationTracker
+
+	snapshotService                     cloudprovider.SnapshotService
+	snapshotSyncPeriod                  time.Duration
+	completeBackupsBeforeSnapshotsReady bool
+	snapshotCopyRegion                  string
+
+	discoveryHelper discovery.Helper
+	clusterName     string
+
+	// interruptedBackupRetries is the number of times a backup that was interrupted
+	// by the server stopping is restarted before it's marked as failed.
+	interruptedBackupRetries int
+
+	// maxItemSizeBytes and largeItemAction are applied to backups that don't
+	// set their own MaxItemSizeBytes.
+	maxItemSizeBytes int64
+	largeItemAction  api.LargeItemAction
+
+	// defaultBackupTTL is applied to backups that don't set their own TTL.
+	defaultBackupTTL time.Duration
+
+	// maxAdditionalItemDepth is applied to backups that don't set their own.
+	maxAdditionalItemDepth int
+
+	// builtInActions are run on every backup, after the actions provided
+	// by plugins.
+	builtInActions []backup.ItemAction
 }
 
 func NewBackupController(
@@ -78,7 +109,19 @@ func NewBackupController(
 	pvProviderExists bool,
 	logger logrus.FieldLogger,
 	pluginManager plugin.Manager,
-	backupTracker BackupTracker,
+	operationTracker OperationTracker,
+	snapshotService cloudprovider.SnapshotService,
+	snapshotSyncPeriod time.Duration,
+	completeBackupsBeforeSnapshotsReady bool,
+	snapshotCopyRegion string,
+	discoveryHelper discovery.Helper,
+	clusterName string,
+	provenanceAnnotations bool,
+	interruptedBackupRetries int,
+	maxItemSizeBytes int64,
+	largeItemAction api.LargeItemAction,
+	defaultBackupTTL time.Duration,
+	maxAdditionalItemDepth int,
 ) Interface {
 	c := &backupController{
 		backupper:        backupper,
@@ -90,9 +133,31 @@ func NewBackupController(
 		client:           client,
 		queue:            workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "backup"),
 		clock:            &clock.RealClock{},
+		newOperationID:   newOperationID,
 		logger:           logger,
 		pluginManager:    pluginManager,
-		backupTracker:    backupTracker,
+		operationTracker: operationTracker,
+
+		snapshotService:                     snapshotService,
+		snapshotSyncPeriod:                  snapshotSyncPeriod,
+		completeBackupsBeforeSnapshotsReady: completeBackupsBeforeSnapshotsReady,
+		snapshotCopyRegion:                  snapshotCopyRegion,
+
+		discoveryHelper: discoveryHelper,
+		clusterName:     clusterName,
+
+		interruptedBackupRetries: interruptedBackupRetries,
+
+		maxItemSizeBytes: maxItemSizeBytes,
+		largeItemAction:  largeItemAction,
+
+		defaultBackupTTL: defaultBackupTTL,
+
+		maxAdditionalItemDepth: maxAdditionalItemDepth,
+	}
+
+	if provenanceAnnotations {
+		c.builtInActions = append(c.builtInActions, backup.NewProvenanceAction(logger))
 	}
 
 	c.syncHandler = c.processBackup
@@ -120,6 +185,22 @@ func NewBackupController(
 				}
 				c.queue.Add(key)
 			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldBackup := oldObj.(*api.Backup)
+				newBackup := newObj.(*api.Backup)
+
+				// interrupted backups are restarted by resetting them to New
+				if oldBackup.Status.Phase != api.BackupPhaseInProgress || newBackup.Status.Phase != api.BackupPhaseNew {
+					return
+				}
+
+				key, err := cache.MetaNamespaceKeyFunc(newBackup)
+				if err != nil {
+					c.logger.WithError(err).WithField("backup", newBackup).Error("Error creating queue key, item not added to queue")
+					return
+				}
+				c.queue.Add(key)
+			},
 		},
 	)
 
@@ -155,6 +236,8 @@ func (controller *backupController) Run(ctx context.Context, numWorkers int) err
 	}
 	controller.logger.Info("Caches are synced")
 
+	controller.handleInterruptedBackups()
+
 	wg.Add(numWorkers)
 	for i := 0; i < numWorkers; i++ {
 		go func() {
@@ -163,6 +246,14 @@ func (controller *backupController) Run(ctx context.Context, numWorkers int) err
 		}()
 	}
 
+	if controller.snapshotService != nil {
+		wg.Add(1)
+		go func() {
+			wait.Until(controller.syncSnapshotPhases, controller.snapshotSyncPeriod, ctx.Done())
+			wg.Done()
+		}()
+	}
+
 	<-ctx.Done()
 
 	return nil
@@ -236,19 +327,50 @@ func (controller *backupController) processBackup(key string) error {
 	// don't modify items in the cache
 	backup = backup.DeepCopy()
 
-	// set backup version
-	backup.Status.Version = backupVersion
+	// record where the backup was taken
+	backup.Status.ClusterInfo = controller.getClusterInfo()
 
-	// calculate expiration
+	// apply the server's large item settings if the backup doesn't have its own
+	if backup.Spec.MaxItemSizeBytes == 0 && controller.maxItemSizeBytes > 0 {
+		backup.Spec.MaxItemSizeBytes = controller.maxItemSizeBytes
+		if backup.Spec.LargeItemAction == "" {
+			backup.Spec.LargeItemAction = controller.largeItemAction
+		}
+	}
+	if backup.Spec.MaxItemSizeBytes > 0 && backup.Spec.LargeItemAction == "" {
+		backup.Spec.LargeItemAction = api.LargeItemActionWarn
+	}
+
+	// apply the server's default TTL if the backup doesn't have its own. A negative default
+	// is disabled, so the backup never expires.
+	if backup.Spec.TTL.Duration == 0 && controller.defaultBackupTTL > 0 {
+		backup.Spec.TTL.Duration = controller.defaultBackupTTL
+	}
+
+	if backup.Spec.MaxAdditionalItemDepth == 0 {
+		backup.Spec.MaxAdditionalItemDepth = controller.maxAdditionalItemDepth
+	}
+
+	// calculate expiration. Backups with a negative TTL never expire.
 	if backup.Spec.TTL.Duration > 0 {
 		backup.Status.Expiration = metav1.NewTime(controller.clock.Now().Add(backup.Spec.TTL.Duration))
 	}
 
+	// record who created the backup
+	backup.Status.RequestedBy = backup.Annotations[api.RequestedByAnnotation]
+
+	// identify the backup in logs. A restarted backup keeps its ID.
+	if backup.Status.OperationID == "" {
+		backup.Status.OperationID = controller.newOperationID()
+	}
+	logContext = logContext.WithField(logging.OperationIDField, backup.Status.OperationID)
+
 	// validation
 	if backup.Status.ValidationErrors = controller.getValidationErrors(backup); len(backup.Status.ValidationErrors) > 0 {
 		backup.Status.Phase = api.BackupPhaseFailedValidation
 	} else {
 		backup.Status.Phase = api.BackupPhaseInProgress
+		backup.Status.Attempts++
 	}
 
 	// update status
@@ -264,8 +386,8 @@ func (controller *backupController) processBackup(key string) error {
 		return nil
 	}
 
-	controller.backupTracker.Add(backup.Namespace, backup.Name)
-	defer controller.backupTracker.Delete(backup.Namespace, backup.Name)
+	controller.operationTracker.Add(api.OperationKindBackup, backup.Namespace, backup.Name)
+	defer controller.operationTracker.Delete(api.OperationKindBackup, backup.Namespace, backup.Name)
 
 	logContext.Debug("Running backup")
 	// execution & upload of backup
@@ -282,6 +404,47 @@ func (controller *backupController) processBackup(key string) error {
 	return nil
 }
 
+// handleInterruptedBackups finds backups that were left InProgress by a server that stopped
+// while running them, and either restarts them, if they haven't used up their retries, or
+// marks them as Failed. Backups that are InProgress only because they're waiting for their
+// volume snapshots to be ready were not interrupted, and are left alone. Workloads that an
+// interrupted backup left scaled down are scaled back up first.
+func (controller *backupController) handleInterruptedBackups() {
+	if err := controller.backupper.UnquiesceInterrupted(controller.logger); err != nil {
+		controller.logger.WithError(err).Error("Error scaling up workloads left scaled down by interrupted backups")
+	}
+
+	backups, err := controller.lister.List(labels.Everything())
+	if err != nil {
+		controller.logger.WithError(errors.WithStack(err)).Error("Error listing backups")
+		return
+	}
+
+	for _, backup := range backups {
+		if backup.Status.Phase != api.BackupPhaseInProgress || hasInProgressSnapshots(backup) {
+			continue
+		}
+
+		log := controller.logger.WithFields(logrus.Fields{
+			"backup":   kubeutil.NamespaceAndName(backup),
+			"attempts": backup.Status.Attempts,
+		})
+
+		updated := backup.DeepCopy()
+		if controller.interruptedBackupRetries > 0 && backup.Status.Attempts <= controller.interruptedBackupRetries {
+			log.Info("Restarting interrupted backup")
+			updated.Status.Phase = api.BackupPhaseNew
+		} else {
+			log.Info("Marking interrupted backup as failed")
+			updated.Status.Phase = api.BackupPhaseFailed
+		}
+
+		if _, err := patchBackup(backup, updated, controller.client); err != nil {
+			log.WithError(err).Error("Error updating interrupted backup's phase")
+		}
+	}
+}
+
 func patchBackup(original, updated *api.Backup, client arkv1client.BackupsGetter) (*api.Backup, error) {
 	origBytes, err := json.Marshal(original)
 	if err != nil {
@@ -317,17 +480,71 @@ func (controller *backupController) getValidationErrors(itm *api.Backup) []strin
 		validationErrors = append(validationErrors, fmt.Sprintf("Invalid included/excluded namespace lists: %v", err))
 	}
 
+	for _, err := range backup.ValidateHooks(itm.Spec.Hooks) {
+		validationErrors = append(validationErrors, fmt.Sprintf("Invalid hook: %v", err))
+	}
+
 	if !controller.pvProviderExists && itm.Spec.SnapshotVolumes != nil && *itm.Spec.SnapshotVolumes {
 		validationErrors = append(validationErrors, "Server is not configured for PV snapshots")
 	}
 
+	if itm.Spec.MaxItemSizeBytes < 0 {
+		validationErrors = append(validationErrors, fmt.Sprintf("Invalid maxItemSizeBytes %d", itm.Spec.MaxItemSizeBytes))
+	}
+
+	if itm.Spec.MaxAdditionalItemDepth < 0 {
+		validationErrors = append(validationErrors, fmt.Sprintf("Invalid maxAdditionalItemDepth %d", itm.Spec.MaxAdditionalItemDepth))
+	}
+
+	switch itm.Spec.LargeItemAction {
+	case "", api.LargeItemActionWarn, api.LargeItemActionSkip, api.LargeItemActionOffload:
+	default:
+		validationErrors = append(validationErrors, fmt.Sprintf("Invalid largeItemAction %q", itm.Spec.LargeItemAction))
+	}
+
+	switch itm.Spec.ShardBy {
+	case "", api.BackupShardByNamespace, api.BackupShardByResourceGroup:
+	default:
+		validationErrors = append(validationErrors, fmt.Sprintf("Invalid shardBy %q", itm.Spec.ShardBy))
+	}
+
+	switch itm.Spec.DeletionPolicy {
+	case "", api.BackupDeletionPolicyAll, api.BackupDeletionPolicySnapshotsOnly, api.BackupDeletionPolicyObjectStorageOnly:
+	default:
+		validationErrors = append(validationErrors, fmt.Sprintf("Invalid deletionPolicy %q", itm.Spec.DeletionPolicy))
+	}
+
+	switch itm.Spec.FailurePolicy {
+	case "", api.BackupFailurePolicyContinue, api.BackupFailurePolicyFailFast:
+	default:
+		validationErrors = append(validationErrors, fmt.Sprintf("Invalid failurePolicy %q", itm.Spec.FailurePolicy))
+	}
+
+	if quiesce := itm.Spec.Quiesce; quiesce != nil {
+		if quiesce.LabelSelector == nil {
+			validationErrors = append(validationErrors, "Invalid quiesce: a label selector must be specified")
+		} else if _, err := metav1.LabelSelectorAsSelector(quiesce.LabelSelector); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("Invalid quiesce label selector: %v", err))
+		}
+		if quiesce.Timeout.Duration < 0 {
+			validationErrors = append(validationErrors, "Invalid quiesce timeout: must not be negative")
+		}
+	}
+
 	return validationErrors
 }
 
 func (controller *backupController) runBackup(backup *api.Backup, bucket string) error {
-	log := controller.logger.WithField("backup", kubeutil.NamespaceAndName(backup))
+	log := controller.logger.WithFields(logrus.Fields{
+		"backup":                 kubeutil.NamespaceAndName(backup),
+		logging.OperationIDField: backup.Status.OperationID,
+	})
 	log.Info("Starting backup")
 
+	span := tracing.StartOperation(backup.Status.OperationID, "backup")
+	span.SetTag("backup", kubeutil.NamespaceAndName(backup))
+	defer span.Finish()
+
 	logFile, err := ioutil.TempFile("", "")
 	if err != nil {
 		return errors.Wrap(err, "error creating temp file for backup log")
@@ -345,6 +562,7 @@ func (controller *backupController) runBackup(backup *api.Backup, bucket string)
 		return err
 	}
 	defer controller.pluginManager.CloseBackupItemActions(backup.Name)
+	actions = append(actions, controller.builtInActions...)
 
 	var errs []error
 
@@ -352,15 +570,32 @@ func (controller *backupController) runBackup(backup *api.Backup, bucket string)
 
 	// Do the actual backup
 	if err := controller.backupper.Backup(backup, backupFile, logFile, actions); err != nil {
-		errs = append(errs, err)
-
-		backup.Status.Phase = api.BackupPhaseFailed
+		if backupFailed(backup, err) {
+			errs = append(errs, err)
+
+			backup.Status.Phase = api.BackupPhaseFailed
+		} else {
+			// only some items or groups failed; everything that could be backed up was, and
+			// is still persisted
+			log.WithError(err).Warn("Backup partially failed")
+
+			backup.Status.Phase = api.BackupPhasePartiallyFailed
+		}
+	} else if hasInProgressSnapshots(backup) && !controller.completeBackupsBeforeSnapshotsReady {
+		// leave the backup InProgress; syncSnapshotPhases will complete it once the cloud
+		// provider has finished processing its snapshots.
+		log.Info("Backup will be completed once its volume snapshots are ready")
 	} else {
 		backup.Status.Phase = api.BackupPhaseCompleted
 	}
 
 	backupJson := new(bytes.Buffer)
-	if err := encode.EncodeTo(backup, "json", backupJson); err != nil {
+	if backup.Spec.DryRun {
+		// a dry run has no data, so only its log, which lists the items it would have
+		// included, is uploaded. Without a metadata file, it isn't synced to other
+		// clusters or restorable.
+		log.Info("Dry-run backup will only upload its log")
+	} else if err := encode.EncodeTo(backup, "json", backupJson); err != nil {
 		errs = append(errs, errors.Wrap(err, "error encoding backup"))
 	} else {
 		// Only upload the json and backup tarball if encoding to json succeeded.
@@ -368,15 +603,49 @@ func (controller *backupController) runBackup(backup *api.Backup, bucket string)
 		backupFileToUpload = backupFile
 	}
 
-	if err := controller.backupService.UploadBackup(bucket, backup.Name, backupJsonToUpload, backupFileToUpload, logFile); err != nil {
+	uploadSpan := span.StartChild("upload")
+	if err := controller.backupService.UploadBackup(bucket, backup, backupJsonToUpload, backupFileToUpload, logFile); err != nil {
 		errs = append(errs, err)
 	}
+	uploadSpan.Finish()
 
 	log.Info("Backup completed")
 
 	return kerrors.NewAggregate(errs)
 }
 
+// backupFailed returns whether err, returned by the backupper, means that itm failed as a whole,
+// either because its failure policy is FailFast or because a cluster couldn't be backed up at all,
+// rather than that only some of its items couldn't be backed up.
+func backupFailed(itm *api.Backup, err error) bool {
+	return itm.Spec.FailurePolicy == api.BackupFailurePolicyFailFast || backup.IsSetupError(err)
+}
+
+// getClusterInfo returns the identity of the cluster the Ark server is running in,
+// including a snapshot of the resources currently served by its discovery API.
+func (controller *backupController) getClusterInfo() *api.ClusterInfo {
+	info := &api.ClusterInfo{
+		Name:       controller.clusterName,
+		ArkVersion: buildinfo.Version,
+	}
+
+	if serverVersion := controller.discoveryHelper.ServerVersion(); serverVersion != nil {
+		info.KubernetesVersion = serverVersion.GitVersion
+	}
+
+	for _, resourceList := range controller.discoveryHelper.Resources() {
+		if info.APIResources == nil {
+			info.APIResources = make(map[string][]string)
+		}
+
+		for _, resource := range resourceList.APIResources {
+			info.APIResources[resourceList.GroupVersion] = append(info.APIResources[resourceList.GroupVersion], resource.Name)
+		}
+	}
+
+	return info
+}
+
 func closeAndRemoveFile(file *os.File, log logrus.FieldLogger) {
 	if err := file.Close(); err != nil {
 		log.WithError(err).WithField("file", file.Name()).Error("error closing file")
@@ -385,3 +654,174 @@ func closeAndRemoveFile(file *os.File, log logrus.FieldLogger) {
 		log.WithError(err).WithField("file", file.Name()).Error("error removing file")
 	}
 }
+
+// hasInProgressSnapshots returns true if any of the backup's volume snapshots, or copies of
+// them, are still being processed by the cloud provider.
+func hasInProgressSnapshots(backup *api.Backup) bool {
+	for _, volumeBackup := range backup.Status.VolumeBackups {
+		if volumeBackup.Phase == api.SnapshotPhaseInProgress || volumeBackup.CopyPhase == api.SnapshotPhaseInProgress {
+			return true
+		}
+	}
+
+	return false
+}
+
+// syncSnapshotPhases checks the status of volume snapshots that the cloud provider is still
+// processing, for all backups that have finished running. If a snapshot copy region is configured,
+// completed snapshots are copied to it. Once a backup has no more in-progress snapshots or copies,
+// it's marked Completed (or Failed, if any of the snapshots failed, or PartiallyFailed, if any of
+// the copies failed) and its metadata file in object storage is updated.
+func (controller *backupController) syncSnapshotPhases() {
+	backups, err := controller.lister.List(labels.Everything())
+	if err != nil {
+		controller.logger.WithError(err).Error("Error listing backups")
+		return
+	}
+
+	for _, backup := range backups {
+		switch backup.Status.Phase {
+		case api.BackupPhaseInProgress, api.BackupPhaseCompleted, api.BackupPhasePartiallyFailed:
+		default:
+			continue
+		}
+
+		// backups that are still running will have their snapshots checked once they finish.
+		if !hasInProgressSnapshots(backup) || controller.operationTracker.Contains(api.OperationKindBackup, backup.Namespace, backup.Name) {
+			continue
+		}
+
+		if err := controller.syncBackupSnapshotPhases(backup); err != nil {
+			controller.logger.WithError(err).WithField("backup", kubeutil.NamespaceAndName(backup)).Error("Error syncing volume snapshot phases")
+		}
+	}
+}
+
+func (controller *backupController) syncBackupSnapshotPhases(original *api.Backup) error {
+	log := controller.logger.WithField("backup", kubeutil.NamespaceAndName(original))
+
+	backup := original.DeepCopy()
+
+	var changed, failed, copyFailed bool
+	for pvName, volumeBackup := range backup.Status.VolumeBackups {
+		pvLog := log.WithField("persistentVolume", pvName)
+
+		if volumeBackup.Phase == api.SnapshotPhaseInProgress {
+			if controller.updateSnapshotPhase(&volumeBackup.Phase, volumeBackup.SnapshotID, pvLog) {
+				changed = true
+			}
+		}
+
+		if volumeBackup.Phase == api.SnapshotPhaseCompleted && controller.snapshotCopyRegion != "" && volumeBackup.CopyPhase == "" {
+			copyLog := pvLog.WithFields(logrus.Fields{
+				"snapshotID": volumeBackup.SnapshotID,
+				"region":     controller.snapshotCopyRegion,
+			})
+
+			copySnapshotID, err := controller.snapshotService.CopySnapshot(volumeBackup.SnapshotID, controller.snapshotCopyRegion)
+			if err != nil {
+				copyLog.WithError(err).Error("Error copying volume snapshot")
+				volumeBackup.CopyPhase = api.SnapshotPhaseFailed
+			} else {
+				copyLog.WithField("copySnapshotID", copySnapshotID).Info("Copying volume snapshot")
+				volumeBackup.CopyRegion = controller.snapshotCopyRegion
+				volumeBackup.CopySnapshotID = copySnapshotID
+				volumeBackup.CopyPhase = api.SnapshotPhaseInProgress
+			}
+			changed = true
+		}
+
+		if volumeBackup.CopyPhase == api.SnapshotPhaseInProgress {
+			if controller.updateSnapshotPhase(&volumeBackup.CopyPhase, volumeBackup.CopySnapshotID, pvLog) {
+				changed = true
+			}
+		}
+
+		if volumeBackup.Phase == api.SnapshotPhaseFailed {
+			failed = true
+		}
+
+		// the primary snapshot is still usable, so a copy that failed only partially fails
+		// the backup
+		if volumeBackup.CopyPhase == api.SnapshotPhaseFailed {
+			copyFailed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	syncVolumeSnapshotPhases(backup)
+
+	finished := !hasInProgressSnapshots(backup)
+	if finished {
+		switch {
+		case failed:
+			backup.Status.Phase = api.BackupPhaseFailed
+		case copyFailed:
+			backup.Status.Phase = api.BackupPhasePartiallyFailed
+		case backup.Status.Phase != api.BackupPhasePartiallyFailed:
+			backup.Status.Phase = api.BackupPhaseCompleted
+		}
+		log.WithField("phase", backup.Status.Phase).Info("All volume snapshots have finished processing")
+	}
+
+	updated, err := patchBackup(original, backup, controller.client)
+	if err != nil {
+		return err
+	}
+
+	if finished {
+		// update the metadata file in object storage so that synced copies of this backup
+		// reflect its final state.
+		backupJSON := new(bytes.Buffer)
+		if err := encode.EncodeTo(updated, "json", backupJSON); err != nil {
+			return errors.Wrap(err, "error encoding backup")
+		}
+
+		if err := controller.backupService.UploadBackup(controller.bucket, updated, backupJSON, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncVolumeSnapshotPhases updates the phase of each of the backup's VolumeSnapshots to match
+// that of the snapshot recorded for its PersistentVolume in VolumeBackups.
+func syncVolumeSnapshotPhases(backup *api.Backup) {
+	for i := range backup.Status.VolumeSnapshots {
+		snapshot := &backup.Status.VolumeSnapshots[i]
+
+		if info, ok := backup.Status.VolumeBackups[snapshot.PersistentVolume]; ok && info.SnapshotID == snapshot.SnapshotID {
+			snapshot.Phase = info.Phase
+		}
+	}
+}
+
+// updateSnapshotPhase gets the current phase of the specified snapshot from the cloud provider,
+// and updates phase if the snapshot is no longer in progress. It returns true if phase was
+// updated.
+func (controller *backupController) updateSnapshotPhase(phase *api.SnapshotPhase, snapshotID string, log logrus.FieldLogger) bool {
+	log = log.WithField("snapshotID", snapshotID)
+
+	current, err := controller.snapshotService.GetSnapshotPhase(snapshotID)
+	if err != nil {
+		// try again on the next sync
+		log.WithError(err).Warn("Error getting volume snapshot phase")
+		return false
+	}
+
+	switch current {
+	case api.SnapshotPhaseCompleted:
+		log.Info("Volume snapshot completed")
+	case api.SnapshotPhaseFailed:
+		log.Error("Volume snapshot failed")
+	default:
+		return false
+	}
+
+	*phase = current
+	return true
+}
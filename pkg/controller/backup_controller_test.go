@@ -22,8 +22,16 @@ import (
 	"testing"
 	"time"
 
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/version"
 	core "k8s.io/client-go/testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,6 +57,11 @@ func (b *fakeBackupper) Backup(backup *v1.Backup, data, log io.Writer, actions [
 	return args.Error(0)
 }
 
+func (b *fakeBackupper) UnquiesceInterrupted(log logrus.FieldLogger) error {
+	args := b.Called(log)
+	return args.Error(0)
+}
+
 func TestProcessBackup(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -59,6 +72,10 @@ func TestProcessBackup(t *testing.T) {
 		backup           *arktest.TestBackup
 		expectBackup     bool
 		allowSnapshots   bool
+		backupErr        error
+		expectedPhase    v1.BackupPhase
+		defaultBackupTTL time.Duration
+		maxItemDepth     int
 	}{
 		{
 			name:        "bad key",
@@ -145,6 +162,87 @@ func TestProcessBackup(t *testing.T) {
 			allowSnapshots: true,
 			expectBackup:   true,
 		},
+		{
+			name:         "invalid shardBy fails validation",
+			key:          "heptio-ark/backup1",
+			backup:       arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew).WithShardBy("Pod"),
+			expectBackup: false,
+		},
+		{
+			name:         "quiesce without a label selector fails validation",
+			key:          "heptio-ark/backup1",
+			backup:       arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew).WithQuiesce(&v1.QuiesceSpec{}),
+			expectBackup: false,
+		},
+		{
+			name:         "invalid failurePolicy fails validation",
+			key:          "heptio-ark/backup1",
+			backup:       arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew).WithFailurePolicy("Retry"),
+			expectBackup: false,
+		},
+		{
+			name:         "invalid deletionPolicy fails validation",
+			key:          "heptio-ark/backup1",
+			backup:       arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew).WithDeletionPolicy("Everything"),
+			expectBackup: false,
+		},
+		{
+			name:          "backup error partially fails the backup by default",
+			key:           "heptio-ark/backup1",
+			backup:        arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew),
+			expectBackup:  true,
+			backupErr:     errors.New("error backing up item"),
+			expectedPhase: v1.BackupPhasePartiallyFailed,
+		},
+		{
+			name:          "backup error fails the backup with the FailFast policy",
+			key:           "heptio-ark/backup1",
+			backup:        arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew).WithFailurePolicy(v1.BackupFailurePolicyFailFast),
+			expectBackup:  true,
+			backupErr:     errors.New("error backing up item"),
+			expectedPhase: v1.BackupPhaseFailed,
+		},
+		{
+			name:         "requested-by annotation is recorded in status",
+			key:          "heptio-ark/backup1",
+			backup:       arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew).WithAnnotation(v1.RequestedByAnnotation, "user-1"),
+			expectBackup: true,
+		},
+		{
+			name:             "backup without a TTL gets the server's default TTL",
+			key:              "heptio-ark/backup1",
+			backup:           arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew),
+			expectBackup:     true,
+			defaultBackupTTL: 24 * time.Hour,
+		},
+		{
+			name:             "backup's own TTL takes precedence over the server's default TTL",
+			key:              "heptio-ark/backup1",
+			backup:           arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew).WithTTL(time.Hour),
+			expectBackup:     true,
+			defaultBackupTTL: 24 * time.Hour,
+		},
+		{
+			name:             "backup without a TTL never expires when the server's default TTL is disabled",
+			key:              "heptio-ark/backup1",
+			backup:           arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew),
+			expectBackup:     true,
+			defaultBackupTTL: -1,
+		},
+		{
+			name:             "backup with a negative TTL never expires",
+			key:              "heptio-ark/backup1",
+			backup:           arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew).WithTTL(-time.Second),
+			expectBackup:     true,
+			defaultBackupTTL: 24 * time.Hour,
+		},
+		{
+			name:         "backup without a max additional item depth gets the server's",
+			key:          "heptio-ark/backup1",
+			backup:       arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseNew).WithTTL(time.Hour),
+			expectBackup: true,
+			maxItemDepth: 5,
+		},
 	}
 
 	for _, test := range tests {
@@ -157,8 +255,20 @@ func TestProcessBackup(t *testing.T) {
 				logger          = arktest.NewLogger()
 				pluginManager   = &MockManager{}
 				clockTime, _    = time.Parse("Mon Jan 2 15:04:05 2006", "Mon Jan 2 15:04:05 2006")
+				discoveryHelper = arktest.NewFakeDiscoveryHelper(true, map[schema.GroupVersionResource]schema.GroupVersionResource{
+					{Resource: "pods"}: {Version: "v1", Resource: "pods"},
+				})
 			)
 
+			discoveryHelper.APIServerVersion = &version.Info{GitVersion: "v1.10.0"}
+
+			// this is what we expect the controller to record about the cluster
+			clusterInfo := &v1.ClusterInfo{
+				Name:              "cluster-1",
+				KubernetesVersion: "v1.10.0",
+				APIResources:      map[string][]string{"v1": {"pods"}},
+			}
+
 			c := NewBackupController(
 				sharedInformers.Ark().V1().Backups(),
 				client.ArkV1(),
@@ -168,34 +278,60 @@ func TestProcessBackup(t *testing.T) {
 				test.allowSnapshots,
 				logger,
 				pluginManager,
-				NewBackupTracker(),
+				NewOperationTracker(),
+				nil,
+				time.Minute,
+				false,
+				"",
+				discoveryHelper,
+				"cluster-1",
+				false,
+				0,
+				0,
+				"",
+				test.defaultBackupTTL,
+				test.maxItemDepth,
 			).(*backupController)
 
 			c.clock = clock.NewFakeClock(clockTime)
+			c.newOperationID = func() string { return "operation-1" }
 
-			var expiration time.Time
+			var (
+				expiration   time.Time
+				defaultedTTL bool
+			)
 
 			if test.backup != nil {
 				// add directly to the informer's store so the lister can function and so we don't have to
 				// start the shared informers.
 				sharedInformers.Ark().V1().Backups().Informer().GetStore().Add(test.backup.Backup)
 
-				if test.backup.Spec.TTL.Duration > 0 {
-					expiration = c.clock.Now().Add(test.backup.Spec.TTL.Duration)
+				ttl := test.backup.Spec.TTL.Duration
+				if ttl == 0 && test.defaultBackupTTL > 0 {
+					ttl = test.defaultBackupTTL
+					defaultedTTL = true
+				}
+				if ttl > 0 {
+					expiration = c.clock.Now().Add(ttl)
 				}
 
 				// set up a Backup object to represent what we expect to be passed to backupper.Backup()
 				backup := test.backup.DeepCopy()
+				backup.Spec.TTL.Duration = ttl
+				backup.Spec.MaxAdditionalItemDepth = test.maxItemDepth
 				backup.Spec.IncludedResources = test.expectedIncludes
 				backup.Spec.ExcludedResources = test.expectedExcludes
 				backup.Spec.IncludedNamespaces = test.backup.Spec.IncludedNamespaces
 				backup.Spec.SnapshotVolumes = test.backup.Spec.SnapshotVolumes
 				backup.Status.Phase = v1.BackupPhaseInProgress
+				backup.Status.Attempts = 1
 				backup.Status.Expiration.Time = expiration
-				backup.Status.Version = 1
-				backupper.On("Backup", backup, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				backup.Status.ClusterInfo = clusterInfo
+				backup.Status.RequestedBy = test.backup.Annotations[v1.RequestedByAnnotation]
+				backup.Status.OperationID = "operation-1"
+				backupper.On("Backup", backup, mock.Anything, mock.Anything, mock.Anything).Return(test.backupErr)
 
-				cloudBackups.On("UploadBackup", "bucket", backup.Name, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				cloudBackups.On("UploadBackup", "bucket", mock.MatchedBy(func(b *v1.Backup) bool { return b.Name == backup.Name }), mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 				pluginManager.On("GetBackupItemActions", backup.Name).Return(nil, nil)
 				pluginManager.On("CloseBackupItemActions", backup.Name).Return(nil)
@@ -225,9 +361,16 @@ func TestProcessBackup(t *testing.T) {
 
 				// these are the fields that we expect to be set by
 				// the controller
-				res.Status.Version = 1
+				if defaultedTTL {
+					res.Spec.TTL.Duration = test.defaultBackupTTL
+				}
+				res.Spec.MaxAdditionalItemDepth = test.maxItemDepth
+				res.Status.ClusterInfo = clusterInfo
 				res.Status.Expiration.Time = expiration
 				res.Status.Phase = v1.BackupPhase(phase)
+				res.Status.Attempts = 1
+				res.Status.RequestedBy = test.backup.Annotations[v1.RequestedByAnnotation]
+				res.Status.OperationID = "operation-1"
 
 				return true, res, nil
 			})
@@ -252,12 +395,21 @@ func TestProcessBackup(t *testing.T) {
 
 			// structs and func for decoding patch content
 			type StatusPatch struct {
-				Expiration time.Time      `json:"expiration"`
-				Version    int            `json:"version"`
-				Phase      v1.BackupPhase `json:"phase"`
+				Expiration  time.Time       `json:"expiration"`
+				Phase       v1.BackupPhase  `json:"phase"`
+				ClusterInfo *v1.ClusterInfo `json:"clusterInfo"`
+				Attempts    int             `json:"attempts"`
+				RequestedBy string          `json:"requestedBy"`
+				OperationID string          `json:"operationID"`
+			}
+
+			type SpecPatch struct {
+				TTL                    metav1.Duration `json:"ttl"`
+				MaxAdditionalItemDepth int             `json:"maxAdditionalItemDepth"`
 			}
 
 			type Patch struct {
+				Spec   *SpecPatch  `json:"spec,omitempty"`
 				Status StatusPatch `json:"status"`
 			}
 
@@ -268,21 +420,37 @@ func TestProcessBackup(t *testing.T) {
 				return *actual, err
 			}
 
-			// validate Patch call 1 (setting version, expiration, and phase)
+			// validate Patch call 1 (setting expiration and phase)
 			expected := Patch{
 				Status: StatusPatch{
-					Version:    1,
-					Phase:      v1.BackupPhaseInProgress,
-					Expiration: expiration,
+					Phase:       v1.BackupPhaseInProgress,
+					Expiration:  expiration,
+					ClusterInfo: clusterInfo,
+					Attempts:    1,
+					RequestedBy: test.backup.Annotations[v1.RequestedByAnnotation],
+					OperationID: "operation-1",
 				},
 			}
+			if defaultedTTL {
+				expected.Spec = &SpecPatch{TTL: metav1.Duration{Duration: test.defaultBackupTTL}}
+			}
+			if test.maxItemDepth > 0 {
+				if expected.Spec == nil {
+					expected.Spec = &SpecPatch{}
+				}
+				expected.Spec.MaxAdditionalItemDepth = test.maxItemDepth
+			}
 
 			arktest.ValidatePatch(t, actions[0], expected, decode)
 
 			// validate Patch call 2 (setting phase)
+			expectedPhase := test.expectedPhase
+			if expectedPhase == "" {
+				expectedPhase = v1.BackupPhaseCompleted
+			}
 			expected = Patch{
 				Status: StatusPatch{
-					Phase: v1.BackupPhaseCompleted,
+					Phase: expectedPhase,
 				},
 			}
 
@@ -291,6 +459,296 @@ func TestProcessBackup(t *testing.T) {
 	}
 }
 
+func TestSyncBackupSnapshotPhases(t *testing.T) {
+	tests := []struct {
+		name                  string
+		backup                *arktest.TestBackup
+		snapshotPhases        map[string]v1.SnapshotPhase
+		snapshotCopyRegion    string
+		expectPatch           bool
+		expectedPhase         v1.BackupPhase
+		expectedSnapshotPhase map[string]v1.SnapshotPhase
+		expectedCopyPhase     map[string]v1.SnapshotPhase
+	}{
+		{
+			name:           "snapshot still in progress doesn't change the backup",
+			backup:         arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).WithSnapshotInPhase("pv1", "snap1", v1.SnapshotPhaseInProgress),
+			snapshotPhases: map[string]v1.SnapshotPhase{"snap1": v1.SnapshotPhaseInProgress},
+			expectPatch:    false,
+		},
+		{
+			name:           "error getting snapshot phase doesn't change the backup",
+			backup:         arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).WithSnapshotInPhase("pv1", "snap1", v1.SnapshotPhaseInProgress),
+			snapshotPhases: map[string]v1.SnapshotPhase{},
+			expectPatch:    false,
+		},
+		{
+			name: "one of two snapshots completing updates the snapshot but not the backup phase",
+			backup: arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).
+				WithSnapshotInPhase("pv1", "snap1", v1.SnapshotPhaseInProgress).
+				WithSnapshotInPhase("pv2", "snap2", v1.SnapshotPhaseInProgress),
+			snapshotPhases:        map[string]v1.SnapshotPhase{"snap1": v1.SnapshotPhaseCompleted, "snap2": v1.SnapshotPhaseInProgress},
+			expectPatch:           true,
+			expectedPhase:         v1.BackupPhaseInProgress,
+			expectedSnapshotPhase: map[string]v1.SnapshotPhase{"pv1": v1.SnapshotPhaseCompleted, "pv2": v1.SnapshotPhaseInProgress},
+		},
+		{
+			name: "all snapshots completing completes the backup",
+			backup: arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).
+				WithSnapshotInPhase("pv1", "snap1", v1.SnapshotPhaseCompleted).
+				WithSnapshotInPhase("pv2", "snap2", v1.SnapshotPhaseInProgress),
+			snapshotPhases:        map[string]v1.SnapshotPhase{"snap2": v1.SnapshotPhaseCompleted},
+			expectPatch:           true,
+			expectedPhase:         v1.BackupPhaseCompleted,
+			expectedSnapshotPhase: map[string]v1.SnapshotPhase{"pv1": v1.SnapshotPhaseCompleted, "pv2": v1.SnapshotPhaseCompleted},
+		},
+		{
+			name:                  "failed snapshot fails the backup",
+			backup:                arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).WithSnapshotInPhase("pv1", "snap1", v1.SnapshotPhaseInProgress),
+			snapshotPhases:        map[string]v1.SnapshotPhase{"snap1": v1.SnapshotPhaseFailed},
+			expectPatch:           true,
+			expectedPhase:         v1.BackupPhaseFailed,
+			expectedSnapshotPhase: map[string]v1.SnapshotPhase{"pv1": v1.SnapshotPhaseFailed},
+		},
+		{
+			name:                  "backup completed before its snapshots were ready stays completed",
+			backup:                arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseCompleted).WithSnapshotInPhase("pv1", "snap1", v1.SnapshotPhaseInProgress),
+			snapshotPhases:        map[string]v1.SnapshotPhase{"snap1": v1.SnapshotPhaseCompleted},
+			expectPatch:           true,
+			expectedPhase:         v1.BackupPhaseCompleted,
+			expectedSnapshotPhase: map[string]v1.SnapshotPhase{"pv1": v1.SnapshotPhaseCompleted},
+		},
+		{
+			name:                  "completed snapshot is copied when a copy region is configured",
+			backup:                arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).WithSnapshotInPhase("pv1", "snap1", v1.SnapshotPhaseInProgress),
+			snapshotPhases:        map[string]v1.SnapshotPhase{"snap1": v1.SnapshotPhaseCompleted, "us-west-2/snap1": v1.SnapshotPhaseInProgress},
+			snapshotCopyRegion:    "us-west-2",
+			expectPatch:           true,
+			expectedPhase:         v1.BackupPhaseInProgress,
+			expectedSnapshotPhase: map[string]v1.SnapshotPhase{"pv1": v1.SnapshotPhaseCompleted},
+			expectedCopyPhase:     map[string]v1.SnapshotPhase{"pv1": v1.SnapshotPhaseInProgress},
+		},
+		{
+			name:                  "completed snapshot copy completes the backup",
+			backup:                arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).WithSnapshotCopyInPhase("pv1", "snap1", "us-west-2/snap1", v1.SnapshotPhaseInProgress),
+			snapshotPhases:        map[string]v1.SnapshotPhase{"us-west-2/snap1": v1.SnapshotPhaseCompleted},
+			snapshotCopyRegion:    "us-west-2",
+			expectPatch:           true,
+			expectedPhase:         v1.BackupPhaseCompleted,
+			expectedSnapshotPhase: map[string]v1.SnapshotPhase{"pv1": v1.SnapshotPhaseCompleted},
+			expectedCopyPhase:     map[string]v1.SnapshotPhase{"pv1": v1.SnapshotPhaseCompleted},
+		},
+		{
+			name:                  "failed snapshot copy partially fails the backup",
+			backup:                arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).WithSnapshotCopyInPhase("pv1", "snap1", "us-west-2/snap1", v1.SnapshotPhaseInProgress),
+			snapshotPhases:        map[string]v1.SnapshotPhase{"us-west-2/snap1": v1.SnapshotPhaseFailed},
+			snapshotCopyRegion:    "us-west-2",
+			expectPatch:           true,
+			expectedPhase:         v1.BackupPhasePartiallyFailed,
+			expectedSnapshotPhase: map[string]v1.SnapshotPhase{"pv1": v1.SnapshotPhaseCompleted},
+			expectedCopyPhase:     map[string]v1.SnapshotPhase{"pv1": v1.SnapshotPhaseFailed},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				client          = fake.NewSimpleClientset(test.backup.Backup)
+				cloudBackups    = &arktest.BackupService{}
+				snapshotService = &arktest.FakeSnapshotService{SnapshotPhases: test.snapshotPhases, SnapshotsTaken: sets.NewString()}
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+			)
+
+			c := NewBackupController(
+				sharedInformers.Ark().V1().Backups(),
+				client.ArkV1(),
+				&fakeBackupper{},
+				cloudBackups,
+				"bucket",
+				true,
+				arktest.NewLogger(),
+				&MockManager{},
+				NewOperationTracker(),
+				snapshotService,
+				time.Minute,
+				false,
+				test.snapshotCopyRegion,
+				arktest.NewFakeDiscoveryHelper(true, nil),
+				"",
+				false,
+				0,
+				0,
+				"",
+				0,
+				0,
+			).(*backupController)
+
+			for pv, volumeBackup := range test.backup.Status.VolumeBackups {
+				snapshotService.SnapshotsTaken.Insert(volumeBackup.SnapshotID)
+
+				test.backup.Status.VolumeSnapshots = append(test.backup.Status.VolumeSnapshots, v1.VolumeSnapshotStatus{
+					PersistentVolume: pv,
+					SnapshotID:       volumeBackup.SnapshotID,
+					Phase:            volumeBackup.Phase,
+				})
+			}
+
+			sharedInformers.Ark().V1().Backups().Informer().GetStore().Add(test.backup.Backup)
+
+			var patched *v1.Backup
+			client.PrependReactor("patch", "backups", func(action core.Action) (bool, runtime.Object, error) {
+				original, err := json.Marshal(test.backup.Backup)
+				require.NoError(t, err)
+
+				updated, err := jsonpatch.MergePatch(original, action.(core.PatchAction).GetPatch())
+				require.NoError(t, err)
+
+				patched = new(v1.Backup)
+				require.NoError(t, json.Unmarshal(updated, patched))
+
+				return true, patched, nil
+			})
+
+			if test.expectedPhase == v1.BackupPhaseCompleted || test.expectedPhase == v1.BackupPhasePartiallyFailed || test.expectedPhase == v1.BackupPhaseFailed {
+				cloudBackups.On("UploadBackup", "bucket", mock.MatchedBy(func(b *v1.Backup) bool { return b.Name == "backup1" }), mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			}
+
+			c.syncSnapshotPhases()
+
+			if !test.expectPatch {
+				assert.Nil(t, patched)
+				assert.Empty(t, cloudBackups.Calls)
+				return
+			}
+
+			require.NotNil(t, patched)
+			assert.Equal(t, test.expectedPhase, patched.Status.Phase)
+
+			snapshotPhases := make(map[string]v1.SnapshotPhase)
+			var copyPhases map[string]v1.SnapshotPhase
+			for pv, volumeBackup := range patched.Status.VolumeBackups {
+				snapshotPhases[pv] = volumeBackup.Phase
+
+				if volumeBackup.CopyPhase != "" {
+					if copyPhases == nil {
+						copyPhases = make(map[string]v1.SnapshotPhase)
+					}
+					copyPhases[pv] = volumeBackup.CopyPhase
+				}
+			}
+			assert.Equal(t, test.expectedSnapshotPhase, snapshotPhases)
+			assert.Equal(t, test.expectedCopyPhase, copyPhases)
+
+			require.Len(t, patched.Status.VolumeSnapshots, len(test.expectedSnapshotPhase))
+			for _, snapshot := range patched.Status.VolumeSnapshots {
+				assert.Equal(t, test.expectedSnapshotPhase[snapshot.PersistentVolume], snapshot.Phase, "phase of %s's VolumeSnapshot", snapshot.PersistentVolume)
+			}
+
+			cloudBackups.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandleInterruptedBackups(t *testing.T) {
+	tests := []struct {
+		name          string
+		backup        *arktest.TestBackup
+		retries       int
+		expectedPhase v1.BackupPhase
+	}{
+		{
+			name:          "interrupted backup is failed when retries are disabled",
+			backup:        arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).WithAttempts(1),
+			expectedPhase: v1.BackupPhaseFailed,
+		},
+		{
+			name:          "interrupted backup is restarted when it has retries left",
+			backup:        arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).WithAttempts(2),
+			retries:       2,
+			expectedPhase: v1.BackupPhaseNew,
+		},
+		{
+			name:          "interrupted backup is failed when it has no retries left",
+			backup:        arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).WithAttempts(3),
+			retries:       2,
+			expectedPhase: v1.BackupPhaseFailed,
+		},
+		{
+			name:    "backup waiting for its snapshots to be ready is left alone",
+			backup:  arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).WithAttempts(1).WithSnapshotInPhase("pv1", "snap1", v1.SnapshotPhaseInProgress),
+			retries: 2,
+		},
+		{
+			name:    "completed backup is left alone",
+			backup:  arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseCompleted).WithAttempts(1),
+			retries: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				client          = fake.NewSimpleClientset(test.backup.Backup)
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+				backupper       = &fakeBackupper{}
+			)
+			backupper.On("UnquiesceInterrupted", mock.Anything).Return(nil)
+
+			c := NewBackupController(
+				sharedInformers.Ark().V1().Backups(),
+				client.ArkV1(),
+				backupper,
+				&arktest.BackupService{},
+				"bucket",
+				true,
+				arktest.NewLogger(),
+				&MockManager{},
+				NewOperationTracker(),
+				nil,
+				time.Minute,
+				false,
+				"",
+				arktest.NewFakeDiscoveryHelper(true, nil),
+				"",
+				false,
+				test.retries,
+				0,
+				"",
+				0,
+				0,
+			).(*backupController)
+
+			sharedInformers.Ark().V1().Backups().Informer().GetStore().Add(test.backup.Backup)
+
+			var patched *v1.Backup
+			client.PrependReactor("patch", "backups", func(action core.Action) (bool, runtime.Object, error) {
+				original, err := json.Marshal(test.backup.Backup)
+				require.NoError(t, err)
+
+				updated, err := jsonpatch.MergePatch(original, action.(core.PatchAction).GetPatch())
+				require.NoError(t, err)
+
+				patched = new(v1.Backup)
+				require.NoError(t, json.Unmarshal(updated, patched))
+
+				return true, patched, nil
+			})
+
+			c.handleInterruptedBackups()
+			backupper.AssertExpectations(t)
+
+			if test.expectedPhase == "" {
+				assert.Nil(t, patched)
+				return
+			}
+
+			require.NotNil(t, patched)
+			assert.Equal(t, test.expectedPhase, patched.Status.Phase)
+			assert.Equal(t, test.backup.Status.Attempts, patched.Status.Attempts)
+		})
+	}
+}
+
 // MockManager is an autogenerated mock type for the Manager type
 type MockManager struct {
 	mock.Mock
@@ -421,3 +879,49 @@ func (_m *MockManager) CleanupClients() {
 	_ = _m.Called()
 	return
 }
+
+func TestRunBackupDryRunOnlyUploadsLog(t *testing.T) {
+	var (
+		client          = fake.NewSimpleClientset()
+		sharedInformers = informers.NewSharedInformerFactory(client, 0)
+		backupper       = &fakeBackupper{}
+		cloudBackups    = &arktest.BackupService{}
+		pluginManager   = &MockManager{}
+	)
+	defer backupper.AssertExpectations(t)
+	defer cloudBackups.AssertExpectations(t)
+
+	c := NewBackupController(
+		sharedInformers.Ark().V1().Backups(),
+		client.ArkV1(),
+		backupper,
+		cloudBackups,
+		"bucket",
+		true,
+		arktest.NewLogger(),
+		pluginManager,
+		NewOperationTracker(),
+		nil,
+		time.Minute,
+		false,
+		"",
+		arktest.NewFakeDiscoveryHelper(true, nil),
+		"",
+		false,
+		0,
+		0,
+		"",
+		0,
+		0,
+	).(*backupController)
+
+	backup := arktest.NewTestBackup().WithName("backup1").WithPhase(v1.BackupPhaseInProgress).WithDryRun(true).Backup
+
+	pluginManager.On("GetBackupItemActions", backup.Name).Return(nil, nil)
+	pluginManager.On("CloseBackupItemActions", backup.Name).Return(nil)
+	backupper.On("Backup", backup, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	cloudBackups.On("UploadBackup", "bucket", backup, nil, nil, mock.Anything).Return(nil)
+
+	require.NoError(t, c.runBackup(backup, "bucket"))
+	assert.Equal(t, v1.BackupPhaseCompleted, backup.Status.Phase)
+}
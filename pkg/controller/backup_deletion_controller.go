@@ -17,16 +17,21 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"sort"
 	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/audit"
 	pkgbackup "github.com/heptio/ark/pkg/backup"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions/ark/v1"
 	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+	"github.com/heptio/ark/pkg/util/encode"
 	"github.com/heptio/ark/pkg/util/kube"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -49,12 +54,21 @@ type backupDeletionController struct {
 	bucket                    string
 	restoreLister             listers.RestoreLister
 	restoreClient             arkv1client.RestoresGetter
-	backupTracker             BackupTracker
+	operationTracker          OperationTracker
+	auditLog                  audit.Log
 
 	processRequestFunc func(*v1.DeleteBackupRequest) error
 	clock              clock.Clock
 }
 
+// backupDeletionRetryPolicy gives up on a DeleteBackupRequest after about 15 minutes of
+// retries, rather than retrying a request that can't succeed forever.
+var backupDeletionRetryPolicy = retryPolicy{
+	baseDelay:  time.Second,
+	maxDelay:   5 * time.Minute,
+	maxRetries: 10,
+}
+
 // NewBackupDeletionController creates a new backup deletion controller.
 func NewBackupDeletionController(
 	logger logrus.FieldLogger,
@@ -66,10 +80,11 @@ func NewBackupDeletionController(
 	bucket string,
 	restoreInformer informers.RestoreInformer,
 	restoreClient arkv1client.RestoresGetter,
-	backupTracker BackupTracker,
+	operationTracker OperationTracker,
+	auditLog audit.Log,
 ) Interface {
 	c := &backupDeletionController{
-		genericController:         newGenericController("backup-deletion", logger),
+		genericController:         newGenericControllerWithRetryPolicy("backup-deletion", logger, backupDeletionRetryPolicy),
 		deleteBackupRequestClient: deleteBackupRequestClient,
 		deleteBackupRequestLister: deleteBackupRequestInformer.Lister(),
 		backupClient:              backupClient,
@@ -78,11 +93,13 @@ func NewBackupDeletionController(
 		bucket:                    bucket,
 		restoreLister:             restoreInformer.Lister(),
 		restoreClient:             restoreClient,
-		backupTracker:             backupTracker,
+		operationTracker:          operationTracker,
+		auditLog:                  auditLog,
 		clock:                     &clock.RealClock{},
 	}
 
 	c.syncHandler = c.processQueueItem
+	c.failedHandler = c.recordFailedRequest
 	c.cacheSyncWaiters = append(c.cacheSyncWaiters, deleteBackupRequestInformer.Informer().HasSynced, restoreInformer.Informer().HasSynced)
 	c.processRequestFunc = c.processRequest
 
@@ -99,6 +116,38 @@ func NewBackupDeletionController(
 	return c
 }
 
+// auditDeletion records a deletion performed for backupName, which returned err, in the audit
+// log.
+func (c *backupDeletionController) auditDeletion(kind, name, backupName string, err error) {
+	c.auditLog.Record(audit.Entry{
+		Action: audit.ActionDelete,
+		Kind:   kind,
+		Name:   name,
+		Backup: backupName,
+	}.WithError(err))
+}
+
+// markBackupDeleted replaces backup's metadata file in object storage with a copy of backup
+// that's annotated as deleted with the given deletion policy.
+func (c *backupDeletionController) markBackupDeleted(backup *v1.Backup, policy v1.BackupDeletionPolicy) error {
+	marked := backup.DeepCopy()
+	if marked.Annotations == nil {
+		marked.Annotations = make(map[string]string)
+	}
+	marked.Annotations[v1.DeletedBackupAnnotation] = string(policy)
+
+	backupJSON := new(bytes.Buffer)
+	if err := encode.EncodeTo(marked, "json", backupJSON); err != nil {
+		return errors.Wrap(err, "error encoding backup")
+	}
+
+	if err := c.backupService.UploadBackup(c.bucket, marked, backupJSON, nil, nil); err != nil {
+		return errors.Wrap(err, "error marking backup as deleted in object storage")
+	}
+
+	return nil
+}
+
 func (c *backupDeletionController) processQueueItem(key string) error {
 	log := c.logger.WithField("key", key)
 	log.Debug("Running processItem")
@@ -129,6 +178,35 @@ func (c *backupDeletionController) processQueueItem(key string) error {
 	return nil
 }
 
+// recordFailedRequest marks a DeleteBackupRequest that the controller has given up on
+// retrying as Processed, recording the error that caused it to fail.
+func (c *backupDeletionController) recordFailedRequest(key string, err error) {
+	log := c.logger.WithField("key", key)
+
+	ns, name, splitErr := cache.SplitMetaNamespaceKey(key)
+	if splitErr != nil {
+		log.WithError(errors.WithStack(splitErr)).Error("Error splitting queue key")
+		return
+	}
+
+	req, getErr := c.deleteBackupRequestLister.DeleteBackupRequests(ns).Get(name)
+	if apierrors.IsNotFound(getErr) {
+		return
+	}
+	if getErr != nil {
+		log.WithError(errors.WithStack(getErr)).Error("Error getting DeleteBackupRequest")
+		return
+	}
+
+	_, patchErr := c.patchDeleteBackupRequest(req.DeepCopy(), func(r *v1.DeleteBackupRequest) {
+		r.Status.Phase = v1.DeleteBackupRequestPhaseProcessed
+		r.Status.Errors = append(r.Status.Errors, fmt.Sprintf("gave up after repeated errors: %v", err))
+	})
+	if patchErr != nil {
+		log.WithError(patchErr).Error("Error recording failure of DeleteBackupRequest")
+	}
+}
+
 func (c *backupDeletionController) processRequest(req *v1.DeleteBackupRequest) error {
 	log := c.logger.WithFields(logrus.Fields{
 		"namespace": req.Namespace,
@@ -148,7 +226,7 @@ func (c *backupDeletionController) processRequest(req *v1.DeleteBackupRequest) e
 	}
 
 	// Don't allow deleting an in-progress backup
-	if c.backupTracker.Contains(req.Namespace, req.Spec.BackupName) {
+	if c.operationTracker.Contains(v1.OperationKindBackup, req.Namespace, req.Spec.BackupName) {
 		_, err = c.patchDeleteBackupRequest(req, func(r *v1.DeleteBackupRequest) {
 			r.Status.Phase = v1.DeleteBackupRequestPhaseProcessed
 			r.Status.Errors = []string{"backup is still in progress"}
@@ -157,6 +235,9 @@ func (c *backupDeletionController) processRequest(req *v1.DeleteBackupRequest) e
 		return err
 	}
 
+	c.operationTracker.Add(v1.OperationKindBackupDeletion, req.Namespace, req.Spec.BackupName)
+	defer c.operationTracker.Delete(v1.OperationKindBackupDeletion, req.Namespace, req.Spec.BackupName)
+
 	// Update status to InProgress and set backup-name label if needed
 	req, err = c.patchDeleteBackupRequest(req, func(r *v1.DeleteBackupRequest) {
 		r.Status.Phase = v1.DeleteBackupRequestPhaseInProgress
@@ -194,9 +275,36 @@ func (c *backupDeletionController) processRequest(req *v1.DeleteBackupRequest) e
 		}
 	}
 
+	// The request's deletion policy overrides the backup's
+	deletionPolicy := req.Spec.DeletionPolicy
+	if deletionPolicy == "" {
+		deletionPolicy = backup.Spec.DeletionPolicy
+	}
+	if deletionPolicy == "" {
+		deletionPolicy = v1.BackupDeletionPolicyAll
+	}
+
+	var deleteSnapshots, deleteObjectStorage bool
+	switch deletionPolicy {
+	case v1.BackupDeletionPolicyAll:
+		deleteSnapshots, deleteObjectStorage = true, true
+	case v1.BackupDeletionPolicySnapshotsOnly:
+		deleteSnapshots = true
+	case v1.BackupDeletionPolicyObjectStorageOnly:
+		deleteObjectStorage = true
+	default:
+		req, err = c.patchDeleteBackupRequest(req, func(r *v1.DeleteBackupRequest) {
+			r.Status.Phase = v1.DeleteBackupRequestPhaseProcessed
+			r.Status.Errors = []string{fmt.Sprintf("invalid deletion policy %q", deletionPolicy)}
+		})
+
+		return err
+	}
+	log = log.WithField("deletionPolicy", deletionPolicy)
+
 	// If the backup includes snapshots but we don't currently have a PVProvider, we don't
 	// want to orphan the snapshots so skip deletion.
-	if c.snapshotService == nil && len(backup.Status.VolumeBackups) > 0 {
+	if deleteSnapshots && c.snapshotService == nil && len(backup.Status.VolumeBackups) > 0 {
 		req, err = c.patchDeleteBackupRequest(req, func(r *v1.DeleteBackupRequest) {
 			r.Status.Phase = v1.DeleteBackupRequestPhaseProcessed
 			r.Status.Errors = []string{"unable to delete backup because it includes PV snapshots and Ark is not configured with a PersistentVolumeProvider"}
@@ -214,21 +322,72 @@ func (c *backupDeletionController) processRequest(req *v1.DeleteBackupRequest) e
 		return err
 	}
 
-	var errs []string
+	var (
+		errs    []string
+		results []v1.ArtifactDeletionResult
+	)
 
 	// Try to delete snapshots
-	log.Info("Removing PV snapshots")
 	for _, volumeBackup := range backup.Status.VolumeBackups {
-		log.WithField("snapshotID", volumeBackup.SnapshotID).Info("Removing snapshot associated with backup")
-		if err := c.snapshotService.DeleteSnapshot(volumeBackup.SnapshotID); err != nil {
-			errs = append(errs, errors.Wrapf(err, "error deleting snapshot %s", volumeBackup.SnapshotID).Error())
+		snapshots := []struct{ id, description string }{{volumeBackup.SnapshotID, "snapshot"}}
+		if volumeBackup.CopySnapshotID != "" {
+			snapshots = append(snapshots, struct{ id, description string }{volumeBackup.CopySnapshotID, "snapshot copy"})
+		}
+
+		for _, snapshot := range snapshots {
+			result := v1.ArtifactDeletionResult{
+				Artifact: v1.BackupArtifactSnapshot,
+				Name:     snapshot.id,
+				Phase:    v1.ArtifactDeletionPhaseRetained,
+			}
+
+			if deleteSnapshots {
+				log.WithField("snapshotID", snapshot.id).Infof("Removing %s associated with backup", snapshot.description)
+				err := c.snapshotService.DeleteSnapshot(snapshot.id)
+				c.auditDeletion(audit.KindVolumeSnapshot, snapshot.id, backup.Name, err)
+				if err != nil {
+					err = errors.Wrapf(err, "error deleting %s %s", snapshot.description, snapshot.id)
+					errs = append(errs, err.Error())
+					result.Phase, result.Error = v1.ArtifactDeletionPhaseFailed, err.Error()
+				} else {
+					result.Phase = v1.ArtifactDeletionPhaseDeleted
+				}
+			}
+
+			results = append(results, result)
 		}
 	}
 
 	// Try to delete backup from object storage
-	log.Info("Removing backup from object storage")
-	if err := c.backupService.DeleteBackupDir(c.bucket, backup.Name); err != nil {
-		errs = append(errs, errors.Wrap(err, "error deleting backup from object storage").Error())
+	if deleteObjectStorage {
+		log.Info("Removing backup from object storage")
+		objectResults, err := c.backupService.DeleteBackupDir(c.bucket, backup.Name)
+		c.auditDeletion(audit.KindBackupStorage, backup.Name, backup.Name, err)
+		if err != nil {
+			errs = append(errs, errors.Wrap(err, "error deleting backup from object storage").Error())
+		}
+		results = append(results, objectResults...)
+	} else {
+		results = append(results,
+			v1.ArtifactDeletionResult{Artifact: v1.BackupArtifactTarball, Name: backup.Name, Phase: v1.ArtifactDeletionPhaseRetained},
+			v1.ArtifactDeletionResult{Artifact: v1.BackupArtifactLogs, Name: backup.Name, Phase: v1.ArtifactDeletionPhaseRetained},
+		)
+	}
+
+	// A backup that's deleted with some of its artifacts retained is marked as deleted in object
+	// storage, so that it isn't synced back into the cluster and its retained snapshots aren't
+	// treated as orphans. This leaves its metadata file in object storage.
+	if !deleteObjectStorage || (!deleteSnapshots && len(backup.Status.VolumeBackups) > 0) {
+		log.Info("Marking backup as deleted in object storage")
+		if err := c.markBackupDeleted(backup, deletionPolicy); err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			for i := range results {
+				if results[i].Artifact == v1.BackupArtifactMetadata {
+					results[i].Phase = v1.ArtifactDeletionPhaseRetained
+				}
+			}
+		}
 	}
 
 	// Try to delete restores
@@ -236,6 +395,8 @@ func (c *backupDeletionController) processRequest(req *v1.DeleteBackupRequest) e
 	if restores, err := c.restoreLister.Restores(backup.Namespace).List(labels.Everything()); err != nil {
 		log.WithError(errors.WithStack(err)).Error("Error listing restore API objects")
 	} else {
+		sort.Slice(restores, func(i, j int) bool { return restores[i].Name < restores[j].Name })
+
 		for _, restore := range restores {
 			if restore.Spec.BackupName != backup.Name {
 				continue
@@ -244,24 +405,43 @@ func (c *backupDeletionController) processRequest(req *v1.DeleteBackupRequest) e
 			restoreLog := log.WithField("restore", kube.NamespaceAndName(restore))
 
 			restoreLog.Info("Deleting restore referencing backup")
+			result := v1.ArtifactDeletionResult{
+				Artifact: v1.BackupArtifactRestore,
+				Name:     kube.NamespaceAndName(restore),
+				Phase:    v1.ArtifactDeletionPhaseDeleted,
+			}
 			if err := c.restoreClient.Restores(restore.Namespace).Delete(restore.Name, &metav1.DeleteOptions{}); err != nil {
-				errs = append(errs, errors.Wrapf(err, "error deleting restore %s", kube.NamespaceAndName(restore)).Error())
+				err = errors.Wrapf(err, "error deleting restore %s", kube.NamespaceAndName(restore))
+				errs = append(errs, err.Error())
+				result.Phase, result.Error = v1.ArtifactDeletionPhaseFailed, err.Error()
 			}
+			results = append(results, result)
 		}
 	}
 
+	backupResult := v1.ArtifactDeletionResult{
+		Artifact: v1.BackupArtifactBackup,
+		Name:     kube.NamespaceAndName(backup),
+		Phase:    v1.ArtifactDeletionPhaseRetained,
+	}
 	if len(errs) == 0 {
 		// Only try to delete the backup object from kube if everything preceding went smoothly
 		err = c.backupClient.Backups(backup.Namespace).Delete(backup.Name, nil)
 		if err != nil {
-			errs = append(errs, errors.Wrapf(err, "error deleting backup %s", kube.NamespaceAndName(backup)).Error())
+			err = errors.Wrapf(err, "error deleting backup %s", kube.NamespaceAndName(backup))
+			errs = append(errs, err.Error())
+			backupResult.Phase, backupResult.Error = v1.ArtifactDeletionPhaseFailed, err.Error()
+		} else {
+			backupResult.Phase = v1.ArtifactDeletionPhaseDeleted
 		}
 	}
+	results = append(results, backupResult)
 
-	// Update status to processed and record errors
+	// Update status to processed and record errors and the outcome for each artifact
 	req, err = c.patchDeleteBackupRequest(req, func(r *v1.DeleteBackupRequest) {
 		r.Status.Phase = v1.DeleteBackupRequestPhaseProcessed
 		r.Status.Errors = errs
+		r.Status.Artifacts = results
 	})
 	if err != nil {
 		return err
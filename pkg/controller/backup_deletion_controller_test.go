@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/audit"
 	pkgbackup "github.com/heptio/ark/pkg/backup"
 	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
@@ -30,6 +31,7 @@ import (
 	arktest "github.com/heptio/ark/pkg/util/test"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -63,7 +65,8 @@ func TestBackupDeletionControllerControllerHasUpdateFunc(t *testing.T) {
 		"bucket",
 		sharedInformers.Ark().V1().Restores(),
 		client.ArkV1(), // restoreClient
-		NewBackupTracker(),
+		NewOperationTracker(),
+		&arktest.FakeAuditLog{},
 	).(*backupDeletionController)
 
 	// disable resync handler since we don't want to test it here
@@ -116,7 +119,8 @@ func TestBackupDeletionControllerProcessQueueItem(t *testing.T) {
 		"bucket",
 		sharedInformers.Ark().V1().Restores(),
 		client.ArkV1(), // restoreClient
-		NewBackupTracker(),
+		NewOperationTracker(),
+		&arktest.FakeAuditLog{},
 	).(*backupDeletionController)
 
 	// Error splitting key
@@ -171,6 +175,7 @@ type backupDeletionControllerTestData struct {
 	sharedInformers informers.SharedInformerFactory
 	backupService   *arktest.BackupService
 	snapshotService *arktest.FakeSnapshotService
+	auditLog        *arktest.FakeAuditLog
 	controller      *backupDeletionController
 	req             *v1.DeleteBackupRequest
 }
@@ -180,6 +185,7 @@ func setupBackupDeletionControllerTest(objects ...runtime.Object) *backupDeletio
 	sharedInformers := informers.NewSharedInformerFactory(client, 0)
 	backupService := &arktest.BackupService{}
 	snapshotService := &arktest.FakeSnapshotService{SnapshotsTaken: sets.NewString()}
+	auditLog := &arktest.FakeAuditLog{}
 	req := pkgbackup.NewDeleteBackupRequest("foo", "uid")
 
 	data := &backupDeletionControllerTestData{
@@ -187,6 +193,7 @@ func setupBackupDeletionControllerTest(objects ...runtime.Object) *backupDeletio
 		sharedInformers: sharedInformers,
 		backupService:   backupService,
 		snapshotService: snapshotService,
+		auditLog:        auditLog,
 		controller: NewBackupDeletionController(
 			arktest.NewLogger(),
 			sharedInformers.Ark().V1().DeleteBackupRequests(),
@@ -197,7 +204,8 @@ func setupBackupDeletionControllerTest(objects ...runtime.Object) *backupDeletio
 			"bucket",
 			sharedInformers.Ark().V1().Restores(),
 			client.ArkV1(), // restoreClient
-			NewBackupTracker(),
+			NewOperationTracker(),
+			auditLog,
 		).(*backupDeletionController),
 
 		req: req,
@@ -234,7 +242,7 @@ func TestBackupDeletionControllerProcessRequest(t *testing.T) {
 		td := setupBackupDeletionControllerTest()
 		defer td.backupService.AssertExpectations(t)
 
-		td.controller.backupTracker.Add(td.req.Namespace, td.req.Spec.BackupName)
+		td.controller.operationTracker.Add(v1.OperationKindBackup, td.req.Namespace, td.req.Spec.BackupName)
 
 		err := td.controller.processRequest(td.req)
 		require.NoError(t, err)
@@ -360,6 +368,7 @@ func TestBackupDeletionControllerProcessRequest(t *testing.T) {
 	t.Run("full delete, no errors", func(t *testing.T) {
 		backup := arktest.NewTestBackup().WithName("foo").WithSnapshot("pv-1", "snap-1").Backup
 		backup.UID = "uid"
+		backup.Status.VolumeBackups["pv-1"].CopySnapshotID = "us-west-2/snap-1"
 
 		restore1 := arktest.NewTestRestore("heptio-ark", "restore-1", v1.RestorePhaseCompleted).WithBackup("foo").Restore
 		restore2 := arktest.NewTestRestore("heptio-ark", "restore-2", v1.RestorePhaseCompleted).WithBackup("foo").Restore
@@ -379,7 +388,7 @@ func TestBackupDeletionControllerProcessRequest(t *testing.T) {
 		td.client.PrependReactor("get", "backups", func(action core.Action) (bool, runtime.Object, error) {
 			return true, backup, nil
 		})
-		td.snapshotService.SnapshotsTaken.Insert("snap-1")
+		td.snapshotService.SnapshotsTaken.Insert("snap-1", "us-west-2/snap-1")
 
 		td.client.PrependReactor("patch", "deletebackuprequests", func(action core.Action) (bool, runtime.Object, error) {
 			return true, td.req, nil
@@ -389,7 +398,9 @@ func TestBackupDeletionControllerProcessRequest(t *testing.T) {
 			return true, backup, nil
 		})
 
-		td.backupService.On("DeleteBackupDir", td.controller.bucket, td.req.Spec.BackupName).Return(nil)
+		td.backupService.On("DeleteBackupDir", td.controller.bucket, td.req.Spec.BackupName).Return([]v1.ArtifactDeletionResult{
+			{Artifact: v1.BackupArtifactTarball, Name: "foo/foo.tar.gz", Phase: v1.ArtifactDeletionPhaseDeleted},
+		}, nil)
 
 		err := td.controller.processRequest(td.req)
 		require.NoError(t, err)
@@ -437,7 +448,13 @@ func TestBackupDeletionControllerProcessRequest(t *testing.T) {
 				v1.SchemeGroupVersion.WithResource("deletebackuprequests"),
 				td.req.Namespace,
 				td.req.Name,
-				[]byte(`{"status":{"phase":"Processed"}}`),
+				[]byte(`{"status":{"artifacts":[`+
+					`{"artifact":"Snapshot","name":"snap-1","phase":"Deleted"},`+
+					`{"artifact":"Snapshot","name":"us-west-2/snap-1","phase":"Deleted"},`+
+					`{"artifact":"Tarball","name":"foo/foo.tar.gz","phase":"Deleted"},`+
+					`{"artifact":"Restore","name":"heptio-ark/restore-1","phase":"Deleted"},`+
+					`{"artifact":"Restore","name":"heptio-ark/restore-2","phase":"Deleted"},`+
+					`{"artifact":"Backup","name":"heptio-ark/foo","phase":"Deleted"}],"phase":"Processed"}}`),
 			),
 			core.NewDeleteCollectionAction(
 				v1.SchemeGroupVersion.WithResource("deletebackuprequests"),
@@ -448,9 +465,176 @@ func TestBackupDeletionControllerProcessRequest(t *testing.T) {
 
 		arktest.CompareActions(t, expectedActions, td.client.Actions())
 
-		// Make sure snapshot was deleted
+		// Make sure snapshot and its copy were deleted
 		assert.Equal(t, 0, td.snapshotService.SnapshotsTaken.Len())
+
+		expectedAudit := []audit.Entry{
+			{Action: audit.ActionDelete, Kind: audit.KindVolumeSnapshot, Name: "snap-1", Backup: "foo"},
+			{Action: audit.ActionDelete, Kind: audit.KindVolumeSnapshot, Name: "us-west-2/snap-1", Backup: "foo"},
+			{Action: audit.ActionDelete, Kind: audit.KindBackupStorage, Name: "foo", Backup: "foo"},
+		}
+		assert.Equal(t, expectedAudit, td.auditLog.Entries())
+	})
+
+	t.Run("snapshots only deletion policy retains backup files", func(t *testing.T) {
+		backup := arktest.NewTestBackup().WithName("foo").WithSnapshot("pv-1", "snap-1").Backup
+		backup.Spec.DeletionPolicy = v1.BackupDeletionPolicySnapshotsOnly
+
+		td := setupBackupDeletionControllerTest(backup)
+		defer td.backupService.AssertExpectations(t)
+
+		td.client.PrependReactor("get", "backups", func(action core.Action) (bool, runtime.Object, error) {
+			return true, backup, nil
+		})
+		td.snapshotService.SnapshotsTaken.Insert("snap-1")
+
+		td.client.PrependReactor("patch", "deletebackuprequests", func(action core.Action) (bool, runtime.Object, error) {
+			return true, td.req, nil
+		})
+		td.client.PrependReactor("patch", "backups", func(action core.Action) (bool, runtime.Object, error) {
+			return true, backup, nil
+		})
+
+		// DeleteBackupDir isn't expected to be called, and the backup is marked as deleted in
+		// object storage so that it isn't synced back into the cluster
+		td.backupService.On("UploadBackup", td.controller.bucket, mock.MatchedBy(func(b *v1.Backup) bool {
+			return b.Annotations[v1.DeletedBackupAnnotation] == string(v1.BackupDeletionPolicySnapshotsOnly)
+		}), mock.Anything, nil, nil).Return(nil)
+
+		err := td.controller.processRequest(td.req)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, td.snapshotService.SnapshotsTaken.Len())
+		assert.Equal(t, []v1.ArtifactDeletionResult{
+			{Artifact: v1.BackupArtifactSnapshot, Name: "snap-1", Phase: v1.ArtifactDeletionPhaseDeleted},
+			{Artifact: v1.BackupArtifactTarball, Name: "foo", Phase: v1.ArtifactDeletionPhaseRetained},
+			{Artifact: v1.BackupArtifactLogs, Name: "foo", Phase: v1.ArtifactDeletionPhaseRetained},
+			{Artifact: v1.BackupArtifactBackup, Name: "heptio-ark/foo", Phase: v1.ArtifactDeletionPhaseDeleted},
+		}, td.req.Status.Artifacts)
+	})
+
+	t.Run("failed snapshot deletion retains the backup", func(t *testing.T) {
+		backup := arktest.NewTestBackup().WithName("foo").WithSnapshot("pv-1", "snap-1").Backup
+
+		td := setupBackupDeletionControllerTest(backup)
+		defer td.backupService.AssertExpectations(t)
+
+		td.client.PrependReactor("get", "backups", func(action core.Action) (bool, runtime.Object, error) {
+			return true, backup, nil
+		})
+		td.client.PrependReactor("patch", "deletebackuprequests", func(action core.Action) (bool, runtime.Object, error) {
+			return true, td.req, nil
+		})
+		td.client.PrependReactor("patch", "backups", func(action core.Action) (bool, runtime.Object, error) {
+			return true, backup, nil
+		})
+
+		// snap-1 was never taken, so deleting it fails
+		td.backupService.On("DeleteBackupDir", td.controller.bucket, td.req.Spec.BackupName).Return([]v1.ArtifactDeletionResult{
+			{Artifact: v1.BackupArtifactTarball, Name: "foo/foo.tar.gz", Phase: v1.ArtifactDeletionPhaseDeleted},
+		}, nil)
+
+		err := td.controller.processRequest(td.req)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"error deleting snapshot snap-1: snapshot not found"}, td.req.Status.Errors)
+		assert.Equal(t, []v1.ArtifactDeletionResult{
+			{Artifact: v1.BackupArtifactSnapshot, Name: "snap-1", Phase: v1.ArtifactDeletionPhaseFailed, Error: "error deleting snapshot snap-1: snapshot not found"},
+			{Artifact: v1.BackupArtifactTarball, Name: "foo/foo.tar.gz", Phase: v1.ArtifactDeletionPhaseDeleted},
+			{Artifact: v1.BackupArtifactBackup, Name: "heptio-ark/foo", Phase: v1.ArtifactDeletionPhaseRetained},
+		}, td.req.Status.Artifacts)
+	})
+
+	t.Run("request's deletion policy overrides backup's", func(t *testing.T) {
+		backup := arktest.NewTestBackup().WithName("foo").WithSnapshot("pv-1", "snap-1").Backup
+		backup.Spec.DeletionPolicy = v1.BackupDeletionPolicySnapshotsOnly
+
+		td := setupBackupDeletionControllerTest(backup)
+		td.controller.snapshotService = nil
+		defer td.backupService.AssertExpectations(t)
+
+		td.req.Spec.DeletionPolicy = v1.BackupDeletionPolicyObjectStorageOnly
+
+		td.client.PrependReactor("get", "backups", func(action core.Action) (bool, runtime.Object, error) {
+			return true, backup, nil
+		})
+		td.client.PrependReactor("patch", "deletebackuprequests", func(action core.Action) (bool, runtime.Object, error) {
+			return true, td.req, nil
+		})
+		td.client.PrependReactor("patch", "backups", func(action core.Action) (bool, runtime.Object, error) {
+			return true, backup, nil
+		})
+
+		// snapshots are retained, so the lack of a snapshot service doesn't prevent deletion
+		td.backupService.On("DeleteBackupDir", td.controller.bucket, td.req.Spec.BackupName).Return([]v1.ArtifactDeletionResult{
+			{Artifact: v1.BackupArtifactMetadata, Name: "foo/ark-backup.json", Phase: v1.ArtifactDeletionPhaseDeleted},
+			{Artifact: v1.BackupArtifactTarball, Name: "foo/foo.tar.gz", Phase: v1.ArtifactDeletionPhaseDeleted},
+		}, nil)
+		// the metadata file is put back, marked as deleted, so that the retained snapshots
+		// aren't treated as orphans
+		td.backupService.On("UploadBackup", td.controller.bucket, mock.MatchedBy(func(b *v1.Backup) bool {
+			return b.Annotations[v1.DeletedBackupAnnotation] == string(v1.BackupDeletionPolicyObjectStorageOnly)
+		}), mock.Anything, nil, nil).Return(nil)
+
+		err := td.controller.processRequest(td.req)
+		require.NoError(t, err)
+
+		assert.Equal(t, []v1.ArtifactDeletionResult{
+			{Artifact: v1.BackupArtifactSnapshot, Name: "snap-1", Phase: v1.ArtifactDeletionPhaseRetained},
+			{Artifact: v1.BackupArtifactMetadata, Name: "foo/ark-backup.json", Phase: v1.ArtifactDeletionPhaseRetained},
+			{Artifact: v1.BackupArtifactTarball, Name: "foo/foo.tar.gz", Phase: v1.ArtifactDeletionPhaseDeleted},
+			{Artifact: v1.BackupArtifactBackup, Name: "heptio-ark/foo", Phase: v1.ArtifactDeletionPhaseDeleted},
+		}, td.req.Status.Artifacts)
 	})
+
+	t.Run("invalid deletion policy", func(t *testing.T) {
+		td := setupBackupDeletionControllerTest()
+		defer td.backupService.AssertExpectations(t)
+
+		td.req.Spec.DeletionPolicy = "Everything"
+
+		td.client.PrependReactor("get", "backups", func(action core.Action) (bool, runtime.Object, error) {
+			return true, arktest.NewTestBackup().WithName("foo").Backup, nil
+		})
+		td.client.PrependReactor("patch", "deletebackuprequests", func(action core.Action) (bool, runtime.Object, error) {
+			return true, td.req, nil
+		})
+
+		err := td.controller.processRequest(td.req)
+		require.NoError(t, err)
+
+		actions := td.client.Actions()
+		require.NotEmpty(t, actions)
+		assert.Equal(t, core.NewPatchAction(
+			v1.SchemeGroupVersion.WithResource("deletebackuprequests"),
+			td.req.Namespace,
+			td.req.Name,
+			[]byte(`{"status":{"errors":["invalid deletion policy \"Everything\""],"phase":"Processed"}}`),
+		), actions[len(actions)-1])
+	})
+}
+
+func TestBackupDeletionControllerRecordFailedRequest(t *testing.T) {
+	td := setupBackupDeletionControllerTest()
+	td.req.Status.Phase = v1.DeleteBackupRequestPhaseInProgress
+	require.NoError(t, td.sharedInformers.Ark().V1().DeleteBackupRequests().Informer().GetStore().Add(td.req))
+
+	td.client.PrependReactor("patch", "deletebackuprequests", func(action core.Action) (bool, runtime.Object, error) {
+		return true, td.req, nil
+	})
+
+	td.controller.recordFailedRequest("heptio-ark/foo-abcde", errors.New("bad"))
+
+	expectedActions := []core.Action{
+		core.NewPatchAction(
+			v1.SchemeGroupVersion.WithResource("deletebackuprequests"),
+			td.req.Namespace,
+			td.req.Name,
+			[]byte(`{"status":{"errors":["gave up after repeated errors: bad"],"phase":"Processed"}}`),
+		),
+	}
+
+	assert.Equal(t, expectedActions, td.client.Actions())
 }
 
 func TestBackupDeletionControllerDeleteExpiredRequests(t *testing.T) {
@@ -576,7 +760,8 @@ func TestBackupDeletionControllerDeleteExpiredRequests(t *testing.T) {
 				"bucket",
 				sharedInformers.Ark().V1().Restores(),
 				client.ArkV1(), // restoreClient
-				NewBackupTracker(),
+				NewOperationTracker(),
+				&arktest.FakeAuditLog{},
 			).(*backupDeletionController)
 
 			fakeClock := &clock.FakeClock{}
@@ -18,14 +18,21 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
 	kuberrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	pkgbackup "github.com/heptio/ark/pkg/backup"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
 	"github.com/heptio/ark/pkg/util/kube"
@@ -33,20 +40,29 @@ import (
 )
 
 type backupSyncController struct {
-	client        arkv1client.BackupsGetter
-	backupService cloudprovider.BackupService
-	bucket        string
-	syncPeriod    time.Duration
-	namespace     string
-	logger        logrus.FieldLogger
+	client               arkv1client.BackupsGetter
+	configClient         arkv1client.ConfigsGetter
+	backupService        cloudprovider.BackupService
+	bucket               string
+	syncPeriod           time.Duration
+	orphanedBackupAction api.OrphanedBackupAction
+	namespace            string
+	configName           string
+	restoreOnly          bool
+	logger               logrus.FieldLogger
+	clock                clock.Clock
 }
 
 func NewBackupSyncController(
 	client arkv1client.BackupsGetter,
+	configClient arkv1client.ConfigsGetter,
 	backupService cloudprovider.BackupService,
 	bucket string,
 	syncPeriod time.Duration,
+	orphanedBackupAction api.OrphanedBackupAction,
 	namespace string,
+	configName string,
+	restoreOnly bool,
 	logger logrus.FieldLogger,
 ) Interface {
 	if syncPeriod < time.Minute {
@@ -54,12 +70,17 @@ func NewBackupSyncController(
 		syncPeriod = time.Minute
 	}
 	return &backupSyncController{
-		client:        client,
-		backupService: backupService,
-		bucket:        bucket,
-		syncPeriod:    syncPeriod,
-		namespace:     namespace,
-		logger:        logger,
+		client:               client,
+		configClient:         configClient,
+		backupService:        backupService,
+		bucket:               bucket,
+		syncPeriod:           syncPeriod,
+		orphanedBackupAction: orphanedBackupAction,
+		namespace:            namespace,
+		configName:           configName,
+		restoreOnly:          restoreOnly,
+		logger:               logger,
+		clock:                clock.RealClock{},
 	}
 }
 
@@ -75,26 +96,176 @@ func (c *backupSyncController) Run(ctx context.Context, workers int) error {
 const gcFinalizer = "gc.ark.heptio.com"
 
 func (c *backupSyncController) run() {
+	c.validateAccess()
+
 	c.logger.Info("Syncing backups from object storage")
-	backups, err := c.backupService.GetAllBackups(c.bucket)
+	var backupCount int
+	err := c.backupService.ForEachBackup(c.bucket, func(cloudBackup *api.Backup) error {
+		backupCount++
+		c.syncBackup(cloudBackup)
+		return nil
+	})
 	if err != nil {
 		c.logger.WithError(err).Error("error listing backups")
 		return
 	}
-	c.logger.WithField("backupCount", len(backups)).Info("Got backups from object storage")
+	c.logger.WithField("backupCount", backupCount).Info("Synced backups from object storage")
+
+	if c.orphanedBackupAction != api.OrphanedBackupActionIgnore {
+		c.reconcileOrphanedBackups()
+	}
+}
+
+// syncBackup creates an Ark backup in the server's namespace for a backup read from object
+// storage, unless it can't or shouldn't be synced.
+func (c *backupSyncController) syncBackup(cloudBackup *api.Backup) {
+	logContext := c.logger.WithField("backup", kube.NamespaceAndName(cloudBackup))
+
+	// backups this server can't read aren't synced, so they can't be restored
+	// or deleted by it
+	if err := pkgbackup.CheckFormatVersion(cloudBackup); err != nil {
+		logContext.WithError(err).Error("Not syncing backup")
+		return
+	}
+
+	// backups that were deleted with some of their artifacts retained are only kept in
+	// object storage so that those artifacts aren't treated as orphans
+	if policy, deleted := cloudBackup.Annotations[api.DeletedBackupAnnotation]; deleted {
+		logContext.WithField("deletionPolicy", policy).Debug("Not syncing deleted backup")
+		return
+	}
+
+	logContext.Info("Syncing backup")
+
+	// If we're syncing backups made by pre-0.8.0 versions, the server removes all finalizers
+	// faster than the sync finishes. Just process them as we find them.
+	cloudBackup.Finalizers = stringslice.Except(cloudBackup.Finalizers, gcFinalizer)
+
+	cloudBackup.Namespace = c.namespace
+	cloudBackup.ResourceVersion = ""
+	if _, err := c.client.Backups(cloudBackup.Namespace).Create(cloudBackup); err != nil && !kuberrs.IsAlreadyExists(err) {
+		logContext.WithError(errors.WithStack(err)).Error("Error syncing backup from object storage")
+	}
+}
+
+// validateAccess checks that the backup storage bucket can be accessed and records the result
+// in the Config's status. In restore-only mode, the bucket only needs to be readable.
+func (c *backupSyncController) validateAccess() {
+	accessErr := c.backupService.ValidateAccess(c.bucket, c.restoreOnly)
+	if accessErr != nil {
+		c.logger.WithError(accessErr).WithField("bucket", c.bucket).Error("Backup storage is not accessible")
+	}
+
+	// without a Config, e.g. when the server's settings all come from flags, there's no status
+	// to record the result in
+	if c.configName == "" {
+		return
+	}
+
+	if err := UpdateBackupStorageStatus(c.configClient, c.namespace, c.configName, accessErr, c.clock.Now()); err != nil {
+		c.logger.WithError(err).Error("Error updating config status")
+	}
+}
+
+// UpdateBackupStorageStatus records the result of checking access to backup storage, accessErr,
+// in the status of the named Config.
+func UpdateBackupStorageStatus(client arkv1client.ConfigsGetter, namespace, name string, accessErr error, now time.Time) error {
+	status := api.BackupStorageStatus{
+		Phase:         api.BackupStoragePhaseAvailable,
+		LastCheckTime: metav1.NewTime(now),
+	}
+	if accessErr != nil {
+		status.Phase = api.BackupStoragePhaseUnavailable
+		status.Message = accessErr.Error()
+	}
+
+	// the message is always included so that it's cleared once the bucket is accessible
+	patch := map[string]interface{}{
+		"status": map[string]interface{}{
+			"backupStorage": map[string]interface{}{
+				"phase":         status.Phase,
+				"lastCheckTime": status.LastCheckTime,
+				"message":       status.Message,
+			},
+		},
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling config status patch")
+	}
+
+	if _, err := client.Configs(namespace).Patch(name, types.MergePatchType, patchBytes); err != nil {
+		return errors.Wrap(err, "error patching config")
+	}
+
+	return nil
+}
+
+// reconcileOrphanedBackups finds Completed Backup API objects whose files no longer exist
+// in object storage, and labels or deletes them according to c.orphanedBackupAction.
+// Labeled backups whose files exist again are unlabeled.
+func (c *backupSyncController) reconcileOrphanedBackups() {
+	// List the Backup API objects before object storage: backups are uploaded before they're
+	// marked Completed, so any Completed backup listed here that has files in object storage
+	// is guaranteed to be found when listing object storage below.
+	backups, err := c.client.Backups(c.namespace).List(metav1.ListOptions{})
+	if err != nil {
+		c.logger.WithError(errors.WithStack(err)).Error("Error listing backups")
+		return
+	}
+
+	dirs, err := c.backupService.ListBackupDirs(c.bucket)
+	if err != nil {
+		c.logger.WithError(err).Error("Error listing backups in object storage")
+		return
+	}
+	storedBackups := sets.NewString(dirs...)
+
+	for i := range backups.Items {
+		backup := &backups.Items[i]
+		// dry-run backups only upload a log, so they're never expected to be in object storage
+		if (backup.Status.Phase != api.BackupPhaseCompleted && backup.Status.Phase != api.BackupPhasePartiallyFailed) || backup.DeletionTimestamp != nil || backup.Spec.DryRun {
+			continue
+		}
+
+		logContext := c.logger.WithField("backup", kube.NamespaceAndName(backup))
+		_, labeled := backup.Labels[api.MissingFromStorageLabel]
+
+		if storedBackups.Has(backup.Name) {
+			if labeled {
+				logContext.Info("Backup's files exist in object storage again, removing label")
+
+				updated := backup.DeepCopy()
+				delete(updated.Labels, api.MissingFromStorageLabel)
+				if _, err := patchBackup(backup, updated, c.client); err != nil {
+					logContext.WithError(err).Error("Error removing label from backup")
+				}
+			}
+			continue
+		}
 
-	for _, cloudBackup := range backups {
-		logContext := c.logger.WithField("backup", kube.NamespaceAndName(cloudBackup))
-		logContext.Info("Syncing backup")
+		switch c.orphanedBackupAction {
+		case api.OrphanedBackupActionDelete:
+			logContext.Info("Backup's files no longer exist in object storage, deleting backup")
 
-		// If we're syncing backups made by pre-0.8.0 versions, the server removes all finalizers
-		// faster than the sync finishes. Just process them as we find them.
-		cloudBackup.Finalizers = stringslice.Except(cloudBackup.Finalizers, gcFinalizer)
+			if err := c.client.Backups(backup.Namespace).Delete(backup.Name, nil); err != nil && !kuberrs.IsNotFound(err) {
+				logContext.WithError(errors.WithStack(err)).Error("Error deleting backup")
+			}
+		case api.OrphanedBackupActionLabel:
+			if labeled {
+				continue
+			}
+			logContext.Info("Backup's files no longer exist in object storage, labeling backup")
 
-		cloudBackup.Namespace = c.namespace
-		cloudBackup.ResourceVersion = ""
-		if _, err := c.client.Backups(cloudBackup.Namespace).Create(cloudBackup); err != nil && !kuberrs.IsAlreadyExists(err) {
-			logContext.WithError(errors.WithStack(err)).Error("Error syncing backup from object storage")
+			updated := backup.DeepCopy()
+			if updated.Labels == nil {
+				updated.Labels = make(map[string]string)
+			}
+			updated.Labels[api.MissingFromStorageLabel] = "true"
+			if _, err := patchBackup(backup, updated, c.client); err != nil {
+				logContext.WithError(err).Error("Error labeling backup")
+			}
 		}
 	}
 }
@@ -22,7 +22,10 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
 	core "k8s.io/client-go/testing"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
@@ -33,17 +36,18 @@ import (
 
 func TestBackupSyncControllerRun(t *testing.T) {
 	tests := []struct {
-		name               string
-		getAllBackupsError error
-		cloudBackups       []*v1.Backup
-		namespace          string
+		name             string
+		listBackupsError error
+		cloudBackups     []*v1.Backup
+		unsyncedBackups  []*v1.Backup
+		namespace        string
 	}{
 		{
 			name: "no cloud backups",
 		},
 		{
-			name:               "backup service returns error on GetAllBackups",
-			getAllBackupsError: errors.New("getAllBackups"),
+			name:             "backup service returns error listing backups",
+			listBackupsError: errors.New("listBackups"),
 		},
 		{
 			name: "normal case",
@@ -76,6 +80,27 @@ func TestBackupSyncControllerRun(t *testing.T) {
 			},
 			namespace: "heptio-ark",
 		},
+		{
+			name: "backups with a newer format version aren't synced",
+			cloudBackups: []*v1.Backup{
+				arktest.NewTestBackup().WithNamespace("ns-1").WithName("backup-1").WithVersion(2).Backup,
+			},
+			unsyncedBackups: []*v1.Backup{
+				arktest.NewTestBackup().WithNamespace("ns-1").WithName("backup-2").WithVersion(3).Backup,
+			},
+			namespace: "ns-1",
+		},
+		{
+			name: "backups deleted with retained artifacts aren't synced",
+			cloudBackups: []*v1.Backup{
+				arktest.NewTestBackup().WithNamespace("ns-1").WithName("backup-1").Backup,
+			},
+			unsyncedBackups: []*v1.Backup{
+				arktest.NewTestBackup().WithNamespace("ns-1").WithName("backup-2").
+					WithAnnotation(v1.DeletedBackupAnnotation, string(v1.BackupDeletionPolicySnapshotsOnly)).Backup,
+			},
+			namespace: "ns-1",
+		},
 	}
 
 	for _, test := range tests {
@@ -88,14 +113,30 @@ func TestBackupSyncControllerRun(t *testing.T) {
 
 			c := NewBackupSyncController(
 				client.ArkV1(),
+				fake.NewSimpleClientset().ArkV1(),
 				bs,
 				"bucket",
 				time.Duration(0),
+				v1.OrphanedBackupActionIgnore,
 				test.namespace,
+				"default",
+				false,
 				logger,
 			).(*backupSyncController)
 
-			bs.On("GetAllBackups", "bucket").Return(test.cloudBackups, test.getAllBackupsError)
+			bs.On("ValidateAccess", "bucket", false).Return(nil)
+			storedBackups := append(append([]*v1.Backup{}, test.cloudBackups...), test.unsyncedBackups...)
+			bs.On("ForEachBackup", "bucket", mock.Anything).Return(func(bucket string, fn func(*v1.Backup) error) error {
+				if test.listBackupsError != nil {
+					return test.listBackupsError
+				}
+				for _, backup := range storedBackups {
+					if err := fn(backup); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
 
 			c.run()
 
@@ -120,3 +161,191 @@ func TestBackupSyncControllerRun(t *testing.T) {
 		})
 	}
 }
+
+func TestBackupSyncControllerValidateAccess(t *testing.T) {
+	tests := []struct {
+		name          string
+		configName    string
+		accessErr     error
+		expectedPatch []string
+	}{
+		{
+			name:          "accessible bucket is recorded as available",
+			configName:    "default",
+			expectedPatch: []string{`{"status":{"backupStorage":{"lastCheckTime":"2018-06-01T12:00:00Z","message":"","phase":"Available"}}}`},
+		},
+		{
+			name:          "inaccessible bucket is recorded as unavailable with the error",
+			configName:    "default",
+			accessErr:     errors.New("error writing to bucket: AccessDenied"),
+			expectedPatch: []string{`{"status":{"backupStorage":{"lastCheckTime":"2018-06-01T12:00:00Z","message":"error writing to bucket: AccessDenied","phase":"Unavailable"}}}`},
+		},
+		{
+			name:      "without a Config, nothing is recorded",
+			accessErr: errors.New("error writing to bucket: AccessDenied"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				bs           = &arktest.BackupService{}
+				configClient = fake.NewSimpleClientset()
+				logger       = arktest.NewLogger()
+			)
+			defer bs.AssertExpectations(t)
+
+			c := NewBackupSyncController(
+				fake.NewSimpleClientset().ArkV1(),
+				configClient.ArkV1(),
+				bs,
+				"bucket",
+				time.Duration(0),
+				v1.OrphanedBackupActionIgnore,
+				"heptio-ark",
+				test.configName,
+				false,
+				logger,
+			).(*backupSyncController)
+			c.clock = clock.NewFakeClock(time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC))
+
+			var patches []string
+			configClient.PrependReactor("patch", "configs", func(action core.Action) (bool, runtime.Object, error) {
+				patchAction := action.(core.PatchAction)
+				assert.Equal(t, "heptio-ark", patchAction.GetNamespace())
+				assert.Equal(t, "default", patchAction.GetName())
+				patches = append(patches, string(patchAction.GetPatch()))
+				return true, nil, nil
+			})
+
+			bs.On("ValidateAccess", "bucket", false).Return(test.accessErr)
+
+			c.validateAccess()
+
+			assert.Equal(t, test.expectedPatch, patches)
+		})
+	}
+}
+
+func TestBackupSyncControllerReconcileOrphanedBackups(t *testing.T) {
+	tests := []struct {
+		name            string
+		action          v1.OrphanedBackupAction
+		clusterBackups  []*v1.Backup
+		storedBackups   []string
+		expectedPatches map[string]string
+		expectedDeletes []string
+	}{
+		{
+			name:   "backups that exist in object storage are left as-is",
+			action: v1.OrphanedBackupActionLabel,
+			clusterBackups: []*v1.Backup{
+				arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-1").WithPhase(v1.BackupPhaseCompleted).Backup,
+			},
+			storedBackups: []string{"backup-1"},
+		},
+		{
+			name:   "label action labels completed backups missing from object storage",
+			action: v1.OrphanedBackupActionLabel,
+			clusterBackups: []*v1.Backup{
+				arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-1").WithPhase(v1.BackupPhaseCompleted).Backup,
+				arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-2").WithPhase(v1.BackupPhaseCompleted).Backup,
+			},
+			storedBackups: []string{"backup-1"},
+			expectedPatches: map[string]string{
+				"backup-2": `{"metadata":{"labels":{"ark.heptio.com/missing-from-storage":"true"}}}`,
+			},
+		},
+		{
+			name:   "label action doesn't relabel backups",
+			action: v1.OrphanedBackupActionLabel,
+			clusterBackups: []*v1.Backup{
+				arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-1").WithPhase(v1.BackupPhaseCompleted).
+					WithLabel(v1.MissingFromStorageLabel, "true").Backup,
+			},
+		},
+		{
+			name:   "label is removed from backups whose files reappear",
+			action: v1.OrphanedBackupActionLabel,
+			clusterBackups: []*v1.Backup{
+				arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-1").WithPhase(v1.BackupPhaseCompleted).
+					WithLabel(v1.MissingFromStorageLabel, "true").WithLabel("foo", "bar").Backup,
+			},
+			storedBackups: []string{"backup-1"},
+			expectedPatches: map[string]string{
+				"backup-1": `{"metadata":{"labels":{"ark.heptio.com/missing-from-storage":null}}}`,
+			},
+		},
+		{
+			name:   "delete action deletes completed backups missing from object storage",
+			action: v1.OrphanedBackupActionDelete,
+			clusterBackups: []*v1.Backup{
+				arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-1").WithPhase(v1.BackupPhaseCompleted).Backup,
+				arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-2").WithPhase(v1.BackupPhaseCompleted).Backup,
+			},
+			storedBackups:   []string{"backup-2"},
+			expectedDeletes: []string{"backup-1"},
+		},
+		{
+			name:   "backups that aren't completed or are being deleted are ignored",
+			action: v1.OrphanedBackupActionDelete,
+			clusterBackups: []*v1.Backup{
+				arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-1").WithPhase(v1.BackupPhaseInProgress).Backup,
+				arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-2").WithPhase(v1.BackupPhaseFailed).Backup,
+				arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-3").WithPhase(v1.BackupPhaseCompleted).
+					WithDeletionTimestamp(time.Now()).Backup,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				bs      = &arktest.BackupService{}
+				objects []runtime.Object
+				logger  = arktest.NewLogger()
+				patches = make(map[string]string)
+				deletes []string
+			)
+
+			for _, backup := range test.clusterBackups {
+				objects = append(objects, backup)
+			}
+			client := fake.NewSimpleClientset(objects...)
+
+			client.PrependReactor("patch", "backups", func(action core.Action) (bool, runtime.Object, error) {
+				patchAction := action.(core.PatchAction)
+				patches[patchAction.GetName()] = string(patchAction.GetPatch())
+				return true, nil, nil
+			})
+			client.PrependReactor("delete", "backups", func(action core.Action) (bool, runtime.Object, error) {
+				deletes = append(deletes, action.(core.DeleteAction).GetName())
+				return true, nil, nil
+			})
+
+			c := NewBackupSyncController(
+				client.ArkV1(),
+				client.ArkV1(),
+				bs,
+				"bucket",
+				time.Duration(0),
+				test.action,
+				"heptio-ark",
+				"default",
+				false,
+				logger,
+			).(*backupSyncController)
+
+			bs.On("ListBackupDirs", "bucket").Return(test.storedBackups, nil)
+
+			c.reconcileOrphanedBackups()
+
+			if test.expectedPatches == nil {
+				test.expectedPatches = map[string]string{}
+			}
+			assert.Equal(t, test.expectedPatches, patches)
+			assert.Equal(t, test.expectedDeletes, deletes)
+			bs.AssertExpectations(t)
+		})
+	}
+}
@@ -220,23 +220,23 @@ func (c *downloadRequestController) generatePreSignedURL(downloadRequest *v1.Dow
 	update := downloadRequest.DeepCopy()
 
 	var (
-		directory string
-		err       error
+		backupName string
+		err        error
 	)
 
 	switch downloadRequest.Spec.Target.Kind {
-	case v1.DownloadTargetKindRestoreLog, v1.DownloadTargetKindRestoreResults:
+	case v1.DownloadTargetKindRestoreLog, v1.DownloadTargetKindRestoreLogIndex, v1.DownloadTargetKindRestoreResults, v1.DownloadTargetKindRestorePlan:
 		restore, err := c.restoreLister.Restores(downloadRequest.Namespace).Get(downloadRequest.Spec.Target.Name)
 		if err != nil {
 			return errors.Wrap(err, "error getting Restore")
 		}
 
-		directory = restore.Spec.BackupName
+		backupName = restore.Spec.BackupName
 	default:
-		directory = downloadRequest.Spec.Target.Name
+		backupName = downloadRequest.Spec.Target.Name
 	}
 
-	update.Status.DownloadURL, err = c.backupService.CreateSignedURL(downloadRequest.Spec.Target, c.bucket, directory, signedURLTTL)
+	update.Status.DownloadURL, err = c.backupService.CreateSignedURL(downloadRequest.Spec.Target, c.bucket, backupName, signedURLTTL)
 	if err != nil {
 		return err
 	}
@@ -86,6 +86,17 @@ func TestProcessDownloadRequest(t *testing.T) {
 			expectedPhase: v1.DownloadRequestPhaseProcessed,
 			expectedURL:   "signedURL",
 		},
+		{
+			name:          "restore log index request is signed in the restore's backup's directory",
+			key:           "heptio-ark/dr1",
+			phase:         v1.DownloadRequestPhaseNew,
+			targetKind:    v1.DownloadTargetKindRestoreLogIndex,
+			targetName:    "backup1-20170912150214",
+			restore:       arktest.NewTestRestore(v1.DefaultNamespace, "backup1-20170912150214", v1.RestorePhaseCompleted).WithBackup("backup1").Restore,
+			expectedDir:   "backup1",
+			expectedPhase: v1.DownloadRequestPhaseProcessed,
+			expectedURL:   "signedURL",
+		},
 		{
 			name:          "restore log request with phase New gets a url",
 			key:           "heptio-ark/dr1",
@@ -23,27 +23,66 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 )
 
+// retryPolicy controls how a genericController retries keys whose syncHandler
+// returns an error.
+type retryPolicy struct {
+	// baseDelay is how long to wait before retrying a key for the first time.
+	// The delay doubles with each subsequent retry of the key, up to maxDelay.
+	baseDelay time.Duration
+	maxDelay  time.Duration
+
+	// maxRetries is how many times a key is retried before the controller gives
+	// up on it. Zero means keys are retried until they succeed.
+	maxRetries int
+}
+
+// defaultRetryPolicy retries keys forever, with the same backoff as
+// workqueue.DefaultControllerRateLimiter.
+var defaultRetryPolicy = retryPolicy{
+	baseDelay: 5 * time.Millisecond,
+	maxDelay:  1000 * time.Second,
+}
+
 type genericController struct {
 	name             string
 	queue            workqueue.RateLimitingInterface
+	maxRetries       int
 	logger           logrus.FieldLogger
 	syncHandler      func(key string) error
 	resyncFunc       func()
 	resyncPeriod     time.Duration
 	cacheSyncWaiters []cache.InformerSynced
+
+	// failedHandler, if set, is called with a key and its last error when the controller
+	// gives up on retrying it, so that the failure can be recorded (e.g. in the status of
+	// the API object the key refers to). The key isn't processed again until it's
+	// re-added to the queue, e.g. because its API object was updated.
+	failedHandler func(key string, err error)
 }
 
 func newGenericController(name string, logger logrus.FieldLogger) *genericController {
+	return newGenericControllerWithRetryPolicy(name, logger, defaultRetryPolicy)
+}
+
+func newGenericControllerWithRetryPolicy(name string, logger logrus.FieldLogger, policy retryPolicy) *genericController {
+	rateLimiter := workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(policy.baseDelay, policy.maxDelay),
+		// 10 qps, 100 bucket size, the same overall limit as workqueue.DefaultControllerRateLimiter
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+
 	c := &genericController{
-		name:   name,
-		queue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name),
-		logger: logger.WithField("controller", name),
+		name:       name,
+		queue:      workqueue.NewNamedRateLimitingQueue(rateLimiter, name),
+		maxRetries: policy.maxRetries,
+		logger:     logger.WithField("controller", name),
 	}
 
 	return c
@@ -133,7 +172,19 @@ func (c *genericController) processNextWorkItem() bool {
 		return true
 	}
 
-	c.logger.WithError(err).WithField("key", key).Error("Error in syncHandler, re-adding item to queue")
+	log := c.logger.WithError(err).WithField("key", key)
+
+	if c.maxRetries > 0 && c.queue.NumRequeues(key) >= c.maxRetries {
+		log.WithField("retries", c.maxRetries).Error("Error in syncHandler, giving up on item after too many retries")
+		c.queue.Forget(key)
+
+		if c.failedHandler != nil {
+			c.failedHandler(key.(string), err)
+		}
+		return true
+	}
+
+	log.Error("Error in syncHandler, re-adding item to queue")
 	// we had an error processing the item so add it back
 	// into the queue for re-processing with rate-limiting
 	c.queue.AddRateLimited(key)
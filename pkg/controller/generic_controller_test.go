@@ -0,0 +1,87 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestGenericControllerProcessNextWorkItem(t *testing.T) {
+	tests := []struct {
+		name             string
+		maxRetries       int
+		attempts         int
+		expectedQueueLen int
+		expectFailed     bool
+	}{
+		{
+			name:             "item is retried forever without maxRetries",
+			attempts:         5,
+			expectedQueueLen: 1,
+		},
+		{
+			name:             "item is retried until maxRetries is reached",
+			maxRetries:       3,
+			attempts:         3,
+			expectedQueueLen: 1,
+		},
+		{
+			name:         "item is given up on after maxRetries",
+			maxRetries:   3,
+			attempts:     4,
+			expectFailed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newGenericControllerWithRetryPolicy("test", arktest.NewLogger(), retryPolicy{maxRetries: test.maxRetries})
+			defer c.queue.ShutDown()
+
+			syncErr := errors.New("sync failed")
+			c.syncHandler = func(key string) error {
+				return syncErr
+			}
+
+			var failedKey string
+			var failedErr error
+			c.failedHandler = func(key string, err error) {
+				failedKey = key
+				failedErr = err
+			}
+
+			c.queue.Add("ns/name")
+			for i := 0; i < test.attempts; i++ {
+				// with a zero delay, items that are re-added are immediately available
+				assert.True(t, c.processNextWorkItem())
+			}
+
+			assert.Equal(t, test.expectedQueueLen, c.queue.Len())
+			if test.expectFailed {
+				assert.Equal(t, "ns/name", failedKey)
+				assert.Equal(t, syncErr, failedErr)
+			} else {
+				assert.Empty(t, failedKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
+	informers "github.com/heptio/ark/pkg/generated/informers/externalversions/ark/v1"
+	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+)
+
+// namespaceBackupResyncPeriod is how often the namespace backup controller checks its
+// schedule, in addition to whenever a namespace changes, so that changes made to the
+// schedule by others are undone.
+const namespaceBackupResyncPeriod = 5 * time.Minute
+
+// namespaceBackupController keeps a schedule's included namespaces up to date with the
+// namespaces matching a label selector, creating the schedule if it doesn't exist, so that
+// new namespaces are backed up without anyone editing the schedule.
+type namespaceBackupController struct {
+	*genericController
+
+	namespace      string
+	scheduleName   string
+	cronSchedule   string
+	selector       labels.Selector
+	namespaceStore cache.Store
+	scheduleLister listers.ScheduleLister
+	scheduleClient arkv1client.SchedulesGetter
+}
+
+// NewNamespaceBackupController constructs a new namespaceBackupController, which manages
+// the schedule named scheduleName in namespace. The schedule is created with cronSchedule
+// and an otherwise empty backup template, which can be customized after it's created: only
+// its included namespaces are kept up to date.
+func NewNamespaceBackupController(
+	namespace string,
+	scheduleName string,
+	cronSchedule string,
+	selector labels.Selector,
+	namespaceInformer cache.SharedIndexInformer,
+	scheduleInformer informers.ScheduleInformer,
+	scheduleClient arkv1client.SchedulesGetter,
+	logger logrus.FieldLogger,
+) Interface {
+	c := &namespaceBackupController{
+		genericController: newGenericController("namespace-backup-controller", logger),
+		namespace:         namespace,
+		scheduleName:      scheduleName,
+		cronSchedule:      cronSchedule,
+		selector:          selector,
+		namespaceStore:    namespaceInformer.GetStore(),
+		scheduleLister:    scheduleInformer.Lister(),
+		scheduleClient:    scheduleClient,
+	}
+
+	c.syncHandler = c.processSchedule
+	c.cacheSyncWaiters = append(c.cacheSyncWaiters, namespaceInformer.HasSynced, scheduleInformer.Informer().HasSynced)
+
+	c.resyncPeriod = namespaceBackupResyncPeriod
+	c.resyncFunc = c.enqueueSchedule
+
+	// every change to a namespace, including its labels, may change which namespaces match,
+	// so they all enqueue the one schedule
+	namespaceInformer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(_ interface{}) { c.enqueueSchedule() },
+			UpdateFunc: func(_, _ interface{}) { c.enqueueSchedule() },
+			DeleteFunc: func(_ interface{}) { c.enqueueSchedule() },
+		},
+	)
+
+	return c
+}
+
+func (c *namespaceBackupController) enqueueSchedule() {
+	c.queue.Add(c.namespace + "/" + c.scheduleName)
+}
+
+// matchingNamespaces returns the sorted names of the namespaces matching the controller's
+// selector.
+func (c *namespaceBackupController) matchingNamespaces() []string {
+	var names []string
+	for _, obj := range c.namespaceStore.List() {
+		ns, ok := obj.(*v1.Namespace)
+		if !ok {
+			continue
+		}
+		if c.selector.Matches(labels.Set(ns.Labels)) {
+			names = append(names, ns.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *namespaceBackupController) processSchedule(key string) error {
+	log := c.logger.WithField("schedule", key)
+
+	namespaces := c.matchingNamespaces()
+
+	schedule, err := c.scheduleLister.Schedules(c.namespace).Get(c.scheduleName)
+	if apierrors.IsNotFound(err) {
+		// a schedule without included namespaces would back up every namespace, so it isn't
+		// created until there's a namespace to back up
+		if len(namespaces) == 0 {
+			log.Debug("No namespaces match the selector, not creating schedule")
+			return nil
+		}
+
+		log.WithField("namespaces", namespaces).Info("Creating schedule for namespaces matching the selector")
+		_, err := c.scheduleClient.Schedules(c.namespace).Create(&api.Schedule{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: c.namespace,
+				Name:      c.scheduleName,
+				Labels:    map[string]string{api.NamespaceAutoBackupLabel: "true"},
+			},
+			Spec: api.ScheduleSpec{
+				Schedule: c.cronSchedule,
+				Template: api.BackupSpec{
+					IncludedNamespaces: namespaces,
+				},
+			},
+		})
+		return errors.Wrap(err, "error creating schedule")
+	}
+	if err != nil {
+		return errors.Wrap(err, "error getting schedule")
+	}
+
+	if schedule.Labels[api.NamespaceAutoBackupLabel] != "true" {
+		log.Warnf("Schedule exists but doesn't have the %s=true label, so it won't be updated", api.NamespaceAutoBackupLabel)
+		return nil
+	}
+
+	if len(namespaces) == 0 {
+		log.Info("No namespaces match the selector, leaving schedule's included namespaces as they are")
+		return nil
+	}
+
+	if sets.NewString(schedule.Spec.Template.IncludedNamespaces...).Equal(sets.NewString(namespaces...)) {
+		return nil
+	}
+
+	log.WithField("namespaces", namespaces).Info("Updating schedule's included namespaces")
+
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"includedNamespaces": namespaces,
+			},
+		},
+	}
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling schedule patch")
+	}
+
+	if _, err := c.scheduleClient.Schedules(c.namespace).Patch(c.scheduleName, types.MergePatchType, patchBytes); err != nil {
+		return errors.Wrap(err, "error patching schedule")
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	corev1api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
+	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func newTestNamespace(name string, labels map[string]string) *corev1api.Namespace {
+	return &corev1api.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
+func TestNamespaceBackupControllerProcessSchedule(t *testing.T) {
+	backupLabels := map[string]string{"backup": "true"}
+
+	tests := []struct {
+		name            string
+		namespaces      []*corev1api.Namespace
+		schedule        *arktest.TestSchedule
+		expectedActions []core.Action
+	}{
+		{
+			name:       "no schedule is created when no namespaces match",
+			namespaces: []*corev1api.Namespace{newTestNamespace("ns-1", nil)},
+		},
+		{
+			name: "schedule is created with the matching namespaces",
+			namespaces: []*corev1api.Namespace{
+				newTestNamespace("ns-2", backupLabels),
+				newTestNamespace("ns-1", backupLabels),
+				newTestNamespace("ns-3", nil),
+			},
+			expectedActions: []core.Action{
+				core.NewCreateAction(
+					v1.SchemeGroupVersion.WithResource("schedules"),
+					"heptio-ark",
+					arktest.NewTestSchedule("heptio-ark", "auto").
+						WithLabel(v1.NamespaceAutoBackupLabel, "true").
+						WithCronSchedule("0 1 * * *").
+						WithIncludedNamespaces("ns-1", "ns-2").
+						Schedule,
+				),
+			},
+		},
+		{
+			name: "schedule's included namespaces are updated when they don't match",
+			namespaces: []*corev1api.Namespace{
+				newTestNamespace("ns-1", backupLabels),
+				newTestNamespace("ns-2", backupLabels),
+			},
+			schedule: arktest.NewTestSchedule("heptio-ark", "auto").WithLabel(v1.NamespaceAutoBackupLabel, "true").WithIncludedNamespaces("ns-1", "ns-old"),
+			expectedActions: []core.Action{
+				core.NewPatchAction(
+					v1.SchemeGroupVersion.WithResource("schedules"),
+					"heptio-ark",
+					"auto",
+					[]byte(`{"spec":{"template":{"includedNamespaces":["ns-1","ns-2"]}}}`),
+				),
+			},
+		},
+		{
+			name: "schedule whose included namespaces match in a different order isn't updated",
+			namespaces: []*corev1api.Namespace{
+				newTestNamespace("ns-1", backupLabels),
+				newTestNamespace("ns-2", backupLabels),
+			},
+			schedule: arktest.NewTestSchedule("heptio-ark", "auto").WithLabel(v1.NamespaceAutoBackupLabel, "true").WithIncludedNamespaces("ns-2", "ns-1"),
+		},
+		{
+			name:       "schedule without the auto-backup label isn't updated",
+			namespaces: []*corev1api.Namespace{newTestNamespace("ns-1", backupLabels)},
+			schedule:   arktest.NewTestSchedule("heptio-ark", "auto").WithIncludedNamespaces("ns-other"),
+		},
+		{
+			name:       "schedule isn't updated when no namespaces match",
+			namespaces: []*corev1api.Namespace{newTestNamespace("ns-1", nil)},
+			schedule:   arktest.NewTestSchedule("heptio-ark", "auto").WithLabel(v1.NamespaceAutoBackupLabel, "true").WithIncludedNamespaces("ns-1"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				client            = fake.NewSimpleClientset()
+				sharedInformers   = informers.NewSharedInformerFactory(client, 0)
+				namespaceInformer = cache.NewSharedIndexInformer(
+					&cache.ListWatch{
+						ListFunc:  func(metav1.ListOptions) (runtime.Object, error) { return &corev1api.NamespaceList{}, nil },
+						WatchFunc: func(metav1.ListOptions) (watch.Interface, error) { return watch.NewFake(), nil },
+					},
+					&corev1api.Namespace{},
+					0,
+					cache.Indexers{},
+				)
+			)
+
+			c := NewNamespaceBackupController(
+				"heptio-ark",
+				"auto",
+				"0 1 * * *",
+				labels.SelectorFromSet(labels.Set(backupLabels)),
+				namespaceInformer,
+				sharedInformers.Ark().V1().Schedules(),
+				client.ArkV1(),
+				arktest.NewLogger(),
+			).(*namespaceBackupController)
+
+			for _, ns := range test.namespaces {
+				require.NoError(t, namespaceInformer.GetStore().Add(ns))
+			}
+			if test.schedule != nil {
+				require.NoError(t, sharedInformers.Ark().V1().Schedules().Informer().GetStore().Add(test.schedule.Schedule))
+			}
+
+			require.NoError(t, c.processSchedule("heptio-ark/auto"))
+
+			if len(test.expectedActions) == 0 {
+				assert.Empty(t, client.Actions())
+				return
+			}
+			assert.Equal(t, test.expectedActions, client.Actions())
+		})
+	}
+}
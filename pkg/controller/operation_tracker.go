@@ -0,0 +1,109 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// OperationTracker keeps track of in-progress backups, restores, and backup deletions.
+type OperationTracker interface {
+	// Add informs the tracker that an operation is in progress.
+	Add(kind api.OperationKind, ns, name string)
+	// Delete informs the tracker that an operation is no longer in progress.
+	Delete(kind api.OperationKind, ns, name string)
+	// Contains returns true if the tracker is tracking the operation.
+	Contains(kind api.OperationKind, ns, name string) bool
+	// List returns the operations being tracked, oldest first.
+	List() []api.Operation
+}
+
+type operationTracker struct {
+	lock       sync.RWMutex
+	operations map[string]api.Operation
+	clock      clock.Clock
+}
+
+// NewOperationTracker returns a new OperationTracker.
+func NewOperationTracker() OperationTracker {
+	return &operationTracker{
+		operations: make(map[string]api.Operation),
+		clock:      &clock.RealClock{},
+	}
+}
+
+func (ot *operationTracker) Add(kind api.OperationKind, ns, name string) {
+	ot.lock.Lock()
+	defer ot.lock.Unlock()
+
+	ot.operations[operationTrackerKey(kind, ns, name)] = api.Operation{
+		Kind:           kind,
+		Namespace:      ns,
+		Name:           name,
+		StartTimestamp: metav1.NewTime(ot.clock.Now()),
+	}
+}
+
+func (ot *operationTracker) Delete(kind api.OperationKind, ns, name string) {
+	ot.lock.Lock()
+	defer ot.lock.Unlock()
+
+	delete(ot.operations, operationTrackerKey(kind, ns, name))
+}
+
+func (ot *operationTracker) Contains(kind api.OperationKind, ns, name string) bool {
+	ot.lock.RLock()
+	defer ot.lock.RUnlock()
+
+	_, ok := ot.operations[operationTrackerKey(kind, ns, name)]
+	return ok
+}
+
+func (ot *operationTracker) List() []api.Operation {
+	ot.lock.RLock()
+	defer ot.lock.RUnlock()
+
+	operations := make([]api.Operation, 0, len(ot.operations))
+	for _, operation := range ot.operations {
+		operations = append(operations, operation)
+	}
+
+	sort.Slice(operations, func(i, j int) bool {
+		if !operations[i].StartTimestamp.Equal(&operations[j].StartTimestamp) {
+			return operations[i].StartTimestamp.Before(&operations[j].StartTimestamp)
+		}
+		return operationTrackerKey(operations[i].Kind, operations[i].Namespace, operations[i].Name) <
+			operationTrackerKey(operations[j].Kind, operations[j].Namespace, operations[j].Name)
+	})
+
+	return operations
+}
+
+func operationTrackerKey(kind api.OperationKind, ns, name string) string {
+	return fmt.Sprintf("%s/%s", kind, trackerKey(ns, name))
+}
+
+func trackerKey(ns, name string) string {
+	return fmt.Sprintf("%s/%s", ns, name)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+func TestOperationTracker(t *testing.T) {
+	ot := NewOperationTracker()
+
+	assert.False(t, ot.Contains(api.OperationKindBackup, "ns", "name"))
+
+	ot.Add(api.OperationKindBackup, "ns", "name")
+	assert.True(t, ot.Contains(api.OperationKindBackup, "ns", "name"))
+	assert.False(t, ot.Contains(api.OperationKindRestore, "ns", "name"))
+
+	ot.Add(api.OperationKindBackup, "ns2", "name2")
+	assert.True(t, ot.Contains(api.OperationKindBackup, "ns", "name"))
+	assert.True(t, ot.Contains(api.OperationKindBackup, "ns2", "name2"))
+
+	ot.Add(api.OperationKindRestore, "ns", "name")
+	assert.True(t, ot.Contains(api.OperationKindRestore, "ns", "name"))
+
+	ot.Delete(api.OperationKindBackup, "ns", "name")
+	assert.False(t, ot.Contains(api.OperationKindBackup, "ns", "name"))
+	assert.True(t, ot.Contains(api.OperationKindBackup, "ns2", "name2"))
+	assert.True(t, ot.Contains(api.OperationKindRestore, "ns", "name"))
+
+	ot.Delete(api.OperationKindBackup, "ns2", "name2")
+	assert.False(t, ot.Contains(api.OperationKindBackup, "ns2", "name2"))
+}
+
+func TestOperationTrackerList(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(now)
+
+	ot := NewOperationTracker().(*operationTracker)
+	ot.clock = fakeClock
+
+	assert.Empty(t, ot.List())
+
+	ot.Add(api.OperationKindRestore, "ns", "restore-1")
+	fakeClock.Step(time.Minute)
+	ot.Add(api.OperationKindBackupDeletion, "ns", "backup-2")
+	ot.Add(api.OperationKindBackup, "ns", "backup-1")
+
+	expected := []api.Operation{
+		{Kind: api.OperationKindRestore, Namespace: "ns", Name: "restore-1", StartTimestamp: metav1.NewTime(now)},
+		{Kind: api.OperationKindBackup, Namespace: "ns", Name: "backup-1", StartTimestamp: metav1.NewTime(now.Add(time.Minute))},
+		{Kind: api.OperationKindBackupDeletion, Namespace: "ns", Name: "backup-2", StartTimestamp: metav1.NewTime(now.Add(time.Minute))},
+	}
+	assert.Equal(t, expected, ot.List())
+
+	ot.Delete(api.OperationKindRestore, "ns", "restore-1")
+	assert.Equal(t, expected[1:], ot.List())
+}
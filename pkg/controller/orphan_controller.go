@@ -0,0 +1,271 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/audit"
+	"github.com/heptio/ark/pkg/cloudprovider"
+	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
+)
+
+// orphanController finds volume snapshots and backup directories in object storage that
+// don't belong to any backup, which can be left behind by failed or interrupted backups.
+// Orphans are always reported, and are deleted if deleteOrphans is true once they've been
+// orphans for at least gracePeriod, so that the snapshots and files of a backup that another
+// Ark server sharing the bucket is still taking aren't deleted.
+type orphanController struct {
+	client          arkv1client.BackupsGetter
+	backupService   cloudprovider.BackupService
+	bucket          string
+	snapshotService cloudprovider.SnapshotService
+	syncPeriod      time.Duration
+	namespace       string
+	deleteOrphans   bool
+	gracePeriod     time.Duration
+	auditLog        audit.Log
+	logger          logrus.FieldLogger
+	clock           clock.Clock
+
+	// orphanedDirs and orphanedSnapshots hold when each of the orphans found by the last run
+	// was first found.
+	orphanedDirs      map[string]time.Time
+	orphanedSnapshots map[string]time.Time
+}
+
+// NewOrphanController constructs a new orphanController. snapshotService may be nil, in
+// which case only object storage is checked for orphans.
+func NewOrphanController(
+	client arkv1client.BackupsGetter,
+	backupService cloudprovider.BackupService,
+	bucket string,
+	snapshotService cloudprovider.SnapshotService,
+	syncPeriod time.Duration,
+	namespace string,
+	deleteOrphans bool,
+	gracePeriod time.Duration,
+	auditLog audit.Log,
+	logger logrus.FieldLogger,
+) Interface {
+	if syncPeriod < time.Minute {
+		logger.Infof("Provided orphan sync period %v is too short. Setting to 1 minute", syncPeriod)
+		syncPeriod = time.Minute
+	}
+
+	return &orphanController{
+		client:          client,
+		backupService:   backupService,
+		bucket:          bucket,
+		snapshotService: snapshotService,
+		syncPeriod:      syncPeriod,
+		namespace:       namespace,
+		deleteOrphans:   deleteOrphans,
+		gracePeriod:     gracePeriod,
+		auditLog:        auditLog,
+		logger:          logger.WithField("controller", "orphan-controller"),
+		clock:           clock.RealClock{},
+	}
+}
+
+// Run is a blocking function that periodically checks for orphaned volume snapshots
+// and backup directories according to the controller's syncPeriod. It will return when
+// it receives on the ctx.Done() channel.
+func (c *orphanController) Run(ctx context.Context, workers int) error {
+	c.logger.Info("Running orphan controller")
+	wait.Until(c.run, c.syncPeriod, ctx.Done())
+	return nil
+}
+
+func (c *orphanController) run() {
+	// snapshots are listed before backups, so that snapshots taken by a backup that's
+	// created in between are never mistaken for orphans
+	var (
+		snapshotIDs   []string
+		snapshotsErr  error
+		listSnapshots = c.snapshotService != nil
+	)
+	if listSnapshots {
+		snapshotIDs, snapshotsErr = c.snapshotService.ListSnapshots(map[string]string{api.SnapshotBackupTagKey: ""})
+	}
+
+	backups, err := c.client.Backups(c.namespace).List(metav1.ListOptions{})
+	if err != nil {
+		c.logger.WithError(errors.WithStack(err)).Error("Error listing backups")
+		return
+	}
+
+	c.reconcileBackupStorage(backups.Items)
+
+	if listSnapshots {
+		if snapshotsErr != nil {
+			c.logger.WithError(snapshotsErr).Error("Error listing snapshots")
+			return
+		}
+
+		// backups that were deleted with their snapshots retained only exist in object storage
+		err := c.backupService.ForEachBackup(c.bucket, func(backup *api.Backup) error {
+			if _, deleted := backup.Annotations[api.DeletedBackupAnnotation]; deleted {
+				backups.Items = append(backups.Items, *backup)
+			}
+			return nil
+		})
+		if err != nil {
+			c.logger.WithError(err).Error("Error listing backups in object storage; not checking for orphaned snapshots")
+			return
+		}
+
+		c.reconcileSnapshots(backups.Items, snapshotIDs)
+	}
+}
+
+// reconcileBackupStorage finds backup directories in object storage that have neither a
+// corresponding backup nor a backup metadata file. Directories with metadata are left for
+// the backup sync controller to create backups for, and directories that can't be checked
+// are skipped until the next sync.
+func (c *orphanController) reconcileBackupStorage(backups []api.Backup) {
+	known := sets.NewString()
+	for _, backup := range backups {
+		known.Insert(backup.Name)
+	}
+
+	dirs, err := c.backupService.ListBackupDirs(c.bucket)
+	if err != nil {
+		c.logger.WithError(err).Error("Error listing backup directories in object storage")
+		return
+	}
+
+	now := c.clock.Now()
+	orphaned := make(map[string]time.Time)
+	defer func() { c.orphanedDirs = orphaned }()
+
+	for _, dir := range dirs {
+		if known.Has(dir) {
+			continue
+		}
+
+		log := c.logger.WithFields(logrus.Fields{"bucket": c.bucket, "dir": dir})
+
+		exists, err := c.backupService.BackupMetadataExists(c.bucket, dir)
+		if err != nil {
+			log.WithError(err).Error("Error checking for backup metadata in object storage; skipping backup directory")
+			continue
+		}
+		if exists {
+			continue
+		}
+
+		orphaned[dir] = orphanedSince(c.orphanedDirs, dir, now)
+
+		if !c.deleteOrphans {
+			log.Warn("Found backup directory in object storage that does not belong to any backup")
+			continue
+		}
+
+		if now.Sub(orphaned[dir]) < c.gracePeriod {
+			log.WithField("gracePeriod", c.gracePeriod).Info("Found backup directory in object storage that does not belong to any backup; deleting it once it has been orphaned for the grace period")
+			continue
+		}
+
+		log.Info("Deleting backup directory in object storage that does not belong to any backup")
+		_, err = c.backupService.DeleteBackupDir(c.bucket, dir)
+		c.auditLog.Record(audit.Entry{Action: audit.ActionDelete, Kind: audit.KindBackupStorage, Name: dir}.WithError(err))
+		if err != nil {
+			log.WithError(err).Error("Error deleting orphaned backup directory")
+			continue
+		}
+		delete(orphaned, dir)
+	}
+}
+
+// reconcileSnapshots finds which of snapshotIDs, the volume snapshots that were tagged by Ark
+// during a backup, are not referenced by any backup. Snapshots belonging to backups that haven't
+// finished yet are never considered orphans, since they're not recorded in the backup until it
+// completes.
+func (c *orphanController) reconcileSnapshots(backups []api.Backup, snapshotIDs []string) {
+	referenced := sets.NewString()
+
+	for _, backup := range backups {
+		for _, volumeBackup := range backup.Status.VolumeBackups {
+			referenced.Insert(volumeBackup.SnapshotID)
+			if volumeBackup.CopySnapshotID != "" {
+				referenced.Insert(volumeBackup.CopySnapshotID)
+			}
+		}
+
+		switch backup.Status.Phase {
+		case "", api.BackupPhaseNew, api.BackupPhaseInProgress:
+			backupSnapshotIDs, err := c.snapshotService.ListSnapshots(map[string]string{api.SnapshotBackupTagKey: backup.Name})
+			if err != nil {
+				c.logger.WithError(err).WithField("backup", backup.Name).Error("Error listing snapshots for backup; not checking for orphaned snapshots")
+				return
+			}
+			referenced.Insert(backupSnapshotIDs...)
+		}
+	}
+
+	now := c.clock.Now()
+	orphaned := make(map[string]time.Time)
+	defer func() { c.orphanedSnapshots = orphaned }()
+
+	for _, snapshotID := range snapshotIDs {
+		if referenced.Has(snapshotID) {
+			continue
+		}
+
+		orphaned[snapshotID] = orphanedSince(c.orphanedSnapshots, snapshotID, now)
+
+		log := c.logger.WithField("snapshotID", snapshotID)
+		if !c.deleteOrphans {
+			log.Warn("Found volume snapshot that does not belong to any backup")
+			continue
+		}
+
+		if now.Sub(orphaned[snapshotID]) < c.gracePeriod {
+			log.WithField("gracePeriod", c.gracePeriod).Info("Found volume snapshot that does not belong to any backup; deleting it once it has been orphaned for the grace period")
+			continue
+		}
+
+		log.Info("Deleting volume snapshot that does not belong to any backup")
+		err := c.snapshotService.DeleteSnapshot(snapshotID)
+		c.auditLog.Record(audit.Entry{Action: audit.ActionDelete, Kind: audit.KindVolumeSnapshot, Name: snapshotID}.WithError(err))
+		if err != nil {
+			log.WithError(err).Error("Error deleting orphaned volume snapshot")
+			continue
+		}
+		delete(orphaned, snapshotID)
+	}
+}
+
+// orphanedSince returns when the named orphan was first found, according to the orphans
+// found by the last run, or now if it wasn't one of them.
+func orphanedSince(lastOrphaned map[string]time.Time, name string, now time.Time) time.Time {
+	if since, ok := lastOrphaned[name]; ok {
+		return since
+	}
+	return now
+}
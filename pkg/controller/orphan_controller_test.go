@@ -0,0 +1,193 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/audit"
+	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestOrphanControllerRun(t *testing.T) {
+	tests := []struct {
+		name              string
+		deleteOrphans     bool
+		expectedSnapshots []string
+		expectDirDeleted  bool
+		expectedAudit     []audit.Entry
+	}{
+		{
+			name:              "orphans are only reported by default",
+			deleteOrphans:     false,
+			expectedSnapshots: []string{"snap-1", "snap-2", "snap-3", "snap-4", "snap-5", "snap-6"},
+		},
+		{
+			name:              "orphans are deleted when enabled",
+			deleteOrphans:     true,
+			expectedSnapshots: []string{"snap-1", "snap-2", "snap-4", "snap-5", "snap-6"},
+			expectDirDeleted:  true,
+			expectedAudit: []audit.Entry{
+				{Action: audit.ActionDelete, Kind: audit.KindBackupStorage, Name: "partial"},
+				{Action: audit.ActionDelete, Kind: audit.KindVolumeSnapshot, Name: "snap-3"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				client = fake.NewSimpleClientset(
+					arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-1").WithPhase(v1.BackupPhaseCompleted).
+						WithSnapshotCopyInPhase("pv-1", "snap-1", "us-west-2/snap-1-copy", v1.SnapshotPhaseCompleted).Backup,
+					arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-2").WithPhase(v1.BackupPhaseInProgress).Backup,
+				)
+				backupService   = &arktest.BackupService{}
+				snapshotService = &arktest.FakeSnapshotService{
+					SnapshotsTaken: sets.NewString("snap-1", "snap-2", "snap-3", "snap-4", "snap-5", "snap-6"),
+					SnapshotTags: map[string]map[string]string{
+						// referenced by a completed backup
+						"snap-1": {v1.SnapshotBackupTagKey: "backup-1"},
+						// taken by an in-progress backup
+						"snap-2": {v1.SnapshotBackupTagKey: "backup-2"},
+						// taken by a backup that no longer exists
+						"snap-3": {v1.SnapshotBackupTagKey: "backup-3"},
+						// not taken by ark
+						"snap-4": {"foo": "bar"},
+						// retained by a backup that was deleted with its snapshots kept
+						"snap-6": {v1.SnapshotBackupTagKey: "backup-5"},
+					},
+				}
+				auditLog = &arktest.FakeAuditLog{}
+			)
+
+			deletedBackup := arktest.NewTestBackup().WithName("backup-5").WithPhase(v1.BackupPhaseDeleting).WithSnapshot("pv-1", "snap-6").Backup
+			deletedBackup.Annotations = map[string]string{v1.DeletedBackupAnnotation: string(v1.BackupDeletionPolicyObjectStorageOnly)}
+			backupService.On("ForEachBackup", "bucket", mock.Anything).Return(func(bucket string, fn func(*v1.Backup) error) error {
+				for _, backup := range []*v1.Backup{arktest.NewTestBackup().WithName("backup-4").Backup, deletedBackup} {
+					if err := fn(backup); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			backupService.On("ListBackupDirs", "bucket").Return([]string{"backup-1", "backup-4", "partial", "unreachable"}, nil)
+			// has metadata, so it's left for the sync controller
+			backupService.On("BackupMetadataExists", "bucket", "backup-4").Return(true, nil)
+			// confirmed to have no metadata
+			backupService.On("BackupMetadataExists", "bucket", "partial").Return(false, nil)
+			// can't be checked, so it's skipped
+			backupService.On("BackupMetadataExists", "bucket", "unreachable").Return(false, errors.New("request throttled"))
+			if test.expectDirDeleted {
+				backupService.On("DeleteBackupDir", "bucket", "partial").Return(nil, nil)
+			}
+
+			c := NewOrphanController(
+				client.ArkV1(),
+				backupService,
+				"bucket",
+				snapshotService,
+				time.Duration(0),
+				"heptio-ark",
+				test.deleteOrphans,
+				time.Duration(0),
+				auditLog,
+				arktest.NewLogger(),
+			).(*orphanController)
+
+			c.run()
+
+			assert.Equal(t, test.expectedSnapshots, snapshotService.SnapshotsTaken.List())
+			assert.Equal(t, test.expectedAudit, auditLog.Entries())
+			backupService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrphanControllerGracePeriod(t *testing.T) {
+	var (
+		client          = fake.NewSimpleClientset()
+		backupService   = &arktest.BackupService{}
+		snapshotService = &arktest.FakeSnapshotService{
+			SnapshotsTaken: sets.NewString("snap-1", "snap-2"),
+			SnapshotTags: map[string]map[string]string{
+				// taken by a backup that no longer exists
+				"snap-1": {v1.SnapshotBackupTagKey: "backup-1"},
+				// taken by a backup that's synced before the grace period is up
+				"snap-2": {v1.SnapshotBackupTagKey: "backup-2"},
+			},
+		}
+		auditLog  = &arktest.FakeAuditLog{}
+		fakeClock = clock.NewFakeClock(time.Now())
+	)
+
+	backupService.On("ForEachBackup", "bucket", mock.Anything).Return(nil)
+	backupService.On("ListBackupDirs", "bucket").Return([]string{"partial"}, nil)
+	backupService.On("BackupMetadataExists", "bucket", "partial").Return(false, nil)
+
+	c := NewOrphanController(
+		client.ArkV1(),
+		backupService,
+		"bucket",
+		snapshotService,
+		time.Duration(0),
+		"heptio-ark",
+		true,
+		time.Hour,
+		auditLog,
+		arktest.NewLogger(),
+	).(*orphanController)
+	c.clock = fakeClock
+
+	// newly-found orphans aren't deleted
+	c.run()
+	assert.Equal(t, []string{"snap-1", "snap-2"}, snapshotService.SnapshotsTaken.List())
+	assert.Empty(t, auditLog.Entries())
+
+	fakeClock.Step(30 * time.Minute)
+	c.run()
+	assert.Equal(t, []string{"snap-1", "snap-2"}, snapshotService.SnapshotsTaken.List())
+	assert.Empty(t, auditLog.Entries())
+
+	// the backup that took snap-2 has been synced
+	_, err := client.ArkV1().Backups("heptio-ark").Create(
+		arktest.NewTestBackup().WithNamespace("heptio-ark").WithName("backup-2").WithPhase(v1.BackupPhaseCompleted).WithSnapshot("pv-1", "snap-2").Backup,
+	)
+	require.NoError(t, err)
+
+	// orphans are deleted once they've been orphaned for the grace period
+	fakeClock.Step(30 * time.Minute)
+	backupService.On("DeleteBackupDir", "bucket", "partial").Return(nil, nil)
+	c.run()
+	assert.Equal(t, []string{"snap-2"}, snapshotService.SnapshotsTaken.List())
+	assert.Equal(t, []audit.Entry{
+		{Action: audit.ActionDelete, Kind: audit.KindBackupStorage, Name: "partial"},
+		{Action: audit.ActionDelete, Kind: audit.KindVolumeSnapshot, Name: "snap-1"},
+	}, auditLog.Entries())
+	backupService.AssertExpectations(t)
+}
@@ -24,6 +24,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -39,19 +41,43 @@ import (
 	"k8s.io/client-go/util/workqueue"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	pkgbackup "github.com/heptio/ark/pkg/backup"
+	"github.com/heptio/ark/pkg/backup/shard"
 	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/discovery"
 	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
 	informers "github.com/heptio/ark/pkg/generated/informers/externalversions/ark/v1"
 	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+	"github.com/heptio/ark/pkg/kuberesource"
 	"github.com/heptio/ark/pkg/plugin"
 	"github.com/heptio/ark/pkg/restore"
 	"github.com/heptio/ark/pkg/util/collections"
 	kubeutil "github.com/heptio/ark/pkg/util/kube"
+	"github.com/heptio/ark/pkg/util/logging"
+	"github.com/heptio/ark/pkg/util/tracing"
 )
 
 // nonRestorableResources is a blacklist for the restoration process. Any resources
-// included here are explicitly excluded from the restoration process.
-var nonRestorableResources = []string{"nodes", "events", "events.events.k8s.io"}
+// included here are explicitly excluded from the restoration process. Ark's own
+// Backups, Restores, and requests are never restored: Backups are synced from object
+// storage, and a restored Restore would be processed again, restoring itself in a loop.
+var nonRestorableResources = []string{
+	"nodes",
+	"events",
+	"events.events.k8s.io",
+	"backups.ark.heptio.com",
+	"restores.ark.heptio.com",
+	"deletebackuprequests.ark.heptio.com",
+	"downloadrequests.ark.heptio.com",
+	"serverstatusrequests.ark.heptio.com",
+}
+
+// arkResources are the Ark resources that are only restored if a restore's
+// IncludeArkResources is set.
+var arkResources = []string{
+	kuberesource.ArkConfigs.String(),
+	kuberesource.ArkSchedules.String(),
+}
 
 type restoreController struct {
 	namespace           string
@@ -67,8 +93,18 @@ type restoreController struct {
 	restoreListerSynced cache.InformerSynced
 	syncHandler         func(restoreName string) error
 	queue               workqueue.RateLimitingInterface
+	newOperationID      func() string
 	logger              logrus.FieldLogger
 	pluginManager       plugin.Manager
+
+	discoveryHelper          discovery.Helper
+	apiVersionCheck          api.APIVersionCheckAction
+	apiVersionCheckOverrides map[string]api.APIVersionCheckAction
+
+	// restoreTracker keeps restores into the same namespace from running at the same time,
+	// which is possible when the controller is run with more than one worker.
+	restoreTracker   RestoreTracker
+	operationTracker OperationTracker
 }
 
 func NewRestoreController(
@@ -83,6 +119,11 @@ func NewRestoreController(
 	pvProviderExists bool,
 	logger logrus.FieldLogger,
 	pluginManager plugin.Manager,
+	discoveryHelper discovery.Helper,
+	apiVersionCheck api.APIVersionCheckAction,
+	apiVersionCheckOverrides map[string]api.APIVersionCheckAction,
+	restoreTracker RestoreTracker,
+	operationTracker OperationTracker,
 ) Interface {
 	c := &restoreController{
 		namespace:           namespace,
@@ -97,8 +138,16 @@ func NewRestoreController(
 		restoreLister:       restoreInformer.Lister(),
 		restoreListerSynced: restoreInformer.Informer().HasSynced,
 		queue:               workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "restore"),
+		newOperationID:      newOperationID,
 		logger:              logger,
 		pluginManager:       pluginManager,
+
+		discoveryHelper:          discoveryHelper,
+		apiVersionCheck:          apiVersionCheck,
+		apiVersionCheckOverrides: apiVersionCheckOverrides,
+
+		restoreTracker:   restoreTracker,
+		operationTracker: operationTracker,
 	}
 
 	c.syncHandler = c.processRestore
@@ -247,8 +296,40 @@ func (controller *restoreController) processRestore(key string) error {
 		}
 	}
 
+	if !restore.Spec.IncludeArkResources {
+		for _, arkResource := range arkResources {
+			if !excludedResources.Has(arkResource) {
+				restore.Spec.ExcludedResources = append(restore.Spec.ExcludedResources, arkResource)
+			}
+		}
+	}
+
+	// record who created the restore
+	restore.Status.RequestedBy = restore.Annotations[api.RequestedByAnnotation]
+
+	// identify the restore in logs
+	if restore.Status.OperationID == "" {
+		restore.Status.OperationID = controller.newOperationID()
+	}
+	logContext = logContext.WithField(logging.OperationIDField, restore.Status.OperationID)
+
 	// validation
-	if restore.Status.ValidationErrors = controller.getValidationErrors(restore); len(restore.Status.ValidationErrors) > 0 {
+	restore.Status.ValidationErrors = controller.getValidationErrors(restore)
+
+	// a valid restore is only run if no other restore is restoring into any of its namespaces
+	if len(restore.Status.ValidationErrors) == 0 {
+		if other, ok := controller.restoreTracker.TryAdd(restore.Namespace, restore.Name, restoreTargetNamespaces(restore)); !ok {
+			restore.Status.ValidationErrors = append(restore.Status.ValidationErrors,
+				fmt.Sprintf("Restore %s is in progress and restores into one or more of the same namespaces. Try again once it has finished", other))
+		} else {
+			defer controller.restoreTracker.Delete(restore.Namespace, restore.Name)
+
+			controller.operationTracker.Add(api.OperationKindRestore, restore.Namespace, restore.Name)
+			defer controller.operationTracker.Delete(api.OperationKindRestore, restore.Namespace, restore.Name)
+		}
+	}
+
+	if len(restore.Status.ValidationErrors) > 0 {
 		restore.Status.Phase = api.RestorePhaseFailedValidation
 	} else {
 		restore.Status.Phase = api.RestorePhaseInProgress
@@ -282,7 +363,16 @@ func (controller *restoreController) processRestore(key string) error {
 	}
 
 	logContext.Debug("restore completed")
-	restore.Status.Phase = api.RestorePhaseCompleted
+	switch {
+	case len(restoreErrors.Ark) > 0:
+		// Ark-level errors, such as failing to download the backup or set up plugins, mean
+		// the restore as a whole failed rather than some of its items
+		restore.Status.Phase = api.RestorePhaseFailed
+	case restore.Status.Errors > 0:
+		restore.Status.Phase = api.RestorePhasePartiallyFailed
+	default:
+		restore.Status.Phase = api.RestorePhaseCompleted
+	}
 
 	logContext.Debug("Updating Restore final status")
 	if _, err = patchRestore(original, restore, controller.restoreClient); err != nil {
@@ -297,8 +387,15 @@ func (controller *restoreController) getValidationErrors(itm *api.Restore) []str
 
 	if itm.Spec.BackupName == "" {
 		validationErrors = append(validationErrors, "BackupName must be non-empty and correspond to the name of a backup in object storage.")
-	} else if _, err := controller.fetchBackup(controller.bucket, itm.Spec.BackupName); err != nil {
+	} else if backup, err := controller.fetchBackup(controller.bucket, itm.Spec.BackupName); err != nil {
 		validationErrors = append(validationErrors, fmt.Sprintf("Error retrieving backup: %v", err))
+	} else if backup.Spec.DryRun {
+		validationErrors = append(validationErrors, "Backup is a dry run and has no data to restore")
+	} else if err := pkgbackup.CheckFormatVersion(backup); err != nil {
+		validationErrors = append(validationErrors, err.Error())
+	} else {
+		_, apiVersionErrs := controller.checkAPIVersions(itm, backup)
+		validationErrors = append(validationErrors, apiVersionErrs...)
 	}
 
 	includedResources := sets.NewString(itm.Spec.IncludedResources...)
@@ -308,6 +405,14 @@ func (controller *restoreController) getValidationErrors(itm *api.Restore) []str
 		}
 	}
 
+	if !itm.Spec.IncludeArkResources {
+		for _, arkResource := range arkResources {
+			if includedResources.Has(arkResource) {
+				validationErrors = append(validationErrors, fmt.Sprintf("%v are Ark resources, which are only restored if includeArkResources is set", arkResource))
+			}
+		}
+	}
+
 	for _, err := range collections.ValidateIncludesExcludes(itm.Spec.IncludedNamespaces, itm.Spec.ExcludedNamespaces) {
 		validationErrors = append(validationErrors, fmt.Sprintf("Invalid included/excluded namespace lists: %v", err))
 	}
@@ -316,10 +421,26 @@ func (controller *restoreController) getValidationErrors(itm *api.Restore) []str
 		validationErrors = append(validationErrors, fmt.Sprintf("Invalid included/excluded resource lists: %v", err))
 	}
 
+	if itm.Spec.RestoreStatus != nil {
+		for _, err := range collections.ValidateIncludesExcludes(itm.Spec.RestoreStatus.IncludedResources, itm.Spec.RestoreStatus.ExcludedResources) {
+			validationErrors = append(validationErrors, fmt.Sprintf("Invalid included/excluded restore status resource lists: %v", err))
+		}
+	}
+
 	if !controller.pvProviderExists && itm.Spec.RestorePVs != nil && *itm.Spec.RestorePVs {
 		validationErrors = append(validationErrors, "Server is not configured for PV snapshot restores")
 	}
 
+	if mapping := itm.Spec.ClusterDomainMapping; mapping != nil && (mapping.From == "" || mapping.To == "") {
+		validationErrors = append(validationErrors, "Invalid cluster domain mapping: from and to must both be specified")
+	}
+
+	switch itm.Spec.Replicas {
+	case "", api.ReplicasPolicyLive, api.ReplicasPolicyDesired:
+	default:
+		validationErrors = append(validationErrors, fmt.Sprintf("Invalid replicas policy %q", itm.Spec.Replicas))
+	}
+
 	return validationErrors
 }
 
@@ -356,13 +477,36 @@ func (controller *restoreController) fetchBackup(bucket, name string) (*api.Back
 	return backup, nil
 }
 
+// shardFilter returns whether a shard of a sharded backup may contain items that the
+// restore needs: those of namespaces that the restore includes, and cluster-scoped
+// items. The restorer doesn't restore the items of additional clusters.
+func shardFilter(restore *api.Restore) func(shard.Shard) bool {
+	namespaces := collections.NewIncludesExcludes().
+		Includes(restore.Spec.IncludedNamespaces...).
+		Excludes(restore.Spec.ExcludedNamespaces...)
+
+	return func(s shard.Shard) bool {
+		if s.Cluster != "" {
+			return false
+		}
+
+		return s.Namespace == "" || namespaces.ShouldInclude(s.Namespace)
+	}
+}
+
 func (controller *restoreController) runRestore(restore *api.Restore, bucket string) (restoreWarnings, restoreErrors api.RestoreResult) {
 	logContext := controller.logger.WithFields(
 		logrus.Fields{
-			"restore": kubeutil.NamespaceAndName(restore),
-			"backup":  restore.Spec.BackupName,
+			"restore":                kubeutil.NamespaceAndName(restore),
+			"backup":                 restore.Spec.BackupName,
+			logging.OperationIDField: restore.Status.OperationID,
 		})
 
+	span := tracing.StartOperation(restore.Status.OperationID, "restore")
+	span.SetTag("restore", kubeutil.NamespaceAndName(restore))
+	span.SetTag("backup", restore.Spec.BackupName)
+	defer span.Finish()
+
 	backup, err := controller.fetchBackup(bucket, restore.Spec.BackupName)
 	if err != nil {
 		logContext.WithError(err).Error("Error getting backup")
@@ -370,15 +514,18 @@ func (controller *restoreController) runRestore(restore *api.Restore, bucket str
 		return
 	}
 
-	var tempFiles []*os.File
-
-	backupFile, err := downloadToTempFile(restore.Spec.BackupName, controller.backupService, bucket, controller.logger)
+	// the backup is streamed from object storage into the restorer, which only
+	// extracts the items that will be restored. Shards of a sharded backup that
+	// don't contain any of them aren't downloaded.
+	backupReader, err := controller.backupService.DownloadBackup(bucket, backup, shardFilter(restore))
 	if err != nil {
 		logContext.WithError(err).Error("Error downloading backup")
 		restoreErrors.Ark = append(restoreErrors.Ark, err.Error())
 		return
 	}
-	tempFiles = append(tempFiles, backupFile)
+	defer backupReader.Close()
+
+	var tempFiles []*os.File
 
 	logFile, err := ioutil.TempFile("", "")
 	if err != nil {
@@ -396,6 +543,16 @@ func (controller *restoreController) runRestore(restore *api.Restore, bucket str
 	}
 	tempFiles = append(tempFiles, resultsFile)
 
+	var planFile *os.File
+	if restore.Spec.DryRun {
+		if planFile, err = ioutil.TempFile("", ""); err != nil {
+			logContext.WithError(errors.WithStack(err)).Error("Error creating plan temp file")
+			restoreErrors.Ark = append(restoreErrors.Ark, err.Error())
+			return
+		}
+		tempFiles = append(tempFiles, planFile)
+	}
+
 	defer func() {
 		for _, file := range tempFiles {
 			if err := file.Close(); err != nil {
@@ -416,9 +573,25 @@ func (controller *restoreController) runRestore(restore *api.Restore, bucket str
 	defer controller.pluginManager.CloseRestoreItemActions(restore.Name)
 
 	logContext.Info("starting restore")
-	restoreWarnings, restoreErrors = controller.restorer.Restore(restore, backup, backupFile, logFile, actions)
+	// planFile is passed as a nil io.Writer, rather than a nil *os.File, if this isn't a dry run
+	var planWriter io.Writer
+	if planFile != nil {
+		planWriter = planFile
+	}
+	restoreWarnings, restoreErrors = controller.restorer.Restore(restore, backup, backupReader, logFile, planWriter, actions)
 	logContext.Info("restore completed")
 
+	if planFile != nil {
+		if _, err := planFile.Seek(0, 0); err != nil {
+			restoreErrors.Ark = append(restoreErrors.Ark, fmt.Sprintf("error resetting plan file offset to 0: %v", err))
+		} else if err := controller.backupService.UploadRestorePlan(bucket, restore.Spec.BackupName, restore.Name, planFile); err != nil {
+			restoreErrors.Ark = append(restoreErrors.Ark, fmt.Sprintf("error uploading plan file to object storage: %v", err))
+		}
+	}
+
+	apiVersionWarnings, _ := controller.checkAPIVersions(restore, backup)
+	restoreWarnings.Ark = append(restoreWarnings.Ark, apiVersionWarnings...)
+
 	// Try to upload the log file. This is best-effort. If we fail, we'll add to the ark errors.
 
 	// Reset the offset to 0 for reading
@@ -427,6 +600,9 @@ func (controller *restoreController) runRestore(restore *api.Restore, bucket str
 		return
 	}
 
+	uploadSpan := span.StartChild("upload")
+	defer uploadSpan.Finish()
+
 	if err := controller.backupService.UploadRestoreLog(bucket, restore.Spec.BackupName, restore.Name, logFile); err != nil {
 		restoreErrors.Ark = append(restoreErrors.Ark, fmt.Sprintf("error uploading log file to object storage: %v", err))
 	}
@@ -455,35 +631,79 @@ func (controller *restoreController) runRestore(restore *api.Restore, bucket str
 	return
 }
 
-func downloadToTempFile(backupName string, backupService cloudprovider.BackupService, bucket string, logger logrus.FieldLogger) (*os.File, error) {
-	readCloser, err := backupService.DownloadBackup(bucket, backupName)
-	if err != nil {
-		return nil, err
-	}
-	defer readCloser.Close()
+// checkAPIVersions compares the API group versions that the backup's resources were backed up with
+// against those served by the cluster. For each resource in the backup that's included in the restore
+// and whose group version is no longer served, it returns a warning or an error according to the
+// configured action. Backups that did not record their cluster's API resources, or the resources they
+// contain, are not checked.
+func (controller *restoreController) checkAPIVersions(restore *api.Restore, backup *api.Backup) (warnings, errs []string) {
+	if backup.Status.ClusterInfo == nil || len(backup.Status.ItemCounts) == 0 {
+		return nil, nil
+	}
+
+	resources := collections.GenerateIncludesExcludes(
+		restore.Spec.IncludedResources,
+		restore.Spec.ExcludedResources,
+		func(item string) string {
+			gr := schema.ParseGroupResource(item)
+			gvr, _, err := controller.discoveryHelper.ResourceFor(gr.WithVersion(""))
+			if err != nil {
+				// the resource may not be served by this cluster at all, so use it as given
+				return gr.String()
+			}
+			resolved := gvr.GroupResource()
+			return resolved.String()
+		},
+	)
 
-	file, err := ioutil.TempFile("", backupName)
-	if err != nil {
-		return nil, errors.Wrap(err, "error creating Backup temp file")
+	// find the group version that each resource was backed up with
+	backedUpVersions := make(map[string]schema.GroupVersion)
+	for groupVersion, resourceNames := range backup.Status.ClusterInfo.APIResources {
+		gv, err := schema.ParseGroupVersion(groupVersion)
+		if err != nil {
+			controller.logger.WithError(err).WithField("groupVersion", groupVersion).Warn("Unable to parse backed-up group version, skipping")
+			continue
+		}
+
+		for _, resource := range resourceNames {
+			gr := gv.WithResource(resource).GroupResource()
+			backedUpVersions[gr.String()] = gv
+		}
 	}
 
-	n, err := io.Copy(file, readCloser)
-	if err != nil {
-		return nil, errors.Wrap(err, "error copying Backup to temp file")
+	// sort the backup's resources so the results are in a consistent order
+	var backedUpResources []string
+	for gr := range backup.Status.ItemCounts {
+		backedUpResources = append(backedUpResources, gr)
 	}
+	sort.Strings(backedUpResources)
+
+	for _, gr := range backedUpResources {
+		gv, found := backedUpVersions[gr]
+		if !found || !resources.ShouldInclude(gr) {
+			continue
+		}
 
-	logContext := logger.WithField("backup", backupName)
+		if controller.discoveryHelper.Serves(gv.WithResource(schema.ParseGroupResource(gr).Resource)) {
+			continue
+		}
 
-	logContext.WithFields(logrus.Fields{
-		"fileName": file.Name(),
-		"bytes":    n,
-	}).Debug("Copied Backup to file")
+		action := controller.apiVersionCheck
+		if override, found := controller.apiVersionCheckOverrides[gr]; found {
+			action = override
+		}
 
-	if _, err := file.Seek(0, 0); err != nil {
-		return nil, errors.Wrap(err, "error resetting Backup file offset")
+		msg := fmt.Sprintf("%s was backed up with API version %s, which is not served by this cluster", gr, gv)
+		switch action {
+		case api.APIVersionCheckActionIgnore:
+		case api.APIVersionCheckActionFail:
+			errs = append(errs, msg)
+		default:
+			warnings = append(warnings, msg)
+		}
 	}
 
-	return file, nil
+	return warnings, errs
 }
 
 func patchRestore(original, updated *api.Restore, client arkv1client.RestoresGetter) (*api.Restore, error) {
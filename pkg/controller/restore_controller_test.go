@@ -29,6 +29,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	core "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 
@@ -93,6 +94,11 @@ func TestFetchBackup(t *testing.T) {
 				false,
 				logger,
 				pluginManager,
+				arktest.NewFakeDiscoveryHelper(true, nil),
+				api.APIVersionCheckActionWarn,
+				nil,
+				NewRestoreTracker(),
+				NewOperationTracker(),
 			).(*restoreController)
 
 			for _, itm := range test.informerBackups {
@@ -121,6 +127,7 @@ func TestProcessRestore(t *testing.T) {
 		restore                     *api.Restore
 		backup                      *api.Backup
 		restorerError               error
+		restorerArkError            error
 		allowRestoreSnapshots       bool
 		expectedErr                 bool
 		expectedPhase               string
@@ -129,6 +136,7 @@ func TestProcessRestore(t *testing.T) {
 		expectedRestorerCall        *api.Restore
 		backupServiceGetBackupError error
 		uploadLogError              error
+		inProgressRestoreNamespaces []string
 	}{
 		{
 			name:        "invalid key returns error",
@@ -188,7 +196,48 @@ func TestProcessRestore(t *testing.T) {
 			backupServiceGetBackupError: errors.New("no backup here"),
 		},
 		{
-			name:                  "restorer throwing an error causes the restore to fail",
+			name:                        "restore into a namespace another restore is restoring into fails validation",
+			restore:                     NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).Restore,
+			backup:                      arktest.NewTestBackup().WithName("backup-1").Backup,
+			inProgressRestoreNamespaces: []string{"ns-2", "ns-1"},
+			expectedErr:                 false,
+			expectedPhase:               string(api.RestorePhaseFailedValidation),
+			expectedValidationErrors:    []string{"Restore foo/other is in progress and restores into one or more of the same namespaces. Try again once it has finished"},
+		},
+		{
+			name:                     "restore from a dry-run backup fails validation",
+			restore:                  NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).Restore,
+			backup:                   arktest.NewTestBackup().WithName("backup-1").WithDryRun(true).Backup,
+			expectedErr:              false,
+			expectedPhase:            string(api.RestorePhaseFailedValidation),
+			expectedValidationErrors: []string{"Backup is a dry run and has no data to restore"},
+		},
+		{
+			name:                     "restore with an invalid replicas policy fails validation",
+			restore:                  NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).WithReplicasPolicy("Most").Restore,
+			backup:                   arktest.NewTestBackup().WithName("backup-1").Backup,
+			expectedErr:              false,
+			expectedPhase:            string(api.RestorePhaseFailedValidation),
+			expectedValidationErrors: []string{`Invalid replicas policy "Most"`},
+		},
+		{
+			name:                     "restore with an incomplete cluster domain mapping fails validation",
+			restore:                  NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).WithClusterDomainMapping("cluster.local", "").Restore,
+			backup:                   arktest.NewTestBackup().WithName("backup-1").Backup,
+			expectedErr:              false,
+			expectedPhase:            string(api.RestorePhaseFailedValidation),
+			expectedValidationErrors: []string{"Invalid cluster domain mapping: from and to must both be specified"},
+		},
+		{
+			name:                     "backup with a newer format version fails validation",
+			restore:                  NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).Restore,
+			backup:                   arktest.NewTestBackup().WithName("backup-1").WithVersion(3).Backup,
+			expectedErr:              false,
+			expectedPhase:            string(api.RestorePhaseFailedValidation),
+			expectedValidationErrors: []string{"backup backup-1 has format version 3, but this version of Ark can only read format versions up to 2; upgrade Ark to use it"},
+		},
+		{
+			name:                  "restorer throwing an error causes the restore to partially fail",
 			restore:               NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).Restore,
 			backup:                arktest.NewTestBackup().WithName("backup-1").Backup,
 			restorerError:         errors.New("blarg"),
@@ -197,6 +246,16 @@ func TestProcessRestore(t *testing.T) {
 			expectedRestoreErrors: 1,
 			expectedRestorerCall:  NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseInProgress).Restore,
 		},
+		{
+			name:                  "restorer returning an Ark error causes the restore to fail",
+			restore:               NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).Restore,
+			backup:                arktest.NewTestBackup().WithName("backup-1").Backup,
+			restorerArkError:      errors.New("error reading backup"),
+			expectedErr:           false,
+			expectedPhase:         string(api.RestorePhaseInProgress),
+			expectedRestoreErrors: 1,
+			expectedRestorerCall:  NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseInProgress).Restore,
+		},
 		{
 			name:                 "valid restore gets executed",
 			restore:              NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).Restore,
@@ -205,6 +264,14 @@ func TestProcessRestore(t *testing.T) {
 			expectedPhase:        string(api.RestorePhaseInProgress),
 			expectedRestorerCall: NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseInProgress).Restore,
 		},
+		{
+			name:                 "dry-run restore gets executed and uploads its plan",
+			restore:              NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).WithDryRun(true).Restore,
+			backup:               arktest.NewTestBackup().WithName("backup-1").Backup,
+			expectedErr:          false,
+			expectedPhase:        string(api.RestorePhaseInProgress),
+			expectedRestorerCall: NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseInProgress).WithDryRun(true).Restore,
+		},
 		{
 			name:                  "valid restore with RestorePVs=true gets executed when allowRestoreSnapshots=true",
 			restore:               NewRestore("foo", "bar", "backup-1", "ns-1", "", api.RestorePhaseNew).WithRestorePVs(true).Restore,
@@ -255,6 +322,36 @@ func TestProcessRestore(t *testing.T) {
 				"Invalid included/excluded resource lists: excludes list cannot contain an item in the includes list: events.events.k8s.io",
 			},
 		},
+		{
+			name:          "restoration of restores.ark.heptio.com is not supported",
+			restore:       NewRestore("foo", "bar", "backup-1", "ns-1", "restores.ark.heptio.com", api.RestorePhaseNew).Restore,
+			backup:        arktest.NewTestBackup().WithName("backup-1").Backup,
+			expectedErr:   false,
+			expectedPhase: string(api.RestorePhaseFailedValidation),
+			expectedValidationErrors: []string{
+				"restores.ark.heptio.com are non-restorable resources",
+				"Invalid included/excluded resource lists: excludes list cannot contain an item in the includes list: restores.ark.heptio.com",
+			},
+		},
+		{
+			name:          "restoration of schedules.ark.heptio.com requires includeArkResources",
+			restore:       NewRestore("foo", "bar", "backup-1", "ns-1", "schedules.ark.heptio.com", api.RestorePhaseNew).Restore,
+			backup:        arktest.NewTestBackup().WithName("backup-1").Backup,
+			expectedErr:   false,
+			expectedPhase: string(api.RestorePhaseFailedValidation),
+			expectedValidationErrors: []string{
+				"schedules.ark.heptio.com are Ark resources, which are only restored if includeArkResources is set",
+				"Invalid included/excluded resource lists: excludes list cannot contain an item in the includes list: schedules.ark.heptio.com",
+			},
+		},
+		{
+			name:                 "restore with includeArkResources doesn't exclude configs and schedules",
+			restore:              NewArkResourcesRestore("foo", "bar", "backup-1", "ns-1", api.RestorePhaseNew).Restore,
+			backup:               arktest.NewTestBackup().WithName("backup-1").Backup,
+			expectedErr:          false,
+			expectedPhase:        string(api.RestorePhaseInProgress),
+			expectedRestorerCall: NewArkResourcesRestore("foo", "bar", "backup-1", "ns-1", api.RestorePhaseInProgress).Restore,
+		},
 	}
 
 	for _, test := range tests {
@@ -283,8 +380,19 @@ func TestProcessRestore(t *testing.T) {
 				test.allowRestoreSnapshots,
 				logger,
 				pluginManager,
+				arktest.NewFakeDiscoveryHelper(true, nil),
+				api.APIVersionCheckActionWarn,
+				nil,
+				NewRestoreTracker(),
+				NewOperationTracker(),
 			).(*restoreController)
 
+			c.newOperationID = func() string { return "operation-1" }
+
+			if test.inProgressRestoreNamespaces != nil {
+				c.restoreTracker.TryAdd("foo", "other", test.inProgressRestoreNamespaces)
+			}
+
 			if test.restore != nil {
 				sharedInformers.Ark().V1().Restores().Informer().GetStore().Add(test.restore)
 
@@ -314,6 +422,7 @@ func TestProcessRestore(t *testing.T) {
 					// the controller
 
 					res.Status.Phase = api.RestorePhase(phase)
+					res.Status.OperationID = "operation-1"
 
 					return true, res, nil
 				})
@@ -327,15 +436,21 @@ func TestProcessRestore(t *testing.T) {
 			if test.restorerError != nil {
 				errors.Namespaces = map[string][]string{"ns-1": {test.restorerError.Error()}}
 			}
+			if test.restorerArkError != nil {
+				errors.Ark = append(errors.Ark, test.restorerArkError.Error())
+			}
 			if test.uploadLogError != nil {
 				errors.Ark = append(errors.Ark, "error uploading log file to object storage: "+test.uploadLogError.Error())
 			}
 			if test.expectedRestorerCall != nil {
 				downloadedBackup := ioutil.NopCloser(bytes.NewReader([]byte("hello world")))
-				backupSvc.On("DownloadBackup", mock.Anything, mock.Anything).Return(downloadedBackup, nil)
+				backupSvc.On("DownloadBackup", mock.Anything, mock.Anything, mock.Anything).Return(downloadedBackup, nil)
 				restorer.On("Restore", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(warnings, errors)
 				backupSvc.On("UploadRestoreLog", "bucket", test.restore.Spec.BackupName, test.restore.Name, mock.Anything).Return(test.uploadLogError)
 				backupSvc.On("UploadRestoreResults", "bucket", test.restore.Spec.BackupName, test.restore.Name, mock.Anything).Return(nil)
+				if test.restore.Spec.DryRun {
+					backupSvc.On("UploadRestorePlan", "bucket", test.restore.Spec.BackupName, test.restore.Name, mock.Anything).Return(nil)
+				}
 			}
 
 			var (
@@ -376,6 +491,7 @@ func TestProcessRestore(t *testing.T) {
 				Phase            api.RestorePhase `json:"phase"`
 				ValidationErrors []string         `json:"validationErrors"`
 				Errors           int              `json:"errors"`
+				OperationID      string           `json:"operationID"`
 			}
 
 			type Patch struct {
@@ -396,6 +512,7 @@ func TestProcessRestore(t *testing.T) {
 				Status: StatusPatch{
 					Phase:            api.RestorePhase(test.expectedPhase),
 					ValidationErrors: test.expectedValidationErrors,
+					OperationID:      "operation-1",
 				},
 			}
 
@@ -411,9 +528,17 @@ func TestProcessRestore(t *testing.T) {
 
 			// validate Patch call 2 (setting phase)
 
+			expectedFinalPhase := api.RestorePhaseCompleted
+			switch {
+			case test.restorerArkError != nil || test.uploadLogError != nil:
+				expectedFinalPhase = api.RestorePhaseFailed
+			case test.expectedRestoreErrors > 0:
+				expectedFinalPhase = api.RestorePhasePartiallyFailed
+			}
+
 			expected = Patch{
 				Status: StatusPatch{
-					Phase:  api.RestorePhaseCompleted,
+					Phase:  expectedFinalPhase,
 					Errors: test.expectedRestoreErrors,
 				},
 			}
@@ -423,7 +548,9 @@ func TestProcessRestore(t *testing.T) {
 			// explicitly capturing the argument passed to Restore myself because
 			// I want to validate the called arg as of the time of calling, but
 			// the mock stores the pointer, which gets modified after
-			assert.Equal(t, *test.expectedRestorerCall, restorer.calledWithArg)
+			expectedRestorerCall := test.expectedRestorerCall.DeepCopy()
+			expectedRestorerCall.Status.OperationID = "operation-1"
+			assert.Equal(t, *expectedRestorerCall, restorer.calledWithArg)
 		})
 	}
 }
@@ -443,6 +570,21 @@ func NewRestore(ns, name, backup, includeNS, includeResource string, phase api.R
 		restore = restore.WithExcludedResource(n)
 	}
 
+	for _, n := range arkResources {
+		restore = restore.WithExcludedResource(n)
+	}
+
+	return restore
+}
+
+// NewArkResourcesRestore returns a restore like NewRestore's, but with IncludeArkResources set.
+func NewArkResourcesRestore(ns, name, backup, includeNS string, phase api.RestorePhase) *arktest.TestRestore {
+	restore := arktest.NewTestRestore(ns, name, phase).WithBackup(backup).WithIncludedNamespace(includeNS).WithIncludeArkResources(true)
+
+	for _, n := range nonRestorableResources {
+		restore = restore.WithExcludedResource(n)
+	}
+
 	return restore
 }
 
@@ -456,6 +598,7 @@ func (r *fakeRestorer) Restore(
 	backup *api.Backup,
 	backupReader io.Reader,
 	logger io.Writer,
+	planFile io.Writer,
 	actions []restore.ItemAction,
 ) (api.RestoreResult, api.RestoreResult) {
 	res := r.Called(restore, backup, backupReader, logger)
@@ -464,3 +607,108 @@ func (r *fakeRestorer) Restore(
 
 	return res.Get(0).(api.RestoreResult), res.Get(1).(api.RestoreResult)
 }
+
+func TestCheckAPIVersions(t *testing.T) {
+	backup := arktest.NewTestBackup().WithName("backup-1").Backup
+	backup.Status.ClusterInfo = &api.ClusterInfo{
+		APIResources: map[string][]string{
+			"v1":                 {"pods", "configmaps"},
+			"extensions/v1beta1": {"deployments", "ingresses"},
+		},
+	}
+	backup.Status.ItemCounts = map[string]int{"pods": 2, "deployments.extensions": 1}
+
+	// only contains pods, so the unserved deployments group version doesn't matter
+	podsBackup := backup.DeepCopy()
+	podsBackup.Status.ItemCounts = map[string]int{"pods": 2}
+
+	// backed up before item counts were recorded for all backups
+	uncountedBackup := backup.DeepCopy()
+	uncountedBackup.Status.ItemCounts = nil
+
+	tests := []struct {
+		name             string
+		backup           *api.Backup
+		restore          *api.Restore
+		action           api.APIVersionCheckAction
+		overrides        map[string]api.APIVersionCheckAction
+		expectedWarnings []string
+		expectedErrs     []string
+	}{
+		{
+			name:    "backup without cluster info is not checked",
+			backup:  arktest.NewTestBackup().WithName("backup-1").Backup,
+			restore: NewRestore("foo", "bar", "backup-1", "*", "*", api.RestorePhaseNew).Restore,
+			action:  api.APIVersionCheckActionFail,
+		},
+		{
+			name:    "backup without item counts is not checked",
+			backup:  uncountedBackup,
+			restore: NewRestore("foo", "bar", "backup-1", "*", "*", api.RestorePhaseNew).Restore,
+			action:  api.APIVersionCheckActionFail,
+		},
+		{
+			name:    "resources that aren't in the backup are not checked",
+			backup:  podsBackup,
+			restore: NewRestore("foo", "bar", "backup-1", "*", "*", api.RestorePhaseNew).Restore,
+			action:  api.APIVersionCheckActionFail,
+		},
+		{
+			name:             "unserved resources generate warnings",
+			backup:           backup,
+			restore:          NewRestore("foo", "bar", "backup-1", "*", "*", api.RestorePhaseNew).Restore,
+			action:           api.APIVersionCheckActionWarn,
+			expectedWarnings: []string{"deployments.extensions was backed up with API version extensions/v1beta1, which is not served by this cluster"},
+		},
+		{
+			name:         "unserved resources generate errors when configured to fail",
+			backup:       backup,
+			restore:      NewRestore("foo", "bar", "backup-1", "*", "*", api.RestorePhaseNew).Restore,
+			action:       api.APIVersionCheckActionFail,
+			expectedErrs: []string{"deployments.extensions was backed up with API version extensions/v1beta1, which is not served by this cluster"},
+		},
+		{
+			name:    "unserved resources are ignored when configured to ignore",
+			backup:  backup,
+			restore: NewRestore("foo", "bar", "backup-1", "*", "*", api.RestorePhaseNew).Restore,
+			action:  api.APIVersionCheckActionIgnore,
+		},
+		{
+			name:             "overrides take precedence over the default action",
+			backup:           backup,
+			restore:          NewRestore("foo", "bar", "backup-1", "*", "*", api.RestorePhaseNew).Restore,
+			action:           api.APIVersionCheckActionFail,
+			overrides:        map[string]api.APIVersionCheckAction{"deployments.extensions": api.APIVersionCheckActionWarn},
+			expectedWarnings: []string{"deployments.extensions was backed up with API version extensions/v1beta1, which is not served by this cluster"},
+		},
+		{
+			name:    "resources not included in the restore are not checked",
+			backup:  backup,
+			restore: NewRestore("foo", "bar", "backup-1", "*", "pods", api.RestorePhaseNew).Restore,
+			action:  api.APIVersionCheckActionFail,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			discoveryHelper := arktest.NewFakeDiscoveryHelper(false, map[schema.GroupVersionResource]schema.GroupVersionResource{
+				{Resource: "pods"}:                           {Version: "v1", Resource: "pods"},
+				{Resource: "configmaps"}:                     {Version: "v1", Resource: "configmaps"},
+				{Resource: "deployments"}:                    {Group: "apps", Version: "v1", Resource: "deployments"},
+				{Group: "extensions", Resource: "ingresses"}: {Group: "extensions", Version: "v1beta1", Resource: "ingresses"},
+			})
+
+			c := &restoreController{
+				logger:                   arktest.NewLogger(),
+				discoveryHelper:          discoveryHelper,
+				apiVersionCheck:          test.action,
+				apiVersionCheckOverrides: test.overrides,
+			}
+
+			warnings, errs := c.checkAPIVersions(test.restore, test.backup)
+
+			assert.Equal(t, test.expectedWarnings, warnings)
+			assert.Equal(t, test.expectedErrs, errs)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path"
+	"sync"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+// RestoreTracker keeps track of in-progress restores and the namespaces they restore into,
+// so that two restores into the same namespace don't run at the same time.
+type RestoreTracker interface {
+	// TryAdd informs the tracker that a restore into namespaces is starting, unless an
+	// in-progress restore restores into one of the same namespaces. In that case, the restore
+	// isn't added, and the in-progress restore's name is returned along with false.
+	// namespaces may include glob patterns, such as "*".
+	TryAdd(ns, name string, namespaces []string) (string, bool)
+	// Delete informs the tracker that a restore is no longer in progress.
+	Delete(ns, name string)
+}
+
+type restoreTracker struct {
+	lock     sync.Mutex
+	restores map[string][]string
+}
+
+// NewRestoreTracker returns a new RestoreTracker.
+func NewRestoreTracker() RestoreTracker {
+	return &restoreTracker{
+		restores: make(map[string][]string),
+	}
+}
+
+func (rt *restoreTracker) TryAdd(ns, name string, namespaces []string) (string, bool) {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	for key, inProgress := range rt.restores {
+		if namespacesOverlap(inProgress, namespaces) {
+			return key, false
+		}
+	}
+
+	rt.restores[trackerKey(ns, name)] = namespaces
+	return "", true
+}
+
+func (rt *restoreTracker) Delete(ns, name string) {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	delete(rt.restores, trackerKey(ns, name))
+}
+
+// restoreTargetNamespaces returns the namespaces restore restores into, after its namespace
+// mapping is applied. Patterns are returned as they are, along with every namespace that's
+// mapped to, since the namespaces they match in the backup aren't known until it's read.
+func restoreTargetNamespaces(restore *api.Restore) []string {
+	includes := restore.Spec.IncludedNamespaces
+	if len(includes) == 0 {
+		includes = []string{"*"}
+	}
+
+	var (
+		targets     []string
+		hasPatterns bool
+	)
+	for _, ns := range includes {
+		if collections.IsGlob(ns) {
+			targets = append(targets, ns)
+			hasPatterns = true
+			continue
+		}
+
+		if mapped, ok := restore.Spec.NamespaceMapping[ns]; ok {
+			ns = mapped
+		}
+		targets = append(targets, ns)
+	}
+
+	if hasPatterns {
+		for _, mapped := range restore.Spec.NamespaceMapping {
+			targets = append(targets, mapped)
+		}
+	}
+
+	return targets
+}
+
+// namespacesOverlap returns true if a and b have a namespace in common, either directly or
+// by a pattern in one matching a namespace in the other. Two patterns are always considered
+// to overlap.
+func namespacesOverlap(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			xGlob, yGlob := collections.IsGlob(x), collections.IsGlob(y)
+
+			switch {
+			case x == y:
+				return true
+			case xGlob && yGlob:
+				return true
+			case xGlob:
+				if matched, _ := path.Match(x, y); matched {
+					return true
+				}
+			case yGlob:
+				if matched, _ := path.Match(y, x); matched {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
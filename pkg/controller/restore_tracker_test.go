@@ -0,0 +1,104 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+func TestRestoreTracker(t *testing.T) {
+	rt := NewRestoreTracker()
+
+	_, ok := rt.TryAdd("ns", "restore-1", []string{"ns-1", "ns-2"})
+	assert.True(t, ok)
+
+	// no overlap
+	_, ok = rt.TryAdd("ns", "restore-2", []string{"ns-3"})
+	assert.True(t, ok)
+
+	// overlaps restore-1
+	conflict, ok := rt.TryAdd("ns", "restore-3", []string{"ns-2"})
+	assert.False(t, ok)
+	assert.Equal(t, "ns/restore-1", conflict)
+
+	// once restore-1 is done, its namespaces are free
+	rt.Delete("ns", "restore-1")
+	_, ok = rt.TryAdd("ns", "restore-3", []string{"ns-2"})
+	assert.True(t, ok)
+
+	// a pattern overlaps the namespaces it matches
+	conflict, ok = rt.TryAdd("ns", "restore-4", []string{"ns-*"})
+	assert.False(t, ok)
+	assert.Contains(t, []string{"ns/restore-2", "ns/restore-3"}, conflict)
+}
+
+func TestRestoreTargetNamespaces(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     api.RestoreSpec
+		expected []string
+	}{
+		{
+			name:     "no included namespaces restores into every namespace",
+			expected: []string{"*"},
+		},
+		{
+			name: "mapped namespaces are replaced",
+			spec: api.RestoreSpec{
+				IncludedNamespaces: []string{"ns-1", "ns-2"},
+				NamespaceMapping:   map[string]string{"ns-1": "ns-1-copy"},
+			},
+			expected: []string{"ns-1-copy", "ns-2"},
+		},
+		{
+			name: "patterns include every namespace mapped to",
+			spec: api.RestoreSpec{
+				IncludedNamespaces: []string{"app-*"},
+				NamespaceMapping:   map[string]string{"app-1": "copy-1"},
+			},
+			expected: []string{"app-*", "copy-1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, restoreTargetNamespaces(&api.Restore{Spec: test.spec}))
+		})
+	}
+}
+
+func TestNamespacesOverlap(t *testing.T) {
+	tests := []struct {
+		a, b     []string
+		expected bool
+	}{
+		{a: []string{"ns-1"}, b: []string{"ns-2"}, expected: false},
+		{a: []string{"ns-1", "ns-2"}, b: []string{"ns-2"}, expected: true},
+		{a: []string{"*"}, b: []string{"ns-2"}, expected: true},
+		{a: []string{"ns-2"}, b: []string{"kube-*"}, expected: false},
+		{a: []string{"kube-system"}, b: []string{"kube-*"}, expected: true},
+		{a: []string{"app-*"}, b: []string{"db-*"}, expected: true},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, namespacesOverlap(test.a, test.b), "a=%v, b=%v", test.a, test.b)
+	}
+}
@@ -17,10 +17,14 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
@@ -33,6 +37,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
@@ -53,6 +58,7 @@ type scheduleController struct {
 	syncHandler           func(scheduleName string) error
 	queue                 workqueue.RateLimitingInterface
 	syncPeriod            time.Duration
+	clusterName           string
 	clock                 clock.Clock
 	logger                logrus.FieldLogger
 }
@@ -63,6 +69,7 @@ func NewScheduleController(
 	backupsClient arkv1client.BackupsGetter,
 	schedulesInformer informers.ScheduleInformer,
 	syncPeriod time.Duration,
+	clusterName string,
 	logger logrus.FieldLogger,
 ) *scheduleController {
 	if syncPeriod < time.Minute {
@@ -78,6 +85,7 @@ func NewScheduleController(
 		schedulesListerSynced: schedulesInformer.Informer().HasSynced,
 		queue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "schedule"),
 		syncPeriod: syncPeriod,
+		clusterName: clusterName,
 		clock:      clock.RealClock{},
 		logger:     logger,
 	}
@@ -246,6 +254,24 @@ func (controller *scheduleController) processSchedule(key string) error {
 	currentPhase := schedule.Status.Phase
 
 	cronSchedule, errs := parseCronSchedule(schedule, controller.logger)
+	if _, err := getBackupName(schedule, controller.clock.Now(), controller.clusterName); err != nil {
+		errs = append(errs, fmt.Sprintf("invalid name template: %v", err))
+	} else if cronSchedule != nil {
+		if err := checkBackupNamesUnique(schedule, cronSchedule, controller.clock.Now(), controller.clusterName); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid name template: %v", err))
+		}
+	}
+	switch schedule.Spec.ConcurrencyPolicy {
+	case "", api.ConcurrencyPolicyAllow, api.ConcurrencyPolicyForbid:
+	default:
+		errs = append(errs, fmt.Sprintf("invalid concurrency policy %q", schedule.Spec.ConcurrencyPolicy))
+	}
+	if _, err := time.LoadLocation(schedule.Spec.TimeZone); err != nil {
+		errs = append(errs, fmt.Sprintf("invalid time zone %q: %v", schedule.Spec.TimeZone, err))
+	}
+	if schedule.Spec.Jitter.Duration < 0 {
+		errs = append(errs, "jitter must not be negative")
+	}
 	if len(errs) > 0 {
 		schedule.Status.Phase = api.SchedulePhaseFailedValidation
 		schedule.Status.ValidationErrors = errs
@@ -326,21 +352,37 @@ func (controller *scheduleController) submitBackupIfDue(item *api.Schedule, cron
 		return nil
 	}
 
+	original := item
+	schedule := item.DeepCopy()
+
 	// Don't attempt to "catch up" if there are any missed or failed runs - simply
-	// trigger a Backup if it's time.
-	//
-	// It might also make sense in the future to explicitly check for currently-running
-	// backups so that we don't overlap runs (for disk snapshots in particular, this can
-	// lead to performance issues).
+	// trigger a Backup if it's time, unless the concurrency policy says otherwise.
+	if item.Spec.ConcurrencyPolicy == api.ConcurrencyPolicyForbid {
+		unfinished, err := controller.getUnfinishedBackups(item)
+		if err != nil {
+			return err
+		}
+
+		if len(unfinished) > 0 {
+			logContext.WithField("nextRunTime", nextRunTime).Info("Schedule is due, but has unfinished backups and its concurrency policy is Forbid, skipping")
+
+			schedule.Status.LastSkipped = metav1.NewTime(now)
+			if _, err := patchSchedule(original, schedule, controller.schedulesClient); err != nil {
+				return errors.Wrapf(err, "error updating Schedule's LastSkipped time to %v", schedule.Status.LastSkipped)
+			}
+			return nil
+		}
+	}
+
 	logContext.WithField("nextRunTime", nextRunTime).Info("Schedule is due, submitting Backup")
-	backup := getBackup(item, now)
+	backup, err := getBackup(item, now, controller.clusterName)
+	if err != nil {
+		return err
+	}
 	if _, err := controller.backupsClient.Backups(backup.Namespace).Create(backup); err != nil {
 		return errors.Wrap(err, "error creating Backup")
 	}
 
-	original := item
-	schedule := item.DeepCopy()
-
 	schedule.Status.LastBackup = metav1.NewTime(now)
 
 	if _, err := patchSchedule(original, schedule, controller.schedulesClient); err != nil {
@@ -350,29 +392,166 @@ func (controller *scheduleController) submitBackupIfDue(item *api.Schedule, cron
 	return nil
 }
 
+// getUnfinishedBackups returns the schedule's backups that haven't started or are in progress.
+func (controller *scheduleController) getUnfinishedBackups(item *api.Schedule) ([]*api.Backup, error) {
+	backups, err := controller.backupsClient.Backups(item.Namespace).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{api.ScheduleLabel: item.Name}).String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing the schedule's Backups")
+	}
+
+	var unfinished []*api.Backup
+	for i := range backups.Items {
+		switch backups.Items[i].Status.Phase {
+		case "", api.BackupPhaseNew, api.BackupPhaseInProgress:
+			unfinished = append(unfinished, &backups.Items[i])
+		}
+	}
+
+	return unfinished, nil
+}
+
 func getNextRunTime(schedule *api.Schedule, cronSchedule cron.Schedule, asOf time.Time) (bool, time.Time) {
 	// get the latest run time (if the schedule hasn't run yet, this will be the zero value which will trigger
-	// an immediate backup)
-	lastBackupTime := schedule.Status.LastBackup.Time
+	// an immediate backup). A skipped run counts as a run.
+	lastRunTime := schedule.Status.LastBackup.Time
+	if schedule.Status.LastSkipped.After(lastRunTime) {
+		lastRunTime = schedule.Status.LastSkipped.Time
+	}
 
-	nextRunTime := cronSchedule.Next(lastBackupTime)
+	// the cron expression is evaluated in the schedule's time zone. An invalid one fails
+	// validation, so it's never used here.
+	if schedule.Spec.TimeZone != "" {
+		if location, err := time.LoadLocation(schedule.Spec.TimeZone); err == nil {
+			lastRunTime = lastRunTime.In(location)
+		}
+	}
+
+	nextRunTime := cronSchedule.Next(lastRunTime).Add(jitterOffset(schedule))
 
 	return asOf.After(nextRunTime), nextRunTime
 }
 
-func getBackup(item *api.Schedule, timestamp time.Time) *api.Backup {
+// jitterOffset returns how long to delay the schedule's runs, between zero and its jitter. A
+// schedule's offset is derived from its namespace and name, so it's the same for every run.
+func jitterOffset(schedule *api.Schedule) time.Duration {
+	if schedule.Spec.Jitter.Duration <= 0 {
+		return 0
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(schedule.Namespace + "/" + schedule.Name))
+
+	return time.Duration(hash.Sum64() % uint64(schedule.Spec.Jitter.Duration))
+}
+
+func getBackup(item *api.Schedule, timestamp time.Time, clusterName string) (*api.Backup, error) {
+	name, err := getBackupName(item, timestamp, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
 	backup := &api.Backup{
 		Spec: item.Spec.Template,
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: item.Namespace,
-			Name:      fmt.Sprintf("%s-%s", item.Name, timestamp.Format("20060102150405")),
+			Name:      name,
 			Labels: map[string]string{
-				"ark-schedule": item.Name,
+				api.ScheduleLabel: item.Name,
+			},
+			Annotations: map[string]string{
+				api.RequestedByAnnotation: "schedule/" + item.Name,
 			},
 		},
 	}
 
-	return backup
+	return backup, nil
+}
+
+// backupNameTemplateData is the data available to a schedule's name template.
+type backupNameTemplateData struct {
+	ScheduleName string
+	ClusterName  string
+	Timestamp    time.Time
+
+	Year   string
+	Month  string
+	Day    string
+	Hour   string
+	Minute string
+	Second string
+}
+
+// getBackupName returns the name for a backup created by the schedule at the given time,
+// using the schedule's name template if it has one. It returns an error if the template is
+// invalid or doesn't produce a valid object name.
+func getBackupName(item *api.Schedule, timestamp time.Time, clusterName string) (string, error) {
+	if item.Spec.NameTemplate == "" {
+		return fmt.Sprintf("%s-%s", item.Name, timestamp.Format("20060102150405")), nil
+	}
+
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(item.Spec.NameTemplate)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	data := backupNameTemplateData{
+		ScheduleName: item.Name,
+		ClusterName:  clusterName,
+		Timestamp:    timestamp,
+		Year:         timestamp.Format("2006"),
+		Month:        timestamp.Format("01"),
+		Day:          timestamp.Format("02"),
+		Hour:         timestamp.Format("15"),
+		Minute:       timestamp.Format("04"),
+		Second:       timestamp.Format("05"),
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	name := buf.String()
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", errors.Errorf("%q is not a valid backup name: %s", name, strings.Join(errs, "; "))
+	}
+
+	return name, nil
+}
+
+// checkBackupNamesUnique returns an error if the schedule's name template gives any two of its
+// next few runs after now the same backup name, since creating the backup would fail for all
+// but the first of them.
+func checkBackupNamesUnique(schedule *api.Schedule, cronSchedule cron.Schedule, now time.Time, clusterName string) error {
+	if schedule.Spec.NameTemplate == "" {
+		return nil
+	}
+
+	if schedule.Spec.TimeZone != "" {
+		if location, err := time.LoadLocation(schedule.Spec.TimeZone); err == nil {
+			now = now.In(location)
+		}
+	}
+
+	runTimes := make(map[string]time.Time)
+	runTime := now
+	for i := 0; i < 10; i++ {
+		runTime = cronSchedule.Next(runTime)
+
+		name, err := getBackupName(schedule, runTime, clusterName)
+		if err != nil {
+			return err
+		}
+
+		if earlier, ok := runTimes[name]; ok {
+			return errors.Errorf("the runs at %s and %s would both be named %q; include enough of the time in the template to tell them apart", earlier.Format(time.RFC3339), runTime.Format(time.RFC3339), name)
+		}
+		runTimes[name] = runTime
+	}
+
+	return nil
 }
 
 func patchSchedule(original, updated *api.Schedule, client arkv1client.SchedulesGetter) (*api.Schedule, error) {
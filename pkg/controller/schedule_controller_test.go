@@ -86,13 +86,34 @@ func TestProcessSchedule(t *testing.T) {
 			expectedPhase:            string(api.SchedulePhaseFailedValidation),
 			expectedValidationErrors: []string{"Schedule must be a non-empty valid Cron expression"},
 		},
+		{
+			name:                     "schedule with an invalid concurrency policy fails validation",
+			schedule:                 arktest.NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseNew).WithCronSchedule("@every 5m").WithConcurrencyPolicy("Replace").Schedule,
+			expectedErr:              false,
+			expectedPhase:            string(api.SchedulePhaseFailedValidation),
+			expectedValidationErrors: []string{`invalid concurrency policy "Replace"`},
+		},
+		{
+			name:                     "schedule with an invalid time zone fails validation",
+			schedule:                 arktest.NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseNew).WithCronSchedule("@every 5m").WithTimeZone("Mars/Olympus_Mons").Schedule,
+			expectedErr:              false,
+			expectedPhase:            string(api.SchedulePhaseFailedValidation),
+			expectedValidationErrors: []string{`invalid time zone "Mars/Olympus_Mons": unknown time zone Mars/Olympus_Mons`},
+		},
+		{
+			name:                     "schedule with a negative jitter fails validation",
+			schedule:                 arktest.NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseNew).WithCronSchedule("@every 5m").WithJitter(-time.Minute).Schedule,
+			expectedErr:              false,
+			expectedPhase:            string(api.SchedulePhaseFailedValidation),
+			expectedValidationErrors: []string{"jitter must not be negative"},
+		},
 		{
 			name:                 "schedule with phase New gets validated and triggers a backup",
 			schedule:             arktest.NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseNew).WithCronSchedule("@every 5m").Schedule,
 			fakeClockTime:        "2017-01-01 12:00:00",
 			expectedErr:          false,
 			expectedPhase:        string(api.SchedulePhaseEnabled),
-			expectedBackupCreate: arktest.NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").Backup,
+			expectedBackupCreate: arktest.NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").WithAnnotation("ark.heptio.com/requested-by", "schedule/name").Backup,
 			expectedLastBackup:   "2017-01-01 12:00:00",
 		},
 		{
@@ -100,7 +121,7 @@ func TestProcessSchedule(t *testing.T) {
 			schedule:             arktest.NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").Schedule,
 			fakeClockTime:        "2017-01-01 12:00:00",
 			expectedErr:          false,
-			expectedBackupCreate: arktest.NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").Backup,
+			expectedBackupCreate: arktest.NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").WithAnnotation("ark.heptio.com/requested-by", "schedule/name").Backup,
 			expectedLastBackup:   "2017-01-01 12:00:00",
 		},
 		{
@@ -109,7 +130,7 @@ func TestProcessSchedule(t *testing.T) {
 				WithCronSchedule("@every 5m").WithLastBackupTime("2000-01-01 00:00:00").Schedule,
 			fakeClockTime:        "2017-01-01 12:00:00",
 			expectedErr:          false,
-			expectedBackupCreate: arktest.NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").Backup,
+			expectedBackupCreate: arktest.NewTestBackup().WithNamespace("ns").WithName("name-20170101120000").WithLabel("ark-schedule", "name").WithAnnotation("ark.heptio.com/requested-by", "schedule/name").Backup,
 			expectedLastBackup:   "2017-01-01 12:00:00",
 		},
 	}
@@ -128,6 +149,7 @@ func TestProcessSchedule(t *testing.T) {
 				client.ArkV1(),
 				sharedInformers.Ark().V1().Schedules(),
 				time.Duration(0),
+				"",
 				logger,
 			)
 
@@ -255,11 +277,112 @@ func parseTime(timeString string) time.Time {
 	return res
 }
 
+func TestSubmitBackupIfDueConcurrencyPolicy(t *testing.T) {
+	newBackup := func(name string, phase api.BackupPhase) *api.Backup {
+		return arktest.NewTestBackup().WithNamespace("ns").WithName(name).WithLabel(api.ScheduleLabel, "name").WithPhase(phase).Backup
+	}
+
+	tests := []struct {
+		name                string
+		policy              api.ConcurrencyPolicy
+		backups             []*api.Backup
+		expectedCreate      bool
+		expectedLastSkipped bool
+	}{
+		{
+			name:           "Allow creates a backup while another is in progress",
+			policy:         api.ConcurrencyPolicyAllow,
+			backups:        []*api.Backup{newBackup("name-1", api.BackupPhaseInProgress)},
+			expectedCreate: true,
+		},
+		{
+			name:                "Forbid skips the run while a backup is in progress",
+			policy:              api.ConcurrencyPolicyForbid,
+			backups:             []*api.Backup{newBackup("name-1", api.BackupPhaseInProgress)},
+			expectedLastSkipped: true,
+		},
+		{
+			name:                "Forbid skips the run while a backup hasn't started",
+			policy:              api.ConcurrencyPolicyForbid,
+			backups:             []*api.Backup{newBackup("name-1", "")},
+			expectedLastSkipped: true,
+		},
+		{
+			name:   "Forbid creates a backup when the others are finished",
+			policy: api.ConcurrencyPolicyForbid,
+			backups: []*api.Backup{
+				newBackup("name-1", api.BackupPhaseCompleted),
+				newBackup("name-2", api.BackupPhaseFailed),
+				// backups from other schedules are ignored
+				arktest.NewTestBackup().WithNamespace("ns").WithName("other-1").WithLabel(api.ScheduleLabel, "other").WithPhase(api.BackupPhaseInProgress).Backup,
+			},
+			expectedCreate: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var objs []runtime.Object
+			for _, backup := range test.backups {
+				objs = append(objs, backup)
+			}
+
+			var (
+				client          = fake.NewSimpleClientset(objs...)
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+				schedule        = arktest.NewTestSchedule("ns", "name").WithPhase(api.SchedulePhaseEnabled).WithCronSchedule("@every 5m").WithConcurrencyPolicy(test.policy).Schedule
+				now             = time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+			)
+
+			c := NewScheduleController(
+				"namespace",
+				client.ArkV1(),
+				client.ArkV1(),
+				sharedInformers.Ark().V1().Schedules(),
+				time.Duration(0),
+				"",
+				arktest.NewLogger(),
+			)
+			c.clock = clock.NewFakeClock(now)
+
+			var patched *api.Schedule
+			client.PrependReactor("patch", "schedules", func(action core.Action) (bool, runtime.Object, error) {
+				patched = schedule.DeepCopy()
+				require.NoError(t, json.Unmarshal(action.(core.PatchAction).GetPatch(), patched))
+				return true, patched, nil
+			})
+
+			cronSchedule, err := cron.ParseStandard(schedule.Spec.Schedule)
+			require.NoError(t, err)
+
+			require.NoError(t, c.submitBackupIfDue(schedule, cronSchedule))
+
+			var created bool
+			for _, action := range client.Actions() {
+				if action.Matches("create", "backups") {
+					created = true
+				}
+			}
+			assert.Equal(t, test.expectedCreate, created)
+
+			require.NotNil(t, patched)
+			if test.expectedLastSkipped {
+				assert.True(t, patched.Status.LastSkipped.Time.Equal(now))
+				assert.True(t, patched.Status.LastBackup.IsZero())
+			} else {
+				assert.True(t, patched.Status.LastSkipped.IsZero())
+				assert.True(t, patched.Status.LastBackup.Time.Equal(now))
+			}
+		})
+	}
+}
+
 func TestGetNextRunTime(t *testing.T) {
 	tests := []struct {
 		name                      string
 		schedule                  *api.Schedule
 		lastRanOffset             string
+		lastSkippedOffset         string
 		expectedDue               bool
 		expectedNextRunTimeOffset string
 	}{
@@ -297,6 +420,14 @@ func TestGetNextRunTime(t *testing.T) {
 			expectedDue:               true,
 			expectedNextRunTimeOffset: "5m",
 		},
+		{
+			name:                      "skipped more recently than last backup",
+			schedule:                  &api.Schedule{Spec: api.ScheduleSpec{Schedule: "@every 5m"}},
+			lastRanOffset:             "4m",
+			lastSkippedOffset:         "1m",
+			expectedDue:               false,
+			expectedNextRunTimeOffset: "5m",
+		},
 	}
 
 	for _, test := range tests {
@@ -313,11 +444,20 @@ func TestGetNextRunTime(t *testing.T) {
 				test.schedule.Status.LastBackup = metav1.Time{Time: testClock.Now().Add(-offsetDuration)}
 			}
 
+			lastRunTime := test.schedule.Status.LastBackup
+			if test.lastSkippedOffset != "" {
+				offsetDuration, err := time.ParseDuration(test.lastSkippedOffset)
+				require.NoError(t, err, "unable to parse test.lastSkippedOffset: %v", err)
+
+				test.schedule.Status.LastSkipped = metav1.Time{Time: testClock.Now().Add(-offsetDuration)}
+				lastRunTime = test.schedule.Status.LastSkipped
+			}
+
 			nextRunTimeOffset, err := time.ParseDuration(test.expectedNextRunTimeOffset)
 			if err != nil {
 				panic(err)
 			}
-			expectedNextRunTime := test.schedule.Status.LastBackup.Add(nextRunTimeOffset)
+			expectedNextRunTime := lastRunTime.Add(nextRunTimeOffset)
 
 			due, nextRunTime := getNextRunTime(test.schedule, cronSchedule, testClock.Now())
 
@@ -328,6 +468,52 @@ func TestGetNextRunTime(t *testing.T) {
 	}
 }
 
+func TestGetNextRunTimeInTimeZone(t *testing.T) {
+	cronSchedule, err := cron.ParseStandard("0 9 * * *")
+	require.NoError(t, err)
+
+	// 09:00 in New York, which is 14:00 UTC in January
+	lastBackup := time.Date(2018, 1, 1, 14, 0, 0, 0, time.UTC)
+
+	schedule := arktest.NewTestSchedule("ns", "name").WithTimeZone("America/New_York").Schedule
+	schedule.Status.LastBackup = metav1.NewTime(lastBackup)
+
+	_, nextRunTime := getNextRunTime(schedule, cronSchedule, lastBackup)
+	assert.True(t, nextRunTime.Equal(time.Date(2018, 1, 2, 14, 0, 0, 0, time.UTC)), "unexpected next run time %v", nextRunTime)
+
+	// without a time zone, the expression is evaluated in the zone of the last backup time,
+	// which is the server's local time zone when it's read from the API
+	schedule.Spec.TimeZone = ""
+
+	_, nextRunTime = getNextRunTime(schedule, cronSchedule, lastBackup)
+	assert.True(t, nextRunTime.Equal(time.Date(2018, 1, 2, 9, 0, 0, 0, time.UTC)), "unexpected next run time %v", nextRunTime)
+}
+
+func TestJitterOffset(t *testing.T) {
+	schedule := arktest.NewTestSchedule("ns", "name").WithCronSchedule("@every 1h").Schedule
+	assert.Equal(t, time.Duration(0), jitterOffset(schedule))
+
+	schedule.Spec.Jitter = metav1.Duration{Duration: 10 * time.Minute}
+	offset := jitterOffset(schedule)
+	assert.True(t, offset >= 0 && offset < 10*time.Minute, "offset %v isn't within the jitter", offset)
+	assert.Equal(t, offset, jitterOffset(schedule.DeepCopy()), "offset isn't stable")
+
+	other := schedule.DeepCopy()
+	other.Name = "other"
+	assert.NotEqual(t, offset, jitterOffset(other))
+
+	cronSchedule, err := cron.Parse(schedule.Spec.Schedule)
+	require.NoError(t, err)
+
+	lastBackup := time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC)
+	schedule.Status.LastBackup = metav1.NewTime(lastBackup)
+
+	// the run isn't due at the time given by the cron expression
+	due, nextRunTime := getNextRunTime(schedule, cronSchedule, lastBackup.Add(time.Hour))
+	assert.False(t, due)
+	assert.Equal(t, lastBackup.Add(time.Hour+offset), nextRunTime)
+}
+
 func TestParseCronSchedule(t *testing.T) {
 	// From https://github.com/heptio/ark/issues/30, where we originally were using cron.Parse(),
 	// which treats the first field as seconds, and not minutes. We want to use cron.ParseStandard()
@@ -383,8 +569,10 @@ func TestGetBackup(t *testing.T) {
 	tests := []struct {
 		name           string
 		schedule       *api.Schedule
+		clusterName    string
 		testClockTime  string
 		expectedBackup *api.Backup
+		expectedErr    bool
 	}{
 		{
 			name: "ensure name is formatted correctly (AM time)",
@@ -460,6 +648,73 @@ func TestGetBackup(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "name template is used when specified",
+			schedule: &api.Schedule{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Spec: api.ScheduleSpec{
+					NameTemplate: "{{.ClusterName}}-{{.ScheduleName}}-{{.Year}}.{{.Month}}.{{.Day}}-{{.Hour}}{{.Minute}}{{.Second}}",
+				},
+			},
+			clusterName:   "prod",
+			testClockTime: "2017-07-25 14:15:00",
+			expectedBackup: &api.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "prod-bar-2017.07.25-141500",
+				},
+			},
+		},
+		{
+			name: "name template can format the timestamp",
+			schedule: &api.Schedule{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Spec: api.ScheduleSpec{
+					NameTemplate: `daily-{{.Timestamp.Format "2006-01-02"}}`,
+				},
+			},
+			testClockTime: "2017-07-25 14:15:00",
+			expectedBackup: &api.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "daily-2017-07-25",
+				},
+			},
+		},
+		{
+			name: "name template referencing an unknown field returns an error",
+			schedule: &api.Schedule{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Spec: api.ScheduleSpec{
+					NameTemplate: "{{.Unknown}}",
+				},
+			},
+			testClockTime: "2017-07-25 14:15:00",
+			expectedErr:   true,
+		},
+		{
+			name: "name template producing an invalid name returns an error",
+			schedule: &api.Schedule{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "bar",
+				},
+				Spec: api.ScheduleSpec{
+					NameTemplate: "{{.ScheduleName}}_{{.Year}}",
+				},
+			},
+			testClockTime: "2017-07-25 14:15:00",
+			expectedErr:   true,
+		},
 	}
 
 	for _, test := range tests {
@@ -467,11 +722,67 @@ func TestGetBackup(t *testing.T) {
 			testTime, err := time.Parse("2006-01-02 15:04:05", test.testClockTime)
 			require.NoError(t, err, "unable to parse test.testClockTime: %v", err)
 
-			backup := getBackup(test.schedule, clock.NewFakeClock(testTime).Now())
+			backup, err := getBackup(test.schedule, clock.NewFakeClock(testTime).Now(), test.clusterName)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
 
 			assert.Equal(t, test.expectedBackup.Namespace, backup.Namespace)
 			assert.Equal(t, test.expectedBackup.Name, backup.Name)
 			assert.Equal(t, test.expectedBackup.Spec, backup.Spec)
+			assert.Equal(t, "schedule/"+test.schedule.Name, backup.Annotations[api.RequestedByAnnotation])
+		})
+	}
+}
+
+func TestCheckBackupNamesUnique(t *testing.T) {
+	now := time.Date(2018, 1, 1, 1, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		cron         string
+		nameTemplate string
+		expectedErr  string
+	}{
+		{
+			name: "default names are unique",
+			cron: "@every 1m",
+		},
+		{
+			name:         "template with the date is unique for a daily schedule",
+			cron:         "0 1 * * *",
+			nameTemplate: "{{.ScheduleName}}-{{.Year}}{{.Month}}{{.Day}}",
+		},
+		{
+			name:         "template with the date isn't unique for a schedule that runs twice a day",
+			cron:         "0 1,2 * * *",
+			nameTemplate: "{{.ScheduleName}}-{{.Year}}{{.Month}}{{.Day}}",
+			expectedErr:  `the runs at 2018-01-02T01:00:00Z and 2018-01-02T02:00:00Z would both be named "name-20180102"; include enough of the time in the template to tell them apart`,
+		},
+		{
+			name:         "template without the time isn't unique",
+			cron:         "0 1 * * *",
+			nameTemplate: "{{.ClusterName}}-{{.ScheduleName}}",
+			expectedErr:  `the runs at 2018-01-02T01:00:00Z and 2018-01-03T01:00:00Z would both be named "prod-name"; include enough of the time in the template to tell them apart`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cronSchedule, err := cron.ParseStandard(test.cron)
+			require.NoError(t, err)
+
+			schedule := arktest.NewTestSchedule("ns", "name").WithCronSchedule(test.cron).Schedule
+			schedule.Spec.NameTemplate = test.nameTemplate
+
+			err = checkBackupNamesUnique(schedule, cronSchedule, now, "prod")
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedErr)
+			}
 		})
 	}
 }
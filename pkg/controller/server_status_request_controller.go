@@ -0,0 +1,164 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/buildinfo"
+	arkv1client "github.com/heptio/ark/pkg/generated/clientset/versioned/typed/ark/v1"
+	informers "github.com/heptio/ark/pkg/generated/informers/externalversions/ark/v1"
+	listers "github.com/heptio/ark/pkg/generated/listers/ark/v1"
+)
+
+// serverStatusRequestTTL is how long a processed ServerStatusRequest is kept before it's
+// deleted, in case the client that created it didn't delete it.
+const serverStatusRequestTTL = time.Minute
+
+type serverStatusRequestController struct {
+	*genericController
+
+	serverStatusRequestClient arkv1client.ServerStatusRequestsGetter
+	serverStatusRequestLister listers.ServerStatusRequestLister
+	operationTracker          OperationTracker
+	clock                     clock.Clock
+}
+
+// NewServerStatusRequestController creates a new controller that responds to
+// ServerStatusRequests with the server's version and the operations operationTracker is
+// tracking.
+func NewServerStatusRequestController(
+	logger logrus.FieldLogger,
+	serverStatusRequestClient arkv1client.ServerStatusRequestsGetter,
+	serverStatusRequestInformer informers.ServerStatusRequestInformer,
+	operationTracker OperationTracker,
+) Interface {
+	c := &serverStatusRequestController{
+		genericController:         newGenericController("serverstatusrequest", logger),
+		serverStatusRequestClient: serverStatusRequestClient,
+		serverStatusRequestLister: serverStatusRequestInformer.Lister(),
+		operationTracker:          operationTracker,
+		clock:                     &clock.RealClock{},
+	}
+
+	c.syncHandler = c.processServerStatusRequest
+	c.cacheSyncWaiters = append(c.cacheSyncWaiters, serverStatusRequestInformer.Informer().HasSynced)
+
+	c.resyncPeriod = serverStatusRequestTTL
+	c.resyncFunc = c.enqueueAllServerStatusRequests
+
+	serverStatusRequestInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: c.enqueue,
+		},
+	)
+
+	return c
+}
+
+// enqueueAllServerStatusRequests requeues all the ServerStatusRequests in the lister's cache,
+// so that processed requests that their clients didn't delete are deleted once they expire.
+func (c *serverStatusRequestController) enqueueAllServerStatusRequests() {
+	requests, err := c.serverStatusRequestLister.List(labels.Everything())
+	if err != nil {
+		c.logger.WithError(errors.WithStack(err)).Error("Error listing ServerStatusRequests")
+		return
+	}
+
+	for _, req := range requests {
+		c.enqueue(req)
+	}
+}
+
+func (c *serverStatusRequestController) processServerStatusRequest(key string) error {
+	log := c.logger.WithField("key", key)
+
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return errors.Wrap(err, "error splitting queue key")
+	}
+
+	req, err := c.serverStatusRequestLister.ServerStatusRequests(ns).Get(name)
+	if apierrors.IsNotFound(err) {
+		log.Debug("Unable to find ServerStatusRequest")
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "error getting ServerStatusRequest")
+	}
+
+	switch req.Status.Phase {
+	case "", api.ServerStatusRequestPhaseNew:
+		log.Debug("Processing ServerStatusRequest")
+
+		updated := req.DeepCopy()
+		updated.Status.Phase = api.ServerStatusRequestPhaseProcessed
+		updated.Status.ProcessedTimestamp = metav1.NewTime(c.clock.Now())
+		updated.Status.ServerVersion = buildinfo.Version
+		updated.Status.Operations = c.operationTracker.List()
+
+		return c.patchServerStatusRequest(req, updated)
+	case api.ServerStatusRequestPhaseProcessed:
+		if c.clock.Now().Sub(req.Status.ProcessedTimestamp.Time) < serverStatusRequestTTL {
+			return nil
+		}
+
+		log.Debug("ServerStatusRequest has expired - deleting")
+		err := c.serverStatusRequestClient.ServerStatusRequests(req.Namespace).Delete(req.Name, nil)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "error deleting ServerStatusRequest")
+		}
+	}
+
+	return nil
+}
+
+func (c *serverStatusRequestController) patchServerStatusRequest(original, updated *api.ServerStatusRequest) error {
+	origBytes, err := json.Marshal(original)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling original ServerStatusRequest")
+	}
+
+	updatedBytes, err := json.Marshal(updated)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling updated ServerStatusRequest")
+	}
+
+	patchBytes, err := jsonpatch.CreateMergePatch(origBytes, updatedBytes)
+	if err != nil {
+		return errors.Wrap(err, "error creating json merge patch for ServerStatusRequest")
+	}
+
+	if _, err := c.serverStatusRequestClient.ServerStatusRequests(original.Namespace).Patch(original.Name, types.MergePatchType, patchBytes); err != nil {
+		return errors.Wrap(err, "error patching ServerStatusRequest")
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	core "k8s.io/client-go/testing"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/buildinfo"
+	"github.com/heptio/ark/pkg/generated/clientset/versioned/fake"
+	informers "github.com/heptio/ark/pkg/generated/informers/externalversions"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestProcessServerStatusRequest(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	originalVersion := buildinfo.Version
+	buildinfo.Version = "v1.0.0"
+	defer func() { buildinfo.Version = originalVersion }()
+
+	newRequest := func(phase api.ServerStatusRequestPhase, processed time.Time) *api.ServerStatusRequest {
+		return &api.ServerStatusRequest{
+			ObjectMeta: metav1.ObjectMeta{Namespace: api.DefaultNamespace, Name: "ssr-1"},
+			Status: api.ServerStatusRequestStatus{
+				Phase:              phase,
+				ProcessedTimestamp: metav1.NewTime(processed),
+			},
+		}
+	}
+
+	tests := []struct {
+		name              string
+		req               *api.ServerStatusRequest
+		operations        [][3]string
+		expectedProcessed bool
+		expectedDelete    bool
+	}{
+		{
+			name: "missing request is ignored",
+		},
+		{
+			name:              "request with phase '' is processed",
+			req:               newRequest("", time.Time{}),
+			expectedProcessed: true,
+		},
+		{
+			name:              "new request is processed with the tracked operations",
+			req:               newRequest(api.ServerStatusRequestPhaseNew, time.Time{}),
+			operations:        [][3]string{{"Backup", "ns-1", "backup-1"}, {"Restore", "ns-1", "restore-1"}},
+			expectedProcessed: true,
+		},
+		{
+			name: "processed request that hasn't expired is left alone",
+			req:  newRequest(api.ServerStatusRequestPhaseProcessed, now.Add(-30*time.Second)),
+		},
+		{
+			name:           "expired processed request is deleted",
+			req:            newRequest(api.ServerStatusRequestPhaseProcessed, now.Add(-2*time.Minute)),
+			expectedDelete: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				client          = fake.NewSimpleClientset()
+				sharedInformers = informers.NewSharedInformerFactory(client, 0)
+				tracker         = NewOperationTracker().(*operationTracker)
+			)
+			tracker.clock = clock.NewFakeClock(now)
+
+			c := NewServerStatusRequestController(
+				arktest.NewLogger(),
+				client.ArkV1(),
+				sharedInformers.Ark().V1().ServerStatusRequests(),
+				tracker,
+			).(*serverStatusRequestController)
+			c.clock = clock.NewFakeClock(now)
+
+			if test.req != nil {
+				require.NoError(t, sharedInformers.Ark().V1().ServerStatusRequests().Informer().GetStore().Add(test.req))
+			}
+			for _, op := range test.operations {
+				tracker.Add(api.OperationKind(op[0]), op[1], op[2])
+			}
+
+			require.NoError(t, c.processServerStatusRequest(api.DefaultNamespace+"/ssr-1"))
+
+			actions := client.Actions()
+
+			switch {
+			case test.expectedProcessed:
+				require.Len(t, actions, 1)
+
+				type PatchStatus struct {
+					Phase              api.ServerStatusRequestPhase `json:"phase"`
+					ProcessedTimestamp time.Time                    `json:"processedTimestamp"`
+					ServerVersion      string                       `json:"serverVersion"`
+					Operations         []api.Operation              `json:"operations"`
+				}
+
+				type Patch struct {
+					Status PatchStatus `json:"status"`
+				}
+
+				decode := func(decoder *json.Decoder) (interface{}, error) {
+					actual := new(Patch)
+					err := decoder.Decode(actual)
+
+					return *actual, err
+				}
+
+				// metav1.Time decodes into the local time zone
+				operations := tracker.List()
+				for i := range operations {
+					operations[i].StartTimestamp = metav1.NewTime(operations[i].StartTimestamp.Local())
+				}
+
+				expected := Patch{
+					Status: PatchStatus{
+						Phase:              api.ServerStatusRequestPhaseProcessed,
+						ProcessedTimestamp: now,
+						ServerVersion:      "v1.0.0",
+						Operations:         operations,
+					},
+				}
+
+				arktest.ValidatePatch(t, actions[0], expected, decode)
+			case test.expectedDelete:
+				require.Len(t, actions, 1)
+				assert.Equal(t, core.NewDeleteAction(api.SchemeGroupVersion.WithResource("serverstatusrequests"), api.DefaultNamespace, "ssr-1"), actions[0])
+			default:
+				assert.Empty(t, actions)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"expvar"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+// WorkqueueMetrics is the expvar map that the controllers' work queue metrics are
+// published in, keyed by queue name (e.g. "backup"). Each queue's map contains its
+// current number of keys waiting to be processed ("depth"), the number of keys that
+// have been added to it ("adds") and retried after failing ("retries"), and, for the
+// keys that have been processed, how many there were ("queueLatencyCount") and the
+// total time they spent waiting in the queue ("queueLatencySeconds") and being
+// processed ("workDurationSeconds").
+var WorkqueueMetrics = expvar.NewMap("workqueue")
+
+// workqueueMetricsLock serializes adding a queue's map to WorkqueueMetrics.
+var workqueueMetricsLock sync.Mutex
+
+var registerWorkqueueMetrics sync.Once
+
+// RegisterWorkqueueMetrics makes the work queues created from now on record their
+// metrics in WorkqueueMetrics. It must be called before the controllers are created.
+func RegisterWorkqueueMetrics() {
+	registerWorkqueueMetrics.Do(func() {
+		workqueue.SetProvider(expvarWorkqueueMetricsProvider{})
+	})
+}
+
+// workqueueMetricsFor returns the map that metrics for the named queue are recorded in.
+func workqueueMetricsFor(name string) *expvar.Map {
+	workqueueMetricsLock.Lock()
+	defer workqueueMetricsLock.Unlock()
+
+	if m, ok := WorkqueueMetrics.Get(name).(*expvar.Map); ok {
+		return m
+	}
+
+	m := new(expvar.Map).Init()
+	WorkqueueMetrics.Set(name, m)
+	return m
+}
+
+// expvarWorkqueueMetricsProvider implements workqueue.MetricsProvider, recording
+// each queue's metrics in its map in WorkqueueMetrics.
+type expvarWorkqueueMetricsProvider struct{}
+
+func (expvarWorkqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return &expvarCounter{metrics: workqueueMetricsFor(name), key: "depth"}
+}
+
+func (expvarWorkqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return &expvarCounter{metrics: workqueueMetricsFor(name), key: "adds"}
+}
+
+func (expvarWorkqueueMetricsProvider) NewLatencyMetric(name string) workqueue.SummaryMetric {
+	return &expvarSummary{metrics: workqueueMetricsFor(name), countKey: "queueLatencyCount", secondsKey: "queueLatencySeconds"}
+}
+
+func (expvarWorkqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.SummaryMetric {
+	// every key that's processed was first waiting in the queue, so the count is
+	// the same as queueLatencyCount's and isn't recorded twice
+	return &expvarSummary{metrics: workqueueMetricsFor(name), secondsKey: "workDurationSeconds"}
+}
+
+func (expvarWorkqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return &expvarCounter{metrics: workqueueMetricsFor(name), key: "retries"}
+}
+
+// expvarCounter implements workqueue.GaugeMetric and workqueue.CounterMetric with
+// an integer in an expvar map.
+type expvarCounter struct {
+	metrics *expvar.Map
+	key     string
+}
+
+func (c *expvarCounter) Inc() {
+	c.metrics.Add(c.key, 1)
+}
+
+func (c *expvarCounter) Dec() {
+	c.metrics.Add(c.key, -1)
+}
+
+// expvarSummary implements workqueue.SummaryMetric, whose observations are in
+// microseconds, by adding them up in seconds in an expvar map, and counting them
+// if countKey is set.
+type expvarSummary struct {
+	metrics    *expvar.Map
+	countKey   string
+	secondsKey string
+}
+
+func (s *expvarSummary) Observe(microseconds float64) {
+	if s.countKey != "" {
+		s.metrics.Add(s.countKey, 1)
+	}
+	s.metrics.AddFloat(s.secondsKey, microseconds*float64(time.Microsecond)/float64(time.Second))
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"expvar"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestWorkqueueMetrics(t *testing.T) {
+	RegisterWorkqueueMetrics()
+
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "metrics-test")
+	defer queue.ShutDown()
+
+	metric := func(name string) string {
+		m, ok := WorkqueueMetrics.Get("metrics-test").(*expvar.Map)
+		require.True(t, ok, "no metrics for metrics-test")
+
+		v := m.Get(name)
+		if v == nil {
+			return ""
+		}
+		return v.String()
+	}
+
+	queue.Add("key-1")
+	queue.Add("key-2")
+	assert.Equal(t, "2", metric("depth"))
+	assert.Equal(t, "2", metric("adds"))
+
+	key, _ := queue.Get()
+	queue.Done(key)
+	assert.Equal(t, "1", metric("depth"))
+	assert.Equal(t, "1", metric("queueLatencyCount"))
+
+	seconds, err := strconv.ParseFloat(metric("workDurationSeconds"), 64)
+	require.NoError(t, err)
+	assert.True(t, seconds >= 0)
+
+	queue.AddRateLimited("key-1")
+	assert.Equal(t, "1", metric("retries"))
+}
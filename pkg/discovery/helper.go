@@ -19,6 +19,7 @@ package discovery
 import (
 	"sort"
 	"sync"
+	"time"
 
 	kcmdutil "github.com/heptio/ark/third_party/kubernetes/pkg/kubectl/cmd/util"
 	"github.com/pkg/errors"
@@ -27,6 +28,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 )
@@ -45,17 +48,42 @@ type Helper interface {
 	// Refresh pulls an updated set of Ark-backuppable resources from the
 	// discovery API.
 	Refresh() error
+
+	// RefreshIfStale is like Refresh, but does nothing if the last refresh
+	// was less than minStaleRefreshInterval ago.
+	RefreshIfStale() error
+
+	// ServerVersion gets the version of the Kubernetes API server as of
+	// the last refresh.
+	ServerVersion() *version.Info
+
+	// Serves returns whether the discovery API served the provided
+	// fully-specified GroupVersionResource, in any version of its group
+	// and not just the preferred one, as of the last refresh.
+	Serves(gvr schema.GroupVersionResource) bool
 }
 
+// minStaleRefreshInterval is the minimum time between refreshes by RefreshIfStale, so that
+// looking up many resources that discovery doesn't know about doesn't refresh it for each one.
+const minStaleRefreshInterval = 30 * time.Second
+
 type helper struct {
 	discoveryClient discovery.DiscoveryInterface
 	logger          logrus.FieldLogger
+	clock           clock.Clock
+
+	// lock guards mapper, resources, resourcesMap, servedResources, serverVersion and refreshed
+	lock            sync.RWMutex
+	mapper          meta.RESTMapper
+	resources       []*metav1.APIResourceList
+	resourcesMap    map[schema.GroupVersionResource]metav1.APIResource
+	servedResources map[schema.GroupVersionResource]bool
+	serverVersion   *version.Info
+	refreshed       time.Time
 
-	// lock guards mapper, resources and resourcesMap
-	lock         sync.RWMutex
-	mapper       meta.RESTMapper
-	resources    []*metav1.APIResourceList
-	resourcesMap map[schema.GroupVersionResource]metav1.APIResource
+	// staleRefreshLock makes concurrent calls to RefreshIfStale wait for the first one's refresh
+	// instead of each refreshing
+	staleRefreshLock sync.Mutex
 }
 
 var _ Helper = &helper{}
@@ -63,6 +91,7 @@ var _ Helper = &helper{}
 func NewHelper(discoveryClient discovery.DiscoveryInterface, logger logrus.FieldLogger) (Helper, error) {
 	h := &helper{
 		discoveryClient: discoveryClient,
+		clock:           clock.RealClock{},
 	}
 	if err := h.Refresh(); err != nil {
 		return nil, err
@@ -91,6 +120,9 @@ func (h *helper) Refresh() error {
 	h.lock.Lock()
 	defer h.lock.Unlock()
 
+	// a failed refresh counts too, so that RefreshIfStale doesn't retry it right away
+	h.refreshed = h.clock.Now()
+
 	groupResources, err := discovery.GetAPIGroupResources(h.discoveryClient)
 	if err != nil {
 		return errors.WithStack(err)
@@ -102,6 +134,15 @@ func (h *helper) Refresh() error {
 	}
 	h.mapper = shortcutExpander
 
+	h.servedResources = make(map[schema.GroupVersionResource]bool)
+	for _, group := range groupResources {
+		for version, resources := range group.VersionedResources {
+			for _, resource := range resources {
+				h.servedResources[schema.GroupVersionResource{Group: group.Group.Name, Version: version, Resource: resource.Name}] = true
+			}
+		}
+	}
+
 	preferredResources, err := h.discoveryClient.ServerPreferredResources()
 	if err != nil {
 		return errors.WithStack(err)
@@ -127,9 +168,46 @@ func (h *helper) Refresh() error {
 		}
 	}
 
+	serverVersion, err := h.discoveryClient.ServerVersion()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	h.serverVersion = serverVersion
+
 	return nil
 }
 
+func (h *helper) RefreshIfStale() error {
+	h.staleRefreshLock.Lock()
+	defer h.staleRefreshLock.Unlock()
+
+	h.lock.RLock()
+	refreshed := h.refreshed
+	h.lock.RUnlock()
+
+	if h.clock.Since(refreshed) < minStaleRefreshInterval {
+		return nil
+	}
+
+	return h.Refresh()
+}
+
+// ResourceForWithRefresh is like helper.ResourceFor, but if the resource can't be resolved it
+// refreshes discovery, unless it was refreshed recently, and tries again, in case the resource
+// was added since the last refresh (e.g. by a newly-installed CRD).
+func ResourceForWithRefresh(helper Helper, input schema.GroupVersionResource) (schema.GroupVersionResource, metav1.APIResource, error) {
+	gvr, resource, err := helper.ResourceFor(input)
+	if err == nil {
+		return gvr, resource, nil
+	}
+
+	if err := helper.RefreshIfStale(); err != nil {
+		return schema.GroupVersionResource{}, metav1.APIResource{}, err
+	}
+
+	return helper.ResourceFor(input)
+}
+
 func filterByVerbs(groupVersion string, r *metav1.APIResource) bool {
 	return discovery.SupportsAllVerbs{Verbs: []string{"list", "create", "get", "delete"}}.Match(groupVersion, r)
 }
@@ -165,3 +243,15 @@ func (h *helper) Resources() []*metav1.APIResourceList {
 	defer h.lock.RUnlock()
 	return h.resources
 }
+
+func (h *helper) Serves(gvr schema.GroupVersionResource) bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.servedResources[gvr]
+}
+
+func (h *helper) ServerVersion() *version.Info {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.serverVersion
+}
@@ -17,11 +17,19 @@ limitations under the License.
 package discovery
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	core "k8s.io/client-go/testing"
 )
 
 func TestSortResources(t *testing.T) {
@@ -141,3 +149,110 @@ func TestFilteringByVerbs(t *testing.T) {
 		})
 	}
 }
+
+type refreshingHelper struct {
+	Helper
+
+	refreshes int
+	resources map[schema.GroupVersionResource]bool
+	added     schema.GroupVersionResource
+}
+
+func (h *refreshingHelper) ResourceFor(input schema.GroupVersionResource) (schema.GroupVersionResource, metav1.APIResource, error) {
+	if !h.resources[input] {
+		return schema.GroupVersionResource{}, metav1.APIResource{}, errors.New("not found")
+	}
+	return input, metav1.APIResource{Name: input.Resource}, nil
+}
+
+func (h *refreshingHelper) RefreshIfStale() error {
+	h.refreshes++
+	h.resources[h.added] = true
+	return nil
+}
+
+func TestResourceForWithRefresh(t *testing.T) {
+	var (
+		known = schema.GroupVersionResource{Resource: "pods"}
+		added = schema.GroupVersionResource{Group: "example.com", Resource: "foos"}
+	)
+
+	tests := []struct {
+		name              string
+		input             schema.GroupVersionResource
+		expectErr         bool
+		expectedRefreshes int
+	}{
+		{
+			name:              "known resource doesn't refresh",
+			input:             known,
+			expectedRefreshes: 0,
+		},
+		{
+			name:              "resource added since the last refresh is resolved after refreshing",
+			input:             added,
+			expectedRefreshes: 1,
+		},
+		{
+			name:              "unknown resource returns an error after refreshing",
+			input:             schema.GroupVersionResource{Resource: "unknown"},
+			expectErr:         true,
+			expectedRefreshes: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			helper := &refreshingHelper{
+				resources: map[schema.GroupVersionResource]bool{known: true},
+				added:     added,
+			}
+
+			gvr, _, err := ResourceForWithRefresh(helper, test.input)
+
+			if test.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.input, gvr)
+			}
+			assert.Equal(t, test.expectedRefreshes, helper.refreshes)
+		})
+	}
+}
+
+func TestRefreshIfStale(t *testing.T) {
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &core.Fake{}}
+
+	versionRequests := func() int {
+		var count int
+		for _, action := range discoveryClient.Actions() {
+			if action.GetResource().Resource == "version" {
+				count++
+			}
+		}
+		return count
+	}
+
+	res, err := NewHelper(discoveryClient, logrus.New())
+	require.NoError(t, err)
+	assert.Equal(t, 1, versionRequests())
+
+	h := res.(*helper)
+	fakeClock := clock.NewFakeClock(time.Now())
+	h.clock = fakeClock
+	require.NoError(t, h.Refresh())
+	assert.Equal(t, 2, versionRequests())
+
+	// discovery was just refreshed
+	require.NoError(t, h.RefreshIfStale())
+	assert.Equal(t, 2, versionRequests())
+
+	fakeClock.Step(minStaleRefreshInterval - time.Second)
+	require.NoError(t, h.RefreshIfStale())
+	assert.Equal(t, 2, versionRequests())
+
+	fakeClock.Step(time.Second)
+	require.NoError(t, h.RefreshIfStale())
+	assert.Equal(t, 3, versionRequests())
+}
@@ -33,6 +33,7 @@ type ArkV1Interface interface {
 	DownloadRequestsGetter
 	RestoresGetter
 	SchedulesGetter
+	ServerStatusRequestsGetter
 }
 
 // ArkV1Client is used to interact with features provided by the ark.heptio.com group.
@@ -64,6 +65,10 @@ func (c *ArkV1Client) Schedules(namespace string) ScheduleInterface {
 	return newSchedules(c, namespace)
 }
 
+func (c *ArkV1Client) ServerStatusRequests(namespace string) ServerStatusRequestInterface {
+	return newServerStatusRequests(c, namespace)
+}
+
 // NewForConfig creates a new ArkV1Client for the given config.
 func NewForConfig(c *rest.Config) (*ArkV1Client, error) {
 	config := *c
@@ -36,6 +36,8 @@ type Interface interface {
 	Restores() RestoreInformer
 	// Schedules returns a ScheduleInformer.
 	Schedules() ScheduleInformer
+	// ServerStatusRequests returns a ServerStatusRequestInformer.
+	ServerStatusRequests() ServerStatusRequestInformer
 }
 
 type version struct {
@@ -78,3 +80,8 @@ func (v *version) Restores() RestoreInformer {
 func (v *version) Schedules() ScheduleInformer {
 	return &scheduleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// ServerStatusRequests returns a ServerStatusRequestInformer.
+func (v *version) ServerStatusRequests() ServerStatusRequestInformer {
+	return &serverStatusRequestInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
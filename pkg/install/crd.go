@@ -18,6 +18,7 @@ func CRDs() []*apiextv1beta1.CustomResourceDefinition {
 		crd("Config", "configs"),
 		crd("DownloadRequest", "downloadrequests"),
 		crd("DeleteBackupRequest", "deletebackuprequests"),
+		crd("ServerStatusRequest", "serverstatusrequests"),
 	}
 }
 
@@ -0,0 +1,60 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kuberesource
+
+import (
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// defaultCohabitatingResources are the built-in resources that are served by more than one
+// API group, with their groups in order of preference.
+var defaultCohabitatingResources = []api.CohabitatingResource{
+	{Resource: "deployments", Groups: []string{"apps", "extensions"}},
+	{Resource: "daemonsets", Groups: []string{"apps", "extensions"}},
+	{Resource: "replicasets", Groups: []string{"apps", "extensions"}},
+	{Resource: "networkpolicies", Groups: []string{"networking.k8s.io", "extensions"}},
+	{Resource: "ingresses", Groups: []string{"networking.k8s.io", "extensions"}},
+	{Resource: "events", Groups: []string{"", "events.k8s.io"}},
+}
+
+// CohabitatingResources returns the built-in cohabitating resources along with additional,
+// such as those from the server's config. An additional entry for a built-in resource
+// replaces it.
+func CohabitatingResources(additional []api.CohabitatingResource) []api.CohabitatingResource {
+	overrides := make(map[string]api.CohabitatingResource, len(additional))
+	for _, resource := range additional {
+		overrides[resource.Resource] = resource
+	}
+
+	var res []api.CohabitatingResource
+	for _, resource := range defaultCohabitatingResources {
+		if override, found := overrides[resource.Resource]; found {
+			resource = override
+			delete(overrides, resource.Resource)
+		}
+		res = append(res, resource)
+	}
+
+	for _, resource := range additional {
+		if override, found := overrides[resource.Resource]; found {
+			res = append(res, override)
+			delete(overrides, resource.Resource)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kuberesource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+func TestCohabitatingResources(t *testing.T) {
+	assert.Equal(t, defaultCohabitatingResources, CohabitatingResources(nil))
+
+	res := CohabitatingResources([]api.CohabitatingResource{
+		{Resource: "widgets", Groups: []string{"example.com", "legacy.example.com"}},
+		{Resource: "deployments", Groups: []string{"extensions", "apps"}},
+	})
+
+	expected := append([]api.CohabitatingResource{}, defaultCohabitatingResources...)
+	expected[0] = api.CohabitatingResource{Resource: "deployments", Groups: []string{"extensions", "apps"}}
+	expected = append(expected, api.CohabitatingResource{Resource: "widgets", Groups: []string{"example.com", "legacy.example.com"}})
+
+	assert.Equal(t, expected, res)
+}
@@ -21,11 +21,20 @@ import (
 )
 
 var (
-	ClusterRoleBindings    = schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"}
-	ClusterRoles           = schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}
-	Jobs                   = schema.GroupResource{Group: "batch", Resource: "jobs"}
-	Namespaces             = schema.GroupResource{Group: "", Resource: "namespaces"}
-	PersistentVolumeClaims = schema.GroupResource{Group: "", Resource: "persistentvolumeclaims"}
-	PersistentVolumes      = schema.GroupResource{Group: "", Resource: "persistentvolumes"}
-	Pods                   = schema.GroupResource{Group: "", Resource: "pods"}
+	ArkConfigs                = schema.GroupResource{Group: "ark.heptio.com", Resource: "configs"}
+	ArkSchedules              = schema.GroupResource{Group: "ark.heptio.com", Resource: "schedules"}
+	ClusterRoleBindings       = schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"}
+	ClusterRoles              = schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}
+	ConfigMaps                = schema.GroupResource{Group: "", Resource: "configmaps"}
+	CustomResourceDefinitions = schema.GroupResource{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"}
+	DaemonSets                = schema.GroupResource{Group: "apps", Resource: "daemonsets"}
+	Deployments               = schema.GroupResource{Group: "apps", Resource: "deployments"}
+	Endpoints                 = schema.GroupResource{Group: "", Resource: "endpoints"}
+	Jobs                      = schema.GroupResource{Group: "batch", Resource: "jobs"}
+	Namespaces                = schema.GroupResource{Group: "", Resource: "namespaces"}
+	PersistentVolumeClaims    = schema.GroupResource{Group: "", Resource: "persistentvolumeclaims"}
+	PersistentVolumes         = schema.GroupResource{Group: "", Resource: "persistentvolumes"}
+	Pods                      = schema.GroupResource{Group: "", Resource: "pods"}
+	Services                  = schema.GroupResource{Group: "", Resource: "services"}
+	StatefulSets              = schema.GroupResource{Group: "apps", Resource: "statefulsets"}
 )
@@ -0,0 +1,260 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package leaderelection implements leader election for running multiple
+// replicas of the Ark server, using a lease recorded in an annotation on a
+// ConfigMap. Only the replica holding the lease runs Ark's controllers.
+//
+// It follows the same protocol as client-go's tools/leaderelection with a
+// ConfigMapLock, which isn't vendored: leases are observed using each replica's
+// local clock, changed with optimistic concurrency, and a leader that can't
+// renew its lease within the renew deadline, which is shorter than the lease
+// duration, gives up leadership before another replica can take it over.
+package leaderelection
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const (
+	// LeaderAnnotation is the annotation on the lock ConfigMap that records
+	// the current lease.
+	LeaderAnnotation = "ark.heptio.com/leader"
+
+	// DefaultLeaseDuration is how long a lease is valid for after it's last
+	// renewed. Other replicas wait this long after they last saw the lease
+	// change before trying to acquire it.
+	DefaultLeaseDuration = 15 * time.Second
+
+	// DefaultRenewDeadline is how long the leader keeps trying to renew its
+	// lease before giving up leadership.
+	DefaultRenewDeadline = 10 * time.Second
+
+	// DefaultRetryPeriod is how often replicas try to acquire or renew the lease.
+	DefaultRetryPeriod = 2 * time.Second
+)
+
+// leaseRecord is the lease recorded in the lock ConfigMap's annotation.
+type leaseRecord struct {
+	HolderIdentity       string      `json:"holderIdentity"`
+	LeaseDurationSeconds int         `json:"leaseDurationSeconds"`
+	AcquireTime          metav1.Time `json:"acquireTime"`
+	RenewTime            metav1.Time `json:"renewTime"`
+}
+
+// LeaderElector acquires and renews a lease recorded on a ConfigMap.
+type LeaderElector struct {
+	client        corev1client.ConfigMapsGetter
+	namespace     string
+	name          string
+	identity      string
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+	clock         clock.Clock
+	logger        logrus.FieldLogger
+
+	// observedRecord is the most recently seen lease, and observedTime is when it was
+	// first seen on this replica. Using the local time that the lease was observed,
+	// rather than the times recorded in it, means replicas' clocks don't need to agree.
+	// observedRawRecord is the annotation the lease was decoded from; it's compared
+	// rather than the decoded lease to tell whether the lease has changed, since
+	// decoded times don't compare equal to the times they were encoded from.
+	observedRecord    leaseRecord
+	observedRawRecord string
+	observedTime      time.Time
+}
+
+// NewLeaderElector returns a LeaderElector that competes, as identity, for the
+// lease recorded on the named ConfigMap, using the default timings.
+func NewLeaderElector(client corev1client.ConfigMapsGetter, namespace, name, identity string, logger logrus.FieldLogger) *LeaderElector {
+	return &LeaderElector{
+		client:        client,
+		namespace:     namespace,
+		name:          name,
+		identity:      identity,
+		leaseDuration: DefaultLeaseDuration,
+		renewDeadline: DefaultRenewDeadline,
+		retryPeriod:   DefaultRetryPeriod,
+		clock:         clock.RealClock{},
+		logger: logger.WithFields(logrus.Fields{
+			"lock":     namespace + "/" + name,
+			"identity": identity,
+		}),
+	}
+}
+
+// Acquire blocks until the lease is acquired, returning nil, or ctx is done,
+// returning an error.
+func (le *LeaderElector) Acquire(ctx context.Context) error {
+	le.logger.Info("Attempting to acquire leader lease")
+
+	for !le.tryAcquireOrRenew() {
+		select {
+		case <-ctx.Done():
+			return errors.New("stopped before acquiring leader lease")
+		case <-le.clock.After(le.retryPeriod):
+		}
+	}
+
+	le.logger.Info("Acquired leader lease")
+	return nil
+}
+
+// Renew blocks, renewing the lease every retry period, until ctx is done,
+// returning nil, or the lease can't be renewed within the renew deadline or is
+// found to be held by another replica, returning an error. It must only be called
+// after Acquire succeeds.
+func (le *LeaderElector) Renew(ctx context.Context) error {
+	lastRenewed := le.clock.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-le.clock.After(le.retryPeriod):
+		}
+
+		if le.tryAcquireOrRenew() {
+			lastRenewed = le.clock.Now()
+			continue
+		}
+
+		if holder := le.observedRecord.HolderIdentity; holder != "" && holder != le.identity {
+			le.logger.WithField("holder", holder).Error("Leader lease was taken over by another replica")
+			return errors.New("leader lease was taken over by another replica")
+		}
+
+		if le.clock.Since(lastRenewed) >= le.renewDeadline {
+			le.logger.Error("Failed to renew leader lease before the renew deadline")
+			return errors.New("failed to renew leader lease")
+		}
+	}
+}
+
+// tryAcquireOrRenew acquires the lease if it's expired or unheld, or renews it if it's
+// held by this replica. It returns whether this replica holds the lease.
+func (le *LeaderElector) tryAcquireOrRenew() bool {
+	now := metav1.NewTime(le.clock.Now())
+	record := leaseRecord{
+		HolderIdentity:       le.identity,
+		LeaseDurationSeconds: int(le.leaseDuration / time.Second),
+		AcquireTime:          now,
+		RenewTime:            now,
+	}
+
+	configMap, err := le.client.ConfigMaps(le.namespace).Get(le.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		configMap = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: le.namespace,
+				Name:      le.name,
+			},
+		}
+		raw, err := setLeaseRecord(configMap, record)
+		if err != nil {
+			le.logger.WithError(err).Error("Error encoding leader lease")
+			return false
+		}
+
+		if _, err := le.client.ConfigMaps(le.namespace).Create(configMap); err != nil {
+			if !apierrors.IsAlreadyExists(err) {
+				le.logger.WithError(errors.WithStack(err)).Error("Error creating leader lease")
+			}
+			return false
+		}
+
+		le.observe(record, raw)
+		return true
+	}
+	if err != nil {
+		le.logger.WithError(errors.WithStack(err)).Error("Error getting leader lease")
+		return false
+	}
+
+	var existing leaseRecord
+	data := configMap.Annotations[LeaderAnnotation]
+	if data != "" {
+		if err := json.Unmarshal([]byte(data), &existing); err != nil {
+			// treat a lease that can't be decoded as unheld, so that it's overwritten
+			le.logger.WithError(errors.WithStack(err)).Warn("Error decoding leader lease")
+			existing = leaseRecord{}
+		}
+	}
+
+	if data != le.observedRawRecord || le.observedTime.IsZero() {
+		le.observe(existing, data)
+	}
+
+	if existing.HolderIdentity != "" && existing.HolderIdentity != le.identity &&
+		le.observedTime.Add(time.Duration(existing.LeaseDurationSeconds)*time.Second).After(now.Time) {
+		// another replica holds an unexpired lease
+		return false
+	}
+
+	if existing.HolderIdentity == le.identity {
+		record.AcquireTime = existing.AcquireTime
+	}
+
+	updated := configMap.DeepCopy()
+	raw, err := setLeaseRecord(updated, record)
+	if err != nil {
+		le.logger.WithError(err).Error("Error encoding leader lease")
+		return false
+	}
+
+	// the update fails with a conflict if another replica has updated the lease since it was read
+	if _, err := le.client.ConfigMaps(le.namespace).Update(updated); err != nil {
+		le.logger.WithError(errors.WithStack(err)).Error("Error updating leader lease")
+		return false
+	}
+
+	le.observe(record, raw)
+	return true
+}
+
+func (le *LeaderElector) observe(record leaseRecord, raw string) {
+	le.observedRecord = record
+	le.observedRawRecord = raw
+	le.observedTime = le.clock.Now()
+}
+
+// setLeaseRecord records the lease in the ConfigMap's annotation, and returns the
+// annotation's value.
+func setLeaseRecord(configMap *v1.ConfigMap, record leaseRecord) (string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	if configMap.Annotations == nil {
+		configMap.Annotations = make(map[string]string)
+	}
+	configMap.Annotations[LeaderAnnotation] = string(data)
+
+	return string(data), nil
+}
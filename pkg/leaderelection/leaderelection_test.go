@@ -0,0 +1,229 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+// fakeConfigMaps is an in-memory ConfigMapsGetter that rejects updates with a
+// stale resourceVersion. Only the methods used by LeaderElector are implemented.
+type fakeConfigMaps struct {
+	corev1client.ConfigMapInterface
+	configMaps      map[string]*v1.ConfigMap
+	resourceVersion int
+}
+
+func newFakeConfigMaps() *fakeConfigMaps {
+	return &fakeConfigMaps{configMaps: make(map[string]*v1.ConfigMap)}
+}
+
+func (f *fakeConfigMaps) ConfigMaps(namespace string) corev1client.ConfigMapInterface {
+	return f
+}
+
+func (f *fakeConfigMaps) Get(name string, options metav1.GetOptions) (*v1.ConfigMap, error) {
+	configMap, ok := f.configMaps[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, name)
+	}
+	return configMap.DeepCopy(), nil
+}
+
+func (f *fakeConfigMaps) Create(configMap *v1.ConfigMap) (*v1.ConfigMap, error) {
+	if _, ok := f.configMaps[configMap.Name]; ok {
+		return nil, apierrors.NewAlreadyExists(schema.GroupResource{Resource: "configmaps"}, configMap.Name)
+	}
+	return f.store(configMap), nil
+}
+
+func (f *fakeConfigMaps) Update(configMap *v1.ConfigMap) (*v1.ConfigMap, error) {
+	existing, ok := f.configMaps[configMap.Name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, configMap.Name)
+	}
+	if existing.ResourceVersion != configMap.ResourceVersion {
+		return nil, apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, configMap.Name, nil)
+	}
+	return f.store(configMap), nil
+}
+
+func (f *fakeConfigMaps) store(configMap *v1.ConfigMap) *v1.ConfigMap {
+	f.resourceVersion++
+	stored := configMap.DeepCopy()
+	stored.ResourceVersion = strconv.Itoa(f.resourceVersion)
+	f.configMaps[configMap.Name] = stored
+	return stored.DeepCopy()
+}
+
+func newTestLeaderElector(client corev1client.ConfigMapsGetter, identity string, clock clock.Clock) *LeaderElector {
+	le := NewLeaderElector(client, "heptio-ark", "ark-leader", identity, arktest.NewLogger())
+	le.clock = clock
+	return le
+}
+
+func leaseHolder(t *testing.T, client *fakeConfigMaps) string {
+	configMap, ok := client.configMaps["ark-leader"]
+	require.True(t, ok)
+
+	var record leaseRecord
+	require.NoError(t, json.Unmarshal([]byte(configMap.Annotations[LeaderAnnotation]), &record))
+	return record.HolderIdentity
+}
+
+func TestTryAcquireOrRenew(t *testing.T) {
+	client := newFakeConfigMaps()
+	fakeClock := clock.NewFakeClock(time.Now())
+
+	a := newTestLeaderElector(client, "a", fakeClock)
+	b := newTestLeaderElector(client, "b", fakeClock)
+
+	// a creates the lock
+	assert.True(t, a.tryAcquireOrRenew())
+	assert.Equal(t, "a", leaseHolder(t, client))
+
+	// b can't acquire an unexpired lease
+	assert.False(t, b.tryAcquireOrRenew())
+
+	// a renews its lease, keeping b out even after the original lease duration passes
+	fakeClock.Step(DefaultRetryPeriod)
+	assert.True(t, a.tryAcquireOrRenew())
+	fakeClock.Step(DefaultLeaseDuration - DefaultRetryPeriod)
+	assert.False(t, b.tryAcquireOrRenew())
+	assert.Equal(t, "a", leaseHolder(t, client))
+
+	// once a stops renewing, b acquires the lease after it expires
+	fakeClock.Step(DefaultLeaseDuration)
+	assert.True(t, b.tryAcquireOrRenew())
+	assert.Equal(t, "b", leaseHolder(t, client))
+
+	// and a can no longer renew it
+	assert.False(t, a.tryAcquireOrRenew())
+	assert.Equal(t, "b", leaseHolder(t, client))
+}
+
+func TestTryAcquireOrRenewKeepsAcquireTime(t *testing.T) {
+	client := newFakeConfigMaps()
+	fakeClock := clock.NewFakeClock(time.Now())
+	le := newTestLeaderElector(client, "a", fakeClock)
+
+	require.True(t, le.tryAcquireOrRenew())
+	acquired := le.observedRecord.AcquireTime
+
+	fakeClock.Step(DefaultRetryPeriod)
+	require.True(t, le.tryAcquireOrRenew())
+
+	assert.Equal(t, acquired.Unix(), le.observedRecord.AcquireTime.Unix())
+	assert.Equal(t, fakeClock.Now().Unix(), le.observedRecord.RenewTime.Unix())
+}
+
+func TestTryAcquireOrRenewOverwritesInvalidLease(t *testing.T) {
+	client := newFakeConfigMaps()
+	client.store(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "heptio-ark",
+			Name:        "ark-leader",
+			Annotations: map[string]string{LeaderAnnotation: "not json"},
+		},
+	})
+
+	le := newTestLeaderElector(client, "a", clock.NewFakeClock(time.Now()))
+
+	assert.True(t, le.tryAcquireOrRenew())
+	assert.Equal(t, "a", leaseHolder(t, client))
+}
+
+func TestAcquireStopsWhenContextDone(t *testing.T) {
+	client := newFakeConfigMaps()
+	fakeClock := clock.NewFakeClock(time.Now())
+
+	holder := newTestLeaderElector(client, "a", fakeClock)
+	require.True(t, holder.tryAcquireOrRenew())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	le := newTestLeaderElector(client, "b", fakeClock)
+	assert.Error(t, le.Acquire(ctx))
+}
+
+func TestTryAcquireOrRenewDoesNotResetObservedTimeForUnchangedLease(t *testing.T) {
+	client := newFakeConfigMaps()
+	fakeClock := clock.NewFakeClock(time.Now())
+
+	a := newTestLeaderElector(client, "a", fakeClock)
+	b := newTestLeaderElector(client, "b", fakeClock)
+
+	require.True(t, a.tryAcquireOrRenew())
+
+	// b keeps polling a lease that isn't renewed, and acquires it once it expires
+	for i := 0; i <= int(DefaultLeaseDuration/DefaultRetryPeriod); i++ {
+		assert.False(t, b.tryAcquireOrRenew())
+		fakeClock.Step(DefaultRetryPeriod)
+	}
+	assert.True(t, b.tryAcquireOrRenew())
+	assert.Equal(t, "b", leaseHolder(t, client))
+}
+
+func TestRenewStopsWhenLeaseTakenOver(t *testing.T) {
+	client := newFakeConfigMaps()
+	fakeClock := clock.NewFakeClock(time.Now())
+
+	a := newTestLeaderElector(client, "a", fakeClock)
+	require.True(t, a.tryAcquireOrRenew())
+
+	// another replica takes over the lease, e.g. because a was paused for longer than
+	// the lease duration
+	b := newTestLeaderElector(client, "b", fakeClock)
+	require.False(t, b.tryAcquireOrRenew())
+	fakeClock.Step(DefaultLeaseDuration)
+	require.True(t, b.tryAcquireOrRenew())
+
+	errs := make(chan error)
+	go func() {
+		errs <- a.Renew(context.Background())
+	}()
+
+	// a gives up leadership at its next renewal rather than at its renew deadline
+	for !fakeClock.HasWaiters() {
+		time.Sleep(time.Millisecond)
+	}
+	fakeClock.Step(DefaultRetryPeriod)
+
+	select {
+	case err := <-errs:
+		assert.EqualError(t, err, "leader lease was taken over by another replica")
+	case <-time.After(10 * time.Second):
+		t.Fatal("Renew didn't return")
+	}
+}
@@ -72,7 +72,7 @@ type BackupItemActionGRPCClient struct {
 }
 
 func (c *BackupItemActionGRPCClient) AppliesTo() (arkbackup.ResourceSelector, error) {
-	res, err := c.grpcClient.AppliesTo(context.Background(), &proto.Empty{})
+	res, err := c.grpcClient.AppliesTo(withCallMetadata(context.Background(), c.log.correlationID, ""), &proto.Empty{})
 	if err != nil {
 		return arkbackup.ResourceSelector{}, err
 	}
@@ -83,6 +83,7 @@ func (c *BackupItemActionGRPCClient) AppliesTo() (arkbackup.ResourceSelector, er
 		IncludedResources:  res.IncludedResources,
 		ExcludedResources:  res.ExcludedResources,
 		LabelSelector:      res.Selector,
+		FieldSelector:      res.FieldSelector,
 	}, nil
 }
 
@@ -102,7 +103,7 @@ func (c *BackupItemActionGRPCClient) Execute(item runtime.Unstructured, backup *
 		Backup: backupJSON,
 	}
 
-	res, err := c.grpcClient.Execute(context.Background(), req)
+	res, err := c.grpcClient.Execute(withCallMetadata(context.Background(), c.log.correlationID, backup.Status.OperationID), req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -130,8 +131,10 @@ func (c *BackupItemActionGRPCClient) Execute(item runtime.Unstructured, backup *
 	return &updatedItem, additionalItems, nil
 }
 
+// SetLog sets the log that the plugin's log entries for the backup
+// this client was created for are written to.
 func (c *BackupItemActionGRPCClient) SetLog(log logrus.FieldLogger) {
-	c.log.impl = log
+	operationLogs.set(c.log.correlationID, log)
 }
 
 // BackupItemActionGRPCServer implements the proto-generated BackupItemActionServer interface, and accepts
@@ -141,6 +144,8 @@ type BackupItemActionGRPCServer struct {
 }
 
 func (s *BackupItemActionGRPCServer) AppliesTo(ctx context.Context, req *proto.Empty) (*proto.AppliesToResponse, error) {
+	defer pluginCorrelation.serve(ctx)()
+
 	resourceSelector, err := s.impl.AppliesTo()
 	if err != nil {
 		return nil, err
@@ -152,10 +157,13 @@ func (s *BackupItemActionGRPCServer) AppliesTo(ctx context.Context, req *proto.E
 		IncludedResources:  resourceSelector.IncludedResources,
 		ExcludedResources:  resourceSelector.ExcludedResources,
 		Selector:           resourceSelector.LabelSelector,
+		FieldSelector:      resourceSelector.FieldSelector,
 	}, nil
 }
 
 func (s *BackupItemActionGRPCServer) Execute(ctx context.Context, req *proto.ExecuteRequest) (*proto.ExecuteResponse, error) {
+	defer pluginCorrelation.serve(ctx)()
+
 	var item unstructured.Unstructured
 	var backup api.Backup
 
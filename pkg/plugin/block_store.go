@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	proto "github.com/heptio/ark/pkg/plugin/generated"
 )
@@ -141,6 +142,38 @@ func (c *BlockStoreGRPCClient) CreateSnapshot(volumeID, volumeAZ string, tags ma
 	return res.SnapshotID, nil
 }
 
+// GetSnapshotPhase returns the current phase of the specified volume snapshot.
+func (c *BlockStoreGRPCClient) GetSnapshotPhase(snapshotID string) (api.SnapshotPhase, error) {
+	res, err := c.grpcClient.GetSnapshotPhase(context.Background(), &proto.GetSnapshotPhaseRequest{SnapshotID: snapshotID})
+	if err != nil {
+		return "", err
+	}
+
+	return api.SnapshotPhase(res.Phase), nil
+}
+
+// CopySnapshot starts copying the specified volume snapshot to another region, and returns
+// the ID of the copy.
+func (c *BlockStoreGRPCClient) CopySnapshot(snapshotID, region string) (string, error) {
+	res, err := c.grpcClient.CopySnapshot(context.Background(), &proto.CopySnapshotRequest{SnapshotID: snapshotID, Region: region})
+	if err != nil {
+		return "", err
+	}
+
+	return res.SnapshotID, nil
+}
+
+// ListSnapshots returns the IDs of all volume snapshots that have all of the
+// specified tags.
+func (c *BlockStoreGRPCClient) ListSnapshots(tagFilters map[string]string) ([]string, error) {
+	res, err := c.grpcClient.ListSnapshots(context.Background(), &proto.ListSnapshotsRequest{TagFilters: tagFilters})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.SnapshotIDs, nil
+}
+
 // DeleteSnapshot deletes the specified volume snapshot.
 func (c *BlockStoreGRPCClient) DeleteSnapshot(snapshotID string) error {
 	_, err := c.grpcClient.DeleteSnapshot(context.Background(), &proto.DeleteSnapshotRequest{SnapshotID: snapshotID})
@@ -268,6 +301,38 @@ func (s *BlockStoreGRPCServer) CreateSnapshot(ctx context.Context, req *proto.Cr
 	return &proto.CreateSnapshotResponse{SnapshotID: snapshotID}, nil
 }
 
+// GetSnapshotPhase returns the current phase of the specified volume snapshot.
+func (s *BlockStoreGRPCServer) GetSnapshotPhase(ctx context.Context, req *proto.GetSnapshotPhaseRequest) (*proto.GetSnapshotPhaseResponse, error) {
+	phase, err := s.impl.GetSnapshotPhase(req.SnapshotID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.GetSnapshotPhaseResponse{Phase: string(phase)}, nil
+}
+
+// CopySnapshot starts copying the specified volume snapshot to another region, and returns
+// the ID of the copy.
+func (s *BlockStoreGRPCServer) CopySnapshot(ctx context.Context, req *proto.CopySnapshotRequest) (*proto.CopySnapshotResponse, error) {
+	snapshotID, err := s.impl.CopySnapshot(req.SnapshotID, req.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.CopySnapshotResponse{SnapshotID: snapshotID}, nil
+}
+
+// ListSnapshots returns the IDs of all volume snapshots that have all of the
+// specified tags.
+func (s *BlockStoreGRPCServer) ListSnapshots(ctx context.Context, req *proto.ListSnapshotsRequest) (*proto.ListSnapshotsResponse, error) {
+	snapshotIDs, err := s.impl.ListSnapshots(req.TagFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.ListSnapshotsResponse{SnapshotIDs: snapshotIDs}, nil
+}
+
 // DeleteSnapshot deletes the specified volume snapshot.
 func (s *BlockStoreGRPCServer) DeleteSnapshot(ctx context.Context, req *proto.DeleteSnapshotRequest) (*proto.Empty, error) {
 	if err := s.impl.DeleteSnapshot(req.SnapshotID); err != nil {
@@ -0,0 +1,179 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/heptio/ark/pkg/util/logging"
+)
+
+const (
+	// correlationIDMetadataKey is the gRPC metadata key that a plugin call's correlation ID
+	// is sent to the plugin server in.
+	correlationIDMetadataKey = "ark-correlation-id"
+
+	// correlationIDField is the log field that a plugin adds to the entries it logs while
+	// serving a call with a correlation ID.
+	correlationIDField = "correlationID"
+
+	// operationIDMetadataKey is the gRPC metadata key that the OperationID of the backup
+	// or restore a plugin call was made for is sent to the plugin server in. The plugin
+	// adds it to the entries it logs while serving the call as logging.OperationIDField.
+	operationIDMetadataKey = "ark-operation-id"
+)
+
+// callMetadataFields maps the gRPC metadata keys sent with plugin calls to the log fields
+// that a plugin adds them to its entries as.
+var callMetadataFields = map[string]string{
+	correlationIDMetadataKey: correlationIDField,
+	operationIDMetadataKey:   logging.OperationIDField,
+}
+
+// backupCorrelationID returns the correlation ID for plugin calls made on behalf of the
+// named backup.
+func backupCorrelationID(backupName string) string {
+	return "backup/" + backupName
+}
+
+// restoreCorrelationID returns the correlation ID for plugin calls made on behalf of the
+// named restore.
+func restoreCorrelationID(restoreName string) string {
+	return "restore/" + restoreName
+}
+
+// withCallMetadata returns a copy of ctx that sends correlationID and operationID to the
+// plugin server with the call it's used for. Empty IDs aren't sent.
+func withCallMetadata(ctx context.Context, correlationID, operationID string) context.Context {
+	md := metadata.MD{}
+	if correlationID != "" {
+		md[correlationIDMetadataKey] = []string{correlationID}
+	}
+	if operationID != "" {
+		md[operationIDMetadataKey] = []string{operationID}
+	}
+
+	if len(md) == 0 {
+		return ctx
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// callFieldsFrom returns the log fields for the IDs sent with the incoming call that
+// ctx belongs to.
+func callFieldsFrom(ctx context.Context) logrus.Fields {
+	fields := logrus.Fields{}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return fields
+	}
+
+	for key, field := range callMetadataFields {
+		if len(md[key]) > 0 && md[key][0] != "" {
+			fields[field] = md[key][0]
+		}
+	}
+
+	return fields
+}
+
+// correlationHook is a logrus hook, used within plugins, that adds the correlation and
+// operation IDs of the call being served to each entry so the Ark server can route the
+// entry to the log of the backup or restore that the call was made for.
+type correlationHook struct {
+	lock   sync.RWMutex
+	fields logrus.Fields
+}
+
+// pluginCorrelation is the hook added to the loggers returned by NewLogger.
+var pluginCorrelation = &correlationHook{}
+
+func (h *correlationHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *correlationHook) Fire(entry *logrus.Entry) error {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	// logrus reuses entries logged directly on a Logger, so make sure a
+	// previous call's IDs aren't left behind.
+	for _, field := range callMetadataFields {
+		delete(entry.Data, field)
+	}
+
+	for field, value := range h.fields {
+		entry.Data[field] = value
+	}
+
+	return nil
+}
+
+// serve sets the IDs to the ones sent with ctx's call, if any, and returns a func that
+// restores the previous ones once the call has been served.
+func (h *correlationHook) serve(ctx context.Context) func() {
+	fields := callFieldsFrom(ctx)
+	if len(fields) == 0 {
+		return func() {}
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	prev := h.fields
+	h.fields = fields
+
+	return func() {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+		h.fields = prev
+	}
+}
+
+// correlatedLogs holds the logs that plugin log entries are routed to, keyed by the
+// correlation ID of the backup or restore they belong to.
+type correlatedLogs struct {
+	lock sync.RWMutex
+	logs map[string]logrus.FieldLogger
+}
+
+// operationLogs is where the Ark server registers each backup or restore's log for
+// plugin log entries to be routed to.
+var operationLogs = &correlatedLogs{logs: make(map[string]logrus.FieldLogger)}
+
+func (c *correlatedLogs) set(id string, log logrus.FieldLogger) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.logs[id] = log
+}
+
+func (c *correlatedLogs) get(id string) logrus.FieldLogger {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.logs[id]
+}
+
+func (c *correlatedLogs) delete(id string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.logs, id)
+}
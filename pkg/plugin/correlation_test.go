@@ -0,0 +1,102 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+)
+
+// bufferLogger returns a logger that writes JSON entries to the returned buffer.
+func bufferLogger() (*logrus.Logger, *bytes.Buffer) {
+	buf := new(bytes.Buffer)
+
+	logger := logrus.New()
+	logger.Out = buf
+	logger.Formatter = &logrus.JSONFormatter{DisableTimestamp: true}
+
+	return logger, buf
+}
+
+func TestCorrelationHook(t *testing.T) {
+	logger, buf := bufferLogger()
+	hook := &correlationHook{}
+	logger.Hooks.Add(hook)
+
+	logger.Info("before")
+	assert.NotContains(t, buf.String(), correlationIDField)
+
+	// the IDs are sent by the client as outgoing metadata, and received by the
+	// server as incoming metadata
+	outgoing, _ := metadata.FromOutgoingContext(withCallMetadata(context.Background(), "backup/backup-1", "operation-1"))
+	done := hook.serve(metadata.NewIncomingContext(context.Background(), outgoing))
+	buf.Reset()
+	logger.Info("during")
+	assert.Contains(t, buf.String(), `"correlationID":"backup/backup-1"`)
+	assert.Contains(t, buf.String(), `"operationID":"operation-1"`)
+
+	done()
+	buf.Reset()
+	logger.Info("after")
+	assert.NotContains(t, buf.String(), correlationIDField)
+	assert.NotContains(t, buf.String(), "operationID")
+
+	// a call without a correlation ID doesn't change anything
+	hook.serve(context.Background())()
+	buf.Reset()
+	logger.Info("no correlation ID")
+	assert.NotContains(t, buf.String(), correlationIDField)
+}
+
+func TestLogrusAdapterRoutesCorrelatedEntries(t *testing.T) {
+	serverLog, serverBuf := bufferLogger()
+	backupLog, backupBuf := bufferLogger()
+
+	operationLogs.set("backup/backup-1", backupLog)
+	defer operationLogs.delete("backup/backup-1")
+
+	shared := (&logrusAdapter{impl: serverLog, level: logrus.InfoLevel}).Named("block-store")
+
+	// an entry tagged by the plugin goes to the operation's log, without the tag
+	shared.Info("tagged", correlationIDField, "backup/backup-1", "key-1", "value-1")
+	assert.Empty(t, serverBuf.String())
+	assert.Contains(t, backupBuf.String(), `"msg":"tagged"`)
+	assert.Contains(t, backupBuf.String(), `"pluginName":"block-store"`)
+	assert.Contains(t, backupBuf.String(), `"key-1":"value-1"`)
+	assert.NotContains(t, backupBuf.String(), correlationIDField)
+
+	// untagged entries, and ones for an operation without a log, go to the server log
+	backupBuf.Reset()
+	shared.Info("untagged")
+	shared.Info("unregistered", correlationIDField, "restore/restore-1")
+	assert.Empty(t, backupBuf.String())
+	assert.Contains(t, serverBuf.String(), `"msg":"untagged"`)
+	assert.Contains(t, serverBuf.String(), `"msg":"unregistered"`)
+
+	// a per-backup adapter routes all of its entries to the backup's log
+	serverBuf.Reset()
+	perBackup := (&logrusAdapter{impl: serverLog, level: logrus.InfoLevel, correlationID: "backup/backup-1"}).With("key-2", "value-2")
+	perBackup.Warn("unstructured stderr")
+	assert.Empty(t, serverBuf.String())
+	assert.Contains(t, backupBuf.String(), `"msg":"unstructured stderr"`)
+	assert.Contains(t, backupBuf.String(), `"key-2":"value-2"`)
+}
@@ -28,6 +28,12 @@ It has these top-level messages:
 	GetVolumeIDResponse
 	SetVolumeIDRequest
 	SetVolumeIDResponse
+	GetSnapshotPhaseRequest
+	GetSnapshotPhaseResponse
+	CopySnapshotRequest
+	CopySnapshotResponse
+	ListSnapshotsRequest
+	ListSnapshotsResponse
 	PutObjectRequest
 	GetObjectRequest
 	Bytes
@@ -38,6 +44,8 @@ It has these top-level messages:
 	DeleteObjectRequest
 	CreateSignedURLRequest
 	CreateSignedURLResponse
+	GetObjectIfChangedRequest
+	GetObjectIfChangedResponse
 	RestoreExecuteRequest
 	RestoreExecuteResponse
 	Empty
@@ -297,6 +297,110 @@ func (m *SetVolumeIDResponse) GetPersistentVolume() []byte {
 	return nil
 }
 
+type GetSnapshotPhaseRequest struct {
+	SnapshotID string `protobuf:"bytes,1,opt,name=snapshotID" json:"snapshotID,omitempty"`
+}
+
+func (m *GetSnapshotPhaseRequest) Reset()                    { *m = GetSnapshotPhaseRequest{} }
+func (m *GetSnapshotPhaseRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetSnapshotPhaseRequest) ProtoMessage()               {}
+func (*GetSnapshotPhaseRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{13} }
+
+func (m *GetSnapshotPhaseRequest) GetSnapshotID() string {
+	if m != nil {
+		return m.SnapshotID
+	}
+	return ""
+}
+
+type GetSnapshotPhaseResponse struct {
+	Phase string `protobuf:"bytes,1,opt,name=phase" json:"phase,omitempty"`
+}
+
+func (m *GetSnapshotPhaseResponse) Reset()                    { *m = GetSnapshotPhaseResponse{} }
+func (m *GetSnapshotPhaseResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetSnapshotPhaseResponse) ProtoMessage()               {}
+func (*GetSnapshotPhaseResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{14} }
+
+func (m *GetSnapshotPhaseResponse) GetPhase() string {
+	if m != nil {
+		return m.Phase
+	}
+	return ""
+}
+
+type CopySnapshotRequest struct {
+	SnapshotID string `protobuf:"bytes,1,opt,name=snapshotID" json:"snapshotID,omitempty"`
+	Region     string `protobuf:"bytes,2,opt,name=region" json:"region,omitempty"`
+}
+
+func (m *CopySnapshotRequest) Reset()                    { *m = CopySnapshotRequest{} }
+func (m *CopySnapshotRequest) String() string            { return proto.CompactTextString(m) }
+func (*CopySnapshotRequest) ProtoMessage()               {}
+func (*CopySnapshotRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{15} }
+
+func (m *CopySnapshotRequest) GetSnapshotID() string {
+	if m != nil {
+		return m.SnapshotID
+	}
+	return ""
+}
+
+func (m *CopySnapshotRequest) GetRegion() string {
+	if m != nil {
+		return m.Region
+	}
+	return ""
+}
+
+type CopySnapshotResponse struct {
+	SnapshotID string `protobuf:"bytes,1,opt,name=snapshotID" json:"snapshotID,omitempty"`
+}
+
+func (m *CopySnapshotResponse) Reset()                    { *m = CopySnapshotResponse{} }
+func (m *CopySnapshotResponse) String() string            { return proto.CompactTextString(m) }
+func (*CopySnapshotResponse) ProtoMessage()               {}
+func (*CopySnapshotResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{16} }
+
+func (m *CopySnapshotResponse) GetSnapshotID() string {
+	if m != nil {
+		return m.SnapshotID
+	}
+	return ""
+}
+
+type ListSnapshotsRequest struct {
+	TagFilters map[string]string `protobuf:"bytes,1,rep,name=tagFilters" json:"tagFilters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *ListSnapshotsRequest) Reset()                    { *m = ListSnapshotsRequest{} }
+func (m *ListSnapshotsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListSnapshotsRequest) ProtoMessage()               {}
+func (*ListSnapshotsRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{17} }
+
+func (m *ListSnapshotsRequest) GetTagFilters() map[string]string {
+	if m != nil {
+		return m.TagFilters
+	}
+	return nil
+}
+
+type ListSnapshotsResponse struct {
+	SnapshotIDs []string `protobuf:"bytes,1,rep,name=snapshotIDs" json:"snapshotIDs,omitempty"`
+}
+
+func (m *ListSnapshotsResponse) Reset()                    { *m = ListSnapshotsResponse{} }
+func (m *ListSnapshotsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListSnapshotsResponse) ProtoMessage()               {}
+func (*ListSnapshotsResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{18} }
+
+func (m *ListSnapshotsResponse) GetSnapshotIDs() []string {
+	if m != nil {
+		return m.SnapshotIDs
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*CreateVolumeRequest)(nil), "generated.CreateVolumeRequest")
 	proto.RegisterType((*CreateVolumeResponse)(nil), "generated.CreateVolumeResponse")
@@ -311,6 +415,12 @@ func init() {
 	proto.RegisterType((*GetVolumeIDResponse)(nil), "generated.GetVolumeIDResponse")
 	proto.RegisterType((*SetVolumeIDRequest)(nil), "generated.SetVolumeIDRequest")
 	proto.RegisterType((*SetVolumeIDResponse)(nil), "generated.SetVolumeIDResponse")
+	proto.RegisterType((*GetSnapshotPhaseRequest)(nil), "generated.GetSnapshotPhaseRequest")
+	proto.RegisterType((*GetSnapshotPhaseResponse)(nil), "generated.GetSnapshotPhaseResponse")
+	proto.RegisterType((*CopySnapshotRequest)(nil), "generated.CopySnapshotRequest")
+	proto.RegisterType((*CopySnapshotResponse)(nil), "generated.CopySnapshotResponse")
+	proto.RegisterType((*ListSnapshotsRequest)(nil), "generated.ListSnapshotsRequest")
+	proto.RegisterType((*ListSnapshotsResponse)(nil), "generated.ListSnapshotsResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -332,6 +442,9 @@ type BlockStoreClient interface {
 	DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*Empty, error)
 	GetVolumeID(ctx context.Context, in *GetVolumeIDRequest, opts ...grpc.CallOption) (*GetVolumeIDResponse, error)
 	SetVolumeID(ctx context.Context, in *SetVolumeIDRequest, opts ...grpc.CallOption) (*SetVolumeIDResponse, error)
+	GetSnapshotPhase(ctx context.Context, in *GetSnapshotPhaseRequest, opts ...grpc.CallOption) (*GetSnapshotPhaseResponse, error)
+	CopySnapshot(ctx context.Context, in *CopySnapshotRequest, opts ...grpc.CallOption) (*CopySnapshotResponse, error)
+	ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error)
 }
 
 type blockStoreClient struct {
@@ -414,6 +527,33 @@ func (c *blockStoreClient) SetVolumeID(ctx context.Context, in *SetVolumeIDReque
 	return out, nil
 }
 
+func (c *blockStoreClient) GetSnapshotPhase(ctx context.Context, in *GetSnapshotPhaseRequest, opts ...grpc.CallOption) (*GetSnapshotPhaseResponse, error) {
+	out := new(GetSnapshotPhaseResponse)
+	err := grpc.Invoke(ctx, "/generated.BlockStore/GetSnapshotPhase", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockStoreClient) CopySnapshot(ctx context.Context, in *CopySnapshotRequest, opts ...grpc.CallOption) (*CopySnapshotResponse, error) {
+	out := new(CopySnapshotResponse)
+	err := grpc.Invoke(ctx, "/generated.BlockStore/CopySnapshot", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockStoreClient) ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error) {
+	out := new(ListSnapshotsResponse)
+	err := grpc.Invoke(ctx, "/generated.BlockStore/ListSnapshots", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for BlockStore service
 
 type BlockStoreServer interface {
@@ -425,6 +565,9 @@ type BlockStoreServer interface {
 	DeleteSnapshot(context.Context, *DeleteSnapshotRequest) (*Empty, error)
 	GetVolumeID(context.Context, *GetVolumeIDRequest) (*GetVolumeIDResponse, error)
 	SetVolumeID(context.Context, *SetVolumeIDRequest) (*SetVolumeIDResponse, error)
+	GetSnapshotPhase(context.Context, *GetSnapshotPhaseRequest) (*GetSnapshotPhaseResponse, error)
+	CopySnapshot(context.Context, *CopySnapshotRequest) (*CopySnapshotResponse, error)
+	ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error)
 }
 
 func RegisterBlockStoreServer(s *grpc.Server, srv BlockStoreServer) {
@@ -575,6 +718,60 @@ func _BlockStore_SetVolumeID_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlockStore_GetSnapshotPhase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSnapshotPhaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockStoreServer).GetSnapshotPhase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.BlockStore/GetSnapshotPhase",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockStoreServer).GetSnapshotPhase(ctx, req.(*GetSnapshotPhaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockStore_CopySnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CopySnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockStoreServer).CopySnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.BlockStore/CopySnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockStoreServer).CopySnapshot(ctx, req.(*CopySnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlockStore_ListSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockStoreServer).ListSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.BlockStore/ListSnapshots",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockStoreServer).ListSnapshots(ctx, req.(*ListSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _BlockStore_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "generated.BlockStore",
 	HandlerType: (*BlockStoreServer)(nil),
@@ -611,6 +808,18 @@ var _BlockStore_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SetVolumeID",
 			Handler:    _BlockStore_SetVolumeID_Handler,
 		},
+		{
+			MethodName: "GetSnapshotPhase",
+			Handler:    _BlockStore_GetSnapshotPhase_Handler,
+		},
+		{
+			MethodName: "CopySnapshot",
+			Handler:    _BlockStore_CopySnapshot_Handler,
+		},
+		{
+			MethodName: "ListSnapshots",
+			Handler:    _BlockStore_ListSnapshots_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "BlockStore.proto",
@@ -619,39 +828,49 @@ var _BlockStore_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("BlockStore.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 540 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0x4d, 0x6f, 0xd3, 0x40,
-	0x10, 0x95, 0x3f, 0x40, 0xcd, 0xa4, 0x54, 0xd1, 0x26, 0xa9, 0x2c, 0x4b, 0x04, 0xe3, 0x53, 0x54,
-	0x89, 0x08, 0xc2, 0xa1, 0x15, 0x07, 0x44, 0x21, 0x05, 0x45, 0x54, 0x3d, 0xd8, 0x85, 0x03, 0x70,
-	0x31, 0x64, 0x48, 0xa3, 0x26, 0x5e, 0xe3, 0xdd, 0x54, 0xf2, 0x0f, 0xe0, 0xbf, 0xf1, 0xa3, 0x38,
-	0x20, 0xdb, 0xeb, 0x8f, 0x4d, 0x36, 0x09, 0x55, 0x6e, 0x9e, 0x99, 0x7d, 0x6f, 0xdf, 0xec, 0xbe,
-	0x59, 0x43, 0xeb, 0xed, 0x9c, 0xfe, 0xb8, 0xf5, 0x39, 0x8d, 0x71, 0x10, 0xc5, 0x94, 0x53, 0xd2,
-	0x98, 0x62, 0x88, 0x71, 0xc0, 0x71, 0x62, 0x1f, 0xfa, 0x37, 0x41, 0x8c, 0x93, 0xbc, 0xe0, 0xfe,
-	0xd6, 0xa0, 0xfd, 0x2e, 0xc6, 0x80, 0xe3, 0x67, 0x3a, 0x5f, 0x2e, 0xd0, 0xc3, 0x5f, 0x4b, 0x64,
-	0x9c, 0xf4, 0x00, 0x58, 0x18, 0x44, 0xec, 0x86, 0xf2, 0xf1, 0xc8, 0xd2, 0x1c, 0xad, 0xdf, 0xf0,
-	0x6a, 0x99, 0xb4, 0x7e, 0x97, 0x01, 0xae, 0x93, 0x08, 0x2d, 0x3d, 0xaf, 0x57, 0x19, 0x62, 0xc3,
-	0x41, 0x1e, 0x9d, 0x7f, 0xb1, 0x8c, 0xac, 0x5a, 0xc6, 0x84, 0x80, 0x39, 0xa3, 0x11, 0xb3, 0x4c,
-	0x47, 0xeb, 0x1b, 0x5e, 0xf6, 0xed, 0x0e, 0xa1, 0x23, 0xcb, 0x60, 0x11, 0x0d, 0x59, 0x8d, 0xa7,
-	0x54, 0x51, 0xc6, 0xee, 0x15, 0x74, 0x3e, 0x20, 0xcf, 0x01, 0xe3, 0xf0, 0x27, 0x2d, 0xb4, 0x6f,
-	0xc1, 0x48, 0xba, 0x74, 0x59, 0x97, 0xfb, 0x11, 0xba, 0x2b, 0x7c, 0x42, 0x84, 0xdc, 0xac, 0xb6,
-	0xd6, 0x6c, 0xd1, 0x90, 0x5e, 0x6b, 0xe8, 0x0a, 0x3a, 0x63, 0x56, 0x34, 0x13, 0x4c, 0x92, 0x7d,
-	0xc5, 0x3d, 0x83, 0xee, 0x0a, 0x9f, 0x10, 0xd7, 0x81, 0x07, 0x71, 0x9a, 0xc8, 0xd8, 0x0e, 0xbc,
-	0x3c, 0x70, 0xff, 0x68, 0xd0, 0xcd, 0x0f, 0xd4, 0x17, 0x97, 0xb6, 0xa7, 0x00, 0xf2, 0x1a, 0x4c,
-	0x1e, 0x4c, 0x99, 0x65, 0x38, 0x46, 0xbf, 0x39, 0x3c, 0x19, 0x94, 0x8e, 0x1a, 0x28, 0xf7, 0x19,
-	0x5c, 0x07, 0x53, 0x76, 0x11, 0xf2, 0x38, 0xf1, 0x32, 0x9c, 0x7d, 0x0a, 0x8d, 0x32, 0x45, 0x5a,
-	0x60, 0xdc, 0x62, 0x22, 0xf6, 0x4f, 0x3f, 0xd3, 0x36, 0xee, 0x82, 0xf9, 0xb2, 0xf0, 0x52, 0x1e,
-	0xbc, 0xd2, 0xcf, 0x34, 0xf7, 0x0c, 0x8e, 0x57, 0x77, 0xa8, 0xee, 0x65, 0x9b, 0x49, 0xdd, 0x53,
-	0xe8, 0x8e, 0x70, 0x8e, 0xeb, 0x67, 0xb0, 0x0b, 0xf8, 0x06, 0x48, 0xe5, 0x84, 0x51, 0x81, 0x3a,
-	0x81, 0x56, 0x84, 0x31, 0x9b, 0x31, 0x8e, 0xa1, 0x28, 0x66, 0xd8, 0x43, 0x6f, 0x2d, 0xef, 0xbe,
-	0x80, 0xb6, 0xc4, 0xf0, 0x1f, 0x76, 0xfe, 0x06, 0xc4, 0xdf, 0x6b, 0x53, 0x89, 0x5d, 0x5f, 0x61,
-	0x3f, 0x87, 0xb6, 0xaf, 0x10, 0x74, 0x0f, 0xfa, 0xe1, 0x5f, 0x13, 0xa0, 0x7a, 0x59, 0xc8, 0x73,
-	0x30, 0xc7, 0xe1, 0x8c, 0x93, 0xe3, 0x9a, 0x15, 0xd2, 0x84, 0x50, 0x6e, 0xb7, 0x6a, 0xf9, 0x8b,
-	0x45, 0xc4, 0x13, 0xf2, 0x15, 0xac, 0xfa, 0x90, 0xbf, 0x8f, 0xe9, 0xa2, 0xb8, 0x19, 0xd2, 0x5b,
-	0x33, 0x94, 0xf4, 0x20, 0xd9, 0x4f, 0x36, 0xd6, 0x45, 0x27, 0x1e, 0x3c, 0x92, 0xa6, 0x97, 0xd4,
-	0x11, 0xaa, 0x77, 0xc2, 0x76, 0x36, 0x2f, 0xa8, 0x38, 0xa5, 0xa1, 0x93, 0x38, 0x55, 0xe3, 0x2d,
-	0x71, 0xaa, 0xe7, 0xf5, 0x13, 0x1c, 0xc9, 0x76, 0x26, 0xce, 0xae, 0x59, 0xb2, 0x9f, 0x6e, 0x59,
-	0x21, 0x68, 0x47, 0x70, 0x24, 0x7b, 0x5d, 0xa2, 0x55, 0x8e, 0x81, 0xe2, 0x86, 0x2e, 0xa1, 0x59,
-	0xb3, 0x2d, 0x79, 0xac, 0x3c, 0xa1, 0xc2, 0x9b, 0x76, 0x6f, 0x53, 0x59, 0x68, 0xba, 0x84, 0xa6,
-	0xbf, 0x81, 0xcd, 0xdf, 0xce, 0xa6, 0xb0, 0xea, 0xf7, 0x87, 0xd9, 0x1f, 0xeb, 0xe5, 0xbf, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0x76, 0x14, 0x93, 0xa6, 0xde, 0x06, 0x00, 0x00,
+	// 704 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0xdf, 0x4e, 0xdb, 0x3e,
+	0x14, 0x56, 0xda, 0xfe, 0x10, 0x3d, 0xe5, 0xc7, 0x2a, 0xb7, 0x65, 0x91, 0xa5, 0x41, 0xe6, 0xdd,
+	0x20, 0xa4, 0x75, 0x8c, 0x49, 0x03, 0x26, 0x6d, 0x1a, 0xa3, 0x80, 0xaa, 0x31, 0x98, 0x12, 0xb6,
+	0x8b, 0xb1, 0x9b, 0x6c, 0x78, 0xa5, 0xa2, 0xc4, 0x59, 0x6c, 0x90, 0xfa, 0x00, 0x7b, 0x95, 0x3d,
+	0xcb, 0x9e, 0x6a, 0x9a, 0x92, 0x38, 0x89, 0x9d, 0x3f, 0x05, 0xc4, 0x5d, 0x7d, 0x8e, 0xbf, 0xcf,
+	0xdf, 0xb1, 0xcf, 0xf9, 0x52, 0x68, 0xbf, 0x9b, 0xb0, 0xef, 0x17, 0x8e, 0x60, 0x01, 0xed, 0xfb,
+	0x01, 0x13, 0x0c, 0x35, 0x47, 0xd4, 0xa3, 0x81, 0x2b, 0xe8, 0x19, 0x5e, 0x70, 0xce, 0xdd, 0x80,
+	0x9e, 0xc5, 0x09, 0xf2, 0xcb, 0x80, 0xce, 0x6e, 0x40, 0x5d, 0x41, 0x3f, 0xb3, 0xc9, 0xd5, 0x25,
+	0xb5, 0xe9, 0xcf, 0x2b, 0xca, 0x05, 0x5a, 0x06, 0xe0, 0x9e, 0xeb, 0xf3, 0x73, 0x26, 0x86, 0x03,
+	0xd3, 0xb0, 0x8c, 0xd5, 0xa6, 0xad, 0x44, 0xc2, 0xfc, 0x75, 0x04, 0x38, 0x99, 0xfa, 0xd4, 0xac,
+	0xc5, 0xf9, 0x2c, 0x82, 0x30, 0xcc, 0xc7, 0xab, 0x9d, 0x2f, 0x66, 0x3d, 0xca, 0xa6, 0x6b, 0x84,
+	0xa0, 0x31, 0x66, 0x3e, 0x37, 0x1b, 0x96, 0xb1, 0x5a, 0xb7, 0xa3, 0xdf, 0x64, 0x03, 0xba, 0xba,
+	0x0c, 0xee, 0x33, 0x8f, 0x2b, 0x3c, 0xa9, 0x8a, 0x74, 0x4d, 0x8e, 0xa0, 0x7b, 0x40, 0x45, 0x0c,
+	0x18, 0x7a, 0x3f, 0x58, 0xa2, 0x7d, 0x06, 0x46, 0xd3, 0x55, 0xd3, 0x75, 0x91, 0xf7, 0xd0, 0xcb,
+	0xf1, 0x49, 0x11, 0x7a, 0xb1, 0x46, 0xa1, 0xd8, 0xa4, 0xa0, 0x9a, 0x52, 0xd0, 0x11, 0x74, 0x87,
+	0x3c, 0x29, 0xc6, 0x3d, 0x9b, 0xde, 0x57, 0xdc, 0x53, 0xe8, 0xe5, 0xf8, 0xa4, 0xb8, 0x2e, 0xfc,
+	0x17, 0x84, 0x81, 0x88, 0x6d, 0xde, 0x8e, 0x17, 0xe4, 0x8f, 0x01, 0xbd, 0xf8, 0x42, 0x1d, 0xf9,
+	0x68, 0xf7, 0x14, 0x80, 0xde, 0x40, 0x43, 0xb8, 0x23, 0x6e, 0xd6, 0xad, 0xfa, 0x6a, 0x6b, 0x63,
+	0xad, 0x9f, 0x76, 0x54, 0xbf, 0xf4, 0x9c, 0xfe, 0x89, 0x3b, 0xe2, 0x7b, 0x9e, 0x08, 0xa6, 0x76,
+	0x84, 0xc3, 0x9b, 0xd0, 0x4c, 0x43, 0xa8, 0x0d, 0xf5, 0x0b, 0x3a, 0x95, 0xe7, 0x87, 0x3f, 0xc3,
+	0x32, 0xae, 0xdd, 0xc9, 0x55, 0xd2, 0x4b, 0xf1, 0xe2, 0x55, 0x6d, 0xcb, 0x20, 0x5b, 0xb0, 0x94,
+	0x3f, 0x21, 0x7b, 0x97, 0x59, 0x4d, 0x4a, 0x36, 0xa1, 0x37, 0xa0, 0x13, 0x5a, 0xbc, 0x83, 0x9b,
+	0x80, 0x6f, 0x01, 0x65, 0x9d, 0x30, 0x48, 0x50, 0x6b, 0xd0, 0xf6, 0x69, 0xc0, 0xc7, 0x5c, 0x50,
+	0x4f, 0x26, 0x23, 0xec, 0x82, 0x5d, 0x88, 0x93, 0xe7, 0xd0, 0xd1, 0x18, 0x6e, 0xd1, 0xce, 0x5f,
+	0x01, 0x39, 0xf7, 0x3a, 0x54, 0x63, 0xaf, 0xe5, 0xd8, 0x77, 0xa0, 0xe3, 0x94, 0x08, 0xba, 0x4b,
+	0x4d, 0xdb, 0xf0, 0xf0, 0x80, 0x8a, 0xe4, 0x2e, 0x3f, 0x9e, 0xbb, 0xfc, 0xb6, 0x76, 0x41, 0xd6,
+	0xc1, 0x2c, 0x42, 0xb3, 0x06, 0xf6, 0xc3, 0x80, 0x84, 0xc5, 0x0b, 0xf2, 0x01, 0x3a, 0xbb, 0xcc,
+	0x9f, 0xde, 0xf1, 0xe5, 0xd0, 0x12, 0xcc, 0x05, 0x74, 0x34, 0x66, 0x9e, 0xbc, 0x00, 0xb9, 0x22,
+	0x2f, 0xa1, 0xab, 0xd3, 0xdd, 0xb2, 0x85, 0x7e, 0x1b, 0xd0, 0x3d, 0x1c, 0xf3, 0x54, 0x3a, 0x4f,
+	0x84, 0x1c, 0x03, 0x08, 0x77, 0xb4, 0x3f, 0x9e, 0x08, 0x1a, 0x70, 0xd3, 0x88, 0x86, 0xe2, 0x99,
+	0x32, 0x14, 0x65, 0xa0, 0xfe, 0x49, 0x8a, 0x88, 0x27, 0x43, 0xa1, 0xc0, 0xaf, 0xe1, 0x41, 0x2e,
+	0x7d, 0xa7, 0x29, 0xd9, 0x86, 0x5e, 0xee, 0x48, 0x59, 0xa1, 0x05, 0xad, 0xac, 0x9e, 0x58, 0x69,
+	0xd3, 0x56, 0x43, 0x1b, 0x7f, 0xe7, 0x00, 0xb2, 0x2f, 0x06, 0x5a, 0x87, 0xc6, 0xd0, 0x1b, 0x0b,
+	0xb4, 0xa4, 0x54, 0x13, 0x06, 0x64, 0x11, 0xb8, 0xad, 0xc4, 0xf7, 0x2e, 0x7d, 0x31, 0x45, 0xa7,
+	0x60, 0xaa, 0xe6, 0xbd, 0x1f, 0xb0, 0xcb, 0x44, 0x07, 0x5a, 0x2e, 0x18, 0x85, 0xf6, 0xa1, 0xc1,
+	0x2b, 0x95, 0x79, 0xa9, 0xdf, 0x86, 0xff, 0x35, 0x57, 0x46, 0x2a, 0xa2, 0xcc, 0xff, 0xb1, 0x55,
+	0xbd, 0x21, 0xe3, 0xd4, 0xcc, 0x54, 0xe3, 0x2c, 0xb3, 0x6d, 0x6c, 0x55, 0x6f, 0x90, 0x9c, 0x9f,
+	0x60, 0x51, 0xb7, 0x29, 0x64, 0xdd, 0xe4, 0x91, 0xf8, 0xf1, 0x8c, 0x1d, 0x92, 0x76, 0x00, 0x8b,
+	0xba, 0x87, 0x69, 0xb4, 0xa5, 0xf6, 0x56, 0xf2, 0x42, 0x87, 0xd0, 0x52, 0xec, 0x08, 0x3d, 0x2a,
+	0xbd, 0xa1, 0xc4, 0x73, 0xf0, 0x72, 0x55, 0x5a, 0x6a, 0x3a, 0x84, 0x96, 0x53, 0xc1, 0xe6, 0xcc,
+	0x66, 0x2b, 0xb3, 0xa0, 0x53, 0x68, 0xe7, 0xbd, 0x01, 0x11, 0x5d, 0x41, 0x99, 0xe7, 0xe0, 0x27,
+	0x33, 0xf7, 0x48, 0xf2, 0x63, 0x58, 0x50, 0xe7, 0x5e, 0x6f, 0xc7, 0xa2, 0xbf, 0xe0, 0x95, 0xca,
+	0x7c, 0xd6, 0x3a, 0xda, 0x9c, 0x69, 0xad, 0x53, 0x36, 0xf4, 0xd8, 0xaa, 0xde, 0x10, 0x73, 0x7e,
+	0x9b, 0x8b, 0xfe, 0x8b, 0xbd, 0xf8, 0x37, 0x00, 0x82, 0x12, 0xb7, 0xc6, 0xb8, 0x09, 0x00, 0x00,
 }
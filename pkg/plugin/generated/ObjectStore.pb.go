@@ -241,6 +241,70 @@ func (m *CreateSignedURLResponse) GetUrl() string {
 	return ""
 }
 
+type GetObjectIfChangedRequest struct {
+	Bucket string `protobuf:"bytes,1,opt,name=bucket" json:"bucket,omitempty"`
+	Key    string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	Etag   string `protobuf:"bytes,3,opt,name=etag" json:"etag,omitempty"`
+}
+
+func (m *GetObjectIfChangedRequest) Reset()                    { *m = GetObjectIfChangedRequest{} }
+func (m *GetObjectIfChangedRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetObjectIfChangedRequest) ProtoMessage()               {}
+func (*GetObjectIfChangedRequest) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{10} }
+
+func (m *GetObjectIfChangedRequest) GetBucket() string {
+	if m != nil {
+		return m.Bucket
+	}
+	return ""
+}
+
+func (m *GetObjectIfChangedRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *GetObjectIfChangedRequest) GetEtag() string {
+	if m != nil {
+		return m.Etag
+	}
+	return ""
+}
+
+type GetObjectIfChangedResponse struct {
+	Data        []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Etag        string `protobuf:"bytes,2,opt,name=etag" json:"etag,omitempty"`
+	NotModified bool   `protobuf:"varint,3,opt,name=notModified" json:"notModified,omitempty"`
+}
+
+func (m *GetObjectIfChangedResponse) Reset()                    { *m = GetObjectIfChangedResponse{} }
+func (m *GetObjectIfChangedResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetObjectIfChangedResponse) ProtoMessage()               {}
+func (*GetObjectIfChangedResponse) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{11} }
+
+func (m *GetObjectIfChangedResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *GetObjectIfChangedResponse) GetEtag() string {
+	if m != nil {
+		return m.Etag
+	}
+	return ""
+}
+
+func (m *GetObjectIfChangedResponse) GetNotModified() bool {
+	if m != nil {
+		return m.NotModified
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterType((*PutObjectRequest)(nil), "generated.PutObjectRequest")
 	proto.RegisterType((*GetObjectRequest)(nil), "generated.GetObjectRequest")
@@ -252,6 +316,8 @@ func init() {
 	proto.RegisterType((*DeleteObjectRequest)(nil), "generated.DeleteObjectRequest")
 	proto.RegisterType((*CreateSignedURLRequest)(nil), "generated.CreateSignedURLRequest")
 	proto.RegisterType((*CreateSignedURLResponse)(nil), "generated.CreateSignedURLResponse")
+	proto.RegisterType((*GetObjectIfChangedRequest)(nil), "generated.GetObjectIfChangedRequest")
+	proto.RegisterType((*GetObjectIfChangedResponse)(nil), "generated.GetObjectIfChangedResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -272,6 +338,7 @@ type ObjectStoreClient interface {
 	ListObjects(ctx context.Context, in *ListObjectsRequest, opts ...grpc.CallOption) (*ListObjectsResponse, error)
 	DeleteObject(ctx context.Context, in *DeleteObjectRequest, opts ...grpc.CallOption) (*Empty, error)
 	CreateSignedURL(ctx context.Context, in *CreateSignedURLRequest, opts ...grpc.CallOption) (*CreateSignedURLResponse, error)
+	GetObjectIfChanged(ctx context.Context, in *GetObjectIfChangedRequest, opts ...grpc.CallOption) (*GetObjectIfChangedResponse, error)
 }
 
 type objectStoreClient struct {
@@ -393,6 +460,15 @@ func (c *objectStoreClient) CreateSignedURL(ctx context.Context, in *CreateSigne
 	return out, nil
 }
 
+func (c *objectStoreClient) GetObjectIfChanged(ctx context.Context, in *GetObjectIfChangedRequest, opts ...grpc.CallOption) (*GetObjectIfChangedResponse, error) {
+	out := new(GetObjectIfChangedResponse)
+	err := grpc.Invoke(ctx, "/generated.ObjectStore/GetObjectIfChanged", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for ObjectStore service
 
 type ObjectStoreServer interface {
@@ -403,6 +479,7 @@ type ObjectStoreServer interface {
 	ListObjects(context.Context, *ListObjectsRequest) (*ListObjectsResponse, error)
 	DeleteObject(context.Context, *DeleteObjectRequest) (*Empty, error)
 	CreateSignedURL(context.Context, *CreateSignedURLRequest) (*CreateSignedURLResponse, error)
+	GetObjectIfChanged(context.Context, *GetObjectIfChangedRequest) (*GetObjectIfChangedResponse, error)
 }
 
 func RegisterObjectStoreServer(s *grpc.Server, srv ObjectStoreServer) {
@@ -546,6 +623,24 @@ func _ObjectStore_CreateSignedURL_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ObjectStore_GetObjectIfChanged_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetObjectIfChangedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ObjectStoreServer).GetObjectIfChanged(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/generated.ObjectStore/GetObjectIfChanged",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ObjectStoreServer).GetObjectIfChanged(ctx, req.(*GetObjectIfChangedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _ObjectStore_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "generated.ObjectStore",
 	HandlerType: (*ObjectStoreServer)(nil),
@@ -570,6 +665,10 @@ var _ObjectStore_serviceDesc = grpc.ServiceDesc{
 			MethodName: "CreateSignedURL",
 			Handler:    _ObjectStore_CreateSignedURL_Handler,
 		},
+		{
+			MethodName: "GetObjectIfChanged",
+			Handler:    _ObjectStore_GetObjectIfChanged_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -589,33 +688,37 @@ var _ObjectStore_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("ObjectStore.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 444 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x94, 0xdf, 0x8b, 0xd3, 0x40,
-	0x10, 0xc7, 0x89, 0xa9, 0xc5, 0xcc, 0x15, 0x8c, 0x73, 0x50, 0x6b, 0x4e, 0xa5, 0x2e, 0x0a, 0x15,
-	0xa1, 0x1c, 0xfa, 0xe2, 0xc3, 0x81, 0xe2, 0x9d, 0x88, 0x50, 0xb0, 0xa6, 0x0a, 0xbe, 0xa6, 0x97,
-	0xf1, 0x8c, 0xcd, 0x2f, 0x37, 0x13, 0x30, 0xff, 0x81, 0x7f, 0xb6, 0x64, 0xb3, 0xc6, 0x4d, 0x2e,
-	0x67, 0xf1, 0xde, 0x66, 0x67, 0xe7, 0xfb, 0x9d, 0xc9, 0xce, 0x87, 0xc0, 0x9d, 0x0f, 0xdb, 0xef,
-	0x74, 0xce, 0x1b, 0xce, 0x24, 0x2d, 0x73, 0x99, 0x71, 0x86, 0xce, 0x05, 0xa5, 0x24, 0x03, 0xa6,
-	0xd0, 0x9b, 0x6c, 0xbe, 0x05, 0x92, 0xc2, 0xe6, 0x42, 0xac, 0xc1, 0x5d, 0x97, 0xdc, 0x08, 0x7c,
-	0xfa, 0x51, 0x52, 0xc1, 0x38, 0x85, 0xf1, 0xb6, 0x3c, 0xdf, 0x11, 0xcf, 0xac, 0xb9, 0xb5, 0x70,
-	0x7c, 0x7d, 0x42, 0x17, 0xec, 0x1d, 0x55, 0xb3, 0x1b, 0x2a, 0x59, 0x87, 0x88, 0x30, 0xda, 0x66,
-	0x61, 0x35, 0xb3, 0xe7, 0xd6, 0x62, 0xe2, 0xab, 0x58, 0x9c, 0x80, 0xfb, 0x8e, 0xae, 0xeb, 0x28,
-	0x8e, 0xe0, 0xe6, 0x9b, 0x8a, 0xa9, 0xa8, 0xad, 0xc3, 0x80, 0x03, 0x25, 0x98, 0xf8, 0x2a, 0x16,
-	0x1f, 0xe1, 0xde, 0x2a, 0x2a, 0xf8, 0x34, 0x4b, 0x92, 0x2c, 0x5d, 0x4b, 0xfa, 0x1a, 0xfd, 0xa4,
-	0x62, 0x5f, 0x8f, 0xfb, 0xe0, 0x84, 0x14, 0x47, 0x49, 0xc4, 0x24, 0x75, 0xa7, 0xbf, 0x09, 0xf1,
-	0x12, 0xbc, 0x21, 0xcb, 0x22, 0xcf, 0xd2, 0x82, 0xd0, 0x83, 0x5b, 0xb9, 0xce, 0xcd, 0xac, 0xb9,
-	0xbd, 0x70, 0xfc, 0xf6, 0x2c, 0xce, 0x00, 0x6b, 0x65, 0xf3, 0xa1, 0x7b, 0xa7, 0x98, 0xc2, 0xb8,
-	0x51, 0xea, 0x11, 0xf4, 0x49, 0x3c, 0x85, 0xc3, 0x8e, 0x8b, 0x6e, 0x8c, 0x30, 0xda, 0x51, 0xf5,
-	0xa7, 0xa9, 0x8a, 0xc5, 0x2b, 0x38, 0x3c, 0xa3, 0x98, 0x98, 0xae, 0xfb, 0xb6, 0x9f, 0x60, 0x7a,
-	0x2a, 0x29, 0x60, 0xda, 0x44, 0x17, 0x29, 0x85, 0x9f, 0xfd, 0xd5, 0xff, 0x6f, 0xdc, 0x05, 0x9b,
-	0x39, 0x56, 0x0b, 0xb7, 0xfd, 0x3a, 0x14, 0xcf, 0xe0, 0xee, 0x25, 0x57, 0xfd, 0x15, 0x2e, 0xd8,
-	0xa5, 0x8c, 0xb5, 0x67, 0x1d, 0x3e, 0xff, 0x35, 0x82, 0x03, 0x83, 0x4e, 0x3c, 0x86, 0xd1, 0xfb,
-	0x34, 0x62, 0x9c, 0x2e, 0x5b, 0x40, 0x97, 0x75, 0x42, 0x0f, 0xe6, 0xb9, 0x46, 0xfe, 0x6d, 0x92,
-	0x73, 0x85, 0x27, 0xe0, 0xb4, 0xc0, 0xe2, 0x91, 0x71, 0xdd, 0xc7, 0xf8, 0xb2, 0x76, 0x61, 0xd5,
-	0xea, 0x16, 0xce, 0x8e, 0xba, 0x8f, 0x6c, 0x47, 0xad, 0x88, 0x3c, 0xb6, 0x30, 0x68, 0x56, 0xde,
-	0x85, 0x05, 0x1f, 0x1b, 0x95, 0x57, 0xe2, 0xe9, 0x3d, 0xd9, 0x53, 0xa5, 0x9f, 0x6c, 0x05, 0x07,
-	0x06, 0x0f, 0xf8, 0xa0, 0xa7, 0xea, 0xd2, 0xe6, 0x3d, 0xbc, 0xea, 0x5a, 0xbb, 0xbd, 0x86, 0x89,
-	0x89, 0x0c, 0x9a, 0xf5, 0x03, 0x2c, 0x0d, 0x3c, 0xf7, 0x17, 0xb8, 0xdd, 0xdb, 0x2e, 0x3e, 0x32,
-	0x8a, 0x86, 0x79, 0xf2, 0xc4, 0xbf, 0x4a, 0x9a, 0xd9, 0xb6, 0x63, 0xf5, 0x03, 0x7a, 0xf1, 0x3b,
-	0x00, 0x00, 0xff, 0xff, 0xf3, 0xb2, 0x85, 0x9a, 0xae, 0x04, 0x00, 0x00,
+	// 509 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0x51, 0x8f, 0xd2, 0x40,
+	0x10, 0x4e, 0xaf, 0x48, 0xae, 0x03, 0x89, 0x75, 0x2e, 0x41, 0xae, 0xa7, 0x06, 0x37, 0x5e, 0x82,
+	0x31, 0x21, 0x17, 0x7d, 0xf1, 0xe1, 0x12, 0x8d, 0x9c, 0x21, 0x97, 0x60, 0xc4, 0xa2, 0x89, 0x3e,
+	0x96, 0xeb, 0xc0, 0x55, 0xa0, 0xc5, 0x76, 0x48, 0xec, 0x6f, 0xf6, 0x4f, 0x98, 0x6e, 0xd7, 0xb2,
+	0x40, 0x81, 0xc8, 0xdb, 0xec, 0xec, 0x7c, 0xdf, 0xcc, 0x4e, 0xbf, 0xaf, 0xf0, 0xe8, 0xf3, 0xe8,
+	0x27, 0xdd, 0xf1, 0x90, 0xa3, 0x98, 0x3a, 0x8b, 0x38, 0xe2, 0x08, 0xad, 0x09, 0x85, 0x14, 0x7b,
+	0x4c, 0xbe, 0x53, 0x1f, 0xde, 0x7b, 0x31, 0xf9, 0xf9, 0x85, 0x18, 0x80, 0x3d, 0x58, 0x72, 0x0e,
+	0x70, 0xe9, 0xd7, 0x92, 0x12, 0xc6, 0x06, 0x54, 0x47, 0xcb, 0xbb, 0x29, 0x71, 0xd3, 0x68, 0x19,
+	0x6d, 0xcb, 0x55, 0x27, 0xb4, 0xc1, 0x9c, 0x52, 0xda, 0x3c, 0x91, 0xc9, 0x2c, 0x44, 0x84, 0xca,
+	0x28, 0xf2, 0xd3, 0xa6, 0xd9, 0x32, 0xda, 0x75, 0x57, 0xc6, 0xe2, 0x1a, 0xec, 0x1e, 0x1d, 0xcb,
+	0x28, 0x2e, 0xe0, 0xc1, 0x87, 0x94, 0x29, 0xc9, 0xa8, 0x7d, 0x8f, 0x3d, 0x09, 0xa8, 0xbb, 0x32,
+	0x16, 0x5f, 0xe0, 0xbc, 0x1f, 0x24, 0xdc, 0x8d, 0xe6, 0xf3, 0x28, 0x1c, 0xc4, 0x34, 0x0e, 0x7e,
+	0x53, 0x72, 0xa8, 0xc7, 0x13, 0xb0, 0x7c, 0x9a, 0x05, 0xf3, 0x80, 0x29, 0x56, 0x9d, 0x56, 0x09,
+	0xf1, 0x16, 0x9c, 0x32, 0xca, 0x64, 0x11, 0x85, 0x09, 0xa1, 0x03, 0xa7, 0x0b, 0x95, 0x6b, 0x1a,
+	0x2d, 0xb3, 0x6d, 0xb9, 0xc5, 0x59, 0xdc, 0x00, 0x66, 0xc8, 0xfc, 0xa1, 0x07, 0xa7, 0x68, 0x40,
+	0x35, 0x47, 0xaa, 0x11, 0xd4, 0x49, 0xbc, 0x84, 0xb3, 0x35, 0x16, 0xd5, 0x18, 0xa1, 0x32, 0xa5,
+	0xf4, 0x5f, 0x53, 0x19, 0x8b, 0x77, 0x70, 0x76, 0x43, 0x33, 0x62, 0x3a, 0x76, 0xb7, 0x5f, 0xa1,
+	0xd1, 0x8d, 0xc9, 0x63, 0x1a, 0x06, 0x93, 0x90, 0xfc, 0x6f, 0x6e, 0xff, 0xff, 0xbf, 0xb8, 0x0d,
+	0x26, 0xf3, 0x4c, 0x7e, 0x70, 0xd3, 0xcd, 0x42, 0xf1, 0x0a, 0x1e, 0x6f, 0xb1, 0xaa, 0x57, 0xd8,
+	0x60, 0x2e, 0xe3, 0x99, 0xe2, 0xcc, 0x42, 0xf1, 0x03, 0xce, 0x0b, 0x71, 0xdc, 0x8e, 0xbb, 0xf7,
+	0x5e, 0x38, 0x21, 0xff, 0x28, 0xdd, 0x11, 0x7b, 0x13, 0x39, 0x86, 0xe5, 0xca, 0x58, 0x8c, 0xc1,
+	0x29, 0xa3, 0x5e, 0x2d, 0x74, 0x53, 0x4e, 0x05, 0xcb, 0xc9, 0x8a, 0x05, 0x5b, 0x50, 0x0b, 0x23,
+	0xfe, 0x14, 0xf9, 0xc1, 0x38, 0x20, 0x5f, 0x36, 0x38, 0x75, 0xf5, 0xd4, 0xeb, 0x3f, 0x15, 0xa8,
+	0x69, 0x06, 0xc3, 0x2b, 0xa8, 0xdc, 0x86, 0x01, 0x63, 0xa3, 0x53, 0x78, 0xac, 0x93, 0x25, 0xd4,
+	0xab, 0x1c, 0x5b, 0xcb, 0x7f, 0x9c, 0x2f, 0x38, 0xc5, 0x6b, 0xb0, 0x0a, 0xcf, 0xe1, 0x85, 0x76,
+	0xbd, 0xe9, 0xc4, 0x6d, 0x6c, 0xdb, 0xc8, 0xd0, 0x3d, 0x2a, 0x43, 0xf7, 0x68, 0x0f, 0x5a, 0x9a,
+	0xea, 0xca, 0x40, 0x0f, 0x70, 0x5b, 0xef, 0xf8, 0x42, 0xab, 0xdc, 0xe9, 0x30, 0xe7, 0xf2, 0x40,
+	0x95, 0x5a, 0x75, 0x1f, 0x6a, 0x9a, 0xa4, 0xf1, 0xe9, 0x06, 0x6a, 0xdd, 0x30, 0xce, 0xb3, 0x5d,
+	0xd7, 0x8a, 0xed, 0x3d, 0xd4, 0x75, 0xd5, 0xa3, 0x5e, 0x5f, 0x62, 0x87, 0x92, 0x75, 0x7f, 0x87,
+	0x87, 0x1b, 0x02, 0xc5, 0xe7, 0x5a, 0x51, 0xb9, 0x25, 0x1c, 0xb1, 0xaf, 0x44, 0xcd, 0xe6, 0x01,
+	0x6e, 0x4b, 0x6e, 0x6d, 0x99, 0x3b, 0xc5, 0xee, 0x5c, 0x1e, 0xa8, 0xca, 0x5b, 0x8c, 0xaa, 0xf2,
+	0x37, 0xfd, 0xe6, 0xef, 0x00, 0x94, 0xff, 0x8c, 0xb2, 0xd4, 0x05, 0x00, 0x00,
 }
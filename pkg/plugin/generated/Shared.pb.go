@@ -42,6 +42,7 @@ type AppliesToResponse struct {
 	IncludedResources  []string `protobuf:"bytes,3,rep,name=includedResources" json:"includedResources,omitempty"`
 	ExcludedResources  []string `protobuf:"bytes,4,rep,name=excludedResources" json:"excludedResources,omitempty"`
 	Selector           string   `protobuf:"bytes,5,opt,name=selector" json:"selector,omitempty"`
+	FieldSelector      string   `protobuf:"bytes,6,opt,name=fieldSelector" json:"fieldSelector,omitempty"`
 }
 
 func (m *AppliesToResponse) Reset()                    { *m = AppliesToResponse{} }
@@ -84,6 +85,13 @@ func (m *AppliesToResponse) GetSelector() string {
 	return ""
 }
 
+func (m *AppliesToResponse) GetFieldSelector() string {
+	if m != nil {
+		return m.FieldSelector
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Empty)(nil), "generated.Empty")
 	proto.RegisterType((*InitRequest)(nil), "generated.InitRequest")
@@ -93,22 +101,22 @@ func init() {
 func init() { proto.RegisterFile("Shared.proto", fileDescriptor4) }
 
 var fileDescriptor4 = []byte{
-	// 257 bytes of a gzipped FileDescriptorProto
+	// 269 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0xd1, 0xb1, 0x4e, 0xc3, 0x30,
-	0x10, 0x06, 0x60, 0xb9, 0x21, 0x85, 0x5c, 0x18, 0xa8, 0xc5, 0x10, 0x75, 0xaa, 0x32, 0x75, 0x40,
-	0x19, 0x60, 0x81, 0x6e, 0x08, 0x75, 0x60, 0x61, 0x30, 0xbc, 0x80, 0x49, 0x7e, 0x4a, 0x44, 0x6a,
-	0x1b, 0xdb, 0x41, 0x64, 0xe7, 0x6d, 0x79, 0x09, 0x14, 0x87, 0x96, 0x4a, 0x61, 0xf3, 0xdd, 0xff,
-	0xdd, 0xc9, 0x96, 0xe9, 0xf4, 0xf1, 0x55, 0x5a, 0x54, 0x85, 0xb1, 0xda, 0x6b, 0x9e, 0x6c, 0xa0,
-	0x60, 0xa5, 0x47, 0x95, 0x1f, 0x53, 0xbc, 0xde, 0x1a, 0xdf, 0xe5, 0x5f, 0x8c, 0xd2, 0x7b, 0x55,
-	0x7b, 0x81, 0xf7, 0x16, 0xce, 0xf3, 0x15, 0x4d, 0x4b, 0xad, 0x5e, 0xea, 0x4d, 0xc6, 0x16, 0xd1,
-	0x32, 0xbd, 0xcc, 0x8b, 0xfd, 0x50, 0x71, 0xe0, 0x8a, 0xbb, 0x80, 0xd6, 0xca, 0xdb, 0x4e, 0xfc,
-	0x4e, 0xcc, 0x6f, 0x28, 0x3d, 0x68, 0xf3, 0x33, 0x8a, 0xde, 0xd0, 0x65, 0x6c, 0xc1, 0x96, 0x89,
-	0xe8, 0x8f, 0xfc, 0x9c, 0xe2, 0x0f, 0xd9, 0xb4, 0xc8, 0x26, 0xa1, 0x37, 0x14, 0xab, 0xc9, 0x35,
-	0xcb, 0xbf, 0x19, 0xcd, 0x6e, 0x8d, 0x69, 0x6a, 0xb8, 0x27, 0x2d, 0xe0, 0x8c, 0x56, 0x0e, 0xbc,
-	0x20, 0x5e, 0xab, 0xb2, 0x69, 0x2b, 0x54, 0x0f, 0x72, 0x0b, 0x67, 0x64, 0x09, 0x17, 0x2e, 0x96,
-	0x88, 0x7f, 0x92, 0xde, 0xe3, 0x73, 0xe4, 0x27, 0x83, 0x1f, 0x27, 0xfc, 0x82, 0x66, 0xbb, 0x2d,
-	0x02, 0x4e, 0xb7, 0xb6, 0xe7, 0x51, 0xe0, 0xe3, 0xa0, 0xd7, 0xbb, 0x1d, 0x7f, 0xfa, 0x68, 0xd0,
-	0xa3, 0x80, 0xcf, 0xe9, 0xc4, 0xa1, 0x41, 0xe9, 0xb5, 0xcd, 0xe2, 0xf0, 0xdc, 0x7d, 0xfd, 0x3c,
-	0x0d, 0xff, 0x71, 0xf5, 0x13, 0x00, 0x00, 0xff, 0xff, 0x19, 0xd7, 0x88, 0x92, 0x9f, 0x01, 0x00,
-	0x00,
+	0x14, 0x05, 0x50, 0x25, 0x25, 0x81, 0xbc, 0x80, 0x44, 0x2d, 0x86, 0xa8, 0x53, 0x15, 0x31, 0x74,
+	0x40, 0x19, 0x60, 0x81, 0x6e, 0x08, 0x75, 0x60, 0x61, 0x70, 0xf9, 0x81, 0x90, 0xdc, 0x96, 0x88,
+	0xd4, 0x36, 0xb6, 0x83, 0x9a, 0x9d, 0x1f, 0xe0, 0x8f, 0x51, 0x1c, 0x5a, 0x15, 0xc2, 0x96, 0x77,
+	0xef, 0x79, 0x91, 0x2d, 0xd3, 0xe9, 0xf2, 0x35, 0xd7, 0x28, 0x33, 0xa5, 0xa5, 0x95, 0x2c, 0x5a,
+	0x43, 0x40, 0xe7, 0x16, 0x65, 0x7a, 0x4c, 0xc1, 0x62, 0xa3, 0x6c, 0x9b, 0x7e, 0x7a, 0x14, 0x3f,
+	0x8a, 0xca, 0x72, 0xbc, 0x37, 0x30, 0x96, 0xcd, 0x29, 0x2c, 0xa4, 0x58, 0x55, 0xeb, 0xc4, 0x9b,
+	0x8e, 0x66, 0xf1, 0x75, 0x9a, 0xed, 0x97, 0xb2, 0x03, 0x97, 0x3d, 0x38, 0xb4, 0x10, 0x56, 0xb7,
+	0xfc, 0x67, 0x63, 0x72, 0x47, 0xf1, 0x41, 0xcc, 0xce, 0x69, 0xf4, 0x86, 0x36, 0xf1, 0xa6, 0xde,
+	0x2c, 0xe2, 0xdd, 0x27, 0xbb, 0xa0, 0xe0, 0x23, 0xaf, 0x1b, 0x24, 0xbe, 0xcb, 0xfa, 0x61, 0xee,
+	0xdf, 0x7a, 0xe9, 0x97, 0x4f, 0xe3, 0x7b, 0xa5, 0xea, 0x0a, 0xe6, 0x59, 0x72, 0x18, 0x25, 0x85,
+	0x01, 0xcb, 0x88, 0x55, 0xa2, 0xa8, 0x9b, 0x12, 0xe5, 0x53, 0xbe, 0x81, 0x51, 0x79, 0x01, 0xe3,
+	0x0e, 0x16, 0xf1, 0x7f, 0x9a, 0xce, 0x63, 0x3b, 0xf0, 0x7e, 0xef, 0x87, 0x0d, 0xbb, 0xa2, 0xf1,
+	0xee, 0x2f, 0x1c, 0x46, 0x36, 0xba, 0xe3, 0x23, 0xc7, 0x87, 0x45, 0xa7, 0xb1, 0xfd, 0x13, 0x26,
+	0x47, 0xbd, 0x1e, 0x14, 0x6c, 0x42, 0x27, 0x06, 0x35, 0x0a, 0x2b, 0x75, 0x12, 0xb8, 0xeb, 0xee,
+	0x67, 0x76, 0x49, 0x67, 0xab, 0x0a, 0x75, 0xb9, 0xdc, 0x81, 0xd0, 0x81, 0xdf, 0xe1, 0x4b, 0xe8,
+	0x5e, 0xed, 0xe6, 0x7b, 0x00, 0x80, 0x6f, 0x08, 0x6c, 0xc5, 0x01, 0x00, 0x00,
 }
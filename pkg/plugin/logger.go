@@ -48,5 +48,9 @@ func NewLogger() logrus.FieldLogger {
 	// Ark server code
 	logger.Hooks.Add(&logging.HcLogLevelHook{})
 
+	// this hook tags entries logged while serving a call made on behalf of
+	// a backup or restore so the Ark server can add them to its log
+	logger.Hooks.Add(pluginCorrelation)
+
 	return logger
 }
@@ -27,11 +27,16 @@ import (
 const pluginNameField = "pluginName"
 
 // logrusAdapter implements the hclog.Logger interface and
-// delegates all calls to a logrus logger.
+// delegates all calls to a logrus logger. Entries that belong
+// to a backup or restore, either because they carry its correlation
+// ID or because the adapter was created for it, are logged to the
+// log registered for that correlation ID if there is one.
 type logrusAdapter struct {
-	impl  logrus.FieldLogger
-	level logrus.Level
-	name  string
+	impl          logrus.FieldLogger
+	level         logrus.Level
+	name          string
+	fields        logrus.Fields
+	correlationID string
 }
 
 // args are alternating key, value pairs, where the keys
@@ -63,6 +68,46 @@ func argsToFields(args ...interface{}) logrus.Fields {
 	return logrus.Fields(fields)
 }
 
+// entry returns the logger that an entry with the given key/value
+// pairs should be emitted with.
+func (l *logrusAdapter) entry(args ...interface{}) logrus.FieldLogger {
+	fields := argsToFields(args...)
+
+	correlationID := l.correlationID
+	if id, ok := fields[correlationIDField].(string); ok {
+		correlationID = id
+		delete(fields, correlationIDField)
+	}
+
+	log := l.impl
+	if correlationID != "" {
+		if operationLog := operationLogs.get(correlationID); operationLog != nil {
+			log = operationLog
+		}
+	}
+
+	return log.WithFields(l.fields).WithFields(fields)
+}
+
+// withFields returns a copy of l that adds fields to each entry.
+func (l *logrusAdapter) withFields(fields logrus.Fields) *logrusAdapter {
+	merged := make(logrus.Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return &logrusAdapter{
+		impl:          l.impl,
+		level:         l.level,
+		name:          l.name,
+		fields:        merged,
+		correlationID: l.correlationID,
+	}
+}
+
 // Trace emits a message and key/value pairs at the DEBUG level
 // (logrus doesn't have a TRACE level)
 func (l *logrusAdapter) Trace(msg string, args ...interface{}) {
@@ -71,22 +116,22 @@ func (l *logrusAdapter) Trace(msg string, args ...interface{}) {
 
 // Debug emits a message and key/value pairs at the DEBUG level
 func (l *logrusAdapter) Debug(msg string, args ...interface{}) {
-	l.impl.WithFields(argsToFields(args...)).Debug(msg)
+	l.entry(args...).Debug(msg)
 }
 
 // Info emits a message and key/value pairs at the INFO level
 func (l *logrusAdapter) Info(msg string, args ...interface{}) {
-	l.impl.WithFields(argsToFields(args...)).Info(msg)
+	l.entry(args...).Info(msg)
 }
 
 // Warn emits a message and key/value pairs at the WARN level
 func (l *logrusAdapter) Warn(msg string, args ...interface{}) {
-	l.impl.WithFields(argsToFields(args...)).Warn(msg)
+	l.entry(args...).Warn(msg)
 }
 
 // Error emits a message and key/value pairs at the ERROR level
 func (l *logrusAdapter) Error(msg string, args ...interface{}) {
-	l.impl.WithFields(argsToFields(args...)).Error(msg)
+	l.entry(args...).Error(msg)
 }
 
 // IsTrace indicates if TRACE logs would be emitted. This and the other Is* guards
@@ -121,10 +166,7 @@ func (l *logrusAdapter) IsError() bool {
 
 // With creates a sublogger that will always have the given key/value pairs
 func (l *logrusAdapter) With(args ...interface{}) hclog.Logger {
-	return &logrusAdapter{
-		impl:  l.impl.WithFields(argsToFields(args...)),
-		level: l.level,
-	}
+	return l.withFields(argsToFields(args...))
 }
 
 // Named creates a logger that will add a `pluginName` field with the name string
@@ -145,11 +187,10 @@ func (l *logrusAdapter) Named(name string) hclog.Logger {
 // as the value. This sets the name of the logger to the value directly, unlike `Named`
 // which appends the given value to the current name.
 func (l *logrusAdapter) ResetNamed(name string) hclog.Logger {
-	return &logrusAdapter{
-		impl:  l.impl.WithField(pluginNameField, name),
-		level: l.level,
-		name:  name,
-	}
+	named := l.withFields(logrus.Fields{pluginNameField: name})
+	named.name = name
+
+	return named
 }
 
 // StandardLogger returns a value that conforms to the stdlib log.Logger interface
@@ -189,10 +189,13 @@ func (m *manager) registerPlugins() error {
 	m.pluginRegistry.register("pv", arkCommand, []string{"run-plugin", string(PluginKindBackupItemAction), "pv"}, PluginKindBackupItemAction)
 	m.pluginRegistry.register("backup-pod", arkCommand, []string{"run-plugin", string(PluginKindBackupItemAction), "pod"}, PluginKindBackupItemAction)
 	m.pluginRegistry.register("serviceaccount", arkCommand, []string{"run-plugin", string(PluginKindBackupItemAction), "serviceaccount"}, PluginKindBackupItemAction)
+	m.pluginRegistry.register("backup-replicas", arkCommand, []string{"run-plugin", string(PluginKindBackupItemAction), "replicas"}, PluginKindBackupItemAction)
 
 	m.pluginRegistry.register("job", arkCommand, []string{"run-plugin", string(PluginKindRestoreItemAction), "job"}, PluginKindRestoreItemAction)
 	m.pluginRegistry.register("restore-pod", arkCommand, []string{"run-plugin", string(PluginKindRestoreItemAction), "pod"}, PluginKindRestoreItemAction)
 	m.pluginRegistry.register("svc", arkCommand, []string{"run-plugin", string(PluginKindRestoreItemAction), "svc"}, PluginKindRestoreItemAction)
+	m.pluginRegistry.register("image-registry", arkCommand, []string{"run-plugin", string(PluginKindRestoreItemAction), "image-registry"}, PluginKindRestoreItemAction)
+	m.pluginRegistry.register("restore-replicas", arkCommand, []string{"run-plugin", string(PluginKindRestoreItemAction), "replicas"}, PluginKindRestoreItemAction)
 
 	// second, register external plugins (these will override internal plugins, if applicable)
 	if _, err := os.Stat(m.pluginDir); err != nil {
@@ -313,7 +316,7 @@ func (m *manager) GetBackupItemActions(backupName string) ([]backup.ItemAction,
 
 		// create clients for each
 		for _, plugin := range pluginInfo {
-			logger := &logrusAdapter{impl: m.logger, level: m.logLevel}
+			logger := &logrusAdapter{impl: m.logger, level: m.logLevel, correlationID: backupCorrelationID(backupName)}
 			client := newClientBuilder(baseConfig()).
 				withCommand(plugin.commandName, plugin.commandArgs...).
 				withPlugin(PluginKindBackupItemAction, &BackupItemActionPlugin{log: logger}).
@@ -349,6 +352,7 @@ func (m *manager) GetBackupItemActions(backupName string) ([]backup.ItemAction,
 // CloseBackupItemActions terminates the plugin sub-processes that
 // are hosting BackupItemAction plugins for the given backup name.
 func (m *manager) CloseBackupItemActions(backupName string) error {
+	operationLogs.delete(backupCorrelationID(backupName))
 	return closeAll(m.clientStore, PluginKindBackupItemAction, backupName)
 }
 
@@ -362,7 +366,7 @@ func (m *manager) GetRestoreItemActions(restoreName string) ([]restore.ItemActio
 
 		// create clients for each
 		for _, plugin := range pluginInfo {
-			logger := &logrusAdapter{impl: m.logger, level: m.logLevel}
+			logger := &logrusAdapter{impl: m.logger, level: m.logLevel, correlationID: restoreCorrelationID(restoreName)}
 			client := newClientBuilder(baseConfig()).
 				withCommand(plugin.commandName, plugin.commandArgs...).
 				withPlugin(PluginKindRestoreItemAction, &RestoreItemActionPlugin{log: logger}).
@@ -398,6 +402,7 @@ func (m *manager) GetRestoreItemActions(restoreName string) ([]restore.ItemActio
 // CloseRestoreItemActions terminates the plugin sub-processes that
 // are hosting RestoreItemAction plugins for the given restore name.
 func (m *manager) CloseRestoreItemActions(restoreName string) error {
+	operationLogs.delete(restoreCorrelationID(restoreName))
 	return closeAll(m.clientStore, PluginKindRestoreItemAction, restoreName)
 }
 
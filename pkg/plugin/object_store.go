@@ -17,12 +17,15 @@ limitations under the License.
 package plugin
 
 import (
+	"bytes"
 	"io"
+	"io/ioutil"
 	"time"
 
 	"github.com/hashicorp/go-plugin"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	"github.com/heptio/ark/pkg/cloudprovider"
 	proto "github.com/heptio/ark/pkg/plugin/generated"
@@ -128,6 +131,29 @@ func (c *ObjectStoreGRPCClient) GetObject(bucket, key string) (io.ReadCloser, er
 	return &StreamReadCloser{receive: receive, close: close}, nil
 }
 
+// GetObjectIfChanged retrieves the object with the given key from the specified
+// bucket in object storage along with its current ETag, unless etag matches the
+// object's current ETag, in which case it returns cloudprovider.ErrObjectNotModified.
+// Unlike GetObject, the object is sent in a single message, so this should only be
+// used for small objects such as backup metadata files. Plugins that don't support
+// conditional fetches are read using GetObject, and return an empty ETag.
+func (c *ObjectStoreGRPCClient) GetObjectIfChanged(bucket, key, etag string) (io.ReadCloser, string, error) {
+	res, err := c.grpcClient.GetObjectIfChanged(context.Background(), &proto.GetObjectIfChangedRequest{Bucket: bucket, Key: key, Etag: etag})
+	if grpc.Code(err) == codes.Unimplemented {
+		body, err := c.GetObject(bucket, key)
+		return body, "", err
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if res.NotModified {
+		return nil, "", cloudprovider.ErrObjectNotModified
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(res.Data)), res.Etag, nil
+}
+
 // ListCommonPrefixes gets a list of all object key prefixes that come
 // before the provided delimiter (this is often used to simulate a directory
 // hierarchy in object storage).
@@ -251,6 +277,33 @@ func (s *ObjectStoreGRPCServer) GetObject(req *proto.GetObjectRequest, stream pr
 	}
 }
 
+// GetObjectIfChanged retrieves the object with the given key from the specified
+// bucket in object storage along with its current ETag, unless etag matches the
+// object's current ETag. It returns an Unimplemented error if the plugin's
+// ObjectStore doesn't support conditional fetches.
+func (s *ObjectStoreGRPCServer) GetObjectIfChanged(ctx context.Context, req *proto.GetObjectIfChangedRequest) (*proto.GetObjectIfChangedResponse, error) {
+	conditional, ok := s.impl.(cloudprovider.ConditionalObjectStore)
+	if !ok {
+		return nil, grpc.Errorf(codes.Unimplemented, "object store does not support conditional fetches")
+	}
+
+	rdr, etag, err := conditional.GetObjectIfChanged(req.Bucket, req.Key, req.Etag)
+	if err == cloudprovider.ErrObjectNotModified {
+		return &proto.GetObjectIfChangedResponse{NotModified: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Close()
+
+	data, err := ioutil.ReadAll(rdr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.GetObjectIfChangedResponse{Data: data, Etag: etag}, nil
+}
+
 // ListCommonPrefixes gets a list of all object key prefixes that come
 // before the provided delimiter (this is often used to simulate a directory
 // hierarchy in object storage).
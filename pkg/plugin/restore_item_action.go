@@ -72,7 +72,7 @@ type RestoreItemActionGRPCClient struct {
 }
 
 func (c *RestoreItemActionGRPCClient) AppliesTo() (restore.ResourceSelector, error) {
-	res, err := c.grpcClient.AppliesTo(context.Background(), &proto.Empty{})
+	res, err := c.grpcClient.AppliesTo(withCallMetadata(context.Background(), c.log.correlationID, ""), &proto.Empty{})
 	if err != nil {
 		return restore.ResourceSelector{}, err
 	}
@@ -83,6 +83,7 @@ func (c *RestoreItemActionGRPCClient) AppliesTo() (restore.ResourceSelector, err
 		IncludedResources:  res.IncludedResources,
 		ExcludedResources:  res.ExcludedResources,
 		LabelSelector:      res.Selector,
+		FieldSelector:      res.FieldSelector,
 	}, nil
 }
 
@@ -102,7 +103,7 @@ func (c *RestoreItemActionGRPCClient) Execute(item runtime.Unstructured, restore
 		Restore: restoreJSON,
 	}
 
-	res, err := c.grpcClient.Execute(context.Background(), req)
+	res, err := c.grpcClient.Execute(withCallMetadata(context.Background(), c.log.correlationID, restore.Status.OperationID), req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -120,8 +121,10 @@ func (c *RestoreItemActionGRPCClient) Execute(item runtime.Unstructured, restore
 	return &updatedItem, warning, nil
 }
 
+// SetLog sets the log that the plugin's log entries for the restore
+// this client was created for are written to.
 func (c *RestoreItemActionGRPCClient) SetLog(log logrus.FieldLogger) {
-	c.log.impl = log
+	operationLogs.set(c.log.correlationID, log)
 }
 
 // RestoreItemActionGRPCServer implements the proto-generated RestoreItemActionServer interface, and accepts
@@ -131,6 +134,8 @@ type RestoreItemActionGRPCServer struct {
 }
 
 func (s *RestoreItemActionGRPCServer) AppliesTo(ctx context.Context, req *proto.Empty) (*proto.AppliesToResponse, error) {
+	defer pluginCorrelation.serve(ctx)()
+
 	appliesTo, err := s.impl.AppliesTo()
 	if err != nil {
 		return nil, err
@@ -142,10 +147,13 @@ func (s *RestoreItemActionGRPCServer) AppliesTo(ctx context.Context, req *proto.
 		IncludedResources:  appliesTo.IncludedResources,
 		ExcludedResources:  appliesTo.ExcludedResources,
 		Selector:           appliesTo.LabelSelector,
+		FieldSelector:      appliesTo.FieldSelector,
 	}, nil
 }
 
 func (s *RestoreItemActionGRPCServer) Execute(ctx context.Context, req *proto.RestoreExecuteRequest) (*proto.RestoreExecuteResponse, error) {
+	defer pluginCorrelation.serve(ctx)()
+
 	var (
 		item    unstructured.Unstructured
 		restore api.Restore
@@ -0,0 +1,179 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/discovery"
+	"github.com/heptio/ark/pkg/util/kube"
+)
+
+// ServedResourceFor returns the resource, and its preferred version, that items backed up
+// from groupResource are restored as: groupResource itself if the cluster serves it, or else
+// the first group of its entry in cohabitatingResources that the cluster serves. It returns
+// false if the cluster serves none of them.
+func ServedResourceFor(helper discovery.Helper, cohabitatingResources []api.CohabitatingResource, groupResource schema.GroupResource) (schema.GroupVersionResource, bool) {
+	if gvr, ok := servedResource(helper, groupResource); ok {
+		return gvr, true
+	}
+
+	for _, cohabitator := range cohabitatingResources {
+		if cohabitator.Resource != groupResource.Resource || !sets.NewString(cohabitator.Groups...).Has(groupResource.Group) {
+			continue
+		}
+
+		for _, group := range cohabitator.Groups {
+			if gvr, ok := servedResource(helper, schema.GroupResource{Group: group, Resource: groupResource.Resource}); ok {
+				return gvr, true
+			}
+		}
+	}
+
+	return schema.GroupVersionResource{}, false
+}
+
+// servedResource returns the preferred version of groupResource if the cluster serves it.
+func servedResource(helper discovery.Helper, groupResource schema.GroupResource) (schema.GroupVersionResource, bool) {
+	gvr, _, err := helper.ResourceFor(groupResource.WithVersion(""))
+	if err != nil || gvr.GroupResource() != groupResource {
+		return schema.GroupVersionResource{}, false
+	}
+	return gvr, true
+}
+
+// moveResourceDirs changes the resource that each cohabitating resource's directory in the
+// backup is restored as to the one the cluster serves, if the cluster doesn't serve the
+// backed-up one. Its items are converted to the served resource's API version as they're
+// restored.
+func (ctx *context) moveResourceDirs(resourceDirsMap map[string]os.FileInfo) {
+	for _, cohabitator := range ctx.cohabitatingResources {
+		for _, group := range cohabitator.Groups {
+			gr := schema.GroupResource{Group: group, Resource: cohabitator.Resource}
+
+			dir, found := resourceDirsMap[gr.String()]
+			if !found || !ctx.resourceIncludesExcludes.ShouldInclude(gr.String()) {
+				continue
+			}
+			if _, served := servedResource(ctx.discoveryHelper, gr); served {
+				continue
+			}
+
+			target, ok := ServedResourceFor(ctx.discoveryHelper, ctx.cohabitatingResources, gr)
+			if !ok {
+				continue
+			}
+			targetGR := target.GroupResource()
+			if _, found := resourceDirsMap[targetGR.String()]; found {
+				continue
+			}
+
+			ctx.infof("Restoring %s as %s, which the cluster serves instead", gr.String(), targetGR.String())
+			resourceDirsMap[targetGR.String()] = dir
+			delete(resourceDirsMap, gr.String())
+		}
+	}
+}
+
+// convertAPIVersion converts obj, an item of groupResource, to the API version the cluster
+// prefers for groupResource if the cluster doesn't serve the version it was backed up with.
+// Fields that are known to differ between the versions are converted, and obj is annotated
+// with its original API version so that restore item actions can convert it further.
+func (ctx *context) convertAPIVersion(obj *unstructured.Unstructured, groupResource schema.GroupResource) error {
+	if ctx.discoveryHelper == nil {
+		return nil
+	}
+
+	from := obj.GroupVersionKind().GroupVersion()
+	if ctx.discoveryHelper.Serves(from.WithResource(groupResource.Resource)) {
+		return nil
+	}
+
+	target, ok := servedResource(ctx.discoveryHelper, groupResource)
+	if !ok {
+		// there's nothing to convert to, so restoring the item will fail
+		return nil
+	}
+	to := target.GroupVersion()
+
+	for _, conversion := range apiVersionConversions {
+		if conversion.to == to && conversion.resources.Has(groupResource.Resource) {
+			if err := conversion.convert(obj); err != nil {
+				return errors.Wrapf(err, "error converting %s from %s to %s", kube.NamespaceAndName(obj), from, to)
+			}
+		}
+	}
+
+	obj.SetAPIVersion(to.String())
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[api.OriginalAPIVersionAnnotation] = from.String()
+	obj.SetAnnotations(annotations)
+
+	ctx.infof("Converted %s %s from %s to %s", groupResource.String(), kube.NamespaceAndName(obj), from, to)
+
+	return nil
+}
+
+// apiVersionConversions convert the fields of items that differ between the API version
+// they were backed up with and the one they're restored as.
+var apiVersionConversions = []struct {
+	resources sets.String
+	to        schema.GroupVersion
+	convert   func(obj *unstructured.Unstructured) error
+}{
+	{
+		resources: sets.NewString("deployments", "daemonsets", "replicasets"),
+		to:        schema.GroupVersion{Group: "apps", Version: "v1"},
+		convert:   convertToAppsV1,
+	},
+}
+
+// convertToAppsV1 converts a workload from extensions/v1beta1, apps/v1beta1, or
+// apps/v1beta2 to apps/v1, which requires a selector and has no rollbackTo or
+// templateGeneration.
+func convertToAppsV1(obj *unstructured.Unstructured) error {
+	content := obj.UnstructuredContent()
+
+	if _, found, err := unstructured.NestedMap(content, "spec", "selector"); err != nil {
+		return errors.WithStack(err)
+	} else if !found {
+		// earlier versions defaulted the selector to the pod template's labels
+		labels, _, err := unstructured.NestedMap(content, "spec", "template", "metadata", "labels")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if err := unstructured.SetNestedField(content, map[string]interface{}{"matchLabels": labels}, "spec", "selector"); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	unstructured.RemoveNestedField(content, "spec", "rollbackTo")
+	unstructured.RemoveNestedField(content, "spec", "templateGeneration")
+
+	return nil
+}
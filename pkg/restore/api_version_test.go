@@ -0,0 +1,167 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/kuberesource"
+	"github.com/heptio/ark/pkg/util/collections"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+var (
+	appsDeployments     = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	appsDaemonSets      = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}
+	networkingIngresses = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1beta1", Resource: "ingresses"}
+)
+
+// newMovedAPIsDiscoveryHelper returns a discovery helper for a cluster that only serves the
+// apps and networking.k8s.io copies of resources that were moved out of extensions.
+func newMovedAPIsDiscoveryHelper() *arktest.FakeDiscoveryHelper {
+	resources := make(map[schema.GroupVersionResource]schema.GroupVersionResource)
+	for _, gvr := range []schema.GroupVersionResource{appsDeployments, appsDaemonSets, networkingIngresses} {
+		resources[gvr.GroupResource().WithVersion("")] = gvr
+	}
+	resources[schema.GroupVersionResource{Resource: "configmaps"}] = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+	return arktest.NewFakeDiscoveryHelper(false, resources)
+}
+
+func TestServedResourceFor(t *testing.T) {
+	tests := []struct {
+		name          string
+		groupResource schema.GroupResource
+		expected      schema.GroupVersionResource
+		expectedOK    bool
+	}{
+		{
+			name:          "served resource resolves to itself",
+			groupResource: schema.GroupResource{Group: "apps", Resource: "deployments"},
+			expected:      appsDeployments,
+			expectedOK:    true,
+		},
+		{
+			name:          "moved resource resolves to its served group",
+			groupResource: schema.GroupResource{Group: "extensions", Resource: "deployments"},
+			expected:      appsDeployments,
+			expectedOK:    true,
+		},
+		{
+			name:          "moved resource resolves to the group served for its cohabitating entry",
+			groupResource: schema.GroupResource{Group: "extensions", Resource: "ingresses"},
+			expected:      networkingIngresses,
+			expectedOK:    true,
+		},
+		{
+			name:          "unserved resource that doesn't cohabitate doesn't resolve",
+			groupResource: schema.GroupResource{Group: "example.com", Resource: "widgets"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gvr, ok := ServedResourceFor(newMovedAPIsDiscoveryHelper(), kuberesource.CohabitatingResources(nil), test.groupResource)
+
+			assert.Equal(t, test.expectedOK, ok)
+			assert.Equal(t, test.expected, gvr)
+		})
+	}
+}
+
+func TestConvertAPIVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		groupResource schema.GroupResource
+		obj           string
+		expected      string
+	}{
+		{
+			name:          "item of a served version is unchanged",
+			groupResource: schema.GroupResource{Resource: "configmaps"},
+			obj:           `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns-1","name":"cm-1"}}`,
+			expected:      `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns-1","name":"cm-1"}}`,
+		},
+		{
+			name:          "extensions deployment is converted to apps/v1",
+			groupResource: schema.GroupResource{Group: "apps", Resource: "deployments"},
+			obj:           `{"apiVersion":"extensions/v1beta1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"deploy-1"},"spec":{"rollbackTo":{"revision":1},"template":{"metadata":{"labels":{"app":"foo"}}}}}`,
+			expected:      `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"deploy-1","annotations":{"ark.heptio.com/original-api-version":"extensions/v1beta1"}},"spec":{"selector":{"matchLabels":{"app":"foo"}},"template":{"metadata":{"labels":{"app":"foo"}}}}}`,
+		},
+		{
+			name:          "extensions daemonset keeps its selector when converted to apps/v1",
+			groupResource: schema.GroupResource{Group: "apps", Resource: "daemonsets"},
+			obj:           `{"apiVersion":"extensions/v1beta1","kind":"DaemonSet","metadata":{"namespace":"ns-1","name":"ds-1","annotations":{"a":"b"}},"spec":{"selector":{"matchLabels":{"app":"bar"}},"templateGeneration":2,"template":{"metadata":{"labels":{"app":"bar","tier":"node"}}}}}`,
+			expected:      `{"apiVersion":"apps/v1","kind":"DaemonSet","metadata":{"namespace":"ns-1","name":"ds-1","annotations":{"a":"b","ark.heptio.com/original-api-version":"extensions/v1beta1"}},"spec":{"selector":{"matchLabels":{"app":"bar"}},"template":{"metadata":{"labels":{"app":"bar","tier":"node"}}}}}`,
+		},
+		{
+			name:          "extensions ingress only has its API version changed",
+			groupResource: schema.GroupResource{Group: "networking.k8s.io", Resource: "ingresses"},
+			obj:           `{"apiVersion":"extensions/v1beta1","kind":"Ingress","metadata":{"namespace":"ns-1","name":"ing-1"},"spec":{"backend":{"serviceName":"svc-1","servicePort":80}}}`,
+			expected:      `{"apiVersion":"networking.k8s.io/v1beta1","kind":"Ingress","metadata":{"namespace":"ns-1","name":"ing-1","annotations":{"ark.heptio.com/original-api-version":"extensions/v1beta1"}},"spec":{"backend":{"serviceName":"svc-1","servicePort":80}}}`,
+		},
+		{
+			name:          "item of a resource the cluster doesn't serve is unchanged",
+			groupResource: schema.GroupResource{Group: "example.com", Resource: "widgets"},
+			obj:           `{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"widget-1"}}`,
+			expected:      `{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"name":"widget-1"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := &context{
+				logger:          arktest.NewLogger(),
+				discoveryHelper: newMovedAPIsDiscoveryHelper(),
+			}
+
+			obj := unstructuredOrDie(test.obj)
+			require.NoError(t, ctx.convertAPIVersion(obj, test.groupResource))
+
+			assert.Equal(t, unstructuredOrDie(test.expected), obj)
+		})
+	}
+}
+
+func TestRestoreRestoresMovedResourceAsServedGroup(t *testing.T) {
+	fileSystem := newFakeFileSystem().WithDirectory("bak/resources/deployments.extensions/cluster")
+
+	ctx := &context{
+		auditLog:                 &arktest.FakeAuditLog{},
+		restore:                  &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
+		namespaceClient:          &fakeNamespaceClient{},
+		fileSystem:               fileSystem,
+		logger:                   arktest.NewLogger(),
+		prioritizedResources:     []schema.GroupResource{appsDeployments.GroupResource()},
+		resourceIncludesExcludes: collections.NewIncludesExcludes(),
+		discoveryHelper:          newMovedAPIsDiscoveryHelper(),
+		cohabitatingResources:    kuberesource.CohabitatingResources(nil),
+	}
+
+	warnings, errs := ctx.restoreFromDir("bak")
+
+	assert.Empty(t, warnings.Ark)
+	assert.Empty(t, errs.Ark)
+	// the extensions directory is read as a prioritized apps resource
+	assert.Equal(t, []string{"bak/resources", "bak/resources/deployments.extensions/cluster"}, fileSystem.readDirCalls)
+}
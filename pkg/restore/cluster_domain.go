@@ -0,0 +1,142 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"bytes"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/heptio/ark/pkg/kuberesource"
+	"github.com/heptio/ark/pkg/util/kube"
+)
+
+// clusterDomainFields are the fields of built-in resources whose references to the cluster
+// domain are rewritten by a restore's cluster domain mapping.
+var clusterDomainFields = map[schema.GroupResource][]string{
+	kuberesource.ConfigMaps:                             {"data"},
+	kuberesource.Services:                               {"spec.externalName"},
+	{Group: "extensions", Resource: "ingresses"}:        {"spec.rules", "spec.tls"},
+	{Group: "networking.k8s.io", Resource: "ingresses"}: {"spec.rules", "spec.tls"},
+}
+
+// rewriteClusterDomain rewrites the references to the cluster domain in the fields of obj, an
+// item of groupResource, according to the restore's cluster domain mapping. Every string
+// within a field, including within its nested lists and maps, is rewritten.
+func (ctx *context) rewriteClusterDomain(obj *unstructured.Unstructured, groupResource schema.GroupResource) error {
+	mapping := ctx.restore.Spec.ClusterDomainMapping
+	if mapping == nil {
+		return nil
+	}
+
+	var paths []string
+	paths = append(paths, clusterDomainFields[groupResource]...)
+	paths = append(paths, mapping.ResourceFields[groupResource.String()]...)
+
+	for _, path := range paths {
+		fields := strings.Split(path, ".")
+
+		value, found, err := unstructured.NestedFieldCopy(obj.UnstructuredContent(), fields...)
+		if err != nil || !found {
+			continue
+		}
+
+		rewritten, changed := rewriteClusterDomainValue(value, mapping.From, mapping.To)
+		if !changed {
+			continue
+		}
+
+		ctx.infof("Rewriting cluster domain %s to %s in %s of %s %s", mapping.From, mapping.To, path, groupResource.String(), kube.NamespaceAndName(obj))
+		if err := unstructured.SetNestedField(obj.UnstructuredContent(), rewritten, fields...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rewriteClusterDomainValue returns value, which may be a string or a list or map containing
+// strings, with the references to the from domain in its strings rewritten to the to domain,
+// and whether any were rewritten.
+func rewriteClusterDomainValue(value interface{}, from, to string) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		rewritten := rewriteClusterDomainString(v, from, to)
+		return rewritten, rewritten != v
+	case []interface{}:
+		changed := false
+		for i := range v {
+			var c bool
+			v[i], c = rewriteClusterDomainValue(v[i], from, to)
+			changed = changed || c
+		}
+		return v, changed
+	case map[string]interface{}:
+		changed := false
+		for key := range v {
+			var c bool
+			v[key], c = rewriteClusterDomainValue(v[key], from, to)
+			changed = changed || c
+		}
+		return v, changed
+	default:
+		return value, false
+	}
+}
+
+// rewriteClusterDomainString returns s with each reference to the from domain rewritten to the
+// to domain. A reference is an occurrence of from that isn't part of a longer name: it's
+// neither preceded by a hostname character, as in "mycluster.local", nor followed by one or by
+// a further label, as in "cluster.local.example.com". A trailing "." is allowed.
+func rewriteClusterDomainString(s, from, to string) string {
+	if from == "" {
+		return s
+	}
+
+	var buf bytes.Buffer
+	for {
+		i := strings.Index(s, from)
+		if i < 0 {
+			break
+		}
+		end := i + len(from)
+
+		if (i == 0 || !isHostnameChar(s[i-1])) && endsDomain(s[end:]) {
+			buf.WriteString(s[:i])
+			buf.WriteString(to)
+		} else {
+			buf.WriteString(s[:end])
+		}
+		s = s[end:]
+	}
+	buf.WriteString(s)
+
+	return buf.String()
+}
+
+// endsDomain returns whether rest, the remainder of a string after a domain name, doesn't
+// continue the name.
+func endsDomain(rest string) bool {
+	rest = strings.TrimPrefix(rest, ".")
+	return rest == "" || (!isHostnameChar(rest[0]) && rest[0] != '.')
+}
+
+func isHostnameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-'
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/kuberesource"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestRewriteClusterDomainString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"my-svc.my-ns.svc.cluster.local", "my-svc.my-ns.svc.east.local"},
+		{"my-svc.my-ns.svc.cluster.local.", "my-svc.my-ns.svc.east.local."},
+		{"http://my-svc.my-ns.svc.cluster.local:8080/path", "http://my-svc.my-ns.svc.east.local:8080/path"},
+		{"a.svc.cluster.local,b.svc.cluster.local", "a.svc.east.local,b.svc.east.local"},
+		{"clusterDomain: cluster.local", "clusterDomain: east.local"},
+		{"cluster.local", "east.local"},
+		{"mycluster.local", "mycluster.local"},
+		{"my-svc.cluster.localhost", "my-svc.cluster.localhost"},
+		{"my-svc.cluster.local.example.com", "my-svc.cluster.local.example.com"},
+		{"no references", "no references"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, rewriteClusterDomainString(test.input, "cluster.local", "east.local"))
+		})
+	}
+}
+
+func TestRewriteClusterDomain(t *testing.T) {
+	tests := []struct {
+		name          string
+		groupResource schema.GroupResource
+		mapping       *api.ClusterDomainMapping
+		obj           string
+		expected      string
+	}{
+		{
+			name:          "nothing is rewritten without a mapping",
+			groupResource: kuberesource.ConfigMaps,
+			obj:           `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns-1","name":"cm-1"},"data":{"url":"db.ns-1.svc.cluster.local"}}`,
+			expected:      `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns-1","name":"cm-1"},"data":{"url":"db.ns-1.svc.cluster.local"}}`,
+		},
+		{
+			name:          "configmap data is rewritten",
+			groupResource: kuberesource.ConfigMaps,
+			mapping:       &api.ClusterDomainMapping{From: "cluster.local", To: "east.local"},
+			obj:           `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns-1","name":"cm-1","annotations":{"a":"db.svc.cluster.local"}},"data":{"url":"db.ns-1.svc.cluster.local","other":"value"}}`,
+			expected:      `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns-1","name":"cm-1","annotations":{"a":"db.svc.cluster.local"}},"data":{"url":"db.ns-1.svc.east.local","other":"value"}}`,
+		},
+		{
+			name:          "ingress hosts are rewritten",
+			groupResource: schema.GroupResource{Group: "extensions", Resource: "ingresses"},
+			mapping:       &api.ClusterDomainMapping{From: "cluster.local", To: "east.local"},
+			obj:           `{"apiVersion":"extensions/v1beta1","kind":"Ingress","metadata":{"namespace":"ns-1","name":"ing-1"},"spec":{"rules":[{"host":"app.cluster.local","http":{"paths":[{"path":"/","backend":{"serviceName":"app","servicePort":80}}]}}],"tls":[{"hosts":["app.cluster.local"],"secretName":"tls"}]}}`,
+			expected:      `{"apiVersion":"extensions/v1beta1","kind":"Ingress","metadata":{"namespace":"ns-1","name":"ing-1"},"spec":{"rules":[{"host":"app.east.local","http":{"paths":[{"path":"/","backend":{"serviceName":"app","servicePort":80}}]}}],"tls":[{"hosts":["app.east.local"],"secretName":"tls"}]}}`,
+		},
+		{
+			name:          "externalName service is rewritten",
+			groupResource: kuberesource.Services,
+			mapping:       &api.ClusterDomainMapping{From: "cluster.local", To: "east.local"},
+			obj:           `{"apiVersion":"v1","kind":"Service","metadata":{"namespace":"ns-1","name":"svc-1"},"spec":{"type":"ExternalName","externalName":"db.ns-2.svc.cluster.local"}}`,
+			expected:      `{"apiVersion":"v1","kind":"Service","metadata":{"namespace":"ns-1","name":"svc-1"},"spec":{"type":"ExternalName","externalName":"db.ns-2.svc.east.local"}}`,
+		},
+		{
+			name:          "configured custom resource fields are rewritten",
+			groupResource: schema.GroupResource{Group: "certmanager.k8s.io", Resource: "certificates"},
+			mapping: &api.ClusterDomainMapping{
+				From:           "cluster.local",
+				To:             "east.local",
+				ResourceFields: map[string][]string{"certificates.certmanager.k8s.io": {"spec.dnsNames", "spec.missing"}},
+			},
+			obj:      `{"apiVersion":"certmanager.k8s.io/v1alpha1","kind":"Certificate","metadata":{"namespace":"ns-1","name":"cert-1"},"spec":{"commonName":"app.cluster.local","dnsNames":["app.cluster.local","app.example.com"]}}`,
+			expected: `{"apiVersion":"certmanager.k8s.io/v1alpha1","kind":"Certificate","metadata":{"namespace":"ns-1","name":"cert-1"},"spec":{"commonName":"app.cluster.local","dnsNames":["app.east.local","app.example.com"]}}`,
+		},
+		{
+			name:          "unconfigured resources are not rewritten",
+			groupResource: schema.GroupResource{Group: "example.com", Resource: "widgets"},
+			mapping:       &api.ClusterDomainMapping{From: "cluster.local", To: "east.local"},
+			obj:           `{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"namespace":"ns-1","name":"widget-1"},"spec":{"host":"app.cluster.local"}}`,
+			expected:      `{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"namespace":"ns-1","name":"widget-1"},"spec":{"host":"app.cluster.local"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := &context{
+				restore: &api.Restore{Spec: api.RestoreSpec{ClusterDomainMapping: test.mapping}},
+				logger:  arktest.NewLogger(),
+			}
+
+			obj := unstructuredOrDie(test.obj)
+			require.NoError(t, ctx.rewriteClusterDomain(obj, test.groupResource))
+
+			assert.Equal(t, unstructuredOrDie(test.expected), obj)
+		})
+	}
+}
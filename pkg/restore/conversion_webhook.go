@@ -0,0 +1,171 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/kuberesource"
+)
+
+const (
+	// defaultConversionWebhookTimeout is how long the restorer waits for a conversion webhook's
+	// service to have ready endpoints before attempting to restore the custom resources that
+	// depend on it.
+	defaultConversionWebhookTimeout = 2 * time.Minute
+
+	conversionWebhookPollInterval = time.Second
+)
+
+// conversionWebhookService identifies the service backing a CRD's conversion webhook.
+type conversionWebhookService struct {
+	namespace string
+	name      string
+}
+
+func (s conversionWebhookService) String() string {
+	return fmt.Sprintf("%s/%s", s.namespace, s.name)
+}
+
+// getConversionWebhooks reads the CustomResourceDefinitions contained in the backup and returns
+// a map of the custom resources whose CRDs use a service-backed conversion webhook, keyed by the
+// custom resource's GroupResource.
+func (ctx *context) getConversionWebhooks(resourcesDir string) map[schema.GroupResource]conversionWebhookService {
+	webhooks := make(map[schema.GroupResource]conversionWebhookService)
+
+	crdDir := filepath.Join(resourcesDir, kuberesource.CustomResourceDefinitions.String(), api.ClusterScopedDir)
+	exists, err := ctx.fileSystem.DirExists(crdDir)
+	if err != nil || !exists {
+		return webhooks
+	}
+
+	files, err := ctx.fileSystem.ReadDir(crdDir)
+	if err != nil {
+		ctx.logger.WithError(err).Warn("Unable to read CustomResourceDefinitions from backup; not checking for conversion webhooks")
+		return webhooks
+	}
+
+	for _, file := range files {
+		crd, err := ctx.unmarshal(filepath.Join(crdDir, file.Name()))
+		if err != nil {
+			ctx.logger.WithError(err).WithField("file", file.Name()).Warn("Unable to decode CustomResourceDefinition")
+			continue
+		}
+
+		gr, svc, found := conversionWebhookFor(crd)
+		if !found {
+			continue
+		}
+
+		ctx.infof("Custom resource %s uses conversion webhook service %s; its items will be restored after the webhook is available", gr, svc)
+		webhooks[gr] = svc
+	}
+
+	return webhooks
+}
+
+// conversionWebhookFor returns the GroupResource served by the CRD and the service backing its
+// conversion webhook, if the CRD uses the Webhook conversion strategy.
+func conversionWebhookFor(crd *unstructured.Unstructured) (schema.GroupResource, conversionWebhookService, bool) {
+	obj := crd.UnstructuredContent()
+
+	strategy, _, _ := unstructured.NestedString(obj, "spec", "conversion", "strategy")
+	if strategy != "Webhook" {
+		return schema.GroupResource{}, conversionWebhookService{}, false
+	}
+
+	svcNamespace, _, _ := unstructured.NestedString(obj, "spec", "conversion", "webhookClientConfig", "service", "namespace")
+	svcName, _, _ := unstructured.NestedString(obj, "spec", "conversion", "webhookClientConfig", "service", "name")
+	if svcNamespace == "" || svcName == "" {
+		// URL-based webhooks live outside the cluster, so there's nothing for us to wait for.
+		return schema.GroupResource{}, conversionWebhookService{}, false
+	}
+
+	group, _, _ := unstructured.NestedString(obj, "spec", "group")
+	plural, _, _ := unstructured.NestedString(obj, "spec", "names", "plural")
+	if plural == "" {
+		return schema.GroupResource{}, conversionWebhookService{}, false
+	}
+
+	return schema.GroupResource{Group: group, Resource: plural}, conversionWebhookService{namespace: svcNamespace, name: svcName}, true
+}
+
+// waitForConversionWebhook waits until the webhook's service has at least one ready endpoint,
+// taking the restore's namespace mapping into account. It returns an error if the timeout is
+// reached first.
+func (ctx *context) waitForConversionWebhook(svc conversionWebhookService) error {
+	namespace := svc.namespace
+	if target, ok := ctx.restore.Spec.NamespaceMapping[namespace]; ok {
+		namespace = target
+	}
+
+	endpointsClient, err := ctx.dynamicFactory.ClientForGroupVersionResource(
+		schema.GroupVersion{Version: "v1"},
+		metav1.APIResource{Name: kuberesource.Endpoints.Resource, Namespaced: true},
+		namespace,
+	)
+	if err != nil {
+		return err
+	}
+
+	timeout := ctx.conversionWebhookTimeout
+	if timeout == 0 {
+		timeout = defaultConversionWebhookTimeout
+	}
+
+	err = wait.PollImmediate(conversionWebhookPollInterval, timeout, func() (bool, error) {
+		endpoints, err := endpointsClient.Get(svc.name, metav1.GetOptions{})
+		if err != nil {
+			ctx.logger.WithError(err).Debugf("Unable to get endpoints for conversion webhook service %s/%s", namespace, svc.name)
+			return false, nil
+		}
+
+		return hasReadyAddresses(endpoints), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.Errorf("timed out after %v waiting for conversion webhook service %s/%s to have ready endpoints", timeout, namespace, svc.name)
+	}
+
+	return err
+}
+
+// hasReadyAddresses returns true if the Endpoints object has at least one ready address.
+func hasReadyAddresses(endpoints *unstructured.Unstructured) bool {
+	subsets, _, _ := unstructured.NestedSlice(endpoints.UnstructuredContent(), "subsets")
+	for _, s := range subsets {
+		subset, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if addresses, ok := subset["addresses"].([]interface{}); ok && len(addresses) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
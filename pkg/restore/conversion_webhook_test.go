@@ -0,0 +1,152 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/util/collections"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+const webhookCRDJSON = `{
+	"apiVersion": "apiextensions.k8s.io/v1beta1",
+	"kind": "CustomResourceDefinition",
+	"metadata": {"name": "foos.example.com"},
+	"spec": {
+		"group": "example.com",
+		"names": {"plural": "foos", "kind": "Foo"},
+		"conversion": {
+			"strategy": "Webhook",
+			"webhookClientConfig": {"service": {"namespace": "foo-system", "name": "foo-webhook"}}
+		}
+	}
+}`
+
+func TestConversionWebhookFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		crd         string
+		expectFound bool
+		expectedGR  schema.GroupResource
+		expectedSvc conversionWebhookService
+	}{
+		{
+			name:        "CRD without conversion returns not found",
+			crd:         `{"apiVersion": "apiextensions.k8s.io/v1beta1", "kind": "CustomResourceDefinition", "spec": {"group": "example.com", "names": {"plural": "foos"}}}`,
+			expectFound: false,
+		},
+		{
+			name:        "CRD with None conversion strategy returns not found",
+			crd:         `{"apiVersion": "apiextensions.k8s.io/v1beta1", "kind": "CustomResourceDefinition", "spec": {"group": "example.com", "names": {"plural": "foos"}, "conversion": {"strategy": "None"}}}`,
+			expectFound: false,
+		},
+		{
+			name:        "CRD with URL-based webhook returns not found",
+			crd:         `{"apiVersion": "apiextensions.k8s.io/v1beta1", "kind": "CustomResourceDefinition", "spec": {"group": "example.com", "names": {"plural": "foos"}, "conversion": {"strategy": "Webhook", "webhookClientConfig": {"url": "https://foo"}}}}`,
+			expectFound: false,
+		},
+		{
+			name:        "CRD with service-based webhook returns group resource and service",
+			crd:         webhookCRDJSON,
+			expectFound: true,
+			expectedGR:  schema.GroupResource{Group: "example.com", Resource: "foos"},
+			expectedSvc: conversionWebhookService{namespace: "foo-system", name: "foo-webhook"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gr, svc, found := conversionWebhookFor(unstructuredOrDie(test.crd))
+
+			assert.Equal(t, test.expectFound, found)
+			assert.Equal(t, test.expectedGR, gr)
+			assert.Equal(t, test.expectedSvc, svc)
+		})
+	}
+}
+
+func TestRestoreDefersConversionWebhookResources(t *testing.T) {
+	fileSystem := newFakeFileSystem().
+		WithFile("bak/resources/customresourcedefinitions.apiextensions.k8s.io/cluster/foos.example.com.json", []byte(webhookCRDJSON)).
+		WithDirectory("bak/resources/foos.example.com/cluster").
+		WithDirectory("bak/resources/a/cluster")
+
+	endpointsClient := &arktest.FakeDynamicClient{}
+	endpointsClient.On("Get", "foo-webhook", metav1.GetOptions{}).Return(unstructuredOrDie(`{"apiVersion": "v1", "kind": "Endpoints", "subsets": [{"addresses": [{"ip": "10.0.0.1"}]}]}`), nil)
+
+	dynamicFactory := &arktest.FakeDynamicFactory{}
+	dynamicFactory.On("ClientForGroupVersionResource", schema.GroupVersion{Version: "v1"}, metav1.APIResource{Name: "endpoints", Namespaced: true}, "foo-system").Return(endpointsClient, nil)
+
+	ctx := &context{
+		restore:         &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
+		namespaceClient: &fakeNamespaceClient{},
+		fileSystem:      fileSystem,
+		dynamicFactory:  dynamicFactory,
+		logger:          arktest.NewLogger(),
+		prioritizedResources: []schema.GroupResource{
+			{Group: "example.com", Resource: "foos"},
+			{Resource: "a"},
+		},
+		resourceIncludesExcludes: collections.NewIncludesExcludes().Excludes("customresourcedefinitions.apiextensions.k8s.io"),
+	}
+
+	warnings, errs := ctx.restoreFromDir("bak")
+
+	assert.Empty(t, warnings.Ark)
+	assert.Empty(t, errs.Ark)
+	assert.Equal(t, []string{
+		"bak/resources",
+		"bak/resources/customresourcedefinitions.apiextensions.k8s.io/cluster",
+		"bak/resources/a/cluster",
+		"bak/resources/foos.example.com/cluster",
+	}, fileSystem.readDirCalls)
+	endpointsClient.AssertExpectations(t)
+}
+
+func TestWaitForConversionWebhookTimeout(t *testing.T) {
+	endpointsClient := &arktest.FakeDynamicClient{}
+	endpointsClient.On("Get", "foo-webhook", metav1.GetOptions{}).Return(&unstructured.Unstructured{}, errors.New("not found"))
+
+	dynamicFactory := &arktest.FakeDynamicFactory{}
+	dynamicFactory.On("ClientForGroupVersionResource", schema.GroupVersion{Version: "v1"}, metav1.APIResource{Name: "endpoints", Namespaced: true}, "mapped").Return(endpointsClient, nil)
+
+	ctx := &context{
+		restore:                  &api.Restore{Spec: api.RestoreSpec{NamespaceMapping: map[string]string{"foo-system": "mapped"}}},
+		dynamicFactory:           dynamicFactory,
+		logger:                   arktest.NewLogger(),
+		conversionWebhookTimeout: time.Millisecond,
+	}
+
+	err := ctx.waitForConversionWebhook(conversionWebhookService{namespace: "foo-system", name: "foo-webhook"})
+	assert.Error(t, err)
+}
+
+func TestHasReadyAddresses(t *testing.T) {
+	assert.False(t, hasReadyAddresses(unstructuredOrDie(`{"apiVersion": "v1", "kind": "Endpoints"}`)))
+	assert.False(t, hasReadyAddresses(unstructuredOrDie(`{"apiVersion": "v1", "kind": "Endpoints", "subsets": [{"notReadyAddresses": [{"ip": "10.0.0.1"}]}]}`)))
+	assert.True(t, hasReadyAddresses(unstructuredOrDie(`{"apiVersion": "v1", "kind": "Endpoints", "subsets": [{"addresses": [{"ip": "10.0.0.1"}]}]}`)))
+}
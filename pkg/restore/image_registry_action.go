@@ -0,0 +1,123 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+// podSpecPaths are the paths to the pod spec of each kind of workload whose container images are
+// rewritten.
+var podSpecPaths = map[string]string{
+	"Pod":         "spec",
+	"Deployment":  "spec.template.spec",
+	"ReplicaSet":  "spec.template.spec",
+	"StatefulSet": "spec.template.spec",
+	"DaemonSet":   "spec.template.spec",
+	"Job":         "spec.template.spec",
+	"CronJob":     "spec.jobTemplate.spec.template.spec",
+}
+
+type imageRegistryAction struct {
+	logger logrus.FieldLogger
+}
+
+// NewImageRegistryAction creates a new ItemAction that rewrites container image references
+// according to the restore's image registry mapping. Image pull secrets are restored unchanged,
+// so that credentials for one registry are never sent to another.
+func NewImageRegistryAction(logger logrus.FieldLogger) ItemAction {
+	return &imageRegistryAction{
+		logger: logger,
+	}
+}
+
+func (a *imageRegistryAction) AppliesTo() (ResourceSelector, error) {
+	return ResourceSelector{
+		IncludedResources: []string{"pods", "deployments", "replicasets", "statefulsets", "daemonsets", "jobs", "cronjobs"},
+	}, nil
+}
+
+func (a *imageRegistryAction) Execute(obj runtime.Unstructured, restore *api.Restore) (runtime.Unstructured, error, error) {
+	if len(restore.Spec.ImageRegistryMapping) == 0 {
+		return obj, nil, nil
+	}
+
+	kind, _ := obj.UnstructuredContent()["kind"].(string)
+
+	path, ok := podSpecPaths[kind]
+	if !ok {
+		return obj, nil, nil
+	}
+
+	podSpec, err := collections.GetMap(obj.UnstructuredContent(), path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, ok := podSpec[field].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, obj := range containers {
+			container, ok := obj.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			image, ok := container["image"].(string)
+			if !ok {
+				continue
+			}
+
+			if remapped := remapImageRegistry(image, restore.Spec.ImageRegistryMapping); remapped != image {
+				a.logger.Infof("Rewriting image %s to %s", image, remapped)
+				container["image"] = remapped
+			}
+		}
+	}
+
+	return obj, nil, nil
+}
+
+// remapImageRegistry returns image with its registry prefix rewritten according to mapping. A
+// prefix matches if it is the whole image reference or is followed by a "/"; if several prefixes
+// match, the longest is used.
+func remapImageRegistry(image string, mapping map[string]string) string {
+	var from string
+	for prefix := range mapping {
+		if image != prefix && !strings.HasPrefix(image, prefix+"/") {
+			continue
+		}
+		if len(prefix) > len(from) {
+			from = prefix
+		}
+	}
+
+	if from == "" {
+		return image
+	}
+
+	return mapping[from] + strings.TrimPrefix(image, from)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestRemapImageRegistry(t *testing.T) {
+	mapping := map[string]string{
+		"quay.io":             "registry.local:5000",
+		"gcr.io/project":      "registry.local:5000/project",
+		"gcr.io/project/team": "registry.local:5000/team",
+	}
+
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{image: "quay.io/coreos/etcd:v3.2", expected: "registry.local:5000/coreos/etcd:v3.2"},
+		{image: "gcr.io/project/app@sha256:abc", expected: "registry.local:5000/project/app@sha256:abc"},
+		{image: "gcr.io/project/team/app", expected: "registry.local:5000/team/app"},
+		{image: "gcr.io/projectx/app", expected: "gcr.io/projectx/app"},
+		{image: "quay.io.evil.com/app", expected: "quay.io.evil.com/app"},
+		{image: "nginx", expected: "nginx"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.image, func(t *testing.T) {
+			assert.Equal(t, test.expected, remapImageRegistry(test.image, mapping))
+		})
+	}
+}
+
+func TestImageRegistryActionExecute(t *testing.T) {
+	tests := []struct {
+		name     string
+		obj      string
+		expected string
+	}{
+		{
+			name:     "pod containers and init containers are rewritten",
+			obj:      `{"kind": "Pod", "spec": {"initContainers": [{"image": "quay.io/init"}], "containers": [{"image": "quay.io/app"}, {"image": "nginx"}]}}`,
+			expected: `{"kind": "Pod", "spec": {"initContainers": [{"image": "mirror/init"}], "containers": [{"image": "mirror/app"}, {"image": "nginx"}]}}`,
+		},
+		{
+			name:     "deployment template is rewritten",
+			obj:      `{"kind": "Deployment", "spec": {"template": {"spec": {"containers": [{"image": "quay.io/app"}]}}}}`,
+			expected: `{"kind": "Deployment", "spec": {"template": {"spec": {"containers": [{"image": "mirror/app"}]}}}}`,
+		},
+		{
+			name:     "cronjob job template is rewritten",
+			obj:      `{"kind": "CronJob", "spec": {"jobTemplate": {"spec": {"template": {"spec": {"containers": [{"image": "quay.io/app"}]}}}}}}`,
+			expected: `{"kind": "CronJob", "spec": {"jobTemplate": {"spec": {"template": {"spec": {"containers": [{"image": "mirror/app"}]}}}}}}`,
+		},
+		{
+			name:     "other kinds are unchanged",
+			obj:      `{"kind": "ConfigMap", "data": {"image": "quay.io/app"}}`,
+			expected: `{"kind": "ConfigMap", "data": {"image": "quay.io/app"}}`,
+		},
+	}
+
+	restore := &api.Restore{Spec: api.RestoreSpec{ImageRegistryMapping: map[string]string{"quay.io": "mirror"}}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			action := NewImageRegistryAction(arktest.NewLogger())
+
+			res, warning, err := action.Execute(unstructuredOrDie(test.obj), restore)
+			require.NoError(t, err)
+			assert.NoError(t, warning)
+			assert.Equal(t, unstructuredOrDie(test.expected), res)
+		})
+	}
+}
+
+func TestImageRegistryActionLeavesPullSecretsUnchanged(t *testing.T) {
+	action := NewImageRegistryAction(arktest.NewLogger())
+
+	selector, err := action.AppliesTo()
+	require.NoError(t, err)
+	assert.NotContains(t, selector.IncludedResources, "secrets")
+}
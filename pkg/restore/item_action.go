@@ -38,8 +38,8 @@ type ItemAction interface {
 }
 
 // ResourceSelector is a collection of included/excluded namespaces,
-// included/excluded resources, and a label-selector that can be used
-// to match a set of items from a cluster.
+// included/excluded resources, a label-selector, and a field-selector
+// that can be used to match a set of items from a cluster.
 type ResourceSelector struct {
 	// IncludedNamespaces is a slice of namespace names to match. All
 	// namespaces in this slice, except those in ExcludedNamespaces,
@@ -64,4 +64,11 @@ type ResourceSelector struct {
 	// when matching resources. See "k8s.io/apimachinery/pkg/labels".Parse()
 	// for details on syntax.
 	LabelSelector string
+	// FieldSelector is a string representation of a selector to apply
+	// to the fields of matching resources, e.g. "spec.type=LoadBalancer".
+	// Each field is a dot separated path into the item; a path that
+	// doesn't exist, or whose value isn't a string, number, or bool,
+	// has the value "". See "k8s.io/apimachinery/pkg/fields".ParseSelector()
+	// for details on syntax.
+	FieldSelector string
 }
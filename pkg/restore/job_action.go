@@ -45,6 +45,7 @@ func (a *jobAction) Execute(obj runtime.Unstructured, restore *api.Restore) (run
 	fieldDeletions := map[string]string{
 		"spec.selector.matchLabels":     "controller-uid",
 		"spec.template.metadata.labels": "controller-uid",
+		"metadata.labels":               "controller-uid",
 	}
 
 	for k, v := range fieldDeletions {
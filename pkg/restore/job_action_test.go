@@ -119,6 +119,21 @@ func TestJobActionExecute(t *testing.T) {
 				}).
 				Unstructured,
 		},
+		{
+			name: "metadata.labels[controller-uid] is removed",
+			obj: NewTestUnstructured().WithName("job-1").
+				WithMetadataField("labels", map[string]interface{}{
+					"controller-uid": "foo",
+					"hello":          "world",
+				}).
+				Unstructured,
+			expectedErr: false,
+			expectedRes: NewTestUnstructured().WithName("job-1").
+				WithMetadataField("labels", map[string]interface{}{
+					"hello": "world",
+				}).
+				Unstructured,
+		},
 	}
 
 	for _, test := range tests {
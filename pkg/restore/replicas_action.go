@@ -0,0 +1,107 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+type replicasAction struct {
+	logger logrus.FieldLogger
+}
+
+// NewReplicasAction creates a new ItemAction that restores Deployments and StatefulSets that were
+// scaled down while they were backed up with their original replica counts, and, for restores with
+// the Desired replicas policy, those that were backed up with zero replicas with their desired
+// replica counts.
+func NewReplicasAction(logger logrus.FieldLogger) ItemAction {
+	return &replicasAction{
+		logger: logger,
+	}
+}
+
+func (a *replicasAction) AppliesTo() (ResourceSelector, error) {
+	return ResourceSelector{
+		IncludedResources: []string{"deployments", "statefulsets"},
+	}, nil
+}
+
+func (a *replicasAction) Execute(obj runtime.Unstructured, restore *api.Restore) (runtime.Unstructured, error, error) {
+	metadata, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	annotations := metadata.GetAnnotations()
+
+	// workloads scaled down by a backup's quiesce spec are always restored with the replica
+	// count they had before, since that's the count they were running with
+	if value, ok := annotations[api.OriginalReplicasAnnotation]; ok {
+		replicas, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid %s annotation %q", api.OriginalReplicasAnnotation, value)
+		}
+
+		a.logger.Infof("Restoring %s with its original %d replicas", metadata.GetName(), replicas)
+		if err := setReplicas(obj, replicas); err != nil {
+			return nil, nil, err
+		}
+
+		delete(annotations, api.OriginalReplicasAnnotation)
+		metadata.SetAnnotations(annotations)
+
+		return obj, nil, nil
+	}
+
+	if restore == nil || restore.Spec.Replicas != api.ReplicasPolicyDesired {
+		return obj, nil, nil
+	}
+
+	value, ok := annotations[api.DesiredReplicasAnnotation]
+	if !ok {
+		return obj, nil, nil
+	}
+	if replicas, _, _ := unstructured.NestedInt64(obj.UnstructuredContent(), "spec", "replicas"); replicas != 0 {
+		return obj, nil, nil
+	}
+
+	replicas, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		// the annotation may have been set by hand, so this doesn't fail the item's restore
+		return obj, errors.Errorf("%s not restored with its desired replicas: invalid %s annotation %q", metadata.GetName(), api.DesiredReplicasAnnotation, value), nil
+	}
+
+	a.logger.Infof("Restoring %s with its desired %d replicas", metadata.GetName(), replicas)
+	if err := setReplicas(obj, replicas); err != nil {
+		return nil, nil, err
+	}
+
+	return obj, nil, nil
+}
+
+func setReplicas(obj runtime.Unstructured, replicas int64) error {
+	return errors.WithStack(unstructured.SetNestedField(obj.UnstructuredContent(), replicas, "spec", "replicas"))
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestReplicasActionExecute(t *testing.T) {
+	tests := []struct {
+		name                string
+		obj                 string
+		policy              api.ReplicasPolicy
+		expectedReplicas    int64
+		expectedAnnotations map[string]string
+		expectedWarning     bool
+		expectedErr         bool
+	}{
+		{
+			name:             "a workload without the annotation is unchanged",
+			obj:              `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"deploy-1"},"spec":{"replicas":2}}`,
+			expectedReplicas: 2,
+		},
+		{
+			name:                "a quiesced workload's original replicas are restored",
+			obj:                 `{"apiVersion":"apps/v1","kind":"StatefulSet","metadata":{"namespace":"ns-1","name":"db","annotations":{"a":"b","ark.heptio.com/original-replicas":"3"}},"spec":{"replicas":0}}`,
+			expectedReplicas:    3,
+			expectedAnnotations: map[string]string{"a": "b"},
+		},
+		{
+			name:                "a quiesced workload's original replicas are restored regardless of the policy",
+			obj:                 `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"web","annotations":{"ark.heptio.com/original-replicas":"2","ark.heptio.com/desired-replicas":"5"}},"spec":{"replicas":0}}`,
+			policy:              api.ReplicasPolicyDesired,
+			expectedReplicas:    2,
+			expectedAnnotations: map[string]string{"ark.heptio.com/desired-replicas": "5"},
+		},
+		{
+			name:                "a workload scaled down for maintenance is restored with zero replicas by default",
+			obj:                 `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"web","annotations":{"ark.heptio.com/desired-replicas":"5"}},"spec":{"replicas":0}}`,
+			expectedReplicas:    0,
+			expectedAnnotations: map[string]string{"ark.heptio.com/desired-replicas": "5"},
+		},
+		{
+			name:                "a workload scaled down for maintenance is restored with its desired replicas with the Desired policy",
+			obj:                 `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"web","annotations":{"ark.heptio.com/desired-replicas":"5"}},"spec":{"replicas":0}}`,
+			policy:              api.ReplicasPolicyDesired,
+			expectedReplicas:    5,
+			expectedAnnotations: map[string]string{"ark.heptio.com/desired-replicas": "5"},
+		},
+		{
+			name:                "a running workload keeps its replicas with the Desired policy",
+			obj:                 `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"web","annotations":{"ark.heptio.com/desired-replicas":"5"}},"spec":{"replicas":2}}`,
+			policy:              api.ReplicasPolicyDesired,
+			expectedReplicas:    2,
+			expectedAnnotations: map[string]string{"ark.heptio.com/desired-replicas": "5"},
+		},
+		{
+			name:                "an invalid desired replicas annotation is a warning",
+			obj:                 `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"web","annotations":{"ark.heptio.com/desired-replicas":"five"}},"spec":{"replicas":0}}`,
+			policy:              api.ReplicasPolicyDesired,
+			expectedWarning:     true,
+			expectedAnnotations: map[string]string{"ark.heptio.com/desired-replicas": "five"},
+		},
+		{
+			name:        "an invalid annotation is an error",
+			obj:         `{"apiVersion":"apps/v1","kind":"StatefulSet","metadata":{"namespace":"ns-1","name":"db","annotations":{"ark.heptio.com/original-replicas":"three"}},"spec":{"replicas":0}}`,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			action := NewReplicasAction(arktest.NewLogger())
+
+			restore := arktest.NewTestRestore("heptio-ark", "restore-1", api.RestorePhaseInProgress).WithReplicasPolicy(test.policy).Restore
+
+			res, warning, err := action.Execute(unstructuredOrDie(test.obj), restore)
+			assert.Equal(t, test.expectedWarning, warning != nil)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			replicas, _, _ := unstructured.NestedInt64(res.UnstructuredContent(), "spec", "replicas")
+			assert.Equal(t, test.expectedReplicas, replicas)
+
+			annotations, _, _ := unstructured.NestedStringMap(res.UnstructuredContent(), "metadata", "annotations")
+			if len(test.expectedAnnotations) == 0 {
+				assert.Empty(t, annotations)
+			} else {
+				assert.Equal(t, test.expectedAnnotations, annotations)
+			}
+		})
+	}
+}
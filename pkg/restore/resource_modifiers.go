@@ -0,0 +1,191 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/heptio/ark/pkg/util/kube"
+)
+
+// resourceModifierRules is the format of each value in a resource modifier ConfigMap's data.
+type resourceModifierRules struct {
+	Rules []resourceModifierRuleSpec `json:"rules"`
+}
+
+// resourceModifierRuleSpec is a rule that modifies the restored items of a resource.
+type resourceModifierRuleSpec struct {
+	// GroupResource is the resource whose items are modified, formatted as resource.group,
+	// such as deployments.apps, or just the resource for the core group.
+	GroupResource string `json:"groupResource"`
+
+	// LabelSelector limits the rule to the resource's items with matching labels. Optional.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// JSONPatch is a JSON patch (RFC 6902) applied to matching items. Optional.
+	JSONPatch json.RawMessage `json:"jsonPatch,omitempty"`
+
+	// JSONMergePatch is a JSON merge patch (RFC 7386) applied to matching items, after
+	// JSONPatch. Unlike a strategic merge patch, it replaces lists, such as a pod's containers,
+	// as a whole rather than merging their elements. Optional.
+	JSONMergePatch json.RawMessage `json:"jsonMergePatch,omitempty"`
+
+	// StrategicMergePatch isn't supported, and is only parsed so that a rule that sets it
+	// is rejected rather than silently ignored.
+	StrategicMergePatch json.RawMessage `json:"strategicMergePatch,omitempty"`
+}
+
+// resourceModifier is a parsed resourceModifierRuleSpec.
+type resourceModifier struct {
+	// name identifies the rule in errors, as "<ConfigMap key>[<index>]".
+	name           string
+	groupResource  schema.GroupResource
+	selector       labels.Selector
+	jsonPatch      jsonpatch.Patch
+	jsonMergePatch []byte
+}
+
+// getResourceModifiers gets the named ConfigMap and parses the resource modifier rules in its
+// data.
+func getResourceModifiers(client corev1.ConfigMapInterface, name string) ([]resourceModifier, error) {
+	configMap, err := client.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting resource modifier ConfigMap %s", name)
+	}
+
+	modifiers, err := parseResourceModifiers(configMap.Data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing resource modifier ConfigMap %s", name)
+	}
+
+	return modifiers, nil
+}
+
+// parseResourceModifiers parses the rules in each of data's values, which are YAML or JSON
+// resourceModifierRules. Rules are returned in order of their keys, then of their position.
+func parseResourceModifiers(data map[string]string) ([]resourceModifier, error) {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var modifiers []resourceModifier
+	for _, key := range keys {
+		var rules resourceModifierRules
+		if err := yaml.Unmarshal([]byte(data[key]), &rules); err != nil {
+			return nil, errors.Wrapf(err, "error unmarshalling %s", key)
+		}
+
+		for i, rule := range rules.Rules {
+			modifier, err := newResourceModifier(rule)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid rule %s[%d]", key, i)
+			}
+			modifier.name = fmt.Sprintf("%s[%d]", key, i)
+
+			modifiers = append(modifiers, modifier)
+		}
+	}
+
+	return modifiers, nil
+}
+
+func newResourceModifier(rule resourceModifierRuleSpec) (resourceModifier, error) {
+	if rule.GroupResource == "" {
+		return resourceModifier{}, errors.New("groupResource must be specified")
+	}
+	if len(rule.StrategicMergePatch) > 0 {
+		return resourceModifier{}, errors.New("strategicMergePatch isn't supported, use jsonPatch or jsonMergePatch")
+	}
+	if len(rule.JSONPatch) == 0 && len(rule.JSONMergePatch) == 0 {
+		return resourceModifier{}, errors.New("jsonPatch or jsonMergePatch must be specified")
+	}
+
+	modifier := resourceModifier{
+		groupResource:  schema.ParseGroupResource(rule.GroupResource),
+		selector:       labels.Everything(),
+		jsonMergePatch: rule.JSONMergePatch,
+	}
+
+	if rule.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(rule.LabelSelector)
+		if err != nil {
+			return resourceModifier{}, errors.Wrap(err, "invalid labelSelector")
+		}
+		modifier.selector = selector
+	}
+
+	if len(rule.JSONPatch) > 0 {
+		patch, err := jsonpatch.DecodePatch(rule.JSONPatch)
+		if err != nil {
+			return resourceModifier{}, errors.Wrap(err, "invalid jsonPatch")
+		}
+		modifier.jsonPatch = patch
+	}
+
+	return modifier, nil
+}
+
+// modify returns obj, an item of groupResource, with the patches of each of the restore's
+// resource modifiers that match it applied, in order.
+func (ctx *context) modify(obj *unstructured.Unstructured, groupResource schema.GroupResource) (*unstructured.Unstructured, error) {
+	for _, modifier := range ctx.resourceModifiers {
+		if modifier.groupResource != groupResource || !modifier.selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if modifier.jsonPatch != nil {
+			if data, err = modifier.jsonPatch.Apply(data); err != nil {
+				return nil, errors.Wrapf(err, "error applying the jsonPatch of resource modifier rule %s", modifier.name)
+			}
+		}
+
+		if len(modifier.jsonMergePatch) > 0 {
+			if data, err = jsonpatch.MergePatch(data, modifier.jsonMergePatch); err != nil {
+				return nil, errors.Wrapf(err, "error applying the jsonMergePatch of resource modifier rule %s", modifier.name)
+			}
+		}
+
+		modified := new(unstructured.Unstructured)
+		if err := modified.UnmarshalJSON(data); err != nil {
+			return nil, errors.Wrapf(err, "error decoding the result of resource modifier rule %s", modifier.name)
+		}
+
+		ctx.infof("Modified %s %s with resource modifier rule %s", groupResource.String(), kube.NamespaceAndName(obj), modifier.name)
+		obj = modified
+	}
+
+	return obj, nil
+}
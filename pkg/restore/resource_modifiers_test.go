@@ -0,0 +1,191 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestParseResourceModifiers(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          map[string]string
+		expectedNames []string
+		expectedErr   string
+	}{
+		{
+			name: "rules are parsed in order of key, then position",
+			data: map[string]string{
+				"b": `
+rules:
+- groupResource: deployments.apps
+  labelSelector:
+    matchLabels:
+      app: web
+  jsonPatch:
+  - op: replace
+    path: /spec/replicas
+    value: 1
+`,
+				"a": `{"rules": [{"groupResource": "configmaps", "jsonMergePatch": {"data": {"key": "value"}}}, {"groupResource": "services", "jsonMergePatch": {"spec": {"type": "ClusterIP"}}}]}`,
+			},
+			expectedNames: []string{"a[0]", "a[1]", "b[0]"},
+		},
+		{
+			name:        "invalid YAML is an error",
+			data:        map[string]string{"a": "rules: ["},
+			expectedErr: "error unmarshalling a",
+		},
+		{
+			name:        "rule without a groupResource is an error",
+			data:        map[string]string{"a": `{"rules": [{"jsonMergePatch": {}}]}`},
+			expectedErr: "invalid rule a[0]: groupResource must be specified",
+		},
+		{
+			name:        "rule without a patch is an error",
+			data:        map[string]string{"a": `{"rules": [{"groupResource": "pods"}]}`},
+			expectedErr: "invalid rule a[0]: jsonPatch or jsonMergePatch must be specified",
+		},
+		{
+			name:        "rule with a strategic merge patch is an error",
+			data:        map[string]string{"a": `{"rules": [{"groupResource": "pods", "strategicMergePatch": {"spec": {}}}]}`},
+			expectedErr: "invalid rule a[0]: strategicMergePatch isn't supported",
+		},
+		{
+			name:        "rule with an invalid label selector is an error",
+			data:        map[string]string{"a": `{"rules": [{"groupResource": "pods", "labelSelector": {"matchExpressions": [{"key": "app", "operator": "Bogus"}]}, "jsonMergePatch": {}}]}`},
+			expectedErr: "invalid rule a[0]: invalid labelSelector",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			modifiers, err := parseResourceModifiers(test.data)
+
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var names []string
+			for _, modifier := range modifiers {
+				names = append(names, modifier.name)
+			}
+			assert.Equal(t, test.expectedNames, names)
+		})
+	}
+}
+
+func TestModify(t *testing.T) {
+	modifiers, err := parseResourceModifiers(map[string]string{
+		"rules": `
+rules:
+- groupResource: deployments.apps
+  labelSelector:
+    matchLabels:
+      app: web
+  jsonPatch:
+  - op: replace
+    path: /spec/replicas
+    value: 1
+- groupResource: deployments.apps
+  jsonMergePatch:
+    metadata:
+      annotations:
+        restored: "true"
+- groupResource: configmaps
+  jsonPatch:
+  - op: replace
+    path: /data/missing
+    value: x
+- groupResource: pods
+  jsonMergePatch:
+    spec:
+      containers:
+      - name: app
+        image: registry.example.com/app:v2
+`,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		groupResource schema.GroupResource
+		obj           string
+		expected      string
+		expectedErr   string
+	}{
+		{
+			name:          "patches of all matching rules are applied in order",
+			groupResource: schema.GroupResource{Group: "apps", Resource: "deployments"},
+			obj:           `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"deploy-1","labels":{"app":"web"}},"spec":{"replicas":3}}`,
+			expected:      `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"deploy-1","labels":{"app":"web"},"annotations":{"restored":"true"}},"spec":{"replicas":1}}`,
+		},
+		{
+			name:          "rules whose label selector doesn't match aren't applied",
+			groupResource: schema.GroupResource{Group: "apps", Resource: "deployments"},
+			obj:           `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"deploy-1","labels":{"app":"db"}},"spec":{"replicas":3}}`,
+			expected:      `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"deploy-1","labels":{"app":"db"},"annotations":{"restored":"true"}},"spec":{"replicas":3}}`,
+		},
+		{
+			name:          "rules for other resources aren't applied",
+			groupResource: schema.GroupResource{Group: "extensions", Resource: "deployments"},
+			obj:           `{"apiVersion":"extensions/v1beta1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"deploy-1","labels":{"app":"web"}},"spec":{"replicas":3}}`,
+			expected:      `{"apiVersion":"extensions/v1beta1","kind":"Deployment","metadata":{"namespace":"ns-1","name":"deploy-1","labels":{"app":"web"}},"spec":{"replicas":3}}`,
+		},
+		{
+			name:          "lists in a JSON merge patch replace the item's lists",
+			groupResource: schema.GroupResource{Resource: "pods"},
+			obj:           `{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"ns-1","name":"pod-1"},"spec":{"containers":[{"name":"app","image":"app:v1","ports":[{"containerPort":80}]},{"name":"sidecar","image":"sidecar:v1"}]}}`,
+			expected:      `{"apiVersion":"v1","kind":"Pod","metadata":{"namespace":"ns-1","name":"pod-1"},"spec":{"containers":[{"name":"app","image":"registry.example.com/app:v2"}]}}`,
+		},
+		{
+			name:          "patch that can't be applied is an error",
+			groupResource: schema.GroupResource{Resource: "configmaps"},
+			obj:           `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"ns-1","name":"cm-1"},"data":{}}`,
+			expectedErr:   "error applying the jsonPatch of resource modifier rule rules[2]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := &context{
+				logger:            arktest.NewLogger(),
+				resourceModifiers: modifiers,
+			}
+
+			res, err := ctx.modify(unstructuredOrDie(test.obj), test.groupResource)
+
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, unstructuredOrDie(test.expected), res)
+		})
+	}
+}
@@ -18,6 +18,7 @@ package restore
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
@@ -26,6 +27,8 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -35,6 +38,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -42,6 +46,7 @@ import (
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/audit"
 	"github.com/heptio/ark/pkg/client"
 	"github.com/heptio/ark/pkg/cloudprovider"
 	"github.com/heptio/ark/pkg/discovery"
@@ -51,12 +56,16 @@ import (
 	"github.com/heptio/ark/pkg/util/collections"
 	"github.com/heptio/ark/pkg/util/kube"
 	"github.com/heptio/ark/pkg/util/logging"
+	"github.com/heptio/ark/pkg/util/tracing"
 )
 
 // Restorer knows how to restore a backup.
 type Restorer interface {
 	// Restore restores the backup data from backupReader, returning warnings and errors.
-	Restore(restore *api.Restore, backup *api.Backup, backupReader io.Reader, logFile io.Writer, actions []ItemAction) (api.RestoreResult, api.RestoreResult)
+	// For a dry-run restore, the plan is written to planFile, and the cluster isn't changed.
+	// If the restore's spec.verify is set, the readiness of its workloads is recorded in its
+	// status.
+	Restore(restore *api.Restore, backup *api.Backup, backupReader io.Reader, logFile io.Writer, planFile io.Writer, actions []ItemAction) (api.RestoreResult, api.RestoreResult)
 }
 
 type gvString string
@@ -70,9 +79,13 @@ type kubernetesRestorer struct {
 	snapshotService    cloudprovider.SnapshotService
 	backupClient       arkv1client.BackupsGetter
 	namespaceClient    corev1.NamespaceInterface
+	configMapClient    corev1.ConfigMapsGetter
 	resourcePriorities []string
 	fileSystem         FileSystem
+	auditLog           audit.Log
 	logger             logrus.FieldLogger
+
+	cohabitatingResources []api.CohabitatingResource
 }
 
 // prioritizeResources returns an ordered, fully-resolved list of resources to restore based on
@@ -85,7 +98,7 @@ func prioritizeResources(helper discovery.Helper, priorities []string, includedR
 
 	// start by resolving priorities into GroupResources and adding them to ret
 	for _, r := range priorities {
-		gvr, _, err := helper.ResourceFor(schema.ParseGroupResource(r).WithVersion(""))
+		gvr, _, err := discovery.ResourceForWithRefresh(helper, schema.ParseGroupResource(r).WithVersion(""))
 		if err != nil {
 			return nil, err
 		}
@@ -133,15 +146,20 @@ func prioritizeResources(helper discovery.Helper, priorities []string, includedR
 	return ret, nil
 }
 
-// NewKubernetesRestorer creates a new kubernetesRestorer.
+// NewKubernetesRestorer creates a new kubernetesRestorer. When a backup contains more than one
+// group's copy of one of the built-in cohabitating resources or additionalCohabitatingResources,
+// only the preferred group's copy is restored.
 func NewKubernetesRestorer(
 	discoveryHelper discovery.Helper,
 	dynamicFactory client.DynamicFactory,
 	backupService cloudprovider.BackupService,
 	snapshotService cloudprovider.SnapshotService,
 	resourcePriorities []string,
+	additionalCohabitatingResources []api.CohabitatingResource,
 	backupClient arkv1client.BackupsGetter,
 	namespaceClient corev1.NamespaceInterface,
+	configMapClient corev1.ConfigMapsGetter,
+	auditLog audit.Log,
 	logger logrus.FieldLogger,
 ) (Restorer, error) {
 	return &kubernetesRestorer{
@@ -151,16 +169,20 @@ func NewKubernetesRestorer(
 		snapshotService:    snapshotService,
 		backupClient:       backupClient,
 		namespaceClient:    namespaceClient,
+		configMapClient:    configMapClient,
 		resourcePriorities: resourcePriorities,
 		fileSystem:         &osFileSystem{},
+		auditLog:           auditLog,
 		logger:             logger,
+
+		cohabitatingResources: kuberesource.CohabitatingResources(additionalCohabitatingResources),
 	}, nil
 }
 
 // Restore executes a restore into the target Kubernetes cluster according to the restore spec
 // and using data from the provided backup/backup reader. Returns a warnings and errors RestoreResult,
 // respectively, summarizing info about the restore.
-func (kr *kubernetesRestorer) Restore(restore *api.Restore, backup *api.Backup, backupReader io.Reader, logFile io.Writer, actions []ItemAction) (api.RestoreResult, api.RestoreResult) {
+func (kr *kubernetesRestorer) Restore(restore *api.Restore, backup *api.Backup, backupReader io.Reader, logFile io.Writer, planFile io.Writer, actions []ItemAction) (api.RestoreResult, api.RestoreResult) {
 	// metav1.LabelSelectorAsSelector converts a nil LabelSelector to a
 	// Nothing Selector, i.e. a selector that matches nothing. We want
 	// a selector that matches everything. This can be accomplished by
@@ -178,50 +200,108 @@ func (kr *kubernetesRestorer) Restore(restore *api.Restore, backup *api.Backup,
 	gzippedLog := gzip.NewWriter(logFile)
 	defer gzippedLog.Close()
 
-	log := logrus.New()
-	log.Out = gzippedLog
-	log.Hooks.Add(&logging.ErrorLocationHook{})
-	log.Hooks.Add(&logging.LogLocationHook{})
+	logger := logrus.New()
+	logger.Out = gzippedLog
+	logger.Hooks.Add(&logging.ErrorLocationHook{})
+	logger.Hooks.Add(&logging.LogLocationHook{})
+	log := logger.WithField(logging.OperationIDField, restore.Status.OperationID)
 
 	// get resource includes-excludes
-	resourceIncludesExcludes := getResourceIncludesExcludes(kr.discoveryHelper, restore.Spec.IncludedResources, restore.Spec.ExcludedResources)
+	resourceIncludesExcludes := GetResourceIncludesExcludes(kr.discoveryHelper, restore.Spec.IncludedResources, restore.Spec.ExcludedResources)
 	prioritizedResources, err := prioritizeResources(kr.discoveryHelper, kr.resourcePriorities, resourceIncludesExcludes, log)
 	if err != nil {
 		return api.RestoreResult{}, api.RestoreResult{Ark: []string{err.Error()}}
 	}
 
+	// set the restore's log on plugin actions before resolving them so
+	// anything they log from AppliesTo ends up in it too
+	for _, action := range actions {
+		if logSetter, ok := action.(logging.LogSetter); ok {
+			logSetter.SetLog(log)
+		}
+	}
+
 	resolvedActions, err := resolveActions(actions, kr.discoveryHelper)
 	if err != nil {
 		return api.RestoreResult{}, api.RestoreResult{Ark: []string{err.Error()}}
 	}
 
+	var resourceModifiers []resourceModifier
+	if restore.Spec.ResourceModifierConfigMap != "" {
+		resourceModifiers, err = getResourceModifiers(kr.configMapClient.ConfigMaps(restore.Namespace), restore.Spec.ResourceModifierConfigMap)
+		if err != nil {
+			return api.RestoreResult{}, api.RestoreResult{Ark: []string{err.Error()}}
+		}
+	}
+
+	var statusIncludesExcludes *collections.IncludesExcludes
+	if restore.Spec.RestoreStatus != nil {
+		statusIncludesExcludes = GetResourceIncludesExcludes(kr.discoveryHelper, restore.Spec.RestoreStatus.IncludedResources, restore.Spec.RestoreStatus.ExcludedResources)
+	}
+
 	ctx := &context{
-		backup:               backup,
-		backupReader:         backupReader,
-		restore:              restore,
-		prioritizedResources: prioritizedResources,
-		selector:             selector,
-		logger:               log,
-		dynamicFactory:       kr.dynamicFactory,
-		fileSystem:           kr.fileSystem,
-		namespaceClient:      kr.namespaceClient,
-		actions:              resolvedActions,
-		snapshotService:      kr.snapshotService,
-		waitForPVs:           true,
+		backup:                   backup,
+		backupReader:             backupReader,
+		restore:                  restore,
+		prioritizedResources:     prioritizedResources,
+		resourceIncludesExcludes: resourceIncludesExcludes,
+		discoveryHelper:          kr.discoveryHelper,
+		selector:                 selector,
+		logger:                   log,
+		dynamicFactory:           kr.dynamicFactory,
+		fileSystem:               kr.fileSystem,
+		namespaceClient:          kr.namespaceClient,
+		actions:                  resolvedActions,
+		snapshotService:          kr.snapshotService,
+		waitForPVs:               true,
+		auditLog:                 kr.auditLog,
+
+		statusIncludesExcludes:   statusIncludesExcludes,
+		resourceModifiers:        resourceModifiers,
+		conversionWebhookTimeout: defaultConversionWebhookTimeout,
+		cohabitatingResources:    kr.cohabitatingResources,
 	}
 
-	return ctx.execute()
+	warnings, errs := ctx.execute()
+
+	if restore.Spec.Verify != nil && !restore.Spec.DryRun {
+		restore.Status.Workloads = ctx.verifyWorkloads(&warnings)
+	}
+
+	if restore.Spec.DryRun && planFile != nil {
+		if err := writePlan(planFile, ctx.plan); err != nil {
+			addArkError(&errs, err)
+		}
+	}
+
+	return warnings, errs
 }
 
-// getResourceIncludesExcludes takes the lists of resources to include and exclude, uses the
+// writePlan writes a dry-run restore's plan to w as gzipped JSON.
+func writePlan(w io.Writer, plan []api.RestorePlanItem) error {
+	gzw := gzip.NewWriter(w)
+	defer gzw.Close()
+
+	if plan == nil {
+		plan = []api.RestorePlanItem{}
+	}
+
+	if err := json.NewEncoder(gzw).Encode(plan); err != nil {
+		return errors.Wrap(err, "error encoding restore plan")
+	}
+
+	return nil
+}
+
+// GetResourceIncludesExcludes takes the lists of resources to include and exclude, uses the
 // discovery helper to resolve them to fully-qualified group-resource names, and returns an
 // IncludesExcludes list.
-func getResourceIncludesExcludes(helper discovery.Helper, includes, excludes []string) *collections.IncludesExcludes {
+func GetResourceIncludesExcludes(helper discovery.Helper, includes, excludes []string) *collections.IncludesExcludes {
 	resources := collections.GenerateIncludesExcludes(
 		includes,
 		excludes,
 		func(item string) string {
-			gvr, _, err := helper.ResourceFor(schema.ParseGroupResource(item).WithVersion(""))
+			gvr, _, err := discovery.ResourceForWithRefresh(helper, schema.ParseGroupResource(item).WithVersion(""))
 			if err != nil {
 				return ""
 			}
@@ -240,6 +320,7 @@ type resolvedAction struct {
 	resourceIncludesExcludes  *collections.IncludesExcludes
 	namespaceIncludesExcludes *collections.IncludesExcludes
 	selector                  labels.Selector
+	fieldSelector             fields.Selector
 }
 
 func resolveActions(actions []ItemAction, helper discovery.Helper) ([]resolvedAction, error) {
@@ -251,7 +332,7 @@ func resolveActions(actions []ItemAction, helper discovery.Helper) ([]resolvedAc
 			return nil, err
 		}
 
-		resources := getResourceIncludesExcludes(helper, resourceSelector.IncludedResources, resourceSelector.ExcludedResources)
+		resources := GetResourceIncludesExcludes(helper, resourceSelector.IncludedResources, resourceSelector.ExcludedResources)
 		namespaces := collections.NewIncludesExcludes().Includes(resourceSelector.IncludedNamespaces...).Excludes(resourceSelector.ExcludedNamespaces...)
 
 		selector := labels.Everything()
@@ -261,11 +342,19 @@ func resolveActions(actions []ItemAction, helper discovery.Helper) ([]resolvedAc
 			}
 		}
 
+		fieldSelector := fields.Everything()
+		if resourceSelector.FieldSelector != "" {
+			if fieldSelector, err = fields.ParseSelector(resourceSelector.FieldSelector); err != nil {
+				return nil, err
+			}
+		}
+
 		res := resolvedAction{
 			ItemAction:                action,
 			resourceIncludesExcludes:  resources,
 			namespaceIncludesExcludes: namespaces,
 			selector:                  selector,
+			fieldSelector:             fieldSelector,
 		}
 
 		resolved = append(resolved, res)
@@ -279,20 +368,60 @@ type context struct {
 	backupReader         io.Reader
 	restore              *api.Restore
 	prioritizedResources []schema.GroupResource
-	selector             labels.Selector
-	logger               logrus.FieldLogger
-	dynamicFactory       client.DynamicFactory
-	fileSystem           FileSystem
-	namespaceClient      corev1.NamespaceInterface
-	actions              []resolvedAction
-	snapshotService      cloudprovider.SnapshotService
-	waitForPVs           bool
+	// resourceIncludesExcludes and discoveryHelper are used to resolve resources in the
+	// backup that weren't known to discovery when the restore started.
+	resourceIncludesExcludes *collections.IncludesExcludes
+	discoveryHelper          discovery.Helper
+	selector                 labels.Selector
+	logger                   logrus.FieldLogger
+	dynamicFactory           client.DynamicFactory
+	fileSystem               FileSystem
+	namespaceClient          corev1.NamespaceInterface
+	actions                  []resolvedAction
+	snapshotService          cloudprovider.SnapshotService
+	waitForPVs               bool
+	auditLog                 audit.Log
+
+	// statusIncludesExcludes selects the resources whose status is restored. If nil, status
+	// is not restored for any resources.
+	statusIncludesExcludes *collections.IncludesExcludes
+
+	// resourceModifiers are the rules from the restore's resource modifier ConfigMap that
+	// are applied to the items they match.
+	resourceModifiers []resourceModifier
+
+	// conversionWebhookTimeout is how long to wait for a CRD's conversion webhook to become
+	// available before restoring its custom resources.
+	conversionWebhookTimeout time.Duration
+
+	// cohabitatingResources are the resources served by more than one API group, only one
+	// of whose copies in the backup is restored.
+	cohabitatingResources []api.CohabitatingResource
+
+	// plan records what a dry-run restore would do with each item.
+	plan []api.RestorePlanItem
+
+	// restoredWorkloads are the workloads created by the restore, which are verified if the
+	// restore's spec.verify is set.
+	restoredWorkloads []restoredWorkload
 }
 
 func (ctx *context) infof(msg string, args ...interface{}) {
 	ctx.logger.Infof(msg, args...)
 }
 
+// auditCreate records the creation of the named item by the restore in the audit log.
+func (ctx *context) auditCreate(groupResource schema.GroupResource, namespace, name string) {
+	ctx.auditLog.Record(audit.Entry{
+		Action:    audit.ActionCreate,
+		Kind:      groupResource.String(),
+		Namespace: namespace,
+		Name:      name,
+		Backup:    ctx.restore.Spec.BackupName,
+		Restore:   ctx.restore.Name,
+	})
+}
+
 func (ctx *context) execute() (api.RestoreResult, api.RestoreResult) {
 	ctx.infof("Starting restore of backup %s", kube.NamespaceAndName(ctx.backup))
 
@@ -340,49 +469,44 @@ func (ctx *context) restoreFromDir(dir string) (api.RestoreResult, api.RestoreRe
 		resourceDirsMap[rscName] = rscDir
 	}
 
-	existingNamespaces := sets.NewString()
-
-	for _, resource := range ctx.prioritizedResources {
-		// we don't want to explicitly restore namespace API objs because we'll handle
-		// them as a special case prior to restoring anything into them
-		if resource == kuberesource.Namespaces {
-			continue
-		}
+	ctx.removeCohabitatingResourceDirs(resourceDirsMap)
+	ctx.moveResourceDirs(resourceDirsMap)
 
-		rscDir := resourceDirsMap[resource.String()]
-		if rscDir == nil {
-			continue
-		}
+	existingNamespaces := sets.NewString()
 
+	// restoreResourceDir restores all items for a single resource from its directory in the
+	// backup. It returns false if a fatal error was encountered and the restore should not
+	// continue.
+	restoreResourceDir := func(resource schema.GroupResource, rscDir os.FileInfo) bool {
 		resourcePath := filepath.Join(resourcesDir, rscDir.Name())
 
 		clusterSubDir := filepath.Join(resourcePath, api.ClusterScopedDir)
 		clusterSubDirExists, err := ctx.fileSystem.DirExists(clusterSubDir)
 		if err != nil {
 			addArkError(&errs, err)
-			return warnings, errs
+			return false
 		}
 		if clusterSubDirExists {
 			w, e := ctx.restoreResource(resource.String(), "", clusterSubDir)
 			merge(&warnings, &w)
 			merge(&errs, &e)
-			continue
+			return true
 		}
 
 		nsSubDir := filepath.Join(resourcePath, api.NamespaceScopedDir)
 		nsSubDirExists, err := ctx.fileSystem.DirExists(nsSubDir)
 		if err != nil {
 			addArkError(&errs, err)
-			return warnings, errs
+			return false
 		}
 		if !nsSubDirExists {
-			continue
+			return true
 		}
 
 		nsDirs, err := ctx.fileSystem.ReadDir(nsSubDir)
 		if err != nil {
 			addArkError(&errs, err)
-			return warnings, errs
+			return false
 		}
 
 		for _, nsDir := range nsDirs {
@@ -410,9 +534,16 @@ func (ctx *context) restoreFromDir(dir string) (api.RestoreResult, api.RestoreRe
 			if !existingNamespaces.Has(mappedNsName) {
 				logger := ctx.logger.WithField("namespace", nsName)
 				ns := getNamespace(logger, filepath.Join(dir, api.ResourcesDir, "namespaces", api.ClusterScopedDir, nsName+".json"), mappedNsName)
-				if _, err := kube.EnsureNamespaceExists(ns, ctx.namespaceClient); err != nil {
+				if ctx.restore.Spec.DryRun {
+					if err := ctx.planNamespace(ns); err != nil {
+						addArkError(&errs, err)
+						continue
+					}
+				} else if created, err := kube.EnsureNamespaceExists(ns, ctx.namespaceClient); err != nil {
 					addArkError(&errs, err)
 					continue
+				} else if created {
+					ctx.auditCreate(kuberesource.Namespaces, "", mappedNsName)
 				}
 
 				// keep track of namespaces that we know exist so we don't
@@ -424,11 +555,143 @@ func (ctx *context) restoreFromDir(dir string) (api.RestoreResult, api.RestoreRe
 			merge(&warnings, &w)
 			merge(&errs, &e)
 		}
+
+		return true
+	}
+
+	// custom resources whose CRDs use a conversion webhook can't be created until the webhook's
+	// service is up, so they're deferred until everything else (including the webhook's
+	// deployment and service) has been restored.
+	conversionWebhooks := ctx.getConversionWebhooks(resourcesDir)
+	var deferred []schema.GroupResource
+	handled := sets.NewString()
+
+	for _, resource := range ctx.prioritizedResources {
+		handled.Insert(resource.String())
+
+		// we don't want to explicitly restore namespace API objs because we'll handle
+		// them as a special case prior to restoring anything into them
+		if resource == kuberesource.Namespaces {
+			continue
+		}
+
+		rscDir := resourceDirsMap[resource.String()]
+		if rscDir == nil {
+			continue
+		}
+
+		if _, found := conversionWebhooks[resource]; found {
+			deferred = append(deferred, resource)
+			continue
+		}
+
+		if !restoreResourceDir(resource, rscDir) {
+			return warnings, errs
+		}
+	}
+
+	// resources that discovery didn't know about when the restore started, such as custom
+	// resources whose CRDs were restored above, are resolved after refreshing discovery.
+	for _, resource := range ctx.resolveUnhandledResources(resourceDirsMap, handled, &warnings) {
+		if _, found := conversionWebhooks[resource]; found {
+			deferred = append(deferred, resource)
+			continue
+		}
+
+		if !restoreResourceDir(resource, resourceDirsMap[resource.String()]) {
+			return warnings, errs
+		}
+	}
+
+	for _, resource := range deferred {
+		svc := conversionWebhooks[resource]
+
+		// a dry run doesn't restore the webhook's deployment and service, so there's
+		// nothing to wait for
+		if !ctx.restore.Spec.DryRun {
+			ctx.infof("Waiting for conversion webhook service %s before restoring %s", svc, resource)
+			if err := ctx.waitForConversionWebhook(svc); err != nil {
+				// try to restore anyway; if the webhook really isn't available, the
+				// individual items will fail and be reported as errors.
+				addArkError(&warnings, errors.Wrapf(err, "restoring %s without a ready conversion webhook", resource))
+			}
+		}
+
+		if !restoreResourceDir(resource, resourceDirsMap[resource.String()]) {
+			return warnings, errs
+		}
 	}
 
 	return warnings, errs
 }
 
+// removeCohabitatingResourceDirs removes, for each cohabitating resource that the backup
+// contains more than one group's copy of, all but the preferred copy from resourceDirsMap. The
+// preferred copy is the one from the first of the resource's groups that the cluster serves,
+// or the first of them in the backup if the cluster serves none. Backups only contain one
+// copy unless they were taken before the resource was known to cohabitate.
+func (ctx *context) removeCohabitatingResourceDirs(resourceDirsMap map[string]os.FileInfo) {
+	for _, cohabitator := range ctx.cohabitatingResources {
+		var inBackup []schema.GroupResource
+		for _, group := range cohabitator.Groups {
+			gr := schema.GroupResource{Group: group, Resource: cohabitator.Resource}
+			if _, found := resourceDirsMap[gr.String()]; found && ctx.resourceIncludesExcludes.ShouldInclude(gr.String()) {
+				inBackup = append(inBackup, gr)
+			}
+		}
+		if len(inBackup) < 2 {
+			continue
+		}
+
+		preferred := inBackup[0]
+		for _, gr := range inBackup {
+			if _, served := servedResource(ctx.discoveryHelper, gr); served {
+				preferred = gr
+				break
+			}
+		}
+
+		for _, gr := range inBackup {
+			if gr != preferred {
+				ctx.infof("Not restoring %s because it cohabitates with %s, which is being restored instead", gr, preferred)
+				delete(resourceDirsMap, gr.String())
+			}
+		}
+	}
+}
+
+// resolveUnhandledResources returns the resources with a directory in the backup that are
+// included in the restore but weren't in ctx.prioritizedResources, sorted by name. Discovery
+// is refreshed to resolve them; any that still can't be resolved are added to warnings.
+func (ctx *context) resolveUnhandledResources(resourceDirsMap map[string]os.FileInfo, handled sets.String, warnings *api.RestoreResult) []schema.GroupResource {
+	var names []string
+	for name := range resourceDirsMap {
+		if !handled.Has(name) && ctx.resourceIncludesExcludes.ShouldInclude(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var resolved []schema.GroupResource
+	for _, name := range names {
+		gvr, _, err := discovery.ResourceForWithRefresh(ctx.discoveryHelper, schema.ParseGroupResource(name).WithVersion(""))
+		if err != nil {
+			addArkError(warnings, errors.Wrapf(err, "skipping resource %s, which could not be resolved by discovery", name))
+			continue
+		}
+
+		gr := gvr.GroupResource()
+		if gr.String() != name || gr == kuberesource.Namespaces {
+			continue
+		}
+
+		ctx.infof("Resolved resource %s after refreshing discovery", name)
+		resolved = append(resolved, gr)
+	}
+
+	return resolved
+}
+
 // getNamespace returns a namespace API object that we should attempt to
 // create before restoring anything into it. It will come from the backup
 // tarball if it exists, else will be a new one. If from the tarball, it
@@ -507,6 +770,11 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 		return warnings, errs
 	}
 
+	span := tracing.StartSpan(ctx.restore.Status.OperationID, "restoreResource")
+	span.SetTag("resource", resource)
+	span.SetTag("namespace", namespace)
+	defer span.Finish()
+
 	if namespace != "" {
 		ctx.infof("Restoring resource '%s' into namespace '%s' from: %s", resource, namespace, resourcePath)
 	} else {
@@ -551,12 +819,24 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 			continue
 		}
 
+		if err := ctx.convertAPIVersion(obj, groupResource); err != nil {
+			addToResult(&errs, namespace, err)
+			continue
+		}
+
 		if !ctx.selector.Matches(labels.Set(obj.GetLabels())) {
 			continue
 		}
 
-		if hasControllerOwner(obj.GetOwnerReferences()) {
+		if HasControllerOwner(obj.GetOwnerReferences()) {
 			ctx.infof("%s/%s has a controller owner - skipping", obj.GetNamespace(), obj.GetName())
+			ctx.addToPlan(groupResource, namespace, obj.GetName(), api.RestorePlanActionSkip, "has a controller owner")
+			continue
+		}
+
+		if groupResource == kuberesource.Jobs && hasCronJobOwner(obj.GetOwnerReferences()) {
+			ctx.infof("%s was created by a CronJob - skipping", kube.NamespaceAndName(obj))
+			ctx.addToPlan(groupResource, namespace, obj.GetName(), api.RestorePlanActionSkip, "was created by a CronJob")
 			continue
 		}
 
@@ -565,8 +845,9 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 			addToResult(&errs, namespace, fmt.Errorf("error checking completion %q: %v", fullPath, err))
 			continue
 		}
-		if complete {
+		if complete && (groupResource != kuberesource.Jobs || !boolptr.IsSetToFalse(ctx.restore.Spec.SkipCompletedJobs)) {
 			ctx.infof("%s is complete - skipping", kube.NamespaceAndName(obj))
+			ctx.addToPlan(groupResource, namespace, obj.GetName(), api.RestorePlanActionSkip, "is complete")
 			continue
 		}
 
@@ -588,9 +869,18 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 			}
 		}
 
+		// hold on to the backed-up volume spec, before actions modify it, to check the
+		// restored item against
+		var backedUpVolumeSpec volumeSpec
+		if isVolumeResource(groupResource) {
+			backedUpVolumeSpec = getVolumeSpec(obj)
+		}
+
 		if groupResource == kuberesource.PersistentVolumes {
 			// restore the PV from snapshot (if applicable)
+			volumeSpan := span.StartChild("restoreVolume")
 			updatedObj, err := ctx.executePVAction(obj)
+			volumeSpan.Finish()
 			if err != nil {
 				addToResult(&errs, namespace, fmt.Errorf("error executing PVAction for %s: %v", fullPath, err))
 				continue
@@ -598,7 +888,7 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 			obj = updatedObj
 
 			// wait for the PV to be ready
-			if ctx.waitForPVs {
+			if ctx.waitForPVs && !ctx.restore.Spec.DryRun {
 				pvWatch, err := resourceClient.Watch(metav1.ListOptions{})
 				if err != nil {
 					addToResult(&errs, namespace, fmt.Errorf("error watching for namespace %q, resource %q: %v", namespace, &groupResource, err))
@@ -610,18 +900,29 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 			}
 		}
 
+		if err := ctx.rewriteClusterDomain(obj, groupResource); err != nil {
+			addToResult(&errs, namespace, fmt.Errorf("error rewriting cluster domain of %s: %v", fullPath, err))
+			continue
+		}
+
 		for _, action := range applicableActions {
 			if !action.selector.Matches(labels.Set(obj.GetLabels())) {
 				continue
 			}
 
+			if !collections.MatchesFieldSelector(action.fieldSelector, obj.UnstructuredContent()) {
+				continue
+			}
+
 			ctx.infof("Executing item action for %v", &groupResource)
 
 			if logSetter, ok := action.ItemAction.(logging.LogSetter); ok {
 				logSetter.SetLog(ctx.logger)
 			}
 
+			actionSpan := span.StartChild("itemAction")
 			updatedObj, warning, err := action.Execute(obj, ctx.restore)
+			actionSpan.Finish()
 			if warning != nil {
 				addToResult(&warnings, namespace, fmt.Errorf("warning preparing %s: %v", fullPath, warning))
 			}
@@ -639,8 +940,16 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 			obj = unstructuredObj
 		}
 
+		if obj, err = ctx.modify(obj, groupResource); err != nil {
+			addToResult(&errs, namespace, fmt.Errorf("error modifying %s: %v", fullPath, err))
+			continue
+		}
+
+		// status is cleared out below, so hold on to it in case it should be restored
+		status, hasStatus := obj.Object["status"]
+
 		// clear out non-core metadata fields & status
-		if obj, err = resetMetadataAndStatus(obj); err != nil {
+		if obj, err = ResetMetadataAndStatus(obj); err != nil {
 			addToResult(&errs, namespace, err)
 			continue
 		}
@@ -654,8 +963,19 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 		// add an ark-restore label to each resource for easy ID
 		addLabel(obj, api.RestoreLabelKey, ctx.restore.Name)
 
+		if ctx.restore.Spec.AnnotateOriginalVolumeSpecs && isVolumeResource(groupResource) {
+			annotateVolumeSpec(obj, groupResource, backedUpVolumeSpec)
+		}
+
+		if ctx.restore.Spec.DryRun {
+			if err := ctx.planItem(resourceClient, groupResource, namespace, obj); err != nil {
+				addToResult(&errs, namespace, fmt.Errorf("error planning restore of %s: %v", fullPath, err))
+			}
+			continue
+		}
+
 		ctx.infof("Restoring %s: %v", obj.GroupVersionKind().Kind, obj.GetName())
-		_, restoreErr := resourceClient.Create(obj)
+		createdObj, restoreErr := resourceClient.Create(obj)
 		if apierrors.IsAlreadyExists(restoreErr) {
 			equal := false
 			if fromCluster, err := resourceClient.Get(obj.GetName(), metav1.GetOptions{}); err == nil {
@@ -680,6 +1000,30 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 			continue
 		}
 
+		ctx.auditCreate(groupResource, namespace, obj.GetName())
+
+		if ctx.restore.Spec.Verify != nil && workloadResources[groupResource] {
+			ctx.restoredWorkloads = append(ctx.restoredWorkloads, restoredWorkload{
+				groupResource: groupResource,
+				namespace:     namespace,
+				name:          obj.GetName(),
+				client:        resourceClient,
+			})
+		}
+
+		if createdObj != nil && isVolumeResource(groupResource) {
+			for _, err := range checkVolumeSpec(createdObj, groupResource, backedUpVolumeSpec) {
+				addToResult(&warnings, namespace, err)
+			}
+		}
+
+		if hasStatus && ctx.statusIncludesExcludes != nil && ctx.statusIncludesExcludes.ShouldInclude(groupResource.String()) {
+			ctx.infof("Restoring status of %s: %v", obj.GroupVersionKind().Kind, obj.GetName())
+			if err := ctx.restoreStatus(createdObj, obj.GroupVersionKind().GroupVersion(), groupResource, namespace, status); err != nil {
+				addToResult(&warnings, namespace, fmt.Errorf("error restoring status of %s: %v", fullPath, err))
+			}
+		}
+
 		if waiter != nil {
 			waiter.RegisterItem(obj.GetName())
 		}
@@ -694,6 +1038,87 @@ func (ctx *context) restoreResource(resource, namespace, resourcePath string) (a
 	return warnings, errs
 }
 
+// planItem records in the plan whether a dry-run restore would create obj, or skip it
+// because it already exists.
+func (ctx *context) planItem(resourceClient client.Dynamic, groupResource schema.GroupResource, namespace string, obj *unstructured.Unstructured) error {
+	fromCluster, err := resourceClient.Get(obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		ctx.addToPlan(groupResource, namespace, obj.GetName(), api.RestorePlanActionCreate, "")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	equal, err := objectsAreEqual(fromCluster, obj)
+	if err != nil {
+		ctx.infof("error checking %s against cluster: %v", obj.GetName(), err)
+	}
+
+	if equal {
+		ctx.addToPlan(groupResource, namespace, obj.GetName(), api.RestorePlanActionSkip, "already exists")
+	} else {
+		ctx.addToPlan(groupResource, namespace, obj.GetName(), api.RestorePlanActionConflict, "")
+	}
+
+	return nil
+}
+
+// planNamespace records in the plan whether a dry-run restore would create ns.
+func (ctx *context) planNamespace(ns *v1.Namespace) error {
+	_, err := ctx.namespaceClient.Get(ns.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		ctx.addToPlan(kuberesource.Namespaces, "", ns.Name, api.RestorePlanActionCreate, "")
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error getting namespace %s", ns.Name)
+	}
+
+	ctx.addToPlan(kuberesource.Namespaces, "", ns.Name, api.RestorePlanActionSkip, "already exists")
+	return nil
+}
+
+// addToPlan records what a dry-run restore would do with an item. It's a no-op if the
+// restore isn't a dry run.
+func (ctx *context) addToPlan(groupResource schema.GroupResource, namespace, name string, action api.RestorePlanAction, reason string) {
+	if !ctx.restore.Spec.DryRun {
+		return
+	}
+
+	ctx.logger.WithFields(logrus.Fields{
+		"resource":  groupResource.String(),
+		"namespace": namespace,
+		"name":      name,
+	}).Infof("Dry run: %s", action)
+
+	ctx.plan = append(ctx.plan, api.RestorePlanItem{
+		Resource:  groupResource.String(),
+		Namespace: namespace,
+		Name:      name,
+		Action:    action,
+		Reason:    reason,
+	})
+}
+
+// restoreStatus sets the status of an object that has just been created to the backed-up status,
+// using the status subresource.
+func (ctx *context) restoreStatus(obj *unstructured.Unstructured, gv schema.GroupVersion, groupResource schema.GroupResource, namespace string, status interface{}) error {
+	resource := metav1.APIResource{
+		Namespaced: len(namespace) > 0,
+		Name:       groupResource.Resource + "/status",
+	}
+
+	statusClient, err := ctx.dynamicFactory.ClientForGroupVersionResource(gv, resource, namespace)
+	if err != nil {
+		return err
+	}
+
+	obj.Object["status"] = status
+	_, err = statusClient.Update(obj)
+	return err
+}
+
 func (ctx *context) executePVAction(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
 	pvName := obj.GetName()
 	if pvName == "" {
@@ -731,8 +1156,20 @@ func (ctx *context) executePVAction(obj *unstructured.Unstructured) (*unstructur
 		return nil, errors.New("you must configure a persistentVolumeProvider to restore PersistentVolumes from snapshots")
 	}
 
+	volumeAZ := backupInfo.AvailabilityZone
+	if targetAZ, ok := ctx.restore.Spec.AvailabilityZoneMapping[volumeAZ]; ok && volumeAZ != "" {
+		ctx.infof("restoring PersistentVolume %s into availability zone %s instead of %s", pvName, targetAZ, volumeAZ)
+		remapPVZone(obj, volumeAZ, targetAZ)
+		volumeAZ = targetAZ
+	}
+
+	if ctx.restore.Spec.DryRun {
+		ctx.infof("dry run: not restoring PersistentVolume %s from SnapshotID %s", pvName, backupInfo.SnapshotID)
+		return obj, nil
+	}
+
 	ctx.infof("restoring PersistentVolume %s from SnapshotID %s", pvName, backupInfo.SnapshotID)
-	volumeID, err := ctx.snapshotService.CreateVolumeFromSnapshot(backupInfo.SnapshotID, backupInfo.Type, backupInfo.AvailabilityZone, backupInfo.Iops)
+	volumeID, err := ctx.snapshotService.CreateVolumeFromSnapshot(backupInfo.SnapshotID, backupInfo.Type, volumeAZ, backupInfo.Iops)
 	if err != nil {
 		return nil, err
 	}
@@ -756,7 +1193,7 @@ func (ctx *context) executePVAction(obj *unstructured.Unstructured) (*unstructur
 // information that won't match
 func objectsAreEqual(fromCluster, fromBackup *unstructured.Unstructured) (bool, error) {
 	// Remove insubstantial metadata
-	fromCluster, err := resetMetadataAndStatus(fromCluster)
+	fromCluster, err := ResetMetadataAndStatus(fromCluster)
 	if err != nil {
 		return false, err
 	}
@@ -779,7 +1216,10 @@ func isPVReady(obj runtime.Unstructured) bool {
 	return phase == string(v1.VolumeAvailable)
 }
 
-func resetMetadataAndStatus(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+// ResetMetadataAndStatus removes all metadata other than name, namespace, labels
+// and annotations from obj, along with its status, so that it can be created in
+// or compared against a cluster.
+func ResetMetadataAndStatus(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
 	metadata, err := collections.GetMap(obj.UnstructuredContent(), "metadata")
 	if err != nil {
 		return nil, err
@@ -812,10 +1252,10 @@ func addLabel(obj *unstructured.Unstructured, key string, val string) {
 	obj.SetLabels(labels)
 }
 
-// hasControllerOwner returns whether or not an object has a controller
+// HasControllerOwner returns whether or not an object has a controller
 // owner ref. Used to identify whether or not an object should be explicitly
 // recreated during a restore.
-func hasControllerOwner(refs []metav1.OwnerReference) bool {
+func HasControllerOwner(refs []metav1.OwnerReference) bool {
 	for _, ref := range refs {
 		if ref.Controller != nil && *ref.Controller {
 			return true
@@ -824,6 +1264,17 @@ func hasControllerOwner(refs []metav1.OwnerReference) bool {
 	return false
 }
 
+// hasCronJobOwner returns whether or not an object is owned by a CronJob,
+// which creates its Jobs on its own schedule once it's restored.
+func hasCronJobOwner(refs []metav1.OwnerReference) bool {
+	for _, ref := range refs {
+		if ref.Kind == "CronJob" && strings.HasPrefix(ref.APIVersion, "batch/") {
+			return true
+		}
+	}
+	return false
+}
+
 // isCompleted returns whether or not an object is considered completed.
 // Used to identify whether or not an object should be restored. Only Jobs or Pods are considered
 func isCompleted(obj *unstructured.Unstructured, groupResource schema.GroupResource) (bool, error) {
@@ -881,7 +1332,8 @@ func (ctx *context) unzipAndExtractBackup(src io.Reader) (string, error) {
 }
 
 // readBackup extracts a tar reader to a local directory/file tree within a
-// temp directory.
+// temp directory. Entries are read from the tar as they're streamed in, and
+// only those that the restore will use are written to the directory.
 func (ctx *context) readBackup(tarRdr *tar.Reader) (string, error) {
 	dir, err := ctx.fileSystem.TempDir("", "")
 	if err != nil {
@@ -889,6 +1341,10 @@ func (ctx *context) readBackup(tarRdr *tar.Reader) (string, error) {
 		return "", err
 	}
 
+	namespaceFilter := collections.NewIncludesExcludes().
+		Includes(ctx.restore.Spec.IncludedNamespaces...).
+		Excludes(ctx.restore.Spec.ExcludedNamespaces...)
+
 	for {
 		header, err := tarRdr.Next()
 
@@ -900,6 +1356,10 @@ func (ctx *context) readBackup(tarRdr *tar.Reader) (string, error) {
 			return "", err
 		}
 
+		if !ctx.shouldExtract(header.Name, namespaceFilter) {
+			continue
+		}
+
 		target := filepath.Join(dir, header.Name)
 
 		switch header.Typeflag {
@@ -911,6 +1371,22 @@ func (ctx *context) readBackup(tarRdr *tar.Reader) (string, error) {
 			}
 
 		case tar.TypeReg:
+			var src io.Reader = tarRdr
+
+			if ctx.shouldFilterLabels(header.Name) {
+				data, err := ioutil.ReadAll(tarRdr)
+				if err != nil {
+					ctx.infof("error reading tar: %v", err)
+					return "", err
+				}
+				// items that can't be decoded are extracted so that restoreResource
+				// reports the error
+				if matches, err := ctx.matchesSelector(data); err == nil && !matches {
+					continue
+				}
+				src = bytes.NewReader(data)
+			}
+
 			// make sure we have the directory created
 			err := ctx.fileSystem.MkdirAll(filepath.Dir(target), header.FileInfo().Mode())
 			if err != nil {
@@ -918,15 +1394,7 @@ func (ctx *context) readBackup(tarRdr *tar.Reader) (string, error) {
 				return "", err
 			}
 
-			// create the file
-			file, err := ctx.fileSystem.Create(target)
-			if err != nil {
-				return "", err
-			}
-			defer file.Close()
-
-			if _, err := io.Copy(file, tarRdr); err != nil {
-				ctx.infof("error copying: %v", err)
+			if err := ctx.extractFile(target, src); err != nil {
 				return "", err
 			}
 		}
@@ -934,3 +1402,79 @@ func (ctx *context) readBackup(tarRdr *tar.Reader) (string, error) {
 
 	return dir, nil
 }
+
+// extractFile writes the contents of src to a new file at target.
+func (ctx *context) extractFile(target string, src io.Reader) error {
+	file, err := ctx.fileSystem.Create(target)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, src); err != nil {
+		ctx.infof("error copying: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// shouldExtract returns whether the tar entry with the given name is needed by
+// the restore. Items of resources or in namespaces that are excluded from the
+// restore aren't. Namespaces are always needed, since their metadata is used when
+// creating the namespaces that items are restored into, as are CRDs, which are
+// checked for conversion webhooks.
+func (ctx *context) shouldExtract(name string, namespaceFilter *collections.IncludesExcludes) bool {
+	// resources/<resource>/<cluster|namespaces>/[<namespace>/]<item>
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(name)), "/")
+	if len(parts) < 2 || parts[0] != api.ResourcesDir {
+		return true
+	}
+
+	resource := parts[1]
+	if resource == kuberesource.Namespaces.String() || resource == kuberesource.CustomResourceDefinitions.String() {
+		return true
+	}
+
+	if !ctx.resourceIncludesExcludes.ShouldInclude(resource) {
+		return false
+	}
+
+	if len(parts) >= 4 && parts[2] == api.NamespaceScopedDir && !namespaceFilter.ShouldInclude(parts[3]) {
+		return false
+	}
+
+	return true
+}
+
+// shouldFilterLabels returns whether the tar entry with the given name is an item
+// that should only be extracted if it matches the restore's label selector.
+func (ctx *context) shouldFilterLabels(name string) bool {
+	if ctx.selector == nil || ctx.selector.Empty() {
+		return false
+	}
+
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(name)), "/")
+	if len(parts) < 4 || parts[0] != api.ResourcesDir {
+		return false
+	}
+
+	resource := parts[1]
+	return resource != kuberesource.Namespaces.String() && resource != kuberesource.CustomResourceDefinitions.String()
+}
+
+// matchesSelector returns whether the labels of the JSON-encoded item match the
+// restore's label selector.
+func (ctx *context) matchesSelector(data []byte) (bool, error) {
+	var item struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+	}
+
+	if err := json.Unmarshal(data, &item); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return ctx.selector.Matches(labels.Set(item.Metadata.Labels)), nil
+}
@@ -17,26 +17,35 @@ limitations under the License.
 package restore
 
 import (
+	"archive/tar"
+	"bytes"
 	"encoding/json"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/audit"
 	"github.com/heptio/ark/pkg/cloudprovider"
+	"github.com/heptio/ark/pkg/kuberesource"
 	"github.com/heptio/ark/pkg/util/boolptr"
 	"github.com/heptio/ark/pkg/util/collections"
 	arktest "github.com/heptio/ark/pkg/util/test"
@@ -181,6 +190,7 @@ func TestRestoreNamespaceFiltering(t *testing.T) {
 			log := arktest.NewLogger()
 
 			ctx := &context{
+				auditLog:             &arktest.FakeAuditLog{},
 				restore:              test.restore,
 				namespaceClient:      &fakeNamespaceClient{},
 				fileSystem:           test.fileSystem,
@@ -201,6 +211,112 @@ func TestRestoreNamespaceFiltering(t *testing.T) {
 	}
 }
 
+func TestReadBackupExtractsOnlyRestoredItems(t *testing.T) {
+	files := map[string]string{
+		"metadata/version":                    "1",
+		"resources/namespaces/cluster/a.json": `{"metadata":{"name":"a"}}`,
+		"resources/namespaces/cluster/b.json": `{"metadata":{"name":"b"}}`,
+		"resources/customresourcedefinitions.apiextensions.k8s.io/cluster/crd.json": `{"metadata":{"name":"crd"}}`,
+		"resources/nodes/cluster/node.json":                                         `{"metadata":{"name":"node","labels":{"app":"x"}}}`,
+		"resources/secrets/namespaces/a/secret.json":                                `{"metadata":{"name":"secret","labels":{"app":"x"}}}`,
+		"resources/secrets/namespaces/b/secret.json":                                `{"metadata":{"name":"secret","labels":{"app":"x"}}}`,
+		"resources/configmaps/namespaces/a/cm-x.json":                               `{"metadata":{"name":"cm-x","labels":{"app":"x"}}}`,
+		"resources/configmaps/namespaces/a/cm-y.json":                               `{"metadata":{"name":"cm-y","labels":{"app":"y"}}}`,
+	}
+
+	tests := []struct {
+		name          string
+		restore       *api.Restore
+		resources     *collections.IncludesExcludes
+		selector      labels.Selector
+		expectedFiles []string
+	}{
+		{
+			name:      "everything is extracted when nothing is filtered",
+			restore:   &api.Restore{},
+			resources: collections.NewIncludesExcludes(),
+			selector:  labels.Everything(),
+			expectedFiles: []string{
+				"metadata/version",
+				"resources/namespaces/cluster/a.json",
+				"resources/namespaces/cluster/b.json",
+				"resources/customresourcedefinitions.apiextensions.k8s.io/cluster/crd.json",
+				"resources/nodes/cluster/node.json",
+				"resources/secrets/namespaces/a/secret.json",
+				"resources/secrets/namespaces/b/secret.json",
+				"resources/configmaps/namespaces/a/cm-x.json",
+				"resources/configmaps/namespaces/a/cm-y.json",
+			},
+		},
+		{
+			name:      "items in excluded namespaces and of excluded resources aren't extracted",
+			restore:   &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"a"}}},
+			resources: collections.NewIncludesExcludes().Excludes("nodes", "configmaps"),
+			selector:  labels.Everything(),
+			expectedFiles: []string{
+				"metadata/version",
+				"resources/namespaces/cluster/a.json",
+				"resources/namespaces/cluster/b.json",
+				"resources/customresourcedefinitions.apiextensions.k8s.io/cluster/crd.json",
+				"resources/secrets/namespaces/a/secret.json",
+			},
+		},
+		{
+			name:      "items that don't match the label selector aren't extracted",
+			restore:   &api.Restore{},
+			resources: collections.NewIncludesExcludes(),
+			selector:  labels.SelectorFromSet(labels.Set{"app": "x"}),
+			expectedFiles: []string{
+				"metadata/version",
+				"resources/namespaces/cluster/a.json",
+				"resources/namespaces/cluster/b.json",
+				"resources/customresourcedefinitions.apiextensions.k8s.io/cluster/crd.json",
+				"resources/nodes/cluster/node.json",
+				"resources/secrets/namespaces/a/secret.json",
+				"resources/secrets/namespaces/b/secret.json",
+				"resources/configmaps/namespaces/a/cm-x.json",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			tw := tar.NewWriter(buf)
+			for name, data := range files {
+				require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Size: int64(len(data)), Typeflag: tar.TypeReg, Mode: 0755}))
+				_, err := tw.Write([]byte(data))
+				require.NoError(t, err)
+			}
+			require.NoError(t, tw.Close())
+
+			fileSystem := newFakeFileSystem()
+			ctx := &context{
+				auditLog:                 &arktest.FakeAuditLog{},
+				restore:                  test.restore,
+				resourceIncludesExcludes: test.resources,
+				selector:                 test.selector,
+				fileSystem:               fileSystem,
+				logger:                   arktest.NewLogger(),
+			}
+
+			dir, err := ctx.readBackup(tar.NewReader(buf))
+			require.NoError(t, err)
+
+			for name, data := range files {
+				contents, err := fileSystem.ReadFile(filepath.Join(dir, name))
+				if !sets.NewString(test.expectedFiles...).Has(name) {
+					assert.Error(t, err, "expected %s not to be extracted", name)
+					continue
+				}
+				if assert.NoError(t, err, "expected %s to be extracted", name) {
+					assert.Equal(t, data, string(contents))
+				}
+			}
+		})
+	}
+}
+
 func TestRestorePriority(t *testing.T) {
 	tests := []struct {
 		name                 string
@@ -273,6 +389,7 @@ func TestRestorePriority(t *testing.T) {
 			log := arktest.NewLogger()
 
 			ctx := &context{
+				auditLog:             &arktest.FakeAuditLog{},
 				restore:              test.restore,
 				namespaceClient:      &fakeNamespaceClient{},
 				fileSystem:           test.fileSystem,
@@ -292,6 +409,95 @@ func TestRestorePriority(t *testing.T) {
 	}
 }
 
+func TestRestoreResolvesUnknownResourcesAfterRefresh(t *testing.T) {
+	fileSystem := newFakeFileSystem().
+		WithDirectory("bak/resources/a/cluster").
+		WithDirectory("bak/resources/foos.example.com/cluster").
+		WithDirectory("bak/resources/bars.example.com/cluster").
+		WithDirectory("bak/resources/unknown.example.com/cluster")
+
+	discoveryHelper := arktest.NewFakeDiscoveryHelper(false, map[schema.GroupVersionResource]schema.GroupVersionResource{
+		{Group: "example.com", Resource: "foos"}: {Group: "example.com", Version: "v1", Resource: "foos"},
+		{Group: "example.com", Resource: "bars"}: {Group: "example.com", Version: "v1", Resource: "bars"},
+	})
+
+	ctx := &context{
+		auditLog:                 &arktest.FakeAuditLog{},
+		restore:                  &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
+		namespaceClient:          &fakeNamespaceClient{},
+		fileSystem:               fileSystem,
+		logger:                   arktest.NewLogger(),
+		prioritizedResources:     []schema.GroupResource{{Resource: "a"}},
+		resourceIncludesExcludes: collections.NewIncludesExcludes().Excludes("bars.example.com"),
+		discoveryHelper:          discoveryHelper,
+	}
+
+	warnings, errs := ctx.restoreFromDir("bak")
+
+	assert.Equal(t, []string{"skipping resource unknown.example.com, which could not be resolved by discovery: invalid resource \"example.com/, Resource=unknown\""}, warnings.Ark)
+	assert.Empty(t, errs.Ark)
+	assert.Equal(t, []string{"bak/resources", "bak/resources/a/cluster", "bak/resources/foos.example.com/cluster"}, fileSystem.readDirCalls)
+}
+
+func TestRestoreRestoresPreferredCohabitatingResource(t *testing.T) {
+	var (
+		extensionsIngresses = schema.GroupVersionResource{Group: "extensions", Version: "v1beta1", Resource: "ingresses"}
+		networkingIngresses = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1beta1", Resource: "ingresses"}
+	)
+
+	tests := []struct {
+		name             string
+		served           []schema.GroupVersionResource
+		expectedReadDirs []string
+	}{
+		{
+			name:             "first group in order of preference is restored when the cluster serves both",
+			served:           []schema.GroupVersionResource{extensionsIngresses, networkingIngresses},
+			expectedReadDirs: []string{"bak/resources", "bak/resources/ingresses.networking.k8s.io/cluster"},
+		},
+		{
+			name:             "group served by the cluster is restored",
+			served:           []schema.GroupVersionResource{extensionsIngresses},
+			expectedReadDirs: []string{"bak/resources", "bak/resources/ingresses.extensions/cluster"},
+		},
+		{
+			name:             "first group in order of preference is restored when the cluster serves neither",
+			expectedReadDirs: []string{"bak/resources", "bak/resources/ingresses.networking.k8s.io/cluster"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileSystem := newFakeFileSystem().
+				WithDirectory("bak/resources/ingresses.extensions/cluster").
+				WithDirectory("bak/resources/ingresses.networking.k8s.io/cluster")
+
+			resources := make(map[schema.GroupVersionResource]schema.GroupVersionResource)
+			for _, gvr := range test.served {
+				resources[gvr.GroupResource().WithVersion("")] = gvr
+			}
+
+			ctx := &context{
+				auditLog:                 &arktest.FakeAuditLog{},
+				restore:                  &api.Restore{Spec: api.RestoreSpec{IncludedNamespaces: []string{"*"}}},
+				namespaceClient:          &fakeNamespaceClient{},
+				fileSystem:               fileSystem,
+				logger:                   arktest.NewLogger(),
+				prioritizedResources:     []schema.GroupResource{extensionsIngresses.GroupResource(), networkingIngresses.GroupResource()},
+				resourceIncludesExcludes: collections.NewIncludesExcludes(),
+				discoveryHelper:          arktest.NewFakeDiscoveryHelper(false, resources),
+				cohabitatingResources:    kuberesource.CohabitatingResources(nil),
+			}
+
+			warnings, errs := ctx.restoreFromDir("bak")
+
+			assert.Empty(t, warnings.Ark)
+			assert.Empty(t, errs.Ark)
+			assert.Equal(t, test.expectedReadDirs, fileSystem.readDirCalls)
+		})
+	}
+}
+
 func TestNamespaceRemapping(t *testing.T) {
 	var (
 		baseDir              = "bak"
@@ -318,6 +524,7 @@ func TestNamespaceRemapping(t *testing.T) {
 	namespaceClient := &fakeNamespaceClient{}
 
 	ctx := &context{
+		auditLog:             &arktest.FakeAuditLog{},
 		dynamicFactory:       dynamicFactory,
 		fileSystem:           fileSystem,
 		selector:             labelSelector,
@@ -461,6 +668,23 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			},
 			expectedObjs: toUnstructured(newTestConfigMap().WithLabels(map[string]string{"fake-restorer": "foo"}).WithArkLabel("my-restore").ConfigMap),
 		},
+		{
+			name:          "custom restorer whose field selector doesn't match is not used",
+			namespace:     "ns-1",
+			resourcePath:  "configmaps",
+			labelSelector: labels.NewSelector(),
+			fileSystem:    newFakeFileSystem().WithFile("configmaps/cm-1.json", newTestConfigMap().ToJSON()),
+			actions: []resolvedAction{
+				{
+					ItemAction:                newFakeAction("configmaps"),
+					resourceIncludesExcludes:  collections.NewIncludesExcludes().Includes("configmaps"),
+					namespaceIncludesExcludes: collections.NewIncludesExcludes(),
+					selector:                  labels.Everything(),
+					fieldSelector:             fields.OneTermEqualSelector("metadata.name", "cm-2"),
+				},
+			},
+			expectedObjs: toUnstructured(newTestConfigMap().WithArkLabel("my-restore").ConfigMap),
+		},
 		{
 			name:          "custom restorer for different group/resource is not used",
 			namespace:     "ns-1",
@@ -549,6 +773,7 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 			dynamicFactory.On("ClientForGroupVersionResource", gv, pvResource, test.namespace).Return(resourceClient, nil)
 
 			ctx := &context{
+				auditLog:       &arktest.FakeAuditLog{},
 				dynamicFactory: dynamicFactory,
 				actions:        test.actions,
 				fileSystem:     test.fileSystem,
@@ -576,6 +801,272 @@ func TestRestoreResourceForNamespace(t *testing.T) {
 	}
 }
 
+func TestRestoreResourceRestoresStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		restoreStatus *collections.IncludesExcludes
+		expectUpdate  bool
+	}{
+		{
+			name:          "status is not restored by default",
+			restoreStatus: nil,
+		},
+		{
+			name:          "status is restored for included resources",
+			restoreStatus: collections.NewIncludesExcludes().Includes("widgets.example.com"),
+			expectUpdate:  true,
+		},
+		{
+			name:          "status is not restored for excluded resources",
+			restoreStatus: collections.NewIncludesExcludes().Includes("*").Excludes("widgets.example.com"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileSystem := newFakeFileSystem().WithFile("widgets.example.com/w-1.json", []byte(
+				`{"apiVersion": "example.com/v1", "kind": "Widget", "metadata": {"namespace": "ns-1", "name": "w-1"}, "status": {"ready": true}}`,
+			))
+
+			created := unstructuredOrDie(`{"apiVersion": "example.com/v1", "kind": "Widget", "metadata": {"namespace": "ns-1", "name": "w-1", "resourceVersion": "1"}}`)
+			resourceClient := &arktest.FakeDynamicClient{}
+			defer resourceClient.AssertExpectations(t)
+			resourceClient.On("Create", mock.Anything).Return(created, nil)
+
+			dynamicFactory := &arktest.FakeDynamicFactory{}
+			defer dynamicFactory.AssertExpectations(t)
+			gv := schema.GroupVersion{Group: "example.com", Version: "v1"}
+			dynamicFactory.On("ClientForGroupVersionResource", gv, metav1.APIResource{Name: "widgets", Namespaced: true}, "ns-1").Return(resourceClient, nil)
+
+			if test.expectUpdate {
+				statusClient := &arktest.FakeDynamicClient{}
+				defer statusClient.AssertExpectations(t)
+				dynamicFactory.On("ClientForGroupVersionResource", gv, metav1.APIResource{Name: "widgets/status", Namespaced: true}, "ns-1").Return(statusClient, nil)
+
+				expected := unstructuredOrDie(`{"apiVersion": "example.com/v1", "kind": "Widget", "metadata": {"namespace": "ns-1", "name": "w-1", "resourceVersion": "1"}, "status": {"ready": true}}`)
+				statusClient.On("Update", expected).Return(expected, nil)
+			}
+
+			ctx := &context{
+				auditLog:               &arktest.FakeAuditLog{},
+				dynamicFactory:         dynamicFactory,
+				fileSystem:             fileSystem,
+				selector:               labels.NewSelector(),
+				restore:                &api.Restore{ObjectMeta: metav1.ObjectMeta{Name: "my-restore"}},
+				backup:                 &api.Backup{},
+				logger:                 arktest.NewLogger(),
+				statusIncludesExcludes: test.restoreStatus,
+			}
+
+			warnings, errs := ctx.restoreResource("widgets.example.com", "ns-1", "widgets.example.com")
+
+			assert.Empty(t, warnings.Namespaces)
+			assert.Empty(t, errs.Namespaces)
+		})
+	}
+}
+
+func TestRestoreResourceSkipsJobs(t *testing.T) {
+	var (
+		falseVal = false
+
+		runningJob   = `{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"namespace": "ns-1", "name": "job-1"}}`
+		completedJob = `{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"namespace": "ns-1", "name": "job-1"}, "status": {"completionTime": "2018-06-01T00:00:00Z"}}`
+		cronJobJob   = `{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"namespace": "ns-1", "name": "job-1", "ownerReferences": [{"apiVersion": "batch/v1beta1", "kind": "CronJob", "name": "cron-1", "uid": "uid-1"}]}}`
+	)
+
+	tests := []struct {
+		name              string
+		job               string
+		skipCompletedJobs *bool
+		expectCreate      bool
+	}{
+		{
+			name:         "running job is restored",
+			job:          runningJob,
+			expectCreate: true,
+		},
+		{
+			name: "completed job is skipped by default",
+			job:  completedJob,
+		},
+		{
+			name:              "completed job is restored when SkipCompletedJobs=false",
+			job:               completedJob,
+			skipCompletedJobs: &falseVal,
+			expectCreate:      true,
+		},
+		{
+			name:              "job created by a cronjob is skipped",
+			job:               cronJobJob,
+			skipCompletedJobs: &falseVal,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileSystem := newFakeFileSystem().WithFile("jobs.batch/job-1.json", []byte(test.job))
+
+			resourceClient := &arktest.FakeDynamicClient{}
+			defer resourceClient.AssertExpectations(t)
+
+			dynamicFactory := &arktest.FakeDynamicFactory{}
+			defer dynamicFactory.AssertExpectations(t)
+
+			if test.expectCreate {
+				created := unstructuredOrDie(runningJob)
+				resourceClient.On("Create", mock.Anything).Return(created, nil)
+
+				gv := schema.GroupVersion{Group: "batch", Version: "v1"}
+				dynamicFactory.On("ClientForGroupVersionResource", gv, metav1.APIResource{Name: "jobs", Namespaced: true}, "ns-1").Return(resourceClient, nil)
+			}
+
+			ctx := &context{
+				auditLog:       &arktest.FakeAuditLog{},
+				dynamicFactory: dynamicFactory,
+				fileSystem:     fileSystem,
+				selector:       labels.NewSelector(),
+				restore: &api.Restore{
+					ObjectMeta: metav1.ObjectMeta{Name: "my-restore"},
+					Spec:       api.RestoreSpec{SkipCompletedJobs: test.skipCompletedJobs},
+				},
+				backup: &api.Backup{},
+				logger: arktest.NewLogger(),
+			}
+
+			warnings, errs := ctx.restoreResource("jobs.batch", "ns-1", "jobs.batch")
+
+			assert.Empty(t, warnings.Namespaces)
+			assert.Empty(t, errs.Namespaces)
+		})
+	}
+}
+
+func TestRestoreResourceChecksVolumeSpecs(t *testing.T) {
+	fileSystem := newFakeFileSystem().WithFile("persistentvolumeclaims/pvc-1.json", []byte(
+		`{"apiVersion": "v1", "kind": "PersistentVolumeClaim", "metadata": {"namespace": "ns-1", "name": "pvc-1"}, "spec": {"accessModes": ["ReadWriteOnce"]}}`,
+	))
+
+	resourceClient := &arktest.FakeDynamicClient{}
+	defer resourceClient.AssertExpectations(t)
+	// the cluster's default storage class is set on the created PVC
+	created := unstructuredOrDie(`{"apiVersion": "v1", "kind": "PersistentVolumeClaim", "metadata": {"namespace": "ns-1", "name": "pvc-1"}, "spec": {"accessModes": ["ReadWriteOnce"], "storageClassName": "standard"}}`)
+	annotated := mock.MatchedBy(func(obj *unstructured.Unstructured) bool {
+		storageClass, found := obj.GetAnnotations()[api.OriginalStorageClassAnnotation]
+		return found && storageClass == "" && obj.GetAnnotations()[api.OriginalAccessModesAnnotation] == "ReadWriteOnce"
+	})
+	resourceClient.On("Create", annotated).Return(created, nil)
+
+	dynamicFactory := &arktest.FakeDynamicFactory{}
+	defer dynamicFactory.AssertExpectations(t)
+	dynamicFactory.On("ClientForGroupVersionResource", schema.GroupVersion{Version: "v1"}, metav1.APIResource{Name: "persistentvolumeclaims", Namespaced: true}, "ns-1").Return(resourceClient, nil)
+
+	ctx := &context{
+		auditLog:       &arktest.FakeAuditLog{},
+		dynamicFactory: dynamicFactory,
+		fileSystem:     fileSystem,
+		selector:       labels.NewSelector(),
+		restore: &api.Restore{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-restore"},
+			Spec:       api.RestoreSpec{AnnotateOriginalVolumeSpecs: true},
+		},
+		backup: &api.Backup{},
+		logger: arktest.NewLogger(),
+	}
+
+	warnings, errs := ctx.restoreResource("persistentvolumeclaims", "ns-1", "persistentvolumeclaims")
+
+	assert.Empty(t, errs.Namespaces)
+	assert.Equal(t, map[string][]string{
+		"ns-1": {`PersistentVolumeClaim ns-1/pvc-1 was restored with storageClassName "standard" instead of "" from the backup`},
+	}, warnings.Namespaces)
+}
+
+func TestRestoreResourceRecordsCreatedItemsInAuditLog(t *testing.T) {
+	fileSystem := newFakeFileSystem().
+		WithFile("configmaps/cm-1.json", []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns-1", "name": "cm-1"}}`)).
+		WithFile("configmaps/cm-2.json", []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns-1", "name": "cm-2"}}`))
+
+	resourceClient := &arktest.FakeDynamicClient{}
+	defer resourceClient.AssertExpectations(t)
+	named := func(name string) interface{} {
+		return mock.MatchedBy(func(obj *unstructured.Unstructured) bool { return obj.GetName() == name })
+	}
+	resourceClient.On("Create", named("cm-1")).Return(unstructuredOrDie(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns-1", "name": "cm-1"}}`), nil)
+	// items that already exist aren't recorded, since the restore didn't change them
+	resourceClient.On("Create", named("cm-2")).Return((*unstructured.Unstructured)(nil), apierrors.NewAlreadyExists(schema.GroupResource{Resource: "configmaps"}, "cm-2"))
+	resourceClient.On("Get", "cm-2", metav1.GetOptions{}).Return(unstructuredOrDie(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns-1", "name": "cm-2"}}`), nil)
+
+	dynamicFactory := &arktest.FakeDynamicFactory{}
+	dynamicFactory.On("ClientForGroupVersionResource", schema.GroupVersion{Version: "v1"}, metav1.APIResource{Name: "configmaps", Namespaced: true}, "ns-1").Return(resourceClient, nil)
+
+	auditLog := &arktest.FakeAuditLog{}
+	ctx := &context{
+		auditLog:       auditLog,
+		dynamicFactory: dynamicFactory,
+		fileSystem:     fileSystem,
+		selector:       labels.NewSelector(),
+		restore: &api.Restore{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-restore"},
+			Spec:       api.RestoreSpec{BackupName: "my-backup"},
+		},
+		backup: &api.Backup{},
+		logger: arktest.NewLogger(),
+	}
+
+	_, errs := ctx.restoreResource("configmaps", "ns-1", "configmaps")
+	assert.Empty(t, errs.Namespaces)
+
+	assert.Equal(t, []audit.Entry{
+		{Action: audit.ActionCreate, Kind: "configmaps", Namespace: "ns-1", Name: "cm-1", Backup: "my-backup", Restore: "my-restore"},
+	}, auditLog.Entries())
+}
+
+func TestRestoreResourceDryRun(t *testing.T) {
+	fileSystem := newFakeFileSystem().
+		WithFile("configmaps/cm-1.json", []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns-1", "name": "cm-1"}}`)).
+		WithFile("configmaps/cm-2.json", []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns-1", "name": "cm-2"}, "data": {"a": "b"}}`)).
+		WithFile("configmaps/cm-3.json", []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns-1", "name": "cm-3"}, "data": {"a": "b"}}`)).
+		WithFile("configmaps/cm-4.json", []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns-1", "name": "cm-4", "ownerReferences": [{"controller": true}]}}`))
+
+	resourceClient := &arktest.FakeDynamicClient{}
+	defer resourceClient.AssertExpectations(t)
+	resourceClient.On("Get", "cm-1", metav1.GetOptions{}).Return((*unstructured.Unstructured)(nil), apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "cm-1"))
+	resourceClient.On("Get", "cm-2", metav1.GetOptions{}).Return(unstructuredOrDie(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns-1", "name": "cm-2", "resourceVersion": "1"}, "data": {"a": "b"}}`), nil)
+	resourceClient.On("Get", "cm-3", metav1.GetOptions{}).Return(unstructuredOrDie(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns-1", "name": "cm-3", "resourceVersion": "1"}, "data": {"a": "c"}}`), nil)
+
+	dynamicFactory := &arktest.FakeDynamicFactory{}
+	gv := schema.GroupVersion{Group: "", Version: "v1"}
+	dynamicFactory.On("ClientForGroupVersionResource", gv, metav1.APIResource{Name: "configmaps", Namespaced: true}, "ns-1").Return(resourceClient, nil)
+
+	ctx := &context{
+		auditLog:       &arktest.FakeAuditLog{},
+		dynamicFactory: dynamicFactory,
+		fileSystem:     fileSystem,
+		selector:       labels.NewSelector(),
+		restore: &api.Restore{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-restore"},
+			Spec:       api.RestoreSpec{DryRun: true},
+		},
+		backup: &api.Backup{},
+		logger: arktest.NewLogger(),
+	}
+
+	warnings, errs := ctx.restoreResource("configmaps", "ns-1", "configmaps")
+
+	assert.Empty(t, warnings.Namespaces)
+	assert.Empty(t, errs.Namespaces)
+	resourceClient.AssertNotCalled(t, "Create", mock.Anything)
+
+	expected := []api.RestorePlanItem{
+		{Resource: "configmaps", Namespace: "ns-1", Name: "cm-1", Action: api.RestorePlanActionCreate},
+		{Resource: "configmaps", Namespace: "ns-1", Name: "cm-2", Action: api.RestorePlanActionSkip, Reason: "already exists"},
+		{Resource: "configmaps", Namespace: "ns-1", Name: "cm-3", Action: api.RestorePlanActionConflict},
+		{Resource: "configmaps", Namespace: "ns-1", Name: "cm-4", Action: api.RestorePlanActionSkip, Reason: "has a controller owner"},
+	}
+	assert.Equal(t, expected, ctx.plan)
+}
+
 func TestHasControllerOwner(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -633,7 +1124,7 @@ func TestHasControllerOwner(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			u := &unstructured.Unstructured{Object: test.object}
-			hasOwner := hasControllerOwner(u.GetOwnerReferences())
+			hasOwner := HasControllerOwner(u.GetOwnerReferences())
 			assert.Equal(t, test.expectOwner, hasOwner)
 		})
 	}
@@ -667,7 +1158,7 @@ func TestResetMetadataAndStatus(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			res, err := resetMetadataAndStatus(test.obj)
+			res, err := ResetMetadataAndStatus(test.obj)
 
 			if assert.Equal(t, test.expectedErr, err != nil) {
 				assert.Equal(t, test.expectedRes, res)
@@ -876,6 +1367,17 @@ func TestExecutePVAction(t *testing.T) {
 			expectSetVolumeID: true,
 			expectedRes:       NewTestUnstructured().WithName("pv-1").WithSpec("xyz").Unstructured,
 		},
+		{
+			name:              "availability zone mapping is applied to CreateVolume and the PV's zone label",
+			obj:               NewTestUnstructured().WithName("pv-1").WithMetadataField("labels", map[string]interface{}{"failure-domain.beta.kubernetes.io/zone": "us-east-1a"}).WithSpec("xyz").Unstructured,
+			restore:           arktest.NewDefaultTestRestore().WithRestorePVs(true).WithMappedAvailabilityZone("us-east-1a", "us-east-1b").Restore,
+			backup:            &api.Backup{Status: api.BackupStatus{VolumeBackups: map[string]*api.VolumeBackupInfo{"pv-1": {SnapshotID: "snap-1", AvailabilityZone: "us-east-1a"}}}},
+			volumeMap:         map[api.VolumeBackupInfo]string{{SnapshotID: "snap-1", AvailabilityZone: "us-east-1b"}: "volume-1"},
+			volumeID:          "volume-1",
+			expectedErr:       false,
+			expectSetVolumeID: true,
+			expectedRes:       NewTestUnstructured().WithName("pv-1").WithMetadataField("labels", map[string]interface{}{"failure-domain.beta.kubernetes.io/zone": "us-east-1b"}).WithSpec("xyz").Unstructured,
+		},
 		{
 			name:              "restoring, snapshotService=nil, backup has at least 1 snapshot -> error",
 			obj:               NewTestUnstructured().WithName("pv-1").WithSpecField("awsElasticBlockStore", make(map[string]interface{})).Unstructured,
@@ -904,6 +1406,7 @@ func TestExecutePVAction(t *testing.T) {
 			}
 
 			ctx := &context{
+				auditLog:        &arktest.FakeAuditLog{},
 				restore:         test.restore,
 				backup:          test.backup,
 				snapshotService: snapshotService,
@@ -1304,7 +1807,7 @@ func (r *fakeAction) Execute(obj runtime.Unstructured, restore *api.Restore) (ru
 	}
 
 	// want the baseline functionality too
-	res, err := resetMetadataAndStatus(unstructuredObj)
+	res, err := ResetMetadataAndStatus(unstructuredObj)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -52,6 +52,10 @@ func (a *serviceAction) Execute(obj runtime.Unstructured, restore *api.Restore)
 		delete(spec, "clusterIP")
 	}
 
+	if restore.Spec.PreserveNodePorts != nil && *restore.Spec.PreserveNodePorts {
+		return obj, nil, nil
+	}
+
 	ports, err := collections.GetSlice(obj.UnstructuredContent(), "spec.ports")
 	if err != nil {
 		return nil, nil, err
@@ -19,6 +19,7 @@ package restore
 import (
 	"testing"
 
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
 	arktest "github.com/heptio/ark/pkg/util/test"
 	"github.com/stretchr/testify/assert"
 
@@ -27,10 +28,11 @@ import (
 
 func TestServiceActionExecute(t *testing.T) {
 	tests := []struct {
-		name        string
-		obj         runtime.Unstructured
-		expectedErr bool
-		expectedRes runtime.Unstructured
+		name              string
+		obj               runtime.Unstructured
+		preserveNodePorts bool
+		expectedErr       bool
+		expectedRes       runtime.Unstructured
 	}{
 		{
 			name:        "no spec should error",
@@ -63,13 +65,27 @@ func TestServiceActionExecute(t *testing.T) {
 					map[string]interface{}{"foo": "bar"},
 				}).Unstructured,
 		},
+		{
+			name: "nodePort should not be deleted when preserving node ports",
+			obj: NewTestUnstructured().WithName("svc-1").WithSpec("clusterIP").
+				WithSpecField("ports", []interface{}{
+					map[string]interface{}{"nodePort": "30000"},
+				}).Unstructured,
+			preserveNodePorts: true,
+			expectedErr:       false,
+			expectedRes: NewTestUnstructured().WithName("svc-1").WithSpec().
+				WithSpecField("ports", []interface{}{
+					map[string]interface{}{"nodePort": "30000"},
+				}).Unstructured,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			action := NewServiceAction(arktest.NewLogger())
 
-			res, _, err := action.Execute(test.obj, nil)
+			restore := &api.Restore{Spec: api.RestoreSpec{PreserveNodePorts: &test.preserveNodePorts}}
+			res, _, err := action.Execute(test.obj, restore)
 
 			if assert.Equal(t, test.expectedErr, err != nil) {
 				assert.Equal(t, test.expectedRes, res)
@@ -0,0 +1,143 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/client"
+	"github.com/heptio/ark/pkg/kuberesource"
+)
+
+const (
+	// defaultVerificationTimeout is how long a restore with spec.verify set waits for its
+	// restored workloads to become ready if the spec doesn't specify a timeout.
+	defaultVerificationTimeout = 5 * time.Minute
+
+	verificationPollInterval = 5 * time.Second
+)
+
+// workloadResources are the resources whose restored items are verified. Deployments and
+// DaemonSets may have been backed up from either the apps or extensions group.
+var workloadResources = map[schema.GroupResource]bool{
+	kuberesource.DaemonSets:                        true,
+	kuberesource.Deployments:                       true,
+	kuberesource.StatefulSets:                      true,
+	{Group: "extensions", Resource: "daemonsets"}:  true,
+	{Group: "extensions", Resource: "deployments"}: true,
+}
+
+// restoredWorkload is a Deployment, StatefulSet, or DaemonSet created by the restore.
+type restoredWorkload struct {
+	groupResource schema.GroupResource
+	namespace     string
+	name          string
+	client        client.Dynamic
+}
+
+// verifyWorkloads waits until all of the restored workloads have all of their replicas ready,
+// or until the restore's verification timeout is reached, returning the readiness of each. A
+// warning is added for each workload that isn't ready.
+func (ctx *context) verifyWorkloads(warnings *api.RestoreResult) []api.WorkloadReadiness {
+	if len(ctx.restoredWorkloads) == 0 {
+		return nil
+	}
+
+	timeout := ctx.restore.Spec.Verify.Timeout.Duration
+	if timeout == 0 {
+		timeout = defaultVerificationTimeout
+	}
+
+	readiness := make([]api.WorkloadReadiness, len(ctx.restoredWorkloads))
+	for i, workload := range ctx.restoredWorkloads {
+		readiness[i] = api.WorkloadReadiness{
+			Resource:  workload.groupResource.String(),
+			Namespace: workload.namespace,
+			Name:      workload.name,
+		}
+	}
+
+	ctx.infof("Waiting up to %v for %d restored workloads to become ready", timeout, len(readiness))
+
+	// the poll's only error is the timeout, which is reported below for each unready workload
+	wait.PollImmediate(verificationPollInterval, timeout, func() (bool, error) {
+		allReady := true
+
+		for i, workload := range ctx.restoredWorkloads {
+			if readiness[i].Ready {
+				continue
+			}
+
+			obj, err := workload.client.Get(workload.name, metav1.GetOptions{})
+			if err != nil {
+				ctx.logger.WithError(err).Debugf("Unable to get %s %s/%s", workload.groupResource, workload.namespace, workload.name)
+				allReady = false
+				continue
+			}
+
+			readiness[i].DesiredReplicas, readiness[i].ReadyReplicas, readiness[i].Ready = workloadReplicas(obj, workload.groupResource)
+			allReady = allReady && readiness[i].Ready
+		}
+
+		return allReady, nil
+	})
+
+	for _, r := range readiness {
+		if r.Ready {
+			ctx.infof("%s %s/%s is ready", r.Resource, r.Namespace, r.Name)
+			continue
+		}
+
+		addToResult(warnings, r.Namespace, errors.Errorf("%s %s wasn't ready after %v: %d of %d replicas ready", r.Resource, r.Name, timeout, r.ReadyReplicas, r.DesiredReplicas))
+	}
+
+	return readiness
+}
+
+// workloadReplicas returns the number of replicas the workload wants, how many of them are
+// ready, and whether the workload is ready. A workload isn't ready until its controller has
+// observed its current generation.
+func workloadReplicas(obj *unstructured.Unstructured, groupResource schema.GroupResource) (int64, int64, bool) {
+	content := obj.UnstructuredContent()
+
+	var desired, ready int64
+	if groupResource.Resource == kuberesource.DaemonSets.Resource {
+		desired, _, _ = unstructured.NestedInt64(content, "status", "desiredNumberScheduled")
+		ready, _, _ = unstructured.NestedInt64(content, "status", "numberReady")
+	} else {
+		var found bool
+		if desired, found, _ = unstructured.NestedInt64(content, "spec", "replicas"); !found {
+			desired = 1
+		}
+		ready, _, _ = unstructured.NestedInt64(content, "status", "readyReplicas")
+	}
+
+	observedGeneration, _, _ := unstructured.NestedInt64(content, "status", "observedGeneration")
+	if observedGeneration < obj.GetGeneration() {
+		return desired, ready, false
+	}
+
+	return desired, ready, ready >= desired
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/kuberesource"
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestWorkloadReplicas(t *testing.T) {
+	tests := []struct {
+		name            string
+		groupResource   schema.GroupResource
+		obj             string
+		expectedDesired int64
+		expectedReady   int64
+		expectedIsReady bool
+	}{
+		{
+			name:            "deployment with all replicas ready",
+			groupResource:   kuberesource.Deployments,
+			obj:             `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"d-1","generation":2},"spec":{"replicas":3},"status":{"observedGeneration":2,"readyReplicas":3}}`,
+			expectedDesired: 3,
+			expectedReady:   3,
+			expectedIsReady: true,
+		},
+		{
+			name:            "deployment with some replicas ready",
+			groupResource:   kuberesource.Deployments,
+			obj:             `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"d-1","generation":1},"spec":{"replicas":3},"status":{"observedGeneration":1,"readyReplicas":1}}`,
+			expectedDesired: 3,
+			expectedReady:   1,
+		},
+		{
+			name:            "statefulset without replicas defaults to 1",
+			groupResource:   kuberesource.StatefulSets,
+			obj:             `{"apiVersion":"apps/v1","kind":"StatefulSet","metadata":{"name":"ss-1","generation":1},"spec":{},"status":{"observedGeneration":1,"readyReplicas":1}}`,
+			expectedDesired: 1,
+			expectedReady:   1,
+			expectedIsReady: true,
+		},
+		{
+			name:            "deployment scaled to zero is ready",
+			groupResource:   schema.GroupResource{Group: "extensions", Resource: "deployments"},
+			obj:             `{"apiVersion":"extensions/v1beta1","kind":"Deployment","metadata":{"name":"d-1","generation":1},"spec":{"replicas":0},"status":{"observedGeneration":1}}`,
+			expectedIsReady: true,
+		},
+		{
+			name:            "daemonset with all pods ready",
+			groupResource:   kuberesource.DaemonSets,
+			obj:             `{"apiVersion":"apps/v1","kind":"DaemonSet","metadata":{"name":"ds-1","generation":1},"status":{"observedGeneration":1,"desiredNumberScheduled":2,"numberReady":2}}`,
+			expectedDesired: 2,
+			expectedReady:   2,
+			expectedIsReady: true,
+		},
+		{
+			name:          "daemonset not yet observed isn't ready",
+			groupResource: kuberesource.DaemonSets,
+			obj:           `{"apiVersion":"apps/v1","kind":"DaemonSet","metadata":{"name":"ds-1","generation":1}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			desired, ready, isReady := workloadReplicas(unstructuredOrDie(test.obj), test.groupResource)
+			assert.Equal(t, test.expectedDesired, desired)
+			assert.Equal(t, test.expectedReady, ready)
+			assert.Equal(t, test.expectedIsReady, isReady)
+		})
+	}
+}
+
+func TestVerifyWorkloads(t *testing.T) {
+	deploymentClient := &arktest.FakeDynamicClient{}
+	deploymentClient.On("Get", "ready", metav1.GetOptions{}).Return(
+		unstructuredOrDie(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"ready"},"spec":{"replicas":2},"status":{"readyReplicas":2}}`), nil)
+	deploymentClient.On("Get", "unready", metav1.GetOptions{}).Return(
+		unstructuredOrDie(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"unready"},"spec":{"replicas":2},"status":{"readyReplicas":1}}`), nil)
+	deploymentClient.On("Get", "missing", metav1.GetOptions{}).Return(&unstructured.Unstructured{}, errors.New("not found"))
+
+	ctx := &context{
+		restore: &api.Restore{Spec: api.RestoreSpec{Verify: &api.RestoreVerifySpec{Timeout: metav1.Duration{Duration: time.Millisecond}}}},
+		logger:  arktest.NewLogger(),
+		restoredWorkloads: []restoredWorkload{
+			{groupResource: kuberesource.Deployments, namespace: "ns-1", name: "ready", client: deploymentClient},
+			{groupResource: kuberesource.Deployments, namespace: "ns-1", name: "unready", client: deploymentClient},
+			{groupResource: kuberesource.Deployments, namespace: "ns-2", name: "missing", client: deploymentClient},
+		},
+	}
+
+	var warnings api.RestoreResult
+	readiness := ctx.verifyWorkloads(&warnings)
+
+	assert.Equal(t, []api.WorkloadReadiness{
+		{Resource: "deployments.apps", Namespace: "ns-1", Name: "ready", DesiredReplicas: 2, ReadyReplicas: 2, Ready: true},
+		{Resource: "deployments.apps", Namespace: "ns-1", Name: "unready", DesiredReplicas: 2, ReadyReplicas: 1},
+		{Resource: "deployments.apps", Namespace: "ns-2", Name: "missing"},
+	}, readiness)
+
+	assert.Equal(t, map[string][]string{
+		"ns-1": {"deployments.apps unready wasn't ready after 1ms: 1 of 2 replicas ready"},
+		"ns-2": {"deployments.apps missing wasn't ready after 1ms: 0 of 0 replicas ready"},
+	}, warnings.Namespaces)
+}
+
+func TestVerifyWorkloadsWithoutWorkloads(t *testing.T) {
+	ctx := &context{
+		restore: &api.Restore{Spec: api.RestoreSpec{Verify: &api.RestoreVerifySpec{}}},
+		logger:  arktest.NewLogger(),
+	}
+
+	var warnings api.RestoreResult
+	assert.Nil(t, ctx.verifyWorkloads(&warnings))
+	assert.Empty(t, warnings.Namespaces)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/kuberesource"
+)
+
+// volumeSpec is the part of a PersistentVolume's or PersistentVolumeClaim's spec that's
+// checked against the backup after the item is restored, since the cluster may default or
+// override it (e.g. a default storage class being set on a PVC that didn't have one).
+type volumeSpec struct {
+	// reclaimPolicy is only set for PersistentVolumes.
+	reclaimPolicy string
+	accessModes   []string
+	storageClass  string
+}
+
+// isVolumeResource returns whether the spec of items of groupResource is checked after
+// they're restored.
+func isVolumeResource(groupResource schema.GroupResource) bool {
+	return groupResource == kuberesource.PersistentVolumes || groupResource == kuberesource.PersistentVolumeClaims
+}
+
+// getVolumeSpec returns the volumeSpec of a PersistentVolume or PersistentVolumeClaim.
+func getVolumeSpec(obj *unstructured.Unstructured) volumeSpec {
+	var spec volumeSpec
+
+	spec.reclaimPolicy, _, _ = unstructured.NestedString(obj.UnstructuredContent(), "spec", "persistentVolumeReclaimPolicy")
+	spec.accessModes, _, _ = unstructured.NestedStringSlice(obj.UnstructuredContent(), "spec", "accessModes")
+	spec.storageClass, _, _ = unstructured.NestedString(obj.UnstructuredContent(), "spec", "storageClassName")
+
+	sort.Strings(spec.accessModes)
+
+	return spec
+}
+
+// annotateVolumeSpec records spec, the item's spec in the backup, in annotations on obj.
+func annotateVolumeSpec(obj *unstructured.Unstructured, groupResource schema.GroupResource, spec volumeSpec) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	if groupResource == kuberesource.PersistentVolumes {
+		annotations[api.OriginalReclaimPolicyAnnotation] = spec.reclaimPolicy
+	}
+	annotations[api.OriginalAccessModesAnnotation] = strings.Join(spec.accessModes, ",")
+	annotations[api.OriginalStorageClassAnnotation] = spec.storageClass
+
+	obj.SetAnnotations(annotations)
+}
+
+// checkVolumeSpec compares the spec of restored, as created in the cluster, to its spec in the
+// backup, and returns an error describing each difference. PersistentVolumes' storage classes
+// aren't compared, since Ark doesn't restore them.
+func checkVolumeSpec(restored *unstructured.Unstructured, groupResource schema.GroupResource, fromBackup volumeSpec) []error {
+	var (
+		errs   []error
+		actual = getVolumeSpec(restored)
+		kind   = restored.GetKind()
+		name   = restored.GetName()
+	)
+
+	if ns := restored.GetNamespace(); ns != "" {
+		name = ns + "/" + name
+	}
+
+	if groupResource == kuberesource.PersistentVolumes && actual.reclaimPolicy != fromBackup.reclaimPolicy {
+		errs = append(errs, errors.Errorf("%s %s was restored with persistentVolumeReclaimPolicy %q instead of %q from the backup", kind, name, actual.reclaimPolicy, fromBackup.reclaimPolicy))
+	}
+
+	if a, b := strings.Join(actual.accessModes, ","), strings.Join(fromBackup.accessModes, ","); a != b {
+		errs = append(errs, errors.Errorf("%s %s was restored with accessModes %q instead of %q from the backup", kind, name, a, b))
+	}
+
+	if groupResource == kuberesource.PersistentVolumeClaims && actual.storageClass != fromBackup.storageClass {
+		errs = append(errs, errors.Errorf("%s %s was restored with storageClassName %q instead of %q from the backup", kind, name, actual.storageClass, fromBackup.storageClass))
+	}
+
+	return errs
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	api "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/kuberesource"
+)
+
+func TestCheckVolumeSpec(t *testing.T) {
+	tests := []struct {
+		name        string
+		backedUp    string
+		restored    string
+		expectedErr []string
+	}{
+		{
+			name:     "matching PV has no differences",
+			backedUp: `{"apiVersion":"v1","kind":"PersistentVolume","metadata":{"name":"pv-1"},"spec":{"persistentVolumeReclaimPolicy":"Retain","accessModes":["ReadWriteOnce","ReadOnlyMany"],"storageClassName":"gp2"}}`,
+			restored: `{"apiVersion":"v1","kind":"PersistentVolume","metadata":{"name":"pv-1"},"spec":{"persistentVolumeReclaimPolicy":"Retain","accessModes":["ReadOnlyMany","ReadWriteOnce"]}}`,
+		},
+		{
+			name:     "PV with a different reclaim policy and access modes",
+			backedUp: `{"apiVersion":"v1","kind":"PersistentVolume","metadata":{"name":"pv-1"},"spec":{"persistentVolumeReclaimPolicy":"Retain","accessModes":["ReadWriteOnce"]}}`,
+			restored: `{"apiVersion":"v1","kind":"PersistentVolume","metadata":{"name":"pv-1"},"spec":{"persistentVolumeReclaimPolicy":"Delete","accessModes":["ReadWriteMany"]}}`,
+			expectedErr: []string{
+				`PersistentVolume pv-1 was restored with persistentVolumeReclaimPolicy "Delete" instead of "Retain" from the backup`,
+				`PersistentVolume pv-1 was restored with accessModes "ReadWriteMany" instead of "ReadWriteOnce" from the backup`,
+			},
+		},
+		{
+			name:     "PVC with a defaulted storage class",
+			backedUp: `{"apiVersion":"v1","kind":"PersistentVolumeClaim","metadata":{"namespace":"ns-1","name":"pvc-1"},"spec":{"accessModes":["ReadWriteOnce"]}}`,
+			restored: `{"apiVersion":"v1","kind":"PersistentVolumeClaim","metadata":{"namespace":"ns-1","name":"pvc-1"},"spec":{"accessModes":["ReadWriteOnce"],"storageClassName":"standard"}}`,
+			expectedErr: []string{
+				`PersistentVolumeClaim ns-1/pvc-1 was restored with storageClassName "standard" instead of "" from the backup`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backedUp := unstructuredOrDie(test.backedUp)
+			groupResource := kuberesource.PersistentVolumes
+			if backedUp.GetKind() == "PersistentVolumeClaim" {
+				groupResource = kuberesource.PersistentVolumeClaims
+			}
+
+			errs := checkVolumeSpec(unstructuredOrDie(test.restored), groupResource, getVolumeSpec(backedUp))
+
+			var actual []string
+			for _, err := range errs {
+				actual = append(actual, err.Error())
+			}
+			assert.Equal(t, test.expectedErr, actual)
+		})
+	}
+}
+
+func TestAnnotateVolumeSpec(t *testing.T) {
+	pv := unstructuredOrDie(`{"apiVersion":"v1","kind":"PersistentVolume","metadata":{"name":"pv-1","annotations":{"a":"b"}},"spec":{"persistentVolumeReclaimPolicy":"Retain","accessModes":["ReadWriteOnce","ReadOnlyMany"],"storageClassName":"gp2"}}`)
+	annotateVolumeSpec(pv, kuberesource.PersistentVolumes, getVolumeSpec(pv))
+	assert.Equal(t, map[string]string{
+		"a":                                 "b",
+		api.OriginalReclaimPolicyAnnotation: "Retain",
+		api.OriginalAccessModesAnnotation:   "ReadOnlyMany,ReadWriteOnce",
+		api.OriginalStorageClassAnnotation:  "gp2",
+	}, pv.GetAnnotations())
+
+	pvc := unstructuredOrDie(`{"apiVersion":"v1","kind":"PersistentVolumeClaim","metadata":{"namespace":"ns-1","name":"pvc-1"},"spec":{"accessModes":["ReadWriteOnce"]}}`)
+	annotateVolumeSpec(pvc, kuberesource.PersistentVolumeClaims, getVolumeSpec(pvc))
+	assert.Equal(t, map[string]string{
+		api.OriginalAccessModesAnnotation:  "ReadWriteOnce",
+		api.OriginalStorageClassAnnotation: "",
+	}, pvc.GetAnnotations())
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/heptio/ark/pkg/util/collections"
+)
+
+const (
+	zoneLabel = "failure-domain.beta.kubernetes.io/zone"
+
+	// zoneLabelDelimiter separates the zones of a volume that spans multiple
+	// zones, such as a GCE regional persistent disk.
+	zoneLabelDelimiter = "__"
+)
+
+// remapPVZone rewrites a PersistentVolume's zone label and the zone terms of its
+// node affinity so that references to the zone "from" point to the zone "to".
+func remapPVZone(pv *unstructured.Unstructured, from, to string) {
+	if labels := pv.GetLabels(); labels != nil {
+		if zones, ok := labels[zoneLabel]; ok {
+			labels[zoneLabel] = remapZones(zones, from, to)
+			pv.SetLabels(labels)
+		}
+	}
+
+	terms, err := collections.GetSlice(pv.UnstructuredContent(), "spec.nodeAffinity.required.nodeSelectorTerms")
+	if err != nil {
+		return
+	}
+
+	for _, term := range terms {
+		termMap, ok := term.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		expressions, ok := termMap["matchExpressions"].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, expression := range expressions {
+			expressionMap, ok := expression.(map[string]interface{})
+			if !ok || expressionMap["key"] != zoneLabel {
+				continue
+			}
+
+			values, ok := expressionMap["values"].([]interface{})
+			if !ok {
+				continue
+			}
+
+			for i := range values {
+				if value, ok := values[i].(string); ok {
+					values[i] = remapZones(value, from, to)
+				}
+			}
+		}
+	}
+}
+
+// remapZones replaces "from" with "to" in a zone label value, which may
+// contain several zones separated by zoneLabelDelimiter.
+func remapZones(zones, from, to string) string {
+	parts := strings.Split(zones, zoneLabelDelimiter)
+	for i := range parts {
+		if parts[i] == from {
+			parts[i] = to
+		}
+	}
+
+	return strings.Join(parts, zoneLabelDelimiter)
+}
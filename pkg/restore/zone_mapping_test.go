@@ -0,0 +1,71 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemapPVZone(t *testing.T) {
+	tests := []struct {
+		name     string
+		pv       string
+		expected string
+	}{
+		{
+			name:     "PV without zone label or node affinity is unchanged",
+			pv:       `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "pv-1", "labels": {"a": "b"}}}`,
+			expected: `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "pv-1", "labels": {"a": "b"}}}`,
+		},
+		{
+			name:     "zone label is remapped",
+			pv:       `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "pv-1", "labels": {"failure-domain.beta.kubernetes.io/zone": "us-east-1a"}}}`,
+			expected: `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "pv-1", "labels": {"failure-domain.beta.kubernetes.io/zone": "us-east-1b"}}}`,
+		},
+		{
+			name:     "zone label for an unmapped zone is unchanged",
+			pv:       `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "pv-1", "labels": {"failure-domain.beta.kubernetes.io/zone": "us-east-1c"}}}`,
+			expected: `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "pv-1", "labels": {"failure-domain.beta.kubernetes.io/zone": "us-east-1c"}}}`,
+		},
+		{
+			name:     "multi-zone label is remapped",
+			pv:       `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "pv-1", "labels": {"failure-domain.beta.kubernetes.io/zone": "us-east-1a__us-east-1c"}}}`,
+			expected: `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "pv-1", "labels": {"failure-domain.beta.kubernetes.io/zone": "us-east-1b__us-east-1c"}}}`,
+		},
+		{
+			name: "node affinity zone terms are remapped",
+			pv: `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "pv-1"}, "spec": {"nodeAffinity": {"required": {"nodeSelectorTerms": [
+				{"matchExpressions": [{"key": "failure-domain.beta.kubernetes.io/zone", "operator": "In", "values": ["us-east-1a", "us-east-1c"]}, {"key": "foo", "operator": "In", "values": ["us-east-1a"]}]}
+			]}}}}`,
+			expected: `{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "pv-1"}, "spec": {"nodeAffinity": {"required": {"nodeSelectorTerms": [
+				{"matchExpressions": [{"key": "failure-domain.beta.kubernetes.io/zone", "operator": "In", "values": ["us-east-1b", "us-east-1c"]}, {"key": "foo", "operator": "In", "values": ["us-east-1a"]}]}
+			]}}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pv := unstructuredOrDie(test.pv)
+
+			remapPVZone(pv, "us-east-1a", "us-east-1b")
+
+			assert.Equal(t, unstructuredOrDie(test.expected), pv)
+		})
+	}
+}
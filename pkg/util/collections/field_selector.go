@@ -0,0 +1,53 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collections
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// MatchesFieldSelector returns true if root matches selector. Each field in selector is a dot
+// separated path into root, e.g. "spec.type". A path that doesn't exist, or whose value isn't
+// a string, number, or bool, has the value "".
+func MatchesFieldSelector(selector fields.Selector, root map[string]interface{}) bool {
+	if selector == nil || selector.Empty() {
+		return true
+	}
+
+	set := fields.Set{}
+	for _, requirement := range selector.Requirements() {
+		set[requirement.Field] = fieldValue(root, requirement.Field)
+	}
+
+	return selector.Matches(set)
+}
+
+func fieldValue(root map[string]interface{}, path string) string {
+	value, err := GetValue(root, path)
+	if err != nil {
+		return ""
+	}
+
+	switch value.(type) {
+	case string, bool, int64, float64:
+		return fmt.Sprint(value)
+	default:
+		return ""
+	}
+}
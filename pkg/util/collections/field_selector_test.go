@@ -0,0 +1,66 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collections
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+func TestMatchesFieldSelector(t *testing.T) {
+	service := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "svc-1"},
+		"spec": map[string]interface{}{
+			"type":  "LoadBalancer",
+			"ports": []interface{}{map[string]interface{}{"port": int64(80)}},
+		},
+		"status": map[string]interface{}{"replicas": int64(3), "ready": true},
+	}
+
+	tests := []struct {
+		selector string
+		expected bool
+	}{
+		{"", true},
+		{"spec.type=LoadBalancer", true},
+		{"spec.type==LoadBalancer", true},
+		{"spec.type!=LoadBalancer", false},
+		{"spec.type=ClusterIP", false},
+		{"spec.type=LoadBalancer,metadata.name=svc-1", true},
+		{"spec.type=LoadBalancer,metadata.name=svc-2", false},
+		{"status.replicas=3,status.ready=true", true},
+		{"spec.clusterIP=", true},
+		{"spec.clusterIP!=None", true},
+		{"spec.ports=", true},
+		{"metadata.name.first=svc-1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.selector, func(t *testing.T) {
+			selector, err := fields.ParseSelector(test.selector)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, MatchesFieldSelector(selector, service))
+		})
+	}
+
+	assert.True(t, MatchesFieldSelector(nil, service))
+}
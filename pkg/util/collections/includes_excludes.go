@@ -17,6 +17,7 @@ limitations under the License.
 package collections
 
 import (
+	"path"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -28,7 +29,12 @@ import (
 // and excluded items. The logic implemented is that everything
 // in the included list except those items in the excluded list
 // should be included. '*' in the includes list means "include
-// everything", but it is not valid in the exclude list.
+// everything", but it is not valid in the exclude list. Group
+// wildcards such as '*.example.com' are valid in both lists, and
+// match every resource.group item in that group. Other glob
+// patterns, such as 'kube-*' or '*-system', are also valid in both
+// lists, and match items as path.Match does; they're meant for
+// namespaces.
 type IncludesExcludes struct {
 	includes sets.String
 	excludes sets.String
@@ -68,12 +74,71 @@ func (ie *IncludesExcludes) GetExcludes() []string {
 // included or not. Everything in the includes list except those
 // items in the excludes list should be included.
 func (ie *IncludesExcludes) ShouldInclude(s string) bool {
-	if ie.excludes.Has(s) {
+	if matches(ie.excludes, s) {
 		return false
 	}
 
 	// len=0 means include everything
-	return ie.includes.Len() == 0 || ie.includes.Has("*") || ie.includes.Has(s)
+	return ie.includes.Len() == 0 || ie.includes.Has("*") || matches(ie.includes, s)
+}
+
+// matches returns whether s is in items, either directly or by
+// matching a group wildcard or glob pattern in items.
+func matches(items sets.String, s string) bool {
+	if items.Has(s) {
+		return true
+	}
+
+	for item := range items {
+		if IsGroupWildcard(item) {
+			if groupOf(s) == strings.TrimPrefix(item, "*.") {
+				return true
+			}
+			continue
+		}
+
+		if IsGlob(item) {
+			// invalid patterns are rejected by ValidateIncludesExcludes, and never match
+			if matched, _ := path.Match(item, s); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// IsGlob returns whether s is a glob pattern, such as '*' or 'kube-*',
+// rather than a literal item.
+func IsGlob(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// HasGlobIncludes returns whether any of the includes are glob patterns,
+// in which case the included items can't be listed without checking
+// every item with ShouldInclude.
+func (ie *IncludesExcludes) HasGlobIncludes() bool {
+	for item := range ie.includes {
+		if IsGlob(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsGroupWildcard returns whether s is a group wildcard, such as
+// '*.example.com', which matches all resources in a group.
+func IsGroupWildcard(s string) bool {
+	return strings.HasPrefix(s, "*.") && len(s) > len("*.")
+}
+
+// groupOf returns the group of a resource.group string, or the empty
+// string if it has no group.
+func groupOf(s string) string {
+	if i := strings.Index(s, "."); i >= 0 {
+		return s[i+1:]
+	}
+	return ""
 }
 
 // IncludesString returns a string containing all of the includes, separated by commas, or * if the
@@ -127,18 +192,25 @@ func ValidateIncludesExcludes(includesList, excludesList []string) []error {
 		}
 	}
 
+	for _, itm := range append(includes.List(), excludes.List()...) {
+		if _, err := path.Match(itm, ""); err != nil {
+			errs = append(errs, errors.Errorf("invalid pattern %q: %v", itm, err))
+		}
+	}
+
 	return errs
 }
 
 // GenerateIncludesExcludes constructs an IncludesExcludes struct by taking the provided
 // include/exclude slices, applying the specified mapping function to each item in them,
 // and adding the output of the function to the new struct. If the mapping function returns
-// an empty string for an item, it is omitted from the result.
+// an empty string for an item, it is omitted from the result. Group wildcards are added
+// without being mapped.
 func GenerateIncludesExcludes(includes, excludes []string, mapFunc func(string) string) *IncludesExcludes {
 	res := NewIncludesExcludes()
 
 	for _, item := range includes {
-		if item == "*" {
+		if item == "*" || IsGroupWildcard(item) {
 			res.Includes(item)
 			continue
 		}
@@ -151,6 +223,11 @@ func GenerateIncludesExcludes(includes, excludes []string, mapFunc func(string)
 	}
 
 	for _, item := range excludes {
+		if IsGroupWildcard(item) {
+			res.Excludes(item)
+			continue
+		}
+
 		key := mapFunc(item)
 		if key == "" {
 			continue
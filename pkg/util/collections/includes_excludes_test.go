@@ -77,6 +77,70 @@ func TestShouldInclude(t *testing.T) {
 			check:    "foo",
 			should:   false,
 		},
+		{
+			name:     "include group wildcard - found",
+			includes: []string{"*.example.com"},
+			check:    "foos.example.com",
+			should:   true,
+		},
+		{
+			name:     "include group wildcard - subgroup not found",
+			includes: []string{"*.example.com"},
+			check:    "foos.sub.example.com",
+			should:   false,
+		},
+		{
+			name:     "include group wildcard - core resource not found",
+			includes: []string{"*.example.com"},
+			check:    "pods",
+			should:   false,
+		},
+		{
+			name:     "include *, exclude group wildcard",
+			includes: []string{"*"},
+			excludes: []string{"*.example.com"},
+			check:    "foos.example.com",
+			should:   false,
+		},
+		{
+			name:     "include group wildcard, exclude specific resource in group",
+			includes: []string{"*.example.com"},
+			excludes: []string{"bars.example.com"},
+			check:    "bars.example.com",
+			should:   false,
+		},
+		{
+			name:     "include pattern - found",
+			includes: []string{"kube-*"},
+			check:    "kube-system",
+			should:   true,
+		},
+		{
+			name:     "include pattern - not found",
+			includes: []string{"kube-*"},
+			check:    "default",
+			should:   false,
+		},
+		{
+			name:     "include *, exclude pattern",
+			includes: []string{"*"},
+			excludes: []string{"*-system"},
+			check:    "kube-system",
+			should:   false,
+		},
+		{
+			name:     "include pattern, exclude specific item matching it",
+			includes: []string{"kube-*"},
+			excludes: []string{"kube-public"},
+			check:    "kube-public",
+			should:   false,
+		},
+		{
+			name:     "include character class pattern",
+			includes: []string{"team-[ab]"},
+			check:    "team-b",
+			should:   true,
+		},
 	}
 
 	for _, test := range tests {
@@ -121,6 +185,20 @@ func TestValidateIncludesExcludes(t *testing.T) {
 			excludes: []string{"bar"},
 			expected: []error{errors.New("excludes list cannot contain an item in the includes list: bar")},
 		},
+		{
+			name:     "patterns are allowed",
+			includes: []string{"kube-*"},
+			excludes: []string{"*-system"},
+		},
+		{
+			name:     "malformed patterns not allowed",
+			includes: []string{"team-[ab"},
+			excludes: []string{"foo-["},
+			expected: []error{
+				errors.New(`invalid pattern "team-[ab": syntax error in pattern`),
+				errors.New(`invalid pattern "foo-[": syntax error in pattern`),
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -168,3 +246,21 @@ func TestIncludeExcludeString(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateIncludesExcludes(t *testing.T) {
+	mapFunc := func(item string) string {
+		if item == "unknown" {
+			return ""
+		}
+		return item + ".mapped"
+	}
+
+	res := GenerateIncludesExcludes(
+		[]string{"foo", "*.example.com", "unknown"},
+		[]string{"bar", "*.other.com"},
+		mapFunc,
+	)
+
+	assert.Equal(t, []string{"*.example.com", "foo.mapped"}, res.GetIncludes())
+	assert.Equal(t, []string{"*.other.com", "bar.mapped"}, res.GetExcludes())
+}
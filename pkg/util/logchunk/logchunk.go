@@ -0,0 +1,148 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logchunk splits backup and restore logs into size-capped chunks, so that
+// large logs can be stored, and downloaded, as several smaller files.
+package logchunk
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Index describes the chunks that a log is stored in. The chunks are numbered from 1.
+type Index struct {
+	// Chunks is the number of chunks the log is stored in.
+	Chunks int `json:"chunks"`
+
+	// TruncatedBytes is the number of bytes at the end of the log that weren't stored,
+	// because the log was larger than its maximum size.
+	TruncatedBytes int64 `json:"truncatedBytes,omitempty"`
+}
+
+// chunk is a temp file that a chunk of a log is being written to.
+type chunk struct {
+	file *os.File
+	gzw  *gzip.Writer
+	size int64
+}
+
+// Split reads a gzipped log from r, and writes it, a line at a time, to gzipped temp
+// files holding at most chunkSizeBytes bytes of it each. A line longer than
+// chunkSizeBytes is written to a chunk of its own. If chunkSizeBytes is zero, the log
+// isn't split. If maxSizeBytes is greater than zero, only the lines in the first
+// maxSizeBytes bytes of the log are written, followed by a line noting how much of it
+// was truncated. It returns the log's index and the temp files, in order, which the
+// caller must remove.
+func Split(r io.Reader, chunkSizeBytes, maxSizeBytes int64) (*Index, []*os.File, error) {
+	var chunks []*chunk
+
+	// on error, remove any temp files that were created
+	removeFiles := func() {
+		for _, c := range chunks {
+			c.file.Close()
+			os.Remove(c.file.Name())
+		}
+	}
+
+	newChunk := func() error {
+		tempFile, err := ioutil.TempFile("", "")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		chunks = append(chunks, &chunk{file: tempFile, gzw: gzip.NewWriter(tempFile)})
+		return nil
+	}
+
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	defer gzr.Close()
+
+	if err := newChunk(); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		index   = new(Index)
+		br      = bufio.NewReader(gzr)
+		written int64
+	)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			lineSize := int64(len(line))
+
+			switch {
+			case index.TruncatedBytes > 0 || (maxSizeBytes > 0 && written+lineSize > maxSizeBytes):
+				index.TruncatedBytes += lineSize
+			default:
+				current := chunks[len(chunks)-1]
+				if chunkSizeBytes > 0 && current.size > 0 && current.size+lineSize > chunkSizeBytes {
+					if err := newChunk(); err != nil {
+						removeFiles()
+						return nil, nil, err
+					}
+					current = chunks[len(chunks)-1]
+				}
+
+				if _, err := current.gzw.Write(line); err != nil {
+					removeFiles()
+					return nil, nil, errors.WithStack(err)
+				}
+				current.size += lineSize
+				written += lineSize
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			removeFiles()
+			return nil, nil, errors.WithStack(err)
+		}
+	}
+
+	if index.TruncatedBytes > 0 {
+		last := chunks[len(chunks)-1]
+		note := fmt.Sprintf("Log truncated: the last %d bytes weren't stored because the log is larger than %d bytes\n", index.TruncatedBytes, maxSizeBytes)
+		if _, err := io.WriteString(last.gzw, note); err != nil {
+			removeFiles()
+			return nil, nil, errors.WithStack(err)
+		}
+	}
+
+	files := make([]*os.File, 0, len(chunks))
+	for _, c := range chunks {
+		if err := c.gzw.Close(); err != nil {
+			removeFiles()
+			return nil, nil, errors.WithStack(err)
+		}
+		files = append(files, c.file)
+	}
+	index.Chunks = len(files)
+
+	return index, files, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logchunk
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipped(t *testing.T, s string) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	gzw := gzip.NewWriter(buf)
+	_, err := gzw.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, gzw.Close())
+	return buf
+}
+
+func readChunk(t *testing.T, file *os.File) string {
+	_, err := file.Seek(0, 0)
+	require.NoError(t, err)
+
+	gzr, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	defer gzr.Close()
+
+	contents, err := ioutil.ReadAll(gzr)
+	require.NoError(t, err)
+	return string(contents)
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name           string
+		log            string
+		chunkSizeBytes int64
+		maxSizeBytes   int64
+		expectedIndex  Index
+		expectedChunks []string
+	}{
+		{
+			name:           "empty log is a single empty chunk",
+			log:            "",
+			chunkSizeBytes: 10,
+			expectedIndex:  Index{Chunks: 1},
+			expectedChunks: []string{""},
+		},
+		{
+			name:           "log smaller than the chunk size isn't split",
+			log:            "line 1\nline 2\n",
+			chunkSizeBytes: 100,
+			expectedIndex:  Index{Chunks: 1},
+			expectedChunks: []string{"line 1\nline 2\n"},
+		},
+		{
+			name:           "log is split on line boundaries",
+			log:            "line 1\nline 2\nline 3\n",
+			chunkSizeBytes: 15,
+			expectedIndex:  Index{Chunks: 2},
+			expectedChunks: []string{"line 1\nline 2\n", "line 3\n"},
+		},
+		{
+			name:           "a line longer than the chunk size gets its own chunk",
+			log:            "line 1\na very long line\nline 3",
+			chunkSizeBytes: 10,
+			expectedIndex:  Index{Chunks: 3},
+			expectedChunks: []string{"line 1\n", "a very long line\n", "line 3"},
+		},
+		{
+			name:           "log larger than the max size is truncated",
+			log:            "line 1\nline 2\nline 3\n",
+			chunkSizeBytes: 7,
+			maxSizeBytes:   15,
+			expectedIndex:  Index{Chunks: 2, TruncatedBytes: 7},
+			expectedChunks: []string{
+				"line 1\n",
+				"line 2\nLog truncated: the last 7 bytes weren't stored because the log is larger than 15 bytes\n",
+			},
+		},
+		{
+			name:           "max size without a chunk size truncates a single chunk",
+			log:            "line 1\nline 2\n",
+			maxSizeBytes:   10,
+			expectedIndex:  Index{Chunks: 1, TruncatedBytes: 7},
+			expectedChunks: []string{"line 1\nLog truncated: the last 7 bytes weren't stored because the log is larger than 10 bytes\n"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			index, files, err := Split(gzipped(t, test.log), test.chunkSizeBytes, test.maxSizeBytes)
+			require.NoError(t, err)
+			defer func() {
+				for _, file := range files {
+					file.Close()
+					os.Remove(file.Name())
+				}
+			}()
+
+			assert.Equal(t, test.expectedIndex, *index)
+
+			var chunks []string
+			for _, file := range files {
+				chunks = append(chunks, readChunk(t, file))
+			}
+			assert.Equal(t, test.expectedChunks, chunks)
+		})
+	}
+}
+
+func TestSplitInvalidLog(t *testing.T) {
+	_, _, err := Split(bytes.NewBufferString("not gzipped"), 10, 0)
+	assert.Error(t, err)
+}
@@ -14,30 +14,30 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package controller
+package logging
 
 import (
+	"io/ioutil"
 	"testing"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestBackupTracker(t *testing.T) {
-	bt := NewBackupTracker()
+func TestLogCounterHook(t *testing.T) {
+	hook := NewLogCounterHook()
 
-	assert.False(t, bt.Contains("ns", "name"))
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	logger.Hooks.Add(hook)
 
-	bt.Add("ns", "name")
-	assert.True(t, bt.Contains("ns", "name"))
+	logger.Info("info")
+	logger.Warn("warning 1")
+	logger.WithField("foo", "bar").Warn("warning 2")
+	logger.Error("error")
 
-	bt.Add("ns2", "name2")
-	assert.True(t, bt.Contains("ns", "name"))
-	assert.True(t, bt.Contains("ns2", "name2"))
-
-	bt.Delete("ns", "name")
-	assert.False(t, bt.Contains("ns", "name"))
-	assert.True(t, bt.Contains("ns2", "name2"))
-
-	bt.Delete("ns2", "name2")
-	assert.False(t, bt.Contains("ns2", "name2"))
+	assert.Equal(t, 1, hook.GetCount(logrus.InfoLevel))
+	assert.Equal(t, 2, hook.GetCount(logrus.WarnLevel))
+	assert.Equal(t, 1, hook.GetCount(logrus.ErrorLevel))
+	assert.Equal(t, 0, hook.GetCount(logrus.DebugLevel))
 }
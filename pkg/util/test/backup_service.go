@@ -19,6 +19,7 @@ package test
 
 import io "io"
 import mock "github.com/stretchr/testify/mock"
+import shard "github.com/heptio/ark/pkg/backup/shard"
 import time "time"
 import v1 "github.com/heptio/ark/pkg/apis/ark/v1"
 
@@ -49,26 +50,35 @@ func (_m *BackupService) CreateSignedURL(target v1.DownloadTarget, bucket, direc
 }
 
 // DeleteBackupDir provides a mock function with given fields: bucket, backupName
-func (_m *BackupService) DeleteBackupDir(bucket string, backupName string) error {
+func (_m *BackupService) DeleteBackupDir(bucket string, backupName string) ([]v1.ArtifactDeletionResult, error) {
 	ret := _m.Called(bucket, backupName)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+	var r0 []v1.ArtifactDeletionResult
+	if rf, ok := ret.Get(0).(func(string, string) []v1.ArtifactDeletionResult); ok {
 		r0 = rf(bucket, backupName)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]v1.ArtifactDeletionResult)
+		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(bucket, backupName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// DownloadBackup provides a mock function with given fields: bucket, name
-func (_m *BackupService) DownloadBackup(bucket string, name string) (io.ReadCloser, error) {
-	ret := _m.Called(bucket, name)
+// DownloadBackup provides a mock function with given fields: bucket, backup, include
+func (_m *BackupService) DownloadBackup(bucket string, backup *v1.Backup, include func(shard.Shard) bool) (io.ReadCloser, error) {
+	ret := _m.Called(bucket, backup, include)
 
 	var r0 io.ReadCloser
-	if rf, ok := ret.Get(0).(func(string, string) io.ReadCloser); ok {
-		r0 = rf(bucket, name)
+	if rf, ok := ret.Get(0).(func(string, *v1.Backup, func(shard.Shard) bool) io.ReadCloser); ok {
+		r0 = rf(bucket, backup, include)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(io.ReadCloser)
@@ -76,8 +86,8 @@ func (_m *BackupService) DownloadBackup(bucket string, name string) (io.ReadClos
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(bucket, name)
+	if rf, ok := ret.Get(1).(func(string, *v1.Backup, func(shard.Shard) bool) error); ok {
+		r1 = rf(bucket, backup, include)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -85,6 +95,20 @@ func (_m *BackupService) DownloadBackup(bucket string, name string) (io.ReadClos
 	return r0, r1
 }
 
+// ForEachBackup provides a mock function with given fields: bucket, fn
+func (_m *BackupService) ForEachBackup(bucket string, fn func(*v1.Backup) error) error {
+	ret := _m.Called(bucket, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, func(*v1.Backup) error) error); ok {
+		r0 = rf(bucket, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetAllBackups provides a mock function with given fields: bucket
 func (_m *BackupService) GetAllBackups(bucket string) ([]*v1.Backup, error) {
 	ret := _m.Called(bucket)
@@ -131,13 +155,71 @@ func (_m *BackupService) GetBackup(bucket string, name string) (*v1.Backup, erro
 	return r0, r1
 }
 
-// UploadBackup provides a mock function with given fields: bucket, name, metadata, backup, log
-func (_m *BackupService) UploadBackup(bucket string, name string, metadata io.Reader, backup io.Reader, log io.Reader) error {
-	ret := _m.Called(bucket, name, metadata, backup, log)
+// BackupMetadataExists provides a mock function with given fields: bucket, backupName
+func (_m *BackupService) BackupMetadataExists(bucket string, backupName string) (bool, error) {
+	ret := _m.Called(bucket, backupName)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
+		r0 = rf(bucket, backupName)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(bucket, backupName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListBackupDirs provides a mock function with given fields: bucket
+func (_m *BackupService) ListBackupDirs(bucket string) ([]string, error) {
+	ret := _m.Called(bucket)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(bucket)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(bucket)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UploadAuditLog provides a mock function with given fields: bucket, name, log
+func (_m *BackupService) UploadAuditLog(bucket string, name string, log io.Reader) error {
+	ret := _m.Called(bucket, name, log)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, io.Reader, io.Reader, io.Reader) error); ok {
-		r0 = rf(bucket, name, metadata, backup, log)
+	if rf, ok := ret.Get(0).(func(string, string, io.Reader) error); ok {
+		r0 = rf(bucket, name, log)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UploadBackup provides a mock function with given fields: bucket, backup, metadata, backupFile, log
+func (_m *BackupService) UploadBackup(bucket string, backup *v1.Backup, metadata io.Reader, backupFile io.Reader, log io.Reader) error {
+	ret := _m.Called(bucket, backup, metadata, backupFile, log)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *v1.Backup, io.Reader, io.Reader, io.Reader) error); ok {
+		r0 = rf(bucket, backup, metadata, backupFile, log)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -172,3 +254,31 @@ func (_m *BackupService) UploadRestoreResults(bucket string, backup string, rest
 
 	return r0
 }
+
+// UploadRestorePlan provides a mock function with given fields: bucket, backup, restore, plan
+func (_m *BackupService) UploadRestorePlan(bucket string, backup string, restore string, plan io.Reader) error {
+	ret := _m.Called(bucket, backup, restore, plan)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string, io.Reader) error); ok {
+		r0 = rf(bucket, backup, restore, plan)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ValidateAccess provides a mock function with given fields: bucket, readOnly
+func (_m *BackupService) ValidateAccess(bucket string, readOnly bool) error {
+	ret := _m.Called(bucket, readOnly)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, bool) error); ok {
+		r0 = rf(bucket, readOnly)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"sync"
+
+	"github.com/heptio/ark/pkg/audit"
+)
+
+// FakeAuditLog is an audit.Log that keeps the entries recorded to it in memory. It doesn't
+// set the entries' times, so tests can compare them.
+type FakeAuditLog struct {
+	lock    sync.Mutex
+	entries []audit.Entry
+}
+
+func (l *FakeAuditLog) Record(entry audit.Entry) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.entries = append(l.entries, entry)
+}
+
+// Entries returns the entries recorded so far.
+func (l *FakeAuditLog) Entries() []audit.Entry {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return append([]audit.Entry(nil), l.entries...)
+}
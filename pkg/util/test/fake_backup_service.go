@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/heptio/ark/pkg/backup/shard"
 )
 
 type FakeBackupService struct {
@@ -46,8 +47,8 @@ func (f *FakeBackupService) UploadBackup(bucket, name string, metadata, backup i
 	return args.Error(0)
 }
 
-func (f *FakeBackupService) DownloadBackup(bucket, name string) (io.ReadCloser, error) {
-	args := f.Called(bucket, name)
+func (f *FakeBackupService) DownloadBackup(bucket string, backup *v1.Backup, include func(shard.Shard) bool) (io.ReadCloser, error) {
+	args := f.Called(bucket, backup, include)
 	return args.Get(0).(io.ReadCloser), args.Error(1)
 }
 
@@ -23,12 +23,14 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
 )
 
 type FakeDiscoveryHelper struct {
 	ResourceList       []*metav1.APIResourceList
 	Mapper             meta.RESTMapper
 	AutoReturnResource bool
+	APIServerVersion   *version.Info
 }
 
 func NewFakeDiscoveryHelper(autoReturnResource bool, resources map[schema.GroupVersionResource]schema.GroupVersionResource) *FakeDiscoveryHelper {
@@ -71,6 +73,30 @@ func (dh *FakeDiscoveryHelper) Refresh() error {
 	return nil
 }
 
+func (dh *FakeDiscoveryHelper) RefreshIfStale() error {
+	return nil
+}
+
+func (dh *FakeDiscoveryHelper) Serves(gvr schema.GroupVersionResource) bool {
+	for _, resourceList := range dh.ResourceList {
+		if resourceList.GroupVersion != gvr.GroupVersion().String() {
+			continue
+		}
+
+		for _, resource := range resourceList.APIResources {
+			if resource.Name == gvr.Resource {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (dh *FakeDiscoveryHelper) ServerVersion() *version.Info {
+	return dh.APIServerVersion
+}
+
 func (dh *FakeDiscoveryHelper) ResourceFor(input schema.GroupVersionResource) (schema.GroupVersionResource, metav1.APIResource, error) {
 	if dh.AutoReturnResource {
 		return schema.GroupVersionResource{
@@ -35,6 +35,12 @@ type FakeSnapshotService struct {
 	// VolumeBackupInfo -> VolumeID
 	RestorableVolumes map[api.VolumeBackupInfo]string
 
+	// SnapshotID -> Phase
+	SnapshotPhases map[string]api.SnapshotPhase
+
+	// SnapshotID -> Tags
+	SnapshotTags map[string]map[string]string
+
 	VolumeID    string
 	VolumeIDSet string
 
@@ -55,9 +61,38 @@ func (s *FakeSnapshotService) CreateSnapshot(volumeID, volumeAZ string, tags map
 	}
 	s.SnapshotsTaken.Insert(s.SnapshottableVolumes[volumeID].SnapshotID)
 
+	if s.SnapshotTags == nil {
+		s.SnapshotTags = make(map[string]map[string]string)
+	}
+	s.SnapshotTags[s.SnapshottableVolumes[volumeID].SnapshotID] = tags
+
 	return s.SnapshottableVolumes[volumeID].SnapshotID, nil
 }
 
+func (s *FakeSnapshotService) ListSnapshots(tagFilters map[string]string) ([]string, error) {
+	if s.Error != nil {
+		return nil, s.Error
+	}
+
+	var snapshotIDs []string
+	for _, snapshotID := range s.SnapshotsTaken.List() {
+		matches := true
+		for key, val := range tagFilters {
+			tag, ok := s.SnapshotTags[snapshotID][key]
+			if !ok || (val != "" && tag != val) {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			snapshotIDs = append(snapshotIDs, snapshotID)
+		}
+	}
+
+	return snapshotIDs, nil
+}
+
 func (s *FakeSnapshotService) CreateVolumeFromSnapshot(snapshotID, volumeType, volumeAZ string, iops *int64) (string, error) {
 	if s.Error != nil {
 		return "", s.Error
@@ -99,6 +134,33 @@ func (s *FakeSnapshotService) GetVolumeInfo(volumeID, volumeAZ string) (string,
 	}
 }
 
+func (s *FakeSnapshotService) GetSnapshotPhase(snapshotID string) (api.SnapshotPhase, error) {
+	if s.Error != nil {
+		return "", s.Error
+	}
+
+	if phase, exists := s.SnapshotPhases[snapshotID]; !exists {
+		return "", errors.New("snapshot not found")
+	} else {
+		return phase, nil
+	}
+}
+
+func (s *FakeSnapshotService) CopySnapshot(snapshotID, region string) (string, error) {
+	if s.Error != nil {
+		return "", s.Error
+	}
+
+	if !s.SnapshotsTaken.Has(snapshotID) {
+		return "", errors.New("snapshot not found")
+	}
+
+	copySnapshotID := region + "/" + snapshotID
+	s.SnapshotsTaken.Insert(copySnapshotID)
+
+	return copySnapshotID, nil
+}
+
 func (s *FakeSnapshotService) GetVolumeID(pv runtime.Unstructured) (string, error) {
 	if s.Error != nil {
 		return "", s.Error
@@ -84,6 +84,36 @@ func (_m *ObjectStore) GetObject(bucket string, key string) (io.ReadCloser, erro
 	return r0, r1
 }
 
+// GetObjectIfChanged provides a mock function with given fields: bucket, key, etag
+func (_m *ObjectStore) GetObjectIfChanged(bucket string, key string, etag string) (io.ReadCloser, string, error) {
+	ret := _m.Called(bucket, key, etag)
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(string, string, string) io.ReadCloser); ok {
+		r0 = rf(bucket, key, etag)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	var r1 string
+	if rf, ok := ret.Get(1).(func(string, string, string) string); ok {
+		r1 = rf(bucket, key, etag)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, string, string) error); ok {
+		r2 = rf(bucket, key, etag)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Init provides a mock function with given fields: config
 func (_m *ObjectStore) Init(config map[string]string) error {
 	ret := _m.Called(config)
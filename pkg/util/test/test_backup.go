@@ -57,11 +57,30 @@ func (b *TestBackup) WithLabel(key, value string) *TestBackup {
 	return b
 }
 
+func (b *TestBackup) WithAnnotation(key, value string) *TestBackup {
+	if b.Annotations == nil {
+		b.Annotations = make(map[string]string)
+	}
+	b.Annotations[key] = value
+
+	return b
+}
+
 func (b *TestBackup) WithPhase(phase v1.BackupPhase) *TestBackup {
 	b.Status.Phase = phase
 	return b
 }
 
+func (b *TestBackup) WithAttempts(attempts int) *TestBackup {
+	b.Status.Attempts = attempts
+	return b
+}
+
+func (b *TestBackup) WithDryRun(value bool) *TestBackup {
+	b.Spec.DryRun = value
+	return b
+}
+
 func (b *TestBackup) WithIncludedResources(r ...string) *TestBackup {
 	b.Spec.IncludedResources = r
 	return b
@@ -105,6 +124,19 @@ func (b *TestBackup) WithSnapshot(pv string, snapshot string) *TestBackup {
 	return b
 }
 
+func (b *TestBackup) WithSnapshotInPhase(pv string, snapshot string, phase v1.SnapshotPhase) *TestBackup {
+	b.WithSnapshot(pv, snapshot)
+	b.Status.VolumeBackups[pv].Phase = phase
+	return b
+}
+
+func (b *TestBackup) WithSnapshotCopyInPhase(pv string, snapshot string, copySnapshot string, phase v1.SnapshotPhase) *TestBackup {
+	b.WithSnapshotInPhase(pv, snapshot, v1.SnapshotPhaseCompleted)
+	b.Status.VolumeBackups[pv].CopySnapshotID = copySnapshot
+	b.Status.VolumeBackups[pv].CopyPhase = phase
+	return b
+}
+
 func (b *TestBackup) WithSnapshotVolumes(value bool) *TestBackup {
 	b.Spec.SnapshotVolumes = &value
 	return b
@@ -115,6 +147,26 @@ func (b *TestBackup) WithSnapshotVolumesPointer(value *bool) *TestBackup {
 	return b
 }
 
+func (b *TestBackup) WithShardBy(shardBy v1.BackupShardBy) *TestBackup {
+	b.Spec.ShardBy = shardBy
+	return b
+}
+
+func (b *TestBackup) WithFailurePolicy(policy v1.BackupFailurePolicy) *TestBackup {
+	b.Spec.FailurePolicy = policy
+	return b
+}
+
+func (b *TestBackup) WithDeletionPolicy(policy v1.BackupDeletionPolicy) *TestBackup {
+	b.Spec.DeletionPolicy = policy
+	return b
+}
+
+func (b *TestBackup) WithQuiesce(quiesce *v1.QuiesceSpec) *TestBackup {
+	b.Spec.Quiesce = quiesce
+	return b
+}
+
 func (b *TestBackup) WithDeletionTimestamp(time time.Time) *TestBackup {
 	b.DeletionTimestamp = &metav1.Time{Time: time}
 	return b
@@ -75,6 +75,16 @@ func (r *TestRestore) WithRestorePVs(value bool) *TestRestore {
 	return r
 }
 
+func (r *TestRestore) WithDryRun(value bool) *TestRestore {
+	r.Spec.DryRun = value
+	return r
+}
+
+func (r *TestRestore) WithIncludeArkResources(value bool) *TestRestore {
+	r.Spec.IncludeArkResources = value
+	return r
+}
+
 func (r *TestRestore) WithMappedNamespace(from string, to string) *TestRestore {
 	if r.Spec.NamespaceMapping == nil {
 		r.Spec.NamespaceMapping = make(map[string]string)
@@ -83,6 +93,14 @@ func (r *TestRestore) WithMappedNamespace(from string, to string) *TestRestore {
 	return r
 }
 
+func (r *TestRestore) WithMappedAvailabilityZone(from string, to string) *TestRestore {
+	if r.Spec.AvailabilityZoneMapping == nil {
+		r.Spec.AvailabilityZoneMapping = make(map[string]string)
+	}
+	r.Spec.AvailabilityZoneMapping[from] = to
+	return r
+}
+
 func (r *TestRestore) WithIncludedResource(resource string) *TestRestore {
 	r.Spec.IncludedResources = append(r.Spec.IncludedResources, resource)
 	return r
@@ -92,3 +110,13 @@ func (r *TestRestore) WithExcludedResource(resource string) *TestRestore {
 	r.Spec.ExcludedResources = append(r.Spec.ExcludedResources, resource)
 	return r
 }
+
+func (r *TestRestore) WithClusterDomainMapping(from, to string) *TestRestore {
+	r.Spec.ClusterDomainMapping = &api.ClusterDomainMapping{From: from, To: to}
+	return r
+}
+
+func (r *TestRestore) WithReplicasPolicy(policy api.ReplicasPolicy) *TestRestore {
+	r.Spec.Replicas = policy
+	return r
+}
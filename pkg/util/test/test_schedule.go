@@ -59,3 +59,31 @@ func (s *TestSchedule) WithLastBackupTime(timeString string) *TestSchedule {
 	s.Status.LastBackup = metav1.Time{Time: t}
 	return s
 }
+
+func (s *TestSchedule) WithConcurrencyPolicy(policy api.ConcurrencyPolicy) *TestSchedule {
+	s.Spec.ConcurrencyPolicy = policy
+	return s
+}
+
+func (s *TestSchedule) WithJitter(jitter time.Duration) *TestSchedule {
+	s.Spec.Jitter = metav1.Duration{Duration: jitter}
+	return s
+}
+
+func (s *TestSchedule) WithTimeZone(timeZone string) *TestSchedule {
+	s.Spec.TimeZone = timeZone
+	return s
+}
+
+func (s *TestSchedule) WithLabel(key, value string) *TestSchedule {
+	if s.Labels == nil {
+		s.Labels = make(map[string]string)
+	}
+	s.Labels[key] = value
+	return s
+}
+
+func (s *TestSchedule) WithIncludedNamespaces(namespaces ...string) *TestSchedule {
+	s.Spec.Template.IncludedNamespaces = namespaces
+	return s
+}
@@ -0,0 +1,299 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing records spans for the steps of backups and restores and
+// sends them to a Zipkin-compatible collector, such as Zipkin or Jaeger's
+// Zipkin endpoint, so the time spent in slow backups can be analyzed.
+//
+// Tracing is disabled until SetTracer is called. While it's disabled, the
+// Start functions return nil spans, and all of a nil span's methods do
+// nothing, so callers don't need to check whether tracing is enabled.
+package tracing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// flushInterval is how often finished spans are sent to the collector.
+	flushInterval = time.Second
+
+	// maxPendingSpans is how many finished spans can be waiting to be sent.
+	// Spans that finish while this many are waiting are dropped.
+	maxPendingSpans = 10000
+)
+
+var (
+	tracerLock sync.RWMutex
+	tracer     *Tracer
+)
+
+// SetTracer enables tracing, recording spans with t.
+func SetTracer(t *Tracer) {
+	tracerLock.Lock()
+	defer tracerLock.Unlock()
+	tracer = t
+}
+
+func currentTracer() *Tracer {
+	tracerLock.RLock()
+	defer tracerLock.RUnlock()
+	return tracer
+}
+
+// StartOperation starts the root span of the trace for the backup or restore
+// with the given OperationID. Every backup or restore has its own trace.
+func StartOperation(operationID, name string) *Span {
+	t := currentTracer()
+	if t == nil {
+		return nil
+	}
+
+	traceID := traceIDFor(operationID)
+	return t.startSpan(traceID, rootSpanID(traceID), "", name)
+}
+
+// StartSpan starts a span that's a child of the root span of the trace for the
+// backup or restore with the given OperationID, so steps that don't have access
+// to their parent span can still be included in the trace.
+func StartSpan(operationID, name string) *Span {
+	t := currentTracer()
+	if t == nil {
+		return nil
+	}
+
+	traceID := traceIDFor(operationID)
+	return t.startSpan(traceID, newSpanID(), rootSpanID(traceID), name)
+}
+
+// traceIDFor returns the 128-bit trace ID, in hex, for an OperationID. UUIDs
+// are used as they are; anything else is hashed.
+func traceIDFor(operationID string) string {
+	id := strings.ToLower(strings.Replace(operationID, "-", "", -1))
+	if _, err := hex.DecodeString(id); err == nil && len(id) == 32 {
+		return id
+	}
+
+	sum := sha256.Sum256([]byte(operationID))
+	return hex.EncodeToString(sum[:16])
+}
+
+// rootSpanID returns the ID of the root span of the trace with the given ID.
+func rootSpanID(traceID string) string {
+	return traceID[:16]
+}
+
+// newSpanID returns a random 64-bit span ID, in hex.
+func newSpanID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand doesn't fail on supported platforms, but a fixed ID is
+		// better than no span
+		return "0000000000000001"
+	}
+	return hex.EncodeToString(b)
+}
+
+// Span is a timed step of a backup or restore. A nil *Span is valid, and all
+// of its methods do nothing.
+type Span struct {
+	tracer   *Tracer
+	traceID  string
+	id       string
+	parentID string
+	name     string
+	start    time.Time
+
+	lock sync.Mutex
+	tags map[string]string
+}
+
+// SetTag records a key/value pair describing the span, such as the name of the
+// resource it backed up.
+func (s *Span) SetTag(key, value string) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.tags[key] = value
+}
+
+// StartChild starts a span that's a child of s.
+func (s *Span) StartChild(name string) *Span {
+	if s == nil {
+		return nil
+	}
+
+	return s.tracer.startSpan(s.traceID, newSpanID(), s.id, name)
+}
+
+// Finish records the span's duration and queues it to be sent to the collector.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	tags := make(map[string]string, len(s.tags))
+	for k, v := range s.tags {
+		tags[k] = v
+	}
+	s.lock.Unlock()
+
+	s.tracer.finish(zipkinSpan{
+		TraceID:       s.traceID,
+		ID:            s.id,
+		ParentID:      s.parentID,
+		Name:          s.name,
+		Timestamp:     s.start.UnixNano() / int64(time.Microsecond),
+		Duration:      int64(s.tracer.now().Sub(s.start) / time.Microsecond),
+		LocalEndpoint: zipkinEndpoint{ServiceName: s.tracer.serviceName},
+		Tags:          tags,
+	})
+}
+
+// zipkinSpan is a span in Zipkin's v2 JSON format.
+type zipkinSpan struct {
+	TraceID       string            `json:"traceId"`
+	ID            string            `json:"id"`
+	ParentID      string            `json:"parentId,omitempty"`
+	Name          string            `json:"name"`
+	Timestamp     int64             `json:"timestamp"`
+	Duration      int64             `json:"duration"`
+	LocalEndpoint zipkinEndpoint    `json:"localEndpoint"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+}
+
+// Tracer records spans and sends them to a Zipkin-compatible collector.
+type Tracer struct {
+	collectorURL string
+	serviceName  string
+	client       *http.Client
+	logger       logrus.FieldLogger
+	now          func() time.Time
+
+	pending chan zipkinSpan
+}
+
+// NewTracer returns a Tracer that sends spans, labeled with serviceName, to
+// the Zipkin v2 JSON API at collectorURL (e.g. http://zipkin:9411/api/v2/spans).
+// Spans aren't sent until Run is called.
+func NewTracer(collectorURL, serviceName string, logger logrus.FieldLogger) *Tracer {
+	return &Tracer{
+		collectorURL: collectorURL,
+		serviceName:  serviceName,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		logger:       logger,
+		now:          time.Now,
+		pending:      make(chan zipkinSpan, maxPendingSpans),
+	}
+}
+
+func (t *Tracer) startSpan(traceID, id, parentID, name string) *Span {
+	return &Span{
+		tracer:   t,
+		traceID:  traceID,
+		id:       id,
+		parentID: parentID,
+		name:     name,
+		start:    t.now(),
+		tags:     make(map[string]string),
+	}
+}
+
+func (t *Tracer) finish(span zipkinSpan) {
+	select {
+	case t.pending <- span:
+	default:
+		t.logger.WithField("span", span.Name).Debug("Dropping span because too many are waiting to be sent")
+	}
+}
+
+// Run sends finished spans to the collector until stopCh is closed, then sends
+// any that are left and returns.
+func (t *Tracer) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.flush()
+		case <-stopCh:
+			t.flush()
+			return
+		}
+	}
+}
+
+// flush sends all of the pending spans to the collector.
+func (t *Tracer) flush() {
+	var spans []zipkinSpan
+
+	for {
+		select {
+		case span := <-t.pending:
+			spans = append(spans, span)
+			continue
+		default:
+		}
+		break
+	}
+
+	if len(spans) == 0 {
+		return
+	}
+
+	if err := t.send(spans); err != nil {
+		t.logger.WithError(err).WithField("spans", len(spans)).Warn("Error sending spans to the trace collector")
+	}
+}
+
+func (t *Tracer) send(spans []zipkinSpan) error {
+	body, err := json.Marshal(spans)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	res, err := t.client.Post(t.collectorURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return errors.Errorf("unexpected response from %s: %s", t.collectorURL, res.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2018 the Heptio Ark contributors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	arktest "github.com/heptio/ark/pkg/util/test"
+)
+
+func TestDisabledTracingReturnsNilSpans(t *testing.T) {
+	SetTracer(nil)
+
+	span := StartOperation("operation-1", "backup")
+	assert.Nil(t, span)
+
+	// none of these should panic
+	span.SetTag("key", "value")
+	assert.Nil(t, span.StartChild("child"))
+	span.Finish()
+	assert.Nil(t, StartSpan("operation-1", "backupGroup"))
+}
+
+func TestTraceIDFor(t *testing.T) {
+	assert.Equal(t, "0b9e4d5a6f0c4b8e9d1e2a7c3f5e8b41", traceIDFor("0B9E4D5A-6F0C-4B8E-9D1E-2A7C3F5E8B41"))
+
+	// anything that isn't a UUID is hashed to a 128-bit ID
+	id := traceIDFor("operation-1")
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, traceIDFor("operation-1"))
+	assert.NotEqual(t, id, traceIDFor("operation-2"))
+}
+
+func TestTracerSendsSpans(t *testing.T) {
+	var received [][]zipkinSpan
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var spans []zipkinSpan
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&spans))
+		received = append(received, spans)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	tracer := NewTracer(server.URL, "ark", arktest.NewLogger())
+	tracer.now = func() time.Time {
+		// each call is a second after the last one
+		defer func() { now = now.Add(time.Second) }()
+		return now
+	}
+
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	operationID := "0b9e4d5a-6f0c-4b8e-9d1e-2a7c3f5e8b41"
+	traceID := "0b9e4d5a6f0c4b8e9d1e2a7c3f5e8b41"
+
+	root := StartOperation(operationID, "backup")
+	group := StartSpan(operationID, "backupGroup")
+	group.SetTag("group", "v1")
+	group.Finish()
+	upload := root.StartChild("upload")
+	upload.Finish()
+	root.Finish()
+
+	tracer.flush()
+
+	require.Len(t, received, 1)
+	spans := received[0]
+	require.Len(t, spans, 3)
+
+	assert.Equal(t, "backupGroup", spans[0].Name)
+	assert.Equal(t, traceID, spans[0].TraceID)
+	assert.Equal(t, "0b9e4d5a6f0c4b8e", spans[0].ParentID)
+	assert.Equal(t, map[string]string{"group": "v1"}, spans[0].Tags)
+	assert.Equal(t, start.Add(time.Second).UnixNano()/int64(time.Microsecond), spans[0].Timestamp)
+	assert.Equal(t, int64(time.Second/time.Microsecond), spans[0].Duration)
+	assert.Equal(t, "ark", spans[0].LocalEndpoint.ServiceName)
+
+	assert.Equal(t, "upload", spans[1].Name)
+	assert.Equal(t, traceID, spans[1].TraceID)
+	assert.Equal(t, "0b9e4d5a6f0c4b8e", spans[1].ParentID)
+
+	assert.Equal(t, "backup", spans[2].Name)
+	assert.Equal(t, "0b9e4d5a6f0c4b8e", spans[2].ID)
+	assert.Empty(t, spans[2].ParentID)
+	assert.Equal(t, int64(5*time.Second/time.Microsecond), spans[2].Duration)
+
+	// nothing is sent when there are no spans
+	tracer.flush()
+	assert.Len(t, received, 1)
+}